@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Gosayram/go-mdfmt/pkg/baseline"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// lintBaseline holds the baseline of a lint run: the violations it already
+// knows and, with --update-baseline, the violations found by the run
+type lintBaseline struct {
+	// path is the baseline file
+	path string
+	// dir is the absolute directory of the baseline file, which recorded
+	// paths are relative to
+	dir string
+	// known holds the recorded violations, or nil without a baseline file
+	known *baseline.Baseline
+	// found collects the violations of the run with --update-baseline
+	found *baseline.Baseline
+}
+
+// newLintBaseline returns the baseline of a lint run. Without an explicit
+// path, the default baseline file is used if it exists; with update, the
+// baseline is rewritten instead of read.
+func newLintBaseline(path string, explicit, update bool) (*lintBaseline, error) {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve baseline directory: %w", err)
+	}
+	l := &lintBaseline{path: path, dir: dir}
+	if update {
+		l.found = baseline.New()
+		return l, nil
+	}
+
+	l.known, err = baseline.Load(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// check returns the violations of the file at path that are new. While
+// the baseline is updated, violations are recorded and none is new.
+func (l *lintBaseline) check(path string, changed bool, diagnostics []parser.Diagnostic) ([]parser.Diagnostic, bool) {
+	if l == nil {
+		return diagnostics, changed
+	}
+	rel := l.relative(path)
+	if l.found != nil {
+		l.found.Add(rel, changed, diagnostics)
+		return nil, false
+	}
+	return l.known.Filter(rel, changed, diagnostics)
+}
+
+// relative returns path relative to the baseline directory, with forward
+// slashes so baselines are shared across platforms
+func (l *lintBaseline) relative(path string) string {
+	if path == StdinPath {
+		return StdinName
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(l.dir, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// save writes the violations found with --update-baseline, if any were
+// collected, and says so unless quiet
+func (l *lintBaseline) save(quiet bool) error {
+	if l == nil || l.found == nil {
+		return nil
+	}
+	if err := l.found.Save(l.path); err != nil {
+		return err
+	}
+	if !quiet {
+		total := 0
+		for _, v := range l.found.Violations() {
+			total += v.Count
+		}
+		fmt.Fprintf(os.Stderr, "Baseline: %d violation(s) recorded in %s\n", total, l.path)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Gosayram/go-mdfmt/pkg/bench"
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+)
+
+// BenchCommand is the name of the benchmark subcommand
+const BenchCommand = "bench"
+
+// runBench formats the markdown files below a directory repeatedly in
+// memory and reports the throughput
+func runBench(args []string) error {
+	fs := flag.NewFlagSet(BenchCommand, flag.ContinueOnError)
+	runs := fs.Int("runs", bench.DefaultRuns, "number of measured passes over the files")
+	configPath := fs.String("config", "", "path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt bench [OPTIONS] [dir]\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return fmt.Errorf("bench accepts at most one directory")
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	configs, err := loadConfig(*configPath, nil)
+	if err != nil {
+		return err
+	}
+	corpus, cfgs, err := loadCorpus([]string{dir}, configs)
+	if err != nil {
+		return err
+	}
+	if len(corpus) == 0 {
+		return fmt.Errorf("no markdown files found in %s", dir)
+	}
+
+	p := newPipeline(0) // measure formatting, not the cache
+	result, err := bench.Run(corpus, func(doc bench.Document) error {
+		_, _, err := p.format(context.Background(), doc.Content, cfgs[doc.Path], nil)
+		return err
+	}, *runs)
+	if err != nil {
+		return err
+	}
+	fmt.Print(result)
+	return nil
+}
+
+// loadCorpus reads the markdown files found in paths and resolves the
+// configuration of each, keyed by the file's path relative to the working
+// directory
+func loadCorpus(paths []string, configs *config.Resolver) ([]bench.Document, map[string]*config.Config, error) {
+	files, err := processor.NewFileProcessor(configs.Base(), false).FindFiles(paths)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find files: %w", err)
+	}
+
+	corpus := make([]bench.Document, 0, len(files))
+	cfgs := make(map[string]*config.Config, len(files))
+	for _, file := range files {
+		content, err := readInput(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+		cfg, err := configs.ForFile(file.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cfg, err = documentConfig(cfg, content); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+		corpus = append(corpus, bench.Document{Path: file.RelativePath, Content: content})
+		cfgs[file.RelativePath] = cfg
+	}
+	return corpus, cfgs, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gosayram/go-mdfmt/internal/version"
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+// Subcommands that share the formatting flags
+const (
+	// FmtCommand formats files; it is the default without a subcommand
+	FmtCommand = "fmt"
+	// CheckCommand reports whether files are formatted, like --check
+	CheckCommand = "check"
+	// DiffCommand shows the changes formatting would make, like --diff
+	DiffCommand = "diff"
+	// LintCommand reports unformatted files and syntax warnings by line
+	LintCommand = "lint"
+)
+
+const (
+	// VersionCommand prints version information
+	VersionCommand = "version"
+	// InitCommand writes a starter configuration file
+	InitCommand = "init"
+	// ConfigFileName is the configuration file written by init and migrate
+	ConfigFileName = ".mdfmt.yaml"
+)
+
+// commands maps subcommand names to their implementations. Without a
+// subcommand, mdfmt runs fmt, so the flags of earlier versions keep working.
+var commands = map[string]func(args []string) error{
+	FmtCommand:     func(args []string) error { return runFormat(FmtCommand, args) },
+	CheckCommand:   func(args []string) error { return runFormat(CheckCommand, args) },
+	DiffCommand:    func(args []string) error { return runFormat(DiffCommand, args) },
+	LintCommand:    func(args []string) error { return runFormat(LintCommand, args) },
+	ConfigCommand:  runConfig,
+	VersionCommand: runVersion,
+	InitCommand:    runInit,
+	SplitCommand:   runSplit,
+	MigrateCommand: runMigrate,
+	BenchCommand:   runBench,
+	ParseCommand:   runParse,
+	RenderCommand:  runRender,
+	PreviewCommand: runPreview,
+	StatsCommand:   runStats,
+	LinksCommand:   runLinks,
+	RulesCommand:   runRules,
+	JoinCommand:    runJoin,
+}
+
+// runCommand runs the subcommand named by args[0], or fmt with all of args
+// if args[0] is not a subcommand
+func runCommand(args []string) error {
+	if len(args) > 0 {
+		if run, ok := commands[args[0]]; ok {
+			return run(args[1:])
+		}
+	}
+	return runFormat(FmtCommand, args)
+}
+
+// runVersion prints version information
+func runVersion(args []string) error {
+	fs := flag.NewFlagSet(VersionCommand, flag.ContinueOnError)
+	short := fs.Bool("short", false, "print only the version number")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("version takes no arguments")
+	}
+
+	if *short {
+		fmt.Println(version.GetVersion())
+	} else {
+		fmt.Println(version.GetFullVersionInfo())
+	}
+	return nil
+}
+
+// runInit writes a starter configuration file to the current directory
+func runInit(args []string) error {
+	fs := flag.NewFlagSet(InitCommand, flag.ContinueOnError)
+	preset := fs.String("preset", "", "extend a built-in preset ("+strings.Join(config.Presets(), ", ")+
+		") instead of listing every default")
+	output := fs.String("o", ConfigFileName, `output file, or "-" for standard output`)
+	force := fs.Bool("force", false, "overwrite an existing configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt init [OPTIONS]\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("init takes no arguments")
+	}
+
+	data, err := initConfig(*preset)
+	if err != nil {
+		return err
+	}
+
+	if *output == StdinPath {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return fmt.Errorf("failed to write configuration: %w", err)
+		}
+		return nil
+	}
+	if _, err := os.Stat(*output); err == nil && !*force {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", *output)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check %s: %w", *output, err)
+	}
+	if err := os.WriteFile(*output, data, OutputFilePermissions); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", *output)
+	return nil
+}
+
+// initConfig returns the content of a starter configuration file: the
+// preset to extend, or every option with its default value
+func initConfig(preset string) ([]byte, error) {
+	header := "# mdfmt configuration: https://github.com/Gosayram/go-mdfmt/blob/main/docs/CONFIGURATION.md\n"
+	if preset != "" {
+		if !contains(config.Presets(), preset) {
+			return nil, fmt.Errorf("unknown preset %q: available presets are %s", preset, strings.Join(config.Presets(), ", "))
+		}
+		return []byte(header + "extends: [" + preset + "]\n"), nil
+	}
+
+	data, err := yaml.Marshal(config.Default())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return append([]byte(header), data...), nil
+}
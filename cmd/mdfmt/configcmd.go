@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+const (
+	// ConfigCommand is the name of the configuration tooling subcommand
+	ConfigCommand = "config"
+	// ConfigSchemaCommand prints the JSON Schema of configuration files
+	ConfigSchemaCommand = "schema"
+	// ConfigPrintCommand prints the effective configuration, like --print-config
+	ConfigPrintCommand = "print"
+)
+
+// runConfig runs a configuration tooling subcommand
+func runConfig(args []string) error {
+	fs := flag.NewFlagSet(ConfigCommand, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `USAGE:
+    mdfmt config schema
+    mdfmt config print [-v] [--format yaml|json] [--config <file>] [file]
+
+COMMANDS:
+    schema    Print a JSON Schema for .mdfmt.yaml, .mdfmt.json and .mdfmt.toml files
+    print     Print the effective configuration for a file or the current
+              directory; with -v each option names where it comes from
+`)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 && fs.Arg(0) == ConfigPrintCommand {
+		return runConfigPrint(fs.Args()[1:])
+	}
+	if fs.NArg() != 1 || fs.Arg(0) != ConfigSchemaCommand {
+		fs.Usage()
+		return fmt.Errorf("unknown or missing config command")
+	}
+
+	schema, err := config.Schema()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stdout.Write(schema); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	return nil
+}
+
+// runConfigPrint prints the effective configuration of a file
+func runConfigPrint(args []string) error {
+	fs := flag.NewFlagSet(ConfigCommand+" "+ConfigPrintCommand, flag.ContinueOnError)
+	verbose := fs.Bool("v", false, "annotate each option with where its value comes from")
+	format := fs.String("format", ConfigFormatYAML, "output format (yaml or json)")
+	configPath := fs.String("config", "", "path to configuration file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("config print accepts at most one file")
+	}
+	if *format != ConfigFormatYAML && *format != ConfigFormatJSON {
+		return fmt.Errorf("unsupported config format %q: supported formats are 'yaml' and 'json'", *format)
+	}
+
+	configs, err := loadConfig(*configPath, nil)
+	if err != nil {
+		return err
+	}
+	return printConfig(os.Stdout, configs, fs.Arg(0), *format, *verbose)
+}
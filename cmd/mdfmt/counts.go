@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/edits"
+)
+
+// formatCounts lists the changes per rule category for --rule-counts, as
+// "reflow 2, whitespace 1", in the order of edits.Categories
+func formatCounts(counts map[string]int) string {
+	parts := make([]string, 0, len(counts))
+	for _, category := range edits.Categories {
+		if n := counts[category]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", category, n))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/edits"
+)
+
+// DiffContextLines is the number of unchanged lines shown around a change
+const DiffContextLines = 3
+
+// printDiff prints the changes formatting makes to the file at path as a
+// unified diff. Changes whose context lines would overlap share a hunk.
+func printDiff(w io.Writer, path string, original []byte, formatted string) {
+	changes := edits.Diff(string(original), formatted)
+	if len(changes) == 0 {
+		return
+	}
+
+	lines := diffLines(original)
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", path, path)
+	offset := 0
+	for _, hunk := range diffHunks(changes) {
+		offset = printHunk(w, lines, hunk, offset)
+	}
+}
+
+// diffHunks groups changes into the hunks printDiff shows them in: changes
+// whose context lines would overlap share a hunk
+func diffHunks(changes []edits.Edit) [][]edits.Edit {
+	var hunks [][]edits.Edit
+	for len(changes) > 0 {
+		n := 1
+		for n < len(changes) && changes[n].StartLine-1-changes[n-1].EndLine <= 2*DiffContextLines {
+			n++
+		}
+		hunks = append(hunks, changes[:n])
+		changes = changes[n:]
+	}
+	return hunks
+}
+
+// printHunk prints changes as one unified diff hunk with DiffContextLines
+// lines of context around them. offset is the difference in line count made
+// by the earlier changes of the file; the offset after changes is returned.
+func printHunk(w io.Writer, lines []string, changes []edits.Edit, offset int) int {
+	first, last := changes[0], changes[len(changes)-1]
+	before := max(first.StartLine-1-DiffContextLines, 0)
+	after := min(last.EndLine+DiffContextLines, len(lines))
+	oldCount := after - before
+	newCount := oldCount
+	for _, change := range changes {
+		newCount += lineCount(change.Replacement) - lineCount(change.Original)
+	}
+
+	fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", before+1, oldCount, before+1+offset, newCount)
+	next := before
+	for _, change := range changes {
+		printLines(w, " ", strings.Join(lines[next:change.StartLine-1], ""))
+		printLines(w, "-", change.Original)
+		printLines(w, "+", change.Replacement)
+		next = change.EndLine
+	}
+	printLines(w, " ", strings.Join(lines[next:after], ""))
+	return offset + newCount - oldCount
+}
+
+// printLines prints each line of text with prefix, marking a last line
+// without a line break the way diff does
+func printLines(w io.Writer, prefix, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n") {
+		fmt.Fprintf(w, "%s%s\n", prefix, strings.TrimSuffix(line, "\n"))
+	}
+	if !strings.HasSuffix(text, "\n") {
+		fmt.Fprintln(w, `\ No newline at end of file`)
+	}
+}
+
+// lineCount returns the number of lines in text
+func lineCount(text string) int {
+	count := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		count++
+	}
+	return count
+}
+
+// diffLines splits content into lines, keeping their line breaks
+func diffLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
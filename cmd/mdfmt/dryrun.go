@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/Gosayram/go-mdfmt/pkg/edits"
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+)
+
+// dryRun prints what --write would do with --dry-run: one line per file
+// that would be written or is already formatted, and per path discovery
+// ignored, each with the reason why
+type dryRun struct {
+	out io.Writer
+	// written, formatted and ignored count the lines of each kind printed
+	written   int
+	formatted int
+	ignored   int
+}
+
+// newDryRun returns a dryRun printing to out
+func newDryRun(out io.Writer) *dryRun {
+	return &dryRun{out: out}
+}
+
+// ignore prints the files and directories discovery skipped
+func (r *dryRun) ignore(skipped []processor.SkippedPath) {
+	if r == nil {
+		return
+	}
+	for _, s := range skipped {
+		path := displayPath(s.Path)
+		if s.IsDirectory {
+			path += string(filepath.Separator)
+		}
+		fmt.Fprintf(r.out, "ignore %s: %s\n", path, s.Message())
+		r.ignored++
+	}
+}
+
+// file prints whether the file at path would be written, with the
+// categories of the changes, or is already formatted
+func (r *dryRun) file(path string, original []byte, formatted string, changed bool) {
+	if !changed {
+		fmt.Fprintf(r.out, "skip   %s: already formatted\n", displayPath(path))
+		r.formatted++
+		return
+	}
+	fmt.Fprintf(r.out, "write  %s: %s\n", displayPath(path), formatCounts(edits.CountCategories(string(original), formatted)))
+	r.written++
+}
+
+// summary prints the number of files of each kind, unless quiet
+func (r *dryRun) summary(quiet bool) {
+	if r == nil || quiet {
+		return
+	}
+	fmt.Fprintf(r.out, "Dry run: %d file(s) would be written, %d already formatted, %d path(s) ignored; nothing was written\n",
+		r.written, r.formatted, r.ignored)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/embedded"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+)
+
+// MinEmbeddedLineWidth is the narrowest line width embedded markdown is
+// wrapped at, however long the prefix of its lines
+const MinEmbeddedLineWidth = 20
+
+// embeddedDiscovery returns cfg extended to find the files that embed
+// markdown when embedded is set
+func embeddedDiscovery(cfg *config.Config, enabled bool) *config.Config {
+	if !enabled {
+		return cfg
+	}
+	discovery := *cfg
+	discovery.Files.Extensions = append(append([]string{}, cfg.MarkdownExtensions()...), embedded.Extensions...)
+	return &discovery
+}
+
+// formatEmbedded formats the file at path together with the markdown it
+// embeds: markdown files are formatted and then their markdown fences, while
+// only the doc comments of Go files and the configured block scalars of YAML
+// files are. The returned document holds the blocks of every region, with
+// diagnostics numbered by the lines of the file.
+func (p *pipeline) formatEmbedded(ctx context.Context, path string, content []byte, cfg *config.Config,
+	timings *processor.Timings, extra ...formatter.NodeFormatter) (*parser.Document, string, error) {
+	combined := &parser.Document{}
+	if !embedded.Supported(path) {
+		doc, formatted, err := p.format(ctx, content, cfg, timings, extra...)
+		if err != nil {
+			return nil, "", err
+		}
+		combined.Children = append(combined.Children, doc.Children...)
+		combined.Diagnostics = append(combined.Diagnostics, doc.Diagnostics...)
+		content = []byte(formatted)
+	}
+
+	regions, err := embedded.Find(path, content, embedded.Options{YAMLKeys: cfg.Embedded.YAMLKeys})
+	if err != nil {
+		return nil, "", err
+	}
+	markdown := make([]string, len(regions))
+	for i, region := range regions {
+		regionCfg := *cfg
+		if regionCfg.LineWidth > 0 {
+			regionCfg.LineWidth = max(cfg.LineWidth-len(region.Prefix), MinEmbeddedLineWidth)
+		}
+		doc, formatted, err := p.format(ctx, []byte(region.Markdown), &regionCfg, timings, extra...)
+		if err != nil {
+			return nil, "", fmt.Errorf("line %d: %w", region.Line, err)
+		}
+		markdown[i] = formatted
+
+		combined.Children = append(combined.Children, doc.Children...)
+		for _, d := range doc.Diagnostics {
+			d.Line += region.Line - 1
+			combined.Diagnostics = append(combined.Diagnostics, d)
+		}
+	}
+	return combined, string(embedded.Replace(content, regions, markdown)), nil
+}
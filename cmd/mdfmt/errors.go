@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// describeError returns err as it is reported for the file at path, which
+// may be empty. Configuration, parse and formatting errors locate
+// themselves as path:line:column: message, so they replace the context
+// wrapped around them; other errors name the file they occurred in.
+func describeError(path string, err error) error {
+	var configErr *config.ConfigError
+	var parseErr *parser.ParseError
+	var formatErr *formatter.FormatError
+	switch {
+	case errors.As(err, &configErr):
+		configErr.Path = displayPath(configErr.Path)
+		if configErr.Path == "" {
+			configErr.Path = path
+		}
+		return configErr
+	case errors.As(err, &parseErr):
+		if parseErr.Path == "" {
+			parseErr.Path = path
+		}
+		return parseErr
+	case errors.As(err, &formatErr):
+		if formatErr.Path == "" {
+			formatErr.Path = path
+		}
+		return formatErr
+	case path != "":
+		return fmt.Errorf("error processing %s: %w", path, err)
+	}
+	return err
+}
+
+// displayPath returns an absolute path relative to the working directory
+// when it is below it, as configuration files are recorded absolute
+func displayPath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
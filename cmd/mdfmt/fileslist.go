@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// readFileList reads the paths listed in the file at source, or on standard
+// input for StdinPath. Paths are separated by NUL bytes if there are any, as
+// written by `git ls-files -z` and `find -print0`, and by newlines
+// otherwise; empty entries are ignored.
+func readFileList(source string) ([]string, error) {
+	var data []byte
+	var err error
+	if source == StdinPath {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(source) // #nosec G304 - path is user provided
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file list: %w", err)
+	}
+	return splitFileList(data), nil
+}
+
+// splitFileList splits a NUL or newline separated list of paths
+func splitFileList(data []byte) []string {
+	separator := []byte("\n")
+	if bytes.IndexByte(data, 0) >= 0 {
+		separator = []byte{0}
+	}
+
+	var paths []string
+	for _, entry := range bytes.Split(data, separator) {
+		if len(separator) == 1 && separator[0] == '\n' {
+			entry = bytes.TrimSuffix(entry, []byte("\r"))
+		}
+		if len(entry) > 0 {
+			paths = append(paths, string(entry))
+		}
+	}
+	return paths
+}
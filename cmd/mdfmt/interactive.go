@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/edits"
+)
+
+// interactiveHelp explains the answers to the --interactive prompt
+const interactiveHelp = `y - apply this change
+n - skip this change
+a - apply this change and all later changes in the file
+d - skip this change and all later changes in the file
+q - quit; skip this change and all remaining changes
+? - print help
+`
+
+// prompter asks which changes to apply with --interactive, like git add -p
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// quit is set once every remaining change is to be skipped
+	quit bool
+}
+
+// newPrompter returns a prompter reading answers from in and writing
+// changes and questions to out
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// review shows each change formatting makes to the file at path and returns
+// original with the accepted changes applied. Changes are shown and applied
+// in the hunks of --diff, so a line the diff moves within a hunk is never
+// deleted without being inserted again.
+func (p *prompter) review(path string, original []byte, formatted string) ([]byte, error) {
+	changes := edits.Diff(string(original), formatted)
+	if p.quit || len(changes) == 0 {
+		return original, nil
+	}
+
+	lines := diffLines(original)
+	fmt.Fprintf(p.out, "--- %s\n+++ %s\n", path, path)
+
+	hunks := diffHunks(changes)
+	var accepted []edits.Edit
+	offset := 0
+	for i, hunk := range hunks {
+		next := printHunk(p.out, lines, hunk, offset)
+
+		answer, err := p.ask(fmt.Sprintf("(%d/%d) Apply this change [y,n,a,d,q,?]? ", i+1, len(hunks)))
+		if err != nil {
+			return nil, err
+		}
+		switch answer {
+		case "y":
+			accepted = append(accepted, hunk...)
+			// Only applied changes move the lines of the later ones
+			offset = next
+			continue
+		case "n":
+			continue
+		case "a":
+			for _, rest := range hunks[i:] {
+				accepted = append(accepted, rest...)
+			}
+		case "q":
+			p.quit = true
+		}
+		break
+	}
+
+	return edits.Apply(original, accepted), nil
+}
+
+// ask prints question until a valid answer is given and returns it. The
+// end of the input answers q.
+func (p *prompter) ask(question string) (string, error) {
+	for {
+		fmt.Fprint(p.out, question)
+		line, err := p.in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+		answer := strings.ToLower(strings.TrimSpace(line))
+		if errors.Is(err, io.EOF) && answer == "" {
+			fmt.Fprintln(p.out)
+			return "q", nil
+		}
+		switch answer {
+		case "y", "n", "a", "d", "q":
+			return answer, nil
+		}
+		fmt.Fprint(p.out, interactiveHelp)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/join"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+// JoinCommand is the name of the subcommand merging documents into one
+const JoinCommand = "join"
+
+// runJoin formats documents and writes them merged into a single document
+func runJoin(args []string) error {
+	fs := flag.NewFlagSet(JoinCommand, flag.ContinueOnError)
+	shiftHeadings := fs.Bool("shift-headings", false,
+		"demote the headings of every file after the first below its top-level headings")
+	output := fs.String("o", "", "output file (default: standard output)")
+	configPath := fs.String("config", "", "path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt join [OPTIONS] <file>...\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("join expects at least one input file")
+	}
+
+	configs, err := loadConfig(*configPath, nil)
+	if err != nil {
+		return err
+	}
+	docs := make([]*parser.Document, 0, fs.NArg())
+	var outputConfig *config.Config
+	for _, path := range fs.Args() {
+		doc, cfg, err := formatJoinInput(path, configs)
+		if err != nil {
+			return err
+		}
+		if outputConfig == nil {
+			outputConfig = cfg
+		}
+		docs = append(docs, doc)
+	}
+
+	joined := join.Join(docs, join.Options{ShiftHeadings: *shiftHeadings})
+	if *output == "" {
+		if err := renderer.New().RenderTo(os.Stdout, joined, outputConfig); err != nil {
+			return fmt.Errorf("failed to render document: %w", err)
+		}
+		return nil
+	}
+	rendered, err := renderer.New().Render(joined, outputConfig)
+	if err != nil {
+		return fmt.Errorf("failed to render document: %w", err)
+	}
+	if err := os.WriteFile(*output, []byte(rendered), OutputFilePermissions); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	return nil
+}
+
+// formatJoinInput reads and formats one input of join with its own
+// configuration
+func formatJoinInput(path string, configs *config.Resolver) (*parser.Document, *config.Config, error) {
+	input := processor.FileInfo{Path: path}
+	cfg, err := configs.ForFile(input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := readInput(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg, err = documentConfig(cfg, content); err != nil {
+		return nil, nil, err
+	}
+	doc, _, err := formatMarkdownContent(context.Background(), content, cfg, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	return doc, cfg, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/links"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// LinksCommand is the name of the link listing subcommand
+const LinksCommand = "links"
+
+// FileLink is a link of a markdown file, as listed by the links subcommand
+type FileLink struct {
+	Path string `json:"path"`
+	links.Link
+}
+
+// runLinks lists the link and image URLs of markdown files with their
+// source lines
+func runLinks(args []string) error {
+	fs := flag.NewFlagSet(LinksCommand, flag.ContinueOnError)
+	external := fs.Bool("external", false, "list only links to other sites")
+	relative := fs.Bool("relative", false, "list only relative links, such as to other files or anchors")
+	asJSON := fs.Bool("json", false, "print the links as JSON")
+	configPath := fs.String("config", "", "path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt links [OPTIONS] [paths...]\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *external && *relative {
+		return fmt.Errorf("--external and --relative are mutually exclusive")
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	configs, err := loadConfig(*configPath, nil)
+	if err != nil {
+		return err
+	}
+	corpus, cfgs, err := loadCorpus(paths, configs)
+	if err != nil {
+		return err
+	}
+	if len(corpus) == 0 {
+		return fmt.Errorf("no markdown files found in %s", strings.Join(paths, ", "))
+	}
+
+	// Links are reported at their lines in the source, so the files are
+	// parsed but not formatted
+	found := make([]FileLink, 0)
+	for _, doc := range corpus {
+		cfg := cfgs[doc.Path]
+		p := parser.NewWithOptions(parser.Options{Dialect: cfg.Dialect, Extensions: cfg.Extensions, Positions: true})
+		parsed, err := parser.ParseContext(context.Background(), p, doc.Content)
+		if err != nil {
+			return &parser.ParseError{Path: doc.Path, Err: err}
+		}
+		for _, link := range links.Extract(parsed) {
+			if (*external && !link.External()) || (*relative && link.External()) {
+				continue
+			}
+			found = append(found, FileLink{Path: doc.Path, Link: link})
+		}
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(found, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode links: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	for _, link := range found {
+		kind := ""
+		if link.Image {
+			kind = " (image)"
+		}
+		fmt.Printf("%s:%d: %s%s\n", link.Path, link.Line, link.URL, kind)
+	}
+	return nil
+}
@@ -2,17 +2,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/Gosayram/go-mdfmt/internal/version"
+	"github.com/Gosayram/go-mdfmt/pkg/baseline"
+	"github.com/Gosayram/go-mdfmt/pkg/cache"
 	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/edits"
 	"github.com/Gosayram/go-mdfmt/pkg/formatter"
 	"github.com/Gosayram/go-mdfmt/pkg/parser"
 	"github.com/Gosayram/go-mdfmt/pkg/processor"
-	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+	"github.com/Gosayram/go-mdfmt/pkg/stats"
 )
 
 const (
@@ -22,6 +34,10 @@ const (
 	ExitCodeChangesNeeded = 1
 	// OutputFilePermissions defines the file permissions for output files
 	OutputFilePermissions = 0o600
+	// StdinPath is the path argument that reads markdown from standard input
+	StdinPath = "-"
+	// StdinName names standard input in messages
+	StdinName = "<stdin>"
 )
 
 var (
@@ -31,6 +47,10 @@ var (
 	flagList  = flag.Bool("l", false, "list files that need formatting")
 	flagDiff  = flag.Bool("d", false, "show diff of changes without writing files")
 
+	flagInteractive = flag.Bool("interactive", false, "show each change and ask whether to apply it, like git add -p")
+	flagRuleCounts  = flag.Bool("rule-counts", false, "with -c/--check, count the changes each file needs per rule category")
+	flagDryRun      = flag.Bool("dry-run", false, "with -w/--write, print the files that would be written, skipped or ignored instead")
+
 	// Long versions of operation flags
 	flagWriteLong = flag.Bool("write", false, "write formatted content back to files")
 	flagCheckLong = flag.Bool("check", false, "check if files are formatted correctly (exit 1 if not)")
@@ -38,11 +58,59 @@ var (
 	flagDiffLong  = flag.Bool("diff", false, "show diff of changes without writing files")
 
 	// Configuration flags
-	flagConfig = flag.String("config", "", "path to configuration file")
+	flagConfig       = flag.String("config", "", "path to configuration file")
+	flagPrintConfig  = flag.Bool("print-config", false, "print the effective configuration for the given file and exit")
+	flagConfigFormat = flag.String("config-format", ConfigFormatYAML, "output format of --print-config (yaml or json)")
+	flagSet          setFlag
+
+	// Option flags, applied above configuration files (see optionFlags)
+	_ = flag.String("line-width", "", "maximum line width for text reflow")
+	_ = flag.String("dialect", "", "markdown flavor: commonmark, gfm, mdx or obsidian")
+	_ = flag.String("heading-style", "", "heading style: atx or setext")
+	_ = flag.String("bullet-style", "", "bullet character: -, * or +, or preserve")
+	_ = flag.String("number-style", "", "ordered list delimiter: . or ), or preserve")
+	_ = flag.String("list-indent", "", "spaces nested lists are indented by")
+	_ = flag.String("fence-style", "", "code fence style: ```, ~~~ or preserve")
+	_ = flag.String("link-style", "", "link style: preserve, inline or reference")
+	_ = flag.String("max-blank-lines", "", "maximum consecutive blank lines")
+	_ = flag.String("end-of-line", "", "line ending of formatted output: lf, crlf or cr")
+
+	// Embedded markdown flag
+	flagEmbedded = flag.Bool("embedded", false, "also format markdown in Go doc comments, YAML block scalars and markdown fences")
+
+	// Safety flag
+	flagSafe = flag.Bool("safe", false, "fail on files whose formatting would change the HTML they render to")
+
+	// Input and output flags for xargs pipelines
+	flagFilesFrom = flag.String("files-from", "", "read the paths to process from a file, or - for standard input")
+	flagPrint0    = flag.Bool("print0", false, "end each path printed by -l with a NUL byte instead of a newline")
+
+	// Error handling flags
+	flagKeepGoing = flag.Bool("keep-going", false, "continue after file errors and report them at the end (default in check mode)")
+	flagBackup    backupFlag
+	flagTimeout   = flag.Duration("timeout", 0, "skip files that take longer than this to format, e.g. 10s (0 means no limit)")
+
+	// Lint baseline flags
+	flagBaseline       = flag.String("baseline", baseline.DefaultFile, "lint: report only violations missing from this baseline file, if it exists")
+	flagUpdateBaseline = flag.Bool("update-baseline", false, "lint: record the current violations in the baseline file instead of reporting them")
+
+	// Report flags
+	flagReport     = flag.String("report", "", "print a report of the processed files in the given format (json)")
+	flagOutput     = flag.String("output", "", "print per-file results as checkstyle or junit XML")
+	flagReportFile = flag.String("report-file", "", "write the --report or --output report to a file instead of stdout")
 
 	// Output flags
-	flagVerbose = flag.Bool("v", false, "verbose output")
-	flagQuiet   = flag.Bool("q", false, "quiet mode (suppress non-error output)")
+	flagProgress      = flag.Bool("progress", false, "show progress on stderr and a summary at the end")
+	flagVerbose       = flag.Bool("v", false, "verbose output")
+	flagQuiet         = flag.Bool("q", false, "quiet mode (suppress non-error output)")
+	flagIncludeHeader = flag.Bool("include-header", false, "print ==> path <== before each file formatted to stdout")
+
+	// Performance and profiling flags
+	flagCPUProfile = flag.String("cpuprofile", "", "write a CPU profile to the given file")
+	flagMemProfile = flag.String("memprofile", "", "write a heap profile to the given file")
+	flagTrace      = flag.String("trace", "", "write an execution trace to the given file")
+	flagTimings    = flag.Bool("timings", false, "print the time spent in each phase and on the slowest files")
+	flagCacheSize  = flag.Int("cache-size", cache.DefaultSize, "number of formatting results kept for identical files (0 disables the cache)")
 
 	// Long versions of output flags
 	flagVerboseLong = flag.Bool("verbose", false, "verbose output")
@@ -62,39 +130,230 @@ type ProcessingArgs struct {
 	diff    bool
 	verbose bool
 	quiet   bool
+	// report is the format of the report or results written after the
+	// run, if any
+	report string
+	// reportFile receives the report instead of standard output, which
+	// then keeps the usual output
+	reportFile string
+	// progress displays each file as it is processed
+	progress bool
+	// keepGoing collects file errors instead of stopping at the first one
+	keepGoing bool
+	// timeout limits the time spent formatting each file; zero means no limit
+	timeout time.Duration
+	// backup, if set, keeps the originals of rewritten files
+	backup *processor.Backup
+	// print0 ends listed paths with a NUL byte
+	print0 bool
+	// includeHeader prints a header naming each file formatted to stdout,
+	// and headerPrinted records that one was printed
+	includeHeader bool
+	headerPrinted bool
+	// lint reports unformatted files and diagnostics instead of formatting
+	lint bool
+	// embedded also formats markdown embedded in Go, YAML and markdown files
+	embedded bool
+	// safe fails on files whose formatted HTML differs from the original's
+	safe bool
+	// counts totals the changes per rule category with --rule-counts, and
+	// is nil otherwise
+	counts map[string]int
+
+	// prompt asks which changes to write with --interactive
+	prompt *prompter
+	// dryRun prints what write mode would do with --dry-run, which then
+	// writes nothing
+	dryRun *dryRun
+	// timings measures the phases of the run with --timings
+	timings *processor.Timings
+	// pipeline formats the files of the run
+	pipeline *pipeline
+
+	// tx collects staged writes in write mode so they can be committed together
+	tx *processor.WriteTransaction
+	// titles resolves link targets to their titles when links.auto_title is enabled
+	titles *processor.TitleIndex
+	// baseline holds the known violations of a lint run, if any
+	baseline *lintBaseline
+	// dictionaries holds the wordlists of spellcheck.enabled
+	dictionaries *dictionaries
+}
+
+// optionFlags maps option flags to the configuration options they set
+var optionFlags = map[string]string{
+	"line-width":      "line_width",
+	"dialect":         "dialect",
+	"heading-style":   "heading.style",
+	"bullet-style":    "list.bullet_style",
+	"number-style":    "list.number_style",
+	"list-indent":     "list.indent",
+	"fence-style":     "code.fence_style",
+	"link-style":      "links.style",
+	"max-blank-lines": "whitespace.max_blank_lines",
+	"end-of-line":     "whitespace.end_of_line",
+}
+
+// setFlag collects repeated --set key=value overrides
+type setFlag []string
+
+// String returns the overrides separated by commas
+func (s *setFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set adds an override
+func (s *setFlag) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*s = append(*s, value)
+	return nil
+}
+
+// backupFlag holds --backup, which may be given without a value
+type backupFlag struct {
+	enabled bool
+	value   string
+}
+
+// String returns the suffix or directory given
+func (b *backupFlag) String() string {
+	return b.value
+}
+
+// Set enables backups; "true" is the value of a bare --backup
+func (b *backupFlag) Set(value string) error {
+	switch value {
+	case "true":
+		b.enabled, b.value = true, ""
+	case "false":
+		b.enabled, b.value = false, ""
+	default:
+		b.enabled, b.value = true, value
+	}
+	return nil
+}
+
+// IsBoolFlag lets --backup be given without a value
+func (b *backupFlag) IsBoolFlag() bool {
+	return true
+}
+
+func init() {
+	flag.Var(&flagSet, "set", "override a configuration option, as key=value (repeatable)")
+	flag.Var(&flagBackup, "backup", "with -w or --interactive, keep the original of each rewritten file as file"+
+		processor.DefaultBackupSuffix+"; --backup=SUFFIX or --backup=DIR/ to choose")
+}
+
+// override is a configuration option set on the command line
+type override struct {
+	key   string
+	value string
+}
+
+// isFlagSet reports whether the flag name was given on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// commandLineOverrides returns the configuration options set by option
+// flags followed by --set, so --set takes precedence
+func commandLineOverrides() []override {
+	var overrides []override
+	flag.Visit(func(f *flag.Flag) {
+		if key, ok := optionFlags[f.Name]; ok {
+			overrides = append(overrides, override{key: key, value: f.Value.String()})
+		}
+	})
+	for _, set := range flagSet {
+		key, value, _ := strings.Cut(set, "=")
+		overrides = append(overrides, override{key: strings.TrimSpace(key), value: value})
+	}
+	return overrides
 }
 
 func main() {
+	if err := runCommand(os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", describeError("", err))
+		}
+		os.Exit(ExitCodeError)
+	}
+}
+
+// runFormat runs the fmt, check, diff or lint subcommand with the
+// formatting flags in args
+func runFormat(mode string, args []string) error {
 	// Custom usage function
 	flag.Usage = printUsage
-	flag.Parse()
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return err
+	}
+	switch mode {
+	case CheckCommand:
+		*flagCheck = true
+	case DiffCommand:
+		*flagDiff = true
+	}
 
 	if *flagHelp || *flagHelpLong {
 		printUsage()
-		return
+		return nil
 	}
 
 	if *flagVersion {
 		fmt.Println(version.GetFullVersionInfo())
-		return
+		return nil
 	}
 
 	// Validate flag combinations
-	if err := validateFlags(); err != nil {
+	if err := validateFlags(mode); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		fmt.Fprintf(os.Stderr, "Run 'mdfmt -h' for usage information.\n")
 		os.Exit(ExitCodeError)
 	}
 
 	// Get configuration
-	cfg, err := loadConfig(*flagConfig)
+	configs, err := loadConfig(*flagConfig, commandLineOverrides())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", describeError("", err))
 		os.Exit(ExitCodeError)
 	}
 
+	if *flagPrintConfig {
+		path := ""
+		if flag.NArg() > 0 {
+			path = flag.Arg(0)
+		}
+		return printConfig(os.Stdout, configs, path, *flagConfigFormat, *flagVerbose || *flagVerboseLong)
+	}
+
 	// Get file paths
 	paths := flag.Args()
+	if runtime.GOOS == "windows" {
+		// cmd.exe and PowerShell leave patterns such as docs\*.md to the command
+		var err error
+		if paths, err = processor.ExpandGlobs(paths); err != nil {
+			return err
+		}
+	}
+	if *flagFilesFrom != "" {
+		listed, err := readFileList(*flagFilesFrom)
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 && len(listed) == 0 {
+			return nil // an empty list, such as from git ls-files, has nothing to format
+		}
+		paths = append(paths, listed...)
+	}
 	if len(paths) == 0 {
 		if !*flagQuiet {
 			fmt.Fprintf(os.Stderr, "Error: No input files or directories specified\n")
@@ -104,37 +363,109 @@ func main() {
 	}
 
 	// Process files
-	if err := processFiles(paths, cfg); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(ExitCodeError)
-	}
+	return processFiles(mode, paths, configs)
 }
 
-// validateFlags validates flag combinations
-func validateFlags() error {
-	// Count mutually exclusive operation flags
+// validateFlags validates flag combinations for the formatting subcommand mode
+func validateFlags(mode string) error {
+	// Count mutually exclusive operation flags; check, list and diff
+	// combine into one operation
 	operationCount := 0
 	if *flagWrite || *flagWriteLong {
 		operationCount++
 	}
-	if *flagCheck || *flagCheckLong {
-		operationCount++
-	}
-	if *flagList || *flagListLong {
+	if *flagCheck || *flagCheckLong || *flagList || *flagListLong || *flagDiff || *flagDiffLong {
 		operationCount++
 	}
-	if *flagDiff || *flagDiffLong {
+	if *flagInteractive {
 		operationCount++
 	}
 
 	if operationCount > 1 {
-		return fmt.Errorf("only one of -w/--write, -c/--check, -l/--list, -d/--diff can be specified")
+		return fmt.Errorf("only one of -w/--write, --interactive or a combination of -c/--check, -l/--list and -d/--diff can be specified")
+	}
+
+	if mode == LintCommand && (operationCount > 0 || *flagReport != "" || *flagOutput != "") {
+		return fmt.Errorf("lint cannot be combined with -w/--write, -c/--check, -l/--list, -d/--diff, --interactive, --report or --output")
+	}
+
+	if mode != LintCommand && (*flagUpdateBaseline || isFlagSet("baseline")) {
+		return fmt.Errorf("--baseline and --update-baseline can only be used with lint")
 	}
 
 	if (*flagVerbose || *flagVerboseLong) && (*flagQuiet || *flagQuietLong) {
 		return fmt.Errorf("-v/--verbose and -q/--quiet cannot be used together")
 	}
 
+	if (*flagWrite || *flagWriteLong) && contains(flag.Args(), StdinPath) {
+		return fmt.Errorf("-w/--write cannot be used with standard input")
+	}
+
+	if *flagInteractive && (contains(flag.Args(), StdinPath) || *flagFilesFrom == StdinPath) {
+		return fmt.Errorf("--interactive reads answers from standard input and cannot also read markdown or paths from it")
+	}
+
+	if *flagReport != "" && *flagReport != ReportFormatJSON {
+		return fmt.Errorf("unsupported report format %q: supported formats are 'json'", *flagReport)
+	}
+
+	if *flagOutput != "" && *flagOutput != OutputFormatCheckstyle && *flagOutput != OutputFormatJUnit {
+		return fmt.Errorf("unsupported output format %q: supported formats are 'checkstyle' and 'junit'", *flagOutput)
+	}
+
+	if *flagReport != "" && *flagOutput != "" {
+		return fmt.Errorf("--report and --output cannot be used together")
+	}
+
+	if *flagReportFile != "" && *flagReport == "" && *flagOutput == "" {
+		return fmt.Errorf("--report-file requires --report or --output")
+	}
+
+	if *flagConfigFormat != ConfigFormatYAML && *flagConfigFormat != ConfigFormatJSON {
+		return fmt.Errorf("unsupported config format %q: supported formats are 'yaml' and 'json'", *flagConfigFormat)
+	}
+
+	if *flagRuleCounts && !(*flagCheck || *flagCheckLong) {
+		return fmt.Errorf("--rule-counts can only be used with -c/--check")
+	}
+
+	if *flagIncludeHeader && (operationCount > 0 || mode == LintCommand ||
+		((*flagReport != "" || *flagOutput != "") && *flagReportFile == "")) {
+		return fmt.Errorf("--include-header can only be used when formatting to stdout")
+	}
+
+	if *flagPrint0 && !(*flagList || *flagListLong) {
+		return fmt.Errorf("--print0 can only be used with -l/--list")
+	}
+
+	if *flagPrint0 && (*flagDiff || *flagDiffLong) {
+		return fmt.Errorf("--print0 cannot be used with -d/--diff")
+	}
+
+	if *flagFilesFrom == StdinPath && contains(flag.Args(), StdinPath) {
+		return fmt.Errorf("--files-from - and - cannot both read standard input")
+	}
+
+	if flagBackup.enabled && !(*flagWrite || *flagWriteLong || *flagInteractive) {
+		return fmt.Errorf("--backup can only be used with -w/--write or --interactive")
+	}
+
+	if *flagDryRun && !(*flagWrite || *flagWriteLong) {
+		return fmt.Errorf("--dry-run can only be used with -w/--write")
+	}
+
+	if *flagTimeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+
+	if *flagCacheSize < 0 {
+		return fmt.Errorf("--cache-size must not be negative")
+	}
+
+	if *flagPrintConfig && flag.NArg() > 1 {
+		return fmt.Errorf("--print-config accepts at most one file")
+	}
+
 	return nil
 }
 
@@ -143,25 +474,143 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, `mdfmt - Fast, reliable Markdown formatter
 
 USAGE:
+    mdfmt <command> [OPTIONS] [arguments]
     mdfmt [OPTIONS] <files...>
+    mdfmt [OPTIONS] -
+
+COMMANDS:
+    fmt       Format files (the default when no command is given)
+    check     Check if files are formatted correctly, like --check
+    diff      Show the changes formatting would make, like --diff
+    lint      Report unformatted files and syntax warnings as path:line: message
+    config    Print the effective configuration or a JSON Schema
+    version   Print version information
+    init      Write a starter .mdfmt.yaml
+    split     Split a document into one file per section
+    join      Merge documents into one, optionally shifting their headings
+    migrate   Translate a Prettier or markdownlint configuration
+    bench     Measure formatting throughput on a directory of documents
+    parse     Print the tree a document parses into, as JSON with --ast
+    render    Render a formatted document as markdown or, with --to text, plain text
+    preview   Show a formatted document styled for the terminal
+    stats     Count headings, words, code blocks, links and images, with reading time
+    links     List link and image URLs with their file and line
+    rules     List the formatting and lint rules with their options and defaults
 
 DESCRIPTION:
     mdfmt formats Markdown files according to consistent style rules.
-    By default, formatted output is written to stdout.
+    By default, formatted output is written to stdout; several files need
+    --include-header or another operation mode.
+    With "-" as the only path, markdown is read from standard input.
+    fmt, check, diff and lint accept the options below; the operation mode
+    flags keep working without a command, as in earlier versions.
 
 OPTIONS:
-    Operation modes (mutually exclusive):
+    Operation modes (-c, -l and -d combine; the others are exclusive):
         -w, --write     Write formatted content back to files
         -c, --check     Check if files are formatted correctly (exit 1 if not)
+        --rule-counts   With -c, print how many changes each file needs per
+                        rule category (reflow, bullet-style, whitespace,
+                        heading, other) and their totals
         -l, --list      List files that need formatting
-        -d, --diff      Show diff of changes without writing files
+        -d, --diff      Show a unified diff of the changes without writing
+                        files
+        --interactive   Show each change and ask whether to apply it, like
+                        git add -p; the accepted changes are written
+        --dry-run       With -w, write nothing and print each file that
+                        would be written, is already formatted or is
+                        ignored, with the reason why
+        --backup[=SUFFIX|DIR/]
+                        With -w or --interactive, keep the original of each
+                        rewritten file as file.md.orig, with another suffix,
+                        or under a backup directory
 
     Configuration:
         --config <file> Path to configuration file (.mdfmt.yaml)
+        --print-config  Print the effective configuration for the given
+                        file (or the current directory) and exit; with -v
+                        each option names the file it comes from
+        --config-format yaml|json
+                        Output format of --print-config (default yaml)
+
+    Options (override configuration files):
+        --line-width <n>        Maximum line width for text reflow; 0 never wraps
+        --dialect <name>        Markdown flavor: commonmark, gfm, mdx or obsidian
+        --heading-style <s>     Heading style: atx or setext
+        --bullet-style <c>      Bullet character: -, * or +, or preserve
+        --number-style <c>      Ordered list delimiter: . or ), or preserve
+        --list-indent <n>       Spaces nested lists are indented by
+        --fence-style <s>       Code fence style: `+"```"+`, ~~~ or preserve
+        --link-style <s>        Link style: preserve, inline or reference
+        --max-blank-lines <n>   Maximum consecutive blank lines
+        --end-of-line <s>       Line ending: lf, crlf or cr
+        --set <key=value>       Set any configuration option, such as
+                                --set whitespace.trim_trailing_spaces=false
+                                (repeatable; takes precedence over the
+                                flags above)
+
+    Reports:
+        --report json   Print per-file statistics as JSON instead of the
+                        formatted content, list or diff output
+        --output checkstyle|junit
+                        Print per-file results (not formatted, errors and
+                        syntax warnings) as checkstyle or JUnit XML instead
+        --report-file <file>
+                        Write the report to a file and keep the usual output
+
+    Lint baseline:
+        --baseline <file>
+                        Report only violations missing from the baseline
+                        file (default .mdfmt-baseline.json, used if it
+                        exists)
+        --update-baseline
+                        Record the current violations in the baseline file
+                        instead of reporting them
+
+    File lists:
+        --files-from <file>
+                        Read the paths to process from a file, or - for
+                        standard input; NUL separated if the list contains
+                        a NUL byte (git ls-files -z), newline separated
+                        otherwise
+        --print0        End each path printed by -l with a NUL byte, for
+                        xargs -0
+        --embedded      Also format markdown embedded in other files: Go doc
+                        comments, YAML block scalars under embedded.yaml_keys
+                        and markdown fences in markdown files
+        --safe          Render each markdown file to HTML before and after
+                        formatting and fail, leaving it unchanged, if the
+                        HTML differs beyond whitespace
 
     Output control:
-        -v, --verbose   Verbose output (show processed files)
+        --keep-going    Continue after file errors, print them at the end and
+                        exit 2 once every file was attempted (default in
+                        check mode)
+        --timeout <duration>
+                        Skip files that take longer than this to format,
+                        such as 10s; 0 (the default) means no limit
+        -v, --verbose   Verbose output (show processed files and a summary)
         -q, --quiet     Quiet mode (suppress non-error output)
+        --include-header
+                        Print "==> path <==" before each file formatted to
+                        stdout, as head does, so several files can be
+                        formatted to stdout
+        --progress      Show each file as it is processed and a summary of
+                        files scanned, changed, skipped and errored on stderr
+
+    Performance:
+        --cache-size <n>
+                        Keep the results of up to n files (default 128) so
+                        files with identical content and configuration are
+                        formatted once; 0 disables the cache
+        --cpuprofile <file>
+                        Write a CPU profile for go tool pprof
+        --memprofile <file>
+                        Write a heap profile at the end of the run
+        --trace <file>  Write an execution trace for go tool trace
+        --timings       Print the time spent discovering, reading, parsing,
+                        formatting, rendering and writing files, and the
+                        slowest files, on stderr
 
     Information:
         -h, --help      Show this help message
@@ -171,16 +620,35 @@ EXAMPLES:
     Format a single file to stdout:
         mdfmt README.md
 
+    Format standard input:
+        cat README.md | mdfmt -
+
+    Format several files to stdout, each after a header with its path:
+        mdfmt --include-header docs/
+
     Format files in place:
         mdfmt --write *.md
         mdfmt -w docs/
 
     Check if files are properly formatted:
-        mdfmt --check README.md docs/
+        mdfmt check README.md docs/
         echo $?  # 0 if formatted, 1 if needs formatting
 
     Show what would change:
-        mdfmt --diff README.md
+        mdfmt diff README.md
+
+    Fail if files need formatting, listing them with their changes:
+        mdfmt --check --list --diff docs/
+
+    Report problems with line numbers:
+        mdfmt lint docs/
+
+    Adopt lint on an existing tree, then report only new problems:
+        mdfmt lint --update-baseline docs/
+        mdfmt lint docs/
+
+    Start a configuration based on a preset:
+        mdfmt init --preset github
 
     List files that need formatting:
         mdfmt --list docs/
@@ -188,12 +656,33 @@ EXAMPLES:
     Use custom configuration:
         mdfmt --config .mdfmt.yaml --write docs/
 
+    Override configuration options for one run:
+        mdfmt --line-width 100 --set links.style=reference README.md
+
+    Show where the settings for a file come from:
+        mdfmt config print -v docs/guide.md
+
+    Preview which files --write would change before a rollout:
+        mdfmt --write --dry-run .
+
     Verbose processing:
         mdfmt --verbose --write docs/
 
+    Collect documentation statistics:
+        mdfmt --report json docs/ > report.json
+
+    Split a document into one file per section with an index:
+        mdfmt split --number-prefix --index -o site/guide guide.md
+
+    Generate a JSON Schema for editor completion and validation:
+        mdfmt config schema > mdfmt.schema.json
+
+    Migrate an existing markdownlint configuration:
+        mdfmt migrate --from markdownlint
+
 EXIT CODES:
     0   Success (no changes needed in check mode)
-    1   Files need formatting (check mode only)
+    1   Files need formatting (check mode) or have problems (lint)
     2   Error occurred
 
 CONFIGURATION:
@@ -203,15 +692,21 @@ CONFIGURATION:
     3. .mdfmt.yaml in parent directories (up to repository root)
     4. Built-in defaults
 
-    Create example config: mdfmt -config example > .mdfmt.yaml
+    Configuration files in subdirectories apply to the files beneath them,
+    merged over the configuration above (unless -config is given).
+
+    Create a starter configuration: mdfmt init
 
 For more information: https://github.com/Gosayram/go-mdfmt
 `)
 }
 
-// loadConfig loads the configuration from file or defaults
-func loadConfig(configPath string) (*config.Config, error) {
+// loadConfig loads the configuration from file or defaults, then applies
+// the command-line overrides. Unless configPath is given, configuration
+// files nested below the discovered one apply to the files beneath them.
+func loadConfig(configPath string, overrides []override) (*config.Resolver, error) {
 	cfg := config.Default()
+	root := ""
 
 	if configPath != "" {
 		// Load from specified config file
@@ -230,145 +725,572 @@ func loadConfig(configPath string) (*config.Config, error) {
 			if err := cfg.LoadFromFile(configFile); err != nil {
 				return nil, fmt.Errorf("failed to load config from %s: %w", configFile, err)
 			}
+			root = filepath.Dir(configFile)
 		}
 		// If no config file found, use defaults (already set above)
 	}
 
+	for _, o := range overrides {
+		if err := cfg.Set(o.key, o.value); err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+		return nil, err
 	}
 
-	return cfg, nil
+	if configPath != "" {
+		return config.NewResolver(cfg), nil
+	}
+	return config.NewNestedResolver(cfg, root), nil
 }
 
-// createProcessingArgs creates processing arguments from flags
-func createProcessingArgs() *ProcessingArgs {
+// createProcessingArgs creates processing arguments from flags for the
+// formatting subcommand mode
+func createProcessingArgs(mode string) *ProcessingArgs {
 	verbose := *flagVerbose || *flagVerboseLong
 	quiet := *flagQuiet || *flagQuietLong
 
+	check := *flagCheck || *flagCheckLong
+
+	report := *flagReport
+	if *flagOutput != "" {
+		report = *flagOutput
+	}
+
+	var prompt *prompter
+	if *flagInteractive {
+		prompt = newPrompter(os.Stdin, os.Stdout)
+	}
+
+	var plan *dryRun
+	if *flagDryRun {
+		plan = newDryRun(os.Stdout)
+	}
+
+	var timings *processor.Timings
+	if *flagTimings {
+		timings = processor.NewTimings()
+	}
+
+	var backup *processor.Backup
+	if flagBackup.enabled {
+		b := processor.ParseBackup(flagBackup.value)
+		backup = &b
+	}
+
+	var counts map[string]int
+	if *flagRuleCounts {
+		counts = make(map[string]int)
+	}
+
 	return &ProcessingArgs{
-		write:   *flagWrite || *flagWriteLong,
-		check:   *flagCheck || *flagCheckLong,
+		write:   *flagWrite || *flagWriteLong || *flagInteractive,
+		check:   check,
 		list:    *flagList || *flagListLong,
 		diff:    *flagDiff || *flagDiffLong,
 		verbose: verbose,
 		quiet:   quiet,
+		report:  report,
+
+		reportFile: *flagReportFile,
+
+		progress:  *flagProgress && !quiet,
+		keepGoing: *flagKeepGoing || check,
+		timeout:   *flagTimeout,
+		backup:    backup,
+		prompt:    prompt,
+		dryRun:    plan,
+		timings:   timings,
+		pipeline:  newPipeline(*flagCacheSize),
+		print0:    *flagPrint0,
+		lint:      mode == LintCommand,
+		embedded:  *flagEmbedded,
+		safe:      *flagSafe,
+		counts:    counts,
+
+		includeHeader: *flagIncludeHeader,
+		dictionaries:  newDictionaries(),
 	}
 }
 
-// processFiles processes the specified files
-func processFiles(paths []string, cfg *config.Config) error {
-	args := createProcessingArgs()
-	fp := processor.NewFileProcessor(cfg, args.verbose)
+// toStdout reports whether formatted content is printed to stdout, rather
+// than written, checked, listed, diffed, linted or replaced by a report
+func (a *ProcessingArgs) toStdout() bool {
+	return !a.write && !a.check && !a.list && !a.diff && !a.lint && (a.report == "" || a.reportFile != "")
+}
+
+// processFiles processes the specified files for the formatting subcommand mode
+func processFiles(mode string, paths []string, configs *config.Resolver) error {
+	cfg := configs.Base()
+	args := createProcessingArgs(mode)
+	if args.lint {
+		var err error
+		if args.baseline, err = newLintBaseline(*flagBaseline, isFlagSet("baseline"), *flagUpdateBaseline); err != nil {
+			return err
+		}
+	}
+	fp := processor.NewFileProcessor(embeddedDiscovery(cfg, args.embedded), args.verbose)
+	if args.backup != nil && args.backup.Dir != "" {
+		fp.ExcludeDir(args.backup.Dir)
+	}
 
-	files, err := fp.FindFiles(paths)
+	stopProfiling, err := startProfiling(*flagCPUProfile, *flagMemProfile, *flagTrace)
 	if err != nil {
-		return fmt.Errorf("failed to find files: %w", err)
+		return err
 	}
+	defer stopProfiling()
+
+	// An interrupt stops the run between files; staged writes are rolled back
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stopDiscover := args.timings.Start(processor.PhaseDiscover)
+	found, err := findInputs(ctx, fp, paths)
+	stopDiscover()
+	if err != nil {
+		return err
+	}
+	files := found.Files
+	if args.verbose && !args.quiet {
+		for _, skipped := range found.Skipped {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %s\n", displayPath(skipped.Path), skipped.Message())
+		}
+	}
+	args.dryRun.ignore(found.Skipped)
 
 	if len(files) == 0 {
 		if args.verbose && !args.quiet {
 			fmt.Println("No markdown files found")
 		}
+		args.dryRun.summary(args.quiet)
 		return nil
 	}
+	if len(files) > 1 && args.toStdout() && !args.includeHeader {
+		return fmt.Errorf("%d files would be formatted to stdout one after another: "+
+			"use --include-header to separate them, or -w/--write, -l/--list or -d/--diff", len(files))
+	}
+
+	var progressOutput io.Writer
+	if args.progress {
+		progressOutput = os.Stderr
+	}
+	progress := processor.NewProgress(progressOutput, len(files), isTerminal(os.Stderr))
+	progress.Skip(fp.Skipped())
+
+	if cfg.Links.AutoTitle {
+		args.titles = processor.NewTitleIndex(args.pipeline.Parser(cfg), cfg.MarkdownExtensions())
+	}
+
+	if args.write && args.dryRun == nil {
+		args.tx = processor.NewWriteTransaction()
+		if args.backup != nil {
+			args.tx.SetBackup(*args.backup)
+		}
+	}
+
+	hasChanges, err := formatFiles(ctx, files, configs, args, progress)
+	if args.progress || (args.verbose && !args.quiet) {
+		fmt.Fprintf(os.Stderr, "Summary: %s\n", progress.Finish())
+	}
+	args.timings.WriteSummary(os.Stderr, SlowestFilesShown)
+	if err != nil {
+		return err
+	}
+	if err := args.baseline.save(args.quiet); err != nil {
+		return err
+	}
+	args.dryRun.summary(args.quiet)
+	if len(args.counts) > 0 && !args.quiet && (args.report == "" || args.reportFile != "") {
+		fmt.Printf("total: %s\n", formatCounts(args.counts))
+	}
+
+	// Handle check and lint mode exit code
+	if (args.check || args.lint) && hasChanges {
+		stopProfiling()
+		os.Exit(ExitCodeChangesNeeded)
+	}
+
+	return nil
+}
 
+// formatFiles formats files, recording each in progress, and reports
+// whether any of them needed formatting. With keepGoing, file errors are
+// collected and printed after every file was attempted. Once ctx is done,
+// no further file is started and staged writes are rolled back.
+func formatFiles(ctx context.Context, files []processor.FileInfo, configs *config.Resolver, args *ProcessingArgs,
+	progress *processor.Progress) (bool, error) {
 	var hasChanges bool
+	report := &Report{Files: make([]FileReport, 0, len(files))}
+	crashes := 0
+	var failures []error
 	for _, file := range files {
-		changed, err := processFile(file, cfg, args)
+		if ctx.Err() != nil {
+			return hasChanges, interrupted(args)
+		}
+		progress.Start(file.RelativePath)
+		args.timings.StartFile(file.RelativePath)
+		changed, err := processFile(ctx, file, configs, args, report)
+		args.timings.EndFile()
+		if ctx.Err() != nil {
+			progress.SkipCurrent()
+			return hasChanges, interrupted(args)
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			progress.SkipCurrent()
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: formatting took longer than %s\n", file.Path, args.timeout)
+			continue
+		}
+		progress.Done(changed, err)
+		var crash *processor.Crash
+		if errors.As(err, &crash) {
+			crashes++
+			report.AddError(file.RelativePath, err)
+			continue
+		}
 		if err != nil {
-			return fmt.Errorf("error processing %s: %w", file.Path, err)
+			report.AddError(file.RelativePath, err)
+			err = describeError(file.Path, err)
+			if args.keepGoing {
+				failures = append(failures, err)
+				continue
+			}
+			if args.tx != nil {
+				if rbErr := args.tx.Rollback(); rbErr != nil {
+					err = fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+				}
+			}
+			return hasChanges, err
 		}
 		if changed {
 			hasChanges = true
 		}
 	}
 
-	// Handle check mode exit code
-	if args.check && hasChanges {
-		os.Exit(ExitCodeChangesNeeded)
+	if args.tx != nil {
+		stopWrite := args.timings.Start(processor.PhaseWrite)
+		err := args.tx.Commit()
+		stopWrite()
+		if err != nil {
+			return hasChanges, fmt.Errorf("failed to write files, changes rolled back: %w", err)
+		}
+		for _, path := range args.tx.Skipped() {
+			warnModifiedOnDisk(path)
+		}
 	}
 
-	return nil
+	if args.report != "" {
+		if err := report.WriteFile(args.reportFile, args.report); err != nil {
+			return hasChanges, err
+		}
+	}
+
+	for _, err := range failures {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+
+	switch {
+	case len(failures) > 0:
+		return hasChanges, fmt.Errorf("%d file(s) could not be processed", len(failures)+crashes)
+	case crashes > 0:
+		return hasChanges, fmt.Errorf("%d file(s) could not be formatted because mdfmt crashed", crashes)
+	}
+	return hasChanges, nil
 }
 
-// processFile processes a single file
-func processFile(file processor.FileInfo, cfg *config.Config, args *ProcessingArgs) (bool, error) {
+// interrupted rolls back the writes staged by an interrupted run and
+// returns the error it exits with
+func interrupted(args *ProcessingArgs) error {
+	if args.tx == nil {
+		return errors.New("interrupted")
+	}
+	if err := args.tx.Rollback(); err != nil {
+		return fmt.Errorf("interrupted, rollback failed: %w", err)
+	}
+	return errors.New("interrupted, no files were changed")
+}
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// findInputs returns the files to process and the paths skipped. A single
+// "-" path reads from standard input.
+func findInputs(ctx context.Context, fp *processor.FileProcessor, paths []string) (*processor.Discovery, error) {
+	if contains(paths, StdinPath) {
+		if len(paths) > 1 {
+			return nil, fmt.Errorf("standard input (-) cannot be combined with other paths")
+		}
+		return &processor.Discovery{Files: []processor.FileInfo{{Path: StdinPath, RelativePath: StdinName}}}, nil
+	}
+
+	found, err := fp.Discover(ctx, paths)
+	if ctx.Err() != nil {
+		return nil, errors.New("interrupted")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files: %w", err)
+	}
+	return found, nil
+}
+
+// readInput reads the content of file, or of standard input for StdinPath
+func readInput(file processor.FileInfo) ([]byte, error) {
+	if file.Path == StdinPath {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read standard input: %w", err)
+		}
+		return content, nil
+	}
+
 	content, err := os.ReadFile(file.Path)
 	if err != nil {
-		return false, fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	return content, nil
+}
 
-	formatted, err := formatMarkdownContent(content, cfg)
+// processFile processes a single file, adding it to report when a report was requested.
+// A panic is recovered and returned as a *processor.Crash. If formatting
+// takes longer than args.timeout, context.DeadlineExceeded is returned.
+func processFile(ctx context.Context, file processor.FileInfo, configs *config.Resolver, args *ProcessingArgs,
+	report *Report) (changed bool, err error) {
+	stopRead := args.timings.Start(processor.PhaseRead)
+	content, err := readInput(file)
+	stopRead()
 	if err != nil {
 		return false, err
 	}
 
-	changed := hasContentChanged(content, formatted)
+	name := file.Path
+	cfg := configs.Base()
+	if file.Path == StdinPath {
+		name = StdinName
+	} else if cfg, err = configs.ForFile(file.Path); err != nil {
+		return false, err
+	}
+	if cfg, err = documentConfig(cfg, content); err != nil {
+		return false, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			changed, err = false, reportCrash(&processor.Crash{Path: name, Value: r, Stack: debug.Stack()}, content, cfg)
+		}
+	}()
+
+	var extra []formatter.NodeFormatter
+	if args.titles != nil {
+		extra = append(extra, formatter.NewLinkTitleFormatter(formatter.TitleResolverFunc(
+			func(destination string) (string, bool) {
+				return args.titles.Title(file.Path, destination)
+			})))
+	}
+	if cfg.Images.CheckPaths {
+		extra = append(extra, formatter.NewImagePathFormatter(formatter.PathResolverFunc(
+			func(destination string) bool {
+				target, ok := processor.LocalPath(file.Path, destination)
+				if !ok {
+					return true
+				}
+				_, err := os.Stat(target)
+				return err == nil
+			})))
+	}
+	if cfg.Spellcheck.Enabled {
+		checker, err := args.dictionaries.checker(cfg)
+		if err != nil {
+			return false, err
+		}
+		extra = append(extra, formatter.NewSpellcheckFormatter(checker))
+	}
+
+	if args.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, args.timeout)
+		defer cancel()
+	}
+	format := args.pipeline.formatterFor(file.Path, args.embedded)
+	result := formatInBackground(ctx, format, content, cfg, args.timings, extra...)
+	if result.panic != nil {
+		return false, reportCrash(&processor.Crash{Path: name, Value: result.panic, Stack: result.stack}, content, cfg)
+	}
+	var conversionErr *parser.ConversionError
+	if errors.As(result.err, &conversionErr) {
+		return false, reportCrash(&processor.Crash{Path: name, Value: conversionErr.Value, Stack: conversionErr.Stack},
+			content, cfg)
+	}
+	if result.err != nil {
+		return false, result.err
+	}
+	doc, formatted := result.doc, result.formatted
+
+	changed = hasContentChanged(content, formatted)
+
+	if args.safe && changed {
+		if err := args.pipeline.checkSafe(file.Path, args.embedded, content, formatted, cfg); err != nil {
+			return false, err
+		}
+	}
+
+	if args.lint {
+		diagnostics := doc.Diagnostics
+		if len(cfg.Plugins) > 0 && pluginsApply(file.Path, args.embedded) {
+			found, err := runPlugins(ctx, cfg, name, content)
+			if err != nil {
+				return false, err
+			}
+			diagnostics = append(append([]parser.Diagnostic(nil), diagnostics...), found...)
+		}
+		diagnostics, unformatted := args.baseline.check(file.Path, changed, diagnostics)
+		return lintFile(name, unformatted, diagnostics), nil
+	}
+
+	if !args.quiet {
+		warnDiagnostics(name, doc.Diagnostics)
+	}
+
+	var changes map[string]int
+	if args.counts != nil && changed {
+		changes = edits.CountCategories(string(content), formatted)
+		for category, n := range changes {
+			args.counts[category] += n
+		}
+	}
+
+	if args.report != "" {
+		report.Add(file.RelativePath, changed, changes, stats.Collect(doc, content, formatted, cfg.LineWidth),
+			doc.Diagnostics)
+		if !args.write && args.reportFile == "" {
+			return changed, nil
+		}
+	}
+
+	if changes != nil && !args.quiet {
+		fmt.Printf("%s: %s\n", name, formatCounts(changes))
+	}
 
 	if args.verbose && !args.quiet && changed {
-		fmt.Printf("File %s will be reformatted\n", file.Path)
+		fmt.Printf("File %s will be reformatted\n", name)
 	}
 
-	if err := handleFileOutput(file.Path, formatted, changed, args); err != nil {
+	if err := handleFileOutput(name, content, formatted, changed, args); err != nil {
 		return false, err
 	}
 
 	return changed, nil
 }
 
-// formatMarkdownContent processes markdown content through parse -> format -> render pipeline
-func formatMarkdownContent(content []byte, cfg *config.Config) (string, error) {
-	p := parser.DefaultParser()
-	doc, err := p.Parse(content)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse markdown: %w", err)
+// documentConfig returns cfg with the options in the front matter of
+// content applied
+func documentConfig(cfg *config.Config, content []byte) (*config.Config, error) {
+	data, ok := parser.FrontMatterData(content)
+	if !ok {
+		return cfg, nil
 	}
+	return cfg.WithFrontMatter(data)
+}
 
-	engine := formatter.New()
-	engine.RegisterDefaults()
-
-	if formatErr := engine.Format(doc, cfg); formatErr != nil {
-		return "", fmt.Errorf("failed to format document: %w", formatErr)
-	}
+// formatResult is the outcome of formatInBackground
+type formatResult struct {
+	doc       *parser.Document
+	formatted string
+	err       error
+	// panic and stack describe a recovered panic
+	panic interface{}
+	stack []byte
+}
 
-	mdRenderer := renderer.New()
-	formatted, err := mdRenderer.Render(doc, cfg)
-	if err != nil {
-		return "", fmt.Errorf("failed to render document: %w", err)
+// formatInBackground formats content with format in a goroutine so a file
+// can be given up on as soon as ctx is done, even while goldmark is still
+// parsing it. The abandoned goroutine stops at the next context check.
+func formatInBackground(ctx context.Context, format formatFunc, content []byte, cfg *config.Config,
+	timings *processor.Timings, extra ...formatter.NodeFormatter) formatResult {
+	done := make(chan formatResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- formatResult{panic: r, stack: debug.Stack()}
+			}
+		}()
+		doc, formatted, err := format(ctx, content, cfg, timings, extra...)
+		done <- formatResult{doc: doc, formatted: formatted, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		return formatResult{err: ctx.Err()}
 	}
+}
 
-	return formatted, nil
+// formatMarkdownContent processes markdown content through parse -> format -> render pipeline,
+// returning the formatted document and its rendering. Runs over many files
+// should share a pipeline instead.
+func formatMarkdownContent(ctx context.Context, content []byte, cfg *config.Config, timings *processor.Timings,
+	extra ...formatter.NodeFormatter) (*parser.Document, string, error) {
+	return newPipeline(0).format(ctx, content, cfg, timings, extra...)
 }
 
-// hasContentChanged checks if the content has been modified after formatting
+// hasContentChanged checks if the content has been modified after
+// formatting, including its final newline
 func hasContentChanged(original []byte, formatted string) bool {
-	originalContent := strings.TrimSpace(string(original))
-	formattedContent := strings.TrimSpace(formatted)
-	return originalContent != formattedContent
+	return string(original) != formatted
 }
 
 // handleFileOutput handles different output modes based on processing arguments
-func handleFileOutput(filePath, formatted string, changed bool, args *ProcessingArgs) error {
+func handleFileOutput(filePath string, original []byte, formatted string, changed bool, args *ProcessingArgs) error {
 	switch {
 	case args.write:
-		return handleWriteMode(filePath, formatted, changed, args)
-	case args.check:
-		return handleCheckMode(filePath, changed, args)
-	case args.list:
-		return handleListMode(filePath, changed)
-	case args.diff:
-		return handleDiffMode(filePath, changed)
+		return handleWriteMode(filePath, original, formatted, changed, args)
+	case args.check || args.list || args.diff:
+		// Each of check, list and diff adds its output, in that order
+		if args.list {
+			handleListMode(filePath, changed, args)
+		}
+		if args.diff {
+			handleDiffMode(filePath, original, formatted)
+		}
+		if args.check {
+			handleCheckMode(filePath, changed, args)
+		}
+		return nil
 	default:
-		return handleStdoutMode(formatted)
+		return handleStdoutMode(filePath, formatted, args)
 	}
 }
 
-// handleWriteMode writes formatted content back to file
-func handleWriteMode(filePath, formatted string, changed bool, args *ProcessingArgs) error {
+// handleWriteMode stages formatted content to be written back to file. With
+// --interactive, only the changes accepted at the prompt are written; with
+// --dry-run, the file is only reported.
+func handleWriteMode(filePath string, original []byte, formatted string, changed bool, args *ProcessingArgs) error {
+	if args.dryRun != nil {
+		args.dryRun.file(filePath, original, formatted, changed)
+		return nil
+	}
+	if changed && args.prompt != nil {
+		accepted, err := args.prompt.review(filePath, original, formatted)
+		if err != nil {
+			return err
+		}
+		formatted = string(accepted)
+		changed = formatted != string(original)
+	}
 	if changed {
-		if err := os.WriteFile(filePath, []byte(formatted), OutputFilePermissions); err != nil {
+		stopWrite := args.timings.Start(processor.PhaseWrite)
+		err := args.tx.Stage(filePath, original, []byte(formatted))
+		stopWrite()
+		if err != nil {
+			if errors.Is(err, processor.ErrModifiedOnDisk) {
+				warnModifiedOnDisk(filePath)
+				return nil
+			}
 			return fmt.Errorf("failed to write file: %w", err)
 		}
 		if args.verbose && !args.quiet {
@@ -380,33 +1302,100 @@ func handleWriteMode(filePath, formatted string, changed bool, args *ProcessingA
 	return nil
 }
 
-// handleCheckMode handles check mode output
-func handleCheckMode(filePath string, changed bool, args *ProcessingArgs) error {
+// lintFile prints the problems of a file, one per line as "path:line:
+// message", and reports whether it has any
+func lintFile(filePath string, changed bool, diagnostics []parser.Diagnostic) bool {
+	for _, d := range diagnostics {
+		fmt.Printf("%s:%d: %s\n", filePath, d.Line, d.Message)
+	}
+	if changed {
+		fmt.Printf("%s: not formatted\n", filePath)
+	}
+	return changed || len(diagnostics) > 0
+}
+
+// warnDiagnostics reports regions that were copied verbatim because their
+// syntax is not enabled, and the problems found by formatters
+func warnDiagnostics(filePath string, diagnostics []parser.Diagnostic) {
+	for _, d := range diagnostics {
+		fmt.Fprintf(os.Stderr, "Warning: %s:%d: %s\n", filePath, d.Line, d.Message)
+	}
+}
+
+// reportCrash writes a reproduction bundle for crash and tells the user
+// where to find it. It returns crash so the run can continue with the next file.
+func reportCrash(crash *processor.Crash, content []byte, cfg *config.Config) error {
+	fmt.Fprintf(os.Stderr, "Error: mdfmt crashed while formatting %s: %v\n", crash.Path, crash.Value)
+
+	// Panics the parser recovers from are raised again, so the bundle is
+	// minimized to the block that causes them
+	bundle, err := processor.WriteCrashBundle(crash, content, cfg, func(content []byte) {
+		_, _, err := formatMarkdownContent(context.Background(), content, cfg, nil)
+		var conversionErr *parser.ConversionError
+		if errors.As(err, &conversionErr) {
+			panic(conversionErr.Value)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write crash reproduction: %v\n", err)
+		return crash
+	}
+
+	fmt.Fprintf(os.Stderr, "A reproduction was written to %s\n", bundle.Dir)
+	if !bundle.Redacted {
+		fmt.Fprintf(os.Stderr, "It contains unredacted text from %s; review it before sharing.\n", crash.Path)
+	}
+	fmt.Fprintf(os.Stderr, "Please attach it to a bug report at https://github.com/Gosayram/go-mdfmt/issues\n")
+	return crash
+}
+
+// warnModifiedOnDisk reports a file that was skipped because it changed while being formatted
+func warnModifiedOnDisk(filePath string) {
+	fmt.Fprintf(os.Stderr, "Warning: %s changed on disk during formatting, skipping\n", filePath)
+}
+
+// handleCheckMode handles check mode output; the exit code reports
+// whether any file changed
+func handleCheckMode(filePath string, changed bool, args *ProcessingArgs) {
 	if changed && args.verbose && !args.quiet {
 		fmt.Printf("would reformat %s\n", filePath)
 	}
-	return nil
 }
 
 // handleListMode handles list mode output
-func handleListMode(filePath string, changed bool) error {
-	if changed {
+func handleListMode(filePath string, changed bool, args *ProcessingArgs) {
+	if changed && args.print0 {
+		fmt.Printf("%s\x00", filePath)
+	} else if changed {
 		fmt.Println(filePath)
 	}
-	return nil
 }
 
-// handleDiffMode handles diff mode output
-func handleDiffMode(filePath string, changed bool) error {
-	if changed {
-		fmt.Printf("--- %s\n+++ %s\n", filePath, filePath)
-		fmt.Println("File would be reformatted")
-	}
-	return nil
+// handleDiffMode prints the changes formatting makes as a unified diff
+func handleDiffMode(filePath string, original []byte, formatted string) {
+	printDiff(os.Stdout, filePath, original, formatted)
 }
 
-// handleStdoutMode writes formatted content to stdout
-func handleStdoutMode(formatted string) error {
+// handleStdoutMode writes formatted content to stdout, after a header
+// naming the file with --include-header
+func handleStdoutMode(filePath, formatted string, args *ProcessingArgs) error {
+	if args.includeHeader {
+		if args.headerPrinted {
+			fmt.Println()
+		}
+		fmt.Printf("==> %s <==\n", filePath)
+		args.headerPrinted = true
+	}
 	fmt.Print(formatted)
 	return nil
 }
+
+// contains checks if a slice contains a string
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Gosayram/go-mdfmt/pkg/migrate"
+)
+
+// MigrateCommand is the name of the configuration migration subcommand
+const MigrateCommand = "migrate"
+
+// runMigrate translates a Prettier or markdownlint configuration into an
+// mdfmt configuration file and reports the settings it could not translate
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet(MigrateCommand, flag.ContinueOnError)
+	from := fs.String("from", "", "tool to migrate from: prettier or markdownlint")
+	input := fs.String("input", "", "configuration file to migrate (default: found in the current directory)")
+	output := fs.String("o", ConfigFileName, `output file, or "-" for standard output`)
+	force := fs.Bool("force", false, "overwrite an existing output file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt migrate --from prettier|markdownlint [OPTIONS]\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *from == "" {
+		fs.Usage()
+		return fmt.Errorf("migrate expects --from and no arguments")
+	}
+
+	path := *input
+	if path == "" {
+		var err error
+		if path, err = migrate.FindConfig(*from, "."); err != nil {
+			return err
+		}
+	}
+	result, err := migrate.File(*from, path)
+	if err != nil {
+		return err
+	}
+	if err := result.Validate(); err != nil {
+		return fmt.Errorf("migrated configuration is invalid: %w", err)
+	}
+	data, err := result.YAML(path)
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return fmt.Errorf("failed to write configuration: %w", err)
+		}
+	} else {
+		if _, err := os.Stat(*output); err == nil && !*force {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", *output)
+		}
+		if err := os.WriteFile(*output, data, OutputFilePermissions); err != nil {
+			return fmt.Errorf("failed to write %s: %w", *output, err)
+		}
+		fmt.Fprintf(os.Stderr, "Migrated %s to %s\n", path, *output)
+	}
+
+	if len(result.Unmapped) > 0 {
+		fmt.Fprintf(os.Stderr, "Settings without an mdfmt equivalent:\n")
+		for _, note := range result.Unmapped {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", note.Setting, note.Reason)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/notebook"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+)
+
+// formatNotebook formats the markdown cells of a Jupyter notebook, leaving
+// its other cells, outputs and metadata untouched. The returned document
+// holds the blocks of all markdown cells; the lines of its diagnostics are
+// relative to their cell.
+func (p *pipeline) formatNotebook(ctx context.Context, content []byte, cfg *config.Config, timings *processor.Timings,
+	extra ...formatter.NodeFormatter) (*parser.Document, string, error) {
+	nb, err := notebook.Parse(content)
+	if err != nil {
+		return nil, "", err
+	}
+
+	combined := &parser.Document{}
+	for _, cell := range nb.MarkdownCells() {
+		if strings.TrimSpace(cell.Source) == "" {
+			continue
+		}
+		doc, formatted, err := p.format(ctx, []byte(cell.Source), cfg, timings, extra...)
+		if err != nil {
+			return nil, "", fmt.Errorf("cell %d: %w", cell.Index+1, err)
+		}
+		// Cells do not end with a newline, unless they were written with one
+		if !strings.HasSuffix(cell.Source, "\n") {
+			formatted = strings.TrimRight(formatted, "\r\n")
+		}
+		cell.Source = formatted
+
+		combined.Children = append(combined.Children, doc.Children...)
+		for _, d := range doc.Diagnostics {
+			d.Message = fmt.Sprintf("cell %d: %s", cell.Index+1, d.Message)
+			combined.Diagnostics = append(combined.Diagnostics, d)
+		}
+	}
+	return combined, string(nb.Bytes()), nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+)
+
+// ParseCommand is the name of the subcommand printing the parsed document
+const ParseCommand = "parse"
+
+// runParse prints the tree mdfmt parses a document into, without
+// formatting it
+func runParse(args []string) error {
+	fs := flag.NewFlagSet(ParseCommand, flag.ContinueOnError)
+	asJSON := fs.Bool("ast", false, "print the tree as JSON, with node types, fields, lines and children")
+	configPath := fs.String("config", "", "path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt parse [OPTIONS] <file|->\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("parse expects exactly one input file")
+	}
+	input := processor.FileInfo{Path: fs.Arg(0)}
+
+	configs, err := loadConfig(*configPath, nil)
+	if err != nil {
+		return err
+	}
+	cfg, err := configs.ForFile(input.Path)
+	if err != nil {
+		return err
+	}
+	content, err := readInput(input)
+	if err != nil {
+		return err
+	}
+	if cfg, err = documentConfig(cfg, content); err != nil {
+		return err
+	}
+
+	p := parser.NewWithOptions(parser.Options{
+		Dialect:    cfg.Dialect,
+		Extensions: cfg.Extensions,
+		Positions:  true,
+	})
+	doc, err := parser.ParseContext(context.Background(), p, content)
+	if err != nil {
+		return &parser.ParseError{Err: err}
+	}
+
+	if !*asJSON {
+		fmt.Print(parser.DebugString(doc))
+		return nil
+	}
+	ast, err := parser.MarshalAST(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode the tree: %w", err)
+	}
+	_, err = os.Stdout.Write(ast)
+	return err
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+
+	"github.com/Gosayram/go-mdfmt/pkg/cache"
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/embedded"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/mdfmt"
+	"github.com/Gosayram/go-mdfmt/pkg/notebook"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+	"github.com/Gosayram/go-mdfmt/pkg/safety"
+)
+
+// pipeline formats the files of a run with the pipeline of the mdfmt
+// package, which shares its parsers and formatting engine between them,
+// caching the results. It is safe for concurrent use.
+type pipeline struct {
+	*mdfmt.Pipeline
+	// results caches the results of formatting without extra formatters
+	results *cache.Cache
+}
+
+// newPipeline returns a pipeline with the default formatters, caching up to
+// cacheSize results (none when 0)
+func newPipeline(cacheSize int) *pipeline {
+	return &pipeline{
+		Pipeline: mdfmt.NewPipeline(),
+		results:  cache.New(cacheSize),
+	}
+}
+
+// formatFunc formats the content of a file, returning the formatted
+// document and the new content of the file
+type formatFunc func(ctx context.Context, content []byte, cfg *config.Config, timings *processor.Timings,
+	extra ...formatter.NodeFormatter) (*parser.Document, string, error)
+
+// formatterFor returns the function formatting the file at path: Jupyter
+// notebooks have their markdown cells formatted and, with embedded, the
+// markdown in other files is formatted in place
+func (p *pipeline) formatterFor(path string, embedded bool) formatFunc {
+	switch {
+	case notebook.IsNotebook(path):
+		return p.formatNotebook
+	case embedded:
+		return func(ctx context.Context, content []byte, cfg *config.Config, timings *processor.Timings,
+			extra ...formatter.NodeFormatter) (*parser.Document, string, error) {
+			return p.formatEmbedded(ctx, path, content, cfg, timings, extra...)
+		}
+	default:
+		return p.format
+	}
+}
+
+// format formats content like mdfmt.Pipeline.Format, returning the
+// formatted document and its rendering. Results are cached unless extra formatters are given, since those may
+// depend on more than the content; the cached document must not be modified.
+func (p *pipeline) format(ctx context.Context, content []byte, cfg *config.Config, timings *processor.Timings,
+	extra ...formatter.NodeFormatter) (*parser.Document, string, error) {
+	var key cache.Key
+	cached := p.results != nil && len(extra) == 0
+	if cached {
+		var err error
+		if key, err = cache.NewKey(content, cfg); err != nil {
+			return nil, "", err
+		}
+		if entry, ok := p.results.Get(key); ok {
+			return entry.Document, entry.Output, nil
+		}
+	}
+
+	doc, formatted, err := p.Pipeline.Format(ctx, content, cfg, timings, extra...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cached {
+		p.results.Add(key, cache.Entry{Document: doc, Output: formatted})
+	}
+	return doc, formatted, nil
+}
+
+// checkSafe returns a *safety.Error if formatted renders to different HTML
+// than content, ignoring format version comments. Only markdown files are
+// checked: notebooks and the files embedded markdown is formatted in are
+// not HTML documents.
+func (p *pipeline) checkSafe(path string, embed bool, content []byte, formatted string, cfg *config.Config) error {
+	if notebook.IsNotebook(path) || (embed && embedded.Supported(path)) {
+		return nil
+	}
+	r, ok := p.Parser(cfg).(safety.HTMLRenderer)
+	if !ok {
+		return nil
+	}
+	return safety.Check(r, formatter.StripFormatVersionComments(content),
+		formatter.StripFormatVersionComments([]byte(formatted)))
+}
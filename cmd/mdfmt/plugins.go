@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/embedded"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/notebook"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/plugin"
+)
+
+// pluginsApply reports whether plugins check the file at path: they read
+// markdown documents, not notebooks or files embedding markdown
+func pluginsApply(path string, embeddedMode bool) bool {
+	return !notebook.IsNotebook(path) && !(embeddedMode && embedded.Supported(path))
+}
+
+// runPlugins runs the plugins of cfg on the markdown file at path, whose
+// source is content, and returns the problems they report that no
+// suppression comment turns off
+func runPlugins(ctx context.Context, cfg *config.Config, path string, content []byte) ([]parser.Diagnostic, error) {
+	var doc *parser.Document
+	for _, p := range cfg.Plugins {
+		if p.Input == plugin.InputText || doc != nil {
+			continue
+		}
+		ps := parser.NewWithOptions(parser.Options{
+			Dialect:    cfg.Dialect,
+			Extensions: cfg.Extensions,
+			Positions:  true,
+		})
+		var err error
+		if doc, err = parser.ParseContext(ctx, ps, content); err != nil {
+			return nil, &parser.ParseError{Err: err}
+		}
+	}
+
+	var diagnostics []parser.Diagnostic
+	for _, p := range cfg.Plugins {
+		found, err := plugin.Run(ctx, p, path, content, doc)
+		if err != nil {
+			return nil, err
+		}
+		diagnostics = append(diagnostics, found...)
+	}
+	return formatter.ParseSuppressions(content).Filter(diagnostics), nil
+}
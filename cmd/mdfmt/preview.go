@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+// PreviewCommand is the name of the subcommand showing a formatted document
+// styled for the terminal
+const PreviewCommand = "preview"
+
+// Values of the preview --color flag
+const (
+	// ColorAuto styles the output when it is a terminal and NO_COLOR is unset
+	ColorAuto = "auto"
+	// ColorAlways always styles the output
+	ColorAlways = "always"
+	// ColorNever never styles the output
+	ColorNever = "never"
+)
+
+// runPreview formats a document and shows the result styled for reading in
+// a terminal
+func runPreview(args []string) error {
+	fs := flag.NewFlagSet(PreviewCommand, flag.ContinueOnError)
+	color := fs.String("color", ColorAuto, "style the output: auto, always or never")
+	configPath := fs.String("config", "", "path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt preview [OPTIONS] <file|->\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("preview expects exactly one input file")
+	}
+	var styled bool
+	switch *color {
+	case ColorAuto:
+		_, noColor := os.LookupEnv("NO_COLOR")
+		styled = !noColor && isTerminal(os.Stdout)
+	case ColorAlways:
+		styled = true
+	case ColorNever:
+		styled = false
+	default:
+		return fmt.Errorf("invalid --color %q, expected auto, always or never", *color)
+	}
+	input := processor.FileInfo{Path: fs.Arg(0)}
+
+	configs, err := loadConfig(*configPath, nil)
+	if err != nil {
+		return err
+	}
+	cfg, err := configs.ForFile(input.Path)
+	if err != nil {
+		return err
+	}
+	content, err := readInput(input)
+	if err != nil {
+		return err
+	}
+	if cfg, err = documentConfig(cfg, content); err != nil {
+		return err
+	}
+	doc, _, err := formatMarkdownContent(context.Background(), content, cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := renderer.NewTerminal(styled).RenderTo(os.Stdout, doc, cfg); err != nil {
+		return fmt.Errorf("failed to render document: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+// Config output formats of --print-config
+const (
+	// ConfigFormatYAML prints the configuration as YAML
+	ConfigFormatYAML = "yaml"
+	// ConfigFormatJSON prints the configuration as JSON
+	ConfigFormatJSON = "json"
+)
+
+// configWithSources is the verbose JSON output of --print-config
+type configWithSources struct {
+	Config  *config.Config    `json:"config"`
+	Sources map[string]string `json:"sources"`
+}
+
+// printConfig writes the effective configuration for path, or the base
+// configuration if path is empty. In verbose mode each option is annotated
+// with the file, preset, .editorconfig, front matter or flag it comes from.
+func printConfig(w io.Writer, configs *config.Resolver, path, format string, verbose bool) error {
+	cfg := configs.Base()
+	if path != "" {
+		fileCfg, err := configs.ForFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve configuration for %s: %w", path, err)
+		}
+		// the file need not exist, but its front matter applies if it does
+		if content, readErr := os.ReadFile(path); readErr == nil { // #nosec G304 - path is user provided
+			if fileCfg, err = documentConfig(fileCfg, content); err != nil {
+				return fmt.Errorf("failed to resolve configuration for %s: %w", path, err)
+			}
+		}
+		cfg = fileCfg
+	}
+
+	// extends is already resolved into the printed values
+	resolved := *cfg
+	resolved.Extends = nil
+
+	var data []byte
+	var err error
+	switch {
+	case format == ConfigFormatJSON && verbose:
+		sources, sourcesErr := resolved.Sources()
+		if sourcesErr != nil {
+			return sourcesErr
+		}
+		data, err = json.MarshalIndent(configWithSources{Config: &resolved, Sources: sources}, "", "  ")
+	case format == ConfigFormatJSON:
+		data, err = json.MarshalIndent(&resolved, "", "  ")
+	case verbose:
+		data, err = resolved.AnnotatedYAML()
+	default:
+		data, err = yaml.Marshal(&resolved)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	if format == ConfigFormatJSON {
+		data = append(data, '\n')
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync"
+)
+
+// SlowestFilesShown is the number of files listed by --timings
+const SlowestFilesShown = 10
+
+// startProfiling starts the CPU profile and execution trace requested by
+// --cpuprofile and --trace. The returned function stops them and writes the
+// heap profile requested by --memprofile; it may be called more than once.
+func startProfiling(cpuProfile, memProfile, traceFile string) (func(), error) {
+	var stops []func()
+	stop := func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile) // #nosec G304 - path given on the command line
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			f.Close()
+		})
+	}
+
+	if traceFile != "" {
+		f, err := os.Create(traceFile) // #nosec G304 - path given on the command line
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("failed to create trace: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			stop()
+			return nil, fmt.Errorf("failed to start trace: %w", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			f.Close()
+		})
+	}
+
+	if memProfile != "" {
+		stops = append(stops, func() {
+			if err := writeHeapProfile(memProfile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		})
+	}
+
+	var once sync.Once
+	return func() { once.Do(stop) }, nil
+}
+
+// writeHeapProfile writes a heap profile to path
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path) // #nosec G304 - path given on the command line
+	if err != nil {
+		return fmt.Errorf("failed to create memory profile: %w", err)
+	}
+	defer f.Close()
+
+	runtime.GC() // get up-to-date statistics
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("failed to write memory profile: %w", err)
+	}
+	return nil
+}
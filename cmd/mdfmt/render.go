@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+// RenderCommand is the name of the subcommand rendering a document to
+// another format
+const RenderCommand = "render"
+
+// Output formats of the render subcommand
+const (
+	// RenderMarkdown renders the formatted markdown, like fmt
+	RenderMarkdown = "markdown"
+	// RenderText renders the prose without markup
+	RenderText = "text"
+)
+
+// renderers maps the output formats of render to their renderers
+var renderers = map[string]func() renderer.Renderer{
+	RenderMarkdown: func() renderer.Renderer { return renderer.New() },
+	RenderText:     func() renderer.Renderer { return renderer.NewText() },
+}
+
+// runRender formats a document and writes it to standard output in another
+// format
+func runRender(args []string) error {
+	fs := flag.NewFlagSet(RenderCommand, flag.ContinueOnError)
+	to := fs.String("to", RenderMarkdown, "output format: "+strings.Join(renderFormats(), ", "))
+	configPath := fs.String("config", "", "path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt render [OPTIONS] <file|->\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("render expects exactly one input file")
+	}
+	newRenderer, ok := renderers[*to]
+	if !ok {
+		return fmt.Errorf("unknown output format %q, expected one of %s", *to, strings.Join(renderFormats(), ", "))
+	}
+	input := processor.FileInfo{Path: fs.Arg(0)}
+
+	configs, err := loadConfig(*configPath, nil)
+	if err != nil {
+		return err
+	}
+	cfg, err := configs.ForFile(input.Path)
+	if err != nil {
+		return err
+	}
+	content, err := readInput(input)
+	if err != nil {
+		return err
+	}
+	if cfg, err = documentConfig(cfg, content); err != nil {
+		return err
+	}
+	doc, _, err := formatMarkdownContent(context.Background(), content, cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := newRenderer().RenderTo(os.Stdout, doc, cfg); err != nil {
+		return fmt.Errorf("failed to render document: %w", err)
+	}
+	return nil
+}
+
+// renderFormats returns the output formats of render, sorted
+func renderFormats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
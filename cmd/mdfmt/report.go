@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/stats"
+)
+
+// Report formats
+const (
+	// ReportFormatJSON selects the JSON report format
+	ReportFormatJSON = "json"
+	// OutputFormatCheckstyle selects checkstyle XML results
+	OutputFormatCheckstyle = "checkstyle"
+	// OutputFormatJUnit selects JUnit XML results
+	OutputFormatJUnit = "junit"
+)
+
+const (
+	// checkstyleVersion is the checkstyle format version written
+	checkstyleVersion = "4.3"
+	// reportSource names mdfmt as the tool behind XML results
+	reportSource = "mdfmt"
+	// notFormattedMessage describes a file that would be reformatted
+	notFormattedMessage = "File is not formatted; run mdfmt fmt -w to fix it"
+)
+
+// Report summarizes a run for dashboards and CI tooling
+type Report struct {
+	Files  []FileReport   `json:"files"`
+	Totals stats.Document `json:"totals"`
+	// Changes totals the changes per rule category with --rule-counts
+	Changes map[string]int `json:"changes,omitempty"`
+}
+
+// FileReport contains the result and metrics of a single file
+type FileReport struct {
+	Path    string         `json:"path"`
+	Changed bool           `json:"changed"`
+	Stats   stats.Document `json:"stats"`
+	// Changes counts the changes the file needs per rule category with
+	// --rule-counts
+	Changes map[string]int `json:"changes,omitempty"`
+	// Error is set when the file could not be processed
+	Error string `json:"error,omitempty"`
+	// Diagnostics lists syntax warnings, reported in XML results
+	Diagnostics []parser.Diagnostic `json:"-"`
+}
+
+// Add records a processed file and includes its metrics and changes in the
+// totals
+func (r *Report) Add(path string, changed bool, changes map[string]int, doc stats.Document,
+	diagnostics []parser.Diagnostic) {
+	r.Files = append(r.Files, FileReport{Path: path, Changed: changed, Changes: changes, Stats: doc,
+		Diagnostics: diagnostics})
+	r.Totals.Add(doc)
+	for category, n := range changes {
+		if r.Changes == nil {
+			r.Changes = make(map[string]int)
+		}
+		r.Changes[category] += n
+	}
+}
+
+// AddError records a file that could not be processed
+func (r *Report) AddError(path string, err error) {
+	r.Files = append(r.Files, FileReport{Path: path, Error: err.Error()})
+}
+
+// WriteFile writes the report in format to path, or to standard output if
+// path is empty
+func (r *Report) WriteFile(path, format string) error {
+	if path == "" {
+		return r.Write(os.Stdout, format)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, OutputFilePermissions) // #nosec G304 - path is user provided
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := r.Write(f, format); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+// Write writes the report in format to w
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case OutputFormatCheckstyle:
+		return writeXML(w, r.checkstyle())
+	case OutputFormatJUnit:
+		return writeXML(w, r.junit())
+	default:
+		return r.WriteJSON(w)
+	}
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(data)); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// checkstyleReport is the root element of checkstyle XML
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+// checkstyleFile lists the problems of one file
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+// checkstyleError is a single problem
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstyle converts the report to checkstyle XML. Unformatted files and
+// processing errors are errors; syntax diagnostics are warnings.
+func (r *Report) checkstyle() checkstyleReport {
+	out := checkstyleReport{Version: checkstyleVersion, Files: make([]checkstyleFile, 0, len(r.Files))}
+	for _, file := range r.Files {
+		f := checkstyleFile{Name: file.Path}
+		if file.Error != "" {
+			f.Errors = append(f.Errors, checkstyleError{Line: 1, Severity: "error", Message: file.Error, Source: reportSource})
+		}
+		if file.Changed {
+			f.Errors = append(f.Errors, checkstyleError{
+				Line: 1, Severity: "error", Message: notFormattedMessage, Source: reportSource,
+			})
+		}
+		for _, d := range file.Diagnostics {
+			f.Errors = append(f.Errors, checkstyleError{
+				Line: d.Line, Severity: "warning", Message: d.Message, Source: reportSource + "." + d.Feature,
+			})
+		}
+		out.Files = append(out.Files, f)
+	}
+	return out
+}
+
+// junitTestSuites is the root element of JUnit XML
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds one test case per file
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is the result of one file
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	Failure   *junitResult `xml:"failure,omitempty"`
+	Error     *junitResult `xml:"error,omitempty"`
+	SystemOut string       `xml:"system-out,omitempty"`
+}
+
+// junitResult describes a failure or error
+type junitResult struct {
+	Message string `xml:"message,attr"`
+}
+
+// junit converts the report to JUnit XML. Each file is a test case that
+// fails if the file is not formatted and errors if it could not be
+// processed; syntax diagnostics are written to its output.
+func (r *Report) junit() junitTestSuites {
+	suite := junitTestSuite{Name: reportSource, Tests: len(r.Files), Cases: make([]junitTestCase, 0, len(r.Files))}
+	for _, file := range r.Files {
+		c := junitTestCase{Name: file.Path, ClassName: reportSource}
+		switch {
+		case file.Error != "":
+			c.Error = &junitResult{Message: file.Error}
+			suite.Errors++
+		case file.Changed:
+			c.Failure = &junitResult{Message: notFormattedMessage}
+			suite.Failures++
+		}
+		for _, d := range file.Diagnostics {
+			c.SystemOut += fmt.Sprintf("%s:%d: %s\n", file.Path, d.Line, d.Message)
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	return junitTestSuites{Suites: []junitTestSuite{suite}}
+}
+
+// writeXML writes v as indented XML with a declaration
+func writeXML(w io.Writer, v interface{}) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "%s%s\n", xml.Header, data); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
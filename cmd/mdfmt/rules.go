@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+)
+
+// RulesCommand is the name of the rule listing subcommand
+const RulesCommand = "rules"
+
+// RuleInfo is a rule as listed by the rules subcommand
+type RuleInfo struct {
+	formatter.Rule
+	// Default reports whether the rule takes effect without configuration
+	Default bool `json:"default"`
+}
+
+// ruleFormatters returns every formatter mdfmt runs: the defaults and those
+// processFile adds for features that need the file system
+func ruleFormatters() []formatter.NodeFormatter {
+	engine := formatter.New()
+	engine.Register(formatter.NewLinkTitleFormatter(nil))
+	engine.Register(formatter.NewImagePathFormatter(nil))
+	engine.Register(formatter.NewSpellcheckFormatter(nil))
+	return engine.Formatters()
+}
+
+// runRules lists the formatting and lint rules with their configuration
+func runRules(args []string) error {
+	fs := flag.NewFlagSet(RulesCommand, flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the rules as JSON")
+	asMarkdown := fs.Bool("markdown", false, "print the rules as a markdown table")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt rules [--json|--markdown]\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("rules takes no arguments")
+	}
+	if *asJSON && *asMarkdown {
+		return fmt.Errorf("--json and --markdown are mutually exclusive")
+	}
+
+	defaults := config.Default()
+	rules := formatter.Rules(ruleFormatters())
+	infos := make([]RuleInfo, 0, len(rules))
+	for _, rule := range rules {
+		if rule.ConfigKeys == nil {
+			rule.ConfigKeys = []string{}
+		}
+		infos = append(infos, RuleInfo{Rule: rule, Default: rule.EnabledIn(defaults)})
+	}
+
+	switch {
+	case *asJSON:
+		data, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode rules: %w", err)
+		}
+		fmt.Println(string(data))
+	case *asMarkdown:
+		fmt.Println("| Rule | Kind | Default | Autofix | Options | Description |")
+		fmt.Println("| --- | --- | --- | --- | --- | --- |")
+		for _, info := range infos {
+			keys := make([]string, len(info.ConfigKeys))
+			for i, key := range info.ConfigKeys {
+				keys[i] = "`" + key + "`"
+			}
+			fmt.Printf("| `%s` | %s | %s | %s | %s | %s |\n", info.ID, info.Kind, onOff(info.Default),
+				yesNo(info.Autofix), strings.Join(keys, ", "), info.Description)
+		}
+	default:
+		for _, info := range infos {
+			fmt.Printf("%s (%s, %s by default", info.ID, info.Kind, onOff(info.Default))
+			if info.Autofix {
+				fmt.Print(", autofix")
+			}
+			fmt.Printf(")\n    %s\n", info.Description)
+			if len(info.ConfigKeys) > 0 {
+				fmt.Printf("    options: %s\n", strings.Join(info.ConfigKeys, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// onOff returns "on" or "off" for enabled
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
+
+// yesNo returns "yes" or "no" for b
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/spellcheck"
+)
+
+// dictionaries builds the spellcheckers of configurations, reading the
+// built-in wordlist and each dictionary file once per run
+type dictionaries struct {
+	mu      sync.Mutex
+	builtin *spellcheck.Wordlist
+	files   map[string]*spellcheck.Wordlist
+}
+
+// newDictionaries creates an empty set of dictionaries
+func newDictionaries() *dictionaries {
+	return &dictionaries{files: make(map[string]*spellcheck.Wordlist)}
+}
+
+// checker returns the spellchecker of cfg: the built-in wordlist, the
+// dictionary files of spellcheck.dictionaries, relative to the working
+// directory, and the words of spellcheck.words
+func (d *dictionaries) checker(cfg *config.Config) (spellcheck.Checker, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.builtin == nil {
+		d.builtin = spellcheck.Builtin()
+	}
+	checkers := []spellcheck.Checker{d.builtin}
+	for _, path := range cfg.Spellcheck.Dictionaries {
+		wordlist, ok := d.files[path]
+		if !ok {
+			var err error
+			if wordlist, err = spellcheck.LoadWordlist(path); err != nil {
+				return nil, err
+			}
+			d.files[path] = wordlist
+		}
+		checkers = append(checkers, wordlist)
+	}
+	if len(cfg.Spellcheck.Words) > 0 {
+		checkers = append(checkers, spellcheck.NewWordlist(cfg.Spellcheck.Words...))
+	}
+	return spellcheck.Combine(checkers...), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+	"github.com/Gosayram/go-mdfmt/pkg/split"
+)
+
+const (
+	// SplitCommand is the name of the document splitting subcommand
+	SplitCommand = "split"
+	// OutputDirPermissions defines the permissions of created output directories
+	OutputDirPermissions = 0o750
+)
+
+// runSplit formats a document and writes each of its sections to its own file
+func runSplit(args []string) error {
+	fs := flag.NewFlagSet(SplitCommand, flag.ContinueOnError)
+	level := fs.Int("level", split.DefaultLevel, "split at headings of this level")
+	outDir := fs.String("o", "", "output directory (default: the input file name without extension)")
+	numberPrefix := fs.Bool("number-prefix", false, "prefix file names with zero-padded section numbers")
+	index := fs.Bool("index", false, "generate an index file linking to the sections")
+	indexName := fs.String("index-name", split.DefaultIndexName, "file name of the generated index")
+	configPath := fs.String("config", "", "path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt split [OPTIONS] <file>\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("split expects exactly one input file")
+	}
+	input := fs.Arg(0)
+
+	configs, err := loadConfig(*configPath, nil)
+	if err != nil {
+		return err
+	}
+	cfg, err := configs.ForFile(input)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if cfg, err = documentConfig(cfg, content); err != nil {
+		return err
+	}
+	doc, _, err := formatMarkdownContent(context.Background(), content, cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	opts := split.Options{Level: *level, NumberPrefix: *numberPrefix, Index: *index, IndexName: *indexName}
+	sections, indexDoc, err := split.Split(doc, opts)
+	if err != nil {
+		return err
+	}
+
+	dir := *outDir
+	if dir == "" {
+		dir = strings.TrimSuffix(input, filepath.Ext(input))
+	}
+	if err := os.MkdirAll(dir, OutputDirPermissions); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, section := range sections {
+		if err := writeSplitFile(filepath.Join(dir, section.FileName), section.Doc, cfg); err != nil {
+			return err
+		}
+	}
+	if indexDoc != nil {
+		return writeSplitFile(filepath.Join(dir, opts.IndexFileName()), indexDoc, cfg)
+	}
+	return nil
+}
+
+// writeSplitFile renders doc and writes it to path
+func writeSplitFile(path string, doc *parser.Document, cfg *config.Config) error {
+	rendered, err := renderer.New().Render(doc, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(rendered), OutputFilePermissions); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/stats"
+)
+
+// StatsCommand is the name of the document statistics subcommand
+const StatsCommand = "stats"
+
+// StatsReport lists the statistics of each document and their totals
+type StatsReport struct {
+	Files  []FileStats    `json:"files"`
+	Totals stats.Document `json:"totals"`
+}
+
+// FileStats contains the statistics of a single document
+type FileStats struct {
+	Path  string         `json:"path"`
+	Stats stats.Document `json:"stats"`
+}
+
+// runStats reports headings, words, code blocks, links, images and reading
+// time for each markdown file and in total
+func runStats(args []string) error {
+	fs := flag.NewFlagSet(StatsCommand, flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the statistics as JSON")
+	configPath := fs.String("config", "", "path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "USAGE:\n    mdfmt stats [OPTIONS] [paths...]\n\nOPTIONS:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	configs, err := loadConfig(*configPath, nil)
+	if err != nil {
+		return err
+	}
+	corpus, cfgs, err := loadCorpus(paths, configs)
+	if err != nil {
+		return err
+	}
+	if len(corpus) == 0 {
+		return fmt.Errorf("no markdown files found in %s", strings.Join(paths, ", "))
+	}
+
+	p := newPipeline(0)
+	report := StatsReport{Files: make([]FileStats, 0, len(corpus))}
+	for _, doc := range corpus {
+		cfg := cfgs[doc.Path]
+		parsed, formatted, err := p.format(context.Background(), doc.Content, cfg, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %w", doc.Path, err)
+		}
+		docStats := stats.Collect(parsed, doc.Content, formatted, cfg.LineWidth)
+		report.Files = append(report.Files, FileStats{Path: doc.Path, Stats: docStats})
+		report.Totals.Add(docStats)
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode statistics: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	for _, file := range report.Files {
+		fmt.Printf("%s: %s\n", file.Path, describeStats(file.Stats))
+	}
+	fmt.Printf("Total (%d files): %s\n", len(report.Files), describeStats(report.Totals))
+	return nil
+}
+
+// describeStats summarizes the statistics of a document on one line
+func describeStats(d stats.Document) string {
+	levels := make([]string, 0, len(d.HeadingLevels))
+	for i, count := range d.HeadingLevels {
+		if count > 0 {
+			levels = append(levels, fmt.Sprintf("h%d: %d", i+1, count))
+		}
+	}
+	headings := fmt.Sprintf("%d headings", d.Headings)
+	if len(levels) > 0 {
+		headings += " (" + strings.Join(levels, ", ") + ")"
+	}
+	return fmt.Sprintf("%s, %d words, %d code blocks, %d links, %d images, %d min read",
+		headings, d.Words, d.CodeBlocks, d.Links, d.Images, d.ReadingMinutes)
+}
@@ -0,0 +1,146 @@
+// Package baseline records the lint violations a repository already has, so
+// later runs report only the violations that are new. Violations are matched
+// by file, rule and message rather than by line, so editing a document does
+// not bring back the violations it already had.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// DefaultFile is the baseline file used when none is given
+	DefaultFile = ".mdfmt-baseline.json"
+	// Version is the version of the baseline file format
+	Version = 1
+	// UnformattedRule is the rule of files that are not formatted
+	UnformattedRule = "unformatted"
+	// UnformattedMessage is the message of files that are not formatted
+	UnformattedMessage = "not formatted"
+	// filePermissions are the permissions of written baseline files
+	filePermissions = 0o644
+)
+
+// Violation is a recorded violation, counted once for each time it occurs
+// in its file
+type Violation struct {
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// file is the JSON layout of baseline files
+type file struct {
+	Version    int         `json:"version"`
+	Violations []Violation `json:"violations"`
+}
+
+// key identifies the violations that are counted together
+type key struct {
+	path, rule, message string
+}
+
+// Baseline is a set of known violations
+type Baseline struct {
+	counts map[key]int
+}
+
+// New creates an empty baseline
+func New() *Baseline {
+	return &Baseline{counts: make(map[key]int)}
+}
+
+// Load reads the baseline file at path
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if f.Version != Version {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", f.Version, path)
+	}
+
+	b := New()
+	for _, v := range f.Violations {
+		b.counts[key{v.Path, v.Rule, v.Message}] += max(v.Count, 1)
+	}
+	return b, nil
+}
+
+// Save writes the baseline to path, sorted so it diffs well under version
+// control
+func (b *Baseline) Save(path string) error {
+	f := file{Version: Version, Violations: b.Violations()}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), filePermissions); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Add records the diagnostics of the file at path, and that the file is
+// not formatted when unformatted is set
+func (b *Baseline) Add(path string, unformatted bool, diagnostics []parser.Diagnostic) {
+	for _, d := range diagnostics {
+		b.counts[key{path, d.Feature, d.Message}]++
+	}
+	if unformatted {
+		b.counts[key{path, UnformattedRule, UnformattedMessage}]++
+	}
+}
+
+// Filter returns the diagnostics of the file at path that the baseline
+// does not know, and whether the file being unformatted is new. Each known
+// violation excuses as many occurrences as were recorded.
+func (b *Baseline) Filter(path string, unformatted bool, diagnostics []parser.Diagnostic) ([]parser.Diagnostic, bool) {
+	if b == nil {
+		return diagnostics, unformatted
+	}
+	used := make(map[key]int)
+	var fresh []parser.Diagnostic
+	for _, d := range diagnostics {
+		k := key{path, d.Feature, d.Message}
+		if used[k] < b.counts[k] {
+			used[k]++
+			continue
+		}
+		fresh = append(fresh, d)
+	}
+	if unformatted && b.counts[key{path, UnformattedRule, UnformattedMessage}] > 0 {
+		unformatted = false
+	}
+	return fresh, unformatted
+}
+
+// Violations returns the recorded violations sorted by path, rule and message
+func (b *Baseline) Violations() []Violation {
+	violations := make([]Violation, 0, len(b.counts))
+	for k, count := range b.counts {
+		violations = append(violations, Violation{Path: k.path, Rule: k.rule, Message: k.message, Count: count})
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		a, c := violations[i], violations[j]
+		if a.Path != c.Path {
+			return a.Path < c.Path
+		}
+		if a.Rule != c.Rule {
+			return a.Rule < c.Rule
+		}
+		return a.Message < c.Message
+	})
+	return violations
+}
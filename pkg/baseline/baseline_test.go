@@ -0,0 +1,104 @@
+package baseline
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func diagnostic(line int, feature, message string) parser.Diagnostic {
+	return parser.Diagnostic{Line: line, Feature: feature, Message: message}
+}
+
+func TestFilter(t *testing.T) {
+	b := New()
+	b.Add("docs/guide.md", true, []parser.Diagnostic{
+		diagnostic(3, "spelling", `unknown word "teh"`),
+		diagnostic(9, "empty-alt", "image a.png has no alt text"),
+	})
+
+	// Lines moved, one more "teh" and a new problem
+	fresh, unformatted := b.Filter("docs/guide.md", true, []parser.Diagnostic{
+		diagnostic(5, "spelling", `unknown word "teh"`),
+		diagnostic(7, "spelling", `unknown word "teh"`),
+		diagnostic(11, "empty-alt", "image a.png has no alt text"),
+		diagnostic(12, "empty-alt", "image b.png has no alt text"),
+	})
+	expected := []parser.Diagnostic{
+		diagnostic(7, "spelling", `unknown word "teh"`),
+		diagnostic(12, "empty-alt", "image b.png has no alt text"),
+	}
+	if !reflect.DeepEqual(fresh, expected) {
+		t.Errorf("Filter() = %v, want %v", fresh, expected)
+	}
+	if unformatted {
+		t.Error("recorded unformatted file reported as new")
+	}
+
+	// Violations of other files are not excused
+	fresh, unformatted = b.Filter("docs/other.md", true, []parser.Diagnostic{diagnostic(3, "spelling", `unknown word "teh"`)})
+	if len(fresh) != 1 || !unformatted {
+		t.Errorf("Filter() of another file = %v, %t", fresh, unformatted)
+	}
+
+	var none *Baseline
+	if fresh, unformatted = none.Filter("a.md", true, expected); len(fresh) != 2 || !unformatted {
+		t.Error("nil baseline should excuse nothing")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	b := New()
+	b.Add("b.md", false, []parser.Diagnostic{diagnostic(1, "spelling", `unknown word "teh"`)})
+	b.Add("a.md", true, []parser.Diagnostic{
+		diagnostic(1, "spelling", `unknown word "teh"`),
+		diagnostic(2, "spelling", `unknown word "teh"`),
+	})
+
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	if err := b.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"version": 1`) ||
+		strings.Index(string(data), `"a.md"`) > strings.Index(string(data), `"b.md"`) {
+		t.Errorf("unexpected baseline file:\n%s", data)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	expected := []Violation{
+		{Path: "a.md", Rule: "spelling", Message: `unknown word "teh"`, Count: 2},
+		{Path: "a.md", Rule: UnformattedRule, Message: UnformattedMessage, Count: 1},
+		{Path: "b.md", Rule: "spelling", Message: `unknown word "teh"`, Count: 1},
+	}
+	if got := loaded.Violations(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Violations() = %v, want %v", got, expected)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(filepath.Join(dir, "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+
+	path := filepath.Join(dir, "baseline.json")
+	if err := os.WriteFile(path, []byte(`{"version": 2, "violations": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unsupported baseline version 2") {
+		t.Errorf("expected a version error, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package bench measures formatting throughput over a corpus of documents.
+package bench
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// Constants
+const (
+	// DefaultRuns is the number of measured passes over the corpus
+	DefaultRuns = 10
+	// bytesPerMB converts bytes to megabytes
+	bytesPerMB = 1 << 20
+	// bytesPerKB converts bytes to kilobytes
+	bytesPerKB = 1 << 10
+)
+
+// Document is a corpus file held in memory
+type Document struct {
+	Path    string
+	Content []byte
+}
+
+// FormatFunc formats a single document
+type FormatFunc func(doc Document) error
+
+// Result is the outcome of a benchmark
+type Result struct {
+	// Files and Bytes measure the corpus
+	Files int
+	Bytes int64
+	// Runs is the number of measured passes over the corpus
+	Runs int
+	// Elapsed is the total time of the measured passes
+	Elapsed time.Duration
+	// Allocs and AllocBytes count the heap allocations of the measured passes
+	Allocs     uint64
+	AllocBytes uint64
+}
+
+// Run formats every document of corpus once to warm up, then runs times
+// more while measuring time and allocations
+func Run(corpus []Document, format FormatFunc, runs int) (Result, error) {
+	if runs < 1 {
+		return Result{}, fmt.Errorf("runs must be at least 1, got %d", runs)
+	}
+
+	result := Result{Files: len(corpus), Runs: runs}
+	for _, doc := range corpus {
+		result.Bytes += int64(len(doc.Content))
+		if err := format(doc); err != nil {
+			return Result{}, fmt.Errorf("failed to format %s: %w", doc.Path, err)
+		}
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < runs; i++ {
+		for _, doc := range corpus {
+			if err := format(doc); err != nil {
+				return Result{}, fmt.Errorf("failed to format %s: %w", doc.Path, err)
+			}
+		}
+	}
+	result.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	result.Allocs = after.Mallocs - before.Mallocs
+	result.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	return result, nil
+}
+
+// MBPerSecond returns the formatting throughput in megabytes per second
+func (r Result) MBPerSecond() float64 {
+	return float64(r.Bytes) * float64(r.Runs) / bytesPerMB / r.seconds()
+}
+
+// FilesPerSecond returns the number of files formatted per second
+func (r Result) FilesPerSecond() float64 {
+	return float64(r.Files) * float64(r.Runs) / r.seconds()
+}
+
+// AllocsPerFile returns the average number of allocations per formatted file
+func (r Result) AllocsPerFile() float64 {
+	return float64(r.Allocs) / r.formatted()
+}
+
+// AllocBytesPerFile returns the average number of bytes allocated per formatted file
+func (r Result) AllocBytesPerFile() float64 {
+	return float64(r.AllocBytes) / r.formatted()
+}
+
+// String formats the result as a short report
+func (r Result) String() string {
+	return fmt.Sprintf("Corpus:      %d files, %.2f MB\n"+
+		"Runs:        %d, %s per run\n"+
+		"Throughput:  %.2f MB/s, %.1f files/s\n"+
+		"Allocations: %.0f per file, %.1f KB per file\n",
+		r.Files, float64(r.Bytes)/bytesPerMB,
+		r.Runs, (r.Elapsed / time.Duration(r.Runs)).Round(time.Microsecond),
+		r.MBPerSecond(), r.FilesPerSecond(),
+		r.AllocsPerFile(), r.AllocBytesPerFile()/bytesPerKB)
+}
+
+// seconds returns the elapsed time in seconds, never zero
+func (r Result) seconds() float64 {
+	if r.Elapsed <= 0 {
+		return time.Nanosecond.Seconds()
+	}
+	return r.Elapsed.Seconds()
+}
+
+// formatted returns the number of files formatted while measuring, never zero
+func (r Result) formatted() float64 {
+	if n := r.Files * r.Runs; n > 0 {
+		return float64(n)
+	}
+	return 1
+}
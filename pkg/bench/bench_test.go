@@ -0,0 +1,61 @@
+package bench
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	corpus := []Document{
+		{Path: "a.md", Content: []byte("# A\n")},
+		{Path: "b.md", Content: []byte("# B\n\nText\n")},
+	}
+	calls := 0
+	result, err := Run(corpus, func(Document) error {
+		calls++
+		return nil
+	}, 3)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if calls != 8 {
+		t.Errorf("Expected one warm-up and three measured passes (8 calls), got %d", calls)
+	}
+	if result.Files != 2 || result.Bytes != 14 || result.Runs != 3 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestRunReportsErrors(t *testing.T) {
+	_, err := Run([]Document{{Path: "bad.md"}}, func(Document) error {
+		return errors.New("boom")
+	}, 1)
+	if err == nil || !strings.Contains(err.Error(), "bad.md") {
+		t.Errorf("Expected the failing file in the error, got %v", err)
+	}
+	if _, err := Run(nil, func(Document) error { return nil }, 0); err == nil {
+		t.Error("Expected zero runs to be rejected")
+	}
+}
+
+func TestResultRates(t *testing.T) {
+	r := Result{Files: 4, Bytes: 2 << 20, Runs: 2, Elapsed: 2 * time.Second, Allocs: 800, AllocBytes: 8 << 10}
+	if r.MBPerSecond() != 2 {
+		t.Errorf("Expected 2 MB/s, got %v", r.MBPerSecond())
+	}
+	if r.FilesPerSecond() != 4 {
+		t.Errorf("Expected 4 files/s, got %v", r.FilesPerSecond())
+	}
+	if r.AllocsPerFile() != 100 || r.AllocBytesPerFile() != 1024 {
+		t.Errorf("Unexpected allocations per file: %v, %v", r.AllocsPerFile(), r.AllocBytesPerFile())
+	}
+	expected := "Corpus:      4 files, 2.00 MB\n" +
+		"Runs:        2, 1s per run\n" +
+		"Throughput:  2.00 MB/s, 4.0 files/s\n" +
+		"Allocations: 100 per file, 1.0 KB per file\n"
+	if r.String() != expected {
+		t.Errorf("Expected report:\n%s\ngot:\n%s", expected, r.String())
+	}
+}
@@ -0,0 +1,134 @@
+// Package cache provides a least recently used cache of formatting results,
+// so a document version formatted again with the same configuration is not
+// parsed and rendered a second time.
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// DefaultSize is the number of results kept by default
+const DefaultSize = 128
+
+// Key identifies a document version and the configuration it is formatted with
+type Key [sha256.Size]byte
+
+// NewKey returns the key of content formatted with cfg
+func NewKey(content []byte, cfg *config.Config) (Key, error) {
+	settings, err := json.Marshal(cfg)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to hash configuration: %w", err)
+	}
+
+	h := sha256.New()
+	contentHash := sha256.Sum256(content)
+	configHash := sha256.Sum256(settings)
+	h.Write(contentHash[:])
+	h.Write(configHash[:])
+
+	var key Key
+	copy(key[:], h.Sum(nil))
+	return key, nil
+}
+
+// Entry is a cached formatting result. Its document is shared by every
+// caller getting it and must not be modified.
+type Entry struct {
+	Document *parser.Document
+	Output   string
+}
+
+// item is an entry with its key, as kept in the recency list
+type item struct {
+	key   Key
+	entry Entry
+}
+
+// Cache is a least recently used cache of formatting results. It is safe
+// for concurrent use. A nil *Cache caches nothing, so callers need not check
+// whether caching is enabled.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	items map[Key]*list.Element
+	// recent orders the items from most to least recently used
+	recent *list.List
+	hits   int
+	misses int
+}
+
+// New returns a cache holding up to size results, or nil if size is not
+// positive
+func New(size int) *Cache {
+	if size <= 0 {
+		return nil
+	}
+	return &Cache{size: size, items: make(map[Key]*list.Element), recent: list.New()}
+}
+
+// Get returns the result cached under key
+func (c *Cache) Get(key Key) (Entry, bool) {
+	if c == nil {
+		return Entry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return Entry{}, false
+	}
+	c.hits++
+	c.recent.MoveToFront(element)
+	return element.Value.(*item).entry, true
+}
+
+// Add caches entry under key, evicting the least recently used result when
+// the cache is full
+func (c *Cache) Add(key Key, entry Entry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		element.Value.(*item).entry = entry
+		c.recent.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.recent.PushFront(&item{key: key, entry: entry})
+	if c.recent.Len() > c.size {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.items, oldest.Value.(*item).key)
+	}
+}
+
+// Len returns the number of cached results
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.recent.Len()
+}
+
+// Stats returns the number of lookups that found a result and that did not
+func (c *Cache) Stats() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
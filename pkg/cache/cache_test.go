@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+// mustKey returns the key of content formatted with cfg
+func mustKey(t *testing.T, content string, cfg *config.Config) Key {
+	t.Helper()
+	key, err := NewKey([]byte(content), cfg)
+	if err != nil {
+		t.Fatalf("NewKey failed: %v", err)
+	}
+	return key
+}
+
+func TestNewKey(t *testing.T) {
+	cfg := config.Default()
+	if mustKey(t, "# Title\n", cfg) != mustKey(t, "# Title\n", config.Default()) {
+		t.Error("Expected equal content and configuration to give equal keys")
+	}
+	if mustKey(t, "# Title\n", cfg) == mustKey(t, "# Other\n", cfg) {
+		t.Error("Expected different content to give different keys")
+	}
+
+	wide := config.Default()
+	wide.LineWidth = cfg.LineWidth + 1
+	if mustKey(t, "# Title\n", cfg) == mustKey(t, "# Title\n", wide) {
+		t.Error("Expected different configuration to give different keys")
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cfg := config.Default()
+	a, b, c := mustKey(t, "a", cfg), mustKey(t, "b", cfg), mustKey(t, "c", cfg)
+
+	cache := New(2)
+	cache.Add(a, Entry{Output: "a"})
+	cache.Add(b, Entry{Output: "b"})
+	if entry, ok := cache.Get(a); !ok || entry.Output != "a" {
+		t.Fatalf("Expected a to be cached, got %+v, %v", entry, ok)
+	}
+	cache.Add(c, Entry{Output: "c"})
+
+	if _, ok := cache.Get(b); ok {
+		t.Error("Expected b to be evicted as least recently used")
+	}
+	if _, ok := cache.Get(a); !ok {
+		t.Error("Expected a to stay cached")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 cached results, got %d", cache.Len())
+	}
+	if hits, misses := cache.Stats(); hits != 2 || misses != 1 {
+		t.Errorf("Expected 2 hits and 1 miss, got %d and %d", hits, misses)
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	cache := New(0)
+	if cache != nil {
+		t.Fatal("Expected no cache for size 0")
+	}
+	key := mustKey(t, "a", config.Default())
+	cache.Add(key, Entry{Output: "a"})
+	if _, ok := cache.Get(key); ok || cache.Len() != 0 {
+		t.Error("Expected a nil cache to cache nothing")
+	}
+}
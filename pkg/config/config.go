@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,14 +17,45 @@ const (
 	DefaultLineWidth = 80
 	// DefaultMaxBlankLines defines the default maximum consecutive blank lines
 	DefaultMaxBlankLines = 2
+	// DefaultNumberingStartLevel defines the first heading level that is auto-numbered
+	DefaultNumberingStartLevel = 2
+	// DefaultListIndent defines the default indentation of nested lists
+	DefaultListIndent = 2
+	// DefaultTabWidth defines the default number of columns of a tab
+	DefaultTabWidth = 4
+	// DefaultMaxFileSize defines the size in bytes above which files are skipped
+	DefaultMaxFileSize = 10 << 20
+	// LatestFormatVersion is the newest formatting behavior version, which
+	// format_version defaults to
+	LatestFormatVersion = 1
+	// MaxHeadingLevel defines the deepest heading level supported by markdown
+	MaxHeadingLevel = 6
 	// ConfigFilePermissions defines the file permissions for config files
 	ConfigFilePermissions = 0o600
 )
 
 // Config represents the configuration for mdfmt
 type Config struct {
-	// LineWidth is the maximum line width for text reflow
-	LineWidth int `yaml:"line_width" json:"line_width"`
+	// Extends lists presets and configuration files this configuration is
+	// based on, such as "github" or "./base.mdfmt.yaml"
+	Extends []string `yaml:"extends,omitempty" json:"extends,omitempty"`
+
+	// FormatVersion pins the formatting behavior, so upgrades of mdfmt that
+	// change its output only apply once the version is raised
+	FormatVersion int `yaml:"format_version" json:"format_version" jsonschema:"minimum=1"`
+
+	// FormatVersionComment records FormatVersion in formatted documents as
+	// an <!-- mdfmt format_version: N --> comment at their end
+	FormatVersionComment bool `yaml:"format_version_comment" json:"format_version_comment"`
+
+	// LineWidth is the maximum line width for text reflow; 0 disables wrapping
+	LineWidth int `yaml:"line_width" json:"line_width" jsonschema:"minimum=0"`
+
+	// Reflow configuration
+	Reflow ReflowConfig `yaml:"reflow" json:"reflow"`
+
+	// Dialect selects the markdown flavor: "commonmark", "gfm", "mdx" or "obsidian"
+	Dialect string `yaml:"dialect" json:"dialect" jsonschema:"enum=commonmark|gfm|mdx|obsidian"`
 
 	// Heading configuration
 	Heading HeadingConfig `yaml:"heading" json:"heading"`
@@ -33,47 +66,226 @@ type Config struct {
 	// Code block configuration
 	Code CodeConfig `yaml:"code" json:"code"`
 
+	// Blockquote configuration
+	Blockquote BlockquoteConfig `yaml:"blockquote" json:"blockquote"`
+
+	// Admonition (GitHub alert) configuration
+	Admonition AdmonitionConfig `yaml:"admonition" json:"admonition"`
+
+	// Inline mark configuration
+	Inline InlineConfig `yaml:"inline" json:"inline"`
+
+	// Link configuration
+	Links LinksConfig `yaml:"links" json:"links"`
+
+	// Image configuration
+	Images ImagesConfig `yaml:"images" json:"images"`
+
 	// Whitespace configuration
 	Whitespace WhitespaceConfig `yaml:"whitespace" json:"whitespace"`
 
+	// Changelog configuration
+	Changelog ChangelogConfig `yaml:"changelog" json:"changelog"`
+
+	// Spellcheck configuration
+	Spellcheck SpellcheckConfig `yaml:"spellcheck" json:"spellcheck"`
+
+	// Plugins are external lint rules run by mdfmt lint
+	Plugins []PluginConfig `yaml:"plugins" json:"plugins"`
+
+	// Embedded markdown configuration, used with --embedded
+	Embedded EmbeddedConfig `yaml:"embedded" json:"embedded"`
+
+	// Replacements maps terms to the spelling prose must use, such as
+	// "github" to "GitHub"; terms match whole words, ignoring case
+	Replacements map[string]string `yaml:"replacements" json:"replacements"`
+
+	// TemplateDelimiters lists the open and close delimiters of template
+	// tags, such as "{{ }}", which are kept whole and never escaped
+	TemplateDelimiters []string `yaml:"template_delimiters" json:"template_delimiters"`
+
 	// File processing configuration
 	Files FilesConfig `yaml:"files" json:"files"`
+
+	// Extensions enables optional markdown syntax extensions such as "math"
+	Extensions []string `yaml:"extensions" json:"extensions" jsonschema:"enum=math|highlight"`
+
+	// EditorConfig applies .editorconfig settings beneath this configuration
+	EditorConfig bool `yaml:"editorconfig" json:"editorconfig"`
+
+	// layers holds the configuration data loaded into c, in order, so it
+	// can be re-applied over per-file .editorconfig settings
+	layers []layer
+}
+
+// ReflowConfig contains paragraph reflow options
+type ReflowConfig struct {
+	// Mode defines how paragraphs are reflowed: "wrap" (at line_width) or "preserve" (keep their line breaks)
+	Mode string `yaml:"mode" json:"mode" jsonschema:"enum=wrap|preserve"`
+	// Wrap lists the blocks whose paragraphs are wrapped: "paragraph" (top-level), "blockquote" and "list"; headings and tables never are
+	Wrap []string `yaml:"wrap" json:"wrap" jsonschema:"enum=paragraph|blockquote|list"`
+	// Exclude lists regular expressions; paragraphs matching any of them keep their line breaks
+	Exclude []string `yaml:"exclude" json:"exclude"`
 }
 
 // HeadingConfig contains heading formatting options
 type HeadingConfig struct {
 	// Style defines the heading style: "atx" (#) or "setext" (===)
-	Style string `yaml:"style" json:"style"`
+	Style string `yaml:"style" json:"style" jsonschema:"enum=atx|setext"`
 	// NormalizeLevels fixes heading level jumps
 	NormalizeLevels bool `yaml:"normalize_levels" json:"normalize_levels"`
+	// Numbering controls automatic hierarchical heading numbers
+	Numbering HeadingNumberingConfig `yaml:"numbering" json:"numbering"`
+	// Duplicates reports headings whose anchors collide: "off", "siblings" or "document"
+	Duplicates string `yaml:"duplicates" json:"duplicates" jsonschema:"enum=off|siblings|document"`
+	// FixDuplicateAnchors gives duplicate headings a unique #id attribute instead of reporting them
+	FixDuplicateAnchors bool `yaml:"fix_duplicate_anchors" json:"fix_duplicate_anchors"`
+}
+
+// HeadingNumberingConfig contains heading auto-numbering options
+type HeadingNumberingConfig struct {
+	// Mode defines numbering behavior: "none", "number" (insert or refresh) or "strip"
+	Mode string `yaml:"mode" json:"mode" jsonschema:"enum=none|number|strip"`
+	// StartLevel is the first heading level that receives numbers
+	StartLevel int `yaml:"start_level" json:"start_level" jsonschema:"minimum=1,maximum=6"`
 }
 
 // ListConfig contains list formatting options
 type ListConfig struct {
-	// BulletStyle defines the bullet character: "-", "*", or "+"
-	BulletStyle string `yaml:"bullet_style" json:"bullet_style"`
-	// NumberStyle defines the numbering style: "." or ")"
-	NumberStyle string `yaml:"number_style" json:"number_style"`
+	// BulletStyle defines the bullet character: "-", "*", "+", or "preserve" to keep the bullets of the source
+	BulletStyle string `yaml:"bullet_style" json:"bullet_style" jsonschema:"enum=-|*|+|preserve"`
+	// AlternateMarkers cycles nested unordered lists through MarkerSequence, starting at BulletStyle
+	AlternateMarkers bool `yaml:"alternate_markers" json:"alternate_markers"`
+	// MarkerSequence lists the bullets nested levels cycle through with AlternateMarkers
+	MarkerSequence []string `yaml:"marker_sequence" json:"marker_sequence"`
+	// NumberStyle defines the numbering style: "." or ")", or "preserve" to keep the delimiter of the source
+	NumberStyle string `yaml:"number_style" json:"number_style" jsonschema:"enum=.|)|preserve"`
+	// NumberAlignment pads numbers to the widest of their list: "none", "left" ("9.  ") or "right" (" 9.")
+	NumberAlignment string `yaml:"number_alignment" json:"number_alignment" jsonschema:"enum=none|left|right"`
+	// Numbering defines how ordered list items are numbered: "sequential" (1. 2. 3.) or "one" (1. 1. 1.)
+	Numbering string `yaml:"numbering" json:"numbering" jsonschema:"enum=sequential|one"`
+	// Renumber numbers ordered list items by Numbering from the start of their list; otherwise items keep their numbers
+	Renumber bool `yaml:"renumber" json:"renumber"`
 	// ConsistentIndentation ensures consistent indentation
 	ConsistentIndentation bool `yaml:"consistent_indentation" json:"consistent_indentation"`
+	// Indent is the number of spaces nested lists are indented by
+	Indent int `yaml:"indent" json:"indent" jsonschema:"minimum=0"`
 }
 
 // CodeConfig contains code block formatting options
 type CodeConfig struct {
-	// FenceStyle defines the fence style: "```" or "~~~"
-	FenceStyle string `yaml:"fence_style" json:"fence_style"`
+	// FenceStyle defines the fence style: "```", "~~~", or "preserve" to keep
+	// the fences of the source. Its tag is double-quoted because the enum
+	// contains backticks.
+	FenceStyle string "yaml:\"fence_style\" json:\"fence_style\" jsonschema:\"enum=```|~~~|preserve\""
 	// LanguageDetection enables automatic language detection
 	LanguageDetection bool `yaml:"language_detection" json:"language_detection"`
 }
 
+// BlockquoteConfig contains blockquote formatting options
+type BlockquoteConfig struct {
+	// MarkerSpacing defines whether quoted lines are written "> text" ("space") or ">text" ("none")
+	MarkerSpacing string `yaml:"marker_spacing" json:"marker_spacing" jsonschema:"enum=space|none"`
+	// BlankMarker defines whether blank lines between the blocks of a quote keep their ">" ("keep") or not ("strip")
+	BlankMarker string `yaml:"blank_marker" json:"blank_marker" jsonschema:"enum=keep|strip"`
+}
+
+// AdmonitionConfig contains GitHub alert (> [!NOTE]) formatting options
+type AdmonitionConfig struct {
+	// TagCase defines how alert tags are written: "preserve", "upper" or "lower"
+	TagCase string `yaml:"tag_case" json:"tag_case" jsonschema:"enum=preserve|upper|lower"`
+}
+
+// InlineConfig contains inline mark formatting options
+type InlineConfig struct {
+	// StrikethroughMarker defines the strikethrough delimiter: "preserve", "~~" or "~"
+	StrikethroughMarker string `yaml:"strikethrough_marker" json:"strikethrough_marker" jsonschema:"enum=preserve|~~|~"`
+}
+
+// LinksConfig contains link formatting options
+type LinksConfig struct {
+	// BareURLStyle defines how bare URLs are written: "bare" (as is), "angle" (<https://...>) or "link" ([https://...](https://...))
+	BareURLStyle string `yaml:"bare_url_style" json:"bare_url_style" jsonschema:"enum=bare|angle|link"`
+	// Style defines how links and images are written: "preserve", "inline" or "reference"
+	Style string `yaml:"style" json:"style" jsonschema:"enum=preserve|inline|reference"`
+	// ReferenceThreshold limits the "reference" style to inline links whose URL is longer than this many characters; 0 converts all
+	ReferenceThreshold int `yaml:"reference_threshold" json:"reference_threshold" jsonschema:"minimum=0"`
+	// ReferencePlacement defines where the "reference" style adds definitions: "document" (at its end) or "section" (before the next heading)
+	ReferencePlacement string `yaml:"reference_placement" json:"reference_placement" jsonschema:"enum=document|section"`
+	// SortDefinitions orders the link reference definitions of each group: "preserve", "alphabetical" or "first_use"
+	SortDefinitions string `yaml:"sort_definitions" json:"sort_definitions" jsonschema:"enum=preserve|alphabetical|first_use"`
+	// MergeDuplicateDefinitions merges definitions with the same URL and title, pointing their references at the first
+	MergeDuplicateDefinitions bool `yaml:"merge_duplicate_definitions" json:"merge_duplicate_definitions"`
+	// WarnUnusedDefinitions reports link reference definitions that no link or image uses
+	WarnUnusedDefinitions bool `yaml:"warn_unused_definitions" json:"warn_unused_definitions"`
+	// AutoTitle fills empty link text and bare path links to other documents with their H1 title
+	AutoTitle bool `yaml:"auto_title" json:"auto_title"`
+}
+
+// ImagesConfig contains image formatting and checking options
+type ImagesConfig struct {
+	// AltFromFilename fills empty alt text with words taken from the image file name
+	AltFromFilename bool `yaml:"alt_from_filename" json:"alt_from_filename"`
+	// TitleQuote defines how image titles are quoted: "double", "single" or "parentheses"
+	TitleQuote string `yaml:"title_quote" json:"title_quote" jsonschema:"enum=double|single|parentheses"`
+	// WarnEmptyAlt reports images without alt text
+	WarnEmptyAlt bool `yaml:"warn_empty_alt" json:"warn_empty_alt"`
+	// BadgeLayout defines how paragraphs of badges are laid out: "preserve", "one_per_line" or "single_line"
+	BadgeLayout string `yaml:"badge_layout" json:"badge_layout" jsonschema:"enum=preserve|one_per_line|single_line"`
+	// CheckPaths reports local images that do not exist relative to the document
+	CheckPaths bool `yaml:"check_paths" json:"check_paths"`
+}
+
+// ChangelogConfig contains options for changelogs following Keep a Changelog
+type ChangelogConfig struct {
+	// Enabled applies the changelog rules to documents titled "Changelog"
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// SectionOrder is the order of the change types of each release
+	SectionOrder []string `yaml:"section_order" json:"section_order"`
+}
+
+// SpellcheckConfig contains spellcheck lint options
+type SpellcheckConfig struct {
+	// Enabled reports the words of prose missing from the wordlists
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Dictionaries are files of project words, one per line, read on top of the built-in wordlist
+	Dictionaries []string `yaml:"dictionaries" json:"dictionaries"`
+	// Words are project words accepted on top of the built-in wordlist
+	Words []string `yaml:"words" json:"words"`
+}
+
+// PluginConfig configures an external lint rule
+type PluginConfig struct {
+	// Name identifies the rule in lint output and suppression comments
+	Name string `yaml:"name" json:"name"`
+	// Command is the program to run with its arguments
+	Command []string `yaml:"command" json:"command"`
+	// Input is what the command reads on stdin: "ast" (the JSON tree printed by mdfmt parse --ast) or "text" (the markdown)
+	Input string `yaml:"input" json:"input" jsonschema:"enum=ast|text"`
+}
+
+// EmbeddedConfig contains options for markdown embedded in other files
+type EmbeddedConfig struct {
+	// YAMLKeys are the keys of YAML files whose literal block scalars hold markdown
+	YAMLKeys []string `yaml:"yaml_keys" json:"yaml_keys"`
+}
+
 // WhitespaceConfig contains whitespace handling options
 type WhitespaceConfig struct {
 	// MaxBlankLines defines maximum consecutive blank lines
-	MaxBlankLines int `yaml:"max_blank_lines" json:"max_blank_lines"`
+	MaxBlankLines int `yaml:"max_blank_lines" json:"max_blank_lines" jsonschema:"minimum=0"`
 	// TrimTrailingSpaces removes trailing spaces
 	TrimTrailingSpaces bool `yaml:"trim_trailing_spaces" json:"trim_trailing_spaces"`
+	// HardBreakStyle defines how hard line breaks are written: "backslash" or "spaces"
+	HardBreakStyle string `yaml:"hard_break_style" json:"hard_break_style" jsonschema:"enum=backslash|spaces"`
 	// EnsureFinalNewline ensures files end with a newline
 	EnsureFinalNewline bool `yaml:"ensure_final_newline" json:"ensure_final_newline"`
+	// EndOfLine defines the line ending of formatted output: "lf", "crlf" or "cr"
+	EndOfLine string `yaml:"end_of_line" json:"end_of_line" jsonschema:"enum=lf|crlf|cr"`
+	// Tabs defines how tabs in prose and list indentation are handled: "preserve" or "spaces"
+	Tabs string `yaml:"tabs" json:"tabs" jsonschema:"enum=preserve|spaces"`
+	// TabWidth defines the number of columns of a tab
+	TabWidth int `yaml:"tab_width" json:"tab_width" jsonschema:"minimum=1"`
 }
 
 // FilesConfig contains file processing options
@@ -82,45 +294,174 @@ type FilesConfig struct {
 	Extensions []string `yaml:"extensions" json:"extensions"`
 	// IgnorePatterns defines glob patterns to ignore
 	IgnorePatterns []string `yaml:"ignore_patterns" json:"ignore_patterns"`
+	// FollowSymlinks follows symbolic links found while walking directories
+	FollowSymlinks bool `yaml:"follow_symlinks" json:"follow_symlinks"`
+	// MaxFileSize skips files larger than this many bytes; 0 disables the limit
+	MaxFileSize int64 `yaml:"max_file_size" json:"max_file_size" jsonschema:"minimum=0"`
+	// SkipBinary skips files that contain NUL bytes or invalid UTF-8
+	SkipBinary bool `yaml:"skip_binary" json:"skip_binary"`
 }
 
 // Default returns the default configuration
 func Default() *Config {
 	return &Config{
-		LineWidth: DefaultLineWidth,
+		FormatVersion: LatestFormatVersion,
+		LineWidth:     DefaultLineWidth,
+		Dialect:       "gfm",
+		Reflow: ReflowConfig{
+			Mode:    "wrap",
+			Wrap:    []string{"paragraph", "blockquote", "list"},
+			Exclude: []string{},
+		},
 		Heading: HeadingConfig{
 			Style:           "atx",
 			NormalizeLevels: true,
+			Numbering: HeadingNumberingConfig{
+				Mode:       "none",
+				StartLevel: DefaultNumberingStartLevel,
+			},
+			Duplicates: "off",
 		},
 		List: ListConfig{
 			BulletStyle:           "-",
+			MarkerSequence:        []string{"-", "*", "+"},
 			NumberStyle:           ".",
+			NumberAlignment:       "none",
+			Numbering:             "sequential",
+			Renumber:              true,
 			ConsistentIndentation: true,
+			Indent:                DefaultListIndent,
 		},
 		Code: CodeConfig{
 			FenceStyle:        "```",
 			LanguageDetection: true,
 		},
+		Blockquote: BlockquoteConfig{
+			MarkerSpacing: "space",
+			BlankMarker:   "keep",
+		},
+		Admonition: AdmonitionConfig{
+			TagCase: "preserve",
+		},
+		Inline: InlineConfig{
+			StrikethroughMarker: "preserve",
+		},
+		Links: LinksConfig{
+			BareURLStyle:       "bare",
+			Style:              "preserve",
+			ReferencePlacement: "document",
+			SortDefinitions:    "preserve",
+		},
+		Images: ImagesConfig{
+			TitleQuote:  "double",
+			BadgeLayout: "preserve",
+		},
+		Replacements:       map[string]string{},
+		TemplateDelimiters: []string{"{{ }}", "{% %}", "{# #}"},
 		Whitespace: WhitespaceConfig{
 			MaxBlankLines:      DefaultMaxBlankLines,
 			TrimTrailingSpaces: true,
+			HardBreakStyle:     "backslash",
 			EnsureFinalNewline: true,
+			EndOfLine:          "lf",
+			Tabs:               "spaces",
+			TabWidth:           DefaultTabWidth,
+		},
+		Changelog: ChangelogConfig{
+			SectionOrder: []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"},
+		},
+		Spellcheck: SpellcheckConfig{
+			Dictionaries: []string{},
+			Words:        []string{},
+		},
+		Plugins: []PluginConfig{},
+		Embedded: EmbeddedConfig{
+			YAMLKeys: []string{"description"},
 		},
 		Files: FilesConfig{
 			Extensions:     []string{".md", ".markdown", ".mdown"},
 			IgnorePatterns: []string{"node_modules/**", ".git/**", "vendor/**"},
+			MaxFileSize:    DefaultMaxFileSize,
+			SkipBinary:     true,
 		},
+		EditorConfig: true,
 	}
 }
 
-// LoadFromFile loads configuration from a file.
+// LoadFromFile loads configuration from a file. Configurations listed in
+// its extends key are loaded first, so the file overrides them.
 func (c *Config) LoadFromFile(filename string) error {
-	data, err := os.ReadFile(filename) // #nosec G304 - filename is user provided and validated
+	source, err := readConfigSource(filename)
+	if err != nil {
+		return err
+	}
+
+	layers, err := source.layers(nil)
+	if err != nil {
+		return err
+	}
+	for _, l := range layers {
+		if err := yaml.Unmarshal(l.data, c); err != nil {
+			return yamlError(l.source, err)
+		}
+	}
+	c.layers = append(c.layers, layers...)
+	return nil
+}
+
+// ForFile returns the configuration for the markdown file at path: the
+// .editorconfig settings that apply to it, with the configuration files
+// loaded into c merged on top. c is returned unchanged when EditorConfig is
+// disabled or no .editorconfig settings apply.
+func (c *Config) ForFile(path string) (*Config, error) {
+	if !c.EditorConfig {
+		return c, nil
+	}
+
+	properties, err := editorConfigProperties(path)
+	if err != nil {
+		return nil, err
+	}
+	editorLayer, err := editorConfigLayer(properties)
+	if err != nil || editorLayer == nil {
+		return c, err
+	}
+
+	cfg, err := fromLayers(append([]layer{*editorLayer}, c.layers...))
 	if err != nil {
-		return fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
 
-	return yaml.Unmarshal(data, c)
+// fromLayers returns the default configuration with layers applied in order
+func fromLayers(layers []layer) (*Config, error) {
+	cfg := Default()
+	for _, l := range layers {
+		if err := yaml.Unmarshal(l.data, cfg); err != nil {
+			return nil, yamlError(l.source, err)
+		}
+	}
+	cfg.layers = layers
+	return cfg, nil
+}
+
+// tomlToYAML converts a TOML configuration to YAML, so TOML files share the
+// schema and decoding of YAML and JSON files
+func tomlToYAML(data []byte) ([]byte, error) {
+	var values map[string]interface{}
+	if err := toml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse TOML config: %w", err)
+	}
+
+	converted, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert TOML config: %w", err)
+	}
+	return converted, nil
 }
 
 // SaveToFile saves configuration to a file.
@@ -137,24 +478,25 @@ func (c *Config) SaveToFile(filename string) error {
 	return nil
 }
 
+// configFileNames are the configuration file names mdfmt discovers, in
+// order of precedence
+var configFileNames = []string{
+	".mdfmt.yaml",
+	".mdfmt.yml",
+	".mdfmt.json",
+	".mdfmt.toml",
+	"mdfmt.yaml",
+	"mdfmt.yml",
+	"mdfmt.json",
+	"mdfmt.toml",
+}
+
 // FindConfigFile searches for configuration files in common locations
 func FindConfigFile(startDir string) (string, error) {
-	configNames := []string{
-		".mdfmt.yaml",
-		".mdfmt.yml",
-		".mdfmt.json",
-		"mdfmt.yaml",
-		"mdfmt.yml",
-		"mdfmt.json",
-	}
-
 	dir := startDir
 	for {
-		for _, name := range configNames {
-			path := filepath.Join(dir, name)
-			if _, err := os.Stat(path); err == nil {
-				return path, nil
-			}
+		if path, ok := configFileIn(dir); ok {
+			return path, nil
 		}
 
 		parent := filepath.Dir(dir)
@@ -167,30 +509,210 @@ func FindConfigFile(startDir string) (string, error) {
 	return "", fmt.Errorf("no configuration file found")
 }
 
-// Validate validates the configuration
+// configFileIn returns the configuration file in dir, if any
+func configFileIn(dir string) (string, bool) {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Validate validates the configuration. Errors are *ConfigError values
+// locating the invalid option in the configuration file that set it.
 func (c *Config) Validate() error {
-	if c.LineWidth < 1 {
-		return fmt.Errorf("line_width must be greater than 0")
+	err := c.validate()
+	if err != nil {
+		c.locate(err)
+		return err
+	}
+	return nil
+}
+
+// validate returns the error of the first invalid option
+func (c *Config) validate() error {
+	if c.FormatVersion < 1 || c.FormatVersion > LatestFormatVersion {
+		return optionError("format_version",
+			"format_version %d is not supported: this mdfmt supports versions 1 to %d", c.FormatVersion, LatestFormatVersion)
+	}
+
+	if c.LineWidth < 0 {
+		return optionError("line_width", "line_width must be >= 0")
+	}
+
+	if c.Reflow.Mode != "" && !contains([]string{"wrap", "preserve"}, c.Reflow.Mode) {
+		return optionError("reflow.mode", "reflow.mode must be 'wrap' or 'preserve'")
+	}
+
+	for _, block := range c.Reflow.Wrap {
+		if !contains([]string{"paragraph", "blockquote", "list"}, block) {
+			return optionError("reflow.wrap",
+				"unknown reflow.wrap block %q: blocks are 'paragraph', 'blockquote' and 'list'", block)
+		}
+	}
+
+	for _, pattern := range c.Reflow.Exclude {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return optionError("reflow.exclude", "reflow.exclude: %w", err)
+		}
+	}
+
+	if c.Dialect != "" && !contains([]string{"commonmark", "gfm", "mdx", "obsidian"}, c.Dialect) {
+		return optionError("dialect", "dialect must be 'commonmark', 'gfm', 'mdx', or 'obsidian'")
 	}
 
 	if c.Heading.Style != "atx" && c.Heading.Style != "setext" {
-		return fmt.Errorf("heading.style must be 'atx' or 'setext'")
+		return optionError("heading.style", "heading.style must be 'atx' or 'setext'")
+	}
+
+	if c.Heading.Numbering.Mode != "" && !contains([]string{"none", "number", "strip"}, c.Heading.Numbering.Mode) {
+		return optionError("heading.numbering.mode", "heading.numbering.mode must be 'none', 'number', or 'strip'")
+	}
+
+	if c.Heading.Numbering.Mode == "number" &&
+		(c.Heading.Numbering.StartLevel < 1 || c.Heading.Numbering.StartLevel > MaxHeadingLevel) {
+		return optionError("heading.numbering.start_level",
+			"heading.numbering.start_level must be between 1 and %d", MaxHeadingLevel)
+	}
+
+	if c.Heading.Duplicates != "" && !contains([]string{"off", "siblings", "document"}, c.Heading.Duplicates) {
+		return optionError("heading.duplicates", "heading.duplicates must be 'off', 'siblings', or 'document'")
+	}
+
+	if !contains([]string{"-", "*", "+", "preserve"}, c.List.BulletStyle) {
+		return optionError("list.bullet_style", "list.bullet_style must be '-', '*', '+', or 'preserve'")
+	}
+
+	for _, marker := range c.List.MarkerSequence {
+		if !contains([]string{"-", "*", "+"}, marker) {
+			return optionError("list.marker_sequence", "list.marker_sequence may only contain '-', '*', and '+'")
+		}
+	}
+
+	if !contains([]string{".", ")", "preserve"}, c.List.NumberStyle) {
+		return optionError("list.number_style", "list.number_style must be '.', ')', or 'preserve'")
+	}
+
+	if !contains([]string{"none", "left", "right"}, c.List.NumberAlignment) {
+		return optionError("list.number_alignment", "list.number_alignment must be 'none', 'left', or 'right'")
+	}
+
+	if !contains([]string{"sequential", "one"}, c.List.Numbering) {
+		return optionError("list.numbering", "list.numbering must be 'sequential' or 'one'")
+	}
+
+	if c.List.Indent < 0 {
+		return optionError("list.indent", "list.indent must be >= 0")
+	}
+
+	if !contains([]string{"```", "~~~", "preserve"}, c.Code.FenceStyle) {
+		return optionError("code.fence_style", "code.fence_style must be '```', '~~~', or 'preserve'")
+	}
+
+	if c.Blockquote.MarkerSpacing != "" && !contains([]string{"space", "none"}, c.Blockquote.MarkerSpacing) {
+		return optionError("blockquote.marker_spacing", "blockquote.marker_spacing must be 'space' or 'none'")
+	}
+
+	if c.Blockquote.BlankMarker != "" && !contains([]string{"keep", "strip"}, c.Blockquote.BlankMarker) {
+		return optionError("blockquote.blank_marker", "blockquote.blank_marker must be 'keep' or 'strip'")
+	}
+
+	if c.Admonition.TagCase != "" && !contains([]string{"preserve", "upper", "lower"}, c.Admonition.TagCase) {
+		return optionError("admonition.tag_case", "admonition.tag_case must be 'preserve', 'upper', or 'lower'")
+	}
+
+	if c.Inline.StrikethroughMarker != "" && !contains([]string{"preserve", "~~", "~"}, c.Inline.StrikethroughMarker) {
+		return optionError("inline.strikethrough_marker", "inline.strikethrough_marker must be 'preserve', '~~', or '~'")
+	}
+
+	if c.Links.BareURLStyle != "" && !contains([]string{"bare", "angle", "link"}, c.Links.BareURLStyle) {
+		return optionError("links.bare_url_style", "links.bare_url_style must be 'bare', 'angle', or 'link'")
+	}
+
+	if c.Links.Style != "" && !contains([]string{"preserve", "inline", "reference"}, c.Links.Style) {
+		return optionError("links.style", "links.style must be 'preserve', 'inline', or 'reference'")
+	}
+
+	if c.Links.ReferenceThreshold < 0 {
+		return optionError("links.reference_threshold", "links.reference_threshold must be >= 0")
+	}
+
+	if c.Links.ReferencePlacement != "" && !contains([]string{"document", "section"}, c.Links.ReferencePlacement) {
+		return optionError("links.reference_placement", "links.reference_placement must be 'document' or 'section'")
 	}
 
-	if !contains([]string{"-", "*", "+"}, c.List.BulletStyle) {
-		return fmt.Errorf("list.bullet_style must be '-', '*', or '+'")
+	if c.Links.SortDefinitions != "" && !contains([]string{"preserve", "alphabetical", "first_use"}, c.Links.SortDefinitions) {
+		return optionError("links.sort_definitions", "links.sort_definitions must be 'preserve', 'alphabetical', or 'first_use'")
 	}
 
-	if !contains([]string{".", ")"}, c.List.NumberStyle) {
-		return fmt.Errorf("list.number_style must be '.' or ')'")
+	if c.Images.TitleQuote != "" && !contains([]string{"double", "single", "parentheses"}, c.Images.TitleQuote) {
+		return optionError("images.title_quote", "images.title_quote must be 'double', 'single', or 'parentheses'")
 	}
 
-	if !contains([]string{"```", "~~~"}, c.Code.FenceStyle) {
-		return fmt.Errorf("code.fence_style must be '```' or '~~~'")
+	if c.Images.BadgeLayout != "" && !contains([]string{"preserve", "one_per_line", "single_line"}, c.Images.BadgeLayout) {
+		return optionError("images.badge_layout", "images.badge_layout must be 'preserve', 'one_per_line', or 'single_line'")
+	}
+
+	for term := range c.Replacements {
+		if strings.TrimSpace(term) == "" {
+			return optionError("replacements", "replacements must not contain an empty term")
+		}
+	}
+
+	for _, delimiters := range c.TemplateDelimiters {
+		if len(strings.Fields(delimiters)) != 2 {
+			return optionError("template_delimiters",
+				"template_delimiters entries must be an open and a close delimiter separated by a space, got %q", delimiters)
+		}
+	}
+
+	for _, plugin := range c.Plugins {
+		if strings.TrimSpace(plugin.Name) == "" {
+			return optionError("plugins", "plugins entries must have a name")
+		}
+		if len(plugin.Command) == 0 {
+			return optionError("plugins", "plugin %q must have a command", plugin.Name)
+		}
+		if plugin.Input != "" && !contains([]string{"ast", "text"}, plugin.Input) {
+			return optionError("plugins", "plugin %q: input must be 'ast' or 'text'", plugin.Name)
+		}
 	}
 
 	if c.Whitespace.MaxBlankLines < 0 {
-		return fmt.Errorf("whitespace.max_blank_lines must be >= 0")
+		return optionError("whitespace.max_blank_lines", "whitespace.max_blank_lines must be >= 0")
+	}
+
+	if c.Whitespace.EndOfLine != "" && !contains([]string{"lf", "crlf", "cr"}, c.Whitespace.EndOfLine) {
+		return optionError("whitespace.end_of_line", "whitespace.end_of_line must be 'lf', 'crlf', or 'cr'")
+	}
+
+	if c.Whitespace.HardBreakStyle != "" && !contains([]string{"backslash", "spaces"}, c.Whitespace.HardBreakStyle) {
+		return optionError("whitespace.hard_break_style", "whitespace.hard_break_style must be 'backslash' or 'spaces'")
+	}
+
+	if !contains([]string{"preserve", "spaces"}, c.Whitespace.Tabs) {
+		return optionError("whitespace.tabs", "whitespace.tabs must be 'preserve' or 'spaces'")
+	}
+
+	if c.Whitespace.TabWidth < 1 {
+		return optionError("whitespace.tab_width", "whitespace.tab_width must be >= 1")
+	}
+
+	if c.Files.MaxFileSize < 0 {
+		return optionError("files.max_file_size", "files.max_file_size must be >= 0")
+	}
+
+	if _, err := NewIgnoreMatcher(c.Files.IgnorePatterns); err != nil {
+		return optionError("files.ignore_patterns", "files.ignore_patterns: %w", err)
+	}
+
+	for _, ext := range c.Extensions {
+		if !contains([]string{"math", "highlight"}, ext) {
+			return optionError("extensions",
+				"unknown extension %q: supported extensions are 'math' and 'highlight'", ext)
+		}
 	}
 
 	return nil
@@ -209,33 +731,14 @@ func contains(slice []string, item string) bool {
 // IsMarkdownFile checks if a file is a markdown file based on extension
 func (c *Config) IsMarkdownFile(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
-	return contains(c.Files.Extensions, ext)
-}
-
-// ShouldIgnore checks if a file should be ignored based on patterns.
-func (c *Config) ShouldIgnore(path string) bool {
-	path = filepath.Clean(path)
-
-	for _, pattern := range c.Files.IgnorePatterns {
-		switch {
-		case strings.HasSuffix(pattern, "/**"):
-			// Directory pattern - match if path is under this directory
-			dirPattern := strings.TrimSuffix(pattern, "/**")
-			if strings.HasPrefix(path, dirPattern+"/") || path == dirPattern {
-				return true
-			}
-		case strings.Contains(pattern, "*"):
-			// Wildcard pattern
-			if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
-				return true
-			}
-		default:
-			// Exact match
-			if path == pattern || filepath.Base(path) == pattern {
-				return true
-			}
-		}
-	}
+	return contains(c.MarkdownExtensions(), ext)
+}
 
-	return false
+// MarkdownExtensions returns the extensions of the files to process: those
+// of files.extensions, and .mdx with the mdx dialect
+func (c *Config) MarkdownExtensions() []string {
+	if c.Dialect == "mdx" && !contains(c.Files.Extensions, ".mdx") {
+		return append(append([]string{}, c.Files.Extensions...), ".mdx")
+	}
+	return c.Files.Extensions
 }
@@ -40,7 +40,7 @@ func TestValidate(t *testing.T) {
 		{
 			name: "invalid line width",
 			config: &Config{
-				LineWidth:  0,
+				LineWidth:  -1,
 				Heading:    HeadingConfig{Style: "atx"},
 				List:       ListConfig{BulletStyle: "-", NumberStyle: "."},
 				Code:       CodeConfig{FenceStyle: "```"},
@@ -70,6 +70,96 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid numbering",
+			config: func() *Config {
+				cfg := Default()
+				cfg.List.Numbering = "zero"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "preserve number style",
+			config: func() *Config {
+				cfg := Default()
+				cfg.List.NumberStyle = "preserve"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "invalid blockquote blank marker",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Blockquote.BlankMarker = "remove"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "unsupported format version",
+			config: func() *Config {
+				cfg := Default()
+				cfg.FormatVersion = LatestFormatVersion + 1
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid reflow mode",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Reflow.Mode = "unwrap"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid reflow wrap block",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Reflow.Wrap = []string{"table"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid reflow exclude pattern",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Reflow.Exclude = []string{"^(unclosed"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid hard break style",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Whitespace.HardBreakStyle = "html"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid tab width",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Whitespace.TabWidth = 0
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid marker sequence",
+			config: func() *Config {
+				cfg := Default()
+				cfg.List.MarkerSequence = []string{"-", "#"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -154,6 +244,82 @@ func TestLoadFromFile_InvalidYAML(t *testing.T) {
 	}
 }
 
+func TestLoadFromFile_TOML(t *testing.T) {
+	content := `line_width = 100
+extensions = ["math"]
+
+[heading]
+style = "setext"
+
+[heading.numbering]
+mode = "number"
+start_level = 1
+
+[list]
+bullet_style = "*"
+`
+
+	path := filepath.Join(t.TempDir(), ".mdfmt.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg := Default()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	if cfg.LineWidth != 100 {
+		t.Errorf("Expected LineWidth 100, got %d", cfg.LineWidth)
+	}
+	if cfg.Heading.Style != "setext" {
+		t.Errorf("Expected Heading.Style 'setext', got %s", cfg.Heading.Style)
+	}
+	if cfg.Heading.Numbering.Mode != "number" || cfg.Heading.Numbering.StartLevel != 1 {
+		t.Errorf("Expected numbering from level 1, got %+v", cfg.Heading.Numbering)
+	}
+	if cfg.List.BulletStyle != "*" {
+		t.Errorf("Expected List.BulletStyle '*', got %s", cfg.List.BulletStyle)
+	}
+	if cfg.List.NumberStyle != "." {
+		t.Errorf("Expected List.NumberStyle to keep its default, got %s", cfg.List.NumberStyle)
+	}
+	if len(cfg.Extensions) != 1 || cfg.Extensions[0] != "math" {
+		t.Errorf("Expected extensions [math], got %v", cfg.Extensions)
+	}
+}
+
+func TestLoadFromFile_InvalidTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mdfmt.toml")
+	if err := os.WriteFile(path, []byte("line_width = "), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	if err := Default().LoadFromFile(path); err == nil {
+		t.Error("Expected error for invalid TOML, got nil")
+	}
+}
+
+func TestFindConfigFile_TOML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".mdfmt.toml")
+	if err := os.WriteFile(path, []byte("line_width = 100\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	subdir := filepath.Join(dir, "docs")
+	if err := os.Mkdir(subdir, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	found, err := FindConfigFile(subdir)
+	if err != nil {
+		t.Fatalf("FindConfigFile failed: %v", err)
+	}
+	if found != path {
+		t.Errorf("Expected %s, got %s", path, found)
+	}
+}
+
 func TestSaveToFile(t *testing.T) {
 	cfg := Default()
 	cfg.LineWidth = 120
@@ -197,6 +363,7 @@ func TestIsMarkdownFile(t *testing.T) {
 		{"style.css", false},
 		{"README.MD", true}, // case insensitive
 		{"file.txt", false},
+		{"page.mdx", false},
 	}
 
 	for _, tt := range tests {
@@ -207,6 +374,15 @@ func TestIsMarkdownFile(t *testing.T) {
 			}
 		})
 	}
+
+	// The mdx dialect formats .mdx files too
+	cfg.Dialect = "mdx"
+	if !cfg.IsMarkdownFile("page.mdx") || !cfg.IsMarkdownFile("README.md") {
+		t.Error("Expected .mdx and .md files to be markdown with the mdx dialect")
+	}
+	if got := len(cfg.MarkdownExtensions()); got != len(cfg.Files.Extensions)+1 {
+		t.Errorf("Expected .mdx added to %d extensions, got %d", len(cfg.Files.Extensions), got)
+	}
 }
 
 func TestShouldIgnore(t *testing.T) {
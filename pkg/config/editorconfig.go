@@ -0,0 +1,289 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EditorConfigFileName is the name of EditorConfig files
+const EditorConfigFileName = ".editorconfig"
+
+// editorConfigSection is a [glob] section of an .editorconfig file
+type editorConfigSection struct {
+	pattern    *regexp.Regexp
+	properties map[string]string
+}
+
+// editorConfigFile is a parsed .editorconfig file
+type editorConfigFile struct {
+	root     bool
+	sections []editorConfigSection
+}
+
+// editorConfigProperties returns the EditorConfig properties that apply to
+// path. Files are read from the directory of path upwards until one
+// declares root = true; nearer files and later sections take precedence.
+func editorConfigProperties(path string) (map[string]string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+
+	var dirs []string
+	var files []*editorConfigFile
+	for dir := filepath.Dir(abs); ; {
+		file, err := readEditorConfig(filepath.Join(dir, EditorConfigFileName))
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			dirs = append(dirs, dir)
+			files = append(files, file)
+			if file.root {
+				break
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break // reached root directory
+		}
+		dir = parent
+	}
+
+	properties := make(map[string]string)
+	for i := len(files) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(dirs[i], abs)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, section := range files[i].sections {
+			if !section.pattern.MatchString(rel) {
+				continue
+			}
+			for key, value := range section.properties {
+				if value == "unset" {
+					delete(properties, key)
+					continue
+				}
+				properties[key] = value
+			}
+		}
+	}
+	return properties, nil
+}
+
+// readEditorConfig parses the .editorconfig file at path, returning nil if
+// it does not exist
+func readEditorConfig(path string) (*editorConfigFile, error) {
+	f, err := os.Open(path) // #nosec G304 - path is built from the formatted file's directory
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	file := &editorConfigFile{}
+	var current *editorConfigSection
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			pattern, err := compileEditorConfigGlob(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid section %s in %s: %w", line, path, err)
+			}
+			file.sections = append(file.sections, editorConfigSection{
+				pattern:    pattern,
+				properties: make(map[string]string),
+			})
+			current = &file.sections[len(file.sections)-1]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if current == nil {
+			if key == "root" {
+				file.root = value == "true"
+			}
+			continue
+		}
+		current.properties[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// compileEditorConfigGlob converts an EditorConfig section glob into a
+// regular expression matching slash-separated paths relative to the
+// directory of the .editorconfig file. Globs without a slash match file
+// names in any directory.
+func compileEditorConfigGlob(glob string) (*regexp.Regexp, error) {
+	switch {
+	case strings.HasPrefix(glob, "/"):
+		glob = glob[1:]
+	case !strings.Contains(glob, "/"):
+		glob = "**/" + glob
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	braces := 0
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					// **/ also matches no directory at all
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		case '{':
+			end := strings.IndexByte(glob[i:], '}')
+			if end > 0 {
+				if expr, ok := numericRange(glob[i+1 : i+end]); ok {
+					b.WriteString(expr)
+					i += end
+					continue
+				}
+			}
+			braces++
+			b.WriteString("(?:")
+		case '}':
+			if braces == 0 {
+				b.WriteString(`\}`)
+				continue
+			}
+			braces--
+			b.WriteString(")")
+		case ',':
+			if braces == 0 {
+				b.WriteString(",")
+				continue
+			}
+			b.WriteString("|")
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if braces != 0 {
+		return nil, fmt.Errorf("unbalanced braces in %q", glob)
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// numericRange converts a {num1..num2} glob into an alternation of the
+// numbers in the range
+func numericRange(body string) (string, bool) {
+	from, to, ok := strings.Cut(body, "..")
+	if !ok {
+		return "", false
+	}
+	start, err := strconv.Atoi(from)
+	if err != nil {
+		return "", false
+	}
+	end, err := strconv.Atoi(to)
+	if err != nil {
+		return "", false
+	}
+	if start > end {
+		start, end = end, start
+	}
+
+	numbers := make([]string, 0, end-start+1)
+	for n := start; n <= end; n++ {
+		numbers = append(numbers, strconv.Itoa(n))
+	}
+	return "(?:" + strings.Join(numbers, "|") + ")", true
+}
+
+// editorConfigLayer converts EditorConfig properties into a configuration
+// layer of the corresponding mdfmt options, or nil if none apply. Values
+// mdfmt cannot use, such as max_line_length = off or indent_size = tab, are
+// ignored.
+func editorConfigLayer(properties map[string]string) (*layer, error) {
+	values := make(map[string]interface{})
+	whitespace := make(map[string]interface{})
+	if width, err := strconv.Atoi(properties["max_line_length"]); err == nil && width > 0 {
+		values["line_width"] = width
+	}
+	if contains([]string{"lf", "crlf", "cr"}, properties["end_of_line"]) {
+		whitespace["end_of_line"] = properties["end_of_line"]
+	}
+	if value, err := strconv.ParseBool(properties["insert_final_newline"]); err == nil {
+		whitespace["ensure_final_newline"] = value
+	}
+	if value, err := strconv.ParseBool(properties["trim_trailing_whitespace"]); err == nil {
+		whitespace["trim_trailing_spaces"] = value
+	}
+	if size, err := strconv.Atoi(properties["indent_size"]); err == nil && size > 0 {
+		values["list"] = map[string]interface{}{"indent": size}
+	}
+	if properties["indent_style"] == "tab" {
+		whitespace["tabs"] = "preserve"
+	}
+	if width, err := strconv.Atoi(properties["tab_width"]); err == nil && width > 0 {
+		whitespace["tab_width"] = width
+	}
+	if len(whitespace) > 0 {
+		values["whitespace"] = whitespace
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s settings: %w", EditorConfigFileName, err)
+	}
+	return &layer{source: EditorConfigFileName, data: data}, nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestEditorConfigGlob(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*", "README.md", true},
+		{"*", "docs/guide.md", true},
+		{"*.md", "docs/guide.md", true},
+		{"*.md", "main.go", false},
+		{"*.{md,markdown}", "notes.markdown", true},
+		{"docs/*.md", "docs/guide.md", true},
+		{"docs/*.md", "docs/api/guide.md", false},
+		{"docs/**.md", "docs/api/guide.md", true},
+		{"/README.md", "README.md", true},
+		{"/README.md", "docs/README.md", false},
+		{"chapter{1..3}.md", "chapter2.md", true},
+		{"chapter{1..3}.md", "chapter4.md", false},
+		{"[!a]*.md", "b.md", true},
+		{"[!a]*.md", "a.md", false},
+	}
+
+	for _, tt := range tests {
+		pattern, err := compileEditorConfigGlob(tt.glob)
+		if err != nil {
+			t.Fatalf("compileEditorConfigGlob(%q) failed: %v", tt.glob, err)
+		}
+		if got := pattern.MatchString(tt.path); got != tt.match {
+			t.Errorf("glob %q on %q: expected %v, got %v", tt.glob, tt.path, tt.match, got)
+		}
+	}
+}
+
+func TestEditorConfigProperties(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ".editorconfig"), `root = true
+
+[*]
+end_of_line = crlf
+indent_size = 2
+
+[*.md]
+max_line_length = 100
+trim_trailing_whitespace = false
+`)
+	writeTestFile(t, filepath.Join(root, "docs", ".editorconfig"), `[*.md]
+max_line_length = 72
+indent_size = unset
+`)
+
+	properties, err := editorConfigProperties(filepath.Join(root, "docs", "guide.md"))
+	if err != nil {
+		t.Fatalf("editorConfigProperties failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"end_of_line":              "crlf",
+		"max_line_length":          "72",
+		"trim_trailing_whitespace": "false",
+	}
+	if len(properties) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, properties)
+	}
+	for key, value := range expected {
+		if properties[key] != value {
+			t.Errorf("Expected %s = %s, got %q", key, value, properties[key])
+		}
+	}
+}
+
+func TestForFile(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ".editorconfig"), `root = true
+
+[*.md]
+max_line_length = 100
+end_of_line = crlf
+insert_final_newline = false
+indent_size = 4
+indent_style = tab
+tab_width = 8
+`)
+	configPath := filepath.Join(root, ".mdfmt.yaml")
+	writeTestFile(t, configPath, "line_width: 120\n")
+
+	cfg := Default()
+	if err := cfg.LoadFromFile(configPath); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	fileCfg, err := cfg.ForFile(filepath.Join(root, "README.md"))
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+
+	if fileCfg.LineWidth != 120 {
+		t.Errorf("Expected mdfmt line_width 120 to win, got %d", fileCfg.LineWidth)
+	}
+	if fileCfg.Whitespace.EndOfLine != "crlf" {
+		t.Errorf("Expected EndOfLine crlf, got %s", fileCfg.Whitespace.EndOfLine)
+	}
+	if fileCfg.Whitespace.EnsureFinalNewline {
+		t.Error("Expected EnsureFinalNewline to be false")
+	}
+	if fileCfg.List.Indent != 4 {
+		t.Errorf("Expected List.Indent 4, got %d", fileCfg.List.Indent)
+	}
+	if fileCfg.Whitespace.Tabs != "preserve" || fileCfg.Whitespace.TabWidth != 8 {
+		t.Errorf("Expected tabs preserved at width 8, got %s at %d", fileCfg.Whitespace.Tabs, fileCfg.Whitespace.TabWidth)
+	}
+
+	cfg.EditorConfig = false
+	if fileCfg, _ := cfg.ForFile(filepath.Join(root, "README.md")); fileCfg != cfg {
+		t.Error("Expected ForFile to return the config unchanged when editorconfig is disabled")
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlLinePattern extracts the line number from YAML errors
+var yamlLinePattern = regexp.MustCompile(`\bline (\d+)\b`)
+
+// ConfigError is an invalid configuration. Path, Line and Column locate the
+// offending value in a configuration file when known; Source names where
+// it was set otherwise, such as a preset or the front matter of Path.
+type ConfigError struct {
+	Path   string
+	Line   int
+	Column int
+	Source string
+	// Key is the dotted path of the invalid option, if a single one is
+	Key string
+	Err error
+}
+
+// Error returns the error as path:line:column: message
+func (e *ConfigError) Error() string {
+	message := "invalid configuration"
+	if e.Source != "" {
+		message += " in " + e.Source
+	}
+	message += ": " + e.Err.Error()
+
+	if e.Path == "" {
+		return message
+	}
+	location := e.Path
+	if e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			location += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	return location + ": " + message
+}
+
+// Unwrap returns the underlying error
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// optionError returns the error of the invalid option key
+func optionError(key, format string, args ...interface{}) *ConfigError {
+	return &ConfigError{Key: key, Err: fmt.Errorf(format, args...)}
+}
+
+// yamlError returns the error decoding the configuration data of source,
+// located at the line the YAML decoder reports
+func yamlError(source string, err error) *ConfigError {
+	e := &ConfigError{Err: err}
+	if filepath.IsAbs(source) {
+		e.Path = source
+	} else {
+		e.Source = source
+	}
+	if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil && e.Path != "" {
+		e.Line, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
+
+// locate fills in where the invalid option of err was set: the last layer
+// of c that sets it, with its line and column in configuration files
+func (c *Config) locate(err error) {
+	var e *ConfigError
+	if !errors.As(err, &e) || e.Key == "" || e.Path != "" || e.Source != "" {
+		return
+	}
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		l := c.layers[i]
+		var root yaml.Node
+		if yaml.Unmarshal(l.data, &root) != nil {
+			continue
+		}
+		value := findOption(&root, e.Key)
+		if value == nil {
+			continue
+		}
+		if filepath.IsAbs(l.source) {
+			e.Path, e.Line, e.Column = l.source, value.Line, value.Column
+		} else {
+			e.Source = l.source
+		}
+		return
+	}
+}
+
+// findOption returns the value node of the option at the dotted path in
+// the YAML document root, or of the first option below it
+func findOption(root *yaml.Node, path string) *yaml.Node {
+	var found *yaml.Node
+	walkLeaves(root, "", func(leaf string, _, value *yaml.Node) {
+		if found == nil && (leaf == path || strings.HasPrefix(leaf, path+".")) {
+			found = value
+		}
+	})
+	return found
+}
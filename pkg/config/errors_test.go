@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateLocatesOption(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	path := filepath.Join(dir, ".mdfmt.yaml")
+	if err := os.WriteFile(base, []byte("list:\n  bullet_style: '#'\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("extends: [base.yaml]\nline_width: 100\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	err := cfg.Validate()
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("Expected a *ConfigError, got %v", err)
+	}
+	if configErr.Key != "list.bullet_style" || configErr.Path != base || configErr.Line != 2 || configErr.Column != 17 {
+		t.Errorf("Expected list.bullet_style at %s:2:17, got %s at %s:%d:%d",
+			base, configErr.Key, configErr.Path, configErr.Line, configErr.Column)
+	}
+	expected := base + ":2:17: invalid configuration: list.bullet_style must be '-', '*', '+', or 'preserve'"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestConfigErrorSources(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Set("list.indent", "-1"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	var configErr *ConfigError
+	if err := cfg.Validate(); !errors.As(err, &configErr) || configErr.Source != CommandLineSource {
+		t.Errorf("Expected an error from the command line, got %v", err)
+	}
+
+	_, err := Default().WithFrontMatter([]byte("title: Guide\nmdfmt:\n  line_width: -1\n"))
+	if !errors.As(err, &configErr) || configErr.Source != FrontMatterSource || configErr.Line != 4 {
+		t.Errorf("Expected an error at line 4 of the front matter, got %#v", configErr)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.yaml")
+	if err := os.WriteFile(path, []byte("line_width: 80\nlist:\n  indent: many\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err = Default().LoadFromFile(path)
+	if !errors.As(err, &configErr) || configErr.Path != path || configErr.Line != 3 {
+		t.Errorf("Expected a YAML error at %s:3, got %v", path, err)
+	}
+}
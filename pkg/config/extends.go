@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PresetPathEnv lists extra directories searched for shared configurations,
+// separated by the OS path list separator
+const PresetPathEnv = "MDFMT_PRESET_PATH"
+
+// presets are the built-in configurations that can be extended by name
+var presets = map[string]string{
+	// changelog formats changelogs following Keep a Changelog
+	"changelog": `changelog:
+  enabled: true
+`,
+	// github matches the markdown style of GitHub's own documentation
+	"github": `dialect: gfm
+heading:
+  style: atx
+list:
+  bullet_style: "-"
+  number_style: "."
+code:
+  fence_style: "` + "```" + `"
+links:
+  bare_url_style: bare
+whitespace:
+  max_blank_lines: 1
+  trim_trailing_spaces: true
+  ensure_final_newline: true
+`,
+	// prettier-compat produces the same block markers as Prettier's markdown formatter
+	"prettier-compat": `line_width: 80
+heading:
+  style: atx
+list:
+  bullet_style: "-"
+  number_style: "."
+code:
+  fence_style: "` + "```" + `"
+inline:
+  strikethrough_marker: "~~"
+whitespace:
+  max_blank_lines: 1
+  trim_trailing_spaces: true
+  ensure_final_newline: true
+  end_of_line: lf
+`,
+	// strict normalizes everything mdfmt can normalize
+	"strict": `extends: [github]
+line_width: 80
+heading:
+  normalize_levels: true
+list:
+  consistent_indentation: true
+inline:
+  strikethrough_marker: "~~"
+links:
+  bare_url_style: angle
+whitespace:
+  end_of_line: lf
+`,
+}
+
+// Presets returns the names of the built-in presets
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// layer is the configuration data of one source
+type layer struct {
+	// source names where the data came from: a file path, a preset or .editorconfig
+	source string
+	data   []byte
+}
+
+// configSource is a configuration file or built-in preset
+type configSource struct {
+	// id identifies the source in cycle detection and errors
+	id string
+	// dir resolves relative paths in extends
+	dir string
+	// data is the YAML (or JSON) content of the source
+	data []byte
+}
+
+// readConfigSource reads the configuration file at path, converting TOML to YAML
+func readConfigSource(path string) (*configSource, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config path %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(abs) // #nosec G304 - path is user provided or referenced by a config file
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(abs), ".toml") {
+		if data, err = tomlToYAML(data); err != nil {
+			return nil, err
+		}
+	}
+	return &configSource{id: abs, dir: filepath.Dir(abs), data: data}, nil
+}
+
+// layers returns the configuration layers of s: the layers of the
+// configurations it extends, in order, followed by s itself. chain holds
+// the sources being resolved, to detect cycles.
+func (s *configSource) layers(chain []string) ([]layer, error) {
+	for i, id := range chain {
+		if id == s.id {
+			cycle := append(append([]string{}, chain[i:]...), s.id)
+			return nil, fmt.Errorf("config extends cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	chain = append(append([]string{}, chain...), s.id)
+
+	var header struct {
+		Extends []string `yaml:"extends"`
+	}
+	if err := yaml.Unmarshal(s.data, &header); err != nil {
+		return nil, yamlError(s.id, err)
+	}
+
+	var layers []layer
+	for _, ref := range header.Extends {
+		base, err := resolveExtends(ref, s.dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve extends %q in %s: %w", ref, s.id, err)
+		}
+		baseLayers, err := base.layers(chain)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, baseLayers...)
+	}
+	return append(layers, layer{source: s.id, data: s.data}), nil
+}
+
+// resolveExtends finds the configuration referenced by an extends entry: a
+// built-in preset name, a path relative to dir, or the name of a shared
+// configuration in a preset directory
+func resolveExtends(ref, dir string) (*configSource, error) {
+	if data, ok := presets[ref]; ok {
+		return &configSource{id: "preset " + ref, dir: dir, data: []byte(data)}, nil
+	}
+
+	if isPathReference(ref) {
+		path := ref
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		return readConfigSource(path)
+	}
+
+	for _, presetDir := range presetDirs() {
+		if path, ok := findSharedConfig(filepath.Join(presetDir, filepath.FromSlash(ref))); ok {
+			return readConfigSource(path)
+		}
+	}
+	return nil, fmt.Errorf("unknown preset or shared config (built-in presets: %s; shared configs are searched in $%s and %s)",
+		strings.Join(Presets(), ", "), PresetPathEnv, filepath.Join("<user config dir>", "mdfmt", "presets"))
+}
+
+// isPathReference reports whether an extends entry is a file path rather
+// than a preset or shared config name
+func isPathReference(ref string) bool {
+	if filepath.IsAbs(ref) || strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../") {
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(ref)) {
+	case ".yaml", ".yml", ".json", ".toml":
+		return true
+	}
+	return false
+}
+
+// presetDirs returns the directories searched for shared configurations
+func presetDirs() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv(PresetPathEnv)) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "mdfmt", "presets"))
+	}
+	return dirs
+}
+
+// findSharedConfig finds a shared configuration stored as base plus a
+// config file extension, or as a directory holding a config file
+func findSharedConfig(base string) (string, bool) {
+	for _, ext := range []string{".yaml", ".yml", ".json", ".toml"} {
+		if info, err := os.Stat(base + ext); err == nil && !info.IsDir() {
+			return base + ext, true
+		}
+	}
+	if info, err := os.Stat(base); err == nil && info.IsDir() {
+		if path, err := FindConfigFile(base); err == nil && filepath.Dir(path) == base {
+			return path, true
+		}
+	}
+	return "", false
+}
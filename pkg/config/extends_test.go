@@ -0,0 +1,103 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPresetsAreValid(t *testing.T) {
+	for _, name := range Presets() {
+		dir := t.TempDir()
+		path := filepath.Join(dir, ".mdfmt.yaml")
+		writeTestFile(t, path, "extends: ["+name+"]\n")
+
+		cfg := Default()
+		if err := cfg.LoadFromFile(path); err != nil {
+			t.Errorf("Preset %s failed to load: %v", name, err)
+			continue
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Preset %s is invalid: %v", name, err)
+		}
+	}
+}
+
+func TestExtendsDeepMerge(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "base", "base.mdfmt.yaml"), `extends: [github]
+line_width: 100
+list:
+  bullet_style: "*"
+  number_style: ")"
+`)
+	path := filepath.Join(dir, ".mdfmt.yaml")
+	writeTestFile(t, path, `extends: ["./base/base.mdfmt.yaml"]
+list:
+  bullet_style: "+"
+`)
+
+	cfg := Default()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	if cfg.List.BulletStyle != "+" {
+		t.Errorf("Expected the extending file to win, got bullet style %s", cfg.List.BulletStyle)
+	}
+	if cfg.List.NumberStyle != ")" {
+		t.Errorf("Expected number style from the base file, got %s", cfg.List.NumberStyle)
+	}
+	if cfg.LineWidth != 100 {
+		t.Errorf("Expected line width from the base file, got %d", cfg.LineWidth)
+	}
+	if cfg.Whitespace.MaxBlankLines != 1 {
+		t.Errorf("Expected max blank lines from the github preset, got %d", cfg.Whitespace.MaxBlankLines)
+	}
+}
+
+func TestExtendsSharedConfig(t *testing.T) {
+	presetDir := t.TempDir()
+	writeTestFile(t, filepath.Join(presetDir, "company", "mdfmt-config", ".mdfmt.yaml"), "line_width: 72\n")
+	writeTestFile(t, filepath.Join(presetDir, "team.toml"), "[code]\nfence_style = \"~~~\"\n")
+	t.Setenv(PresetPathEnv, presetDir)
+
+	path := filepath.Join(t.TempDir(), ".mdfmt.yaml")
+	writeTestFile(t, path, "extends: [company/mdfmt-config, team]\n")
+
+	cfg := Default()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.LineWidth != 72 {
+		t.Errorf("Expected line width from the shared config, got %d", cfg.LineWidth)
+	}
+	if cfg.Code.FenceStyle != "~~~" {
+		t.Errorf("Expected fence style from the shared TOML config, got %s", cfg.Code.FenceStyle)
+	}
+}
+
+func TestExtendsCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.yaml"), "extends: [./b.yaml]\n")
+	writeTestFile(t, filepath.Join(dir, "b.yaml"), "extends: [./a.yaml]\n")
+
+	err := Default().LoadFromFile(filepath.Join(dir, "a.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("Expected a cycle error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "a.yaml -> ") || !strings.Contains(err.Error(), "b.yaml -> ") {
+		t.Errorf("Expected the cycle to name both files, got %v", err)
+	}
+}
+
+func TestExtendsUnknown(t *testing.T) {
+	t.Setenv(PresetPathEnv, t.TempDir())
+	path := filepath.Join(t.TempDir(), ".mdfmt.yaml")
+	writeTestFile(t, path, "extends: [nonexistent]\n")
+
+	err := Default().LoadFromFile(path)
+	if err == nil || !strings.Contains(err.Error(), "github, prettier-compat, strict") {
+		t.Errorf("Expected an error listing the built-in presets, got %v", err)
+	}
+}
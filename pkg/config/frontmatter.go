@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FrontMatterKey is the front matter key holding per-document options
+	FrontMatterKey = "mdfmt"
+	// FrontMatterSource is the source of options set in a document's front matter
+	FrontMatterSource = "front matter"
+)
+
+// WithFrontMatter returns the configuration for a document with the YAML
+// front matter data: the options under its mdfmt key merged over c,
+// beneath any command-line overrides. c is returned unchanged when the
+// front matter has no mdfmt key or is not valid YAML, as front matter
+// belongs to the document and is copied verbatim. The lines of errors are
+// those of the document, assuming its front matter starts on line 1.
+func (c *Config) WithFrontMatter(data []byte) (*Config, error) {
+	var matter map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &matter); err != nil {
+		return c, nil
+	}
+	options, ok := matter[FrontMatterKey]
+	if !ok || options.Kind == yaml.ScalarNode && options.Tag == "!!null" {
+		return c, nil
+	}
+	if options.Kind != yaml.MappingNode {
+		return nil, &ConfigError{
+			Line: options.Line + 1, Column: options.Column, Source: FrontMatterSource, Key: FrontMatterKey,
+			Err: fmt.Errorf("front matter %s must be a mapping of options", FrontMatterKey),
+		}
+	}
+
+	defaults := optionDefaults()
+	var unknown string
+	var unknownKey *yaml.Node
+	walkLeaves(&options, "", func(path string, key, _ *yaml.Node) {
+		if _, ok := defaults[path]; !ok && unknown == "" {
+			unknown, unknownKey = path, key
+		}
+	})
+	if unknown != "" {
+		return nil, &ConfigError{
+			Line: unknownKey.Line + 1, Column: unknownKey.Column, Source: FrontMatterSource, Key: unknown,
+			Err: fmt.Errorf("unknown config option %q", unknown),
+		}
+	}
+
+	layerData, err := yaml.Marshal(&options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode front matter options: %w", err)
+	}
+	cfg, err := c.withLayers([]layer{{source: FrontMatterSource, data: layerData}})
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		// Lines are counted in the document, whose first line is the
+		// opening delimiter
+		var configErr *ConfigError
+		if errors.As(err, &configErr) && configErr.Source == FrontMatterSource {
+			if value := findOption(&options, configErr.Key); value != nil {
+				configErr.Line, configErr.Column = value.Line+1, value.Column
+			}
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
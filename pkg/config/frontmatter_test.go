@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithFrontMatter(t *testing.T) {
+	base := Default()
+	if err := base.Set("heading.style", "setext"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	cfg, err := base.WithFrontMatter([]byte("title: API\nmdfmt:\n  line_width: 120\n  heading:\n    style: atx\n"))
+	if err != nil {
+		t.Fatalf("WithFrontMatter failed: %v", err)
+	}
+	if cfg.LineWidth != 120 {
+		t.Errorf("Expected line width from front matter, got %d", cfg.LineWidth)
+	}
+	if cfg.Heading.Style != "setext" {
+		t.Errorf("Expected the command-line override to win, got %s", cfg.Heading.Style)
+	}
+	if base.LineWidth != DefaultLineWidth {
+		t.Error("Expected the base configuration to be unchanged")
+	}
+
+	sources, err := cfg.Sources()
+	if err != nil {
+		t.Fatalf("Sources failed: %v", err)
+	}
+	if sources["line_width"] != FrontMatterSource {
+		t.Errorf("Expected line_width from front matter, got %q", sources["line_width"])
+	}
+}
+
+func TestWithFrontMatterUnchanged(t *testing.T) {
+	base := Default()
+	for _, data := range []string{"title: Hi\n", "mdfmt:\n", "{not yaml"} {
+		cfg, err := base.WithFrontMatter([]byte(data))
+		if err != nil || cfg != base {
+			t.Errorf("Expected %q to leave the configuration unchanged, got %v", data, err)
+		}
+	}
+}
+
+func TestWithFrontMatterErrors(t *testing.T) {
+	tests := []struct {
+		data, message string
+	}{
+		{"mdfmt: 120\n", "must be a mapping"},
+		{"mdfmt:\n  line_widht: 120\n", `unknown config option "line_widht"`},
+		{"mdfmt:\n  line_width: -1\n", "invalid configuration in front matter"},
+	}
+	for _, tt := range tests {
+		_, err := Default().WithFrontMatter([]byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("WithFrontMatter(%q): expected error containing %q, got %v", tt.data, tt.message, err)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ignoreRule is a compiled gitignore-style pattern
+type ignoreRule struct {
+	// pattern matches the slash-separated paths the rule applies to
+	pattern *regexp.Regexp
+	// contents matches the directory of a "dir/**" rule, whose contents are
+	// all matched
+	contents *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	// glob is the pattern without its "!", leading and trailing slashes
+	glob     string
+	anchored bool
+	// source is the pattern as written
+	source string
+}
+
+// ignoreTrie indexes anchored rules by the literal path segments they
+// start with
+type ignoreTrie struct {
+	children map[string]*ignoreTrie
+	// rules holds the indexes of the rules whose literal segments end here
+	rules []int
+}
+
+// IgnoreMatcher matches paths against gitignore-style patterns: "*", "?"
+// and "[...]" match within a path segment, "**" matches across segments, a
+// pattern containing a slash is anchored to the root while others match at
+// any depth, a trailing slash matches directories only, and a leading "!"
+// re-includes paths excluded by earlier patterns. As in git, a path inside
+// an excluded directory cannot be re-included.
+//
+// Patterns are indexed when compiled so that matching a path only runs the
+// regular expressions of the rules that can match it: literal names and
+// paths and "*.ext" suffixes are looked up in maps, and anchored globs are
+// found through a trie of their leading literal segments. Results for
+// directories are remembered, as every file below them checks them again.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+	// names indexes the rules matching a literal name at any depth
+	names map[string][]int
+	// paths indexes the rules matching a literal path from the root
+	paths map[string][]int
+	// suffixes indexes the rules matching names with a literal suffix, such
+	// as "*.tmp.md", by the suffix from its first "."
+	suffixes map[string][]int
+	// prefixes holds the other anchored rules
+	prefixes ignoreTrie
+	// globs holds the indexes of the remaining rules, in order
+	globs []int
+
+	mu sync.Mutex
+	// dirs remembers the index of the rule ignoring each directory checked,
+	// or -1 if none does
+	dirs map[string]int
+}
+
+// NewIgnoreMatcher compiles patterns into a matcher. Empty patterns and
+// patterns starting with "#" are skipped.
+func NewIgnoreMatcher(patterns []string) (*IgnoreMatcher, error) {
+	m := &IgnoreMatcher{}
+	for _, pattern := range patterns {
+		rule, ok, err := compileIgnoreRule(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+		if ok {
+			m.rules = append(m.rules, rule)
+			m.index(len(m.rules) - 1)
+		}
+	}
+	return m, nil
+}
+
+// index adds the rule at i to the index that can find it fastest
+func (m *IgnoreMatcher) index(i int) {
+	glob, anchored := m.rules[i].glob, m.rules[i].anchored
+	switch {
+	case isLiteralGlob(glob) && anchored:
+		m.paths = appendIndex(m.paths, glob, i)
+	case isLiteralGlob(glob):
+		m.names = appendIndex(m.names, glob, i)
+	case !anchored && strings.HasPrefix(glob, "*.") && isLiteralGlob(glob[1:]):
+		m.suffixes = appendIndex(m.suffixes, glob[1:], i)
+	case anchored:
+		node := &m.prefixes
+		for _, segment := range strings.Split(glob, "/") {
+			if !isLiteralGlob(segment) {
+				break
+			}
+			if node.children == nil {
+				node.children = make(map[string]*ignoreTrie)
+			}
+			child, ok := node.children[segment]
+			if !ok {
+				child = &ignoreTrie{}
+				node.children[segment] = child
+			}
+			node = child
+		}
+		node.rules = append(node.rules, i)
+	default:
+		m.globs = append(m.globs, i)
+	}
+}
+
+// Match reports whether the path, relative to the root the patterns are
+// anchored to, is ignored. isDir tells whether path is a directory.
+// Paths may use the separator of the platform, as patterns are matched
+// against their slash-separated form.
+func (m *IgnoreMatcher) Match(path string, isDir bool) bool {
+	_, ignored := m.MatchingPattern(path, isDir)
+	return ignored
+}
+
+// MatchingPattern returns the pattern, as written, that ignores path, or
+// false if path is not ignored. For a path inside an ignored directory, it
+// is the pattern ignoring the directory.
+func (m *IgnoreMatcher) MatchingPattern(path string, isDir bool) (string, bool) {
+	path = slashPath(path)
+	if path == "." || path == "" || len(m.rules) == 0 {
+		return "", false
+	}
+
+	rule := -1
+	for i := 0; i < len(path) && rule < 0; i++ {
+		if path[i] == '/' {
+			rule = m.matchDir(path[:i])
+		}
+	}
+	switch {
+	case rule >= 0:
+	case isDir:
+		rule = m.matchDir(path)
+	default:
+		rule = m.matchPath(path, false)
+	}
+	if rule < 0 {
+		return "", false
+	}
+	return m.rules[rule].source, true
+}
+
+// SkipDir reports whether the directory at path and everything below it
+// is ignored, so a walk need not descend into it. This holds when the
+// directory itself is ignored, or its contents are matched by a "dir/**"
+// rule that no later negation can override.
+func (m *IgnoreMatcher) SkipDir(path string) bool {
+	_, skip := m.SkipDirPattern(path)
+	return skip
+}
+
+// SkipDirPattern returns the pattern, as written, for which SkipDir skips
+// the directory at path, or false if it does not
+func (m *IgnoreMatcher) SkipDirPattern(path string) (string, bool) {
+	if pattern, ok := m.MatchingPattern(path, true); ok {
+		return pattern, true
+	}
+
+	path = slashPath(path)
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		rule := m.rules[i]
+		if rule.negate {
+			return "", false
+		}
+		if rule.contents != nil && rule.contents.MatchString(path) {
+			return rule.source, true
+		}
+	}
+	return "", false
+}
+
+// matchDir applies the rules to the directory at path, remembering the
+// result
+func (m *IgnoreMatcher) matchDir(path string) int {
+	m.mu.Lock()
+	rule, ok := m.dirs[path]
+	m.mu.Unlock()
+	if ok {
+		return rule
+	}
+
+	rule = m.matchPath(path, true)
+	m.mu.Lock()
+	if m.dirs == nil {
+		m.dirs = make(map[string]int)
+	}
+	m.dirs[path] = rule
+	m.mu.Unlock()
+	return rule
+}
+
+// matchPath applies the rules to a single path and returns the index of
+// the rule ignoring it, or -1 if none does; the last matching rule wins
+func (m *IgnoreMatcher) matchPath(path string, isDir bool) int {
+	last := -1
+	consider := func(indexes []int) {
+		for _, i := range indexes {
+			if i > last && (isDir || !m.rules[i].dirOnly) {
+				last = i
+			}
+		}
+	}
+	// try runs the expressions of the rules at indexes, which are in
+	// order, from the last down to the last rule already matched
+	try := func(indexes []int) {
+		for j := len(indexes) - 1; j >= 0 && indexes[j] > last; j-- {
+			rule := m.rules[indexes[j]]
+			if (isDir || !rule.dirOnly) && rule.pattern.MatchString(path) {
+				last = indexes[j]
+				return
+			}
+		}
+	}
+
+	name := path[strings.LastIndexByte(path, '/')+1:]
+	consider(m.paths[path])
+	consider(m.names[name])
+	for i := 0; i < len(name); i++ {
+		if name[i] == '.' {
+			consider(m.suffixes[name[i:]])
+		}
+	}
+
+	node := &m.prefixes
+	try(node.rules)
+	for _, segment := range strings.Split(path, "/") {
+		if node = node.children[segment]; node == nil {
+			break
+		}
+		try(node.rules)
+	}
+	try(m.globs)
+
+	if last < 0 || m.rules[last].negate {
+		return -1
+	}
+	return last
+}
+
+// ignoreMatchers caches the matchers compiled by ShouldIgnore by their
+// patterns
+var ignoreMatchers sync.Map
+
+// ShouldIgnore checks if a file should be ignored based on patterns. path
+// is relative to the directory the patterns are anchored to; invalid
+// patterns, which Validate rejects, never match.
+func (c *Config) ShouldIgnore(path string) bool {
+	key := strings.Join(c.Files.IgnorePatterns, "\n")
+	if m, ok := ignoreMatchers.Load(key); ok {
+		return m.(*IgnoreMatcher).Match(path, false)
+	}
+	m, err := NewIgnoreMatcher(c.Files.IgnorePatterns)
+	if err != nil {
+		return false
+	}
+	ignoreMatchers.Store(key, m)
+	return m.Match(path, false)
+}
+
+// slashPath returns the clean, slash-separated form of a relative path,
+// such as "docs/guide.md" for ".\docs\guide.md" on Windows
+func slashPath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
+
+// appendIndex adds i to the indexes stored under key
+func appendIndex(index map[string][]int, key string, i int) map[string][]int {
+	if index == nil {
+		index = make(map[string][]int)
+	}
+	index[key] = append(index[key], i)
+	return index
+}
+
+// isLiteralGlob reports whether glob has no wildcards or escapes
+func isLiteralGlob(glob string) bool {
+	return !strings.ContainsAny(glob, "*?[\\")
+}
+
+// compileIgnoreRule compiles a single pattern, reporting false for blank
+// lines and comments
+func compileIgnoreRule(pattern string) (ignoreRule, bool, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return ignoreRule{}, false, nil
+	}
+
+	rule := ignoreRule{source: pattern}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return ignoreRule{}, false, nil
+	}
+	rule.glob, rule.anchored = pattern, anchored
+
+	expr := ignoreGlobToRegexp(pattern)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+
+	var err error
+	if rule.pattern, err = regexp.Compile("^" + expr + "$"); err != nil {
+		return ignoreRule{}, false, err
+	}
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok && !rule.negate {
+		if rule.contents, err = regexp.Compile("^" + ignoreGlobToRegexp(dir) + "$"); err != nil {
+			return ignoreRule{}, false, err
+		}
+	}
+	return rule, true, nil
+}
+
+// ignoreGlobToRegexp converts a gitignore glob into a regular expression
+func ignoreGlobToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					// **/ also matches no directory at all
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
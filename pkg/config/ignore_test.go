@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	m, err := NewIgnoreMatcher([]string{
+		"# comment",
+		"**/generated/*.md",
+		"*.tmp.md",
+		"/CHANGELOG.md",
+		"build/",
+		"docs/**",
+		"!docs/keep.md",
+		"drafts/",
+		"!drafts/keep.md",
+	})
+	if err != nil {
+		t.Fatalf("NewIgnoreMatcher failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"README.md", false, false},
+		{"generated/api.md", false, true},
+		{"pkg/web/generated/api.md", false, true},
+		{"generated/sub/api.md", false, false},
+		{"notes.tmp.md", false, true},
+		{"deep/dir/notes.tmp.md", false, true},
+		{"CHANGELOG.md", false, true},
+		{"pkg/CHANGELOG.md", false, false},
+		{"build/out.md", false, true},
+		{"pkg/build/out.md", false, true},
+		{"build", false, false},
+		{"build", true, true},
+		{"docs", true, false},
+		{"docs/guide.md", false, true},
+		{"docs/keep.md", false, false},
+		{"drafts/keep.md", false, true},
+		{"./CHANGELOG.md", false, true},
+	}
+	for _, tt := range tests {
+		if result := m.Match(tt.path, tt.isDir); result != tt.expected {
+			t.Errorf("Match(%q, %v) = %v, expected %v", tt.path, tt.isDir, result, tt.expected)
+		}
+	}
+}
+
+func TestIgnoreMatcherMatchingPattern(t *testing.T) {
+	m, err := NewIgnoreMatcher([]string{"*.tmp.md", "build/", "/docs/**", "!docs/keep.md"})
+	if err != nil {
+		t.Fatalf("NewIgnoreMatcher failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected string
+		ok       bool
+	}{
+		{"notes.tmp.md", false, "*.tmp.md", true},
+		{"build/out.md", false, "build/", true},
+		{"docs/guide.md", false, "/docs/**", true},
+		{"docs/keep.md", false, "", false},
+		{"README.md", false, "", false},
+	}
+	for _, tt := range tests {
+		pattern, ok := m.MatchingPattern(tt.path, tt.isDir)
+		if pattern != tt.expected || ok != tt.ok {
+			t.Errorf("MatchingPattern(%q, %v) = %q, %v, expected %q, %v", tt.path, tt.isDir, pattern, ok, tt.expected, tt.ok)
+		}
+	}
+
+	if pattern, ok := m.SkipDirPattern("docs"); ok {
+		t.Errorf("SkipDirPattern(docs) = %q, expected no pattern before a negation", pattern)
+	}
+	m, err = NewIgnoreMatcher([]string{"node_modules/**"})
+	if err != nil {
+		t.Fatalf("NewIgnoreMatcher failed: %v", err)
+	}
+	if pattern, ok := m.SkipDirPattern("node_modules"); !ok || pattern != "node_modules/**" {
+		t.Errorf("SkipDirPattern(node_modules) = %q, %v, expected node_modules/**", pattern, ok)
+	}
+}
+
+func TestIgnoreMatcherSkipDir(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		dir      string
+		expected bool
+	}{
+		{[]string{"node_modules/**"}, "node_modules", true},
+		{[]string{"out/"}, "out", true},
+		{[]string{"node_modules/**"}, "docs", false},
+		// a later negation may re-include files inside the directory
+		{[]string{"vendor/**", "!vendor/README.md"}, "vendor", false},
+		// paths use the separator of the platform
+		{[]string{"docs/build/**"}, filepath.FromSlash("./docs/build"), true},
+		{[]string{"docs/build/"}, filepath.FromSlash("docs/build/api"), true},
+	}
+	for _, tt := range tests {
+		m, err := NewIgnoreMatcher(tt.patterns)
+		if err != nil {
+			t.Fatalf("NewIgnoreMatcher failed: %v", err)
+		}
+		if result := m.SkipDir(tt.dir); result != tt.expected {
+			t.Errorf("SkipDir(%q) with %v = %v, expected %v", tt.dir, tt.patterns, result, tt.expected)
+		}
+	}
+}
+
+// ignorePatterns mixes every kind of pattern the matcher indexes
+var ignorePatterns = []string{
+	"node_modules/", "*.tmp.md", "CHANGELOG.md", "/docs/api.md", "docs/**", "!docs/keep.md",
+	"**/generated/*.md", "pkg/*/testdata/", "build*", "!build.md", "vendor/**/README.md", "a?c.md",
+}
+
+// matchAll applies every rule of m to each prefix of path, without the
+// indexes, as a reference for Match
+func matchAll(m *IgnoreMatcher, path string, isDir bool) bool {
+	segments := strings.Split(path, "/")
+	for i := 1; i <= len(segments); i++ {
+		prefix, dir := strings.Join(segments[:i], "/"), i < len(segments) || isDir
+		ignored := false
+		for _, rule := range m.rules {
+			if (dir || !rule.dirOnly) && rule.pattern.MatchString(prefix) {
+				ignored = !rule.negate
+			}
+		}
+		if ignored {
+			return true
+		}
+	}
+	return false
+}
+
+func TestIgnoreMatcherIndexes(t *testing.T) {
+	m, err := NewIgnoreMatcher(ignorePatterns)
+	if err != nil {
+		t.Fatalf("NewIgnoreMatcher failed: %v", err)
+	}
+
+	dirs := []string{"", "docs/", "pkg/web/", "pkg/web/testdata/", "node_modules/x/", "vendor/a/b/", "src/generated/", "build/"}
+	names := []string{"README.md", "CHANGELOG.md", "api.md", "keep.md", "x.tmp.md", "abc.md", "build.md", "buildx", "generated"}
+	for _, dir := range dirs {
+		for _, name := range names {
+			for _, isDir := range []bool{false, true} {
+				path := dir + name
+				if got, expected := m.Match(path, isDir), matchAll(m, path, isDir); got != expected {
+					t.Errorf("Match(%q, %v) = %v, expected %v", path, isDir, got, expected)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkIgnoreMatcher_Match(b *testing.B) {
+	m, err := NewIgnoreMatcher(ignorePatterns)
+	if err != nil {
+		b.Fatalf("NewIgnoreMatcher failed: %v", err)
+	}
+	paths := make([]string, 1000)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("pkg/module%d/sub%d/file%d.md", i%50, i%7, i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Match(paths[i%len(paths)], false)
+	}
+}
+
+func TestValidateIgnorePatterns(t *testing.T) {
+	cfg := Default()
+	cfg.Files.IgnorePatterns = []string{"[z-a].md"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an invalid ignore pattern to be rejected")
+	}
+}
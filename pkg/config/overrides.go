@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommandLineSource is the source of options set on the command line
+const CommandLineSource = "command line"
+
+// Options returns the dotted paths of all configuration options, such as
+// "list.bullet_style", sorted. extends is not an option, as it only takes
+// effect in configuration files.
+func Options() []string {
+	defaults := optionDefaults()
+	options := make([]string, 0, len(defaults))
+	for path := range defaults {
+		options = append(options, path)
+	}
+	sort.Strings(options)
+	return options
+}
+
+// optionDefaults returns the YAML nodes of the default option values, keyed
+// by dotted option path
+func optionDefaults() map[string]*yaml.Node {
+	defaults := make(map[string]*yaml.Node)
+	var root yaml.Node
+	if err := root.Encode(Default()); err != nil {
+		return defaults
+	}
+	walkLeaves(&root, "", func(path string, _, value *yaml.Node) {
+		defaults[path] = value
+	})
+	return defaults
+}
+
+// Set overrides the option at the dotted path key with value. Values of
+// non-string options are parsed as YAML, so numbers, booleans and lists
+// such as "[math]" have their usual meaning. Overrides are kept as a
+// configuration layer, so they also take precedence over per-file
+// .editorconfig settings.
+func (c *Config) Set(key, value string) error {
+	defaultValue, ok := optionDefaults()[key]
+	if !ok {
+		return &ConfigError{Source: CommandLineSource, Key: key, Err: fmt.Errorf("unknown config option %q", key)}
+	}
+
+	var parsed interface{} = value
+	if defaultValue.Tag != "!!str" {
+		if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+			return &ConfigError{Source: CommandLineSource, Key: key,
+				Err: fmt.Errorf("invalid value %q for %s: %w", value, key, err)}
+		}
+	}
+	parts := strings.Split(key, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		parsed = map[string]interface{}{parts[i]: parsed}
+	}
+
+	data, err := yaml.Marshal(parsed)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return &ConfigError{Source: CommandLineSource, Key: key,
+			Err: fmt.Errorf("invalid value %q for %s: %w", value, key, err)}
+	}
+	c.layers = append(c.layers, layer{source: CommandLineSource, data: data})
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	cfg := Default()
+	overrides := map[string]string{
+		"line_width":                      "100",
+		"list.bullet_style":               "*",
+		"whitespace.ensure_final_newline": "false",
+		"extensions":                      "[math]",
+	}
+	for key, value := range overrides {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatalf("Set(%s, %s) failed: %v", key, value, err)
+		}
+	}
+
+	if cfg.LineWidth != 100 {
+		t.Errorf("Expected line width 100, got %d", cfg.LineWidth)
+	}
+	if cfg.List.BulletStyle != "*" {
+		t.Errorf("Expected bullet style *, got %s", cfg.List.BulletStyle)
+	}
+	if cfg.Whitespace.EnsureFinalNewline {
+		t.Error("Expected EnsureFinalNewline to be false")
+	}
+	if len(cfg.Extensions) != 1 || cfg.Extensions[0] != "math" {
+		t.Errorf("Expected extensions [math], got %v", cfg.Extensions)
+	}
+	if cfg.Heading.Style != "atx" {
+		t.Errorf("Expected other options to keep their values, got heading style %s", cfg.Heading.Style)
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	tests := []struct {
+		key, value, message string
+	}{
+		{"line_widht", "100", "unknown config option"},
+		{"heading", "atx", "unknown config option"},
+		{"line_width", "wide", "invalid value"},
+	}
+	for _, tt := range tests {
+		err := Default().Set(tt.key, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Set(%s, %s): expected error containing %q, got %v", tt.key, tt.value, tt.message, err)
+		}
+	}
+}
+
+func TestSetOverridesEditorConfig(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ".editorconfig"), "root = true\n\n[*.md]\nmax_line_length = 100\n")
+
+	cfg := Default()
+	if err := cfg.Set("line_width", "60"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	fileCfg, err := cfg.ForFile(filepath.Join(root, "README.md"))
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	if fileCfg.LineWidth != 60 {
+		t.Errorf("Expected the command-line value to win, got %d", fileCfg.LineWidth)
+	}
+
+	sources, err := fileCfg.Sources()
+	if err != nil {
+		t.Fatalf("Sources failed: %v", err)
+	}
+	if sources["line_width"] != CommandLineSource {
+		t.Errorf("Expected line_width from the command line, got %q", sources["line_width"])
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// Resolver resolves the configuration of each file being formatted. Nested
+// configuration files are resolved once per directory.
+type Resolver struct {
+	base *Config
+	// root is the directory of the root configuration file; nested
+	// configuration files are looked up below it
+	root   string
+	nested bool
+
+	mu   sync.Mutex
+	dirs map[string]*Config
+}
+
+// NewResolver returns a Resolver that applies the .editorconfig settings of
+// each file beneath base
+func NewResolver(base *Config) *Resolver {
+	return &Resolver{base: base}
+}
+
+// NewNestedResolver returns a Resolver that also merges the configuration
+// files in each file's directory and its parents, up to but excluding
+// root, over base, nearer files taking precedence. root is the directory of
+// the configuration file base was loaded from; if it is empty, directories
+// up to the filesystem root are searched. Options set with Set keep
+// precedence over nested files.
+func NewNestedResolver(base *Config, root string) *Resolver {
+	if root != "" {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+	}
+	return &Resolver{base: base, root: root, nested: true, dirs: make(map[string]*Config)}
+}
+
+// Base returns the configuration the resolver starts from
+func (r *Resolver) Base() *Config {
+	return r.base
+}
+
+// ForFile returns the configuration for the markdown file at path
+func (r *Resolver) ForFile(path string) (*Config, error) {
+	cfg := r.base
+	if r.nested {
+		dir, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path %s: %w", path, err)
+		}
+		r.mu.Lock()
+		cfg, err = r.dirConfig(dir)
+		r.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg.ForFile(path)
+}
+
+// dirConfig returns the configuration of files in dir. r.mu must be held.
+func (r *Resolver) dirConfig(dir string) (*Config, error) {
+	if cfg, ok := r.dirs[dir]; ok {
+		return cfg, nil
+	}
+
+	parent := filepath.Dir(dir)
+	if dir == r.root || parent == dir {
+		r.dirs[dir] = r.base
+		return r.base, nil
+	}
+
+	cfg, err := r.dirConfig(parent)
+	if err != nil {
+		return nil, err
+	}
+	if path, ok := configFileIn(dir); ok {
+		if cfg, err = cfg.withFile(path); err != nil {
+			return nil, err
+		}
+	}
+	r.dirs[dir] = cfg
+	return cfg, nil
+}
+
+// withFile returns c with the configuration file at path merged in,
+// beneath any command-line overrides
+func (c *Config) withFile(path string) (*Config, error) {
+	source, err := readConfigSource(path)
+	if err != nil {
+		return nil, err
+	}
+	fileLayers, err := source.layers(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := c.withLayers(fileLayers)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// withLayers returns c with extra layers merged in beneath any
+// command-line overrides
+func (c *Config) withLayers(extra []layer) (*Config, error) {
+	var layers, overrides []layer
+	for _, l := range c.layers {
+		if l.source == CommandLineSource {
+			overrides = append(overrides, l)
+		} else {
+			layers = append(layers, l)
+		}
+	}
+	return fromLayers(append(append(layers, extra...), overrides...))
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNestedResolver(t *testing.T) {
+	root := t.TempDir()
+	rootConfig := filepath.Join(root, ".mdfmt.yaml")
+	writeTestFile(t, rootConfig, "line_width: 100\nlist:\n  bullet_style: \"*\"\n")
+	writeTestFile(t, filepath.Join(root, "packages", "web", ".mdfmt.toml"), "[list]\nbullet_style = \"+\"\n")
+	writeTestFile(t, filepath.Join(root, "packages", "web", "docs", ".mdfmt.yaml"), "code:\n  fence_style: \"~~~\"\n")
+
+	base := Default()
+	if err := base.LoadFromFile(rootConfig); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if err := base.Set("heading.style", "setext"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	resolver := NewNestedResolver(base, root)
+
+	cfg, err := resolver.ForFile(filepath.Join(root, "packages", "web", "docs", "guide.md"))
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	if cfg.LineWidth != 100 {
+		t.Errorf("Expected line width from the root config, got %d", cfg.LineWidth)
+	}
+	if cfg.List.BulletStyle != "+" {
+		t.Errorf("Expected bullet style from the package config, got %s", cfg.List.BulletStyle)
+	}
+	if cfg.Code.FenceStyle != "~~~" {
+		t.Errorf("Expected fence style from the nearest config, got %s", cfg.Code.FenceStyle)
+	}
+	if cfg.Heading.Style != "setext" {
+		t.Errorf("Expected the command-line override to win, got %s", cfg.Heading.Style)
+	}
+
+	cfg, err = resolver.ForFile(filepath.Join(root, "README.md"))
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	if cfg.List.BulletStyle != "*" || cfg.Code.FenceStyle != "```" {
+		t.Errorf("Expected the root config outside nested directories, got bullet %s and fence %s",
+			cfg.List.BulletStyle, cfg.Code.FenceStyle)
+	}
+
+	again, err := resolver.ForFile(filepath.Join(root, "packages", "web", "docs", "other.md"))
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	if again != resolver.dirs[filepath.Join(root, "packages", "web", "docs")] {
+		t.Error("Expected the directory configuration to be cached")
+	}
+}
+
+func TestNestedResolverInvalidConfig(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "sub", ".mdfmt.yaml"), "line_width: -1\n")
+
+	if _, err := NewNestedResolver(Default(), root).ForFile(filepath.Join(root, "sub", "a.md")); err == nil {
+		t.Error("Expected an error for an invalid nested config")
+	}
+}
+
+func TestResolverWithoutNesting(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "sub", ".mdfmt.yaml"), "line_width: 50\n")
+
+	cfg, err := NewResolver(Default()).ForFile(filepath.Join(root, "sub", "a.md"))
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	if cfg.LineWidth != DefaultLineWidth {
+		t.Errorf("Expected nested configs to be ignored, got line width %d", cfg.LineWidth)
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SchemaDialect is the JSON Schema draft generated schemas conform to
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns a JSON Schema describing configuration files. It is
+// derived from the json tags of Config, with allowed values and bounds from
+// jsonschema tags ("enum=a|b", "minimum=n", "maximum=n") and defaults from
+// Default.
+func Schema() ([]byte, error) {
+	schema := objectSchema(reflect.TypeOf(Config{}), reflect.ValueOf(*Default()))
+	schema["$schema"] = SchemaDialect
+	schema["title"] = "mdfmt configuration"
+
+	// $schema lets JSON configs reference the schema for editor support
+	properties := schema["properties"].(map[string]interface{})
+	properties["$schema"] = map[string]interface{}{"type": "string"}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// objectSchema returns the schema of the struct type t, with defaults from
+// value unless it is the zero Value
+func objectSchema(t reflect.Type, value reflect.Value) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		var fieldValue reflect.Value
+		if value.IsValid() {
+			fieldValue = value.Field(i)
+		}
+		properties[name] = fieldSchema(field, fieldValue)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// fieldSchema returns the schema of a struct field holding value
+func fieldSchema(field reflect.StructField, value reflect.Value) map[string]interface{} {
+	if field.Type.Kind() == reflect.Struct {
+		return objectSchema(field.Type, value)
+	}
+
+	schema := typeSchema(field.Type)
+	constraints := schema
+	if field.Type.Kind() == reflect.Slice {
+		constraints = schema["items"].(map[string]interface{})
+	}
+	for _, rule := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+		key, arg, ok := strings.Cut(rule, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "enum":
+			constraints["enum"] = strings.Split(arg, "|")
+		case "minimum", "maximum":
+			if n, err := strconv.Atoi(arg); err == nil {
+				constraints[key] = n
+			}
+		}
+	}
+
+	if value.IsValid() && (field.Type.Kind() != reflect.Slice || !value.IsNil()) {
+		schema["default"] = value.Interface()
+	}
+	return schema
+}
+
+// typeSchema returns the schema of a type
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Struct:
+		// Structs in lists, such as plugins, have no defaults of their own
+		return objectSchema(t, reflect.Value{})
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// schemaLeaves returns the schemas of the non-object properties of schema,
+// keyed by dotted option path
+func schemaLeaves(schema map[string]interface{}, prefix string, leaves map[string]map[string]interface{}) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, property := range properties {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		property := property.(map[string]interface{})
+		if property["type"] == "object" {
+			schemaLeaves(property, path, leaves)
+			continue
+		}
+		leaves[path] = property
+	}
+}
+
+func TestSchemaCoversOptions(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	leaves := make(map[string]map[string]interface{})
+	schemaLeaves(schema, "", leaves)
+	for _, option := range append(Options(), "extends") {
+		if _, ok := leaves[option]; !ok {
+			t.Errorf("Expected the schema to describe %s", option)
+		}
+	}
+	if leaves["line_width"]["default"] != float64(DefaultLineWidth) {
+		t.Errorf("Expected line_width default %d, got %v", DefaultLineWidth, leaves["line_width"]["default"])
+	}
+}
+
+func TestSchemaEnumsMatchValidate(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	leaves := make(map[string]map[string]interface{})
+	schemaLeaves(schema, "", leaves)
+	for path, property := range leaves {
+		enum, ok := property["enum"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, value := range enum {
+			cfg := Default()
+			if err := cfg.Set(path, value.(string)); err != nil {
+				t.Fatalf("Set(%s, %v) failed: %v", path, value, err)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("Schema allows %s = %q but Validate rejects it: %v", path, value, err)
+			}
+		}
+
+		cfg := Default()
+		if err := cfg.Set(path, "bogus"); err != nil {
+			t.Fatalf("Set(%s, bogus) failed: %v", path, err)
+		}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected Validate to reject %s = bogus, as the schema does", path)
+		}
+	}
+}
+
+func TestSchemaListOfObjects(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+	var schema struct {
+		Properties struct {
+			Plugins struct {
+				Items struct {
+					Properties map[string]map[string]interface{} `json:"properties"`
+				} `json:"items"`
+			} `json:"plugins"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	plugin := schema.Properties.Plugins.Items.Properties
+	if plugin["command"]["type"] != "array" || plugin["input"]["enum"] == nil {
+		t.Errorf("Expected plugins items to describe PluginConfig, got %v", plugin)
+	}
+	if _, ok := plugin["name"]["default"]; ok {
+		t.Error("Expected no defaults for plugins items")
+	}
+}
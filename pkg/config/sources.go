@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSource is the source reported for options no layer sets
+const DefaultSource = "default"
+
+// Sources returns the source of each option set by the configuration
+// layers of c, keyed by dotted option path such as "list.bullet_style".
+// Sources are configuration file paths, "preset <name>" or .editorconfig;
+// options missing from the map have their default value.
+func (c *Config) Sources() (map[string]string, error) {
+	sources := make(map[string]string)
+	for _, l := range c.layers {
+		var root yaml.Node
+		if err := yaml.Unmarshal(l.data, &root); err != nil {
+			return nil, fmt.Errorf("failed to parse config from %s: %w", l.source, err)
+		}
+		walkLeaves(&root, "", func(path string, _, _ *yaml.Node) {
+			if path != "extends" {
+				sources[path] = l.source
+			}
+		})
+	}
+	return sources, nil
+}
+
+// AnnotatedYAML returns c as YAML with a comment naming the source of each
+// option
+func (c *Config) AnnotatedYAML() ([]byte, error) {
+	sources, err := c.Sources()
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := root.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	walkLeaves(&root, "", func(path string, key, value *yaml.Node) {
+		source, ok := sources[path]
+		if !ok {
+			source = DefaultSource
+		}
+		// the encoder misplaces line comments of block sequences, so
+		// those are attached to the key instead
+		if value.Kind == yaml.ScalarNode || len(value.Content) == 0 {
+			value.LineComment = "from " + source
+		} else {
+			key.LineComment = "from " + source
+		}
+	})
+
+	data, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return data, nil
+}
+
+// walkLeaves calls visit for each mapping value in node that is not itself
+// a mapping, with its dotted key path and key node
+func walkLeaves(node *yaml.Node, prefix string, visit func(path string, key, value *yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, content := range node.Content {
+			walkLeaves(content, prefix, visit)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			path := key.Value
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+			if value.Kind == yaml.MappingNode {
+				walkLeaves(value, path, visit)
+			} else {
+				visit(path, key, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSources(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ".editorconfig"), "root = true\n\n[*.md]\nindent_size = 4\n")
+	configPath := filepath.Join(root, ".mdfmt.yaml")
+	writeTestFile(t, configPath, "extends: [github]\nline_width: 100\nlist:\n  bullet_style: \"*\"\n")
+
+	cfg := Default()
+	if err := cfg.LoadFromFile(configPath); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	fileCfg, err := cfg.ForFile(filepath.Join(root, "README.md"))
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+
+	sources, err := fileCfg.Sources()
+	if err != nil {
+		t.Fatalf("Sources failed: %v", err)
+	}
+	expected := map[string]string{
+		"line_width":        configPath,
+		"list.bullet_style": configPath,
+		"list.number_style": "preset github",
+		"list.indent":       EditorConfigFileName,
+	}
+	for path, source := range expected {
+		if sources[path] != source {
+			t.Errorf("Expected %s from %q, got %q", path, source, sources[path])
+		}
+	}
+	for _, path := range []string{"extends", "heading.normalize_levels"} {
+		if source, ok := sources[path]; ok {
+			t.Errorf("Expected no source for %s, got %q", path, source)
+		}
+	}
+}
+
+func TestAnnotatedYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".mdfmt.yaml")
+	writeTestFile(t, path, "line_width: 100\n")
+
+	cfg := Default()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	data, err := cfg.AnnotatedYAML()
+	if err != nil {
+		t.Fatalf("AnnotatedYAML failed: %v", err)
+	}
+
+	output := string(data)
+	if !strings.Contains(output, "line_width: 100 # from "+path) {
+		t.Errorf("Expected line_width to name its file, got:\n%s", output)
+	}
+	if !strings.Contains(output, "dialect: gfm # from default") {
+		t.Errorf("Expected dialect to be annotated as default, got:\n%s", output)
+	}
+}
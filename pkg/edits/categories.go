@@ -0,0 +1,136 @@
+package edits
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Change categories group edits by the kind of rule behind them
+const (
+	// CategoryReflow is prose rewrapped to other line breaks
+	CategoryReflow = "reflow"
+	// CategoryBulletStyle is list markers changed, such as * to - or 1) to 1.
+	CategoryBulletStyle = "bullet-style"
+	// CategoryWhitespace is indentation, spacing, trailing spaces and blank
+	// lines changed
+	CategoryWhitespace = "whitespace"
+	// CategoryHeading is heading lines changed, such as setext to ATX
+	CategoryHeading = "heading"
+	// CategoryOther is any other change to the text, such as escapes,
+	// fences or link styles
+	CategoryOther = "other"
+)
+
+// Categories lists the change categories in the order they are reported
+var Categories = []string{CategoryReflow, CategoryBulletStyle, CategoryWhitespace, CategoryHeading, CategoryOther}
+
+var (
+	// atxHeadingPattern matches an ATX heading line
+	atxHeadingPattern = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]|$)`)
+	// setextUnderlinePattern matches the underline of a setext heading
+	setextUnderlinePattern = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	// quotePrefixPattern matches the blockquote markers starting a line
+	quotePrefixPattern = regexp.MustCompile(`^(?:[ \t]*>[ \t]?)+`)
+	// listMarkerPattern matches the list marker starting a line
+	listMarkerPattern = regexp.MustCompile(`^[ \t]*([-*+]|[0-9]{1,9}[.)])(?:[ \t]+|$)`)
+)
+
+// lineShape is the structure of the lines of an edit, split into what each
+// category of rule changes
+type lineShape struct {
+	headings []string
+	markers  []string
+	// body holds the trimmed non-blank lines of prose, without markers
+	body []string
+	// raw holds every line with its list markers replaced, so only
+	// whitespace tells them apart once the rest matches
+	raw []string
+}
+
+// Categorize returns the categories of the changes e makes, in the order
+// of Categories
+func Categorize(e Edit) []string {
+	a, b := shapeOf(e.Original), shapeOf(e.Replacement)
+
+	found := make(map[string]bool)
+	if !slices.Equal(a.headings, b.headings) {
+		found[CategoryHeading] = true
+	}
+	if !slices.Equal(a.markers, b.markers) {
+		found[CategoryBulletStyle] = true
+	}
+	switch {
+	case strings.Join(strings.Fields(strings.Join(a.body, " ")), " ") !=
+		strings.Join(strings.Fields(strings.Join(b.body, " ")), " "):
+		found[CategoryOther] = true
+	case !slices.Equal(a.body, b.body):
+		found[CategoryReflow] = true
+	case !slices.Equal(a.raw, b.raw):
+		found[CategoryWhitespace] = true
+	}
+	if len(found) == 0 && e.Original != e.Replacement {
+		// Only line endings or the spacing of headings differ
+		found[CategoryWhitespace] = true
+	}
+
+	var categories []string
+	for _, category := range Categories {
+		if found[category] {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// CountCategories returns the number of edits that turn original into
+// formatted in each category. An edit is a run of changed lines, counted
+// once in each of its categories.
+func CountCategories(original, formatted string) map[string]int {
+	counts := make(map[string]int)
+	for _, e := range Diff(original, formatted) {
+		for _, category := range Categorize(e) {
+			counts[category]++
+		}
+	}
+	return counts
+}
+
+// shapeOf splits the lines of text into headings, list markers and prose
+func shapeOf(text string) lineShape {
+	var shape lineShape
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSuffix(lines[i], "\r")
+		if strings.TrimSpace(line) == "" {
+			shape.raw = append(shape.raw, line)
+			continue
+		}
+		quote := quotePrefixPattern.FindString(line)
+		content := line[len(quote):]
+
+		if atxHeadingPattern.MatchString(content) {
+			shape.headings = append(shape.headings, strings.TrimRight(content, " \t"))
+			continue
+		}
+		if i+1 < len(lines) && !listMarkerPattern.MatchString(content) &&
+			setextUnderlinePattern.MatchString(strings.TrimPrefix(lines[i+1], quote)) {
+			// A setext heading and its underline are one heading line
+			i++
+			shape.headings = append(shape.headings, strings.TrimSpace(content)+"\n"+strings.TrimSpace(lines[i]))
+			continue
+		}
+
+		if m := listMarkerPattern.FindStringSubmatchIndex(content); m != nil {
+			shape.markers = append(shape.markers, content[m[2]:m[3]])
+			content = content[:m[2]] + "-" + content[m[3]:]
+			if rest := strings.TrimSpace(content[m[2]+1:]); rest != "" {
+				shape.body = append(shape.body, rest)
+			}
+		} else {
+			shape.body = append(shape.body, strings.TrimSpace(content))
+		}
+		shape.raw = append(shape.raw, quote+content)
+	}
+	return shape
+}
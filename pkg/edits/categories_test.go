@@ -0,0 +1,55 @@
+package edits
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCategorize(t *testing.T) {
+	tests := []struct {
+		name        string
+		original    string
+		replacement string
+		expected    []string
+	}{
+		{"reflow", "one two\nthree\n", "one two three\n", []string{CategoryReflow}},
+		{"quoted reflow", "> one\n> two\n", "> one two\n", []string{CategoryReflow}},
+		{"bullet", "* one\n* two\n", "- one\n- two\n", []string{CategoryBulletStyle}},
+		{"number style", "1) one\n", "1. one\n", []string{CategoryBulletStyle}},
+		{"trailing spaces", "text  \n", "text\n", []string{CategoryWhitespace}},
+		{"blank lines", "a\n\n\n", "a\n\n", []string{CategoryWhitespace}},
+		{"list indent", "- a\n    - b\n", "- a\n  - b\n", []string{CategoryWhitespace}},
+		{"quote spacing", ">text\n", "> text\n", []string{CategoryWhitespace}},
+		{"atx heading", "#  Title\n", "# Title\n", []string{CategoryHeading}},
+		{"setext heading", "Title\n=====\n", "# Title\n", []string{CategoryHeading}},
+		{"heading trailing spaces", "# Title  \n", "# Title\n", []string{CategoryWhitespace}},
+		{"escape", "a # b\n", "a \\# b\n", []string{CategoryOther}},
+		{"fence", "~~~\ncode\n~~~\n", "```\ncode\n```\n", []string{CategoryOther}},
+		{"bullet and reflow", "* one\n  two\n", "- one two\n", []string{CategoryReflow, CategoryBulletStyle}},
+		{"unchanged", "text\n", "text\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Categorize(Edit{Original: tt.original, Replacement: tt.replacement})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCountCategories(t *testing.T) {
+	original := "Title\n=====\n\nSome text  \nthat wraps.\n\n* one\n* two\n\nEnd  \n"
+	formatted := "# Title\n\nSome text that wraps.\n\n- one\n- two\n\nEnd\n"
+
+	expected := map[string]int{
+		CategoryHeading:     1,
+		CategoryReflow:      1,
+		CategoryBulletStyle: 1,
+		CategoryWhitespace:  1,
+	}
+	if got := CountCategories(original, formatted); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
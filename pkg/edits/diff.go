@@ -0,0 +1,110 @@
+package edits
+
+import "strings"
+
+// maxDiffCells bounds the size of the table used to match lines. Larger
+// changed regions are reported as a single replacement.
+const maxDiffCells = 4_000_000
+
+// hunk is a run of lines a[aStart:aEnd] replaced by b[bStart:bEnd]
+type hunk struct {
+	aStart, aEnd int
+	bStart, bEnd int
+}
+
+// splitLines splits text into lines that keep their "\n" terminators, so
+// joining them reproduces text exactly
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines matches the lines of a and b along a longest common
+// subsequence. It returns, for each line of b, the index of its matching
+// line in a or -1, and the hunks between matched lines.
+func diffLines(a, b []string) ([]int, []hunk) {
+	matches := make([]int, len(b))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// Common prefix and suffix are matched without the table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		matches[len(b)-1-suffix] = len(a) - 1 - suffix
+		suffix++
+	}
+
+	matchMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], matches[prefix:len(b)-suffix], prefix)
+	return matches, hunksBetween(matches, len(a))
+}
+
+// matchMiddle fills matches for the lines of b with the LCS of a and b,
+// adding offset to the indexes of a
+func matchMiddle(a, b []string, matches []int, offset int) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 || n*m > maxDiffCells {
+		return
+	}
+
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			matches[j] = offset + i
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+}
+
+// hunksBetween returns the unmatched regions between the matched lines of
+// a (of length n) and b
+func hunksBetween(matches []int, n int) []hunk {
+	var hunks []hunk
+	nextA, nextB := 0, 0
+	flush := func(aEnd, bEnd int) {
+		if nextA < aEnd || nextB < bEnd {
+			hunks = append(hunks, hunk{aStart: nextA, aEnd: aEnd, bStart: nextB, bEnd: bEnd})
+		}
+	}
+	for j, i := range matches {
+		if i < 0 {
+			continue
+		}
+		flush(i, j)
+		nextA, nextB = i+1, j+1
+	}
+	flush(n, len(matches))
+	return hunks
+}
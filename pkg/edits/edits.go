@@ -0,0 +1,244 @@
+// Package edits computes the changes formatting would make to a document as
+// line edits attributed to the rules that cause them, without producing the
+// formatted document. It powers editor code actions such as "fix only this
+// rule here" and review bot suggestions.
+package edits
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+// RenderRule attributes changes made by rendering the document without any
+// formatter, such as blank line normalization
+const RenderRule = "render"
+
+// Edit is a proposed replacement of a range of source lines
+type Edit struct {
+	// StartLine is the 1-based first source line replaced by the edit
+	StartLine int `json:"start_line"`
+	// EndLine is the 1-based last source line replaced by the edit. It is
+	// StartLine-1 when the edit inserts lines before StartLine.
+	EndLine int `json:"end_line"`
+	// Original is the replaced source text, including line endings
+	Original string `json:"original"`
+	// Replacement is the proposed text, including line endings
+	Replacement string `json:"replacement"`
+	// Rules names the formatters whose changes make up the edit, in the
+	// order they run
+	Rules []string `json:"rules"`
+}
+
+// HasRule reports whether rule contributes to the edit
+func (e Edit) HasRule(rule string) bool {
+	for _, r := range e.Rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Pipeline is the parser, formatting engine and renderer used to format documents
+type Pipeline struct {
+	Parser   parser.Parser
+	Engine   *formatter.Engine
+	Renderer renderer.Renderer
+}
+
+// ruleSpan is the source line range [start, end) changed by a rule
+type ruleSpan struct {
+	rule       string
+	start, end int
+}
+
+// Propose returns the edits that formatting source with pipeline would make,
+// in source order. Applying all of them yields the formatted document.
+//
+// Rules are attributed by formatting the document once per formatter, each
+// time with one more formatter of the engine enabled, and tracing the lines
+// each step changes back to the source.
+func Propose(source []byte, pipeline Pipeline, cfg *config.Config) ([]Edit, error) {
+	sourceLines := splitLines(string(source))
+
+	origins := make([]int, len(sourceLines))
+	for i := range origins {
+		origins[i] = i
+	}
+	previous := sourceLines
+
+	var spans []ruleSpan
+	formatters := pipeline.Engine.Formatters()
+	for step := 0; step <= len(formatters); step++ {
+		rule := RenderRule
+		if step > 0 {
+			rule = formatters[step-1].Name()
+		}
+
+		engine := &formatter.Engine{}
+		for _, f := range formatters[:step] {
+			engine.Register(f)
+		}
+		output, err := format(source, pipeline, engine, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format with %s: %w", rule, err)
+		}
+
+		current := splitLines(output)
+		matches, hunks := diffLines(previous, current)
+		for _, h := range hunks {
+			start, end := sourceSpan(origins, h, len(sourceLines))
+			spans = append(spans, ruleSpan{rule: rule, start: start, end: end})
+		}
+
+		next := make([]int, len(current))
+		for j, i := range matches {
+			next[j] = -1
+			if i >= 0 {
+				next[j] = origins[i]
+			}
+		}
+		origins, previous = next, current
+	}
+
+	_, hunks := diffLines(sourceLines, previous)
+	edits := make([]Edit, 0, len(hunks))
+	for _, h := range hunks {
+		edit := newEdit(sourceLines, previous, h)
+		edit.Rules = rulesFor(spans, h.aStart, h.aEnd)
+		edits = append(edits, edit)
+	}
+	return edits, nil
+}
+
+// Diff returns the edits that turn original into formatted, in source
+// order. Unlike Propose, it does not attribute the edits to rules.
+func Diff(original, formatted string) []Edit {
+	a, b := splitLines(original), splitLines(formatted)
+	_, hunks := diffLines(a, b)
+	edits := make([]Edit, 0, len(hunks))
+	for _, h := range hunks {
+		edits = append(edits, newEdit(a, b, h))
+	}
+	return edits
+}
+
+// newEdit returns the edit replacing the lines of a by those of b in h
+func newEdit(a, b []string, h hunk) Edit {
+	return Edit{
+		StartLine:   h.aStart + 1,
+		EndLine:     h.aEnd,
+		Original:    strings.Join(a[h.aStart:h.aEnd], ""),
+		Replacement: strings.Join(b[h.bStart:h.bEnd], ""),
+	}
+}
+
+// format parses, formats and renders source with engine
+func format(source []byte, pipeline Pipeline, engine *formatter.Engine, cfg *config.Config) (string, error) {
+	doc, err := pipeline.Parser.Parse(source)
+	if err != nil {
+		return "", &parser.ParseError{Err: err}
+	}
+	if err := engine.Format(doc, cfg); err != nil {
+		return "", fmt.Errorf("failed to format document: %w", err)
+	}
+	return pipeline.Renderer.Render(doc, cfg)
+}
+
+// sourceSpan returns the source lines covered by a hunk of a formatting
+// step. origins maps the lines before the step to source lines (-1 for lines
+// added by earlier steps); lines without an origin fall back to the gap
+// between their nearest source neighbors.
+func sourceSpan(origins []int, h hunk, sourceLen int) (int, int) {
+	start, end := -1, -1
+	for _, origin := range origins[h.aStart:h.aEnd] {
+		if origin < 0 {
+			continue
+		}
+		if start < 0 || origin < start {
+			start = origin
+		}
+		if origin+1 > end {
+			end = origin + 1
+		}
+	}
+	if start >= 0 {
+		return start, end
+	}
+
+	start, end = 0, sourceLen
+	for i := h.aStart - 1; i >= 0; i-- {
+		if origins[i] >= 0 {
+			start = origins[i] + 1
+			break
+		}
+	}
+	for i := h.aEnd; i < len(origins); i++ {
+		if origins[i] >= 0 {
+			end = origins[i]
+			break
+		}
+	}
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// rulesFor returns the rules whose spans overlap the source lines
+// [start, end), in the order the rules ran. Empty ranges overlap the
+// ranges they touch.
+func rulesFor(spans []ruleSpan, start, end int) []string {
+	var rules []string
+	seen := make(map[string]bool)
+	for _, span := range spans {
+		overlaps := span.start < end && start < span.end
+		if span.start == span.end || start == end {
+			overlaps = span.start <= end && start <= span.end
+		}
+		if overlaps && !seen[span.rule] {
+			seen[span.rule] = true
+			rules = append(rules, span.rule)
+		}
+	}
+	if len(rules) == 0 {
+		rules = append(rules, RenderRule)
+	}
+	return rules
+}
+
+// Filter returns the edits rule contributes to
+func Filter(edits []Edit, rule string) []Edit {
+	var result []Edit
+	for _, edit := range edits {
+		if edit.HasRule(rule) {
+			result = append(result, edit)
+		}
+	}
+	return result
+}
+
+// Apply returns source with edits applied. Edits must not overlap.
+func Apply(source []byte, edits []Edit) []byte {
+	lines := splitLines(string(source))
+	sorted := append([]Edit(nil), edits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartLine < sorted[j].StartLine
+	})
+
+	var b strings.Builder
+	next := 0
+	for _, edit := range sorted {
+		b.WriteString(strings.Join(lines[next:edit.StartLine-1], ""))
+		b.WriteString(edit.Replacement)
+		next = edit.EndLine
+	}
+	b.WriteString(strings.Join(lines[next:], ""))
+	return []byte(b.String())
+}
@@ -0,0 +1,98 @@
+package edits
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+func newPipeline() Pipeline {
+	return Pipeline{
+		Parser:   parser.New(),
+		Engine:   formatter.New(),
+		Renderer: renderer.New(),
+	}
+}
+
+func TestProposeAttributesRules(t *testing.T) {
+	source := "# Title\n\n\n\n\nSome text.\n\n- one\n- two\n\n```\ncode\n```\n\n1) one\n2) two\n"
+	cfg := config.Default()
+	cfg.Code.FenceStyle = "~~~"
+
+	pipeline := newPipeline()
+	edits, err := Propose([]byte(source), pipeline, cfg)
+	if err != nil {
+		t.Fatalf("Propose failed: %v", err)
+	}
+
+	expected := []Edit{
+		{StartLine: 3, EndLine: 5, Original: "\n\n\n", Replacement: "", Rules: []string{RenderRule}},
+		{StartLine: 11, EndLine: 11, Original: "```\n", Replacement: "~~~\n", Rules: []string{"code-block"}},
+		{StartLine: 13, EndLine: 13, Original: "```\n", Replacement: "~~~\n", Rules: []string{"code-block"}},
+		{StartLine: 15, EndLine: 16, Original: "1) one\n2) two\n", Replacement: "1. one\n2. two\n", Rules: []string{"list"}},
+	}
+	if !reflect.DeepEqual(edits, expected) {
+		t.Errorf("Expected edits:\n%+v\ngot:\n%+v", expected, edits)
+	}
+
+	doc, err := pipeline.Parser.Parse([]byte(source))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if err := pipeline.Engine.Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	formatted, err := pipeline.Renderer.Render(doc, cfg)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got := string(Apply([]byte(source), edits)); got != formatted {
+		t.Errorf("Applying all edits should give the formatted document:\n%q\ngot:\n%q", formatted, got)
+	}
+}
+
+func TestFilterAndApplyOneRule(t *testing.T) {
+	source := "```\ncode\n```\n\n1) one\n2) two\n"
+	cfg := config.Default()
+	cfg.Code.FenceStyle = "~~~"
+
+	edits, err := Propose([]byte(source), newPipeline(), cfg)
+	if err != nil {
+		t.Fatalf("Propose failed: %v", err)
+	}
+
+	got := string(Apply([]byte(source), Filter(edits, "code-block")))
+	expected := "~~~\ncode\n~~~\n\n1) one\n2) two\n"
+	if got != expected {
+		t.Errorf("Expected only code block edits applied:\n%q\ngot:\n%q", expected, got)
+	}
+}
+
+func TestApplyInsertion(t *testing.T) {
+	source := []byte("a\nc\n")
+	edits := []Edit{{StartLine: 2, EndLine: 1, Replacement: "b\n"}}
+	if got := string(Apply(source, edits)); got != "a\nb\nc\n" {
+		t.Errorf("Expected inserted line, got %q", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	original := "#  Title\n\nkeep\n* one\n"
+	formatted := "# Title\n\nkeep\n\n- one\n"
+
+	edits := Diff(original, formatted)
+	expected := []Edit{
+		{StartLine: 1, EndLine: 1, Original: "#  Title\n", Replacement: "# Title\n"},
+		{StartLine: 4, EndLine: 4, Original: "* one\n", Replacement: "\n- one\n"},
+	}
+	if !reflect.DeepEqual(edits, expected) {
+		t.Errorf("Expected edits %+v, got %+v", expected, edits)
+	}
+	if got := string(Apply([]byte(original), edits[1:])); got != "#  Title\n\nkeep\n\n- one\n" {
+		t.Errorf("Expected only the second edit applied, got %q", got)
+	}
+}
@@ -0,0 +1,335 @@
+// Package embedded finds markdown embedded in other files, such as Go doc
+// comments, YAML block scalars and markdown code fences, and splices
+// formatted markdown back in its place.
+package embedded
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Constants
+const (
+	// goCommentMarker starts a Go line comment
+	goCommentMarker = "//"
+	// maxFenceIndent is the deepest indentation of a fence
+	maxFenceIndent = 3
+)
+
+// Extensions lists the extensions of the files, other than markdown, that
+// embed markdown
+var Extensions = []string{".go", ".yaml", ".yml"}
+
+var (
+	// goDirectivePattern matches comment lines that are directives rather
+	// than documentation, such as //go:generate or //line
+	goDirectivePattern = regexp.MustCompile(`^//(?:line |extern |export |[a-z0-9]+:[a-z0-9])`)
+	// yamlBlockPattern matches a key introducing a literal block scalar
+	yamlBlockPattern = regexp.MustCompile(`^(\s*(?:-\s+)?)("[^"]*"|'[^']*'|[^\s:#][^:#]*?):\s*\|[1-9+-]*\s*(?:#.*)?$`)
+	// fenceOpenPattern matches the opening line of a code fence
+	fenceOpenPattern = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`\\s]*)[^`]*$")
+	// goListPattern matches a line starting with a list marker, which
+	// markdown would turn into a list indenting its continuation lines
+	goListPattern = regexp.MustCompile(`^(?:[-*+•]|\d+[.)])(?:\s|$)`)
+)
+
+// markdownLanguages are the info strings of fences holding markdown
+var markdownLanguages = map[string]bool{"markdown": true, "md": true}
+
+// Options configures where embedded markdown is looked for
+type Options struct {
+	// YAMLKeys are the keys whose literal block scalars hold markdown
+	YAMLKeys []string
+}
+
+// Region is a piece of markdown embedded in a file
+type Region struct {
+	// Start and End are the byte offsets of the lines of the region; End
+	// is at the end of the last line, before its newline
+	Start, End int
+	// Line is the line number of the first line of the region
+	Line int
+	// Prefix starts each line of the region, such as "// " in Go comments
+	Prefix string
+	// Markdown is the embedded markdown, without the line prefixes
+	Markdown string
+}
+
+// Supported reports whether markdown is looked for in the file at path
+func Supported(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, supported := range Extensions {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the markdown regions of content, in order. The kind of file
+// is taken from the extension of path; files that are neither Go nor YAML
+// are read as markdown, whose markdown fences are returned.
+func Find(path string, content []byte, opts Options) ([]Region, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		return goDocComments(path, content)
+	case ".yaml", ".yml":
+		return yamlBlocks(string(content), opts.YAMLKeys), nil
+	default:
+		return markdownFences(string(content)), nil
+	}
+}
+
+// Replace returns content with the markdown of each region replaced by the
+// corresponding entry of markdown, prefixed like the original lines.
+// Blank lines get the prefix without its trailing spaces.
+func Replace(content []byte, regions []Region, markdown []string) []byte {
+	var sb strings.Builder
+	last := 0
+	for i, region := range regions {
+		sb.Write(content[last:region.Start])
+		lines := strings.Split(strings.TrimRight(markdown[i], "\r\n"), "\n")
+		for j, line := range lines {
+			if j > 0 {
+				sb.WriteString("\n")
+			}
+			if line == "" {
+				sb.WriteString(strings.TrimRight(region.Prefix, " \t"))
+				continue
+			}
+			sb.WriteString(region.Prefix + line)
+		}
+		last = region.End
+	}
+	sb.Write(content[last:])
+	return []byte(sb.String())
+}
+
+// sourceLine is a line of a file with its byte offset
+type sourceLine struct {
+	text  string
+	start int
+}
+
+// splitLines returns the lines of content without their line endings
+func splitLines(content string) []sourceLine {
+	var lines []sourceLine
+	start := 0
+	for start <= len(content) {
+		end := strings.IndexByte(content[start:], '\n')
+		if end < 0 {
+			if start < len(content) {
+				lines = append(lines, sourceLine{text: strings.TrimSuffix(content[start:], "\r"), start: start})
+			}
+			break
+		}
+		lines = append(lines, sourceLine{text: strings.TrimSuffix(content[start:start+end], "\r"), start: start})
+		start += end + 1
+	}
+	return lines
+}
+
+// region returns the region of lines[first:last+1], each with prefix removed
+func region(lines []sourceLine, first, last int, prefix string, strip func(string) string) Region {
+	texts := make([]string, 0, last-first+1)
+	for _, line := range lines[first : last+1] {
+		texts = append(texts, strip(line.text))
+	}
+	return Region{
+		Start:    lines[first].start,
+		End:      lines[last].start + len(lines[last].text),
+		Line:     first + 1,
+		Prefix:   prefix,
+		Markdown: strings.Join(texts, "\n") + "\n",
+	}
+}
+
+// goDocComments returns the paragraphs of the doc comments of the package
+// clause and the top-level declarations of a Go file. Comments with
+// directives or written as /* */ blocks are left alone.
+func goDocComments(path string, content []byte) ([]Region, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Go file: %w", err)
+	}
+
+	groups := []*ast.CommentGroup{file.Doc}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			groups = append(groups, d.Doc)
+		case *ast.GenDecl:
+			groups = append(groups, d.Doc)
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					groups = append(groups, s.Doc)
+				case *ast.ValueSpec:
+					groups = append(groups, s.Doc)
+				}
+			}
+		}
+	}
+
+	lines := splitLines(string(content))
+	var regions []Region
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		first := fset.Position(group.Pos()).Line - 1
+		last := fset.Position(group.End()).Line - 1
+		regions = append(regions, goCommentRegions(lines, first, last)...)
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Start < regions[j].Start })
+	return regions, nil
+}
+
+// goCommentRegions returns a region per paragraph of the line comments on
+// lines first to last, if they hold documentation only. Paragraphs with
+// indented lines or list items are left alone: gofmt formats those as the
+// code blocks and lists of Go doc comments, which markdown has other rules
+// for.
+func goCommentRegions(lines []sourceLine, first, last int) []Region {
+	indent := ""
+	for i := first; i <= last; i++ {
+		text := strings.TrimLeft(lines[i].text, " \t")
+		if !strings.HasPrefix(text, goCommentMarker) || goDirectivePattern.MatchString(text) {
+			return nil
+		}
+		if i == first {
+			indent = lines[i].text[:len(lines[i].text)-len(text)]
+		}
+	}
+	strip := func(line string) string {
+		text := strings.TrimPrefix(strings.TrimLeft(line, " \t"), goCommentMarker)
+		return strings.TrimPrefix(text, " ")
+	}
+
+	var regions []Region
+	for i := first; i <= last; i++ {
+		if strings.TrimSpace(strip(lines[i].text)) == "" {
+			continue
+		}
+		end, prose := i, true
+		for ; end <= last; end++ {
+			text := strip(lines[end].text)
+			if strings.TrimSpace(text) == "" {
+				break
+			}
+			if text[0] == ' ' || text[0] == '\t' || goListPattern.MatchString(text) {
+				prose = false
+			}
+		}
+		if prose {
+			regions = append(regions, region(lines, i, end-1, indent+goCommentMarker+" ", strip))
+		}
+		i = end
+	}
+	return regions
+}
+
+// yamlBlocks returns the literal block scalars of the given keys
+func yamlBlocks(content string, keys []string) []Region {
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	lines := splitLines(content)
+	var regions []Region
+	for i := 0; i < len(lines); i++ {
+		m := yamlBlockPattern.FindStringSubmatch(lines[i].text)
+		if m == nil || !wanted[strings.Trim(strings.TrimSpace(m[2]), `"'`)] {
+			continue
+		}
+		keyIndent := len(m[1])
+
+		// The block is indented by its first line; it ends at the first
+		// line indented less, ignoring trailing blank lines
+		blockIndent, first, last := 0, -1, -1
+		for j := i + 1; j < len(lines); j++ {
+			text := lines[j].text
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			indent := len(text) - len(strings.TrimLeft(text, " "))
+			if first < 0 {
+				if indent <= keyIndent {
+					break
+				}
+				blockIndent, first = indent, j
+			} else if indent < blockIndent {
+				break
+			}
+			last = j
+		}
+		if first < 0 {
+			continue
+		}
+		prefix := strings.Repeat(" ", blockIndent)
+		strip := func(line string) string {
+			if len(line) < blockIndent {
+				return ""
+			}
+			return line[blockIndent:]
+		}
+		regions = append(regions, region(lines, first, last, prefix, strip))
+		i = last
+	}
+	return regions
+}
+
+// markdownFences returns the content of the code fences of a markdown
+// document whose info string is markdown or md
+func markdownFences(content string) []Region {
+	lines := splitLines(content)
+	var regions []Region
+	for i := 0; i < len(lines); i++ {
+		m := fenceOpenPattern.FindStringSubmatch(lines[i].text)
+		if m == nil {
+			continue
+		}
+		indent, fence := len(m[1]), m[2]
+		closing := -1
+		for j := i + 1; j < len(lines); j++ {
+			if isFenceClose(lines[j].text, fence) {
+				closing = j
+				break
+			}
+		}
+		if closing < 0 {
+			// An unclosed fence runs to the end of the document
+			break
+		}
+		if markdownLanguages[strings.ToLower(m[3])] && closing > i+1 {
+			strip := func(line string) string {
+				for k := 0; k < indent && strings.HasPrefix(line, " "); k++ {
+					line = line[1:]
+				}
+				return line
+			}
+			regions = append(regions, region(lines, i+1, closing-1, strings.Repeat(" ", indent), strip))
+		}
+		i = closing
+	}
+	return regions
+}
+
+// isFenceClose reports whether line closes a fence opened with fence
+func isFenceClose(line, fence string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > maxFenceIndent {
+		return false
+	}
+	trimmed = strings.TrimRight(trimmed, " \t")
+	run := strings.TrimLeft(trimmed, fence[:1])
+	return run == "" && len(trimmed) >= len(fence)
+}
@@ -0,0 +1,104 @@
+package embedded
+
+import (
+	"strings"
+	"testing"
+)
+
+// upper stands in for formatting, so replaced regions are easy to spot
+func upper(regions []Region) []string {
+	markdown := make([]string, len(regions))
+	for i, r := range regions {
+		markdown[i] = strings.ToUpper(r.Markdown)
+	}
+	return markdown
+}
+
+func TestFind_Go(t *testing.T) {
+	content := "// Package demo does\n// things.\npackage demo\n\n//go:generate stringer\n// Kind is a kind\ntype Kind int\n\n" +
+		"const (\n\t// A is\n\t//\n\t// first\n\tA Kind = iota\n)\n\n/* F is old style */\nfunc F() {}\n"
+	regions, err := Find("demo.go", []byte(content), Options{})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(regions) != 3 {
+		t.Fatalf("Expected the package comment and the paragraphs of the A comment, got %+v", regions)
+	}
+	if regions[0].Markdown != "Package demo does\nthings.\n" || regions[0].Prefix != "// " || regions[0].Line != 1 {
+		t.Errorf("Unexpected package comment %+v", regions[0])
+	}
+	if regions[1].Markdown != "A is\n" || regions[1].Prefix != "\t// " || regions[1].Line != 10 {
+		t.Errorf("Unexpected A comment %+v", regions[1])
+	}
+	if regions[2].Markdown != "first\n" || regions[2].Line != 12 {
+		t.Errorf("Unexpected A comment %+v", regions[2])
+	}
+
+	expected := strings.Replace(content, "// Package demo does\n// things.", "// PACKAGE DEMO DOES\n// THINGS.", 1)
+	expected = strings.Replace(expected, "\t// A is\n\t//\n\t// first", "\t// A IS\n\t//\n\t// FIRST", 1)
+	if got := string(Replace([]byte(content), regions, upper(regions))); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	if _, err := Find("broken.go", []byte("package"), Options{}); err == nil {
+		t.Error("Expected an error for invalid Go")
+	}
+}
+
+func TestFind_GoDocSyntax(t *testing.T) {
+	content := "// Package demo computes.\n//\n//\tx := compute()\n//\n// Options:\n//   - fast\n//   - slow\n//\n" +
+		"// * a\n//\texample()\n//\n// # Usage\n//\n// Call   it.\npackage demo\n"
+	regions, err := Find("demo.go", []byte(content), Options{})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	var markdown []string
+	for _, r := range regions {
+		markdown = append(markdown, r.Markdown)
+	}
+	expected := []string{"Package demo computes.\n", "# Usage\n", "Call   it.\n"}
+	if strings.Join(markdown, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected only the paragraphs %q, got %q", expected, markdown)
+	}
+}
+
+func TestFind_YAML(t *testing.T) {
+	content := "info:\n  description: |\n    # API\n\n    Text\n\n  title: |\n    not markdown\n" +
+		"paths:\n  - \"description\": |-\n      item\n"
+	regions, err := Find("openapi.yaml", []byte(content), Options{YAMLKeys: []string{"description"}})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(regions) != 2 {
+		t.Fatalf("Expected 2 description blocks, got %+v", regions)
+	}
+	if regions[0].Markdown != "# API\n\nText\n" || regions[0].Prefix != "    " || regions[0].Line != 3 {
+		t.Errorf("Unexpected first block %+v", regions[0])
+	}
+	if regions[1].Markdown != "item\n" || regions[1].Prefix != "      " {
+		t.Errorf("Unexpected second block %+v", regions[1])
+	}
+
+	expected := strings.NewReplacer("# API", "# API", "    Text", "    TEXT", "      item", "      ITEM").Replace(content)
+	if got := string(Replace([]byte(content), regions, upper(regions))); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestFind_MarkdownFences(t *testing.T) {
+	content := "# Guide\n\n````markdown\n```go\nx\n```\n*a*\n````\n\n```go\n// ```md\n```\n\n  ~~~ md\n  text\n  ~~~\n\n```md\n"
+	regions, err := Find("README.md", []byte(content), Options{})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(regions) != 2 {
+		t.Fatalf("Expected 2 markdown fences, got %+v", regions)
+	}
+	if regions[0].Markdown != "```go\nx\n```\n*a*\n" || regions[0].Line != 4 {
+		t.Errorf("Unexpected first fence %+v", regions[0])
+	}
+	if regions[1].Markdown != "text\n" || regions[1].Prefix != "  " {
+		t.Errorf("Unexpected second fence %+v", regions[1])
+	}
+}
@@ -0,0 +1,70 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// AdmonitionFormatterPriority defines the priority for admonition formatting
+	AdmonitionFormatterPriority = 85
+
+	// TagCasePreserve keeps alert tags as written
+	TagCasePreserve = "preserve"
+	// TagCaseUpper writes alert tags in upper case ([!NOTE])
+	TagCaseUpper = "upper"
+	// TagCaseLower writes alert tags in lower case ([!note])
+	TagCaseLower = "lower"
+)
+
+// AdmonitionFormatter normalizes GitHub alert tags
+type AdmonitionFormatter struct {
+	BaseFormatter
+}
+
+// NewAdmonitionFormatter creates a new admonition formatter
+func NewAdmonitionFormatter() *AdmonitionFormatter {
+	return &AdmonitionFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "admonition",
+			priority: AdmonitionFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true if this formatter can handle admonitions
+func (f *AdmonitionFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeAdmonition
+}
+
+// Rules describes alert tag normalization
+func (f *AdmonitionFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Normalize the case of GitHub alert tags",
+		ConfigKeys:  []string{"admonition.tag_case"},
+		Autofix:     true,
+		Enabled:     func(cfg *config.Config) bool { return cfg.Admonition.TagCase != TagCasePreserve },
+	}}
+}
+
+// Format applies the configured tag casing to an admonition
+func (f *AdmonitionFormatter) Format(node parser.Node, cfg *config.Config) error {
+	admonition, ok := node.(*parser.Admonition)
+	if !ok {
+		return nil
+	}
+
+	switch cfg.Admonition.TagCase {
+	case TagCaseUpper:
+		admonition.Tag = strings.ToUpper(admonition.Tag)
+	case TagCaseLower:
+		admonition.Tag = strings.ToLower(admonition.Tag)
+	}
+
+	return nil
+}
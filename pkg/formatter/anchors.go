@@ -0,0 +1,164 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// DuplicateHeadingsFormatterPriority runs once numbering, changelog and
+	// replacement rules have settled the heading text
+	DuplicateHeadingsFormatterPriority = 102
+
+	// DuplicateHeadingsOff disables the duplicate heading check
+	DuplicateHeadingsOff = "off"
+	// DuplicateHeadingsSiblings reports headings repeating a sibling under the same parent heading
+	DuplicateHeadingsSiblings = "siblings"
+	// DuplicateHeadingsDocument reports headings repeating any other heading of the document
+	DuplicateHeadingsDocument = "document"
+
+	// DuplicateHeadingFeature is the feature of duplicate heading diagnostics
+	DuplicateHeadingFeature = "duplicate-heading"
+)
+
+// headingIDPattern extracts the #id of a heading attribute block
+var headingIDPattern = regexp.MustCompile(`#([\w:.-]+)`)
+
+// DuplicateHeadingsFormatter reports headings whose anchors collide, as
+// GitHub and MkDocs generate them, and optionally gives the repeats an
+// explicit anchor with a numeric suffix
+type DuplicateHeadingsFormatter struct {
+	BaseFormatter
+}
+
+// NewDuplicateHeadingsFormatter creates a new duplicate headings formatter
+func NewDuplicateHeadingsFormatter() *DuplicateHeadingsFormatter {
+	return &DuplicateHeadingsFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "duplicate-headings",
+			priority: DuplicateHeadingsFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, since anchors are unique per document
+func (f *DuplicateHeadingsFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes the duplicate heading check
+func (f *DuplicateHeadingsFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          DuplicateHeadingFeature,
+		Kind:        RuleKindLint,
+		Description: "Report headings whose anchors collide, optionally giving repeats their own anchor",
+		ConfigKeys:  []string{"heading.duplicates", "heading.fix_duplicate_anchors"},
+		Autofix:     true,
+		Enabled:     func(cfg *config.Config) bool { return cfg.Heading.Duplicates != DuplicateHeadingsOff },
+	}}
+}
+
+// Format checks the headings of the document as heading.duplicates
+// configures, fixing repeats when heading.fix_duplicate_anchors is set
+func (f *DuplicateHeadingsFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	scope := cfg.Heading.Duplicates
+	if !ok || (scope != DuplicateHeadingsSiblings && scope != DuplicateHeadingsDocument) {
+		return nil
+	}
+
+	var headings []*parser.Heading
+	used := make(map[string]bool)
+	walker := parser.NewWalker(doc)
+	for n, ok := walker.Next(); ok; n, ok = walker.Next() {
+		if heading, ok := n.(*parser.Heading); ok {
+			headings = append(headings, heading)
+			used[HeadingAnchor(heading)] = true
+		}
+	}
+
+	// parents holds the headings enclosing the current one, by level
+	var parents []*parser.Heading
+	seen := make(map[*parser.Heading]map[string]bool)
+	for _, heading := range headings {
+		for len(parents) > 0 && parents[len(parents)-1].Level >= heading.Level {
+			parents = parents[:len(parents)-1]
+		}
+		var parent *parser.Heading
+		if scope == DuplicateHeadingsSiblings && len(parents) > 0 {
+			parent = parents[len(parents)-1]
+		}
+		parents = append(parents, heading)
+
+		if seen[parent] == nil {
+			seen[parent] = make(map[string]bool)
+		}
+		anchor := HeadingAnchor(heading)
+		if !seen[parent][anchor] {
+			seen[parent][anchor] = true
+			continue
+		}
+
+		if cfg.Heading.FixDuplicateAnchors && !hasHeadingID(heading) {
+			unique := uniqueAnchor(anchor, used)
+			used[unique] = true
+			heading.Attributes = withHeadingID(heading.Attributes, unique)
+			continue
+		}
+		line, _ := doc.Line(heading)
+		doc.Diagnostics = append(doc.Diagnostics, parser.Diagnostic{
+			Line:    line,
+			Feature: DuplicateHeadingFeature,
+			Message: fmt.Sprintf("duplicate heading %q would share the anchor #%s", heading.Text, anchor),
+		})
+	}
+	return nil
+}
+
+// HeadingAnchor returns the anchor of a heading: the #id of its attribute
+// block, or the id GitHub and MkDocs generate from its text
+func HeadingAnchor(heading *parser.Heading) string {
+	if match := headingIDPattern.FindStringSubmatch(heading.Attributes); match != nil {
+		return match[1]
+	}
+	var sb strings.Builder
+	for _, r := range strings.ToLower(parser.InlineText(heading.Text, nil)) {
+		switch {
+		case r == ' ' || r == '-':
+			sb.WriteRune('-')
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r) || r == '_':
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// hasHeadingID reports whether a heading has an explicit #id
+func hasHeadingID(heading *parser.Heading) bool {
+	return headingIDPattern.MatchString(heading.Attributes)
+}
+
+// uniqueAnchor returns anchor with the lowest numeric suffix not in used
+func uniqueAnchor(anchor string, used map[string]bool) string {
+	for n := 1; ; n++ {
+		candidate := anchor + "-" + strconv.Itoa(n)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// withHeadingID returns an attribute block with #id added in front
+func withHeadingID(attributes, id string) string {
+	if attributes == "" {
+		return "{#" + id + "}"
+	}
+	return "{#" + id + " " + strings.TrimPrefix(attributes, "{")
+}
@@ -0,0 +1,78 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+const duplicateHeadings = "# Guide\n\n## Linux\n\n### Install\n\n## macOS\n\n### Install\n\n### Install {.tab}\n\n## Install-1\n"
+
+func diagnosticLines(doc *parser.Document) []int {
+	var lines []int
+	for _, d := range doc.Diagnostics {
+		lines = append(lines, d.Line)
+	}
+	return lines
+}
+
+func TestDuplicateHeadingsFormatter(t *testing.T) {
+	tests := []struct {
+		scope    string
+		expected []int
+	}{
+		{DuplicateHeadingsOff, nil},
+		{DuplicateHeadingsSiblings, []int{11}},
+		{DuplicateHeadingsDocument, []int{9, 11}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Heading.Duplicates = tt.scope
+			doc := formatWith(t, NewDuplicateHeadingsFormatter(), duplicateHeadings, cfg)
+			got := diagnosticLines(doc)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected diagnostics on lines %v, got %v", tt.expected, doc.Diagnostics)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("Expected diagnostics on lines %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDuplicateHeadingsFormatter_Fix(t *testing.T) {
+	cfg := config.Default()
+	cfg.Heading.Duplicates = DuplicateHeadingsDocument
+	cfg.Heading.FixDuplicateAnchors = true
+	doc := formatWith(t, NewDuplicateHeadingsFormatter(), duplicateHeadings, cfg)
+	if len(doc.Diagnostics) != 0 {
+		t.Errorf("Expected fixed headings not to be reported, got %v", doc.Diagnostics)
+	}
+	expected := []string{"", "", "", "", "{#install-2}", "{#install-3 .tab}", ""}
+	for i, child := range doc.Children {
+		if got := child.(*parser.Heading).Attributes; got != expected[i] {
+			t.Errorf("Heading %d: expected attributes %q, got %q", i, expected[i], got)
+		}
+	}
+}
+
+func TestHeadingAnchor(t *testing.T) {
+	for text, expected := range map[string]string{
+		"Getting Started":          "getting-started",
+		"`go test` & [CI](ci.md)!": "go-test--ci",
+		"Über_uns -- 2.0":          "über_uns----20",
+		"Custom {#my-id}":          "my-id",
+	} {
+		doc, err := parser.NewGoldmarkParser().Parse([]byte("# " + text + "\n"))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if got := HeadingAnchor(doc.Children[0].(*parser.Heading)); got != expected {
+			t.Errorf("HeadingAnchor(%q) = %q, expected %q", text, got, expected)
+		}
+	}
+}
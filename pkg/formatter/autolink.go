@@ -0,0 +1,38 @@
+package formatter
+
+import (
+	"regexp"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// BareURLStyleBare keeps bare URLs as written
+	BareURLStyleBare = "bare"
+	// BareURLStyleAngle converts bare URLs to <https://...> autolinks
+	BareURLStyleAngle = "angle"
+	// BareURLStyleLink converts bare URLs to [https://...](https://...) links
+	BareURLStyleLink = "link"
+)
+
+// bareURLPattern matches a bare URL preceded by whitespace or an opening
+// parenthesis. Trailing punctuation is left outside the URL, as linkify does.
+var bareURLPattern = regexp.MustCompile(`(^|[\s(])((?:https?|ftp)://[^\s<>()]*[^\s<>().,:;!?'"*_~])`)
+
+// convertBareURLs rewrites bare URLs outside code, links and autolinks
+// according to the configured style
+func convertBareURLs(text, style string, syntax *parser.InlineSyntax) string {
+	var replacement string
+	switch style {
+	case BareURLStyleAngle:
+		replacement = "$1<$2>"
+	case BareURLStyleLink:
+		replacement = "$1[$2]($2)"
+	default:
+		return text
+	}
+	return syntax.MapPlain(text, func(plain string) string {
+		return bareURLPattern.ReplaceAllString(plain, replacement)
+	})
+}
@@ -0,0 +1,36 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestConvertBareURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"bare url", "See https://example.com.", "See <https://example.com>."},
+		{"parenthesized", "(http://example.org/p?q=1)", "(<http://example.org/p?q=1>)"},
+		{"already autolink", "<https://example.com>", "<https://example.com>"},
+		{"inline link", "[site](https://example.com)", "[site](https://example.com)"},
+		{"code span", "`curl https://example.com`", "`curl https://example.com`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertBareURLs(tt.text, BareURLStyleAngle, parser.TemplateSyntax(nil)); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	if got := convertBareURLs("See https://example.com.", BareURLStyleLink, parser.TemplateSyntax(nil)); got != "See [https://example.com](https://example.com)." {
+		t.Errorf("Expected link style to write an inline link, got %q", got)
+	}
+	if got := convertBareURLs("See https://example.com", BareURLStyleBare, parser.TemplateSyntax(nil)); got != "See https://example.com" {
+		t.Errorf("Expected bare style to keep URL unchanged, got %q", got)
+	}
+}
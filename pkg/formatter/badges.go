@@ -0,0 +1,62 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Badge layouts
+const (
+	// BadgeLayoutPreserve keeps badges on the lines they were written on
+	BadgeLayoutPreserve = "preserve"
+	// BadgeLayoutOnePerLine puts each badge on a line of its own
+	BadgeLayoutOnePerLine = "one_per_line"
+	// BadgeLayoutSingleLine puts all badges of a paragraph on one line
+	BadgeLayoutSingleLine = "single_line"
+)
+
+// badges returns the badges of text when it holds nothing but badges:
+// images, and links whose text is a single image, as in README badge rows.
+// It returns nil for any other text.
+func badges(text string) []string {
+	var found []string
+	valid := true
+	rest := parser.ReplaceLinks(text, func(link *parser.Link) string {
+		if !link.Image && !isSingleImage(link.Text) {
+			valid = false
+		}
+		found = append(found, link.Markdown())
+		return ""
+	})
+	if !valid || strings.TrimSpace(rest) != "" {
+		return nil
+	}
+	return found
+}
+
+// isSingleImage reports whether inline text is exactly one image
+func isSingleImage(text string) bool {
+	images := 0
+	rest := parser.ReplaceLinks(text, func(link *parser.Link) string {
+		if link.Image {
+			images++
+		}
+		return ""
+	})
+	return images == 1 && strings.TrimSpace(rest) == ""
+}
+
+// layoutBadges returns a badge paragraph laid out as configured. Badge
+// paragraphs are never reflowed, since wrapping inside a badge row breaks
+// it into an unreadable run of fragments.
+func layoutBadges(text string, found []string, layout string, syntax *parser.InlineSyntax) string {
+	switch layout {
+	case BadgeLayoutOnePerLine:
+		return strings.Join(found, "\n")
+	case BadgeLayoutSingleLine:
+		return strings.Join(found, " ")
+	default:
+		return normalizeWhitespace(strings.TrimSpace(text), syntax)
+	}
+}
@@ -0,0 +1,66 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestParagraphFormatter_Badges(t *testing.T) {
+	text := "[![CI](https://ci.example/badge.svg)](https://ci.example)   [![Go Report](https://goreport.example/badge.svg)](https://goreport.example)\n" +
+		"![License](https://img.example/license.svg)"
+	tests := []struct {
+		layout   string
+		expected string
+	}{
+		{
+			layout: BadgeLayoutPreserve,
+			expected: "[![CI](https://ci.example/badge.svg)](https://ci.example) [![Go Report](https://goreport.example/badge.svg)](https://goreport.example)\n" +
+				"![License](https://img.example/license.svg)",
+		},
+		{
+			layout: BadgeLayoutOnePerLine,
+			expected: "[![CI](https://ci.example/badge.svg)](https://ci.example)\n" +
+				"[![Go Report](https://goreport.example/badge.svg)](https://goreport.example)\n" +
+				"![License](https://img.example/license.svg)",
+		},
+		{
+			layout: BadgeLayoutSingleLine,
+			expected: "[![CI](https://ci.example/badge.svg)](https://ci.example) " +
+				"[![Go Report](https://goreport.example/badge.svg)](https://goreport.example) " +
+				"![License](https://img.example/license.svg)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.LineWidth = 40
+			cfg.Images.BadgeLayout = tt.layout
+			paragraph := &parser.Paragraph{Text: text}
+			if err := NewParagraphFormatter().Format(paragraph, cfg); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			if paragraph.Text != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, paragraph.Text)
+			}
+		})
+	}
+}
+
+func TestBadges(t *testing.T) {
+	for text, expected := range map[string]int{
+		"![a](a.svg) [![b](b.svg)](b)":     2,
+		"![a](a.svg) and ![b](b.svg)":      0,
+		"[![a](a.svg) text](a)":            0,
+		"[![a](a.svg)![b](b.svg)](a)":      0,
+		"[link](https://example.com)":      0,
+		"`![a](a.svg)`":                    0,
+		"":                                 0,
+		"![logo][logo] [![ci][ci]][build]": 2,
+	} {
+		if got := len(badges(text)); got != expected {
+			t.Errorf("badges(%q) found %d badges, expected %d", text, got, expected)
+		}
+	}
+}
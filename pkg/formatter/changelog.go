@@ -0,0 +1,247 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// ChangelogFormatterPriority runs before headings and lists are cleaned up
+	ChangelogFormatterPriority = 109
+
+	// ChangelogHeadingFeature is the feature of malformed release heading diagnostics
+	ChangelogHeadingFeature = "changelog-heading"
+
+	// releaseLevel is the heading level of releases
+	releaseLevel = 2
+	// changeTypeLevel is the heading level of the change types of a release
+	changeTypeLevel = 3
+	// releaseDateLayout is the date format of release headings
+	releaseDateLayout = "2006-01-02"
+	// unreleasedHeading is the heading of changes not released yet
+	unreleasedHeading = "[Unreleased]"
+)
+
+var (
+	// changelogTitlePattern matches the title of a changelog
+	changelogTitlePattern = regexp.MustCompile(`(?i)^change\s?log$`)
+	// unreleasedPattern matches the heading of the Unreleased section
+	unreleasedPattern = regexp.MustCompile(`(?i)^\[?unreleased\]?$`)
+	// releaseHeadingPattern matches release headings such as "[1.2.0] - 2024-01-31",
+	// "v1.2.0 (2024-01-31)" or "[1.2.0](https://...) - 2024-01-31 [YANKED]"
+	releaseHeadingPattern = regexp.MustCompile(
+		`^(\[v?\d+\.\d+\.\d+[0-9A-Za-z.+-]*\](?:\([^)]*\)|\[[^\]]*\])?|v?\d+\.\d+\.\d+[0-9A-Za-z.+-]*)` +
+			`(?:\s*[-–—]\s*|\s+)\(?(\d{4}-\d{2}-\d{2})\)?(?:\s+(\[YANKED\]))?$`)
+)
+
+// ChangelogFormatter formats changelogs following Keep a Changelog: it
+// writes release headings as "[1.2.0] - 2024-01-31", orders the change types
+// of each release, keeps each bullet of the Unreleased section on one line
+// and reports release headings it cannot read
+type ChangelogFormatter struct {
+	BaseFormatter
+}
+
+// NewChangelogFormatter creates a new changelog formatter
+func NewChangelogFormatter() *ChangelogFormatter {
+	return &ChangelogFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "changelog",
+			priority: ChangelogFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, since releases span many blocks
+func (f *ChangelogFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes changelog formatting and the release heading check
+func (f *ChangelogFormatter) Rules() []Rule {
+	enabled := func(cfg *config.Config) bool { return cfg.Changelog.Enabled }
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Format Keep a Changelog release headings and order their change types",
+		ConfigKeys:  []string{"changelog.enabled", "changelog.section_order"},
+		Autofix:     true,
+		Enabled:     enabled,
+	}, {
+		ID:          ChangelogHeadingFeature,
+		Kind:        RuleKindLint,
+		Description: "Report changelog release headings that cannot be read",
+		ConfigKeys:  []string{"changelog.enabled"},
+		Enabled:     enabled,
+	}}
+}
+
+// Format applies the changelog rules to documents titled "Changelog" when
+// changelog.enabled is set
+func (f *ChangelogFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok || !cfg.Changelog.Enabled || !IsChangelog(doc) {
+		return nil
+	}
+
+	for i := 0; i < len(doc.Children); i++ {
+		heading, ok := doc.Children[i].(*parser.Heading)
+		if !ok || heading.Level != releaseLevel {
+			continue
+		}
+		end := releaseEnd(doc.Children, i+1)
+
+		unreleased := unreleasedPattern.MatchString(strings.TrimSpace(heading.Text))
+		if unreleased {
+			heading.Text = unreleasedHeading
+			unwrapListItems(doc.Children[i+1 : end])
+		} else if text, ok := releaseHeading(heading.Text); ok {
+			heading.Text = text
+		} else {
+			line, _ := doc.Line(heading)
+			doc.Diagnostics = append(doc.Diagnostics, parser.Diagnostic{
+				Line:    line,
+				Feature: ChangelogHeadingFeature,
+				Message: fmt.Sprintf("release heading %q should read like \"[1.2.0] - 2024-01-31\"", heading.Text),
+			})
+		}
+
+		orderChangeTypes(doc.Children[i+1:end], cfg.Changelog.SectionOrder)
+		i = end - 1
+	}
+	return nil
+}
+
+// IsChangelog reports whether the first heading of doc is a level 1
+// "Changelog" heading
+func IsChangelog(doc *parser.Document) bool {
+	for _, child := range doc.Children {
+		if heading, ok := child.(*parser.Heading); ok {
+			return heading.Level == 1 && changelogTitlePattern.MatchString(strings.TrimSpace(heading.Text))
+		}
+	}
+	return false
+}
+
+// releaseEnd returns the index of the block ending the release starting at
+// start: the next release heading, or the link definitions closing the
+// changelog
+func releaseEnd(blocks []parser.Node, start int) int {
+	for i := start; i < len(blocks); i++ {
+		switch block := blocks[i].(type) {
+		case *parser.Heading:
+			if block.Level <= releaseLevel {
+				return i
+			}
+		case *parser.LinkDefinitions:
+			return i
+		}
+	}
+	return len(blocks)
+}
+
+// releaseHeading returns a release heading in the Keep a Changelog format,
+// or false if text is not a release heading with a valid date
+func releaseHeading(text string) (string, bool) {
+	m := releaseHeadingPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return "", false
+	}
+	if _, err := time.Parse(releaseDateLayout, m[2]); err != nil {
+		return "", false
+	}
+	version := m[1]
+	if !strings.HasPrefix(version, "[") {
+		version = "[" + version + "]"
+	}
+	heading := version + " - " + m[2]
+	if m[3] != "" {
+		heading += " " + m[3]
+	}
+	return heading, true
+}
+
+// orderChangeTypes sorts the change type sections of a release, such as
+// "Added" and "Fixed", into order. Sections of other types follow in their
+// original order; blocks before the first section stay first.
+func orderChangeTypes(blocks []parser.Node, order []string) {
+	rank := make(map[string]int, len(order))
+	canonical := make(map[string]string, len(order))
+	for i, name := range order {
+		key := strings.ToLower(name)
+		rank[key], canonical[key] = i, name
+	}
+
+	type section struct {
+		rank   int
+		blocks []parser.Node
+	}
+	var sections []section
+	first := len(blocks)
+	for i, block := range blocks {
+		heading, ok := block.(*parser.Heading)
+		if !ok || heading.Level != changeTypeLevel {
+			if len(sections) > 0 {
+				sections[len(sections)-1].blocks = append(sections[len(sections)-1].blocks, block)
+			}
+			continue
+		}
+		if len(sections) == 0 {
+			first = i
+		}
+		key := strings.ToLower(strings.TrimSpace(heading.Text))
+		r, known := rank[key]
+		if known {
+			heading.Text = canonical[key]
+		} else {
+			r = len(order)
+		}
+		sections = append(sections, section{rank: r, blocks: []parser.Node{block}})
+	}
+
+	sort.SliceStable(sections, func(i, j int) bool { return sections[i].rank < sections[j].rank })
+	pos := first
+	for _, s := range sections {
+		pos += copy(blocks[pos:], s.blocks)
+	}
+}
+
+// unwrapListItems joins the lines of the list items in blocks, so each
+// bullet stays on one line however long it grows
+func unwrapListItems(blocks []parser.Node) {
+	for _, block := range blocks {
+		list, ok := block.(*parser.List)
+		if !ok {
+			continue
+		}
+		for _, item := range list.Items {
+			item.Text = joinSoftBreaks(item.Text)
+			unwrapListItems(item.Children)
+		}
+	}
+}
+
+// joinSoftBreaks replaces the soft line breaks of text with spaces,
+// keeping hard breaks written with a backslash
+func joinSoftBreaks(text string) string {
+	lines := strings.Split(text, "\n")
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			if strings.HasSuffix(lines[i-1], "\\") {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(strings.TrimSpace(line))
+	}
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestChangelogFormatter(t *testing.T) {
+	content := "# Changelog\n\n## unreleased\n\n- a long\n  bullet\n\n## v1.1.0 (2024-02-29)\n\n### fixed\n\n- x\n\n" +
+		"### Notes\n\nText\n\n### Added\n\n- y\n\n## [1.0.0] – 2023-01-05 [YANKED]\n\n## 0.9 - 2022\n\n[1.0.0]: https://example.com\n"
+	cfg := config.Default()
+	cfg.Changelog.Enabled = true
+	doc := formatWith(t, NewChangelogFormatter(), content, cfg)
+
+	expected := []string{"Changelog", "[Unreleased]", "[v1.1.0] - 2024-02-29", "Added", "Fixed", "Notes",
+		"[1.0.0] - 2023-01-05 [YANKED]", "0.9 - 2022"}
+	if got := headingTexts(doc); strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected headings %q, got %q", expected, got)
+	}
+	if item := doc.Children[2].(*parser.List).Items[0]; item.Text != "a long bullet" {
+		t.Errorf("Expected the Unreleased bullet on one line, got %q", item.Text)
+	}
+	if _, ok := doc.Children[len(doc.Children)-1].(*parser.LinkDefinitions); !ok {
+		t.Errorf("Expected link definitions to stay last, got %s", parser.DebugString(doc))
+	}
+
+	if len(doc.Diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %v", doc.Diagnostics)
+	}
+	if d := doc.Diagnostics[0]; d.Line != 24 || d.Feature != ChangelogHeadingFeature {
+		t.Errorf("Unexpected diagnostic %+v", d)
+	}
+}
+
+func TestChangelogFormatter_OtherDocuments(t *testing.T) {
+	cfg := config.Default()
+	cfg.Changelog.Enabled = true
+	doc := formatWith(t, NewChangelogFormatter(), "# Guide\n\n## v1.0.0 (2024-01-01)\n\n### Fixed\n\n### Added\n", cfg)
+	if got := headingTexts(doc); strings.Join(got, "|") != "Guide|v1.0.0 (2024-01-01)|Fixed|Added" {
+		t.Errorf("Expected documents other than changelogs to be left alone, got %q", got)
+	}
+}
+
+func TestReleaseHeading(t *testing.T) {
+	for text, expected := range map[string]string{
+		"[1.2.0] - 2024-01-31":                   "[1.2.0] - 2024-01-31",
+		"1.2.0-rc.1 2024-01-31":                  "[1.2.0-rc.1] - 2024-01-31",
+		"[1.2.0](https://x.example) —2024-01-31": "[1.2.0](https://x.example) - 2024-01-31",
+		"[1.2.0] - 2024-02-30":                   "",
+		"Release 1.2.0":                          "",
+	} {
+		got, ok := releaseHeading(text)
+		if ok != (expected != "") || got != expected {
+			t.Errorf("releaseHeading(%q) = %q, %t; expected %q", text, got, ok, expected)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package formatter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+func TestFormatContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	doc := &parser.Document{Children: []parser.Node{&parser.Heading{Level: 1, Text: "Title"}}}
+
+	if err := New().FormatContext(ctx, doc, config.Default()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected FormatContext to return context.Canceled, got %v", err)
+	}
+	if _, err := renderer.New().RenderContext(ctx, doc, config.Default()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected RenderContext to return context.Canceled, got %v", err)
+	}
+}
+
+func TestFormatConcurrentMatchesFormat(t *testing.T) {
+	source := []byte(strings.Repeat("Title\n=====\n\n* one\n* two\n\n    code\n\nSome  *text*  with [a link][l].\n\n", 50) +
+		"[l]: https://example.com\n")
+	cfg := config.Default()
+	cfg.Heading.Numbering.Mode = NumberingModeNumber
+	cfg.Links.Style = LinkStyleInline
+
+	render := func(concurrent bool) string {
+		doc, err := parser.New().Parse(source)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		var out strings.Builder
+		if concurrent {
+			err = New().FormatConcurrent(context.Background(), doc, cfg, 3)
+			if err == nil {
+				err = renderer.RenderConcurrent(context.Background(), &out, doc, cfg, 3)
+			}
+		} else {
+			err = New().Format(doc, cfg)
+			if err == nil {
+				err = renderer.New().RenderTo(&out, doc, cfg)
+			}
+		}
+		if err != nil {
+			t.Fatalf("Formatting failed: %v", err)
+		}
+		return out.String()
+	}
+
+	if expected, got := render(false), render(true); got != expected {
+		t.Errorf("Expected concurrent formatting to match:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,218 @@
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// DefinitionsFormatterPriority runs after link style conversion, which
+	// adds and removes definitions
+	DefinitionsFormatterPriority = 104
+
+	// DefinitionSortPreserve keeps definitions in source order
+	DefinitionSortPreserve = "preserve"
+	// DefinitionSortAlphabetical orders definitions by label, numeric labels by value
+	DefinitionSortAlphabetical = "alphabetical"
+	// DefinitionSortFirstUse orders definitions by their first use, unused ones last
+	DefinitionSortFirstUse = "first_use"
+
+	// UnusedDefinitionFeature is the feature of unused definition diagnostics
+	UnusedDefinitionFeature = "unused-definition"
+)
+
+// DefinitionsFormatter tidies link reference definitions: it merges
+// duplicates, sorts each group and reports the definitions no link uses
+type DefinitionsFormatter struct {
+	BaseFormatter
+}
+
+// NewDefinitionsFormatter creates a new link definitions formatter
+func NewDefinitionsFormatter() *DefinitionsFormatter {
+	return &DefinitionsFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "link-definitions",
+			priority: DefinitionsFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, since definitions are shared by all blocks
+func (f *DefinitionsFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes definition tidying and the unused definition check
+func (f *DefinitionsFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Merge duplicate link reference definitions and sort them",
+		ConfigKeys:  []string{"links.sort_definitions", "links.merge_duplicate_definitions"},
+		Autofix:     true,
+		Enabled: func(cfg *config.Config) bool {
+			return cfg.Links.MergeDuplicateDefinitions || cfg.Links.SortDefinitions != DefinitionSortPreserve
+		},
+	}, {
+		ID:          UnusedDefinitionFeature,
+		Kind:        RuleKindLint,
+		Description: "Report link reference definitions that no link uses",
+		ConfigKeys:  []string{"links.warn_unused_definitions"},
+		Enabled:     func(cfg *config.Config) bool { return cfg.Links.WarnUnusedDefinitions },
+	}}
+}
+
+// Format merges, sorts and checks the definitions of the document as configured
+func (f *DefinitionsFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok {
+		return nil
+	}
+
+	if cfg.Links.MergeDuplicateDefinitions {
+		mergeDuplicateDefinitions(doc)
+	}
+	sortOrder := cfg.Links.SortDefinitions
+	sorted := sortOrder == DefinitionSortAlphabetical || sortOrder == DefinitionSortFirstUse
+	if !sorted && !cfg.Links.WarnUnusedDefinitions {
+		return nil
+	}
+
+	uses := definitionUses(doc)
+	for _, group := range definitionGroups(doc) {
+		if sorted {
+			sortDefinitions(group.Definitions, sortOrder, uses)
+		}
+		if !cfg.Links.WarnUnusedDefinitions {
+			continue
+		}
+		for _, definition := range group.Definitions {
+			if _, used := uses[parser.NormalizeLabel(definition.Label)]; used {
+				continue
+			}
+			line, _ := doc.DefinitionLine(definition)
+			doc.Diagnostics = append(doc.Diagnostics, parser.Diagnostic{
+				Line:    line,
+				Feature: UnusedDefinitionFeature,
+				Message: fmt.Sprintf("link definition [%s] is not used", definition.Label),
+			})
+		}
+	}
+	return nil
+}
+
+// mergeDuplicateDefinitions removes definitions with the same destination
+// and title as an earlier one, pointing their references at its label
+func mergeDuplicateDefinitions(doc *parser.Document) {
+	first := make(map[string]*parser.LinkDefinition)
+	renamed := make(map[string]string)
+	duplicates := make(map[*parser.LinkDefinition]bool)
+	for _, group := range definitionGroups(doc) {
+		for _, definition := range group.Definitions {
+			target := definition.Destination + "\x00" + definition.Title
+			if kept, ok := first[target]; ok {
+				renamed[parser.NormalizeLabel(definition.Label)] = kept.Label
+				duplicates[definition] = true
+				continue
+			}
+			first[target] = definition
+		}
+	}
+	if len(duplicates) == 0 {
+		return
+	}
+
+	var convert func(string) string
+	convert = func(text string) string {
+		return parser.ReplaceLinks(text, func(link *parser.Link) string {
+			link.Text = convert(link.Text)
+			if link.Style != parser.LinkStyleInline {
+				if label, ok := renamed[parser.NormalizeLabel(link.ReferenceLabel())]; ok {
+					link.Style, link.Label = parser.LinkStyleFull, label
+				}
+			}
+			return link.Markdown()
+		})
+	}
+	forEachText(doc, convert)
+	removeDefinitions(doc, duplicates)
+}
+
+// definitionUses returns the normalized labels that links and images refer
+// to, each with the position of its first use
+func definitionUses(doc *parser.Document) map[string]int {
+	uses := make(map[string]int)
+	var visit func(string)
+	visit = func(text string) {
+		parser.EachLink(text, func(link *parser.Link, _ int) {
+			if link.Style != parser.LinkStyleInline {
+				label := parser.NormalizeLabel(link.ReferenceLabel())
+				if _, seen := uses[label]; !seen {
+					uses[label] = len(uses)
+				}
+			}
+			visit(link.Text)
+		})
+	}
+	forEachText(doc, func(text string) string {
+		visit(text)
+		return text
+	})
+	return uses
+}
+
+// sortDefinitions sorts definitions alphabetically or by first use
+func sortDefinitions(definitions []*parser.LinkDefinition, order string, uses map[string]int) {
+	sort.SliceStable(definitions, func(i, j int) bool {
+		a, b := parser.NormalizeLabel(definitions[i].Label), parser.NormalizeLabel(definitions[j].Label)
+		if order == DefinitionSortFirstUse {
+			return firstUse(uses, a) < firstUse(uses, b)
+		}
+		na, errA := strconv.Atoi(a)
+		nb, errB := strconv.Atoi(b)
+		switch {
+		case errA == nil && errB == nil:
+			return na < nb
+		case errA == nil || errB == nil:
+			// Numbered definitions come first
+			return errA == nil
+		}
+		return a < b
+	})
+}
+
+// firstUse returns the position of the first use of label, placing unused
+// labels after all used ones
+func firstUse(uses map[string]int, label string) int {
+	if use, ok := uses[label]; ok {
+		return use
+	}
+	return len(uses)
+}
+
+// removeDefinitions removes definitions from their groups, dropping groups
+// left empty
+func removeDefinitions(doc *parser.Document, remove map[*parser.LinkDefinition]bool) {
+	children := doc.Children[:0]
+	for _, child := range doc.Children {
+		if group, ok := child.(*parser.LinkDefinitions); ok {
+			kept := group.Definitions[:0]
+			for _, definition := range group.Definitions {
+				if !remove[definition] {
+					kept = append(kept, definition)
+				}
+			}
+			group.Definitions = kept
+			if len(kept) == 0 {
+				continue
+			}
+		}
+		children = append(children, child)
+	}
+	doc.Children = children
+}
@@ -0,0 +1,121 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+func definitionLabels(doc *parser.Document) []string {
+	var labels []string
+	for _, group := range definitionGroups(doc) {
+		for _, definition := range group.Definitions {
+			labels = append(labels, definition.Label)
+		}
+	}
+	return labels
+}
+
+func TestDefinitionsFormatter_Sort(t *testing.T) {
+	content := "[b] then [a][10] and ![c][2]\n\n[c]: c.md\n[10]: ten.md\n[a]: a.md\n[b]: b.md\n[2]: two.png\n"
+	tests := []struct {
+		order    string
+		expected []string
+	}{
+		{DefinitionSortPreserve, []string{"c", "10", "a", "b", "2"}},
+		{DefinitionSortAlphabetical, []string{"2", "10", "a", "b", "c"}},
+		{DefinitionSortFirstUse, []string{"b", "10", "2", "c", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Links.SortDefinitions = tt.order
+			doc := formatWith(t, NewDefinitionsFormatter(), content, cfg)
+			got := definitionLabels(doc)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("Expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDefinitionsFormatter_MergeDuplicates(t *testing.T) {
+	content := "[one][a], [two][B] and [b]\n\n[a]: https://example.com \"T\"\n[b]: https://example.com \"T\"\n" +
+		"[c]: https://example.com\n"
+	cfg := config.Default()
+	cfg.Links.MergeDuplicateDefinitions = true
+	doc := formatWith(t, NewDefinitionsFormatter(), content, cfg)
+
+	if got := doc.Children[0].(*parser.Paragraph).Text; got != "[one][a], [two][a] and [b][a]" {
+		t.Errorf("Expected references to the merged definition, got %q", got)
+	}
+	if got := definitionLabels(doc); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("Expected definitions a and c, got %v", got)
+	}
+}
+
+func TestDefinitionsFormatter_WarnUnused(t *testing.T) {
+	content := "# [Title][t]\n\n- [![logo][img]](x.md)\n\n[t]: t.md\n[img]: logo.png\n[unused]: u.md\n"
+	cfg := config.Default()
+	cfg.Links.WarnUnusedDefinitions = true
+	doc := formatWith(t, NewDefinitionsFormatter(), content, cfg)
+
+	if len(doc.Diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %v", doc.Diagnostics)
+	}
+	d := doc.Diagnostics[0]
+	if d.Line != 7 || d.Feature != UnusedDefinitionFeature || d.Message != "link definition [unused] is not used" {
+		t.Errorf("Unexpected diagnostic %+v", d)
+	}
+
+	if quiet := formatWith(t, NewDefinitionsFormatter(), content, config.Default()); len(quiet.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics without warn_unused_definitions, got %v", quiet.Diagnostics)
+	}
+}
+
+func TestDefinitionsFormatter_SortReferencesIdempotent(t *testing.T) {
+	content := "# One\n\nSee [a](https://example.com/zzzzzz) and [docs].\n\n[docs]: https://docs.example\n\n" +
+		"## Two\n\nThen [b](https://example.com/yyyyyy).\n\n[api]: https://api.example\n"
+	tests := []struct {
+		placement string
+		expected  string
+	}{
+		{ReferencePlacementDocument, "# One\n\nSee [a][1] and [docs].\n\n[docs]: https://docs.example\n\n" +
+			"## Two\n\nThen [b][2].\n\n[1]: https://example.com/zzzzzz\n[2]: https://example.com/yyyyyy\n[api]: https://api.example\n"},
+		{ReferencePlacementSection, "# One\n\nSee [a][1] and [docs].\n\n[1]: https://example.com/zzzzzz\n[docs]: https://docs.example\n\n" +
+			"## Two\n\nThen [b][2].\n\n[2]: https://example.com/yyyyyy\n[api]: https://api.example\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.placement, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Links.Style = LinkStyleReference
+			cfg.Links.ReferencePlacement = tt.placement
+			cfg.Links.SortDefinitions = DefinitionSortAlphabetical
+
+			output := content
+			for run := range 2 {
+				doc, err := parser.NewGoldmarkParser().Parse([]byte(output))
+				if err != nil {
+					t.Fatalf("Parse failed: %v", err)
+				}
+				if err := New().Format(doc, cfg); err != nil {
+					t.Fatalf("Format failed: %v", err)
+				}
+				if output, err = renderer.New().Render(doc, cfg); err != nil {
+					t.Fatalf("Render failed: %v", err)
+				}
+				if output != tt.expected {
+					t.Fatalf("Expected run %d to give:\n%s\ngot:\n%s", run+1, tt.expected, output)
+				}
+			}
+		})
+	}
+}
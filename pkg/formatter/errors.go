@@ -0,0 +1,26 @@
+package formatter
+
+import "github.com/Gosayram/go-mdfmt/pkg/parser"
+
+// FormatError is an error applying a formatting rule. Rule is the name of
+// the formatter; Line and Column locate the node it failed on when the
+// document was parsed with positions, and are 0 otherwise.
+type FormatError struct {
+	Path   string
+	Line   int
+	Column int
+	Rule   string
+	Err    error
+}
+
+// Error returns the error as path:line:column: message
+func (e *FormatError) Error() string {
+	message := "rule " + e.Rule + " failed: " + e.Err.Error()
+	if location := parser.Location(e.Path, e.Line, e.Column); location != "" {
+		return location + ": " + message
+	}
+	return message
+}
+
+// Unwrap returns the underlying error
+func (e *FormatError) Unwrap() error { return e.Err }
@@ -0,0 +1,48 @@
+package formatter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// failingFormatter fails on every list
+type failingFormatter struct {
+	BaseFormatter
+}
+
+func (f *failingFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeList
+}
+
+func (f *failingFormatter) Format(parser.Node, *config.Config) error {
+	return errFailing
+}
+
+var errFailing = errors.New("unsupported list")
+
+func TestFormatErrorLocatesNode(t *testing.T) {
+	doc, err := parser.NewGoldmarkParserWithOptions(parser.Options{Positions: true}).
+		Parse([]byte("# Title\n\nText.\n\n- one\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	engine := New()
+	engine.Register(&failingFormatter{BaseFormatter{name: "failing", priority: 1000}})
+
+	err = engine.Format(doc, config.Default())
+	var formatErr *FormatError
+	if !errors.As(err, &formatErr) || !errors.Is(err, errFailing) {
+		t.Fatalf("Expected a *FormatError wrapping the failure, got %v", err)
+	}
+	if formatErr.Rule != "failing" || formatErr.Line != 5 {
+		t.Errorf("Expected rule failing at line 5, got %s at %d", formatErr.Rule, formatErr.Line)
+	}
+
+	formatErr.Path = "guide.md"
+	if expected := "guide.md:5: rule failing failed: unsupported list"; err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
@@ -2,8 +2,10 @@
 package formatter
 
 import (
-	"fmt"
+	"context"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/Gosayram/go-mdfmt/pkg/config"
@@ -32,10 +34,19 @@ const (
 
 	// MinHeadingLevel defines the minimum allowed heading level
 	MinHeadingLevel = 1
-	// MaxHeadingLevel defines the maximum allowed heading level
-	MaxHeadingLevel = 6
 	// SetextMaxLevel defines the maximum level for setext-style headings
 	SetextMaxLevel = 2
+
+	// BulletStylePreserve keeps the bullets lists are written with, and the
+	// delimiters of ordered lists
+	BulletStylePreserve = "preserve"
+	// NumberStylePreserve keeps the delimiters ordered lists are written with
+	NumberStylePreserve = "preserve"
+
+	// minFenceLength is the shortest code fence
+	minFenceLength = 3
+	// maxFenceIndent is the deepest indentation of a closing code fence
+	maxFenceIndent = 3
 )
 
 // Formatter represents a markdown formatter interface
@@ -70,12 +81,22 @@ func New() *Engine {
 
 // RegisterDefaults registers the default formatters
 func (e *Engine) RegisterDefaults() {
-	e.Register(&HeadingFormatter{})
-	e.Register(&ParagraphFormatter{})
-	e.Register(&ListFormatter{})
-	e.Register(&CodeBlockFormatter{})
-	e.Register(&InlineFormatter{})
-	e.Register(&WhitespaceFormatter{})
+	e.Register(NewNoReflowFormatter())
+	e.Register(NewHeadingNumberingFormatter())
+	e.Register(NewChangelogFormatter())
+	e.Register(NewReplacementsFormatter())
+	e.Register(NewImageFormatter())
+	e.Register(NewLinkStyleFormatter())
+	e.Register(NewDefinitionsFormatter())
+	e.Register(NewDuplicateHeadingsFormatter())
+	e.Register(NewHeadingFormatter())
+	e.Register(NewParagraphFormatter())
+	e.Register(NewListFormatter())
+	e.Register(NewCodeBlockFormatter())
+	e.Register(NewAdmonitionFormatter())
+	e.Register(NewInlineFormatter())
+	e.Register(NewWhitespaceFormatter())
+	e.Register(NewFormatVersionFormatter())
 }
 
 // Register registers a new node formatter
@@ -91,21 +112,86 @@ func (e *Engine) Register(formatter NodeFormatter) {
 	}
 }
 
-// Format formats the given AST according to configuration
+// Formatters returns the registered formatters in the order they run
+func (e *Engine) Formatters() []NodeFormatter {
+	return append([]NodeFormatter(nil), e.formatters...)
+}
+
+// Format formats the given AST according to configuration.
+// Every document-level formatter runs on the document; other nodes are
+// formatted by the first matching formatter only.
 func (e *Engine) Format(doc *parser.Document, cfg *config.Config) error {
+	return e.FormatContext(context.Background(), doc, cfg)
+}
+
+// FormatContext formats the given AST like Format, returning ctx.Err() if
+// ctx is done before every node has been formatted. The document may then
+// be partially formatted.
+func (e *Engine) FormatContext(ctx context.Context, doc *parser.Document, cfg *config.Config) error {
 	walker := parser.NewWalker(doc)
 
 	for node, ok := walker.Next(); ok; node, ok = walker.Next() {
-		for _, formatter := range e.formatters {
-			if formatter.CanFormat(node.Type()) {
-				if err := formatter.Format(node, cfg); err != nil {
-					return err
-				}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := e.formatNode(doc, node, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FormatConcurrent formats the given AST like FormatContext, formatting
+// runs of top-level blocks on up to workers goroutines once the
+// document-level formatters have run. Every formatter must be safe for
+// concurrent use on different nodes.
+func (e *Engine) FormatConcurrent(ctx context.Context, doc *parser.Document, cfg *config.Config, workers int) error {
+	// Collect the nodes before document-level formatters change the
+	// document, as the walker of FormatContext does
+	runs := parser.SplitBlocks(doc.Children, workers)
+	walkers := make([]*parser.Walker, len(runs))
+	for i, run := range runs {
+		walkers[i] = parser.NewWalker(&parser.Document{Children: run})
+		walkers[i].Next() // skip the document wrapping the run
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := e.formatNode(doc, doc, cfg); err != nil {
+		return err
+	}
+
+	return parser.RunConcurrently(len(walkers), workers, func(i int) error {
+		walker := walkers[i]
+		for node, ok := walker.Next(); ok; node, ok = walker.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := e.formatNode(doc, node, cfg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// formatNode applies every document-level formatter to a document and the
+// first matching formatter to any other node of doc. Errors are returned
+// as a *FormatError naming the formatter.
+func (e *Engine) formatNode(doc *parser.Document, node parser.Node, cfg *config.Config) error {
+	for _, formatter := range e.formatters {
+		if formatter.CanFormat(node.Type()) {
+			if err := formatter.Format(node, cfg); err != nil {
+				line, _ := doc.Line(node)
+				return &FormatError{Line: line, Rule: formatter.Name(), Err: err}
+			}
+			if node.Type() != parser.NodeDocument {
 				break // Only apply first matching formatter
 			}
 		}
 	}
-
 	return nil
 }
 
@@ -145,6 +231,17 @@ func (f *HeadingFormatter) CanFormat(nodeType parser.NodeType) bool {
 	return nodeType == parser.NodeHeading
 }
 
+// Rules describes heading formatting
+func (f *HeadingFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Write headings in one style and normalize their levels",
+		ConfigKeys:  []string{"heading.style", "heading.normalize_levels"},
+		Autofix:     true,
+	}}
+}
+
 // Format applies heading formatting rules.
 func (f *HeadingFormatter) Format(node parser.Node, cfg *config.Config) error {
 	heading, ok := node.(*parser.Heading)
@@ -169,8 +266,8 @@ func (f *HeadingFormatter) Format(node parser.Node, cfg *config.Config) error {
 	// Apply heading level normalization if enabled
 	if cfg.Heading.NormalizeLevels {
 		// Ensure heading level doesn't exceed HTML limit
-		if heading.Level > MaxHeadingLevel {
-			heading.Level = MaxHeadingLevel
+		if heading.Level > config.MaxHeadingLevel {
+			heading.Level = config.MaxHeadingLevel
 		}
 		// Ensure heading level is at least minimum
 		if heading.Level < MinHeadingLevel {
@@ -189,11 +286,33 @@ type ParagraphFormatter struct {
 	BaseFormatter
 }
 
+// NewParagraphFormatter creates a new paragraph formatter
+func NewParagraphFormatter() *ParagraphFormatter {
+	return &ParagraphFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "paragraph",
+			priority: ParagraphFormatterPriority,
+		},
+	}
+}
+
 // CanFormat returns true if this formatter can handle paragraphs
 func (f *ParagraphFormatter) CanFormat(nodeType parser.NodeType) bool {
 	return nodeType == parser.NodeParagraph
 }
 
+// Rules describes paragraph formatting
+func (f *ParagraphFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Wrap paragraphs at the line width and lay out badges, bare URLs and strikethrough",
+		ConfigKeys: []string{"line_width", "images.badge_layout", "links.bare_url_style",
+			"inline.strikethrough_marker"},
+		Autofix: true,
+	}}
+}
+
 // Format applies paragraph formatting rules with text reflow.
 func (f *ParagraphFormatter) Format(node parser.Node, cfg *config.Config) error {
 	paragraph, ok := node.(*parser.Paragraph)
@@ -201,26 +320,51 @@ func (f *ParagraphFormatter) Format(node parser.Node, cfg *config.Config) error
 		return nil
 	}
 
+	syntax := inlineSyntax(cfg)
+	paragraph.Text = applyInlineRules(paragraph.Text, cfg)
+
+	if found := badges(paragraph.Text); found != nil {
+		paragraph.Text = layoutBadges(paragraph.Text, found, cfg.Images.BadgeLayout, syntax)
+		return nil
+	}
+
 	// Apply text reflow if line width is configured
-	if cfg.LineWidth > 0 {
-		paragraph.Text = f.wrapText(paragraph.Text, cfg.LineWidth)
+	if cfg.LineWidth > 0 && !paragraph.NoReflow {
+		paragraph.Text = f.wrapText(paragraph.Text, cfg.LineWidth, syntax)
 	}
 
 	// Clean up excessive whitespace
 	paragraph.Text = strings.TrimSpace(paragraph.Text)
 	// Replace multiple spaces with single space
-	paragraph.Text = normalizeWhitespace(paragraph.Text)
+	paragraph.Text = normalizeWhitespace(paragraph.Text, syntax)
 
 	return nil
 }
 
-// wrapText wraps text to the specified line width
-func (f *ParagraphFormatter) wrapText(text string, width int) string {
+// wrapText wraps text to the specified line width, keeping its hard breaks
+func (f *ParagraphFormatter) wrapText(text string, width int, syntax *parser.InlineSyntax) string {
 	if width <= 0 {
 		return text
 	}
 
-	words := strings.Fields(text)
+	parts := parser.SplitHardBreaks(text)
+	for i, part := range parts {
+		parts[i] = f.wrapPart(part, width, syntax)
+	}
+	return strings.Join(parts, parser.HardBreak)
+}
+
+// wrapPart wraps text without hard breaks to the specified line width,
+// keeping lines of template tags on their own
+func (f *ParagraphFormatter) wrapPart(text string, width int, syntax *parser.InlineSyntax) string {
+	if pieces := syntax.SplitTagLines(text); len(pieces) > 1 {
+		for i, piece := range pieces {
+			pieces[i] = f.wrapPart(piece, width, syntax)
+		}
+		return strings.Join(pieces, "\n")
+	}
+
+	words := syntax.WrapTokens(text)
 	if len(words) == 0 {
 		return text
 	}
@@ -250,14 +394,52 @@ func (f *ParagraphFormatter) wrapText(text string, width int) string {
 	return strings.Join(lines, "\n")
 }
 
-// normalizeWhitespace replaces multiple consecutive spaces with single spaces
-func normalizeWhitespace(text string) string {
-	// Replace multiple spaces/tabs with single space
+// applyInlineRules applies the configured inline rewrites to block text
+func applyInlineRules(text string, cfg *config.Config) string {
+	syntax := inlineSyntax(cfg)
+	text = convertBareURLs(text, cfg.Links.BareURLStyle, syntax)
+	text = normalizeStrikethrough(text, cfg.Inline.StrikethroughMarker, syntax)
+	return text
+}
+
+// inlineSyntax returns the atomic inline constructs of cfg, including its
+// template tags, keeping tabs within words if cfg preserves them
+func inlineSyntax(cfg *config.Config) *parser.InlineSyntax {
+	syntax := parser.TemplateSyntax(cfg.TemplateDelimiters)
+	if cfg.Whitespace.Tabs == "preserve" {
+		return syntax.KeepingTabs()
+	}
+	return syntax
+}
+
+// spaceRunPattern matches runs of spaces and tabs within a line
+var spaceRunPattern = regexp.MustCompile(`[ \t]+`)
+
+// Inline normalization patterns, compiled once rather than per call
+var (
+	// underscoreEmphasisPattern matches _emphasis_
+	underscoreEmphasisPattern = regexp.MustCompile(`\b_([^_]+)_\b`)
+	// paddedLinkTextPattern matches link text with spaces inside the brackets
+	paddedLinkTextPattern = regexp.MustCompile(`\[\s+([^\]]+)\s+\]`)
+)
+
+// normalizeWhitespace replaces multiple consecutive spaces with single spaces,
+// leaving verbatim spans such as math and template tags untouched, and runs
+// holding tabs too if syntax keeps them
+func normalizeWhitespace(text string, syntax *parser.InlineSyntax) string {
+	text = syntax.MapOutsideCode(text, func(prose string) string {
+		return spaceRunPattern.ReplaceAllStringFunc(prose, func(run string) string {
+			if syntax.KeepsTabs() && strings.Contains(run, "\t") {
+				return run
+			}
+			return " "
+		})
+	})
+	// Lines are trimmed of spaces and tabs only, the whitespace of markdown
+	// and wrapping: a line of \v is text
 	lines := strings.Split(text, "\n")
 	for i, line := range lines {
-		// Replace multiple whitespace characters with single space
-		fields := strings.Fields(line)
-		lines[i] = strings.Join(fields, " ")
+		lines[i] = strings.Trim(line, " \t")
 	}
 	return strings.Join(lines, "\n")
 }
@@ -267,67 +449,191 @@ type ListFormatter struct {
 	BaseFormatter
 }
 
-// CanFormat returns true if this formatter can handle lists
+// NewListFormatter creates a new list formatter
+func NewListFormatter() *ListFormatter {
+	return &ListFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "list",
+			priority: ListFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true if this formatter can handle lists, and the
+// document to count the lists following each other
 func (f *ListFormatter) CanFormat(nodeType parser.NodeType) bool {
-	return nodeType == parser.NodeList || nodeType == parser.NodeListItem
+	return nodeType == parser.NodeList || nodeType == parser.NodeListItem || nodeType == parser.NodeDocument
+}
+
+// Rules describes list formatting
+func (f *ListFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Use consistent list markers and indentation",
+		ConfigKeys: []string{"list.bullet_style", "list.alternate_markers", "list.marker_sequence", "list.number_style", "list.number_alignment", "list.numbering",
+			"list.renumber", "list.consistent_indentation", "list.indent"},
+		Autofix: true,
+	}}
 }
 
 // Format applies list formatting rules.
 func (f *ListFormatter) Format(node parser.Node, cfg *config.Config) error {
 	switch n := node.(type) {
+	case *parser.Document:
+		countFollowingLists(n.Children)
 	case *parser.List:
-		return f.formatList(n, cfg)
+		return f.formatList(n, cfg, 0)
 	case *parser.ListItem:
 		return f.formatListItem(n, cfg)
 	}
 	return nil
 }
 
-// formatList handles formatting of list nodes
-func (f *ListFormatter) formatList(list *parser.List, cfg *config.Config) error {
+// countFollowingLists sets the Follows count of the lists of blocks and of
+// the lists nested in them
+func countFollowingLists(blocks []parser.Node) {
+	var previous *parser.List
+	for _, block := range blocks {
+		list, ok := block.(*parser.List)
+		if !ok {
+			previous = nil
+			if container, ok := block.(parser.BlockContainer); ok {
+				countFollowingLists(container.Blocks())
+			}
+			continue
+		}
+
+		list.Follows = 0
+		if previous != nil && previous.Ordered == list.Ordered {
+			list.Follows = previous.Follows + 1
+		}
+		for _, item := range list.Items {
+			countFollowingLists(item.Children)
+		}
+		previous = list
+	}
+}
+
+// formatList handles formatting of list nodes; level counts the unordered
+// lists list is nested in
+func (f *ListFormatter) formatList(list *parser.List, cfg *config.Config, level int) error {
 	if !list.Ordered {
-		f.formatUnorderedList(list, cfg)
+		f.formatUnorderedList(list, cfg, level)
+		level++
 	} else {
 		f.formatOrderedList(list, cfg)
 	}
 
-	return f.processListItems(list, cfg)
+	return f.processListItems(list, cfg, level)
 }
 
-// formatUnorderedList sets consistent bullet style for unordered lists
-func (f *ListFormatter) formatUnorderedList(list *parser.List, cfg *config.Config) {
-	list.Marker = cfg.List.BulletStyle
+// formatUnorderedList sets consistent bullet style for unordered lists.
+// With the preserve bullet style, lists keep the bullet of their source.
+func (f *ListFormatter) formatUnorderedList(list *parser.List, cfg *config.Config, level int) {
+	if cfg.List.BulletStyle == BulletStylePreserve {
+		return
+	}
+	marker := bulletMarker(cfg, level)
+	if list.Follows%2 == 1 {
+		marker = otherBullet(marker)
+	}
+	list.Marker = marker
 	// Apply the same marker to all items
 	for _, item := range list.Items {
-		item.Marker = cfg.List.BulletStyle
+		item.Marker = marker
+	}
+}
+
+// bulletMarker returns the bullet of unordered lists nested in level
+// unordered lists. With alternate markers, levels cycle through the marker
+// sequence starting at the bullet style.
+func bulletMarker(cfg *config.Config, level int) string {
+	sequence := cfg.List.MarkerSequence
+	if !cfg.List.AlternateMarkers || len(sequence) == 0 {
+		return cfg.List.BulletStyle
+	}
+	start := slices.Index(sequence, cfg.List.BulletStyle)
+	if start < 0 {
+		start = 0
 	}
+	return sequence[(start+level)%len(sequence)]
 }
 
-// formatOrderedList sets consistent numbering for ordered lists
+// otherBullet returns a bullet other than marker, for a list following a
+// list with marker
+func otherBullet(marker string) string {
+	if marker == "-" {
+		return "*"
+	}
+	return "-"
+}
+
+// otherDelimiter returns the ordered list delimiter other than delimiter
+func otherDelimiter(delimiter string) string {
+	if delimiter == "." {
+		return ")"
+	}
+	return "."
+}
+
+// formatOrderedList sets consistent numbering for ordered lists. Numbers
+// continue from the start of the list, or are kept without renumbering.
 func (f *ListFormatter) formatOrderedList(list *parser.List, cfg *config.Config) {
+	style := cfg.List.NumberStyle
+	preserve := style == NumberStylePreserve || cfg.List.BulletStyle == BulletStylePreserve
+	if preserve {
+		style = list.Marker
+	}
+	delimiter := "."
+	if style == ")" {
+		delimiter = ")"
+	}
+	if list.Follows%2 == 1 && !preserve {
+		// A list following a list takes the other delimiter
+		delimiter = otherDelimiter(delimiter)
+	}
+
+	width := 0
 	for i, item := range list.Items {
-		switch cfg.List.NumberStyle {
-		case ".":
-			item.Marker = fmt.Sprintf("%d.", i+1)
-		case ")":
-			item.Marker = fmt.Sprintf("%d)", i+1)
-		default:
-			item.Marker = fmt.Sprintf("%d.", i+1)
+		number := list.Start + i
+		switch {
+		case !cfg.List.Renumber:
+			if n, err := strconv.Atoi(strings.TrimRight(strings.TrimSpace(item.Marker), ".)")); err == nil {
+				number = n
+			}
+		case cfg.List.Numbering == "one":
+			number = list.Start
+		}
+		item.Marker = strconv.Itoa(number) + delimiter
+		width = max(width, len(item.Marker))
+	}
+
+	// Padding lines up the markers or the text of items of different widths
+	for _, item := range list.Items {
+		padding := strings.Repeat(" ", width-len(item.Marker))
+		switch cfg.List.NumberAlignment {
+		case "left":
+			item.Marker += padding
+		case "right":
+			item.Marker = padding + item.Marker
 		}
 	}
 }
 
 // processListItems handles list item processing and nested lists
-func (f *ListFormatter) processListItems(list *parser.List, cfg *config.Config) error {
+func (f *ListFormatter) processListItems(list *parser.List, cfg *config.Config, level int) error {
 	for _, item := range list.Items {
+		item.Text = applyInlineRules(item.Text, cfg)
+
 		if cfg.List.ConsistentIndentation {
 			// Normalize list item text (trim and clean whitespace)
 			item.Text = strings.TrimSpace(item.Text)
-			item.Text = normalizeWhitespace(item.Text)
+			item.Text = normalizeWhitespace(item.Text, inlineSyntax(cfg))
 		}
 
 		// Process nested lists recursively
-		if err := f.processNestedLists(item, cfg); err != nil {
+		if err := f.processNestedLists(item, cfg, level); err != nil {
 			return err
 		}
 	}
@@ -335,10 +641,10 @@ func (f *ListFormatter) processListItems(list *parser.List, cfg *config.Config)
 }
 
 // processNestedLists handles nested lists within list items
-func (f *ListFormatter) processNestedLists(item *parser.ListItem, cfg *config.Config) error {
+func (f *ListFormatter) processNestedLists(item *parser.ListItem, cfg *config.Config, level int) error {
 	for _, child := range item.Children {
 		if childList, ok := child.(*parser.List); ok {
-			if err := f.Format(childList, cfg); err != nil {
+			if err := f.formatList(childList, cfg, level); err != nil {
 				return err
 			}
 		}
@@ -350,10 +656,10 @@ func (f *ListFormatter) processNestedLists(item *parser.ListItem, cfg *config.Co
 func (f *ListFormatter) formatListItem(item *parser.ListItem, cfg *config.Config) error {
 	// Individual list item formatting
 	item.Text = strings.TrimSpace(item.Text)
-	item.Text = normalizeWhitespace(item.Text)
+	item.Text = normalizeWhitespace(item.Text, inlineSyntax(cfg))
 
 	// Process nested lists in this item
-	return f.processNestedLists(item, cfg)
+	return f.processNestedLists(item, cfg, 0)
 }
 
 // CodeBlockFormatter formats code block nodes
@@ -361,11 +667,32 @@ type CodeBlockFormatter struct {
 	BaseFormatter
 }
 
+// NewCodeBlockFormatter creates a new code block formatter
+func NewCodeBlockFormatter() *CodeBlockFormatter {
+	return &CodeBlockFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "code-block",
+			priority: CodeFormatterPriority,
+		},
+	}
+}
+
 // CanFormat returns true if this formatter can handle code blocks
 func (f *CodeBlockFormatter) CanFormat(nodeType parser.NodeType) bool {
 	return nodeType == parser.NodeCodeBlock
 }
 
+// Rules describes code block formatting
+func (f *CodeBlockFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Fence code blocks in one style",
+		ConfigKeys:  []string{"code.fence_style", "code.language_detection"},
+		Autofix:     true,
+	}}
+}
+
 // Format formats code block nodes
 func (f *CodeBlockFormatter) Format(node parser.Node, cfg *config.Config) error {
 	code, ok := node.(*parser.CodeBlock)
@@ -373,12 +700,14 @@ func (f *CodeBlockFormatter) Format(node parser.Node, cfg *config.Config) error
 		return nil
 	}
 
-	// Apply fence style preferences
+	// Apply fence style preferences; preserve keeps the fence of the source
 	switch cfg.Code.FenceStyle {
-	case "```":
-		code.Fence = "```"
-	case "~~~":
-		code.Fence = "~~~"
+	case "```", "~~~":
+		// A backtick fence cannot have a backtick in its info string
+		if cfg.Code.FenceStyle[0] == '`' && strings.Contains(code.Info, "`") {
+			break
+		}
+		code.Fence = fenceFor(code.Content, cfg.Code.FenceStyle[0])
 	}
 
 	// Language detection is not implemented yet
@@ -387,16 +716,54 @@ func (f *CodeBlockFormatter) Format(node parser.Node, cfg *config.Config) error
 	return nil
 }
 
+// fenceFor returns the shortest fence of char, at least three long, that no
+// line of content closes
+func fenceFor(content string, char byte) string {
+	length := minFenceLength
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) > maxFenceIndent {
+			continue
+		}
+		run := len(trimmed) - len(strings.TrimLeft(trimmed, string(char)))
+		length = max(length, run+1)
+	}
+	return strings.Repeat(string(char), length)
+}
+
 // WhitespaceFormatter handles whitespace normalization
 type WhitespaceFormatter struct {
 	BaseFormatter
 }
 
+// NewWhitespaceFormatter creates a new whitespace formatter
+func NewWhitespaceFormatter() *WhitespaceFormatter {
+	return &WhitespaceFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "whitespace",
+			priority: WhitespaceFormatterPriority,
+		},
+	}
+}
+
 // CanFormat returns true for all node types (whitespace affects everything)
 func (f *WhitespaceFormatter) CanFormat(_ parser.NodeType) bool {
 	return true // Whitespace formatter can format any node
 }
 
+// Rules describes whitespace normalization
+func (f *WhitespaceFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Trim trailing spaces, limit blank lines and normalize line endings and tabs",
+		ConfigKeys: []string{"whitespace.max_blank_lines", "whitespace.trim_trailing_spaces",
+			"whitespace.hard_break_style", "whitespace.ensure_final_newline", "whitespace.end_of_line",
+			"whitespace.tabs", "whitespace.tab_width"},
+		Autofix: true,
+	}}
+}
+
 // Format applies whitespace normalization rules.
 func (f *WhitespaceFormatter) Format(node parser.Node, cfg *config.Config) error {
 	// Apply whitespace rules based on node type
@@ -414,16 +781,6 @@ func (f *WhitespaceFormatter) Format(node parser.Node, cfg *config.Config) error
 		if cfg.Whitespace.TrimTrailingSpaces {
 			n.Text = strings.TrimSpace(n.Text)
 		}
-	case *parser.CodeBlock:
-		// For code blocks, be more careful with whitespace
-		if cfg.Whitespace.TrimTrailingSpaces {
-			// Only trim trailing spaces at the end of lines, preserve indentation
-			lines := strings.Split(n.Content, "\n")
-			for i, line := range lines {
-				lines[i] = strings.TrimRight(line, " \t")
-			}
-			n.Content = strings.Join(lines, "\n")
-		}
 	case *parser.Text:
 		// Normalize text node whitespace
 		if cfg.Whitespace.TrimTrailingSpaces {
@@ -469,18 +826,29 @@ func (f *InlineFormatter) CanFormat(nodeType parser.NodeType) bool {
 	return nodeType == parser.NodeText || nodeType == parser.NodeParagraph
 }
 
+// Rules describes inline formatting
+func (f *InlineFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Normalize emphasis, links and code spans within text",
+		Autofix:     true,
+	}}
+}
+
 // Format applies inline formatting rules
-func (f *InlineFormatter) Format(node parser.Node, _ *config.Config) error {
+func (f *InlineFormatter) Format(node parser.Node, cfg *config.Config) error {
 	var text string
+	syntax := inlineSyntax(cfg)
 
 	switch n := node.(type) {
 	case *parser.Text:
 		text = n.Content
-		text = f.normalizeInlineElements(text)
+		text = f.normalizeInlineElements(text, syntax)
 		n.Content = text
 	case *parser.Paragraph:
 		text = n.Text
-		text = f.normalizeInlineElements(text)
+		text = f.normalizeInlineElements(text, syntax)
 		n.Text = text
 	default:
 		return nil
@@ -490,10 +858,14 @@ func (f *InlineFormatter) Format(node parser.Node, _ *config.Config) error {
 }
 
 // normalizeInlineElements cleans up inline markdown formatting
-func (f *InlineFormatter) normalizeInlineElements(text string) string {
-	// Normalize inline code backticks (ensure single backticks for simple inline code)
-	text = f.normalizeInlineCode(text)
+func (f *InlineFormatter) normalizeInlineElements(text string, syntax *parser.InlineSyntax) string {
+	return syntax.MapOutsideCode(text, f.normalizeProse)
+}
 
+// normalizeProse cleans up inline formatting outside code spans and
+// verbatim spans such as math. Code spans are kept byte for byte: even the
+// spaces padding their content can be significant.
+func (f *InlineFormatter) normalizeProse(text string) string {
 	// Normalize emphasis and strong formatting
 	text = f.normalizeEmphasis(text)
 
@@ -503,24 +875,11 @@ func (f *InlineFormatter) normalizeInlineElements(text string) string {
 	return text
 }
 
-// normalizeInlineCode ensures consistent inline code formatting
-func (f *InlineFormatter) normalizeInlineCode(text string) string {
-	// Replace multiple backticks with single backticks where appropriate
-	// This is a simplified implementation
-
-	// Remove spaces around inline code
-	re := regexp.MustCompile("`\\s+([^`]+)\\s+`")
-	text = re.ReplaceAllString(text, "`$1`")
-
-	return text
-}
-
 // normalizeEmphasis ensures consistent emphasis formatting
 func (f *InlineFormatter) normalizeEmphasis(text string) string {
 	// Normalize emphasis to use asterisks consistently
 	// Convert _text_ to *text*
-	re := regexp.MustCompile(`\b_([^_]+)_\b`)
-	text = re.ReplaceAllString(text, "*$1*")
+	text = underscoreEmphasisPattern.ReplaceAllString(text, "*$1*")
 
 	// Normalize strong emphasis **text** (keep as is, it's already correct)
 	return text
@@ -532,8 +891,7 @@ func (f *InlineFormatter) normalizeLinks(text string) string {
 	// This is a basic implementation - could be enhanced
 
 	// Remove extra spaces in link text
-	re := regexp.MustCompile(`\[\s+([^\]]+)\s+\]`)
-	text = re.ReplaceAllString(text, "[$1]")
+	text = paddedLinkTextPattern.ReplaceAllString(text, "[$1]")
 
 	return text
 }
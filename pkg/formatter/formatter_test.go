@@ -0,0 +1,320 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+// formatWith parses content, recording source lines, and formats it with f
+func formatWith(t *testing.T, f NodeFormatter, content string, cfg *config.Config) *parser.Document {
+	t.Helper()
+	doc, err := parser.NewGoldmarkParserWithOptions(parser.Options{Positions: true}).Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if err := f.Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	return doc
+}
+
+func TestListFormatter_OrderedMarkers(t *testing.T) {
+	tests := []struct {
+		name      string
+		style     string
+		alignment string
+		numbering string
+		expected  []string
+	}{
+		{"sequential", ".", "none", "sequential", []string{"1.", "2.", "9.", "10."}},
+		{"parenthesis", ")", "none", "sequential", []string{"1)", "2)", "9)", "10)"}},
+		{"left aligned", ".", "left", "sequential", []string{"1. ", "2. ", "9. ", "10."}},
+		{"right aligned", ".", "right", "sequential", []string{" 1.", " 2.", " 9.", "10."}},
+		{"all ones", ".", "right", "one", []string{"1.", "1.", "1.", "1."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.List.NumberStyle = tt.style
+			cfg.List.NumberAlignment = tt.alignment
+			cfg.List.Numbering = tt.numbering
+
+			list := &parser.List{Ordered: true, Start: 1}
+			for range 10 {
+				list.Items = append(list.Items, &parser.ListItem{Text: "item"})
+			}
+			if err := NewListFormatter().Format(list, cfg); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			got := []string{list.Items[0].Marker, list.Items[1].Marker, list.Items[8].Marker, list.Items[9].Marker}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected markers %q, got %q", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestListFormatter_OrderedStart(t *testing.T) {
+	tests := []struct {
+		name      string
+		numbering string
+		renumber  bool
+		expected  []string
+	}{
+		{"sequential", "sequential", true, []string{"3.", "4.", "5."}},
+		{"one", "one", true, []string{"3.", "3.", "3."}},
+		{"kept", "sequential", false, []string{"3.", "7.", "4."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.List.Numbering = tt.numbering
+			cfg.List.Renumber = tt.renumber
+
+			list := &parser.List{Ordered: true, Start: 3, Items: []*parser.ListItem{
+				{Text: "a", Marker: "3)"}, {Text: "b", Marker: "7)"}, {Text: "c", Marker: "4)"},
+			}}
+			if err := NewListFormatter().Format(list, cfg); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			var got []string
+			for _, item := range list.Items {
+				got = append(got, item.Marker)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Expected markers %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestListFormatter_AlternateMarkers(t *testing.T) {
+	doc, err := parser.NewGoldmarkParser().Parse([]byte("- a\n  - b\n    1. c\n       - d\n         - e\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg := config.Default()
+	cfg.List.BulletStyle = "*"
+	cfg.List.AlternateMarkers = true
+
+	if err := NewListFormatter().Format(doc.Children[0], cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var markers []string
+	for list := doc.Children[0].(*parser.List); list != nil; {
+		item := list.Items[0]
+		markers = append(markers, item.Marker)
+		list = nil
+		if len(item.Children) > 0 {
+			list, _ = item.Children[0].(*parser.List)
+		}
+	}
+	expected := []string{"*", "+", "1.", "-", "*"}
+	if strings.Join(markers, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected markers %q, got %q", expected, markers)
+	}
+}
+
+func TestListFormatter_PreserveBullets(t *testing.T) {
+	doc, err := parser.NewGoldmarkParser().Parse([]byte("* a\n  + b\n\n- c\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg := config.Default()
+	cfg.List.BulletStyle = BulletStylePreserve
+	cfg.List.AlternateMarkers = true
+
+	var markers []string
+	for _, child := range doc.Children {
+		if err := NewListFormatter().Format(child, cfg); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		list := child.(*parser.List)
+		markers = append(markers, list.Items[0].Marker)
+		if len(list.Items[0].Children) > 0 {
+			markers = append(markers, list.Items[0].Children[0].(*parser.List).Items[0].Marker)
+		}
+	}
+	expected := []string{"*", "+", "-"}
+	if strings.Join(markers, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected markers %q, got %q", expected, markers)
+	}
+}
+
+func TestListFormatter_PreserveDelimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		bullet   string
+		number   string
+		expected []string
+	}{
+		{"number style", "-", ".", []string{"3.", "4.", "1."}},
+		{"preserve number style", "-", NumberStylePreserve, []string{"3)", "4)", "1."}},
+		{"preserve bullet style", BulletStylePreserve, ")", []string{"3)", "4)", "1."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.NewGoldmarkParser().Parse([]byte("3) a\n4) b\n\ntext\n\n1. c\n"))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			cfg := config.Default()
+			cfg.List.BulletStyle = tt.bullet
+			cfg.List.NumberStyle = tt.number
+
+			var markers []string
+			for _, child := range doc.Children {
+				if list, ok := child.(*parser.List); ok {
+					if err := NewListFormatter().Format(list, cfg); err != nil {
+						t.Fatalf("Format failed: %v", err)
+					}
+					for _, item := range list.Items {
+						markers = append(markers, item.Marker)
+					}
+				}
+			}
+			if strings.Join(markers, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Expected markers %q, got %q", tt.expected, markers)
+			}
+		})
+	}
+}
+
+func TestListFormatter_AdjacentLists(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"delimiters", "1) a\n2) b\n\n10. ten\n", "1. a\n2. b\n\n10) ten\n"},
+		{"bullets", "- a\n- b\n\n* c\n\n+ d\n", "- a\n- b\n\n* c\n\n- d\n"},
+		{"other kind", "* a\n\n1) b\n\n+ c\n", "- a\n\n1. b\n\n- c\n"},
+		{"blockquote", "> - a\n>\n> * b\n", "> - a\n>\n> * b\n"},
+		{"nested", "- x\n  - a\n\n  + b\n", "- x\n  - a\n\n  * b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			output := tt.source
+			for run := range 2 {
+				doc, err := parser.NewGoldmarkParser().Parse([]byte(output))
+				if err != nil {
+					t.Fatalf("Parse failed: %v", err)
+				}
+				if err := New().Format(doc, cfg); err != nil {
+					t.Fatalf("Format failed: %v", err)
+				}
+				if output, err = renderer.New().Render(doc, cfg); err != nil {
+					t.Fatalf("Render failed: %v", err)
+				}
+				if output != tt.expected {
+					t.Fatalf("Expected run %d to give:\n%s\ngot:\n%s", run+1, tt.expected, output)
+				}
+			}
+		})
+	}
+}
+
+func TestCodeBlockFormatter_Fence(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    string
+		fence    string
+		info     string
+		content  string
+		expected string
+	}{
+		{"backticks", "```", "~~~", "", "x\n", "```"},
+		{"tildes", "~~~", "```", "", "x\n", "~~~"},
+		{"shortened", "```", "`````", "", "x\n", "```"},
+		{"longer than content", "```", "~~~", "", "````\nx\n  ```\n", "`````"},
+		{"indented content", "```", "~~~", "", "    ````\n", "```"},
+		{"other character", "~~~", "````", "", "```\n", "~~~"},
+		{"preserve", "preserve", "~~~~", "", "x\n", "~~~~"},
+		{"backtick in info", "```", "~~~", "sh `title`", "x\n", "~~~"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Code.FenceStyle = tt.style
+			code := &parser.CodeBlock{Info: tt.info, Content: tt.content, Fenced: true, Fence: tt.fence}
+			if err := NewCodeBlockFormatter().Format(code, cfg); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			if code.Fence != tt.expected {
+				t.Errorf("Expected fence %q, got %q", tt.expected, code.Fence)
+			}
+		})
+	}
+}
+
+func TestFormattersKeepCode(t *testing.T) {
+	source := "Some  text with ` padded  code ` and ``a `tick`  here``.\n\n" +
+		"```text\ntrailing   \n\ttab\t\n\n\n\nend\n```\n\n<div>\n  html   with  spaces   \n</div>\n"
+	doc, err := parser.NewGoldmarkParser().Parse([]byte(source))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	engine := New()
+	engine.RegisterDefaults()
+	if err := engine.Format(doc, config.Default()); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	paragraph := doc.Children[0].(*parser.Paragraph)
+	if paragraph.Text != "Some text with ` padded  code ` and ``a `tick`  here``." {
+		t.Errorf("Expected code spans kept, got %q", paragraph.Text)
+	}
+	if code := doc.Children[1].(*parser.CodeBlock); code.Content != "trailing   \n\ttab\t\n\n\n\nend\n" {
+		t.Errorf("Expected code block content kept, got %q", code.Content)
+	}
+	if html := doc.Children[2].(*parser.HTMLBlock); html.Content != "<div>\n  html   with  spaces   \n</div>\n" {
+		t.Errorf("Expected HTML block kept, got %q", html.Content)
+	}
+}
+
+func TestParagraphFormatter_Tabs(t *testing.T) {
+	tests := []struct {
+		tabs     string
+		expected string
+	}{
+		{"spaces", "Name: value with\ntabs `a\tb`."},
+		{"preserve", "Name:\tvalue\twith\ntabs `a\tb`."},
+	}
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.LineWidth = 18
+		cfg.Whitespace.Tabs = tt.tabs
+		paragraph := &parser.Paragraph{Text: "Name:\tvalue\twith  tabs `a\tb`."}
+		if err := NewParagraphFormatter().Format(paragraph, cfg); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		if paragraph.Text != tt.expected {
+			t.Errorf("Expected %q with tabs %s, got %q", tt.expected, tt.tabs, paragraph.Text)
+		}
+	}
+}
+
+func TestParagraphFormatter_HardBreaks(t *testing.T) {
+	cfg := config.Default()
+	cfg.LineWidth = 10
+	paragraph := &parser.Paragraph{Text: "one two three\\\nfour five\nsix"}
+	if err := NewParagraphFormatter().Format(paragraph, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if expected := "one two\nthree\\\nfour five\nsix"; paragraph.Text != expected {
+		t.Errorf("Expected %q, got %q", expected, paragraph.Text)
+	}
+}
@@ -0,0 +1,215 @@
+package formatter
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/links"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// ImageFormatterPriority runs before link titles and styles, while
+	// images are still written as they were in the source
+	ImageFormatterPriority = 107
+	// ImagePathFormatterPriority checks image paths after their text is final
+	ImagePathFormatterPriority = 104
+
+	// TitleQuoteDouble writes titles as "title"
+	TitleQuoteDouble = "double"
+	// TitleQuoteSingle writes titles as 'title'
+	TitleQuoteSingle = "single"
+	// TitleQuoteParentheses writes titles as (title)
+	TitleQuoteParentheses = "parentheses"
+
+	// EmptyAltFeature is the feature of empty alt text diagnostics
+	EmptyAltFeature = "empty-alt"
+	// MissingImageFeature is the feature of missing image diagnostics
+	MissingImageFeature = "missing-image"
+)
+
+// altSeparatorPattern matches the separators of words in file names
+var altSeparatorPattern = regexp.MustCompile(`[-_.\s]+`)
+
+// ImageFormatter normalizes images: it fills empty alt text from the file
+// name, quotes titles in the configured style and reports images without
+// alt text
+type ImageFormatter struct {
+	BaseFormatter
+}
+
+// NewImageFormatter creates a new image formatter
+func NewImageFormatter() *ImageFormatter {
+	return &ImageFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "image",
+			priority: ImageFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, so images are reported in order
+func (f *ImageFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes image normalization and the alt text check
+func (f *ImageFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Fill empty alt text from file names and quote image titles consistently",
+		ConfigKeys:  []string{"images.alt_from_filename", "images.title_quote"},
+		Autofix:     true,
+	}, {
+		ID:          EmptyAltFeature,
+		Kind:        RuleKindLint,
+		Description: "Report images without alt text",
+		ConfigKeys:  []string{"images.warn_empty_alt"},
+		Enabled:     func(cfg *config.Config) bool { return cfg.Images.WarnEmptyAlt },
+	}}
+}
+
+// Format applies the images options of cfg to the document
+func (f *ImageFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok {
+		return nil
+	}
+
+	quote := cfg.Images.TitleQuote
+	if cfg.Images.AltFromFilename || (quote != "" && quote != TitleQuoteDouble) {
+		var convert func(string) string
+		convert = func(text string) string {
+			return parser.ReplaceLinks(text, func(link *parser.Link) string {
+				if !link.Image {
+					link.Text = convert(link.Text)
+					return link.Markdown()
+				}
+				if cfg.Images.AltFromFilename && link.Style == parser.LinkStyleInline && strings.TrimSpace(link.Text) == "" {
+					link.Text = altFromFilename(link.Destination)
+				}
+				return imageMarkdown(link, quote)
+			})
+		}
+		forEachText(doc, convert)
+	}
+
+	if cfg.Images.WarnEmptyAlt {
+		for _, link := range links.Extract(doc) {
+			if link.Image && strings.TrimSpace(parser.InlineText(link.Text, nil)) == "" {
+				doc.Diagnostics = append(doc.Diagnostics, parser.Diagnostic{
+					Line:    link.Line,
+					Feature: EmptyAltFeature,
+					Message: fmt.Sprintf("image %s has no alt text", link.URL),
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// altFromFilename returns the words of the file name of an image
+// destination, such as "team photo" for "img/team-photo.png"
+func altFromFilename(destination string) string {
+	name := destination
+	if u, err := url.Parse(destination); err == nil {
+		name = u.Path
+	}
+	name = path.Base(name)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if name == "." || name == "/" {
+		return ""
+	}
+	return strings.TrimSpace(altSeparatorPattern.ReplaceAllString(name, " "))
+}
+
+// imageMarkdown returns an image in markdown syntax with its title in the
+// quote style. Titles that contain the closing quote are written as usual.
+func imageMarkdown(link *parser.Link, quote string) string {
+	title := link.Title
+	if link.Style != parser.LinkStyleInline || title == "" {
+		return link.Markdown()
+	}
+	switch {
+	case quote == TitleQuoteSingle && !strings.Contains(title, "'"):
+		title = "'" + title + "'"
+	case quote == TitleQuoteParentheses && !strings.ContainsAny(title, "()"):
+		title = "(" + title + ")"
+	default:
+		return link.Markdown()
+	}
+	return "![" + link.Text + "](" + parser.FormatDestination(link.Destination, "") + " " + title + ")"
+}
+
+// PathResolver checks the local files that links point to
+type PathResolver interface {
+	// Exists reports whether the target of destination exists; destinations
+	// that are not local files are reported to exist
+	Exists(destination string) bool
+}
+
+// PathResolverFunc adapts a function to the PathResolver interface
+type PathResolverFunc func(destination string) bool
+
+// Exists implements PathResolver.
+func (f PathResolverFunc) Exists(destination string) bool {
+	return f(destination)
+}
+
+// ImagePathFormatter reports images whose local file does not exist
+type ImagePathFormatter struct {
+	BaseFormatter
+	resolver PathResolver
+}
+
+// NewImagePathFormatter creates an image path formatter checking targets with resolver
+func NewImagePathFormatter(resolver PathResolver) *ImagePathFormatter {
+	return &ImagePathFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "image-path",
+			priority: ImagePathFormatterPriority,
+		},
+		resolver: resolver,
+	}
+}
+
+// CanFormat returns true for documents
+func (f *ImagePathFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes the image path check
+func (f *ImagePathFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          MissingImageFeature,
+		Kind:        RuleKindLint,
+		Description: "Report images whose local file does not exist",
+		ConfigKeys:  []string{"images.check_paths"},
+		Enabled:     func(cfg *config.Config) bool { return cfg.Images.CheckPaths },
+	}}
+}
+
+// Format reports missing local images when images.check_paths is enabled
+func (f *ImagePathFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok || !cfg.Images.CheckPaths || f.resolver == nil {
+		return nil
+	}
+
+	for _, link := range links.Extract(doc) {
+		if link.Image && !link.External() && !f.resolver.Exists(link.URL) {
+			doc.Diagnostics = append(doc.Diagnostics, parser.Diagnostic{
+				Line:    link.Line,
+				Feature: MissingImageFeature,
+				Message: fmt.Sprintf("image %s does not exist", link.URL),
+			})
+		}
+	}
+	return nil
+}
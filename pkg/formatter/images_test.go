@@ -0,0 +1,96 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestImageFormatter(t *testing.T) {
+	content := `![](img/team-photo_2024.png "Team") and [![](badge.svg?style=flat)](ci) ![Logo](logo.png "It's")`
+	tests := []struct {
+		name     string
+		images   config.ImagesConfig
+		expected string
+	}{
+		{
+			name:     "defaults",
+			images:   config.ImagesConfig{TitleQuote: TitleQuoteDouble},
+			expected: `![](img/team-photo_2024.png "Team") and [![](badge.svg?style=flat)](ci) ![Logo](logo.png "It's")`,
+		},
+		{
+			name:     "alt from filename",
+			images:   config.ImagesConfig{AltFromFilename: true},
+			expected: `![team photo 2024](img/team-photo_2024.png "Team") and [![badge](badge.svg?style=flat)](ci) ![Logo](logo.png "It's")`,
+		},
+		{
+			name:     "single quotes",
+			images:   config.ImagesConfig{TitleQuote: TitleQuoteSingle},
+			expected: `![](img/team-photo_2024.png 'Team') and [![](badge.svg?style=flat)](ci) ![Logo](logo.png "It's")`,
+		},
+		{
+			name:     "parentheses",
+			images:   config.ImagesConfig{TitleQuote: TitleQuoteParentheses},
+			expected: `![](img/team-photo_2024.png (Team)) and [![](badge.svg?style=flat)](ci) ![Logo](logo.png (It's))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Images = tt.images
+			doc := formatWith(t, NewImageFormatter(), content, cfg)
+			if got := doc.Children[0].(*parser.Paragraph).Text; got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestImageFormatter_WarnEmptyAlt(t *testing.T) {
+	content := "# Title\n\nText ![ok](a.png)\nand ![](b.png) ![][c]\n\n[c]: c.png\n"
+	cfg := config.Default()
+	cfg.Images.WarnEmptyAlt = true
+	doc := formatWith(t, NewImageFormatter(), content, cfg)
+
+	expected := []parser.Diagnostic{
+		{Line: 4, Feature: EmptyAltFeature, Message: "image b.png has no alt text"},
+		{Line: 4, Feature: EmptyAltFeature, Message: "image c.png has no alt text"},
+	}
+	if len(doc.Diagnostics) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, doc.Diagnostics)
+	}
+	for i, d := range doc.Diagnostics {
+		if d != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], d)
+		}
+	}
+}
+
+func TestImagePathFormatter(t *testing.T) {
+	content := "![a](found.png) ![b](missing.png)\n\n![c](https://example.com/c.png)\n"
+	exists := PathResolverFunc(func(destination string) bool {
+		return destination != "missing.png" && destination != "https://example.com/c.png"
+	})
+	cfg := config.Default()
+	cfg.Images.CheckPaths = true
+	doc := formatWith(t, NewImagePathFormatter(exists), content, cfg)
+
+	if len(doc.Diagnostics) != 1 || doc.Diagnostics[0].Line != 1 ||
+		doc.Diagnostics[0].Message != "image missing.png does not exist" {
+		t.Errorf("Expected only missing.png reported, got %v", doc.Diagnostics)
+	}
+}
+
+func TestAltFromFilename(t *testing.T) {
+	for destination, expected := range map[string]string{
+		"img/team-photo.png":          "team photo",
+		"https://x.example/a_b.svg?x": "a b",
+		"./diagram.v2.png":            "diagram v2",
+		"":                            "",
+	} {
+		if got := altFromFilename(destination); got != expected {
+			t.Errorf("altFromFilename(%q) = %q, expected %q", destination, got, expected)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// LinkTitleFormatterPriority defines the priority for link title filling (before link style conversion)
+const LinkTitleFormatterPriority = 106
+
+// TitleResolver looks up the title of the document a link points to
+type TitleResolver interface {
+	// Title returns the H1 title of the target of destination
+	Title(destination string) (string, bool)
+}
+
+// TitleResolverFunc adapts a function to the TitleResolver interface
+type TitleResolverFunc func(destination string) (string, bool)
+
+// Title implements TitleResolver.
+func (f TitleResolverFunc) Title(destination string) (string, bool) {
+	return f(destination)
+}
+
+// LinkTitleFormatter fills the text of links to other documents with the
+// target's title when the text is empty or just repeats the path
+type LinkTitleFormatter struct {
+	BaseFormatter
+	resolver TitleResolver
+}
+
+// NewLinkTitleFormatter creates a link title formatter resolving targets with resolver
+func NewLinkTitleFormatter(resolver TitleResolver) *LinkTitleFormatter {
+	return &LinkTitleFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "link-title",
+			priority: LinkTitleFormatterPriority,
+		},
+		resolver: resolver,
+	}
+}
+
+// CanFormat returns true for documents
+func (f *LinkTitleFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes link titles
+func (f *LinkTitleFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Fill empty links to other documents with the title of the target",
+		ConfigKeys:  []string{"links.auto_title"},
+		Autofix:     true,
+		Enabled:     func(cfg *config.Config) bool { return cfg.Links.AutoTitle },
+	}}
+}
+
+// Format fills empty and bare path link text when links.auto_title is enabled
+func (f *LinkTitleFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok || !cfg.Links.AutoTitle || f.resolver == nil {
+		return nil
+	}
+
+	forEachText(doc, func(text string) string {
+		return parser.ReplaceLinks(text, func(link *parser.Link) string {
+			if link.Image || link.Style != parser.LinkStyleInline || !isPathText(link.Text, link.Destination) {
+				return link.Markdown()
+			}
+			if title, ok := f.resolver.Title(link.Destination); ok {
+				link.Text = title
+			}
+			return link.Markdown()
+		})
+	})
+	return nil
+}
+
+// isPathText reports whether link text is empty or just repeats the
+// destination, with or without its #fragment
+func isPathText(text, destination string) bool {
+	path, _, _ := strings.Cut(destination, "#")
+	return text == "" || text == destination || text == path
+}
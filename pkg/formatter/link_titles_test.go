@@ -0,0 +1,44 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestLinkTitleFormatter(t *testing.T) {
+	resolver := TitleResolverFunc(func(destination string) (string, bool) {
+		if destination == "a.md" || destination == "a.md#x" {
+			return "Title A", true
+		}
+		return "", false
+	})
+
+	content := "[](a.md), [a.md](a.md#x), [Keep](a.md), [](b.md), ![](a.md)\n\n- [](a.md)\n"
+	expected := []string{
+		"[Title A](a.md), [Title A](a.md#x), [Keep](a.md), [](b.md), ![](a.md)",
+		"[Title A](a.md)",
+	}
+
+	for _, enabled := range []bool{true, false} {
+		doc, err := parser.NewGoldmarkParser().Parse([]byte(content))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		cfg := config.Default()
+		cfg.Links.AutoTitle = enabled
+		if err := NewLinkTitleFormatter(resolver).Format(doc, cfg); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+
+		para := doc.Children[0].(*parser.Paragraph).Text
+		item := doc.Children[1].(*parser.List).Items[0].Text
+		if enabled && (para != expected[0] || item != expected[1]) {
+			t.Errorf("Expected %q, got %q and %q", expected, para, item)
+		}
+		if !enabled && para != "[](a.md), [a.md](a.md#x), [Keep](a.md), [](b.md), ![](a.md)" {
+			t.Errorf("Expected links unchanged when disabled, got %q", para)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package formatter
+
+import (
+	"strconv"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// LinkStyleFormatterPriority defines the priority for link style conversion
+	LinkStyleFormatterPriority = 105
+
+	// LinkStylePreserve keeps links and images as written
+	LinkStylePreserve = "preserve"
+	// LinkStyleInline converts references to [text](url "title")
+	LinkStyleInline = "inline"
+	// LinkStyleReference converts inline links to [text][n] with definitions at the end
+	LinkStyleReference = "reference"
+
+	// ReferencePlacementDocument appends new definitions at the end of the document
+	ReferencePlacementDocument = "document"
+	// ReferencePlacementSection appends new definitions at the end of their section
+	ReferencePlacementSection = "section"
+)
+
+// LinkStyleFormatter converts links and images between inline and reference style
+type LinkStyleFormatter struct {
+	BaseFormatter
+}
+
+// NewLinkStyleFormatter creates a new link style formatter
+func NewLinkStyleFormatter() *LinkStyleFormatter {
+	return &LinkStyleFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "link-style",
+			priority: LinkStyleFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, since definitions are shared by all blocks
+func (f *LinkStyleFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes link style conversion
+func (f *LinkStyleFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Convert links and images between inline and reference style",
+		ConfigKeys:  []string{"links.style", "links.reference_threshold", "links.reference_placement"},
+		Autofix:     true,
+		Enabled:     func(cfg *config.Config) bool { return cfg.Links.Style != LinkStylePreserve },
+	}}
+}
+
+// Format converts the links of the document to the configured style
+func (f *LinkStyleFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok {
+		return nil
+	}
+
+	switch cfg.Links.Style {
+	case LinkStyleInline:
+		inlineLinks(doc)
+	case LinkStyleReference:
+		referenceLinks(doc, cfg.Links.ReferenceThreshold, cfg.Links.ReferencePlacement)
+	}
+	return nil
+}
+
+// inlineLinks replaces references that resolve to a definition with inline
+// links and drops the definitions that were used
+func inlineLinks(doc *parser.Document) {
+	definitions := make(map[string]*parser.LinkDefinition)
+	for _, group := range definitionGroups(doc) {
+		for _, definition := range group.Definitions {
+			key := parser.NormalizeLabel(definition.Label)
+			if _, exists := definitions[key]; !exists {
+				definitions[key] = definition
+			}
+		}
+	}
+	if len(definitions) == 0 {
+		return
+	}
+
+	used := make(map[*parser.LinkDefinition]bool)
+	var convert func(string) string
+	convert = func(text string) string {
+		return parser.ReplaceLinks(text, func(link *parser.Link) string {
+			if link.Style != parser.LinkStyleInline {
+				if definition, ok := definitions[parser.NormalizeLabel(link.ReferenceLabel())]; ok {
+					used[definition] = true
+					link.Style = parser.LinkStyleInline
+					link.Destination, link.Title = definition.Destination, definition.Title
+				}
+			}
+			link.Text = convert(link.Text)
+			return link.Markdown()
+		})
+	}
+	forEachText(doc, convert)
+	removeDefinitions(doc, used)
+}
+
+// referenceLinks replaces inline links whose URL is longer than threshold
+// with numbered full references, reusing existing definitions with the same
+// destination and title. New definitions are appended at the end of the
+// document, or with the section placement before the heading that ends the
+// section of their first use.
+func referenceLinks(doc *parser.Document, threshold int, placement string) {
+	byTarget := make(map[string]string)
+	next := 1
+	for _, group := range definitionGroups(doc) {
+		for _, definition := range group.Definitions {
+			target := definition.Destination + "\x00" + definition.Title
+			if _, exists := byTarget[target]; !exists {
+				byTarget[target] = definition.Label
+			}
+			if n, err := strconv.Atoi(definition.Label); err == nil && n >= next {
+				next = n + 1
+			}
+		}
+	}
+
+	added := &parser.LinkDefinitions{}
+	var convert func(string) string
+	convert = func(text string) string {
+		return parser.ReplaceLinks(text, func(link *parser.Link) string {
+			link.Text = convert(link.Text)
+			if link.Style != parser.LinkStyleInline || len(link.Destination) <= threshold {
+				return link.Markdown()
+			}
+			target := link.Destination + "\x00" + link.Title
+			label, ok := byTarget[target]
+			if !ok {
+				label = strconv.Itoa(next)
+				next++
+				byTarget[target] = label
+				added.Definitions = append(added.Definitions, &parser.LinkDefinition{
+					Label:       label,
+					Destination: link.Destination,
+					Title:       link.Title,
+				})
+			}
+			link.Style, link.Label = parser.LinkStyleFull, label
+			return link.Markdown()
+		})
+	}
+
+	if placement != ReferencePlacementSection {
+		forEachText(doc, convert)
+		doc.Children = appendDefinitions(doc.Children, added)
+		return
+	}
+
+	children := make([]parser.Node, 0, len(doc.Children))
+	for _, child := range doc.Children {
+		if _, ok := child.(*parser.Heading); ok && len(added.Definitions) > 0 {
+			children = appendDefinitions(children, added)
+			added = &parser.LinkDefinitions{}
+		}
+		forEachText(&parser.Document{Children: []parser.Node{child}}, convert)
+		children = append(children, child)
+	}
+	doc.Children = appendDefinitions(children, added)
+}
+
+// appendDefinitions appends the added definitions to blocks. They join the
+// group of definitions blocks end with, as the rendered definitions would
+// once parsed again.
+func appendDefinitions(blocks []parser.Node, added *parser.LinkDefinitions) []parser.Node {
+	if len(added.Definitions) == 0 {
+		return blocks
+	}
+	if len(blocks) > 0 {
+		if group, ok := blocks[len(blocks)-1].(*parser.LinkDefinitions); ok {
+			group.Definitions = append(group.Definitions, added.Definitions...)
+			return blocks
+		}
+	}
+	return append(blocks, added)
+}
+
+// definitionGroups returns the link definition groups of the document
+func definitionGroups(doc *parser.Document) []*parser.LinkDefinitions {
+	var groups []*parser.LinkDefinitions
+	for _, child := range doc.Children {
+		if group, ok := child.(*parser.LinkDefinitions); ok {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// forEachText rewrites the inline text of every block in the document
+func forEachText(doc *parser.Document, fn func(string) string) {
+	walker := parser.NewWalker(doc)
+	for node, ok := walker.Next(); ok; node, ok = walker.Next() {
+		switch n := node.(type) {
+		case *parser.Paragraph:
+			n.Text = fn(n.Text)
+		case *parser.Heading:
+			n.Text = fn(n.Text)
+		case *parser.DefinitionTerm:
+			n.Text = fn(n.Text)
+		case *parser.List:
+			forEachItemText(n, fn)
+		}
+	}
+}
+
+// forEachItemText rewrites the text of list items, including nested lists
+func forEachItemText(list *parser.List, fn func(string) string) {
+	for _, item := range list.Items {
+		item.Text = fn(item.Text)
+		for _, child := range item.Children {
+			if nested, ok := child.(*parser.List); ok {
+				forEachItemText(nested, fn)
+			}
+		}
+	}
+}
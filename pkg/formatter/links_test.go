@@ -0,0 +1,97 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestLinkStyleFormatter_Reference(t *testing.T) {
+	content := "See [a](https://a.example \"A\") and ![img](https://a.example \"A\").\n\n" +
+		"[![CI](https://ci/badge.svg)](https://ci/run) `[x](y)`\n\n[3]: https://ci/run\n"
+	cfg := config.Default()
+	cfg.Links.Style = LinkStyleReference
+	doc := formatWith(t, NewLinkStyleFormatter(), content, cfg)
+
+	expected := []string{
+		"See [a][4] and ![img][4].",
+		"[![CI][5]][3] `[x](y)`",
+	}
+	for i, want := range expected {
+		if got := doc.Children[i].(*parser.Paragraph).Text; got != want {
+			t.Errorf("Paragraph %d: expected %q, got %q", i, want, got)
+		}
+	}
+
+	// The added definitions join the group ending the document
+	group, ok := doc.Children[len(doc.Children)-1].(*parser.LinkDefinitions)
+	if !ok || len(group.Definitions) != 3 {
+		t.Fatalf("Expected 2 definitions added to the group, got %v", doc.Children[len(doc.Children)-1])
+	}
+	if got := group.Definitions[1].Markdown(); got != `[4]: https://a.example "A"` {
+		t.Errorf("Unexpected definition %q", got)
+	}
+}
+
+func TestLinkStyleFormatter_ReferenceThresholdAndSections(t *testing.T) {
+	content := "# One\n\n[short](a.md) and [long](https://example.com/a/long/path)\n\n" +
+		"## Two\n\n[again](https://example.com/a/long/path) [other](https://example.com/other/path)\n"
+	doc, err := parser.NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg := config.Default()
+	cfg.Links.Style = LinkStyleReference
+	cfg.Links.ReferenceThreshold = 10
+	cfg.Links.ReferencePlacement = ReferencePlacementSection
+	if err := NewLinkStyleFormatter().Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	if len(doc.Children) != 6 {
+		t.Fatalf("Expected 6 blocks, got %v", doc.Children)
+	}
+	if got := doc.Children[1].(*parser.Paragraph).Text; got != "[short](a.md) and [long][1]" {
+		t.Errorf("Expected only the long link converted, got %q", got)
+	}
+	if got := doc.Children[4].(*parser.Paragraph).Text; got != "[again][1] [other][2]" {
+		t.Errorf("Expected the first definition reused, got %q", got)
+	}
+	for i, label := range map[int]string{2: "1", 5: "2"} {
+		group, ok := doc.Children[i].(*parser.LinkDefinitions)
+		if !ok || len(group.Definitions) != 1 || group.Definitions[0].Label != label {
+			t.Errorf("Expected definition %q at the end of its section, got %v", label, doc.Children[i])
+		}
+	}
+}
+
+func TestLinkStyleFormatter_Inline(t *testing.T) {
+	content := "- [a][Ref], ![logo][] and [ref]\n- [unknown]\n\n[ref]: https://r.example 'T'\n[logo]: img.png\n[unused]: https://u.example\n"
+	cfg := config.Default()
+	cfg.Links.Style = LinkStyleInline
+	doc := formatWith(t, NewLinkStyleFormatter(), content, cfg)
+
+	list := doc.Children[0].(*parser.List)
+	if got, want := list.Items[0].Text, `[a](https://r.example "T"), ![logo](img.png) and [ref](https://r.example "T")`; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := list.Items[1].Text; got != "[unknown]" {
+		t.Errorf("Expected unknown reference kept, got %q", got)
+	}
+
+	definitions, ok := doc.Children[1].(*parser.LinkDefinitions)
+	if !ok || len(definitions.Definitions) != 1 || definitions.Definitions[0].Label != "unused" {
+		t.Errorf("Expected only the unused definition to remain, got %v", doc.Children[1:])
+	}
+}
+
+func TestLinkStyleFormatter_Preserve(t *testing.T) {
+	content := "[a][r] and [b](https://b.example)\n\n[r]: https://r.example\n"
+	cfg := config.Default()
+	cfg.Links.Style = LinkStylePreserve
+	doc := formatWith(t, NewLinkStyleFormatter(), content, cfg)
+	if got := doc.Children[0].(*parser.Paragraph).Text; got != "[a][r] and [b](https://b.example)" {
+		t.Errorf("Expected links unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,37 @@
+package formatter
+
+import (
+	"regexp"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// StrikethroughMarkerPreserve keeps strikethrough tildes as written
+	StrikethroughMarkerPreserve = "preserve"
+	// StrikethroughMarkerDouble writes strikethrough as ~~text~~
+	StrikethroughMarkerDouble = "~~"
+	// StrikethroughMarkerSingle writes strikethrough as ~text~
+	StrikethroughMarkerSingle = "~"
+)
+
+// strikethroughPattern matches ~text~ or ~~text~~ with no space inside the markers
+var strikethroughPattern = regexp.MustCompile(`(~~?)([^~\s](?:[^~]*[^~\s])?)(~~?)`)
+
+// normalizeStrikethrough rewrites strikethrough marks outside code and links
+// to use the configured number of tildes
+func normalizeStrikethrough(text, marker string, syntax *parser.InlineSyntax) string {
+	if marker != StrikethroughMarkerDouble && marker != StrikethroughMarkerSingle {
+		return text
+	}
+	return syntax.MapPlain(text, func(plain string) string {
+		return strikethroughPattern.ReplaceAllStringFunc(plain, func(match string) string {
+			parts := strikethroughPattern.FindStringSubmatch(match)
+			if parts[1] != parts[3] {
+				return match
+			}
+			return marker + parts[2] + marker
+		})
+	})
+}
@@ -0,0 +1,30 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestNormalizeStrikethrough(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		marker   string
+		expected string
+	}{
+		{"single to double", "a ~gone~ b", StrikethroughMarkerDouble, "a ~~gone~~ b"},
+		{"double to single", "a ~~gone~~ b", StrikethroughMarkerSingle, "a ~gone~ b"},
+		{"preserve", "a ~gone~ b", StrikethroughMarkerPreserve, "a ~gone~ b"},
+		{"mismatched markers", "a ~~gone~ b", StrikethroughMarkerDouble, "a ~~gone~ b"},
+		{"code span", "`~x~` and ~y~", StrikethroughMarkerDouble, "`~x~` and ~~y~~"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeStrikethrough(tt.text, tt.marker, parser.TemplateSyntax(nil)); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// HeadingNumberingFormatterPriority defines the priority for heading numbering (runs before heading cleanup)
+	HeadingNumberingFormatterPriority = 110
+
+	// NumberingModeNone disables heading numbering
+	NumberingModeNone = "none"
+	// NumberingModeNumber inserts or refreshes heading numbers
+	NumberingModeNumber = "number"
+	// NumberingModeStrip removes existing heading numbers
+	NumberingModeStrip = "strip"
+)
+
+// headingNumberPattern matches a number prefix in the format numbering
+// generates: "1." for a single level and "2.3" for deeper ones. A number
+// without a dot, such as the year in "2024 Roadmap", is part of the title.
+var headingNumberPattern = regexp.MustCompile(`^(?:\d+\.|\d+(?:\.\d+)+\.?)\s+`)
+
+// HeadingNumberingFormatter inserts, refreshes or strips hierarchical heading numbers
+type HeadingNumberingFormatter struct {
+	BaseFormatter
+}
+
+// NewHeadingNumberingFormatter creates a new heading numbering formatter
+func NewHeadingNumberingFormatter() *HeadingNumberingFormatter {
+	return &HeadingNumberingFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "heading-numbering",
+			priority: HeadingNumberingFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, since numbering depends on all headings
+func (f *HeadingNumberingFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes heading numbering
+func (f *HeadingNumberingFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Insert, refresh or strip hierarchical heading numbers",
+		ConfigKeys:  []string{"heading.numbering.mode", "heading.numbering.start_level"},
+		Autofix:     true,
+		Enabled:     func(cfg *config.Config) bool { return cfg.Heading.Numbering.Mode != NumberingModeNone },
+	}}
+}
+
+// Format numbers or strips the headings of the document
+func (f *HeadingNumberingFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok {
+		return nil
+	}
+
+	numbering := cfg.Heading.Numbering
+	if numbering.Mode != NumberingModeNumber && numbering.Mode != NumberingModeStrip {
+		return nil
+	}
+	for _, h := range numberedHeadings(doc, numbering.StartLevel) {
+		text := strings.TrimSpace(h.heading.Text)
+		if h.generated {
+			text = StripHeadingNumber(text)
+		}
+		if numbering.Mode == NumberingModeNumber {
+			text = formatHeadingNumber(h.number) + " " + text
+		}
+		h.heading.Text = text
+	}
+
+	return nil
+}
+
+// numberedHeading is a heading at a numbered level with the number
+// numbering gives it
+type numberedHeading struct {
+	heading *parser.Heading
+	number  []int
+	// generated is set when the heading text starts with a number
+	// numbering wrote, to be refreshed or stripped
+	generated bool
+}
+
+// numberedHeadings returns the headings at or below startLevel with their
+// hierarchical numbers. A number the text starts with is taken for one
+// numbering wrote when it has as many parts as the heading's number, its
+// parent parts are those of the parent heading, and its last part is at
+// most twice the count of the heading and its siblings. That allows for
+// sections added, moved or removed since, while "1.5 Release notes" on a
+// first-level heading or "2024. A year" keep their number.
+func numberedHeadings(doc *parser.Document, startLevel int) []numberedHeading {
+	var headings []numberedHeading
+	siblings := make(map[string]int)
+	counters := make([]int, config.MaxHeadingLevel+1)
+	for _, child := range doc.Children {
+		heading, ok := child.(*parser.Heading)
+		if !ok || heading.Level < startLevel || heading.Level > config.MaxHeadingLevel {
+			continue
+		}
+		counters[heading.Level]++
+		for level := heading.Level + 1; level <= config.MaxHeadingLevel; level++ {
+			counters[level] = 0
+		}
+		number := slices.Clone(counters[startLevel : heading.Level+1])
+		siblings[parentKey(number)]++
+		headings = append(headings, numberedHeading{heading: heading, number: number})
+	}
+
+	// written holds the generated numbers of the headings at each level as
+	// written, which their children's numbers continue
+	written := make([][]int, config.MaxHeadingLevel+1)
+	for i, h := range headings {
+		level := h.heading.Level
+		for deeper := level; deeper <= config.MaxHeadingLevel; deeper++ {
+			written[deeper] = nil
+		}
+		parts := headingNumber(h.heading.Text)
+		if len(parts) != len(h.number) {
+			continue
+		}
+		last := len(parts) - 1
+		parent := h.number[:last]
+		if written[level-1] != nil {
+			parent = written[level-1]
+		}
+		if slices.Equal(parts, h.number) ||
+			(slices.Equal(parts[:last], parent) && parts[last] <= 2*siblings[parentKey(h.number)]) {
+			headings[i].generated = true
+			written[level] = parts
+		}
+	}
+	return headings
+}
+
+// parentKey identifies the parent of the heading numbered number
+func parentKey(number []int) string {
+	return fmt.Sprint(number[:len(number)-1])
+}
+
+// headingNumber returns the parts of the number text starts with, in the
+// format numbering writes, or nil
+func headingNumber(text string) []int {
+	match := headingNumberPattern.FindString(strings.TrimSpace(text))
+	if match == "" {
+		return nil
+	}
+	fields := strings.Split(strings.TrimSuffix(strings.TrimSpace(match), "."), ".")
+	parts := make([]int, len(fields))
+	for i, field := range fields {
+		part, err := strconv.Atoi(field)
+		if err != nil {
+			return nil
+		}
+		parts[i] = part
+	}
+	return parts
+}
+
+// formatHeadingNumber formats number as numbering writes it: "1." for a
+// single level and "2.3" for deeper ones
+func formatHeadingNumber(number []int) string {
+	parts := make([]string, len(number))
+	for i, part := range number {
+		parts[i] = strconv.Itoa(part)
+	}
+	if len(parts) == 1 {
+		return parts[0] + "."
+	}
+	return strings.Join(parts, ".")
+}
+
+// StripHeadingNumber removes a leading hierarchical number from heading text
+func StripHeadingNumber(text string) string {
+	return headingNumberPattern.ReplaceAllString(strings.TrimSpace(text), "")
+}
@@ -0,0 +1,174 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func numberingDocument() *parser.Document {
+	return &parser.Document{
+		Children: []parser.Node{
+			&parser.Heading{Level: 1, Text: "Specification"},
+			&parser.Heading{Level: 2, Text: "Introduction"},
+			&parser.Paragraph{Text: "Body"},
+			&parser.Heading{Level: 3, Text: "1.4 Scope"},
+			&parser.Heading{Level: 3, Text: "Terms"},
+			&parser.Heading{Level: 4, Text: "Glossary"},
+			&parser.Heading{Level: 2, Text: "1. Design"},
+			&parser.Heading{Level: 3, Text: "Goals"},
+		},
+	}
+}
+
+func headingTexts(doc *parser.Document) []string {
+	var texts []string
+	for _, child := range doc.Children {
+		if heading, ok := child.(*parser.Heading); ok {
+			texts = append(texts, heading.Text)
+		}
+	}
+	return texts
+}
+
+func TestHeadingNumberingFormatter_Number(t *testing.T) {
+	cfg := config.Default()
+	cfg.Heading.Numbering.Mode = NumberingModeNumber
+
+	doc := numberingDocument()
+	if err := NewHeadingNumberingFormatter().Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	expected := []string{
+		"Specification",
+		"1. Introduction",
+		"1.1 Scope",
+		"1.2 Terms",
+		"1.2.1 Glossary",
+		"2. Design",
+		"2.1 Goals",
+	}
+	assertHeadingTexts(t, headingTexts(doc), expected)
+
+	// Numbering an already numbered document is idempotent
+	if err := NewHeadingNumberingFormatter().Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	assertHeadingTexts(t, headingTexts(doc), expected)
+}
+
+func TestHeadingNumberingFormatter_Strip(t *testing.T) {
+	cfg := config.Default()
+	cfg.Heading.Numbering.Mode = NumberingModeStrip
+
+	doc := numberingDocument()
+	if err := NewHeadingNumberingFormatter().Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	expected := []string{"Specification", "Introduction", "Scope", "Terms", "Glossary", "Design", "Goals"}
+	assertHeadingTexts(t, headingTexts(doc), expected)
+}
+
+func TestHeadingNumberingFormatter_NumberedTitles(t *testing.T) {
+	titles := []string{"2024 Roadmap", "3 Little Pigs", "1.5x Faster Builds", "42"}
+	for _, mode := range []string{NumberingModeNumber, NumberingModeStrip} {
+		t.Run(mode, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Heading.Numbering.Mode = mode
+			cfg.Heading.Numbering.StartLevel = 1
+
+			doc := &parser.Document{}
+			for _, title := range titles {
+				doc.Children = append(doc.Children, &parser.Heading{Level: 1, Text: title})
+			}
+			if err := NewHeadingNumberingFormatter().Format(doc, cfg); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			expected := titles
+			if mode == NumberingModeNumber {
+				expected = []string{"1. 2024 Roadmap", "2. 3 Little Pigs", "3. 1.5x Faster Builds", "4. 42"}
+			}
+			assertHeadingTexts(t, headingTexts(doc), expected)
+		})
+	}
+}
+
+func TestHeadingNumberingFormatter_TitleNumbers(t *testing.T) {
+	numbered := func(texts ...string) *parser.Document {
+		doc := &parser.Document{}
+		for i, text := range texts {
+			doc.Children = append(doc.Children, &parser.Heading{Level: i%2 + 1, Text: text})
+		}
+		return doc
+	}
+	tests := []struct {
+		name     string
+		doc      *parser.Document
+		mode     string
+		expected []string
+	}{
+		{"strip", numbered("1.5 Release notes", "2024. A year"), NumberingModeStrip,
+			[]string{"1.5 Release notes", "2024. A year"}},
+		{"number", numbered("1.5 Release notes", "2024. A year"), NumberingModeNumber,
+			[]string{"1. 1.5 Release notes", "1.1 2024. A year"}},
+		{"refresh", numbered("1. Intro", "1.1 Scope", "3. Design", "3.2 Goals", "Added"), NumberingModeNumber,
+			[]string{"1. Intro", "1.1 Scope", "2. Design", "2.1 Goals", "3. Added"}},
+		{"refresh title number", numbered("2. 2024. A year"), NumberingModeNumber, []string{"1. 2024. A year"}},
+		{"parent mismatch", numbered("Intro", "2.1 Scope"), NumberingModeStrip, []string{"Intro", "2.1 Scope"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Heading.Numbering.Mode = tt.mode
+			cfg.Heading.Numbering.StartLevel = 1
+			if err := NewHeadingNumberingFormatter().Format(tt.doc, cfg); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			assertHeadingTexts(t, headingTexts(tt.doc), tt.expected)
+		})
+	}
+}
+
+func TestStripHeadingNumber(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"1. Introduction", "Introduction"},
+		{"1.2 Scope", "Scope"},
+		{"1.2.3. Glossary", "Glossary"},
+		{"2024 Roadmap", "2024 Roadmap"},
+		{"3 Little Pigs", "3 Little Pigs"},
+		{"1.5x Faster", "1.5x Faster"},
+	}
+	for _, tt := range tests {
+		if got := StripHeadingNumber(tt.text); got != tt.expected {
+			t.Errorf("StripHeadingNumber(%q) = %q, expected %q", tt.text, got, tt.expected)
+		}
+	}
+}
+
+func TestHeadingNumberingFormatter_NoneLeavesText(t *testing.T) {
+	doc := numberingDocument()
+	if err := NewHeadingNumberingFormatter().Format(doc, config.Default()); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	assertHeadingTexts(t, headingTexts(doc), headingTexts(numberingDocument()))
+}
+
+func assertHeadingTexts(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d headings, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Heading %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
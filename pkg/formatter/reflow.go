@@ -0,0 +1,168 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// NoReflowFormatterPriority defines the priority for marking paragraphs
+	// that keep their line breaks
+	NoReflowFormatterPriority = 111
+	// ReflowModeWrap wraps paragraphs at the line width
+	ReflowModeWrap = "wrap"
+	// ReflowModePreserve keeps the line breaks of every paragraph
+	ReflowModePreserve = "preserve"
+	// WrapParagraph lists top-level paragraphs in reflow.wrap
+	WrapParagraph = "paragraph"
+	// WrapBlockquote lists paragraphs in blockquotes and alerts in reflow.wrap
+	WrapBlockquote = "blockquote"
+	// WrapList lists paragraphs in list items and definitions in reflow.wrap
+	WrapList = "list"
+)
+
+// noReflowPattern matches the comment that keeps the line breaks of the
+// paragraphs of the block after it
+var noReflowPattern = regexp.MustCompile(`^<!--\s*mdfmt:no-reflow\s*-->\s*$`)
+
+// NoReflowFormatter marks the paragraphs that are never wrapped: every
+// paragraph with reflow.mode preserve or in a block reflow.wrap does not
+// list, otherwise those of the block following a <!-- mdfmt:no-reflow -->
+// comment and those matching a pattern of reflow.exclude
+type NoReflowFormatter struct {
+	BaseFormatter
+}
+
+// NewNoReflowFormatter creates a new no-reflow formatter
+func NewNoReflowFormatter() *NoReflowFormatter {
+	return &NoReflowFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "no-reflow",
+			priority: NoReflowFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, so markers see the block after them
+func (f *NoReflowFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes the paragraphs kept as written
+func (f *NoReflowFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Keep the line breaks of paragraphs that a no-reflow comment or the reflow options exclude from wrapping",
+		ConfigKeys:  []string{"reflow.mode", "reflow.wrap", "reflow.exclude"},
+		Autofix:     true,
+	}}
+}
+
+// Format marks the paragraphs of the document that keep their line breaks
+func (f *NoReflowFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok {
+		return nil
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(cfg.Reflow.Exclude))
+	for _, pattern := range cfg.Reflow.Exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid reflow.exclude pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	m := &noReflowMarker{
+		preserve: cfg.Reflow.Mode == ReflowModePreserve,
+		wrap:     cfg.Reflow.Wrap,
+		patterns: patterns,
+	}
+	m.blocks(doc.Children, WrapParagraph)
+	return nil
+}
+
+// noReflowMarker marks the paragraphs of a document that keep their line
+// breaks
+type noReflowMarker struct {
+	preserve bool
+	// wrap lists the blocks whose paragraphs are wrapped
+	wrap     []string
+	patterns []*regexp.Regexp
+}
+
+// blocks marks the paragraphs of nodes, which are in a block of kind block
+func (m *noReflowMarker) blocks(nodes []parser.Node, block string) {
+	markAfterNoReflow(nodes)
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *parser.Paragraph:
+			if m.keeps(n.Text, block) {
+				n.NoReflow = true
+			}
+		case *parser.Blockquote:
+			m.blocks(n.Children, WrapBlockquote)
+		case *parser.Admonition:
+			m.blocks(n.Children, WrapBlockquote)
+		case *parser.DefinitionDescription:
+			m.blocks(n.Children, WrapList)
+		case *parser.List:
+			for _, item := range n.Items {
+				if m.keeps(item.Text, WrapList) {
+					item.NoReflow = true
+				}
+				m.blocks(item.Children, WrapList)
+			}
+		case parser.BlockContainer:
+			m.blocks(n.Blocks(), block)
+		}
+	}
+}
+
+// keeps reports whether the paragraph text, in a block of kind block, keeps
+// its line breaks
+func (m *noReflowMarker) keeps(text, block string) bool {
+	if m.preserve || !slices.Contains(m.wrap, block) {
+		return true
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// markAfterNoReflow marks the paragraphs and list items of each block of
+// blocks that follows a no-reflow comment
+func markAfterNoReflow(blocks []parser.Node) {
+	for i := 1; i < len(blocks); i++ {
+		if !isNoReflowMarker(blocks[i-1]) {
+			continue
+		}
+		walker := parser.NewWalker(&parser.Document{Children: []parser.Node{blocks[i]}})
+		for n, ok := walker.Next(); ok; n, ok = walker.Next() {
+			switch n := n.(type) {
+			case *parser.Paragraph:
+				n.NoReflow = true
+			case *parser.List:
+				for _, item := range n.Items {
+					item.NoReflow = true
+				}
+			}
+		}
+	}
+}
+
+// isNoReflowMarker reports whether node is a no-reflow comment
+func isNoReflowMarker(node parser.Node) bool {
+	html, ok := node.(*parser.HTMLBlock)
+	return ok && noReflowPattern.MatchString(html.Content)
+}
@@ -0,0 +1,109 @@
+package formatter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestNoReflowFormatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		mode     string
+		exclude  []string
+		expected []bool
+	}{
+		{"no marker", "one\ntwo\n", ReflowModeWrap, nil, []bool{false}},
+		{"marker", "<!-- mdfmt:no-reflow -->\none\ntwo\n\nthree\n", ReflowModeWrap, nil, []bool{true, false}},
+		{"marker before container", "<!--mdfmt:no-reflow-->\n> one\n>\n> two\n", ReflowModeWrap, nil, []bool{true, true}},
+		{"marker in container", "> one\n>\n> <!-- mdfmt:no-reflow -->\n> two\n", ReflowModeWrap, nil, []bool{false, true}},
+		{"other comment", "<!-- note -->\none\n", ReflowModeWrap, nil, []bool{false}},
+		{"pattern", "Name: x\nAge: 3\n\nProse.\n", ReflowModeWrap, []string{`^Name:`}, []bool{true, false}},
+		{"preserve", "one\ntwo\n\n> three\n", ReflowModePreserve, nil, []bool{true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.NewGoldmarkParser().Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			cfg := config.Default()
+			cfg.Reflow.Mode = tt.mode
+			cfg.Reflow.Exclude = tt.exclude
+			if err := NewNoReflowFormatter().Format(doc, cfg); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			var got []bool
+			walker := parser.NewWalker(doc)
+			for node, ok := walker.Next(); ok; node, ok = walker.Next() {
+				if paragraph, ok := node.(*parser.Paragraph); ok {
+					got = append(got, paragraph.NoReflow)
+				}
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %d paragraphs, got %d", len(tt.expected), len(got))
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected paragraph %d NoReflow %v, got %v", i, tt.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNoReflowFormatter_Wrap(t *testing.T) {
+	content := "one\n\n> two\n\n> [!NOTE]\n> three\n\n- four\n\n  five\n\nTerm\n: six\n"
+	tests := []struct {
+		wrap     []string
+		expected []bool
+	}{
+		{[]string{WrapParagraph, WrapBlockquote, WrapList}, []bool{false, false, false, false, false, false}},
+		{[]string{WrapParagraph, WrapBlockquote}, []bool{false, false, false, true, true, true}},
+		{[]string{WrapList}, []bool{true, true, true, false, false, false}},
+		{nil, []bool{true, true, true, true, true, true}},
+	}
+
+	for _, tt := range tests {
+		doc, err := parser.NewGoldmarkParser().Parse([]byte(content))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		cfg := config.Default()
+		cfg.Reflow.Wrap = tt.wrap
+		if err := NewNoReflowFormatter().Format(doc, cfg); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+
+		var got []bool
+		walker := parser.NewWalker(doc)
+		for node, ok := walker.Next(); ok; node, ok = walker.Next() {
+			switch n := node.(type) {
+			case *parser.Paragraph:
+				got = append(got, n.NoReflow)
+			case *parser.List:
+				for _, item := range n.Items {
+					got = append(got, item.NoReflow)
+				}
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("Expected NoReflow %v with wrap %v, got %v", tt.expected, tt.wrap, got)
+		}
+	}
+}
+
+func TestParagraphFormatter_NoReflow(t *testing.T) {
+	paragraph := &parser.Paragraph{Text: "Roses are red,\nviolets   are blue.", NoReflow: true}
+	if err := NewParagraphFormatter().Format(paragraph, config.Default()); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if expected := "Roses are red,\nviolets are blue."; paragraph.Text != expected {
+		t.Errorf("Expected %q, got %q", expected, paragraph.Text)
+	}
+}
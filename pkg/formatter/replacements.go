@@ -0,0 +1,172 @@
+package formatter
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// ReplacementsFormatterPriority defines the priority for terminology
+// replacement, which runs before links are converted to references
+const ReplacementsFormatterPriority = 108
+
+// protectedProsePattern matches the parts of prose that are not words:
+// bracketed link text and labels, whose text is replaced separately, inline
+// HTML tags, bare URLs and email addresses
+var protectedProsePattern = regexp.MustCompile(
+	`\[(?:[^\[\]]|\[[^\[\]]*\])*\](?:\[[^\[\]]*\])?|<[^<>\n]*>|` +
+		`(?i:https?|ftp)://[^\s<>]*|www\.[^\s<>]*|[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+
+// ReplacementsFormatter replaces terms in prose with their configured
+// spelling, such as "github" with "GitHub". Code, URLs, HTML and front
+// matter are never changed.
+type ReplacementsFormatter struct {
+	BaseFormatter
+}
+
+// NewReplacementsFormatter creates a new terminology replacement formatter
+func NewReplacementsFormatter() *ReplacementsFormatter {
+	return &ReplacementsFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "replacements",
+			priority: ReplacementsFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, so the dictionary is compiled once
+func (f *ReplacementsFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes term replacement
+func (f *ReplacementsFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Replace terms in prose with their configured spelling",
+		ConfigKeys:  []string{"replacements"},
+		Autofix:     true,
+		Enabled:     func(cfg *config.Config) bool { return len(cfg.Replacements) > 0 },
+	}}
+}
+
+// Format applies the replacements of cfg to the prose of the document
+func (f *ReplacementsFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok || len(cfg.Replacements) == 0 {
+		return nil
+	}
+	r := newTermReplacer(cfg.Replacements, inlineSyntax(cfg))
+	forEachText(doc, r.text)
+	return nil
+}
+
+// termReplacer replaces whole words of prose, ignoring case
+type termReplacer struct {
+	pattern      *regexp.Regexp
+	replacements map[string]string
+	syntax       *parser.InlineSyntax
+}
+
+// newTermReplacer compiles a dictionary of replacements. Longer terms take
+// precedence, so "github actions" wins over "github".
+func newTermReplacer(replacements map[string]string, syntax *parser.InlineSyntax) *termReplacer {
+	terms := make([]string, 0, len(replacements))
+	for term := range replacements {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i]) != len(terms[j]) {
+			return len(terms[i]) > len(terms[j])
+		}
+		return terms[i] < terms[j]
+	})
+
+	r := &termReplacer{replacements: make(map[string]string, len(terms)), syntax: syntax}
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		key := strings.ToLower(term)
+		if _, exists := r.replacements[key]; exists {
+			continue
+		}
+		r.replacements[key] = replacements[term]
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	r.pattern = regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+	return r
+}
+
+// text replaces terms in inline markdown text: in prose and link text, but
+// not in code, link destinations, HTML, URLs or template tags
+func (r *termReplacer) text(text string) string {
+	text = parser.ReplaceLinks(text, func(link *parser.Link) string {
+		replaced := r.text(link.Text)
+		// The text of a shortcut or collapsed reference is its label, which
+		// may only change in case
+		if link.Style == parser.LinkStyleInline || link.Style == parser.LinkStyleFull ||
+			parser.NormalizeLabel(replaced) == parser.NormalizeLabel(link.Text) {
+			link.Text = replaced
+		}
+		return link.Markdown()
+	})
+	return r.syntax.MapPlain(text, func(prose string) string {
+		var sb strings.Builder
+		last := 0
+		for _, loc := range protectedProsePattern.FindAllStringIndex(prose, -1) {
+			sb.WriteString(r.words(prose[last:loc[0]]))
+			sb.WriteString(prose[loc[0]:loc[1]])
+			last = loc[1]
+		}
+		sb.WriteString(r.words(prose[last:]))
+		return sb.String()
+	})
+}
+
+// words replaces the terms of plain prose that stand as whole words
+func (r *termReplacer) words(prose string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range r.pattern.FindAllStringIndex(prose, -1) {
+		if !isWordBoundary(prose, loc[0], true) || !isWordBoundary(prose, loc[1], false) {
+			continue
+		}
+		sb.WriteString(prose[last:loc[0]])
+		sb.WriteString(r.replacements[strings.ToLower(prose[loc[0]:loc[1]])])
+		last = loc[1]
+	}
+	sb.WriteString(prose[last:])
+	return sb.String()
+}
+
+// isWordBoundary reports whether a term starting (or ending) at offset in
+// s stands on its own: it is not joined to a letter, digit or underscore,
+// nor part of a path or host name such as .github/ or github.com
+func isWordBoundary(s string, offset int, start bool) bool {
+	var neighbor rune
+	if start {
+		if offset == 0 {
+			return true
+		}
+		neighbor, _ = utf8.DecodeLastRuneInString(s[:offset])
+		if neighbor == '.' {
+			return false
+		}
+	} else {
+		if offset == len(s) {
+			return true
+		}
+		var size int
+		neighbor, size = utf8.DecodeRuneInString(s[offset:])
+		if neighbor == '.' {
+			next, _ := utf8.DecodeRuneInString(s[offset+size:])
+			return !unicode.IsLetter(next) && !unicode.IsDigit(next)
+		}
+	}
+	return !unicode.IsLetter(neighbor) && !unicode.IsDigit(neighbor) && !strings.ContainsRune("_/\\@", neighbor)
+}
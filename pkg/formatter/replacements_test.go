@@ -0,0 +1,72 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestReplacementsFormatter(t *testing.T) {
+	replacements := map[string]string{
+		"github":         "GitHub",
+		"github actions": "GitHub Actions",
+		"k8s":            "Kubernetes",
+	}
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"words", "Use github and Github Actions on K8S.", "Use GitHub and GitHub Actions on Kubernetes."},
+		{"whole words only", "githubber k8s2 my_github", "githubber k8s2 my_github"},
+		{"code", "Run `github` in github", "Run `github` in GitHub"},
+		{"inline link", "[github docs](https://github.com/docs)", "[GitHub docs](https://github.com/docs)"},
+		{"full reference", "[on k8s][k8s]", "[on Kubernetes][k8s]"},
+		{"shortcut reference", "[k8s] and [github]", "[k8s] and [GitHub]"},
+		{"urls and paths", "See https://github.com/x, github.com, .github/ and a@github.io", "See https://github.com/x, github.com, .github/ and a@github.io"},
+		{"html", `<a title="github">github</a>`, `<a title="github">GitHub</a>`},
+		{"emphasis", "**github**", "**GitHub**"},
+	}
+
+	cfg := config.Default()
+	cfg.Replacements = replacements
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paragraph := &parser.Paragraph{Text: tt.text}
+			doc := &parser.Document{Children: []parser.Node{paragraph}}
+			if err := NewReplacementsFormatter().Format(doc, cfg); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			if paragraph.Text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, paragraph.Text)
+			}
+		})
+	}
+}
+
+func TestReplacementsFormatter_SkipsCodeBlocksAndFrontMatter(t *testing.T) {
+	content := "---\nname: github\n---\n# About github\n\n```\ngithub\n```\n\n- k8s\n"
+	doc, err := parser.NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg := config.Default()
+	cfg.Replacements = map[string]string{"github": "GitHub", "k8s": "Kubernetes"}
+	if err := NewReplacementsFormatter().Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	if doc.FrontMatter != "---\nname: github\n---\n" {
+		t.Errorf("Expected front matter unchanged, got %q", doc.FrontMatter)
+	}
+	if got := doc.Children[0].(*parser.Heading).Text; got != "About GitHub" {
+		t.Errorf("Expected heading replaced, got %q", got)
+	}
+	if got := doc.Children[1].(*parser.CodeBlock).Content; got != "github\n" {
+		t.Errorf("Expected code unchanged, got %q", got)
+	}
+	if got := doc.Children[2].(*parser.List).Items[0].Text; got != "Kubernetes" {
+		t.Errorf("Expected list item replaced, got %q", got)
+	}
+}
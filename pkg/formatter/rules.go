@@ -0,0 +1,48 @@
+package formatter
+
+import "github.com/Gosayram/go-mdfmt/pkg/config"
+
+// Rule kinds
+const (
+	// RuleKindFormat is the kind of rules that rewrite documents
+	RuleKindFormat = "format"
+	// RuleKindLint is the kind of rules that report problems as diagnostics
+	RuleKindLint = "lint"
+)
+
+// Rule describes a formatting or lint rule of a formatter. The ID of a lint
+// rule is the feature of its diagnostics, which suppression comments and
+// baselines refer to.
+type Rule struct {
+	ID          string   `json:"id"`
+	Kind        string   `json:"kind"`
+	Description string   `json:"description"`
+	ConfigKeys  []string `json:"config_keys"`
+	// Autofix reports whether formatting fixes what the rule finds
+	Autofix bool `json:"autofix"`
+	// Enabled reports whether the rule takes effect with a configuration;
+	// nil means always
+	Enabled func(cfg *config.Config) bool `json:"-"`
+}
+
+// EnabledIn reports whether the rule takes effect with cfg
+func (r Rule) EnabledIn(cfg *config.Config) bool {
+	return r.Enabled == nil || r.Enabled(cfg)
+}
+
+// RuleDescriber is implemented by formatters that document their rules
+type RuleDescriber interface {
+	Rules() []Rule
+}
+
+// Rules returns the rules of formatters in the order they run, skipping
+// formatters that do not document theirs
+func Rules(formatters []NodeFormatter) []Rule {
+	var rules []Rule
+	for _, f := range formatters {
+		if d, ok := f.(RuleDescriber); ok {
+			rules = append(rules, d.Rules()...)
+		}
+	}
+	return rules
+}
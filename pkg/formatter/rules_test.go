@@ -0,0 +1,76 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+func TestRulesDocumentEveryFormatter(t *testing.T) {
+	engine := New()
+	engine.Register(NewLinkTitleFormatter(nil))
+	engine.Register(NewImagePathFormatter(nil))
+	engine.Register(NewSpellcheckFormatter(nil))
+
+	options := make(map[string]bool)
+	for _, option := range config.Options() {
+		options[option] = true
+	}
+	// replacements is a map, so it is not an option with a single value
+	options["replacements"] = true
+	ids := make(map[string]bool)
+	for _, f := range engine.Formatters() {
+		d, ok := f.(RuleDescriber)
+		if !ok {
+			t.Errorf("formatter %s does not document its rules", f.Name())
+			continue
+		}
+		for _, rule := range d.Rules() {
+			if ids[rule.ID] {
+				t.Errorf("rule %s is documented twice", rule.ID)
+			}
+			ids[rule.ID] = true
+			if rule.Kind != RuleKindFormat && rule.Kind != RuleKindLint {
+				t.Errorf("rule %s has kind %q", rule.ID, rule.Kind)
+			}
+			if rule.Description == "" {
+				t.Errorf("rule %s has no description", rule.ID)
+			}
+			for _, key := range rule.ConfigKeys {
+				if !options[key] {
+					t.Errorf("rule %s names unknown option %s", rule.ID, key)
+				}
+			}
+		}
+	}
+
+	// Suppression comments accept markdownlint IDs for these rules
+	for alias, rule := range ruleAliases {
+		if !ids[rule] {
+			t.Errorf("alias %s refers to undocumented rule %s", alias, rule)
+		}
+	}
+}
+
+func TestRulesDefaults(t *testing.T) {
+	defaults := config.Default()
+	enabled := make(map[string]bool)
+	for _, rule := range Rules(New().Formatters()) {
+		enabled[rule.ID] = rule.EnabledIn(defaults)
+	}
+
+	if !enabled["heading"] || !enabled["whitespace"] {
+		t.Errorf("expected heading and whitespace rules on by default, got %v", enabled)
+	}
+	if enabled[DuplicateHeadingFeature] || enabled[UnusedDefinitionFeature] || enabled["heading-numbering"] {
+		t.Errorf("expected opt-in rules off by default, got %v", enabled)
+	}
+
+	cfg := config.Default()
+	cfg.Links.WarnUnusedDefinitions = true
+	for _, rule := range Rules([]NodeFormatter{NewDefinitionsFormatter()}) {
+		if rule.ID == UnusedDefinitionFeature && !rule.EnabledIn(cfg) {
+			t.Error("expected unused-definition on with links.warn_unused_definitions")
+		}
+	}
+}
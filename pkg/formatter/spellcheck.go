@@ -0,0 +1,141 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/spellcheck"
+)
+
+// Constants
+const (
+	// SpellcheckFormatterPriority checks prose once replacements have
+	// settled it, before paragraphs are reflowed, so lines match the source
+	SpellcheckFormatterPriority = 103
+
+	// SpellingFeature is the feature of unknown word diagnostics
+	SpellingFeature = "spelling"
+)
+
+// nonProsePattern matches the parts of prose outside code and verbatim
+// constructs that are not words: link destinations and reference labels,
+// inline HTML tags, bare URLs and email addresses
+var nonProsePattern = regexp.MustCompile(
+	`\]\([^)\n]*\)|\]\[[^\]\n]*\]|<[^<>\n]*>|` +
+		`(?i:https?|ftp)://[^\s<>]*|www\.[^\s<>]*|[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+
+// SpellcheckFormatter reports the words of prose that a checker does not
+// know. Code, URLs, HTML, template tags and front matter are never checked.
+type SpellcheckFormatter struct {
+	BaseFormatter
+	checker spellcheck.Checker
+}
+
+// NewSpellcheckFormatter creates a spellcheck formatter reporting the words
+// checker rejects
+func NewSpellcheckFormatter(checker spellcheck.Checker) *SpellcheckFormatter {
+	return &SpellcheckFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "spellcheck",
+			priority: SpellcheckFormatterPriority,
+		},
+		checker: checker,
+	}
+}
+
+// CanFormat returns true for documents, so words are reported in order
+func (f *SpellcheckFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes the spellcheck
+func (f *SpellcheckFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          SpellingFeature,
+		Kind:        RuleKindLint,
+		Description: "Report words of prose missing from the dictionaries",
+		ConfigKeys:  []string{"spellcheck.enabled", "spellcheck.dictionaries", "spellcheck.words"},
+		Enabled:     func(cfg *config.Config) bool { return cfg.Spellcheck.Enabled },
+	}}
+}
+
+// Format reports unknown words when spellcheck.enabled is set
+func (f *SpellcheckFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok || !cfg.Spellcheck.Enabled || f.checker == nil {
+		return nil
+	}
+
+	syntax := inlineSyntax(cfg)
+	check := func(node parser.Node, text string) {
+		line, _ := doc.Line(node)
+		for _, word := range spellcheck.Words(proseMask(text, syntax)) {
+			if f.checker.Correct(word.Text) {
+				continue
+			}
+			doc.Diagnostics = append(doc.Diagnostics, parser.Diagnostic{
+				Line:    line + word.Line,
+				Feature: SpellingFeature,
+				Message: fmt.Sprintf("unknown word %q", word.Text),
+			})
+		}
+	}
+
+	var checkList func(list *parser.List)
+	checkList = func(list *parser.List) {
+		for _, item := range list.Items {
+			check(item, item.Text)
+			for _, child := range item.Children {
+				if nested, ok := child.(*parser.List); ok {
+					checkList(nested)
+				}
+			}
+		}
+	}
+	walker := parser.NewWalker(doc)
+	for n, ok := walker.Next(); ok; n, ok = walker.Next() {
+		switch n := n.(type) {
+		case *parser.Paragraph:
+			check(n, n.Text)
+		case *parser.Heading:
+			check(n, n.Text)
+		case *parser.DefinitionTerm:
+			check(n, n.Text)
+		case *parser.List:
+			checkList(n)
+		}
+	}
+	return nil
+}
+
+// proseMask returns inline markdown text with everything but its prose,
+// such as code spans, template tags, link destinations and URLs, replaced by
+// spaces. Line breaks are kept, so words stay on their lines.
+func proseMask(text string, syntax *parser.InlineSyntax) string {
+	// MapOutsideCode leaves code and verbatim constructs as they are, so
+	// the parts marked with NUL bytes line up with text
+	marked := syntax.MapOutsideCode(text, func(plain string) string {
+		return strings.Repeat("\x00", len(plain))
+	})
+	if len(marked) != len(text) {
+		return ""
+	}
+
+	mask := []byte(text)
+	for i := range mask {
+		if marked[i] != 0 && mask[i] != '\n' {
+			mask[i] = ' '
+		}
+	}
+	for _, loc := range nonProsePattern.FindAllIndex(mask, -1) {
+		for i := loc[0]; i < loc[1]; i++ {
+			if mask[i] != '\n' {
+				mask[i] = ' '
+			}
+		}
+	}
+	return string(mask)
+}
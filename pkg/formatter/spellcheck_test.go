@@ -0,0 +1,69 @@
+package formatter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/spellcheck"
+)
+
+func TestSpellcheckFormatter(t *testing.T) {
+	content := `---
+title: Skiped front matter
+---
+
+# Instalation
+
+Run ` + "`mdfmt --wrte`" + ` to format
+teh files, see [the documantation](https://example.com/docz) or
+<https://example.com/speling> and {{ .Misspeled }}.
+
+- an itme
+
+` + "```" + `
+code is nevr checked
+` + "```" + `
+`
+	doc, err := parser.NewGoldmarkParserWithOptions(parser.Options{Positions: true}).Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg := config.Default()
+	cfg.Spellcheck.Enabled = true
+	f := NewSpellcheckFormatter(spellcheck.Combine(spellcheck.Builtin(), spellcheck.NewWordlist("mdfmt")))
+	if err := f.Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	var got []string
+	for _, d := range doc.Diagnostics {
+		if d.Feature != SpellingFeature {
+			t.Errorf("unexpected feature %q", d.Feature)
+		}
+		got = append(got, d.String())
+	}
+	expected := []string{
+		`line 5: unknown word "Instalation"`,
+		`line 8: unknown word "teh"`,
+		`line 8: unknown word "documantation"`,
+		`line 11: unknown word "itme"`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("diagnostics = %q, want %q", got, expected)
+	}
+}
+
+func TestSpellcheckFormatterDisabled(t *testing.T) {
+	doc, err := parser.NewGoldmarkParser().Parse([]byte("Teh text\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if err := NewSpellcheckFormatter(spellcheck.Builtin()).Format(doc, config.Default()); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if len(doc.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics %v", doc.Diagnostics)
+	}
+}
@@ -0,0 +1,194 @@
+package formatter
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// suppressDisable turns rules off from its line on
+	suppressDisable = "disable"
+	// suppressEnable turns rules back on from its line on
+	suppressEnable = "enable"
+	// suppressDisableNextLine turns rules off for the next line with content only
+	suppressDisableNextLine = "disable-next-line"
+)
+
+var (
+	// suppressMarker is in every suppression comment
+	suppressMarker = []byte("mdfmt-")
+	// suppressCommentPattern matches suppression comments such as
+	// <!-- mdfmt-disable-next-line spelling -->
+	suppressCommentPattern = regexp.MustCompile(`<!--\s*mdfmt-(disable-next-line|disable|enable)\b([^>]*?)\s*-->`)
+	// suppressFencePattern matches the fences of code blocks, whose
+	// comments are code
+	suppressFencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// ruleAliases maps the markdownlint rule IDs of the checks mdfmt shares
+// to its own rule IDs, so existing suppression comments carry over
+var ruleAliases = map[string]string{
+	"md024": DuplicateHeadingFeature,
+	"md045": EmptyAltFeature,
+	"md053": UnusedDefinitionFeature,
+}
+
+// suppressDirective is a suppression comment
+type suppressDirective struct {
+	line int
+	// next is the line of content following the comment
+	next   int
+	action string
+	// rules are the rule IDs of the comment; empty means every rule
+	rules []string
+}
+
+// Suppressions holds the suppression comments of a document, which turn
+// lint rules off for a region of it: <!-- mdfmt-disable --> and
+// <!-- mdfmt-enable --> turn rules off and back on from their line, and
+// <!-- mdfmt-disable-next-line --> turns rules off for the following line
+// with content, so the blank line formatting puts after it does not matter.
+// Each comment may list the rule IDs it applies to, such as
+// <!-- mdfmt-disable spelling duplicate-heading -->; without IDs it applies
+// to every rule.
+type Suppressions struct {
+	directives []suppressDirective
+}
+
+// ParseSuppressions returns the suppression comments of markdown source,
+// ignoring those in fenced code blocks. It returns nil when the source has
+// none.
+func ParseSuppressions(source []byte) *Suppressions {
+	if !bytes.Contains(source, suppressMarker) {
+		return nil
+	}
+
+	s := &Suppressions{}
+	fence := ""
+	lines := strings.Split(string(source), "\n")
+	for i, line := range lines {
+		if m := suppressFencePattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(line) == m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		for _, m := range suppressCommentPattern.FindAllStringSubmatch(line, -1) {
+			s.directives = append(s.directives, suppressDirective{
+				line:   i + 1,
+				next:   nextContentLine(lines, i+1),
+				action: m[1],
+				rules:  ruleIDs(m[2]),
+			})
+		}
+	}
+	if len(s.directives) == 0 {
+		return nil
+	}
+	return s
+}
+
+// nextContentLine returns the 1-based number of the first line from the
+// 0-based index start that is not blank
+func nextContentLine(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			return i + 1
+		}
+	}
+	return len(lines) + 1
+}
+
+// ruleIDs returns the rule IDs listed in a suppression comment, in lower
+// case with markdownlint aliases resolved
+func ruleIDs(list string) []string {
+	fields := strings.FieldsFunc(list, func(r rune) bool { return r == ' ' || r == '\t' || r == ',' })
+	rules := make([]string, 0, len(fields))
+	for _, field := range fields {
+		rule := strings.ToLower(field)
+		if alias, ok := ruleAliases[rule]; ok {
+			rule = alias
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Suppressed reports whether a suppression comment turns the rule of d off
+// at its line
+func (s *Suppressions) Suppressed(d parser.Diagnostic) bool {
+	if s == nil {
+		return false
+	}
+
+	// all turns every rule off but those in except; disabled turns rules
+	// off one by one
+	all := false
+	except := make(map[string]bool)
+	disabled := make(map[string]bool)
+	for _, directive := range s.directives {
+		if directive.line > d.Line {
+			break
+		}
+		switch directive.action {
+		case suppressDisableNextLine:
+			if directive.next == d.Line && appliesTo(directive.rules, d.Feature) {
+				return true
+			}
+		case suppressDisable:
+			if len(directive.rules) == 0 {
+				all, except, disabled = true, make(map[string]bool), make(map[string]bool)
+			}
+			for _, rule := range directive.rules {
+				delete(except, rule)
+				disabled[rule] = true
+			}
+		case suppressEnable:
+			if len(directive.rules) == 0 {
+				all, except, disabled = false, make(map[string]bool), make(map[string]bool)
+			}
+			for _, rule := range directive.rules {
+				except[rule] = true
+				delete(disabled, rule)
+			}
+		}
+	}
+	return disabled[d.Feature] || (all && !except[d.Feature])
+}
+
+// Filter returns the diagnostics that are not suppressed
+func (s *Suppressions) Filter(diagnostics []parser.Diagnostic) []parser.Diagnostic {
+	if s == nil {
+		return diagnostics
+	}
+	kept := diagnostics[:0:0]
+	for _, d := range diagnostics {
+		if !s.Suppressed(d) {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// appliesTo reports whether a comment listing rules applies to rule
+func appliesTo(rules []string, rule string) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
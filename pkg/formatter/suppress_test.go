@@ -0,0 +1,71 @@
+package formatter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestSuppressions(t *testing.T) {
+	source := `# Title
+
+<!-- mdfmt-disable-next-line spelling -->
+
+Teh line
+Teh line
+
+<!-- mdfmt-disable MD024 -->
+## Install
+<!-- mdfmt-enable -->
+## Install
+
+<!-- mdfmt-disable -->
+![](a.png) teh
+<!-- mdfmt-enable empty-alt -->
+![](b.png) teh
+
+` + "```" + `
+<!-- mdfmt-disable empty-alt -->
+` + "```" + `
+![](c.png)
+`
+	var diagnostics []parser.Diagnostic
+	for _, d := range []struct {
+		line    int
+		feature string
+	}{
+		{5, SpellingFeature},
+		{6, SpellingFeature},
+		{9, DuplicateHeadingFeature},
+		{11, DuplicateHeadingFeature},
+		{14, EmptyAltFeature},
+		{14, SpellingFeature},
+		{16, EmptyAltFeature},
+		{16, SpellingFeature},
+		{21, EmptyAltFeature},
+	} {
+		diagnostics = append(diagnostics, parser.Diagnostic{Line: d.line, Feature: d.feature})
+	}
+
+	var kept []int
+	for _, d := range ParseSuppressions([]byte(source)).Filter(diagnostics) {
+		kept = append(kept, d.Line)
+	}
+	// The comment in the code block is code, so line 21 is kept
+	expected := []int{6, 11, 16, 21}
+	if !reflect.DeepEqual(kept, expected) {
+		t.Errorf("kept diagnostics on lines %v, want %v", kept, expected)
+	}
+}
+
+func TestParseSuppressionsNone(t *testing.T) {
+	if s := ParseSuppressions([]byte("# Title\n\n<!-- a comment -->\n")); s != nil {
+		t.Errorf("expected no suppressions, got %+v", s)
+	}
+	diagnostics := []parser.Diagnostic{{Line: 1, Feature: SpellingFeature}}
+	var s *Suppressions
+	if got := s.Filter(diagnostics); len(got) != 1 {
+		t.Errorf("nil suppressions filtered %v", got)
+	}
+}
@@ -0,0 +1,123 @@
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// FormatVersionFormatterPriority defines the priority for the format
+// version comment, which runs after the formatters that append blocks so
+// the comment stays last
+const FormatVersionFormatterPriority = 5
+
+// formatVersionPattern matches a format version comment
+var formatVersionPattern = regexp.MustCompile(`^<!--\s*mdfmt format_version:\s*([0-9]+)\s*-->\s*$`)
+
+// FormatVersionFormatter records the format version in documents, and
+// refuses to format documents recorded with a newer version than this
+// mdfmt supports
+type FormatVersionFormatter struct {
+	BaseFormatter
+}
+
+// NewFormatVersionFormatter creates a new format version formatter
+func NewFormatVersionFormatter() *FormatVersionFormatter {
+	return &FormatVersionFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "format-version",
+			priority: FormatVersionFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents
+func (f *FormatVersionFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Rules describes the format version comment
+func (f *FormatVersionFormatter) Rules() []Rule {
+	return []Rule{{
+		ID:          f.Name(),
+		Kind:        RuleKindFormat,
+		Description: "Record the format version in a comment at the end of documents",
+		ConfigKeys:  []string{"format_version", "format_version_comment"},
+		Autofix:     true,
+		Enabled:     func(cfg *config.Config) bool { return cfg.FormatVersionComment },
+	}}
+}
+
+// Format checks the format version comments of the document and, with
+// format_version_comment, replaces them with one for cfg.FormatVersion at
+// the end of the document
+func (f *FormatVersionFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok {
+		return nil
+	}
+
+	children := make([]parser.Node, 0, len(doc.Children)+1)
+	for _, child := range doc.Children {
+		version, ok := formatVersion(child)
+		if !ok {
+			children = append(children, child)
+			continue
+		}
+		if version > config.LatestFormatVersion {
+			return fmt.Errorf("document was formatted with format_version %d: this mdfmt supports versions 1 to %d",
+				version, config.LatestFormatVersion)
+		}
+		if !cfg.FormatVersionComment {
+			children = append(children, child)
+		}
+	}
+
+	if cfg.FormatVersionComment {
+		children = append(children, &parser.HTMLBlock{Content: FormatVersionComment(cfg.FormatVersion)})
+	}
+	doc.Children = children
+	return nil
+}
+
+// FormatVersionComment returns the comment recording format version
+func FormatVersionComment(version int) string {
+	return fmt.Sprintf("<!-- mdfmt format_version: %d -->", version)
+}
+
+// StripFormatVersionComments returns content without the lines holding
+// nothing but a format version comment, so documents can be compared
+// whether or not formatting recorded its version in them
+func StripFormatVersionComments(content []byte) []byte {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	kept := lines[:0]
+	for _, line := range lines {
+		if !formatVersionPattern.Match(bytes.TrimSpace(line)) {
+			kept = append(kept, line)
+		}
+	}
+	return bytes.Join(kept, nil)
+}
+
+// formatVersion returns the version of a format version comment
+func formatVersion(node parser.Node) (int, bool) {
+	html, ok := node.(*parser.HTMLBlock)
+	if !ok {
+		return 0, false
+	}
+	m := formatVersionPattern.FindStringSubmatch(html.Content)
+	if m == nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(m[1])
+	if err != nil {
+		// Versions too large to parse are newer than any supported
+		return math.MaxInt, true
+	}
+	return version, true
+}
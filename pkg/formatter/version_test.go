@@ -0,0 +1,74 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestFormatVersionFormatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		comment  bool
+		expected []string
+	}{
+		{"off", "# Title\n", false, []string{"Heading"}},
+		{"adds comment", "# Title\n", true, []string{"Heading", "<!-- mdfmt format_version: 1 -->"}},
+		{"moves comment to end", "<!-- mdfmt format_version: 1 -->\n\n# Title\n", true,
+			[]string{"Heading", "<!-- mdfmt format_version: 1 -->"}},
+		{"keeps comment when off", "# Title\n\n<!--mdfmt format_version:1-->\n", false,
+			[]string{"Heading", "<!--mdfmt format_version:1-->\n"}},
+		{"other comments", "<!-- note -->\n", true,
+			[]string{"<!-- note -->\n", "<!-- mdfmt format_version: 1 -->"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.NewGoldmarkParser().Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			cfg := config.Default()
+			cfg.FormatVersionComment = tt.comment
+			if err := NewFormatVersionFormatter().Format(doc, cfg); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			var got []string
+			for _, child := range doc.Children {
+				if html, ok := child.(*parser.HTMLBlock); ok {
+					got = append(got, html.Content)
+				} else {
+					got = append(got, strings.SplitN(child.String(), "(", 2)[0])
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected blocks %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatVersionFormatter_NewerVersion(t *testing.T) {
+	for _, version := range []string{"2", "99999999999999999999"} {
+		doc, err := parser.NewGoldmarkParser().Parse([]byte("# Title\n\n<!-- mdfmt format_version: " + version + " -->\n"))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		err = NewFormatVersionFormatter().Format(doc, config.Default())
+		if err == nil || !strings.Contains(err.Error(), "supports versions 1 to 1") {
+			t.Errorf("Expected an error for format_version %s, got %v", version, err)
+		}
+	}
+}
+
+func TestStripFormatVersionComments(t *testing.T) {
+	content := "# Title\n\n<!-- mdfmt format_version: 1 -->\n\nText <!-- mdfmt format_version: 1 -->\n\n  <!--mdfmt format_version: 12-->\n"
+	expected := "# Title\n\n\nText <!-- mdfmt format_version: 1 -->\n\n"
+	if got := string(StripFormatVersionComments([]byte(content))); got != expected {
+		t.Errorf("Expected:\n%q\ngot:\n%q", expected, got)
+	}
+}
@@ -0,0 +1,320 @@
+// Package incremental re-formats a document after an edit by re-parsing and
+// re-formatting only the sections the edit touches, reusing the output of
+// the rest. It serves long-lived callers such as editor integrations that
+// format the same large document after every change.
+package incremental
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+// sectionEndText is the text of the heading parsed after a section to
+// check where it ends
+const sectionEndText = "mdfmt-section-end"
+
+// sectionEnd is the source of the heading parsed after a section
+var sectionEnd = []byte("# " + sectionEndText + "\n")
+
+// definitionPattern matches link reference and footnote definitions, which
+// are shared by the whole document
+var definitionPattern = regexp.MustCompile(`(?m)^ {0,3}\[[^\]]+\]:`)
+
+// detailsPattern matches the opening tag of a <details> element
+var detailsPattern = regexp.MustCompile(`(?i)<details`)
+
+// Edit replaces a range of the source of a document
+type Edit struct {
+	// Start and End are the byte offsets of the replaced range in the
+	// source before the edit
+	Start, End int
+	// Text replaces the range
+	Text string
+}
+
+// section is a run of top-level blocks that formats the same on its own as
+// it does within the document
+type section struct {
+	// start and end are the byte offsets of the section in the source
+	start, end int
+	// output is the rendering of the section's blocks
+	output string
+	// hasBlocks is set when the section has blocks
+	hasBlocks bool
+}
+
+// Document is a formatted document that is updated by edits. It is not safe
+// for concurrent use.
+type Document struct {
+	parser   parser.Parser
+	engine   *formatter.Engine
+	renderer *renderer.MarkdownRenderer
+	cfg      *config.Config
+
+	source      []byte
+	frontMatter string
+	sections    []section
+	// reformatted counts the sections formatted by the last update
+	reformatted int
+}
+
+// New returns an empty document formatted with p, engine and cfg
+func New(p parser.Parser, engine *formatter.Engine, cfg *config.Config) *Document {
+	return &Document{parser: p, engine: engine, renderer: renderer.New(), cfg: cfg}
+}
+
+// Source returns the current source of the document
+func (d *Document) Source() []byte {
+	return d.source
+}
+
+// Format formats source from scratch, replacing the document, and returns
+// the formatted output
+func (d *Document) Format(ctx context.Context, source []byte) (string, error) {
+	source = append([]byte(nil), source...)
+	sections, _, frontMatter, err := d.formatSections(ctx, source, 0, len(source))
+	if err != nil {
+		return "", err
+	}
+
+	d.source, d.frontMatter, d.sections = source, frontMatter, sections
+	d.reformatted = len(sections)
+	return d.output()
+}
+
+// Update applies edit to the source and returns the formatted output,
+// re-formatting only the sections the edit touches. The output is the
+// same as formatting the edited source with Format.
+func (d *Document) Update(ctx context.Context, edit Edit) (string, error) {
+	if edit.Start < 0 || edit.End < edit.Start || edit.End > len(d.source) {
+		return "", fmt.Errorf("edit %d-%d is outside the document of %d bytes", edit.Start, edit.End, len(d.source))
+	}
+
+	source := make([]byte, 0, len(d.source)-(edit.End-edit.Start)+len(edit.Text))
+	source = append(source, d.source[:edit.Start]...)
+	source = append(source, edit.Text...)
+	source = append(source, d.source[edit.End:]...)
+
+	if len(d.sections) == 0 || !d.sectioned(d.source) || !d.sectioned(source) {
+		return d.Format(ctx, source)
+	}
+
+	// Sections touching the edit are formatted again, starting with the
+	// first, whose start still is a section boundary. Once past the edit, a
+	// new section ending where an old one starts resynchronizes the
+	// remaining sections, which are reused with shifted offsets.
+	first := 0
+	for first < len(d.sections)-1 && d.sections[first].end < edit.Start {
+		first++
+	}
+	if first > 0 && edit.Start <= lineEnd(d.source, d.sections[first].start) {
+		// The edit may turn the heading starting the section into text
+		first--
+	}
+	shift := len(edit.Text) - (edit.End - edit.Start)
+	next := first + 1
+	for next < len(d.sections) && d.sections[next].start < edit.End {
+		next++
+	}
+
+	frontMatter := d.frontMatter
+	var changed []section
+	start := d.sections[first].start
+	for {
+		stop := len(source)
+		if next < len(d.sections) {
+			stop = d.sections[next].start + shift
+		}
+		sections, end, sectionFrontMatter, err := d.formatSections(ctx, source, start, stop)
+		if err != nil {
+			return "", err
+		}
+		if start == 0 {
+			frontMatter = sectionFrontMatter
+		}
+		changed = append(changed, sections...)
+
+		for next < len(d.sections) && d.sections[next].start+shift < end {
+			next++
+		}
+		if end == len(source) || (next < len(d.sections) && d.sections[next].start+shift == end) {
+			break
+		}
+		start = end
+	}
+
+	sections := make([]section, 0, first+len(changed)+len(d.sections)-next)
+	sections = append(sections, d.sections[:first]...)
+	sections = append(sections, changed...)
+	for _, s := range d.sections[next:] {
+		s.start += shift
+		s.end += shift
+		sections = append(sections, s)
+	}
+
+	d.source, d.frontMatter, d.sections = source, frontMatter, sections
+	d.reformatted = len(changed)
+	return d.output()
+}
+
+// formatSections formats source from start, a section boundary, splitting
+// it into sections, until a section ends at or past stop. It returns the
+// sections, where the last one ends, and the front matter when start is 0.
+func (d *Document) formatSections(ctx context.Context, source []byte, start, stop int) (
+	sections []section, end int, frontMatter string, err error) {
+	sectioned := d.sectioned(source)
+	for {
+		end = len(source)
+		if sectioned {
+			end = nextBoundary(source, start)
+		}
+
+		var s section
+		var doc *parser.Document
+		for {
+			var ok bool
+			s, doc, ok, err = d.formatSection(ctx, source, start, end)
+			if err != nil {
+				return nil, 0, "", err
+			}
+			if ok {
+				break
+			}
+			// The section runs on past the heading that was to start the
+			// next one
+			end = nextBoundary(source, end)
+		}
+		if start == 0 {
+			frontMatter = doc.FrontMatter
+		}
+
+		sections = append(sections, s)
+		if end >= stop {
+			return sections, end, frontMatter, nil
+		}
+		start = end
+	}
+}
+
+// formatSection formats the section of source from start to end. Unless
+// the section ends the document, it is parsed followed by a heading to
+// check that the heading starting the next section is not part of one of
+// its blocks; ok is false when it is.
+func (d *Document) formatSection(ctx context.Context, source []byte, start, end int) (
+	s section, doc *parser.Document, ok bool, err error) {
+	content := source[start:end]
+	checked := end < len(source)
+	if checked {
+		content = append(append(make([]byte, 0, len(content)+len(sectionEnd)), content...), sectionEnd...)
+	}
+
+	doc, err = parser.ParseContext(ctx, d.parser, content)
+	if err != nil {
+		return section{}, nil, false, &parser.ParseError{Err: err}
+	}
+	if checked {
+		last := len(doc.Children) - 1
+		if last < 0 {
+			return section{}, nil, false, nil
+		}
+		if heading, isHeading := doc.Children[last].(*parser.Heading); !isHeading || heading.Text != sectionEndText {
+			return section{}, nil, false, nil
+		}
+		doc.Children = doc.Children[:last]
+	}
+
+	if err := d.engine.FormatContext(ctx, doc, d.cfg); err != nil {
+		return section{}, nil, false, fmt.Errorf("failed to format document: %w", err)
+	}
+	output, err := d.renderer.RenderSection(ctx, doc, d.cfg)
+	if err != nil {
+		return section{}, nil, false, fmt.Errorf("failed to render document: %w", err)
+	}
+	return section{start: start, end: end, output: output, hasBlocks: len(doc.Children) > 0}, doc, true, nil
+}
+
+// output joins the sections into the formatted document
+func (d *Document) output() (string, error) {
+	var out strings.Builder
+	blocks := renderer.NewBlockWriter(&out, d.cfg)
+	blocks.SetFinalNewline(parser.EndsWithLineBreak(d.source))
+
+	hasBlocks := false
+	for _, s := range d.sections {
+		hasBlocks = hasBlocks || s.hasBlocks
+	}
+	if d.frontMatter != "" {
+		if err := blocks.WriteFrontMatter(d.frontMatter, hasBlocks); err != nil {
+			return "", err
+		}
+	}
+	for _, s := range d.sections {
+		if _, err := blocks.Write([]byte(s.output)); err != nil {
+			return "", err
+		}
+	}
+	if err := blocks.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// sectioned reports whether source can be formatted section by section. It
+// cannot when anything in the document depends on blocks in other
+// sections: link reference definitions, <details> elements spanning
+// headings, MDX elements, or document-level formatting that looks beyond a
+// single block, such as numbering headings.
+func (d *Document) sectioned(source []byte) bool {
+	if d.cfg.Dialect == parser.DialectMDX ||
+		definitionPattern.Match(source) ||
+		detailsPattern.Match(source) {
+		return false
+	}
+
+	for _, f := range d.engine.Formatters() {
+		if !f.CanFormat(parser.NodeDocument) {
+			continue
+		}
+		switch f.(type) {
+		case *formatter.HeadingNumberingFormatter:
+			if d.cfg.Heading.Numbering.Mode == formatter.NumberingModeNumber {
+				return false
+			}
+		case *formatter.ChangelogFormatter:
+			if d.cfg.Changelog.Enabled {
+				return false
+			}
+		case *formatter.DuplicateHeadingsFormatter:
+			if d.cfg.Heading.Duplicates == formatter.DuplicateHeadingsDocument ||
+				d.cfg.Heading.Duplicates == formatter.DuplicateHeadingsSiblings {
+				return false
+			}
+		case *formatter.LinkStyleFormatter:
+			if d.cfg.Links.Style == formatter.LinkStyleReference {
+				return false
+			}
+		case *formatter.FormatVersionFormatter:
+			if d.cfg.FormatVersionComment {
+				return false
+			}
+		case *formatter.WhitespaceFormatter, *formatter.LinkTitleFormatter, *formatter.ImageFormatter,
+			*formatter.ReplacementsFormatter, *formatter.NoReflowFormatter:
+			// Each block is formatted on its own
+		case *formatter.ListFormatter:
+			// Lists following each other never span the heading that
+			// starts a section
+		case *formatter.DefinitionsFormatter:
+			// Documents with definitions are never sectioned
+		default:
+			return false
+		}
+	}
+	return true
+}
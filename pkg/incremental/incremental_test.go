@@ -0,0 +1,209 @@
+package incremental
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+// testDocument has sections separated by headings and blocks that contain
+// heading-like lines after blank lines
+const testDocument = `---
+title: Test
+
+# a YAML comment
+---
+
+# Introduction
+
+Some   text that   needs formatting.
+
+* one
+* two
+
+## Code
+
+` + "```sh" + `
+
+# not a heading
+` + "```" + `
+
+## Comment
+
+<!--
+
+# not a heading either
+
+-->
+
+## List
+
+1. first
+
+   # heading inside the item
+
+2. second
+`
+
+// formatWhole formats source in one piece
+func formatWhole(t *testing.T, source string, cfg *config.Config) string {
+	t.Helper()
+	doc, err := parser.New().Parse([]byte(source))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if err := formatter.New().Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output, err := renderer.New().Render(doc, cfg)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	return output
+}
+
+func TestFormatMatchesWholeDocument(t *testing.T) {
+	cfg := config.Default()
+	doc := New(parser.New(), formatter.New(), cfg)
+	output, err := doc.Format(context.Background(), []byte(testDocument))
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if expected := formatWhole(t, testDocument, cfg); output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+	if len(doc.sections) < 4 {
+		t.Errorf("Expected the document to be split into sections, got %d", len(doc.sections))
+	}
+}
+
+func TestUpdateMatchesWholeDocument(t *testing.T) {
+	edits := []string{"x", "\n", "\n\n", "# ", "```\n", "  ```\n", "<!--\n", "-->", "\n\n# New\n\n", "- item\n"}
+	cfg := config.Default()
+	for _, text := range edits {
+		for offset := 0; offset <= len(testDocument); offset += 7 {
+			doc := New(parser.New(), formatter.New(), cfg)
+			if _, err := doc.Format(context.Background(), []byte(testDocument)); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			// Insert text, then delete what follows it
+			for _, edit := range []Edit{
+				{Start: offset, End: offset, Text: text},
+				{Start: offset, End: min(offset+len(text)+3, len(doc.Source())+len(text))},
+			} {
+				output, err := doc.Update(context.Background(), edit)
+				if err != nil {
+					t.Fatalf("Update failed: %v", err)
+				}
+				source := string(doc.Source())
+				if expected := formatWhole(t, source, cfg); output != expected {
+					t.Fatalf("After edit %+v, source:\n%s\nexpected:\n%s\ngot:\n%s", edit, source, expected, output)
+				}
+			}
+		}
+	}
+}
+
+func TestUpdateKeepsContentAfterSections(t *testing.T) {
+	source := "# A\n\nx\n\n## B\n\n```\n\n# C\n\nc\n\n# D\n\nd\n"
+	cfg := config.Default()
+	doc := New(parser.New(), formatter.New(), cfg)
+	if _, err := doc.Format(context.Background(), []byte(source)); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output, err := doc.Update(context.Background(), Edit{Start: 5, End: 5, Text: "```\n"})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if expected := formatWhole(t, string(doc.Source()), cfg); output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestUpdateRandomEdits(t *testing.T) {
+	fragments := []string{"", "x", "\n", "\n\n", "# ", "## H\n\n", "```\n", "~~~\n", "<!--\n", "-->\n",
+		"- item\n", "1. first\n", "> quote\n", "    code\n", "---\n"}
+	base := "# A\n\nx\n\n## B\n\n```\n\n# C\n\nc\n\n# D\n\nd\n"
+	cfg := config.Default()
+	seeds := uint64(3000)
+	if testing.Short() {
+		seeds = 300
+	}
+	for seed := range seeds {
+		r := rand.New(rand.NewPCG(seed, 0))
+		doc := New(parser.New(), formatter.New(), cfg)
+		if _, err := doc.Format(context.Background(), []byte(base)); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		for range 4 {
+			size := len(doc.Source())
+			start := r.IntN(size + 1)
+			edit := Edit{Start: start, End: start + r.IntN(min(size-start, 8)+1), Text: fragments[r.IntN(len(fragments))]}
+			output, err := doc.Update(context.Background(), edit)
+			if err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+			source := string(doc.Source())
+			if expected := formatWhole(t, source, cfg); output != expected {
+				t.Fatalf("Seed %d, after edit %+v, source:\n%s\nexpected:\n%s\ngot:\n%s", seed, edit, source, expected, output)
+			}
+		}
+	}
+}
+
+func TestUpdateReformatsTouchedSection(t *testing.T) {
+	source := strings.Repeat("# Heading\n\nSome   text.\n\n", 50)
+	doc := New(parser.New(), formatter.New(), config.Default())
+	if _, err := doc.Format(context.Background(), []byte(source)); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	offset := strings.Index(source[len(source)/2:], "Some") + len(source)/2
+	output, err := doc.Update(context.Background(), Edit{Start: offset, End: offset, Text: "More "})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if doc.reformatted != 1 {
+		t.Errorf("Expected 1 section to be formatted again, got %d", doc.reformatted)
+	}
+	if !strings.Contains(output, "More Some text.") {
+		t.Errorf("Expected the edit in the output, got:\n%s", output)
+	}
+}
+
+func TestUpdateWithDefinitionsFormatsWholeDocument(t *testing.T) {
+	source := "# One\n\nSee [docs][d].\n\n# Two\n\n[d]: https://example.com\n"
+	cfg := config.Default()
+	doc := New(parser.New(), formatter.New(), cfg)
+	if _, err := doc.Format(context.Background(), []byte(source)); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if len(doc.sections) != 1 {
+		t.Errorf("Expected a single section, got %d", len(doc.sections))
+	}
+
+	output, err := doc.Update(context.Background(), Edit{Start: 2, End: 5, Text: "First"})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if expected := formatWhole(t, string(doc.Source()), cfg); output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestUpdateOutOfRange(t *testing.T) {
+	doc := New(parser.New(), formatter.New(), config.Default())
+	if _, err := doc.Format(context.Background(), []byte("# Title\n")); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if _, err := doc.Update(context.Background(), Edit{Start: 5, End: 50}); err == nil {
+		t.Error("Expected an error for an edit past the end of the document")
+	}
+}
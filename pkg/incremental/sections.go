@@ -0,0 +1,127 @@
+package incremental
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// maxBlockIndent is the indentation up to which a line can open a block
+const maxBlockIndent = 3
+
+var (
+	// headingPattern matches an ATX heading line
+	headingPattern = regexp.MustCompile(`^#{1,6}(?:[ \t]|$)`)
+	// fencePattern matches the opening line of a fenced code or math block
+	fencePattern = regexp.MustCompile("^( *)(`{3,}|~{3,}|\\${2,})")
+)
+
+// htmlBlock is a kind of HTML block that may contain blank lines, from its
+// opening text to the text that closes it
+type htmlBlock struct {
+	open, close string
+}
+
+// htmlBlocks lists the HTML blocks that end at a closing text rather than at
+// a blank line
+var htmlBlocks = []htmlBlock{
+	{"<!--", "-->"},
+	{"<![cdata[", "]]>"},
+	{"<?", "?>"},
+	{"<script", "</script>"},
+	{"<pre", "</pre>"},
+	{"<style", "</style>"},
+	{"<textarea", "</textarea>"},
+}
+
+// nextBoundary returns the offset of the first section boundary in source
+// after from, itself a boundary, or len(source) if there is none. A section
+// starts at an unindented ATX heading that follows a blank line outside any
+// fenced block or multi-line HTML block: nothing before such a heading can
+// continue past it. The scan errs on the side of finding fewer boundaries.
+func nextBoundary(source []byte, from int) int {
+	pos := from
+	if from == 0 {
+		frontMatter, _ := parser.SplitFrontMatter(source)
+		pos = len(frontMatter)
+	}
+
+	var fence []byte
+	fenceIndent := 0
+	var closeHTML []byte
+	blank := false
+	for pos < len(source) {
+		end := lineEnd(source, pos)
+		line := source[pos:end]
+		next := end + 1
+		if next > len(source) {
+			next = len(source)
+		}
+
+		switch {
+		case fence != nil:
+			if indent, marker, ok := fenceLine(line); ok && indent <= fenceIndent+maxBlockIndent &&
+				marker[0] == fence[0] && len(marker) >= len(fence) &&
+				len(bytes.TrimSpace(line[indent+len(marker):])) == 0 {
+				fence = nil
+			}
+		case closeHTML != nil:
+			if bytes.Contains(bytes.ToLower(line), closeHTML) {
+				closeHTML = nil
+			}
+		case blank && pos > from && headingPattern.Match(line):
+			return pos
+		default:
+			if indent, marker, ok := fenceLine(line); ok {
+				fence, fenceIndent = marker, indent
+				if marker[0] == '$' && bytes.Contains(line[indent+len(marker):], marker) {
+					// $$...$$ on a single line
+					fence = nil
+				}
+			} else {
+				closeHTML = openHTML(line)
+			}
+		}
+
+		blank = len(bytes.TrimSpace(line)) == 0
+		pos = next
+	}
+	return len(source)
+}
+
+// fenceLine returns the indentation and marker of a line opening or closing
+// a fenced block
+func fenceLine(line []byte) (int, []byte, bool) {
+	m := fencePattern.FindSubmatchIndex(line)
+	if m == nil {
+		return 0, nil, false
+	}
+	return m[3] - m[2], line[m[4]:m[5]], true
+}
+
+// openHTML returns the text closing a multi-line HTML block opened on line
+// and not closed on it, or nil
+func openHTML(line []byte) []byte {
+	lower := bytes.ToLower(line)
+	for _, block := range htmlBlocks {
+		i := bytes.Index(lower, []byte(block.open))
+		if i < 0 {
+			continue
+		}
+		if bytes.Contains(lower[i+len(block.open):], []byte(block.close)) {
+			continue
+		}
+		return []byte(block.close)
+	}
+	return nil
+}
+
+// lineEnd returns the offset of the newline ending the line at pos, or
+// len(source) for the last line
+func lineEnd(source []byte, pos int) int {
+	if i := bytes.IndexByte(source[pos:], '\n'); i >= 0 {
+		return pos + i
+	}
+	return len(source)
+}
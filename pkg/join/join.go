@@ -0,0 +1,263 @@
+// Package join merges markdown documents into a single document.
+package join
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// maxLevel is the deepest heading level
+	maxLevel = 6
+	// yamlIndent is the indentation of merged YAML front matter
+	yamlIndent = 2
+)
+
+// Options configures how documents are joined
+type Options struct {
+	// ShiftHeadings demotes the headings of every document after the first,
+	// so its top-level headings sit one level below those of the first
+	ShiftHeadings bool
+}
+
+// Join merges docs into one document, in order. The joined document has a
+// single front matter block: the keys of YAML front matter are merged, the
+// first document defining a key providing its value, and front matter that
+// cannot be merged, such as TOML, is kept only from the first document that
+// has any. A link reference definition whose label an earlier document
+// defines with another destination is renamed with a numeric suffix, along
+// with the references to it. The blocks of docs are moved, not copied, into
+// the result.
+func Join(docs []*parser.Document, opts Options) *parser.Document {
+	joined := &parser.Document{}
+	frontMatters := make([]string, 0, len(docs))
+	// targets holds the destination and title of each label defined so far
+	targets := make(map[string]string)
+	base := 0
+	for i, doc := range docs {
+		renameDefinitions(doc, targets)
+		if doc.FrontMatter != "" {
+			frontMatters = append(frontMatters, doc.FrontMatter)
+		}
+		top := topLevel(doc.Children)
+		if i == 0 {
+			base = top
+		} else if opts.ShiftHeadings && top > 0 && base > 0 {
+			if shift := base + 1 - top; shift > 0 {
+				shiftHeadings(doc.Children, shift)
+			}
+		}
+		joined.Children = append(joined.Children, doc.Children...)
+	}
+	joined.FrontMatter = mergeFrontMatter(frontMatters)
+	return joined
+}
+
+// topLevel returns the level of the highest-ranking heading among nodes, or
+// 0 if there is none
+func topLevel(nodes []parser.Node) int {
+	top := 0
+	eachHeading(nodes, func(heading *parser.Heading) {
+		if top == 0 || heading.Level < top {
+			top = heading.Level
+		}
+	})
+	return top
+}
+
+// shiftHeadings demotes the headings among nodes by shift levels, stopping
+// at the deepest level
+func shiftHeadings(nodes []parser.Node, shift int) {
+	eachHeading(nodes, func(heading *parser.Heading) {
+		heading.Level = min(heading.Level+shift, maxLevel)
+	})
+}
+
+// eachHeading calls fn for the headings among nodes and the blocks they
+// contain, including those nested in list items
+func eachHeading(nodes []parser.Node, fn func(*parser.Heading)) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *parser.Heading:
+			fn(n)
+		case *parser.List:
+			for _, item := range n.Items {
+				eachHeading(item.Children, fn)
+			}
+		case parser.BlockContainer:
+			eachHeading(n.Blocks(), fn)
+		}
+	}
+}
+
+// renameDefinitions renames the link reference definitions of doc whose
+// label targets holds with another destination or title, and the references
+// to them, then adds the labels of doc to targets
+func renameDefinitions(doc *parser.Document, targets map[string]string) {
+	definitions := eachDefinition(doc.Children)
+	taken := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		taken[parser.NormalizeLabel(definition.Label)] = true
+	}
+
+	used := func(label string) bool {
+		_, defined := targets[parser.NormalizeLabel(label)]
+		return defined || taken[parser.NormalizeLabel(label)]
+	}
+	renamed := make(map[string]string)
+	for _, definition := range definitions {
+		label := parser.NormalizeLabel(definition.Label)
+		target, defined := targets[label]
+		if !defined || target == definition.Destination+"\x00"+definition.Title {
+			continue
+		}
+		if name, ok := renamed[label]; ok {
+			definition.Label = name
+			continue
+		}
+		name := definition.Label
+		for n := 2; used(name); n++ {
+			name = definition.Label + "-" + strconv.Itoa(n)
+		}
+		taken[parser.NormalizeLabel(name)] = true
+		renamed[label] = name
+		definition.Label = name
+	}
+
+	for _, definition := range definitions {
+		label := parser.NormalizeLabel(definition.Label)
+		if _, defined := targets[label]; !defined {
+			targets[label] = definition.Destination + "\x00" + definition.Title
+		}
+	}
+	if len(renamed) == 0 {
+		return
+	}
+
+	var rename func(string) string
+	rename = func(text string) string {
+		return parser.ReplaceLinks(text, func(link *parser.Link) string {
+			link.Text = rename(link.Text)
+			if link.Style != parser.LinkStyleInline {
+				if label, ok := renamed[parser.NormalizeLabel(link.ReferenceLabel())]; ok {
+					link.Style, link.Label = parser.LinkStyleFull, label
+				}
+			}
+			return link.Markdown()
+		})
+	}
+	eachText(doc.Children, rename)
+}
+
+// eachDefinition returns the link reference definitions among nodes and the
+// blocks they contain
+func eachDefinition(nodes []parser.Node) []*parser.LinkDefinition {
+	var definitions []*parser.LinkDefinition
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *parser.LinkDefinitions:
+			definitions = append(definitions, n.Definitions...)
+		case *parser.List:
+			for _, item := range n.Items {
+				definitions = append(definitions, eachDefinition(item.Children)...)
+			}
+		case parser.BlockContainer:
+			definitions = append(definitions, eachDefinition(n.Blocks())...)
+		}
+	}
+	return definitions
+}
+
+// eachText rewrites the inline text of nodes and the blocks they contain
+// with fn
+func eachText(nodes []parser.Node, fn func(string) string) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *parser.Paragraph:
+			n.Text = fn(n.Text)
+		case *parser.Heading:
+			n.Text = fn(n.Text)
+		case *parser.DefinitionTerm:
+			n.Text = fn(n.Text)
+		case *parser.Table:
+			for _, row := range n.Rows {
+				for i, cell := range row {
+					row[i] = fn(cell)
+				}
+			}
+		case *parser.List:
+			for _, item := range n.Items {
+				item.Text = fn(item.Text)
+				eachText(item.Children, fn)
+			}
+		case parser.BlockContainer:
+			eachText(n.Blocks(), fn)
+		}
+	}
+}
+
+// mergeFrontMatter returns a single front matter block for frontMatters,
+// merging the keys of YAML blocks. The first block is returned unchanged
+// when all blocks are identical or some cannot be merged.
+func mergeFrontMatter(frontMatters []string) string {
+	if len(frontMatters) == 0 {
+		return ""
+	}
+	first := frontMatters[0]
+	var merged *yaml.Node
+	changed := false
+	for _, frontMatter := range frontMatters {
+		data, ok := parser.FrontMatterData([]byte(frontMatter))
+		if !ok {
+			return first
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return first
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		mapping := doc.Content[0]
+		if mapping.Kind != yaml.MappingNode {
+			return first
+		}
+		if merged == nil {
+			merged = mapping
+			continue
+		}
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if !hasKey(merged, mapping.Content[i].Value) {
+				merged.Content = append(merged.Content, mapping.Content[i], mapping.Content[i+1])
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return first
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent)
+	if err := enc.Encode(merged); err != nil {
+		return first
+	}
+	return parser.FrontMatterYAML + "\n" + strings.TrimRight(buf.String(), "\n") + "\n" + parser.FrontMatterYAML + "\n"
+}
+
+// hasKey reports whether the YAML mapping has key
+func hasKey(mapping *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package join
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func parse(t *testing.T, content string) *parser.Document {
+	t.Helper()
+	doc, err := parser.NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return doc
+}
+
+func headingLevels(doc *parser.Document) []int {
+	var levels []int
+	eachHeading(doc.Children, func(heading *parser.Heading) {
+		levels = append(levels, heading.Level)
+	})
+	return levels
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		docs     []string
+		expected []int
+	}{
+		{
+			name:     "levels kept",
+			docs:     []string{"# A\n\n## A1\n", "# B\n\n## B1\n"},
+			expected: []int{1, 2, 1, 2},
+		},
+		{
+			name:     "shifted below the first document",
+			opts:     Options{ShiftHeadings: true},
+			docs:     []string{"# A\n\n## A1\n", "# B\n\n> ## B1\n", "## C\n\n###### C1\n"},
+			expected: []int{1, 2, 2, 3, 2, 6},
+		},
+		{
+			name:     "already deeper",
+			opts:     Options{ShiftHeadings: true},
+			docs:     []string{"# A\n", "### B\n"},
+			expected: []int{1, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := make([]*parser.Document, 0, len(tt.docs))
+			for _, content := range tt.docs {
+				docs = append(docs, parse(t, content))
+			}
+			got := headingLevels(Join(docs, tt.opts))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected levels %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("Expected levels %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestJoin_FrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		docs     []string
+		expected string
+	}{
+		{
+			name:     "none",
+			docs:     []string{"# A\n", "# B\n"},
+			expected: "",
+		},
+		{
+			name:     "duplicate",
+			docs:     []string{"---\ntitle:  Guide\n---\n# A\n", "---\ntitle: Other\n---\n# B\n"},
+			expected: "---\ntitle:  Guide\n---\n",
+		},
+		{
+			name:     "merged keys",
+			docs:     []string{"# A\n", "---\ntitle: Guide\n---\n# B\n", "---\ntitle: C\ntags: [a, b]\n---\n# C\n"},
+			expected: "---\ntitle: Guide\ntags: [a, b]\n---\n",
+		},
+		{
+			name:     "toml",
+			docs:     []string{"+++\ntitle = \"Guide\"\n+++\n# A\n", "---\ntags: [a]\n---\n# B\n"},
+			expected: "+++\ntitle = \"Guide\"\n+++\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := make([]*parser.Document, 0, len(tt.docs))
+			for _, content := range tt.docs {
+				docs = append(docs, parse(t, content))
+			}
+			if got := Join(docs, Options{}).FrontMatter; got != tt.expected {
+				t.Errorf("Expected front matter %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestJoin_ReferenceLabels(t *testing.T) {
+	docs := []*parser.Document{
+		parse(t, "See [x][ref] and [ref-2].\n\n[ref]: https://a.example\n[ref-2]: https://c.example\n"),
+		parse(t, "See [y][ref], [Ref] and [same].\n\n[REF]: https://b.example\n[same]: https://c.example\n"),
+		parse(t, "Same [same] and [ref].\n\n[same]: https://c.example\n[ref]: https://a.example\n"),
+	}
+	joined := Join(docs, Options{})
+
+	var texts, labels []string
+	for _, node := range joined.Children {
+		switch n := node.(type) {
+		case *parser.Paragraph:
+			texts = append(texts, n.Text)
+		case *parser.LinkDefinitions:
+			for _, definition := range n.Definitions {
+				labels = append(labels, definition.Label)
+			}
+		}
+	}
+	expectedTexts := []string{
+		"See [x][ref] and [ref-2].",
+		"See [y][REF-3], [Ref][REF-3] and [same].",
+		"Same [same] and [ref].",
+	}
+	expectedLabels := []string{"ref", "ref-2", "REF-3", "same", "same", "ref"}
+	if strings.Join(texts, "|") != strings.Join(expectedTexts, "|") {
+		t.Errorf("Expected texts %q, got %q", expectedTexts, texts)
+	}
+	if strings.Join(labels, "|") != strings.Join(expectedLabels, "|") {
+		t.Errorf("Expected labels %q, got %q", expectedLabels, labels)
+	}
+}
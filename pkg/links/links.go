@@ -0,0 +1,119 @@
+// Package links lists the links and images of documents with their source
+// lines, for external link checkers and cross-reference audits.
+package links
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// schemePattern matches the scheme of an absolute URL, such as "https:"
+var schemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+
+// Link is a link or image of a document
+type Link struct {
+	// Line is the 1-based source line of the link, or 0 when the document
+	// was parsed without parser.Options.Positions
+	Line int `json:"line"`
+	// URL is the destination, resolved through reference definitions
+	URL string `json:"url"`
+	// Text is the link text or image alt text, as markdown
+	Text  string `json:"text"`
+	Image bool   `json:"image"`
+}
+
+// External reports whether the link points to another site: its URL has a
+// scheme, such as https: or mailto:, or is protocol-relative
+func (l Link) External() bool {
+	return schemePattern.MatchString(l.URL) || strings.HasPrefix(l.URL, "//")
+}
+
+// extractor collects the links of a document
+type extractor struct {
+	doc         *parser.Document
+	definitions map[string]string
+	links       []Link
+}
+
+// Extract returns the links, images and autolinks of doc in document order.
+// Reference links are reported with the destination of their definition;
+// references without one are not links and are left out.
+func Extract(doc *parser.Document) []Link {
+	e := &extractor{doc: doc, definitions: make(map[string]string)}
+	walker := parser.NewWalker(doc)
+	for node, ok := walker.Next(); ok; node, ok = walker.Next() {
+		if group, ok := node.(*parser.LinkDefinitions); ok {
+			for _, d := range group.Definitions {
+				label := parser.NormalizeLabel(d.Label)
+				if _, seen := e.definitions[label]; !seen {
+					e.definitions[label] = d.Destination
+				}
+			}
+		}
+	}
+
+	walker = parser.NewWalker(doc)
+	for node, ok := walker.Next(); ok; node, ok = walker.Next() {
+		e.node(node)
+	}
+	return e.links
+}
+
+// node collects the links of the text of node
+func (e *extractor) node(node parser.Node) {
+	switch n := node.(type) {
+	case *parser.Heading:
+		e.text(node, n.Text)
+	case *parser.Paragraph:
+		e.text(node, n.Text)
+	case *parser.DefinitionTerm:
+		e.text(node, n.Text)
+	case *parser.Text:
+		e.text(node, n.Content)
+	case *parser.List:
+		for _, item := range n.Items {
+			e.text(item, item.Text)
+			for _, child := range item.Children {
+				e.node(child)
+			}
+		}
+	}
+}
+
+// text collects the links of the inline markdown text of node
+func (e *extractor) text(node parser.Node, text string) {
+	line, _ := e.doc.Line(node)
+	e.inline(text, func(offset int) int {
+		if line == 0 {
+			return 0
+		}
+		return line + strings.Count(text[:offset], "\n")
+	}, 0)
+}
+
+// inline collects the links of text found at base in the text of a block,
+// whose lines lineAt returns by offset
+func (e *extractor) inline(text string, lineAt func(offset int) int, base int) {
+	parser.EachLink(text, func(link *parser.Link, offset int) {
+		url := link.Destination
+		if link.Style != parser.LinkStyleInline {
+			destination, ok := e.definitions[parser.NormalizeLabel(link.ReferenceLabel())]
+			if !ok {
+				// [text] without a definition is plain bracketed text, which
+				// may still contain links
+				e.inline(link.Text, lineAt, base+offset+1)
+				return
+			}
+			url = destination
+		}
+
+		e.links = append(e.links, Link{Line: lineAt(base + offset), URL: url, Text: link.Text, Image: link.Image})
+		textOffset := 1
+		if link.Image {
+			textOffset = 2
+		}
+		e.inline(link.Text, lineAt, base+offset+textOffset)
+	})
+}
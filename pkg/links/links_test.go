@@ -0,0 +1,51 @@
+package links
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestExtract(t *testing.T) {
+	source := "# Links [home](https://example.com)\n\n" +
+		"See [a guide](docs/guide.md) and\n![logo](img/logo.png \"Logo\").\n\n" +
+		"- item [ref][r] and <https://auto.example>\n  - nested [anchor](#top) and `[code](x)`\n\n" +
+		"> quoted <me@example.com> [missing]\n\n" +
+		"[![badge](https://img.shields.io/x.svg)](https://ci.example.com)\n\n" +
+		"[r]: https://ref.example\n"
+	doc, err := parser.NewWithOptions(parser.Options{Positions: true}).Parse([]byte(source))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []Link{
+		{Line: 1, URL: "https://example.com", Text: "home"},
+		{Line: 3, URL: "docs/guide.md", Text: "a guide"},
+		{Line: 4, URL: "img/logo.png", Text: "logo", Image: true},
+		{Line: 6, URL: "https://ref.example", Text: "ref"},
+		{Line: 6, URL: "https://auto.example", Text: "https://auto.example"},
+		{Line: 7, URL: "#top", Text: "anchor"},
+		{Line: 9, URL: "mailto:me@example.com", Text: "me@example.com"},
+		{Line: 11, URL: "https://ci.example.com", Text: "![badge](https://img.shields.io/x.svg)"},
+		{Line: 11, URL: "https://img.shields.io/x.svg", Text: "badge", Image: true},
+	}
+	if got := Extract(doc); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected:\n%+v\ngot:\n%+v", expected, got)
+	}
+}
+
+func TestLinkExternal(t *testing.T) {
+	for url, expected := range map[string]bool{
+		"https://example.com":   true,
+		"mailto:me@example.com": true,
+		"//cdn.example.com/a":   true,
+		"docs/guide.md":         false,
+		"../README.md#usage":    false,
+		"#top":                  false,
+	} {
+		if got := (Link{URL: url}).External(); got != expected {
+			t.Errorf("External(%q) = %v, expected %v", url, got, expected)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package mdfmt formats markdown with a single call, for programs using
+// go-mdfmt as a library. It runs the parse, format and render pipeline of
+// the mdfmt command.
+package mdfmt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+)
+
+// Constants
+const (
+	// maxSnippetLength is the length of the input quoted by a PanicError
+	maxSnippetLength = 200
+)
+
+// PanicError is a panic recovered while formatting. Snippet is the first
+// top-level block of the input that panics on its own, or the start of the
+// input if none does.
+type PanicError struct {
+	Snippet string
+	// Value is the recovered panic value and Stack the stack trace of the
+	// panicking goroutine
+	Value interface{}
+	Stack []byte
+}
+
+// Error describes the panic and the input that caused it
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic while formatting %q: %v", e.Snippet, e.Value)
+}
+
+// Format formats markdown content with cfg, or the default configuration
+// if cfg is nil, applying the options in the front matter of content.
+func Format(content []byte, cfg *config.Config) ([]byte, error) {
+	return FormatContext(context.Background(), content, cfg)
+}
+
+// FormatContext formats content like Format, returning ctx.Err() if ctx is
+// done first. It never panics: a panic while converting a block is returned
+// as a *parser.ParseError wrapping a *parser.ConversionError, and one while
+// formatting or rendering as a *PanicError.
+func FormatContext(ctx context.Context, content []byte, cfg *config.Config) ([]byte, error) {
+	if cfg == nil {
+		cfg = config.Default()
+	}
+	return recovering(ctx, content, cfg, format)
+}
+
+// formatFunc formats markdown content with a configuration
+type formatFunc func(ctx context.Context, content []byte, cfg *config.Config) ([]byte, error)
+
+// recovering formats content with format, returning a panic as a *PanicError
+func recovering(ctx context.Context, content []byte, cfg *config.Config, format formatFunc) (formatted []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			err = &PanicError{Snippet: crashSnippet(content, cfg, format), Value: r, Stack: stack}
+		}
+	}()
+	return format(ctx, content, cfg)
+}
+
+// FormatFuzz is the entry point for go-fuzz style fuzzers such as OSS-Fuzz.
+// It formats data with the default configuration without recovering
+// panics, raises the panics the parser recovers from again, and panics if
+// the output does not format again to itself. It returns 1 for data that
+// formats, which fuzzers should favor, and 0 otherwise.
+func FormatFuzz(data []byte) int {
+	cfg := config.Default()
+	formatted, err := format(context.Background(), data, cfg)
+	var conversionErr *parser.ConversionError
+	if errors.As(err, &conversionErr) {
+		panic(conversionErr)
+	}
+	if err != nil {
+		return 0
+	}
+	again, err := format(context.Background(), formatted, cfg)
+	if err != nil {
+		panic(fmt.Sprintf("formatted output does not format again: %v", err))
+	}
+	if !bytes.Equal(again, formatted) {
+		panic(fmt.Sprintf("formatting is not idempotent: %q formats to %q", formatted, again))
+	}
+	return 1
+}
+
+// pipeline formats the documents of Format and FormatContext
+var pipeline = NewPipeline()
+
+// format formats content with cfg without recovering panics
+func format(ctx context.Context, content []byte, cfg *config.Config) ([]byte, error) {
+	if data, ok := parser.FrontMatterData(content); ok {
+		var err error
+		if cfg, err = cfg.WithFrontMatter(data); err != nil {
+			return nil, err
+		}
+	}
+
+	_, formatted, err := pipeline.Format(ctx, content, cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(formatted), nil
+}
+
+// crashSnippet returns the first top-level block of content that panics
+// on its own, cut to maxSnippetLength
+func crashSnippet(content []byte, cfg *config.Config, format formatFunc) string {
+	snippet := processor.MinimizeCrash(content, func(block []byte) {
+		_, _ = format(context.Background(), block, cfg)
+	})
+	if len(snippet) > maxSnippetLength {
+		snippet = snippet[:maxSnippetLength]
+	}
+	return string(snippet)
+}
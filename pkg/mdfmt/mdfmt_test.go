@@ -0,0 +1,63 @@
+package mdfmt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+func TestFormat(t *testing.T) {
+	content := []byte("---\nmdfmt:\n  list:\n    bullet_style: '*'\n---\n\nSetext\n======\n\n- one\n- two\n")
+	formatted, err := Format(content, nil)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	expected := "---\nmdfmt:\n  list:\n    bullet_style: '*'\n---\n\n# Setext\n\n* one\n* two"
+	if string(bytes.TrimRight(formatted, "\n")) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, formatted)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FormatContext(ctx, content, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFormatRecoversPanics(t *testing.T) {
+	panicking := func(ctx context.Context, content []byte, cfg *config.Config) ([]byte, error) {
+		if bytes.Contains(content, []byte("boom")) {
+			panic("formatter bug")
+		}
+		return format(ctx, content, cfg)
+	}
+
+	content := []byte("# Title\n\nSafe text.\n\nThis goes boom.\n\nMore text.\n")
+	_, err := recovering(context.Background(), content, config.Default(), panicking)
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected a *PanicError, got %v", err)
+	}
+	if panicErr.Snippet != "This goes boom.\n" || panicErr.Value != "formatter bug" || len(panicErr.Stack) == 0 {
+		t.Errorf("Expected the panicking block, got %q: %v", panicErr.Snippet, panicErr.Value)
+	}
+}
+
+func FuzzFormat(f *testing.F) {
+	for _, seed := range []string{
+		"# Title\n\nSome *text* with `code` and a [link](https://example.com).\n",
+		"- one\n  - nested\n\n1. first\n2. second\n",
+		"> quote\n>\n> - item\n\n```go\ncode\n```\n",
+		"Setext\n===\n\n| a | b |\n|---|---|\n| 1 | 2 |\n",
+		"---\ntitle: x\n---\n\n[ref]: https://example.com\n",
+		"Text with \\# escapes and <span>html</span>\n\n<div>\nblock\n</div>\n",
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		FormatFuzz(data)
+	})
+}
@@ -0,0 +1,126 @@
+package mdfmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/formatter"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/processor"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+// ConcurrentFormatThreshold is the size in bytes from which a document's
+// blocks are formatted and rendered on all cores
+const ConcurrentFormatThreshold = 1 << 20
+
+// Pipeline parses, formats and renders markdown with the parsers and
+// formatting engine it shares between documents, so they are built once
+// rather than for every document. It is safe for concurrent use.
+type Pipeline struct {
+	mu sync.Mutex
+	// parsers holds a parser per dialect and set of extensions
+	parsers map[string]parser.Parser
+	engine  *formatter.Engine
+}
+
+// NewPipeline returns a pipeline with the default formatters
+func NewPipeline() *Pipeline {
+	return &Pipeline{
+		parsers: make(map[string]parser.Parser),
+		engine:  formatter.New(),
+	}
+}
+
+// Parser returns the parser for the dialect and extensions of cfg, which
+// records source lines when cfg enables checks reporting them
+func (p *Pipeline) Parser(cfg *config.Config) parser.Parser {
+	positions := cfg.Images.WarnEmptyAlt || cfg.Images.CheckPaths || cfg.Changelog.Enabled || cfg.Spellcheck.Enabled ||
+		(cfg.Heading.Duplicates != "" && cfg.Heading.Duplicates != formatter.DuplicateHeadingsOff)
+	key := fmt.Sprintf("%s\x00%s\x00%t", cfg.Dialect, strings.Join(cfg.Extensions, ","), positions)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ps, ok := p.parsers[key]; ok {
+		return ps
+	}
+	ps := parser.NewWithOptions(parser.Options{
+		Dialect:    cfg.Dialect,
+		Extensions: cfg.Extensions,
+		Positions:  positions,
+	})
+	p.parsers[key] = ps
+	return ps
+}
+
+// engineWith returns the shared engine, or a new engine with extra
+// formatters alongside the defaults
+func (p *Pipeline) engineWith(extra ...formatter.NodeFormatter) *formatter.Engine {
+	if len(extra) == 0 {
+		return p.engine
+	}
+	engine := &formatter.Engine{}
+	for _, f := range p.engine.Formatters() {
+		engine.Register(f)
+	}
+	for _, f := range extra {
+		engine.Register(f)
+	}
+	return engine
+}
+
+// Format processes markdown content through parse -> format -> render,
+// returning the formatted document and its rendering. cfg is used as is:
+// the options in the front matter of content are not applied. extra
+// formatters run alongside the defaults; each phase is measured in timings,
+// which may be nil. Parse errors are returned as a *parser.ParseError.
+func (p *Pipeline) Format(ctx context.Context, content []byte, cfg *config.Config, timings *processor.Timings,
+	extra ...formatter.NodeFormatter) (*parser.Document, string, error) {
+	stopParse := timings.Start(processor.PhaseParse)
+	doc, err := parser.ParseContext(ctx, p.Parser(cfg), content)
+	stopParse()
+	if err != nil {
+		var parseErr *parser.ParseError
+		if !errors.As(err, &parseErr) {
+			err = &parser.ParseError{Err: err}
+		}
+		return nil, "", err
+	}
+
+	// Large documents are formatted and rendered a run of blocks per core
+	workers := runtime.GOMAXPROCS(0)
+	concurrent := len(content) >= ConcurrentFormatThreshold && workers > 1
+
+	stopFormat := timings.Start(processor.PhaseFormat)
+	var formatErr error
+	if concurrent {
+		formatErr = p.engineWith(extra...).FormatConcurrent(ctx, doc, cfg, workers)
+	} else {
+		formatErr = p.engineWith(extra...).FormatContext(ctx, doc, cfg)
+	}
+	stopFormat()
+	if formatErr != nil {
+		return nil, "", fmt.Errorf("failed to format document: %w", formatErr)
+	}
+	doc.Diagnostics = formatter.ParseSuppressions(content).Filter(doc.Diagnostics)
+
+	stopRender := timings.Start(processor.PhaseRender)
+	var formatted string
+	if concurrent {
+		var out strings.Builder
+		err = renderer.RenderConcurrent(ctx, &out, doc, cfg, workers)
+		formatted = out.String()
+	} else {
+		formatted, err = renderer.New().RenderContext(ctx, doc, cfg)
+	}
+	stopRender()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to render document: %w", err)
+	}
+	return doc, formatted, nil
+}
@@ -0,0 +1,42 @@
+package mdfmt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestPipeline(t *testing.T) {
+	p := NewPipeline()
+	cfg := config.Default()
+	if p.Parser(cfg) != p.Parser(config.Default()) {
+		t.Error("Expected the parser to be shared between equal configurations")
+	}
+	commonMark := config.Default()
+	commonMark.Dialect = parser.DialectCommonMark
+	if p.Parser(cfg) == p.Parser(commonMark) {
+		t.Error("Expected a parser per dialect")
+	}
+
+	content := []byte("Setext\n======\n\n* one\n* two\n")
+	doc, formatted, err := p.Format(context.Background(), content, cfg, nil)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if expected := "# Setext\n\n- one\n- two\n"; formatted != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, formatted)
+	}
+	if len(doc.Children) != 2 {
+		t.Errorf("Expected the heading and the list, got %d blocks", len(doc.Children))
+	}
+
+	library, err := Format(content, cfg)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if string(library) != formatted {
+		t.Errorf("Expected Format to match the pipeline:\n%s\ngot:\n%s", formatted, library)
+	}
+}
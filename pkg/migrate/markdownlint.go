@@ -0,0 +1,146 @@
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// markdownlintAliases maps the aliases of the rules with formatting
+// equivalents to their IDs
+var markdownlintAliases = map[string]string{
+	"heading-increment":       "MD001",
+	"heading-style":           "MD003",
+	"ul-style":                "MD004",
+	"ul-indent":               "MD007",
+	"no-trailing-spaces":      "MD009",
+	"no-multiple-blanks":      "MD012",
+	"line-length":             "MD013",
+	"ol-prefix":               "MD029",
+	"no-bare-urls":            "MD034",
+	"code-block-style":        "MD046",
+	"single-trailing-newline": "MD047",
+	"code-fence-style":        "MD048",
+	"emphasis-style":          "MD049",
+	"strong-style":            "MD050",
+}
+
+// markdownlintBullets maps MD004 styles to bullet characters
+var markdownlintBullets = map[string]string{"dash": "-", "asterisk": "*", "plus": "+"}
+
+// markdownlintFences maps MD048 styles to fence styles
+var markdownlintFences = map[string]string{"backtick": "```", "tilde": "~~~"}
+
+// Markdownlint translates a markdownlint configuration in JSON, JSONC or
+// YAML. Rules may be named by ID or alias. Enabled rules without a
+// formatting equivalent are reported; disabled ones impose nothing.
+func Markdownlint(data []byte) (*Result, error) {
+	values, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := newResult()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+		rule := strings.ToUpper(key)
+		if id, ok := markdownlintAliases[strings.ToLower(key)]; ok {
+			rule = id
+		}
+		enabled := value != false
+		params, _ := value.(map[string]interface{})
+
+		switch rule {
+		case "DEFAULT", "$SCHEMA":
+			// rule defaults and editor metadata
+		case "EXTENDS":
+			r.skip(key, "migrate the extended configuration and add it to extends")
+		case "MD001":
+			if enabled {
+				r.set("heading.normalize_levels", true)
+			}
+		case "MD003":
+			switch style := params["style"]; style {
+			case nil, "consistent":
+			case "atx", "setext":
+				r.set("heading.style", style)
+			default:
+				r.skip(key, fmt.Sprintf("style %v: mdfmt writes atx or setext headings", style))
+			}
+		case "MD004":
+			switch style := params["style"]; style {
+			case nil, "consistent":
+			case "dash", "asterisk", "plus":
+				r.set("list.bullet_style", markdownlintBullets[style.(string)])
+			default:
+				r.skip(key, fmt.Sprintf("style %v: mdfmt uses one bullet character at every level", style))
+			}
+		case "MD007":
+			if indent, ok := toInt(params["indent"]); ok && enabled {
+				r.set("list.indent", indent)
+			}
+		case "MD009":
+			r.set("whitespace.trim_trailing_spaces", enabled)
+		case "MD012":
+			if !enabled {
+				break
+			}
+			maximum := 1
+			if n, ok := toInt(params["maximum"]); ok {
+				maximum = n
+			}
+			r.set("whitespace.max_blank_lines", maximum)
+		case "MD013":
+			if !enabled {
+				r.set("reflow.mode", "preserve")
+				break
+			}
+			width := 80
+			if n, ok := toInt(params["line_length"]); ok {
+				width = n
+			}
+			r.set("line_width", width)
+		case "MD029":
+			switch style := params["style"]; {
+			case !enabled || style == nil || style == "ordered" || style == "one_or_ordered":
+			case style == "one":
+				r.set("list.numbering", "one")
+			default:
+				r.skip(key, fmt.Sprintf("style %v: mdfmt numbers ordered lists from one", style))
+			}
+		case "MD034":
+			if enabled {
+				r.set("links.bare_url_style", "angle")
+			}
+		case "MD046":
+			if style := params["style"]; style == "indented" {
+				r.skip(key, "style indented: mdfmt writes fenced code blocks")
+			}
+		case "MD047":
+			r.set("whitespace.ensure_final_newline", enabled)
+		case "MD048":
+			switch style := params["style"]; style {
+			case nil, "consistent":
+			case "backtick", "tilde":
+				r.set("code.fence_style", markdownlintFences[style.(string)])
+			default:
+				r.skip(key, fmt.Sprintf("style %v is not supported", style))
+			}
+		case "MD049", "MD050":
+			if enabled {
+				r.skip(key, "mdfmt keeps emphasis markers as written")
+			}
+		default:
+			if enabled {
+				r.skip(key, "lint rule with no formatting equivalent")
+			}
+		}
+	}
+	return r, nil
+}
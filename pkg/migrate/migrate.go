@@ -0,0 +1,266 @@
+// Package migrate translates Prettier and markdownlint configurations into
+// mdfmt configurations.
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+// Tools whose configurations can be migrated
+const (
+	// FromPrettier migrates a Prettier configuration
+	FromPrettier = "prettier"
+	// FromMarkdownlint migrates a markdownlint configuration
+	FromMarkdownlint = "markdownlint"
+)
+
+// packageJSON holds Prettier configuration under its "prettier" key
+const packageJSON = "package.json"
+
+// ConfigFiles lists the configuration file names of each tool, in order of
+// precedence
+var ConfigFiles = map[string][]string{
+	FromPrettier: {
+		".prettierrc", ".prettierrc.json", ".prettierrc.yaml", ".prettierrc.yml", packageJSON,
+	},
+	FromMarkdownlint: {
+		".markdownlint.json", ".markdownlint.jsonc", ".markdownlint.yaml", ".markdownlint.yml",
+	},
+}
+
+// Note explains a setting that has no mdfmt equivalent
+type Note struct {
+	// Setting names the setting in the source configuration
+	Setting string
+	// Reason explains why it was not translated
+	Reason string
+}
+
+// Result is a translated configuration
+type Result struct {
+	// Extends lists the presets the configuration is based on
+	Extends []string
+	// Options maps dotted mdfmt option paths, such as "list.bullet_style",
+	// to their values
+	Options map[string]interface{}
+	// Unmapped lists the settings that have no mdfmt equivalent
+	Unmapped []Note
+}
+
+// newResult returns an empty result
+func newResult() *Result {
+	return &Result{Options: make(map[string]interface{})}
+}
+
+// set records the value of an mdfmt option
+func (r *Result) set(option string, value interface{}) {
+	r.Options[option] = value
+}
+
+// skip records a setting that has no mdfmt equivalent
+func (r *Result) skip(setting, reason string) {
+	r.Unmapped = append(r.Unmapped, Note{Setting: setting, Reason: reason})
+}
+
+// Validate checks that the translated options form a valid configuration
+func (r *Result) Validate() error {
+	cfg := config.Default()
+	for _, option := range r.options() {
+		if err := cfg.Set(option, fmt.Sprint(r.Options[option])); err != nil {
+			return err
+		}
+	}
+	return cfg.Validate()
+}
+
+// options returns the translated option paths, sorted
+func (r *Result) options() []string {
+	options := make([]string, 0, len(r.Options))
+	for option := range r.Options {
+		options = append(options, option)
+	}
+	sort.Strings(options)
+	return options
+}
+
+// YAML returns the translated configuration as the content of an
+// .mdfmt.yaml file, with a comment naming the file it was migrated from
+func (r *Result) YAML(from string) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode, HeadComment: "Migrated from " + from + " by mdfmt migrate"}
+	if len(r.Extends) > 0 {
+		var extends yaml.Node
+		if err := extends.Encode(r.Extends); err != nil {
+			return nil, fmt.Errorf("failed to encode extends: %w", err)
+		}
+		extends.Style = yaml.FlowStyle
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "extends"}, &extends)
+	}
+
+	nested := make(map[string]interface{})
+	for _, option := range r.options() {
+		parts := strings.Split(option, ".")
+		m := nested
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := m[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				m[part] = child
+			}
+			m = child
+		}
+		m[parts[len(parts)-1]] = r.Options[option]
+	}
+	var options yaml.Node
+	if err := options.Encode(nested); err != nil {
+		return nil, fmt.Errorf("failed to encode options: %w", err)
+	}
+	root.Content = append(root.Content, options.Content...)
+
+	data, err := yaml.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return data, nil
+}
+
+// FindConfig returns the first configuration file of tool in dir
+func FindConfig(tool, dir string) (string, error) {
+	names, ok := ConfigFiles[tool]
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q: supported tools are %s and %s", tool, FromPrettier, FromMarkdownlint)
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if name == packageJSON && !hasPrettierKey(path) {
+			continue
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("no %s configuration found in %s (looked for %s)", tool, dir, strings.Join(names, ", "))
+}
+
+// File translates the configuration file of tool at path
+func File(tool, path string) (*Result, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is user provided
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	switch tool {
+	case FromPrettier:
+		if filepath.Base(path) == packageJSON {
+			if data, err = prettierFromPackageJSON(data); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		}
+		return Prettier(data)
+	case FromMarkdownlint:
+		return Markdownlint(data)
+	default:
+		return nil, fmt.Errorf("unknown tool %q: supported tools are %s and %s", tool, FromPrettier, FromMarkdownlint)
+	}
+}
+
+// hasPrettierKey reports whether the package.json at path configures Prettier
+func hasPrettierKey(path string) bool {
+	data, err := os.ReadFile(path) // #nosec G304 - path is built from a user provided directory
+	if err != nil {
+		return false
+	}
+	_, err = prettierFromPackageJSON(data)
+	return err == nil
+}
+
+// prettierFromPackageJSON returns the "prettier" key of a package.json
+func prettierFromPackageJSON(data []byte) ([]byte, error) {
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	prettier, ok := pkg["prettier"]
+	if !ok {
+		return nil, fmt.Errorf("no \"prettier\" key")
+	}
+	return prettier, nil
+}
+
+// decode parses a JSON, JSONC or YAML configuration into a map
+func decode(data []byte) (map[string]interface{}, error) {
+	data = stripJSONComments(data)
+	values := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) == 0 {
+		return values, nil
+	}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	return values, nil
+}
+
+// stripJSONComments removes // and /* */ comments outside strings, as
+// allowed in .jsonc files. YAML is returned unchanged, as it never starts
+// with "{".
+func stripJSONComments(data []byte) []byte {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return data
+	}
+
+	var b bytes.Buffer
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				b.WriteByte(data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			b.WriteByte(c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			b.WriteByte('\n')
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return b.Bytes()
+			}
+			i += end + 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.Bytes()
+}
+
+// toInt returns v as an int, if it is a whole number
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,165 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+func TestPrettier(t *testing.T) {
+	data := []byte(`{
+  "printWidth": 100,
+  "proseWrap": "preserve",
+  "semi": false,
+  "overrides": [
+    {"files": "*.{md,mdx}", "options": {"tabWidth": 4}},
+    {"files": "*.ts", "options": {"printWidth": 120}}
+  ]
+}`)
+	r, err := Prettier(data)
+	if err != nil {
+		t.Fatalf("Prettier failed: %v", err)
+	}
+	if len(r.Extends) != 1 || r.Extends[0] != prettierPreset {
+		t.Errorf("Expected the prettier-compat preset, got %v", r.Extends)
+	}
+	if r.Options["line_width"] != 100 || r.Options["list.indent"] != 4 || r.Options["reflow.mode"] != "preserve" {
+		t.Errorf("Unexpected options: %v", r.Options)
+	}
+
+	unmapped := settings(r)
+	for _, setting := range []string{"semi", "overrides[1]"} {
+		if !strings.Contains(unmapped, setting) {
+			t.Errorf("Expected %s to be reported, got %s", setting, unmapped)
+		}
+	}
+}
+
+func TestMarkdownlint(t *testing.T) {
+	data := []byte(`{
+  // comments are allowed in .jsonc files
+  "default": true,
+  "heading-style": {"style": "setext"},
+  "MD004": {"style": "asterisk"},
+  "md007": {"indent": 4},
+  "MD013": {"line_length": 120},
+  "MD024": true,
+  "ol-prefix": {"style": "one"},
+  "MD033": false,
+  "code-fence-style": {"style": "tilde"}
+}`)
+	r, err := Markdownlint(data)
+	if err != nil {
+		t.Fatalf("Markdownlint failed: %v", err)
+	}
+	expected := map[string]interface{}{
+		"heading.style":     "setext",
+		"list.bullet_style": "*",
+		"list.indent":       4,
+		"line_width":        120,
+		"code.fence_style":  "~~~",
+		"list.numbering":    "one",
+	}
+	for option, value := range expected {
+		if r.Options[option] != value {
+			t.Errorf("Expected %s to be %v, got %v", option, value, r.Options[option])
+		}
+	}
+	if unmapped := settings(r); unmapped != "MD024" {
+		t.Errorf("Expected only MD024 to be reported, got %s", unmapped)
+	}
+}
+
+func TestMarkdownlintLineLengthDisabled(t *testing.T) {
+	r, err := Markdownlint([]byte(`{"line-length": false}`))
+	if err != nil {
+		t.Fatalf("Markdownlint failed: %v", err)
+	}
+	if r.Options["reflow.mode"] != "preserve" {
+		t.Errorf("Expected reflow.mode preserve, got %v", r.Options)
+	}
+	if err := r.Validate(); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+}
+
+func TestYAMLLoads(t *testing.T) {
+	r, err := Markdownlint([]byte("MD004:\n  style: dash\nMD012:\n  maximum: 2\nMD047: false\n"))
+	if err != nil {
+		t.Fatalf("Markdownlint failed: %v", err)
+	}
+	r.Extends = []string{prettierPreset}
+	if err := r.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	data, err := r.YAML(".markdownlint.yaml")
+	if err != nil {
+		t.Fatalf("YAML failed: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# Migrated from .markdownlint.yaml") {
+		t.Errorf("Expected a header comment, got:\n%s", data)
+	}
+
+	path := filepath.Join(t.TempDir(), ".mdfmt.yaml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v\n%s", err, data)
+	}
+	if cfg.List.BulletStyle != "-" || cfg.Whitespace.MaxBlankLines != 2 || cfg.Whitespace.EnsureFinalNewline {
+		t.Errorf("Unexpected configuration loaded from:\n%s", data)
+	}
+}
+
+func TestValidateRejectsInvalidOptions(t *testing.T) {
+	r, err := Prettier([]byte("printWidth: -1\n"))
+	if err != nil {
+		t.Fatalf("Prettier failed: %v", err)
+	}
+	if err := r.Validate(); err == nil {
+		t.Error("Expected a negative line width to be rejected")
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, packageJSON), []byte(`{"name": "docs"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FindConfig(FromPrettier, dir); err == nil {
+		t.Error("Expected a package.json without a prettier key to be skipped")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, packageJSON), []byte(`{"prettier": {"printWidth": 72}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path, err := FindConfig(FromPrettier, dir)
+	if err != nil {
+		t.Fatalf("FindConfig failed: %v", err)
+	}
+	r, err := File(FromPrettier, path)
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+	if r.Options["line_width"] != 72 {
+		t.Errorf("Expected line width from package.json, got %v", r.Options["line_width"])
+	}
+
+	if _, err := FindConfig("remark", dir); err == nil {
+		t.Error("Expected an unknown tool to be rejected")
+	}
+}
+
+// settings returns the unmapped settings of r, comma separated
+func settings(r *Result) string {
+	names := make([]string, 0, len(r.Unmapped))
+	for _, note := range r.Unmapped {
+		names = append(names, note.Setting)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// prettierPreset is the preset matching Prettier's fixed markdown style
+const prettierPreset = "prettier-compat"
+
+// markdownExtensionPattern matches markdown file extensions in override
+// globs such as "*.md" and "*.{md,mdx}"
+var markdownExtensionPattern = regexp.MustCompile(`[.{,](md|mdx|markdown)([},]|$)`)
+
+// Prettier translates a Prettier configuration in JSON or YAML. The result
+// extends the prettier-compat preset, which matches the markdown style
+// Prettier always produces. Overrides for markdown files are applied over
+// the top-level options.
+func Prettier(data []byte) (*Result, error) {
+	values, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := newResult()
+	r.Extends = []string{prettierPreset}
+	translatePrettierOptions(r, values, "")
+
+	overrides, _ := values["overrides"].([]interface{})
+	for i, override := range overrides {
+		setting := fmt.Sprintf("overrides[%d]", i)
+		override, ok := override.(map[string]interface{})
+		if !ok {
+			r.skip(setting, "not a mapping")
+			continue
+		}
+		if !matchesMarkdown(override["files"]) {
+			r.skip(setting, "does not apply to markdown files")
+			continue
+		}
+		options, _ := override["options"].(map[string]interface{})
+		translatePrettierOptions(r, options, setting+".options.")
+	}
+	return r, nil
+}
+
+// translatePrettierOptions translates top-level or override options
+func translatePrettierOptions(r *Result, values map[string]interface{}, prefix string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+		setting := prefix + key
+		switch key {
+		case "overrides", "$schema":
+			// overrides are handled by Prettier; $schema is editor metadata
+		case "printWidth":
+			if width, ok := toInt(value); ok {
+				r.set("line_width", width)
+			} else {
+				r.skip(setting, "not a number")
+			}
+		case "proseWrap":
+			switch value {
+			case "always":
+				r.set("reflow.mode", "wrap")
+			case "preserve":
+				r.set("reflow.mode", "preserve")
+			default:
+				r.skip(setting, fmt.Sprintf("%v: mdfmt either wraps paragraphs or keeps their line breaks", value))
+			}
+		case "endOfLine":
+			switch value {
+			case "lf", "crlf", "cr":
+				r.set("whitespace.end_of_line", value)
+			default:
+				r.skip(setting, fmt.Sprintf("%v: mdfmt writes a fixed line ending", value))
+			}
+		case "tabWidth":
+			if width, ok := toInt(value); ok {
+				r.set("list.indent", width)
+			} else {
+				r.skip(setting, "not a number")
+			}
+		case "useTabs":
+			if value == true {
+				r.skip(setting, "mdfmt indents with spaces")
+			}
+		default:
+			r.skip(setting, "no markdown formatting equivalent")
+		}
+	}
+}
+
+// matchesMarkdown reports whether the files pattern of a Prettier override
+// applies to markdown files
+func matchesMarkdown(files interface{}) bool {
+	var patterns []string
+	switch files := files.(type) {
+	case string:
+		patterns = []string{files}
+	case []interface{}:
+		for _, pattern := range files {
+			if s, ok := pattern.(string); ok {
+				patterns = append(patterns, s)
+			}
+		}
+	}
+	for _, pattern := range patterns {
+		if markdownExtensionPattern.MatchString(strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+// Package notebook reads and rewrites the markdown cells of Jupyter notebooks.
+package notebook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Constants
+const (
+	// Extension is the file extension of Jupyter notebooks
+	Extension = ".ipynb"
+	// markdownCellType is the cell_type of markdown cells
+	markdownCellType = "markdown"
+)
+
+// Notebook is a Jupyter notebook whose markdown cell sources can be
+// replaced. Everything else, including outputs, metadata and the layout of
+// the JSON, is written back byte for byte.
+type Notebook struct {
+	data  []byte
+	cells []*Cell
+}
+
+// Cell is a markdown cell of a notebook
+type Cell struct {
+	// Index is the position of the cell among all cells of the notebook
+	Index int
+	// Source is the markdown of the cell; changes are written by Bytes
+	Source string
+
+	original string
+	// start and end are the offsets of the source value in the notebook
+	start, end int
+	// lines is set when the source is a list of lines rather than a string
+	lines bool
+	// multiline is set when the lines are written one per line, indented
+	// by indent, with the closing bracket indented by closeIndent
+	multiline           bool
+	indent, closeIndent string
+}
+
+// IsNotebook reports whether path names a Jupyter notebook
+func IsNotebook(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), Extension)
+}
+
+// Parse parses a notebook, finding its markdown cells
+func Parse(data []byte) (*Notebook, error) {
+	fields, err := objectFields(data, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notebook: %w", err)
+	}
+	cells, ok := fields["cells"]
+	if !ok {
+		return nil, fmt.Errorf("invalid notebook: no cells")
+	}
+	elements, err := arrayElements(data, cells[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid notebook cells: %w", err)
+	}
+
+	nb := &Notebook{data: data}
+	for i, element := range elements {
+		cell, err := parseCell(data, element)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notebook cell %d: %w", i+1, err)
+		}
+		if cell != nil {
+			cell.Index = i
+			nb.cells = append(nb.cells, cell)
+		}
+	}
+	return nb, nil
+}
+
+// parseCell returns the markdown cell at offset, or nil for other cells
+func parseCell(data []byte, offset int) (*Cell, error) {
+	fields, err := objectFields(data, offset)
+	if err != nil {
+		return nil, err
+	}
+	var cellType string
+	if span, ok := fields["cell_type"]; ok {
+		if err := json.Unmarshal(data[span[0]:span[1]], &cellType); err != nil {
+			return nil, fmt.Errorf("invalid cell_type: %w", err)
+		}
+	}
+	span, ok := fields["source"]
+	if cellType != markdownCellType || !ok {
+		return nil, nil
+	}
+
+	cell := &Cell{start: span[0], end: span[1]}
+	raw := data[span[0]:span[1]]
+	if raw[0] == '[' {
+		var lines []string
+		if err := json.Unmarshal(raw, &lines); err != nil {
+			return nil, fmt.Errorf("invalid source: %w", err)
+		}
+		cell.Source = strings.Join(lines, "")
+		cell.lines = true
+		cell.indent, cell.closeIndent, cell.multiline = listLayout(raw)
+	} else if err := json.Unmarshal(raw, &cell.Source); err != nil {
+		return nil, fmt.Errorf("invalid source: %w", err)
+	}
+	cell.original = cell.Source
+	return cell, nil
+}
+
+// MarkdownCells returns the markdown cells of the notebook, in order
+func (n *Notebook) MarkdownCells() []*Cell {
+	return n.cells
+}
+
+// Bytes returns the notebook with the changed sources of its markdown cells
+// written in the shape they were read in
+func (n *Notebook) Bytes() []byte {
+	var buf bytes.Buffer
+	last := 0
+	for _, cell := range n.cells {
+		if cell.Source == cell.original {
+			continue
+		}
+		buf.Write(n.data[last:cell.start])
+		buf.WriteString(cell.encode())
+		last = cell.end
+	}
+	buf.Write(n.data[last:])
+	return buf.Bytes()
+}
+
+// encode returns the source of the cell as JSON
+func (c *Cell) encode() string {
+	if !c.lines {
+		return quote(c.Source)
+	}
+	lines := SplitLines(c.Source)
+	if len(lines) == 0 {
+		return "[]"
+	}
+	quoted := make([]string, len(lines))
+	for i, line := range lines {
+		quoted[i] = quote(line)
+	}
+	if !c.multiline {
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+	return "[\n" + c.indent + strings.Join(quoted, ",\n"+c.indent) + "\n" + c.closeIndent + "]"
+}
+
+// SplitLines splits source into the lines of a notebook source list, each
+// but the last ending with its newline
+func SplitLines(source string) []string {
+	if source == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(source, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// quote returns s as a JSON string without escaping HTML characters, as
+// Jupyter writes them
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// listLayout returns the indentation of the elements and of the closing
+// bracket of a JSON list, and whether its elements are on their own lines
+func listLayout(raw []byte) (indent, closeIndent string, multiline bool) {
+	inner := raw[1 : len(raw)-1]
+	first := bytes.TrimLeft(inner, " \t\r\n")
+	leading := inner[:len(inner)-len(first)]
+	i := bytes.LastIndexByte(leading, '\n')
+	if i < 0 {
+		return "", "", false
+	}
+	indent = string(bytes.TrimRight(leading[i+1:], "\r"))
+
+	trailing := inner[len(bytes.TrimRight(inner, " \t\r\n")):]
+	if j := bytes.LastIndexByte(trailing, '\n'); j >= 0 {
+		closeIndent = string(trailing[j+1:])
+	}
+	return indent, closeIndent, true
+}
+
+// objectFields returns the value spans of the fields of the JSON object at
+// offset in data, by key
+func objectFields(data []byte, offset int) (map[string][2]int, error) {
+	dec := json.NewDecoder(bytes.NewReader(data[offset:]))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected an object at offset %d", offset)
+	}
+	fields := make(map[string][2]int)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		start := skipSeparators(data, offset+int(dec.InputOffset()))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		fields[key] = [2]int{start, offset + int(dec.InputOffset())}
+	}
+	return fields, nil
+}
+
+// arrayElements returns the offsets of the elements of the JSON array at
+// offset in data
+func arrayElements(data []byte, offset int) ([]int, error) {
+	dec := json.NewDecoder(bytes.NewReader(data[offset:]))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("expected an array at offset %d", offset)
+	}
+	var elements []int
+	for dec.More() {
+		elements = append(elements, skipSeparators(data, offset+int(dec.InputOffset())))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+	}
+	return elements, nil
+}
+
+// skipSeparators returns the offset of the next value at or after offset,
+// skipping whitespace, colons and commas
+func skipSeparators(data []byte, offset int) int {
+	for offset < len(data) && strings.IndexByte(" \t\r\n:,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
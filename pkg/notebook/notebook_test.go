@@ -0,0 +1,78 @@
+package notebook
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "metadata": {},
+   "source": [
+    "# Title\n",
+    "\n",
+    "Some   text"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 1,
+   "metadata": {"tags": ["a"]},
+   "outputs": [{"output_type": "stream", "name": "stdout", "text": ["# not markdown\n"]}],
+   "source": ["print('<b>')"]
+  },
+  {"cell_type": "markdown", "metadata": {}, "source": "*a*  &  <b>"}
+ ],
+ "metadata": {"kernelspec": {"name": "python3"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestParse(t *testing.T) {
+	nb, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cells := nb.MarkdownCells()
+	if len(cells) != 2 {
+		t.Fatalf("Expected 2 markdown cells, got %d", len(cells))
+	}
+	if cells[0].Index != 0 || cells[0].Source != "# Title\n\nSome   text" {
+		t.Errorf("Unexpected first cell %d %q", cells[0].Index, cells[0].Source)
+	}
+	if cells[1].Index != 2 || cells[1].Source != "*a*  &  <b>" {
+		t.Errorf("Unexpected second cell %d %q", cells[1].Index, cells[1].Source)
+	}
+	if got := string(nb.Bytes()); got != sample {
+		t.Errorf("Expected an unchanged notebook to be written back as is, got:\n%s", got)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	nb, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cells := nb.MarkdownCells()
+	cells[0].Source = "# Title\n\nSome text\nmore"
+	cells[1].Source = "_a_ & <b>"
+
+	expected := strings.NewReplacer(
+		`    "Some   text"`, `    "Some text\n",`+"\n"+`    "more"`,
+		`"source": "*a*  &  <b>"`, `"source": "_a_ & <b>"`,
+	).Replace(sample)
+	if got := string(nb.Bytes()); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, data := range []string{``, `[]`, `{"metadata": {}}`, `{"cells": [{"cell_type": "markdown", "source": 1}]}`} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
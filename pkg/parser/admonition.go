@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// admonitionPattern matches a GitHub alert marker line such as "[!NOTE]"
+var admonitionPattern = regexp.MustCompile(`^\[!((?i:NOTE|TIP|IMPORTANT|WARNING|CAUTION))\]\s*$`)
+
+// asAdmonition turns the blocks of a blockquote into an Admonition when the
+// first line of the quote is a GitHub alert marker. It returns nil otherwise.
+func asAdmonition(children []Node) *Admonition {
+	if len(children) == 0 {
+		return nil
+	}
+
+	para, ok := children[0].(*Paragraph)
+	if !ok {
+		return nil
+	}
+
+	firstLine, rest, _ := strings.Cut(para.Text, "\n")
+	match := admonitionPattern.FindStringSubmatch(strings.TrimSpace(firstLine))
+	if match == nil {
+		return nil
+	}
+
+	body := children[1:]
+	if rest = strings.TrimSpace(rest); rest != "" {
+		para.Text = rest
+		body = children
+	}
+
+	return &Admonition{
+		Kind:     strings.ToUpper(match[1]),
+		Tag:      match[1],
+		Children: body,
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import "testing"
+
+func TestGoldmarkParser_ParseAdmonition(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		kind     string
+		tag      string
+		children int
+	}{
+		{"upper case", "> [!NOTE]\n> Useful information.\n", "NOTE", "NOTE", 1},
+		{"mixed case", "> [!Warning]\n> Be careful.\n", "WARNING", "Warning", 1},
+		{"marker only", "> [!TIP]\n", "TIP", "TIP", 0},
+		{"separate paragraphs", "> [!CAUTION]\n>\n> First.\n>\n> Second.\n", "CAUTION", "CAUTION", 2},
+	}
+
+	parser := NewGoldmarkParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(doc.Children) != 1 {
+				t.Fatalf("Expected one node, got %s", DebugString(doc))
+			}
+
+			admonition, ok := doc.Children[0].(*Admonition)
+			if !ok {
+				t.Fatalf("Expected Admonition, got %T", doc.Children[0])
+			}
+			if admonition.Kind != tt.kind || admonition.Tag != tt.tag {
+				t.Errorf("Expected kind %q tag %q, got kind %q tag %q", tt.kind, tt.tag, admonition.Kind, admonition.Tag)
+			}
+			if len(admonition.Children) != tt.children {
+				t.Errorf("Expected %d body blocks, got %d", tt.children, len(admonition.Children))
+			}
+			for _, child := range admonition.Children {
+				if para, ok := child.(*Paragraph); ok && admonitionPattern.MatchString(para.Text) {
+					t.Errorf("Marker leaked into body: %q", para.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestGoldmarkParser_ParseBlockquote(t *testing.T) {
+	parser := NewGoldmarkParser()
+	doc, err := parser.Parse([]byte("> [!NOTE] is not alone on its line\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	quote, ok := doc.Children[0].(*Blockquote)
+	if !ok {
+		t.Fatalf("Expected plain Blockquote, got %T", doc.Children[0])
+	}
+	if len(quote.Children) != 1 {
+		t.Errorf("Expected one paragraph in blockquote, got %d", len(quote.Children))
+	}
+}
@@ -4,6 +4,7 @@ package parser
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
 // NodeType represents the type of a node in the AST
@@ -25,6 +26,40 @@ const (
 	NodeCodeBlock
 	// NodeText represents plain text content
 	NodeText
+	// NodeHTMLBlock represents a raw HTML block preserved verbatim
+	NodeHTMLBlock
+	// NodeDetails represents a <details> wrapper around markdown blocks
+	NodeDetails
+	// NodeBlockquote represents a blockquote (> quoted text)
+	NodeBlockquote
+	// NodeAdmonition represents a GitHub alert blockquote (> [!NOTE])
+	NodeAdmonition
+	// NodeMath represents a display math block ($$...$$)
+	NodeMath
+	// NodeAutolink represents an inline autolink (<https://...>) or bare URL
+	NodeAutolink
+	// NodeMDXBlock represents a raw MDX region (JSX, ESM or expression)
+	NodeMDXBlock
+	// NodeMDXElement represents a JSX element wrapping markdown blocks
+	NodeMDXElement
+	// NodeStrikethrough represents inline ~~deleted~~ text
+	NodeStrikethrough
+	// NodeHighlight represents inline ==highlighted== text
+	NodeHighlight
+	// NodeDefinitionList represents a definition list (Term / : Description)
+	NodeDefinitionList
+	// NodeDefinitionTerm represents a term in a definition list
+	NodeDefinitionTerm
+	// NodeDefinitionDescription represents a ": description" in a definition list
+	NodeDefinitionDescription
+	// NodeLink represents an inline link or image
+	NodeLink
+	// NodeLinkDefinitions represents consecutive link reference definitions
+	NodeLinkDefinitions
+	// NodeTable represents a GFM table
+	NodeTable
+	// NodeCustom represents nodes produced by registered extensions
+	NodeCustom
 )
 
 // Node represents a basic node in the markdown AST
@@ -33,9 +68,41 @@ type Node interface {
 	String() string
 }
 
+// BlockContainer is implemented by nodes that wrap other block nodes
+type BlockContainer interface {
+	Node
+	Blocks() []Node
+}
+
 // Document represents the root document node
 type Document struct {
-	Children []Node
+	// FrontMatter is the front matter block of the document, including its
+	// delimiter lines, copied verbatim
+	FrontMatter string
+	Children    []Node
+	// NoFinalNewline is set when the source does not end with a line break
+	NoFinalNewline bool
+	// Diagnostics reports regions that were copied verbatim because their
+	// syntax is recognized but not enabled, and problems found by formatters
+	Diagnostics []Diagnostic
+	// lines holds the source line of each block, when recorded
+	lines map[Node]int
+	// definitionLines holds the source line of each link reference definition
+	definitionLines map[*LinkDefinition]int
+}
+
+// Line returns the 1-based source line where node starts. Lines are known
+// for the blocks and list items of documents parsed with Options.Positions.
+func (n *Document) Line(node Node) (int, bool) {
+	line, ok := n.lines[node]
+	return line, ok
+}
+
+// DefinitionLine returns the 1-based source line of a link reference
+// definition of the parsed document
+func (n *Document) DefinitionLine(definition *LinkDefinition) (int, bool) {
+	line, ok := n.definitionLines[definition]
+	return line, ok
 }
 
 // Type returns the node type for Document nodes.
@@ -47,6 +114,9 @@ type Heading struct {
 	Level int
 	Text  string
 	Style string // "atx" or "setext"
+	// Attributes is a trailing attribute block such as "{#custom-id .class}",
+	// kept apart from Text so formatting rules never touch it
+	Attributes string
 }
 
 // Type returns the node type for Heading nodes.
@@ -58,6 +128,12 @@ func (n *Heading) String() string {
 // Paragraph represents a paragraph node
 type Paragraph struct {
 	Text string
+	// NoReflow keeps the line breaks of Text as written instead of
+	// wrapping it at the line width
+	NoReflow bool
+	// Interrupted is set when a list starts on the line after the
+	// paragraph, without a blank line between them
+	Interrupted bool
 }
 
 // Type returns the node type for Paragraph nodes.
@@ -69,8 +145,18 @@ func (n *Paragraph) String() string {
 // List represents a list node
 type List struct {
 	Ordered bool
-	Items   []*ListItem
-	Marker  string
+	// Start is the number of the first item of an ordered list
+	Start  int
+	Items  []*ListItem
+	Marker string
+	// TabIndented is set when the list was indented with tabs in the source
+	TabIndented bool
+	// Loose lists separate their items with blank lines
+	Loose bool
+	// Follows counts the lists of the same kind directly before the list
+	// in its container. Markdown keeps such lists apart only by their
+	// markers, so lists at odd counts take another marker.
+	Follows int
 }
 
 // Type returns the node type for List nodes.
@@ -81,8 +167,14 @@ func (n *List) String() string {
 
 // ListItem represents a list item node
 type ListItem struct {
-	Text     string
-	Marker   string
+	Text   string
+	Marker string
+	// NoReflow keeps the line breaks of Text, like Paragraph.NoReflow
+	NoReflow bool
+	// Task is set for items of task lists, which start with a checkbox
+	// before Text; Checked is the state of the checkbox
+	Task     bool
+	Checked  bool
 	Children []Node // Support for nested lists and other elements
 }
 
@@ -94,10 +186,16 @@ func (n *ListItem) String() string {
 
 // CodeBlock represents a code block node
 type CodeBlock struct {
+	// Language is the first word of Info
 	Language string
-	Content  string
-	Fenced   bool
-	Fence    string
+	// Info is the info string after the opening fence of a fenced block,
+	// such as `go title="main.go"`, as written in the source
+	Info    string
+	Content string
+	Fenced  bool
+	// Fence is the opening fence of a fenced block, such as "```" or
+	// "~~~~", as written in the source
+	Fence string
 }
 
 // Type returns the node type for CodeBlock nodes.
@@ -117,18 +215,431 @@ func (n *Text) String() string {
 	return fmt.Sprintf("Text(content=%q)", n.Content)
 }
 
-// Walker provides a simple way to iterate over nodes
+// HTMLBlock represents a raw HTML block node
+type HTMLBlock struct {
+	Content string
+}
+
+// Type returns the node type for HTMLBlock nodes.
+func (n *HTMLBlock) Type() NodeType { return NodeHTMLBlock }
+func (n *HTMLBlock) String() string {
+	return fmt.Sprintf("HTMLBlock(content=%q)", n.Content)
+}
+
+// Details represents a collapsible <details> section. Open holds the opening
+// tag together with its <summary>, Close holds the closing tag, and Children
+// holds the markdown blocks between them.
+type Details struct {
+	Open     string
+	Close    string
+	Children []Node
+}
+
+// Type returns the node type for Details nodes.
+func (n *Details) Type() NodeType { return NodeDetails }
+func (n *Details) String() string {
+	return fmt.Sprintf("Details(open=%q, children=%d)", n.Open, len(n.Children))
+}
+
+// Blocks returns the markdown blocks inside the details section.
+func (n *Details) Blocks() []Node { return n.Children }
+
+// Blockquote represents a blockquote node
+type Blockquote struct {
+	Children []Node
+}
+
+// Type returns the node type for Blockquote nodes.
+func (n *Blockquote) Type() NodeType { return NodeBlockquote }
+func (n *Blockquote) String() string {
+	return fmt.Sprintf("Blockquote(children=%d)", len(n.Children))
+}
+
+// Blocks returns the blocks inside the blockquote.
+func (n *Blockquote) Blocks() []Node { return n.Children }
+
+// Admonition represents a GitHub alert such as "> [!NOTE]". Kind holds the
+// alert type in upper case, Tag holds the type as written in the source.
+type Admonition struct {
+	Kind     string
+	Tag      string
+	Children []Node
+}
+
+// Type returns the node type for Admonition nodes.
+func (n *Admonition) Type() NodeType { return NodeAdmonition }
+func (n *Admonition) String() string {
+	return fmt.Sprintf("Admonition(kind=%q, children=%d)", n.Kind, len(n.Children))
+}
+
+// Blocks returns the blocks inside the admonition body.
+func (n *Admonition) Blocks() []Node { return n.Children }
+
+// Math represents a display math block. Content holds the source including
+// its $$ delimiters and is emitted byte-for-byte.
+type Math struct {
+	Content string
+}
+
+// Type returns the node type for Math nodes.
+func (n *Math) Type() NodeType { return NodeMath }
+func (n *Math) String() string {
+	return fmt.Sprintf("Math(content=%q)", n.Content)
+}
+
+// DefinitionList represents a definition list. Children holds its terms and
+// descriptions in document order.
+type DefinitionList struct {
+	Children []Node
+}
+
+// Type returns the node type for DefinitionList nodes.
+func (n *DefinitionList) Type() NodeType { return NodeDefinitionList }
+func (n *DefinitionList) String() string {
+	return fmt.Sprintf("DefinitionList(children=%d)", len(n.Children))
+}
+
+// Blocks returns the terms and descriptions of the list.
+func (n *DefinitionList) Blocks() []Node { return n.Children }
+
+// DefinitionTerm represents a term in a definition list
+type DefinitionTerm struct {
+	Text string
+}
+
+// Type returns the node type for DefinitionTerm nodes.
+func (n *DefinitionTerm) Type() NodeType { return NodeDefinitionTerm }
+func (n *DefinitionTerm) String() string {
+	return fmt.Sprintf("DefinitionTerm(text=%q)", n.Text)
+}
+
+// DefinitionDescription represents a description in a definition list. Tight
+// descriptions directly follow their term without a blank line.
+type DefinitionDescription struct {
+	Tight    bool
+	Children []Node
+}
+
+// Type returns the node type for DefinitionDescription nodes.
+func (n *DefinitionDescription) Type() NodeType { return NodeDefinitionDescription }
+func (n *DefinitionDescription) String() string {
+	return fmt.Sprintf("DefinitionDescription(tight=%t, children=%d)", n.Tight, len(n.Children))
+}
+
+// Blocks returns the blocks inside the description.
+func (n *DefinitionDescription) Blocks() []Node { return n.Children }
+
+// MDXBlock represents an MDX region kept byte-for-byte. Kind is one of
+// MDXKindJSX, MDXKindESM or MDXKindExpression.
+type MDXBlock struct {
+	Kind    string
+	Content string
+
+	fragment mdxFragment
+}
+
+// Type returns the node type for MDXBlock nodes.
+func (n *MDXBlock) Type() NodeType { return NodeMDXBlock }
+func (n *MDXBlock) String() string {
+	return fmt.Sprintf("MDXBlock(kind=%q, content=%q)", n.Kind, n.Content)
+}
+
+// MDXElement represents a JSX element whose children are markdown. Open and
+// Close hold the tags verbatim, Children holds the blocks between them.
+type MDXElement struct {
+	Open     string
+	Close    string
+	Children []Node
+}
+
+// Type returns the node type for MDXElement nodes.
+func (n *MDXElement) Type() NodeType { return NodeMDXElement }
+func (n *MDXElement) String() string {
+	return fmt.Sprintf("MDXElement(open=%q, children=%d)", n.Open, len(n.Children))
+}
+
+// Blocks returns the markdown blocks inside the element.
+func (n *MDXElement) Blocks() []Node { return n.Children }
+
+// Autolink represents an inline autolink. URL holds the link text as written,
+// without angle brackets; Bare is set for URLs recognized without brackets.
+type Autolink struct {
+	URL   string
+	Email bool
+	Bare  bool
+}
+
+// Type returns the node type for Autolink nodes.
+func (n *Autolink) Type() NodeType { return NodeAutolink }
+func (n *Autolink) String() string {
+	return fmt.Sprintf("Autolink(url=%q, bare=%t)", n.URL, n.Bare)
+}
+
+// Markdown returns the autolink in markdown syntax, exactly as written.
+func (n *Autolink) Markdown() string {
+	if n.Bare {
+		return n.URL
+	}
+	return "<" + n.URL + ">"
+}
+
+// Link represents an inline link or image. Style records how it was written
+// (LinkStyleInline, LinkStyleFull, LinkStyleCollapsed or LinkStyleShortcut);
+// Label is the reference label of full references.
+type Link struct {
+	Text        string
+	Destination string
+	Title       string
+	Image       bool
+	Style       string
+	Label       string
+}
+
+// Type returns the node type for Link nodes.
+func (n *Link) Type() NodeType { return NodeLink }
+func (n *Link) String() string {
+	return fmt.Sprintf("Link(text=%q, destination=%q, style=%q)", n.Text, n.Destination, n.Style)
+}
+
+// Markdown returns the link in markdown syntax, in the style it was written.
+func (n *Link) Markdown() string {
+	var sb strings.Builder
+	if n.Image {
+		sb.WriteString("!")
+	}
+	sb.WriteString("[" + n.Text + "]")
+
+	switch n.Style {
+	case LinkStyleFull:
+		sb.WriteString("[" + n.Label + "]")
+	case LinkStyleCollapsed:
+		sb.WriteString("[]")
+	case LinkStyleShortcut:
+	default:
+		sb.WriteString("(" + FormatDestination(n.Destination, n.Title) + ")")
+	}
+	return sb.String()
+}
+
+// ReferenceLabel returns the label a reference resolves through: the
+// explicit label of full references, otherwise the link text.
+func (n *Link) ReferenceLabel() string {
+	if n.Style == LinkStyleFull {
+		return n.Label
+	}
+	return n.Text
+}
+
+// NormalizeLabel returns the form in which reference labels are matched:
+// case-insensitive, with runs of whitespace collapsed.
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// LinkDefinition represents a link reference definition ([label]: url "title")
+type LinkDefinition struct {
+	Label       string
+	Destination string
+	Title       string
+}
+
+// Markdown returns the definition in markdown syntax.
+func (n *LinkDefinition) Markdown() string {
+	return "[" + n.Label + "]: " + FormatDestination(n.Destination, n.Title)
+}
+
+// LinkDefinitions represents a run of link reference definitions, one per line
+type LinkDefinitions struct {
+	Definitions []*LinkDefinition
+}
+
+// Type returns the node type for LinkDefinitions nodes.
+func (n *LinkDefinitions) Type() NodeType { return NodeLinkDefinitions }
+func (n *LinkDefinitions) String() string {
+	return fmt.Sprintf("LinkDefinitions(count=%d)", len(n.Definitions))
+}
+
+// Table column alignments
+const (
+	AlignLeft   = "left"
+	AlignCenter = "center"
+	AlignRight  = "right"
+)
+
+// minTableColumnWidth is the width of the narrowest delimiter row cell, ---
+const minTableColumnWidth = 3
+
+// Table represents a GFM table. Rows holds the header row first; each cell
+// is its markdown source. Alignments holds the alignment of each column:
+// "", AlignLeft, AlignCenter or AlignRight.
+type Table struct {
+	Alignments []string
+	Rows       [][]string
+}
+
+// Type returns the node type for Table nodes.
+func (n *Table) Type() NodeType { return NodeTable }
+func (n *Table) String() string {
+	return fmt.Sprintf("Table(columns=%d, rows=%d)", len(n.Alignments), len(n.Rows))
+}
+
+// Markdown returns the table in markdown syntax, with its columns padded
+// to line up. Rows are never wrapped, however long.
+func (n *Table) Markdown() string {
+	widths := make([]int, len(n.Alignments))
+	for i := range widths {
+		widths[i] = minTableColumnWidth
+		for _, row := range n.Rows {
+			if i < len(row) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(row[i]))
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(n.Rows)+1)
+	for r, row := range n.Rows {
+		cells := make([]string, len(widths))
+		for i, width := range widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			cells[i] = padCell(cell, width, n.Alignments[i])
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if r == 0 {
+			lines = append(lines, n.delimiterRow(widths))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// delimiterRow returns the row of dashes below the header, with colons
+// marking the alignment of each column
+func (n *Table) delimiterRow(widths []int) string {
+	cells := make([]string, len(widths))
+	for i, width := range widths {
+		switch n.Alignments[i] {
+		case AlignLeft:
+			cells[i] = ":" + strings.Repeat("-", width-1)
+		case AlignCenter:
+			cells[i] = ":" + strings.Repeat("-", width-2) + ":"
+		case AlignRight:
+			cells[i] = strings.Repeat("-", width-1) + ":"
+		default:
+			cells[i] = strings.Repeat("-", width)
+		}
+	}
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+// padCell pads cell with spaces to width, on the sides its alignment calls for
+func padCell(cell string, width int, alignment string) string {
+	padding := width - utf8.RuneCountInString(cell)
+	switch alignment {
+	case AlignRight:
+		return strings.Repeat(" ", padding) + cell
+	case AlignCenter:
+		return strings.Repeat(" ", padding/2) + cell + strings.Repeat(" ", padding-padding/2)
+	default:
+		return cell + strings.Repeat(" ", padding)
+	}
+}
+
+// FormatDestination formats a link destination with its optional title.
+// Destinations that are empty or contain spaces are wrapped in angle brackets.
+func FormatDestination(destination, title string) string {
+	if destination == "" || strings.ContainsAny(destination, " \t") {
+		destination = "<" + destination + ">"
+	}
+	if title == "" {
+		return destination
+	}
+	if strings.Contains(title, `"`) && !strings.Contains(title, "'") {
+		return destination + " '" + title + "'"
+	}
+	return destination + ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+}
+
+// Strikethrough represents inline struck-through text. Marker is "~~" or "~"
+// as written; Text holds the inner markdown.
+type Strikethrough struct {
+	Marker string
+	Text   string
+}
+
+// Type returns the node type for Strikethrough nodes.
+func (n *Strikethrough) Type() NodeType { return NodeStrikethrough }
+func (n *Strikethrough) String() string {
+	return fmt.Sprintf("Strikethrough(marker=%q, text=%q)", n.Marker, n.Text)
+}
+
+// Markdown returns the strikethrough in markdown syntax.
+func (n *Strikethrough) Markdown() string {
+	return n.Marker + n.Text + n.Marker
+}
+
+// Highlight represents inline ==highlighted== text; Text holds the inner markdown.
+type Highlight struct {
+	Text string
+}
+
+// Type returns the node type for Highlight nodes.
+func (n *Highlight) Type() NodeType { return NodeHighlight }
+func (n *Highlight) String() string {
+	return fmt.Sprintf("Highlight(text=%q)", n.Text)
+}
+
+// Markdown returns the highlight in markdown syntax.
+func (n *Highlight) Markdown() string {
+	return "==" + n.Text + "=="
+}
+
+// Walker provides a simple way to iterate over the blocks of a document and
+// its containers. Walk visits list items too, and can change the tree.
 type Walker struct {
 	nodes []Node
 	index int
 }
 
-// NewWalker creates a new walker for the given document
+// NewWalker creates a new walker for the given document.
+// Blocks wrapped by container nodes are visited after their container, and
+// blocks nested in list items after their list. Nested lists are not
+// visited themselves: formatting a list formats its nested lists.
 func NewWalker(doc *Document) *Walker {
-	nodes := append([]Node{doc}, doc.Children...)
+	nodes := appendBlocks([]Node{doc}, doc.Children)
 	return &Walker{nodes: nodes, index: -1}
 }
 
+// appendBlocks appends nodes and the blocks of any container nodes in document order
+func appendBlocks(dst, nodes []Node) []Node {
+	for _, node := range nodes {
+		dst = append(dst, node)
+		switch n := node.(type) {
+		case BlockContainer:
+			dst = appendBlocks(dst, n.Blocks())
+		case *List:
+			dst = appendItemBlocks(dst, n)
+		}
+	}
+	return dst
+}
+
+// appendItemBlocks appends the blocks nested in the items of list, and in
+// the items of its nested lists
+func appendItemBlocks(dst []Node, list *List) []Node {
+	for _, item := range list.Items {
+		for _, child := range item.Children {
+			if nested, ok := child.(*List); ok {
+				dst = appendItemBlocks(dst, nested)
+			} else {
+				dst = appendBlocks(dst, []Node{child})
+			}
+		}
+	}
+	return dst
+}
+
 // Next returns the next node in the walk
 func (w *Walker) Next() (Node, bool) {
 	w.index++
@@ -155,6 +666,40 @@ func NodeTypeString(t NodeType) string {
 		return "CodeBlock"
 	case NodeText:
 		return "Text"
+	case NodeHTMLBlock:
+		return "HTMLBlock"
+	case NodeDetails:
+		return "Details"
+	case NodeBlockquote:
+		return "Blockquote"
+	case NodeAdmonition:
+		return "Admonition"
+	case NodeMath:
+		return "Math"
+	case NodeAutolink:
+		return "Autolink"
+	case NodeMDXBlock:
+		return "MDXBlock"
+	case NodeMDXElement:
+		return "MDXElement"
+	case NodeStrikethrough:
+		return "Strikethrough"
+	case NodeHighlight:
+		return "Highlight"
+	case NodeDefinitionList:
+		return "DefinitionList"
+	case NodeDefinitionTerm:
+		return "DefinitionTerm"
+	case NodeDefinitionDescription:
+		return "DefinitionDescription"
+	case NodeLink:
+		return "Link"
+	case NodeLinkDefinitions:
+		return "LinkDefinitions"
+	case NodeTable:
+		return "Table"
+	case NodeCustom:
+		return "Custom"
 	default:
 		return "Unknown"
 	}
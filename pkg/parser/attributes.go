@@ -0,0 +1,30 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// headingAttribute matches one item of a Pandoc/Hugo attribute block:
+// #id, .class, key=value, or "-" (short for .unnumbered)
+const headingAttribute = `#[\w:.-]+|\.[\w-]+|[\w-]+=(?:"[^"]*"|'[^']*'|[^\s"'{}]+)|-`
+
+var (
+	// headingAttributesPattern matches an attribute block at the end of a heading
+	headingAttributesPattern = regexp.MustCompile(
+		`\s*\{\s*((?:` + headingAttribute + `)(?:\s+(?:` + headingAttribute + `))*)\s*\}$`)
+	headingAttributePattern = regexp.MustCompile(headingAttribute)
+)
+
+// splitHeadingAttributes separates a trailing attribute block such as
+// {#custom-id .class} from heading text. The block is returned with its
+// items separated by single spaces, or "" if the heading has none.
+func splitHeadingAttributes(text string) (string, string) {
+	loc := headingAttributesPattern.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return text, ""
+	}
+
+	items := headingAttributePattern.FindAllString(text[loc[2]:loc[3]], -1)
+	return strings.TrimSpace(text[:loc[0]]), "{" + strings.Join(items, " ") + "}"
+}
@@ -0,0 +1,38 @@
+package parser
+
+import "testing"
+
+func TestGoldmarkParser_ParseHeadingAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		text       string
+		attributes string
+	}{
+		{"id", "## Title {#custom-id}", "Title", "{#custom-id}"},
+		{"id and classes", "# Install   the tool {  #install   .wide  .x }", "Install the tool", "{#install .wide .x}"},
+		{"key value", `## Title {#t data-x="a b" lang=en}`, "Title", `{#t data-x="a b" lang=en}`},
+		{"unnumbered", "Setup {-}\n=====", "Setup", "{-}"},
+		{"plain braces", "# Plain {not attrs}", "Plain {not attrs}", ""},
+		{"code span", "# Code `{#x}`", "Code `{#x}`", ""},
+		{"not at end", "# A {#a} b", "A {#a} b", ""},
+	}
+
+	parser := NewGoldmarkParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			heading, ok := doc.Children[0].(*Heading)
+			if !ok {
+				t.Fatalf("Expected Heading, got %T", doc.Children[0])
+			}
+			if heading.Text != tt.text || heading.Attributes != tt.attributes {
+				t.Errorf("Expected text %q and attributes %q, got %q and %q",
+					tt.text, tt.attributes, heading.Text, heading.Attributes)
+			}
+		})
+	}
+}
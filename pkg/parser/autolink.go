@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Constants
+const (
+	// angleAutolinkParserPriority runs just before goldmark's own autolink parser (300)
+	angleAutolinkParserPriority = 299
+)
+
+// angleAutolinkAttribute marks autolinks written in <...> form
+var angleAutolinkAttribute = []byte("mdfmt-angle")
+
+// angleAutolinkParser wraps goldmark's autolink parser so that <...>
+// autolinks can be told apart from bare URLs recognized by linkify
+type angleAutolinkParser struct {
+	gmparser.InlineParser
+}
+
+// Parse implements parser.InlineParser.
+func (s *angleAutolinkParser) Parse(parent ast.Node, block text.Reader, pc gmparser.Context) ast.Node {
+	node := s.InlineParser.Parse(parent, block, pc)
+	if node != nil {
+		node.SetAttribute(angleAutolinkAttribute, true)
+	}
+	return node
+}
+
+// autolinkExtension registers the angle autolink parser with goldmark
+type autolinkExtension struct{}
+
+// Extend implements goldmark.Extender.
+func (e *autolinkExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(gmparser.WithInlineParsers(
+		util.Prioritized(&angleAutolinkParser{InlineParser: gmparser.NewAutoLinkParser()}, angleAutolinkParserPriority),
+	))
+}
+
+// convertAutolink converts a goldmark autolink, remembering how it was written
+func convertAutolink(n *ast.AutoLink, source []byte) *Autolink {
+	_, angle := n.Attribute(angleAutolinkAttribute)
+	return &Autolink{
+		URL:   string(n.Label(source)),
+		Email: n.AutoLinkType == ast.AutoLinkEmail,
+		Bare:  !angle,
+	}
+}
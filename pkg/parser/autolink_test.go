@@ -0,0 +1,42 @@
+package parser
+
+import "testing"
+
+func TestGoldmarkParser_ParseAutolinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"url", "See <https://example.com/a_b>.", "See <https://example.com/a_b>."},
+		{"email", "Mail <user@example.com> now", "Mail <user@example.com> now"},
+		{"bare url", "Visit https://example.com today", "Visit https://example.com today"},
+		{"inside emphasis", "*see <https://example.com>*", "*see <https://example.com>*"},
+	}
+
+	parser := NewGoldmarkParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			para, ok := doc.Children[0].(*Paragraph)
+			if !ok {
+				t.Fatalf("Expected Paragraph, got %T", doc.Children[0])
+			}
+			if para.Text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, para.Text)
+			}
+		})
+	}
+}
+
+func TestAutolink_Markdown(t *testing.T) {
+	if got := (&Autolink{URL: "user@example.com", Email: true}).Markdown(); got != "<user@example.com>" {
+		t.Errorf("Expected angle brackets around email autolink, got %q", got)
+	}
+	if got := (&Autolink{URL: "https://example.com", Bare: true}).Markdown(); got != "https://example.com" {
+		t.Errorf("Expected bare URL unchanged, got %q", got)
+	}
+}
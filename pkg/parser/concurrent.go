@@ -0,0 +1,60 @@
+package parser
+
+import "sync"
+
+// RunsPerWorker is the number of runs SplitBlocks makes per worker, so
+// uneven runs still keep every worker busy
+const RunsPerWorker = 4
+
+// SplitBlocks splits nodes into runs of consecutive nodes of about the same
+// size, for processing top-level blocks on workers goroutines
+func SplitBlocks(nodes []Node, workers int) [][]Node {
+	n := workers * RunsPerWorker
+	if n < 1 {
+		n = 1
+	}
+	if n > len(nodes) {
+		n = len(nodes)
+	}
+	runs := make([][]Node, 0, n)
+	for i := 0; i < n; i++ {
+		runs = append(runs, nodes[i*len(nodes)/n:(i+1)*len(nodes)/n])
+	}
+	return runs
+}
+
+// RunConcurrently calls fn for 0 to n-1 on up to workers goroutines and
+// returns the error of the lowest index that failed. A panic in fn is
+// raised again in the calling goroutine once every call has returned.
+func RunConcurrently(n, workers int, fn func(i int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	errs := make([]error, n)
+	panics := make([]interface{}, n)
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				panics[i] = recover()
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range errs {
+		if panics[i] != nil {
+			panic(panics[i])
+		}
+		if errs[i] != nil {
+			return errs[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSplitBlocks(t *testing.T) {
+	nodes := make([]Node, 10)
+	for i := range nodes {
+		nodes[i] = &Paragraph{}
+	}
+
+	runs := SplitBlocks(nodes, 2)
+	if len(runs) != 2*RunsPerWorker {
+		t.Fatalf("Expected %d runs, got %d", 2*RunsPerWorker, len(runs))
+	}
+	total := 0
+	for _, run := range runs {
+		if len(run) == 0 {
+			t.Error("Expected no empty runs")
+		}
+		for _, node := range run {
+			if node != nodes[total] {
+				t.Fatalf("Expected the runs to keep the nodes in order")
+			}
+			total++
+		}
+	}
+	if total != len(nodes) {
+		t.Errorf("Expected %d nodes in the runs, got %d", len(nodes), total)
+	}
+
+	if runs := SplitBlocks(nodes[:3], 4); len(runs) != 3 {
+		t.Errorf("Expected one run per node when there are few nodes, got %d", len(runs))
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+	err := RunConcurrently(5, 2, func(i int) error {
+		switch i {
+		case 1:
+			return first
+		case 3:
+			return second
+		}
+		return nil
+	})
+	if !errors.Is(err, first) {
+		t.Errorf("Expected the error of the lowest index, got %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected the panic to be raised again, got %v", r)
+		}
+	}()
+	_ = RunConcurrently(3, 2, func(i int) error {
+		if i == 2 {
+			panic("boom")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+package parser
+
+import "testing"
+
+func TestGoldmarkParser_ParseDefinitionList(t *testing.T) {
+	content := []byte(`Apple
+:   A *red* fruit
+
+Orange
+Tangerine
+
+:   A citrus fruit.
+
+    Second paragraph.
+`)
+
+	doc, err := NewGoldmarkParser().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 1 {
+		t.Fatalf("Expected a single definition list, got %s", DebugString(doc))
+	}
+
+	list, ok := doc.Children[0].(*DefinitionList)
+	if !ok {
+		t.Fatalf("Expected DefinitionList, got %T", doc.Children[0])
+	}
+
+	expected := []NodeType{
+		NodeDefinitionTerm, NodeDefinitionDescription,
+		NodeDefinitionTerm, NodeDefinitionTerm, NodeDefinitionDescription,
+	}
+	if len(list.Children) != len(expected) {
+		t.Fatalf("Expected %d children, got %d", len(expected), len(list.Children))
+	}
+	for i, nodeType := range expected {
+		if list.Children[i].Type() != nodeType {
+			t.Errorf("Child %d: expected %s, got %s", i, NodeTypeString(nodeType), list.Children[i])
+		}
+	}
+
+	first := list.Children[1].(*DefinitionDescription)
+	if !first.Tight {
+		t.Error("Expected first description to be tight")
+	}
+	if para, ok := first.Children[0].(*Paragraph); !ok || para.Text != "A *red* fruit" {
+		t.Errorf("Expected description paragraph with inline markup, got %s", first.Children[0])
+	}
+
+	second := list.Children[4].(*DefinitionDescription)
+	if second.Tight || len(second.Children) != 2 {
+		t.Errorf("Expected loose description with two paragraphs, got %s", second)
+	}
+
+	if paragraphs := FindNodes(doc, NodeParagraph); len(paragraphs) != 3 {
+		t.Errorf("Expected walker to reach description paragraphs, found %d", len(paragraphs))
+	}
+}
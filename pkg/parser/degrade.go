@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Diagnostic reports syntax that was recognized but not formatted, or a
+// problem found while formatting
+type Diagnostic struct {
+	// Line is the 1-based source line where the region starts
+	Line int
+	// Feature names the disabled syntax, such as "math", or the problem
+	Feature string
+	// Message describes what happened and how to enable the feature
+	Message string
+}
+
+// String returns the diagnostic as "line N: message"
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("line %d: %s", d.Line, d.Message)
+}
+
+// ErrDisabledSyntax is wrapped by the errors of strict parsers for syntax
+// that is recognized but not enabled
+var ErrDisabledSyntax = errors.New("syntax is not enabled")
+
+// recognizer detects syntax of a feature that is known but disabled, so the
+// region can be copied verbatim instead of being reformatted as plain markdown
+type recognizer struct {
+	feature string
+	hint    string
+	enabled func(Options) bool
+	blocks  []util.PrioritizedValue
+	inlines []util.PrioritizedValue
+}
+
+// esmStatementPattern matches lines that are unambiguously ESM, so prose
+// starting with "import" or "export" is not mistaken for MDX outside the
+// mdx dialect
+var esmStatementPattern = regexp.MustCompile(
+	`^(import\s+(['"]|.+\s+from\s+['"])|export\s+(default|const|let|var|function|class|async|\{|\*))`)
+
+// recognizers is the registry of known-but-disabled syntaxes
+var recognizers = []recognizer{
+	{
+		feature: ExtensionMath,
+		hint:    `add "math" to extensions`,
+		enabled: func(o Options) bool { return o.hasExtension(ExtensionMath) },
+		blocks:  []util.PrioritizedValue{util.Prioritized(&mathBlockParser{}, mathBlockParserPriority)},
+		inlines: []util.PrioritizedValue{util.Prioritized(&mathInlineParser{}, mathInlineParserPriority)},
+	},
+	{
+		feature: DialectMDX,
+		hint:    `set dialect to "mdx"`,
+		enabled: func(o Options) bool { return o.Dialect == DialectMDX },
+		blocks:  []util.PrioritizedValue{util.Prioritized(&mdxRecognizer{}, mdxBlockParserPriority)},
+	},
+	{
+		feature: "wiki link",
+		hint:    `set dialect to "obsidian"`,
+		enabled: func(o Options) bool { return o.Dialect == DialectObsidian },
+		inlines: []util.PrioritizedValue{util.Prioritized(&wikiLinkParser{}, wikiLinkParserPriority)},
+	},
+}
+
+// mdxRecognizer recognizes JSX blocks and unambiguous ESM statements
+// outside the mdx dialect. {...} expressions are left alone, since braces
+// are common in templates and prose.
+type mdxRecognizer struct {
+	mdxBlockParser
+}
+
+// Open implements parser.BlockParser.
+func (b *mdxRecognizer) Open(parent ast.Node, reader text.Reader, pc gmparser.Context) (ast.Node, gmparser.State) {
+	line, _ := reader.PeekLine()
+	if pos := pc.BlockOffset(); pos < 0 || line[pos] == '{' {
+		return nil, gmparser.NoChildren
+	}
+
+	node, state := b.mdxBlockParser.Open(parent, reader, pc)
+	if node != nil && node.(*mdxBlockNode).mdxKind == MDXKindESM && !esmStatementPattern.Match(line) {
+		return nil, gmparser.NoChildren
+	}
+	return node, state
+}
+
+// degradedRegion is the start of a region copied verbatim for a disabled feature
+type degradedRegion struct {
+	offset     int
+	recognizer *recognizer
+}
+
+// degradedRegionsKey stores the degraded regions found while parsing
+var degradedRegionsKey = gmparser.NewContextKey()
+
+// recordDegraded remembers a region found by r at offset
+func recordDegraded(pc gmparser.Context, r *recognizer, offset int) {
+	regions, _ := pc.Get(degradedRegionsKey).([]degradedRegion)
+	pc.Set(degradedRegionsKey, append(regions, degradedRegion{offset: offset, recognizer: r}))
+}
+
+// degradedBlockParser wraps a block parser of a disabled feature and
+// records the regions it recognizes
+type degradedBlockParser struct {
+	gmparser.BlockParser
+	recognizer *recognizer
+}
+
+// Open implements parser.BlockParser.
+func (b *degradedBlockParser) Open(parent ast.Node, reader text.Reader, pc gmparser.Context) (ast.Node, gmparser.State) {
+	_, segment := reader.PeekLine()
+	node, state := b.BlockParser.Open(parent, reader, pc)
+	if node != nil {
+		recordDegraded(pc, b.recognizer, segment.Start)
+	}
+	return node, state
+}
+
+// degradedInlineParser wraps an inline parser of a disabled feature and
+// records the regions it recognizes
+type degradedInlineParser struct {
+	gmparser.InlineParser
+	recognizer *recognizer
+}
+
+// Parse implements parser.InlineParser.
+func (s *degradedInlineParser) Parse(parent ast.Node, block text.Reader, pc gmparser.Context) ast.Node {
+	_, segment := block.PeekLine()
+	node := s.InlineParser.Parse(parent, block, pc)
+	if node != nil {
+		recordDegraded(pc, s.recognizer, segment.Start)
+	}
+	return node
+}
+
+// degradedParsers returns the recognizers of features disabled by opts as
+// goldmark parser options
+func degradedParsers(opts Options) []gmparser.Option {
+	var options []gmparser.Option
+	for i := range recognizers {
+		r := &recognizers[i]
+		if r.enabled(opts) {
+			continue
+		}
+		for _, block := range r.blocks {
+			options = append(options, gmparser.WithBlockParsers(util.Prioritized(
+				&degradedBlockParser{BlockParser: block.Value.(gmparser.BlockParser), recognizer: r}, block.Priority)))
+		}
+		for _, inline := range r.inlines {
+			options = append(options, gmparser.WithInlineParsers(util.Prioritized(
+				&degradedInlineParser{InlineParser: inline.Value.(gmparser.InlineParser), recognizer: r}, inline.Priority)))
+		}
+	}
+	return options
+}
+
+// diagnostics converts the degraded regions recorded in pc into one
+// diagnostic per feature and line, in source order
+func diagnostics(pc gmparser.Context, source []byte) []Diagnostic {
+	regions, _ := pc.Get(degradedRegionsKey).([]degradedRegion)
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].offset < regions[j].offset
+	})
+
+	var result []Diagnostic
+	seen := make(map[string]bool)
+	for _, region := range regions {
+		line := bytes.Count(source[:region.offset], []byte("\n")) + 1
+		key := fmt.Sprintf("%d:%s", line, region.recognizer.feature)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		result = append(result, Diagnostic{
+			Line:    line,
+			Feature: region.recognizer.feature,
+			Message: fmt.Sprintf("%s syntax is not enabled and was copied verbatim; to enable it, %s",
+				region.recognizer.feature, region.recognizer.hint),
+		})
+	}
+	return result
+}
+
+// disabledSyntaxError returns the error of a strict parse for the disabled
+// syntax reported by d
+func disabledSyntaxError(d Diagnostic) *ParseError {
+	err := fmt.Errorf("%s %w", d.Feature, ErrDisabledSyntax)
+	for i := range recognizers {
+		if recognizers[i].feature == d.Feature {
+			err = fmt.Errorf("%s %w; to enable it, %s", d.Feature, ErrDisabledSyntax, recognizers[i].hint)
+		}
+	}
+	return &ParseError{Line: d.Line, Err: err}
+}
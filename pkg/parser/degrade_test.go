@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGoldmarkParser_DegradedSyntax(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		feature  string
+		line     int
+		expected string
+	}{
+		{"display math", "Intro\n\n$$\na  =  b\n$$\n", ExtensionMath, 3, "$$\na  =  b\n$$\n"},
+		{"inline math", "Euler: $e^{i\\pi}  + 1 = 0$ holds", ExtensionMath, 1, "Euler: $e^{i\\pi}  + 1 = 0$ holds"},
+		{"esm", "import Chart from './chart'\n", DialectMDX, 1, "import Chart from './chart'\n"},
+		{"jsx", "<Tabs>\n  <Tab   label=\"a\" />\n</Tabs>\n", DialectMDX, 1, "<Tabs>\n  <Tab   label=\"a\" />\n</Tabs>\n"},
+		{"wiki link", "See [[Some Page|the  page]]", "wiki link", 1, "See [[Some Page|the  page]]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewGoldmarkParser().Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(doc.Diagnostics) != 1 {
+				t.Fatalf("Expected 1 diagnostic, got %v", doc.Diagnostics)
+			}
+			if d := doc.Diagnostics[0]; d.Feature != tt.feature || d.Line != tt.line {
+				t.Errorf("Expected %s diagnostic on line %d, got %+v", tt.feature, tt.line, d)
+			}
+
+			var got string
+			switch n := doc.Children[len(doc.Children)-1].(type) {
+			case *Paragraph:
+				got = n.Text
+			case *Math:
+				got = n.Content
+			case *MDXBlock:
+				got = n.Content
+			default:
+				t.Fatalf("Unexpected node %s", n)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected region verbatim %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGoldmarkParser_DegradedSyntaxEnabled(t *testing.T) {
+	p := NewGoldmarkParserWithOptions(Options{Dialect: DialectObsidian, Extensions: []string{ExtensionMath}})
+	doc, err := p.Parse([]byte("$x$ and [[Page]]\n\n$$\ny\n$$\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics for enabled features, got %v", doc.Diagnostics)
+	}
+}
+
+func TestGoldmarkParser_DegradedSyntaxFalsePositives(t *testing.T) {
+	content := "import the data first\n\n{% include note.html %}\n\nPrices: $5 and $10.\n"
+	doc, err := NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", doc.Diagnostics)
+	}
+}
+
+func TestGoldmarkParser_Strict(t *testing.T) {
+	strict := NewGoldmarkParserWithOptions(Options{Strict: true})
+	_, err := strict.ParseString("Intro\n\n$$\na = b\n$$\n")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !errors.Is(err, ErrDisabledSyntax) {
+		t.Fatalf("Expected a disabled syntax error, got %v", err)
+	}
+	if parseErr.Line != 3 || !strings.Contains(err.Error(), `add "math" to extensions`) {
+		t.Errorf("Expected the math block on line 3 with a hint, got %v", err)
+	}
+
+	enabled := NewGoldmarkParserWithOptions(Options{Strict: true, Extensions: []string{ExtensionMath}})
+	if _, err := enabled.ParseReader(strings.NewReader("$$\na = b\n$$\n")); err != nil {
+		t.Errorf("Expected enabled math to parse, got %v", err)
+	}
+}
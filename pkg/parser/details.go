@@ -0,0 +1,71 @@
+package parser
+
+import "strings"
+
+// groupDetails nests the blocks found between <details> and </details> HTML
+// blocks into Details nodes. GitHub only renders markdown inside a details
+// section when it is separated from the tags by blank lines, in which case
+// goldmark yields the tags and the inner blocks as siblings. A Details node
+// takes the line of its opening tag in lines, which may be nil.
+func groupDetails(nodes []Node, lines map[Node]int) []Node {
+	result := make([]Node, 0, len(nodes))
+
+	for i := 0; i < len(nodes); i++ {
+		open, ok := nodes[i].(*HTMLBlock)
+		if !ok || !isDetailsOpen(open.Content) {
+			result = append(result, nodes[i])
+			continue
+		}
+
+		end := findDetailsClose(nodes, i+1)
+		if end < 0 {
+			result = append(result, nodes[i])
+			continue
+		}
+
+		details := &Details{
+			Open:     open.Content,
+			Close:    nodes[end].(*HTMLBlock).Content,
+			Children: groupDetails(nodes[i+1:end], lines),
+		}
+		moveLine(lines, open, details)
+		result = append(result, details)
+		i = end
+	}
+
+	return result
+}
+
+// findDetailsClose returns the index of the HTML block closing the details
+// section that starts before start, or -1 if it is never closed
+func findDetailsClose(nodes []Node, start int) int {
+	depth := 0
+	for i := start; i < len(nodes); i++ {
+		html, ok := nodes[i].(*HTMLBlock)
+		if !ok {
+			continue
+		}
+		switch {
+		case isDetailsOpen(html.Content):
+			depth++
+		case isDetailsClose(html.Content):
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// isDetailsOpen reports whether an HTML block opens a details section without closing it
+func isDetailsOpen(content string) bool {
+	lower := strings.ToLower(strings.TrimSpace(content))
+	return strings.HasPrefix(lower, "<details") && !strings.Contains(lower, "</details>")
+}
+
+// isDetailsClose reports whether an HTML block consists of a closing details tag
+func isDetailsClose(content string) bool {
+	lower := strings.ToLower(strings.TrimSpace(content))
+	return strings.HasPrefix(lower, "</details>")
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoldmarkParser_ParseDetails(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`<details>
+<summary>Outer</summary>
+
+Inner paragraph.
+
+<details>
+<summary>Nested</summary>
+
+- item
+
+</details>
+
+</details>
+
+After.
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(doc.Children) != 2 {
+		t.Fatalf("Expected details and paragraph at top level, got %s", DebugString(doc))
+	}
+
+	outer, ok := doc.Children[0].(*Details)
+	if !ok {
+		t.Fatalf("Expected Details node, got %T", doc.Children[0])
+	}
+	if !strings.Contains(outer.Open, "<summary>Outer</summary>") {
+		t.Errorf("Expected summary to be kept with opening tag, got %q", outer.Open)
+	}
+	if strings.TrimSpace(outer.Close) != "</details>" {
+		t.Errorf("Expected closing tag, got %q", outer.Close)
+	}
+	if len(outer.Children) != 2 {
+		t.Fatalf("Expected paragraph and nested details inside, got %d children", len(outer.Children))
+	}
+	if _, ok := outer.Children[0].(*Paragraph); !ok {
+		t.Errorf("Expected inner paragraph, got %T", outer.Children[0])
+	}
+
+	nested, ok := outer.Children[1].(*Details)
+	if !ok {
+		t.Fatalf("Expected nested Details node, got %T", outer.Children[1])
+	}
+	if len(nested.Children) != 1 {
+		t.Errorf("Expected nested list, got %d children", len(nested.Children))
+	}
+
+	// The walker visits blocks nested in details sections
+	if lists := FindNodes(doc, NodeList); len(lists) != 1 {
+		t.Errorf("Expected walker to find the nested list, found %d", len(lists))
+	}
+}
+
+func TestGoldmarkParser_ParseDetailsWithoutBlankLines(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte("<details>\n<summary>Raw</summary>\nNot *markdown* here\n</details>\n")
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(doc.Children) != 1 {
+		t.Fatalf("Expected a single node, got %s", DebugString(doc))
+	}
+	html, ok := doc.Children[0].(*HTMLBlock)
+	if !ok {
+		t.Fatalf("Expected raw HTMLBlock, got %T", doc.Children[0])
+	}
+	if html.Content != string(content) {
+		t.Errorf("Expected HTML to be kept verbatim, got %q", html.Content)
+	}
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// maxSnippetLength is the length of the source quoted by a ConversionError
+const maxSnippetLength = 80
+
+// Location formats a source position as path:line:column, leaving out the
+// line and column when they are not known. It returns "" without a path.
+func Location(path string, line, column int) string {
+	if path == "" || line <= 0 {
+		return path
+	}
+	location := path + ":" + strconv.Itoa(line)
+	if column > 0 {
+		location += ":" + strconv.Itoa(column)
+	}
+	return location
+}
+
+// ParseError is an error parsing a markdown document. Path is empty when
+// the document was not read from a file; Line and Column are 0 when the
+// position is not known.
+type ParseError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error returns the error as path:line:column: message
+func (e *ParseError) Error() string {
+	message := "failed to parse markdown: " + e.Err.Error()
+	if location := Location(e.Path, e.Line, e.Column); location != "" {
+		return location + ": " + message
+	}
+	return message
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// ConversionError is a panic recovered while converting a block of the
+// goldmark tree, such as in the NodeConverter of an extension. Parse
+// returns it wrapped in a *ParseError at the line of the block.
+type ConversionError struct {
+	// Kind is the goldmark kind of the block
+	Kind string
+	// Snippet is the start of the source of the block
+	Snippet string
+	// Value is the recovered panic value and Stack the stack trace of the
+	// panicking goroutine
+	Value interface{}
+	Stack []byte
+}
+
+// Error describes the panic and the block it occurred in
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("panic converting %s %q: %v", e.Kind, e.Snippet, e.Value)
+}
+
+// snippet returns the line of source holding offset, cut to maxSnippetLength
+func snippet(source []byte, offset int) string {
+	line := source[bytes.LastIndexByte(source[:offset], '\n')+1:]
+	if end := bytes.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	if len(line) > maxSnippetLength {
+		line = line[:maxSnippetLength]
+	}
+	return string(line)
+}
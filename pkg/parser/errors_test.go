@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		err      *ParseError
+		expected string
+	}{
+		{&ParseError{Err: context.Canceled}, "failed to parse markdown: context canceled"},
+		{&ParseError{Path: "a.md", Err: context.Canceled}, "a.md: failed to parse markdown: context canceled"},
+		{&ParseError{Path: "a.md", Line: 3, Column: 7, Err: context.Canceled},
+			"a.md:3:7: failed to parse markdown: context canceled"},
+	}
+	for _, tt := range tests {
+		if tt.err.Error() != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, tt.err.Error())
+		}
+		if !errors.Is(tt.err, context.Canceled) {
+			t.Errorf("Expected %v to unwrap to context.Canceled", tt.err)
+		}
+	}
+}
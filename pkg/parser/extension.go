@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+)
+
+// MarkdownNode is a node that writes itself back as markdown. Inline nodes
+// returned by a NodeConverter must implement it; block nodes that implement
+// it and are not built-in types are rendered verbatim.
+type MarkdownNode interface {
+	Node
+	Markdown() string
+}
+
+// NodeConverter converts goldmark nodes of custom kinds into mdfmt nodes
+type NodeConverter interface {
+	// Kinds returns the goldmark node kinds handled by the converter
+	Kinds() []ast.NodeKind
+	// Convert converts a block node into any Node, or an inline node into a
+	// MarkdownNode. Returning nil drops the node.
+	Convert(n ast.Node, source []byte) Node
+}
+
+// nodeConverterFunc adapts a function to the NodeConverter interface
+type nodeConverterFunc struct {
+	kinds   []ast.NodeKind
+	convert func(n ast.Node, source []byte) Node
+}
+
+// Kinds implements NodeConverter.
+func (c *nodeConverterFunc) Kinds() []ast.NodeKind { return c.kinds }
+
+// Convert implements NodeConverter.
+func (c *nodeConverterFunc) Convert(n ast.Node, source []byte) Node { return c.convert(n, source) }
+
+// NewNodeConverter creates a NodeConverter from a function handling the given kinds
+func NewNodeConverter(convert func(n ast.Node, source []byte) Node, kinds ...ast.NodeKind) NodeConverter {
+	return &nodeConverterFunc{kinds: kinds, convert: convert}
+}
+
+// registration is an extension registered with RegisterExtension
+type registration struct {
+	extension goldmark.Extender
+	converter NodeConverter
+}
+
+var (
+	registryMu    sync.RWMutex
+	registrations []registration
+)
+
+// RegisterExtension teaches mdfmt a custom syntax: ext is added to every
+// parser created afterwards, and conv converts the goldmark nodes it
+// produces. conv may be nil when the extension only changes how existing
+// syntax is parsed. Converters take precedence over the built-in conversion
+// for their kinds.
+func RegisterExtension(ext goldmark.Extender, conv NodeConverter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registrations = append(registrations, registration{extension: ext, converter: conv})
+}
+
+// registeredExtensions returns the registered extensions and a converter
+// lookup by node kind
+func registeredExtensions() ([]goldmark.Extender, map[ast.NodeKind]NodeConverter) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	extensions := make([]goldmark.Extender, 0, len(registrations))
+	converters := make(map[ast.NodeKind]NodeConverter)
+	for _, r := range registrations {
+		if r.extension != nil {
+			extensions = append(extensions, r.extension)
+		}
+		if r.converter == nil {
+			continue
+		}
+		for _, kind := range r.converter.Kinds() {
+			converters[kind] = r.converter
+		}
+	}
+	return extensions, converters
+}
+
+// writeCustomInline writes an inline node handled by a registered converter
+// and reports whether it did
+func (p *GoldmarkParser) writeCustomInline(buf *bytes.Buffer, n ast.Node, source []byte) bool {
+	conv, ok := p.converters[n.Kind()]
+	if !ok {
+		return false
+	}
+	if node, ok := conv.Convert(n, source).(MarkdownNode); ok {
+		buf.WriteString(node.Markdown())
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var kindShortcode = ast.NewNodeKind("Shortcode")
+
+// shortcodeNode is a {{< name >}} shortcode used to exercise RegisterExtension
+type shortcodeNode struct {
+	ast.BaseInline
+	value []byte
+}
+
+func (n *shortcodeNode) Kind() ast.NodeKind { return kindShortcode }
+
+func (n *shortcodeNode) Dump(source []byte, level int) { ast.DumpHelper(n, source, level, nil, nil) }
+
+type shortcodeParser struct{}
+
+func (s *shortcodeParser) Trigger() []byte { return []byte{'{'} }
+
+func (s *shortcodeParser) Parse(_ ast.Node, block text.Reader, _ gmparser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	end := bytes.Index(line, []byte(">}}"))
+	if !bytes.HasPrefix(line, []byte("{{<")) || end < 0 {
+		return nil
+	}
+	block.Advance(end + 3)
+	return &shortcodeNode{value: line[:end+3]}
+}
+
+type shortcodeExtension struct{}
+
+func (e *shortcodeExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(gmparser.WithInlineParsers(util.Prioritized(&shortcodeParser{}, 100)))
+}
+
+// shortcode is the mdfmt node for shortcodes
+type shortcode struct{ value string }
+
+func (n *shortcode) Type() NodeType   { return NodeCustom }
+func (n *shortcode) String() string   { return "Shortcode" }
+func (n *shortcode) Markdown() string { return n.value }
+
+func TestRegisterExtension(t *testing.T) {
+	saved := registrations
+	t.Cleanup(func() { registrations = saved })
+
+	RegisterExtension(&shortcodeExtension{}, NewNodeConverter(func(n ast.Node, _ []byte) Node {
+		return &shortcode{value: string(n.(*shortcodeNode).value)}
+	}, kindShortcode))
+
+	doc, err := NewGoldmarkParser().Parse([]byte("See {{<   ref  \"a_b_c\" >}} *now*"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	para, ok := doc.Children[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("Expected Paragraph, got %T", doc.Children[0])
+	}
+	if expected := `See {{<   ref  "a_b_c" >}} *now*`; para.Text != expected {
+		t.Errorf("Expected %q, got %q", expected, para.Text)
+	}
+}
+
+func TestRegisterExtension_BlockConverter(t *testing.T) {
+	saved := registrations
+	t.Cleanup(func() { registrations = saved })
+
+	// Converters may also take over built-in kinds
+	RegisterExtension(nil, NewNodeConverter(func(n ast.Node, source []byte) Node {
+		return &shortcode{value: "---"}
+	}, ast.KindThematicBreak))
+
+	doc, err := NewGoldmarkParser().Parse([]byte("a\n\n***\n\nb\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected 3 blocks, got %s", DebugString(doc))
+	}
+	if node, ok := doc.Children[1].(MarkdownNode); !ok || node.Markdown() != "---" {
+		t.Errorf("Expected converted thematic break, got %v", doc.Children[1])
+	}
+}
+
+func TestRegisterExtension_ConverterPanic(t *testing.T) {
+	saved := registrations
+	t.Cleanup(func() { registrations = saved })
+
+	RegisterExtension(nil, NewNodeConverter(func(n ast.Node, source []byte) Node {
+		panic("broken converter")
+	}, ast.KindHeading))
+
+	_, err := NewGoldmarkParser().Parse([]byte("a\n\n## Title\n\nb\n"))
+	var parseErr *ParseError
+	var conversionErr *ConversionError
+	if !errors.As(err, &parseErr) || !errors.As(err, &conversionErr) {
+		t.Fatalf("Expected a conversion error, got %v", err)
+	}
+	if parseErr.Line != 3 || conversionErr.Snippet != "## Title" || conversionErr.Value != "broken converter" {
+		t.Errorf("Expected the panic located at line 3, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"bytes"
+)
+
+// Front matter delimiters
+const (
+	// FrontMatterYAML delimits YAML front matter
+	FrontMatterYAML = "---"
+	// FrontMatterTOML delimits TOML front matter, as used by Hugo
+	FrontMatterTOML = "+++"
+)
+
+// SplitFrontMatter splits content into its front matter block, including
+// the delimiter lines, and the rest of the document. frontMatter is empty
+// when content does not start with a front matter block. YAML front matter
+// may be closed with "..." instead of "---".
+func SplitFrontMatter(content []byte) (frontMatter, body []byte) {
+	first, rest, ok := cutLine(content)
+	if !ok {
+		return nil, content
+	}
+	delimiter := string(first)
+	if delimiter != FrontMatterYAML && delimiter != FrontMatterTOML {
+		return nil, content
+	}
+
+	end := len(content) - len(rest)
+	for len(rest) > 0 {
+		line, next, _ := cutLine(rest)
+		end += len(rest) - len(next)
+		rest = next
+		if string(line) == delimiter || (delimiter == FrontMatterYAML && string(line) == "...") {
+			return content[:end], content[end:]
+		}
+	}
+	return nil, content // unterminated: not front matter
+}
+
+// FrontMatterData returns the YAML front matter of content without its
+// delimiters, and whether content has YAML front matter
+func FrontMatterData(content []byte) ([]byte, bool) {
+	frontMatter, _ := SplitFrontMatter(content)
+	if !bytes.HasPrefix(frontMatter, []byte(FrontMatterYAML)) {
+		return nil, false
+	}
+	_, data, _ := cutLine(frontMatter)
+	if i := bytes.LastIndexByte(bytes.TrimRight(data, "\r\n"), '\n'); i >= 0 {
+		return data[:i+1], true
+	}
+	return nil, true // empty front matter
+}
+
+// cutLine returns the first line of content without its line ending, and
+// the content after it. ok is false if content has no complete line.
+func cutLine(content []byte) (line, rest []byte, ok bool) {
+	i := bytes.IndexByte(content, '\n')
+	if i < 0 {
+		return bytes.TrimRight(content, "\r"), nil, false
+	}
+	return bytes.TrimRight(content[:i], "\r"), content[i+1:], true
+}
+
+// blankFrontMatter returns a copy of content with its first n bytes
+// replaced by blank lines, so the front matter is skipped by the markdown
+// parser while source offsets stay unchanged
+func blankFrontMatter(content []byte, n int) []byte {
+	blanked := make([]byte, len(content))
+	copy(blanked, content)
+	for i := 0; i < n; i++ {
+		if blanked[i] != '\n' && blanked[i] != '\r' {
+			blanked[i] = ' '
+		}
+	}
+	return blanked
+}
@@ -0,0 +1,60 @@
+package parser
+
+import "testing"
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		frontMatter string
+	}{
+		{"yaml", "---\ntitle: Hi\n---\n# Title\n", "---\ntitle: Hi\n---\n"},
+		{"yaml dots", "---\ntitle: Hi\n...\nText\n", "---\ntitle: Hi\n...\n"},
+		{"toml", "+++\ntitle = \"Hi\"\n+++\nText\n", "+++\ntitle = \"Hi\"\n+++\n"},
+		{"crlf", "---\r\ntitle: Hi\r\n---\r\nText\r\n", "---\r\ntitle: Hi\r\n---\r\n"},
+		{"only front matter", "---\ntitle: Hi\n---", "---\ntitle: Hi\n---"},
+		{"unterminated", "---\ntitle: Hi\n", ""},
+		{"not at start", "Text\n---\ntitle: Hi\n---\n", ""},
+		{"thematic break", "***\nText\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frontMatter, body := SplitFrontMatter([]byte(tt.content))
+			if string(frontMatter) != tt.frontMatter {
+				t.Errorf("Expected front matter %q, got %q", tt.frontMatter, frontMatter)
+			}
+			if string(frontMatter)+string(body) != tt.content {
+				t.Errorf("Expected front matter and body to make up the content, got %q + %q", frontMatter, body)
+			}
+		})
+	}
+}
+
+func TestFrontMatterData(t *testing.T) {
+	data, ok := FrontMatterData([]byte("---\ntitle: Hi\nmdfmt:\n  line_width: 120\n---\nText\n"))
+	if !ok || string(data) != "title: Hi\nmdfmt:\n  line_width: 120\n" {
+		t.Errorf("Expected the YAML between the delimiters, got %q (%v)", data, ok)
+	}
+
+	if _, ok := FrontMatterData([]byte("+++\ntitle = \"Hi\"\n+++\n")); ok {
+		t.Error("Expected TOML front matter not to be reported as YAML")
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	content := "---\ntitle: Hi\n---\n# Title\n"
+	doc, err := NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if doc.FrontMatter != "---\ntitle: Hi\n---\n" {
+		t.Errorf("Expected the front matter block, got %q", doc.FrontMatter)
+	}
+	if len(doc.Children) != 1 {
+		t.Fatalf("Expected only the heading, got %d children", len(doc.Children))
+	}
+	if heading, ok := doc.Children[0].(*Heading); !ok || heading.Text != "Title" {
+		t.Errorf("Expected heading Title, got %v", doc.Children[0])
+	}
+}
@@ -2,12 +2,18 @@ package parser
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"regexp"
+	"runtime/debug"
+	"strconv"
 	"strings"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
 	gmparser "github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 )
@@ -16,60 +22,238 @@ import (
 const (
 	// StrongEmphasisLevel defines the level for strong emphasis (**)
 	StrongEmphasisLevel = 2
+	// minFenceLength is the shortest code fence
+	minFenceLength = 3
 )
 
+// orderedMarkerPattern matches the number and delimiter of an ordered list
+// item at the end of the text before its content
+var orderedMarkerPattern = regexp.MustCompile(`([0-9]{1,9})([.)])[ \t]*$`)
+
 // GoldmarkParser implements the Parser interface using goldmark
 type GoldmarkParser struct {
 	markdown goldmark.Markdown
+	// converters holds the converters of registered extensions by node kind
+	converters map[ast.NodeKind]NodeConverter
+	// positions is set when block lines are recorded
+	positions bool
+	// strict is set when disabled syntax fails parsing
+	strict bool
+	// lines and index record block lines during a parse; they are only set
+	// on the copy of the parser a parse with positions converts with
+	lines map[Node]int
+	index lineIndex
 }
 
 // NewGoldmarkParser creates a new goldmark-based parser
 func NewGoldmarkParser() *GoldmarkParser {
+	return NewGoldmarkParserWithOptions(Options{})
+}
+
+// NewGoldmarkParserWithOptions creates a new goldmark-based parser with optional extensions
+func NewGoldmarkParserWithOptions(opts Options) *GoldmarkParser {
+	extensions := []goldmark.Extender{
+		&autolinkExtension{}, // Tell <...> autolinks from bare URLs
+	}
+	if opts.Dialect != DialectCommonMark {
+		extensions = append(extensions,
+			extension.GFM,            // GitHub Flavored Markdown
+			extension.Table,          // Tables support
+			extension.Strikethrough,  // Strikethrough support
+			extension.TaskList,       // Task lists support
+			extension.DefinitionList, // Definition lists (PHP Markdown Extra)
+		)
+	}
+	if opts.hasExtension(ExtensionMath) {
+		extensions = append(extensions, &mathExtension{})
+	}
+	if opts.hasExtension(ExtensionHighlight) {
+		extensions = append(extensions, &highlightExtension{})
+	}
+	switch opts.Dialect {
+	case DialectMDX:
+		extensions = append(extensions, &mdxExtension{})
+	case DialectObsidian:
+		extensions = append(extensions, &obsidianExtension{})
+	}
+	custom, converters := registeredExtensions()
+	extensions = append(extensions, custom...)
+
 	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,           // GitHub Flavored Markdown
-			extension.Table,         // Tables support
-			extension.Strikethrough, // Strikethrough support
-			extension.TaskList,      // Task lists support
-		),
+		goldmark.WithParser(gmparser.NewParser(
+			gmparser.WithBlockParsers(gmparser.DefaultBlockParsers()...),
+			gmparser.WithInlineParsers(inlineParsers()...),
+			gmparser.WithParagraphTransformers(gmparser.DefaultParagraphTransformers()...),
+		)),
+		goldmark.WithExtensions(extensions...),
 		goldmark.WithParserOptions(
 			gmparser.WithAutoHeadingID(), // Auto-generate heading IDs
 		),
+		// Copy known syntax of disabled features verbatim
+		goldmark.WithParserOptions(degradedParsers(opts)...),
+		withSourceHTML(converters),
 	)
 
 	return &GoldmarkParser{
-		markdown: md,
+		markdown:   md,
+		converters: converters,
+		positions:  opts.Positions,
+		strict:     opts.Strict,
 	}
 }
 
 // Parse parses the given markdown content and returns an AST
 func (p *GoldmarkParser) Parse(content []byte) (*Document, error) {
+	return p.ParseContext(context.Background(), content)
+}
+
+// ParseString parses markdown source like Parse
+func (p *GoldmarkParser) ParseString(source string) (*Document, error) {
+	return p.Parse([]byte(source))
+}
+
+// ParseReader parses the markdown read from r like Parse
+func (p *GoldmarkParser) ParseReader(r io.Reader) (*Document, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read markdown: %w", err)
+	}
+	return p.Parse(content)
+}
+
+// RenderHTML renders content to HTML with goldmark and the syntax the
+// parser enables, the way sites built from the document would show it.
+// Front matter is left out.
+func (p *GoldmarkParser) RenderHTML(w io.Writer, content []byte) error {
+	if frontMatter, _ := SplitFrontMatter(content); len(frontMatter) > 0 {
+		content = blankFrontMatter(content, len(frontMatter))
+	}
+	if err := p.markdown.Convert(content, w); err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return nil
+}
+
+// ParseContext parses content like Parse, returning ctx.Err() if ctx is done
+// before the document has been converted
+func (p *GoldmarkParser) ParseContext(ctx context.Context, content []byte) (*Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	frontMatter, _ := SplitFrontMatter(content)
+	noFinalNewline := !EndsWithLineBreak(content)
+	if len(frontMatter) > 0 {
+		content = blankFrontMatter(content, len(frontMatter))
+	}
+
 	// Parse with goldmark
 	reader := text.NewReader(content)
-	doc := p.markdown.Parser().Parse(reader)
-
-	// Convert goldmark AST to our AST
-	ourDoc := &Document{
-		Children: make([]Node, 0),
+	pc := gmparser.NewContext()
+	doc := p.markdown.Parser().Parse(reader, gmparser.WithContext(pc))
+
+	// Record block lines with a copy of the parser, which may be in use by
+	// other parses
+	if p.positions {
+		positioned := *p
+		positioned.lines = make(map[Node]int)
+		positioned.index = newLineIndex(content)
+		p = &positioned
 	}
 
 	// Walk through goldmark AST and convert only top-level nodes
+	blocks := make([]positionedNode, 0)
+	offset := 0
 	for child := doc.FirstChild(); child != nil; child = child.NextSibling() {
-		ourNode := p.convertNode(child, content)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if childOffset := sourceOffset(child); childOffset >= 0 {
+			offset = childOffset
+		}
+		ourNode, err := p.convertBlock(child, content, offset)
+		if err != nil {
+			return nil, err
+		}
 		if ourNode != nil {
-			ourDoc.Children = append(ourDoc.Children, ourNode)
+			blocks = append(blocks, positionedNode{offset: offset, node: ourNode})
+			if p.lines != nil {
+				p.lines[ourNode] = p.index.blockLine(child, offset)
+			}
 		}
 	}
 
+	// Convert goldmark AST to our AST, keeping link reference definitions
+	// that goldmark removes from the tree
+	definitions := linkDefinitions(pc, content)
+	ourDoc := &Document{
+		FrontMatter:     string(frontMatter),
+		Children:        mergeDefinitions(blocks, definitions),
+		NoFinalNewline:  noFinalNewline,
+		Diagnostics:     diagnostics(pc, content),
+		lines:           p.lines,
+		definitionLines: definitionLines(definitions, content),
+	}
+	if p.strict && len(ourDoc.Diagnostics) > 0 {
+		return nil, disabledSyntaxError(ourDoc.Diagnostics[0])
+	}
+	if p.lines != nil {
+		recordDefinitionLines(p.lines, ourDoc.Children, definitions, p.index)
+	}
+	ourDoc.Children = groupMDX(groupDetails(ourDoc.Children, p.lines), p.lines)
+
 	return ourDoc, nil
 }
 
-// convertNode converts a goldmark AST node to our AST node
+// convertBlock converts a top-level block like convertNode, returning a
+// panic during the conversion as a *ConversionError at the line of offset
+func (p *GoldmarkParser) convertBlock(n ast.Node, source []byte, offset int) (node Node, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &ParseError{
+				Line: newLineIndex(source).line(offset),
+				Err: &ConversionError{
+					Kind:    n.Kind().String(),
+					Snippet: snippet(source, offset),
+					Value:   r,
+					Stack:   debug.Stack(),
+				},
+			}
+		}
+	}()
+	return p.convertNode(n, source), nil
+}
+
+// convertNode converts a goldmark AST node to our AST node, recording its
+// line when lines are recorded
 func (p *GoldmarkParser) convertNode(n ast.Node, source []byte) Node {
+	node := p.convertNodeKind(n, source)
+	if node != nil {
+		p.recordLine(node, n)
+	}
+	return node
+}
+
+// recordLine records the line of the goldmark block n as the line of node,
+// when lines are recorded
+func (p *GoldmarkParser) recordLine(node Node, n ast.Node) {
+	if p.lines == nil {
+		return
+	}
+	if offset := sourceOffset(n); offset >= 0 {
+		p.lines[node] = p.index.blockLine(n, offset)
+	}
+}
+
+// convertNodeKind converts a goldmark AST node by its kind
+func (p *GoldmarkParser) convertNodeKind(n ast.Node, source []byte) Node {
+	if conv, ok := p.converters[n.Kind()]; ok {
+		return conv.Convert(n, source)
+	}
+
 	switch n.Kind() {
 	case ast.KindHeading:
 		return p.convertHeading(n, source)
-	case ast.KindParagraph:
+	case ast.KindParagraph, ast.KindTextBlock:
 		return p.convertParagraph(n, source)
 	case ast.KindList:
 		return p.convertList(n, source)
@@ -77,27 +261,169 @@ func (p *GoldmarkParser) convertNode(n ast.Node, source []byte) Node {
 		return p.convertCodeBlock(n, source)
 	case ast.KindText, ast.KindString:
 		return p.convertText(n, source)
+	case ast.KindHTMLBlock:
+		return p.convertHTMLBlock(n, source)
+	case ast.KindBlockquote:
+		return p.convertBlockquote(n, source)
+	case extast.KindTable:
+		return p.convertTable(n, source)
+	case extast.KindDefinitionList:
+		return p.convertDefinitionList(n, source)
+	case KindMDXBlock:
+		return p.convertMDXBlock(n, source)
+	case KindMathBlock:
+		return p.convertMathBlock(n, source)
 	default:
 		return p.convertGenericNode(n, source)
 	}
 }
 
+// convertTable converts a GFM table, keeping the source of its cells
+func (p *GoldmarkParser) convertTable(n ast.Node, source []byte) Node {
+	table := &Table{}
+	for _, alignment := range n.(*extast.Table).Alignments {
+		switch alignment {
+		case extast.AlignLeft:
+			table.Alignments = append(table.Alignments, AlignLeft)
+		case extast.AlignCenter:
+			table.Alignments = append(table.Alignments, AlignCenter)
+		case extast.AlignRight:
+			table.Alignments = append(table.Alignments, AlignRight)
+		default:
+			table.Alignments = append(table.Alignments, "")
+		}
+	}
+
+	// The header holds its cells directly, like the rows after it
+	for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+		cells := make([]string, 0, len(table.Alignments))
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			var buf bytes.Buffer
+			lines := cell.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				buf.Write(segment.Value(source))
+			}
+			cells = append(cells, buf.String())
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	return table
+}
+
+// convertDefinitionList converts a definition list with its terms and descriptions
+func (p *GoldmarkParser) convertDefinitionList(n ast.Node, source []byte) Node {
+	list := &DefinitionList{
+		Children: make([]Node, 0),
+	}
+
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch item := child.(type) {
+		case *extast.DefinitionTerm:
+			list.Children = append(list.Children, &DefinitionTerm{
+				Text: p.extractParagraphText(item, source),
+			})
+		case *extast.DefinitionDescription:
+			description := &DefinitionDescription{
+				Tight:    item.IsTight,
+				Children: make([]Node, 0),
+			}
+			for block := item.FirstChild(); block != nil; block = block.NextSibling() {
+				if ourNode := p.convertNode(block, source); ourNode != nil {
+					description.Children = append(description.Children, ourNode)
+				}
+			}
+			list.Children = append(list.Children, description)
+		}
+	}
+	return list
+}
+
+// convertMDXBlock converts a raw MDX region, keeping its lines verbatim
+func (p *GoldmarkParser) convertMDXBlock(n ast.Node, source []byte) Node {
+	var buf bytes.Buffer
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	mdx := n.(*mdxBlockNode)
+	return &MDXBlock{
+		Kind:     mdx.mdxKind,
+		Content:  buf.String(),
+		fragment: mdx.fragment,
+	}
+}
+
+// convertMathBlock converts display math, keeping its lines verbatim
+func (p *GoldmarkParser) convertMathBlock(n ast.Node, source []byte) Node {
+	var buf bytes.Buffer
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	return &Math{
+		Content: buf.String(),
+	}
+}
+
+// convertBlockquote converts a blockquote node, recognizing GitHub alerts
+func (p *GoldmarkParser) convertBlockquote(n ast.Node, source []byte) Node {
+	children := make([]Node, 0)
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if ourNode := p.convertNode(child, source); ourNode != nil {
+			children = append(children, ourNode)
+		}
+	}
+	children = groupMDX(groupDetails(children, p.lines), p.lines)
+
+	if admonition := asAdmonition(children); admonition != nil {
+		return admonition
+	}
+	return &Blockquote{
+		Children: children,
+	}
+}
+
+// convertHTMLBlock converts a raw HTML block, keeping its lines verbatim
+func (p *GoldmarkParser) convertHTMLBlock(n ast.Node, source []byte) Node {
+	html := n.(*ast.HTMLBlock)
+	var buf bytes.Buffer
+	for i := 0; i < html.Lines().Len(); i++ {
+		line := html.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	if html.HasClosure() {
+		buf.Write(html.ClosureLine.Value(source))
+	}
+	return &HTMLBlock{
+		Content: buf.String(),
+	}
+}
+
 // convertHeading converts a heading node
 func (p *GoldmarkParser) convertHeading(n ast.Node, source []byte) Node {
 	heading := n.(*ast.Heading)
-	headingText := p.extractText(n, source)
+	headingText := p.extractParagraphText(n, source)
 	headingText = strings.Join(strings.Fields(headingText), " ")
+	headingText, attributes := splitHeadingAttributes(headingText)
 	return &Heading{
-		Level: heading.Level,
-		Text:  strings.TrimSpace(headingText),
-		Style: "atx",
+		Level:      heading.Level,
+		Text:       strings.TrimSpace(headingText),
+		Style:      "atx",
+		Attributes: attributes,
 	}
 }
 
 // convertParagraph converts a paragraph node
 func (p *GoldmarkParser) convertParagraph(n ast.Node, source []byte) Node {
+	// Paragraphs made only of link reference definitions are left empty
+	if n.Lines().Len() == 0 {
+		return nil
+	}
+	next := n.NextSibling()
 	return &Paragraph{
-		Text: p.extractText(n, source),
+		Text:        p.extractParagraphText(n, source),
+		Interrupted: next != nil && next.Kind() == ast.KindList && !next.HasBlankPreviousLines(),
 	}
 }
 
@@ -108,31 +434,60 @@ func (p *GoldmarkParser) convertList(n ast.Node, source []byte) Node {
 		Ordered: list.IsOrdered(),
 		Items:   make([]*ListItem, 0),
 		Marker:  p.getListMarker(list),
+		Loose:   !list.IsTight,
+	}
+	if list.IsOrdered() {
+		ourList.Start = list.Start
+	}
+	if offset := sourceOffset(n); offset >= 0 {
+		ourList.TabIndented = tabIndented(source, offset)
 	}
 
 	for child := list.FirstChild(); child != nil; child = child.NextSibling() {
 		if child.Kind() == ast.KindListItem {
 			item := p.convertListItem(child, source)
+			item.Marker = p.getListItemMarker(list, child.(*ast.ListItem), len(ourList.Items), source)
 			ourList.Items = append(ourList.Items, item)
 		}
 	}
 	return ourList
 }
 
-// convertListItem converts a list item node
+// tabIndented reports whether the line holding offset is indented with tabs
+func tabIndented(source []byte, offset int) bool {
+	start := bytes.LastIndexByte(source[:offset], '\n') + 1
+	for _, c := range source[start:offset] {
+		switch c {
+		case '\t':
+			return true
+		case ' ':
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+// convertListItem converts a list item node. The paragraph on the marker
+// line becomes the item text; continuation paragraphs, code blocks, nested
+// lists and other blocks become its children.
 func (p *GoldmarkParser) convertListItem(n ast.Node, source []byte) *ListItem {
 	item := &ListItem{
-		Text:     p.extractText(n, source),
-		Marker:   p.getListItemMarker(n.(*ast.ListItem)),
 		Children: make([]Node, 0),
 	}
+	p.recordLine(item, n)
 
-	for nestedChild := n.FirstChild(); nestedChild != nil; nestedChild = nestedChild.NextSibling() {
-		if nestedChild.Kind() == ast.KindList {
-			nestedList := p.convertNode(nestedChild, source)
-			if nestedList != nil {
-				item.Children = append(item.Children, nestedList)
-			}
+	child := n.FirstChild()
+	if child != nil && (child.Kind() == ast.KindParagraph || child.Kind() == ast.KindTextBlock) {
+		if box, ok := child.FirstChild().(*extast.TaskCheckBox); ok {
+			item.Task, item.Checked = true, box.IsChecked
+		}
+		item.Text = strings.TrimSpace(p.extractParagraphText(child, source))
+		child = child.NextSibling()
+	}
+	for ; child != nil; child = child.NextSibling() {
+		if node := p.convertNode(child, source); node != nil {
+			item.Children = append(item.Children, node)
 		}
 	}
 	return item
@@ -159,11 +514,40 @@ func (p *GoldmarkParser) extractCodeBlockInfo(n ast.Node, source []byte, code *C
 		code.Language = string(fenced.Language(source))
 	}
 	if fenced.Info != nil {
-		info := string(fenced.Info.Value(source))
-		if strings.HasPrefix(info, "~~~") {
-			code.Fence = "~~~"
+		code.Info = strings.TrimSpace(string(fenced.Info.Segment.Value(source)))
+	}
+	if fence := fenceOf(fenced, source); fence != "" {
+		code.Fence = fence
+	}
+}
+
+// fenceOf returns the opening fence of a fenced code block as written in
+// source, such as "~~~" or "````", or "" if it cannot be located: the fence
+// line is the line of the info string, or the line before the content
+func fenceOf(fenced *ast.FencedCodeBlock, source []byte) string {
+	var end int
+	switch {
+	case fenced.Info != nil:
+		end = fenced.Info.Segment.Start
+	case fenced.Lines().Len() > 0:
+		end = bytes.LastIndexByte(source[:fenced.Lines().At(0).Start], '\n')
+		if end < 0 {
+			return ""
 		}
+	default:
+		return ""
+	}
+	start := bytes.LastIndexByte(source[:end], '\n') + 1
+	line := bytes.TrimRight(source[start:end], " \t\r")
+	if len(line) == 0 || (line[len(line)-1] != '`' && line[len(line)-1] != '~') {
+		return ""
 	}
+	char := line[len(line)-1]
+	n := len(line) - len(bytes.TrimRight(line, string(char)))
+	if n < minFenceLength {
+		return ""
+	}
+	return strings.Repeat(string(char), n)
 }
 
 // convertText converts a text/string node
@@ -184,25 +568,33 @@ func (p *GoldmarkParser) convertGenericNode(n ast.Node, source []byte) Node {
 	return nil
 }
 
-// getListMarker determines the list marker from a goldmark list
+// getListMarker returns the marker of a goldmark list: its bullet, or the
+// delimiter of an ordered list
 func (p *GoldmarkParser) getListMarker(list *ast.List) string {
-	if list.IsOrdered() {
-		return "."
+	if list.Marker == 0 {
+		if list.IsOrdered() {
+			return "."
+		}
+		return "-"
 	}
-	return "-" // Default bullet
+	return string(list.Marker)
 }
 
-// getListItemMarker determines the list item marker
-func (p *GoldmarkParser) getListItemMarker(item *ast.ListItem) string {
-	// Check if this is part of an ordered list
-	if parent := item.Parent(); parent != nil && parent.Kind() == ast.KindList {
-		list := parent.(*ast.List)
-		if list.IsOrdered() {
-			// For ordered lists, we'll let the formatter handle the numbering
-			return "1."
+// getListItemMarker returns the marker of the item of list at index: the
+// bullet of the list, or the number and delimiter written in source,
+// counting on from the start of the list if it cannot be found
+func (p *GoldmarkParser) getListItemMarker(list *ast.List, item *ast.ListItem, index int, source []byte) string {
+	marker := p.getListMarker(list)
+	if !list.IsOrdered() {
+		return marker
+	}
+	if offset := sourceOffset(item); offset >= 0 {
+		line := source[bytes.LastIndexByte(source[:offset], '\n')+1 : offset]
+		if m := orderedMarkerPattern.FindSubmatch(line); m != nil && string(m[2]) == marker {
+			return string(m[1]) + marker
 		}
 	}
-	return "-" // Default bullet for unordered lists
+	return strconv.Itoa(list.Start+index) + marker
 }
 
 // extractText extracts the text content from a goldmark AST node
@@ -285,17 +677,23 @@ func (p *GoldmarkParser) extractListItemText(n ast.Node, source []byte) string {
 // extractWithInlineFormatting extracts text preserving inline formatting
 func (p *GoldmarkParser) extractWithInlineFormatting(n ast.Node, source []byte) string {
 	var buf bytes.Buffer
+	if p.writeCustomInline(&buf, n, source) {
+		return buf.String()
+	}
 
 	switch n.Kind() {
 	case ast.KindText:
-		textNode := n.(*ast.Text)
-		buf.Write(textNode.Segment.Value(source))
+		writeTextSegment(&buf, n.(*ast.Text), source)
 	case ast.KindEmphasis:
 		p.extractEmphasisText(n, source, &buf)
 	case ast.KindCodeSpan:
 		p.extractCodeSpanText(n, source, &buf)
-	case ast.KindLink:
+	case ast.KindLink, ast.KindImage:
 		p.extractLinkText(n, source, &buf)
+	case KindMathInline, KindWikiLink, KindTag, ast.KindAutoLink, ast.KindRawHTML:
+		writeInlineLeaf(&buf, n, source)
+	case extast.KindStrikethrough, KindHighlight:
+		p.extractMarkText(n, source, &buf)
 	default:
 		// For container nodes, process children with inline formatting
 		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
@@ -312,16 +710,22 @@ func (p *GoldmarkParser) extractParagraphText(n ast.Node, source []byte) string
 	var buf bytes.Buffer
 
 	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if p.writeCustomInline(&buf, child, source) {
+			continue
+		}
 		switch child.Kind() {
 		case ast.KindText:
-			childText := p.extractText(child, source)
-			buf.WriteString(childText)
+			writeTextSegment(&buf, child.(*ast.Text), source)
 		case ast.KindEmphasis:
 			p.extractEmphasisText(child, source, &buf)
 		case ast.KindCodeSpan:
 			p.extractCodeSpanText(child, source, &buf)
-		case ast.KindLink:
+		case ast.KindLink, ast.KindImage:
 			p.extractLinkText(child, source, &buf)
+		case KindMathInline, KindWikiLink, KindTag, ast.KindAutoLink, ast.KindRawHTML:
+			writeInlineLeaf(&buf, child, source)
+		case extast.KindStrikethrough, KindHighlight:
+			p.extractMarkText(child, source, &buf)
 		default:
 			childText := p.extractText(child, source)
 			buf.WriteString(childText)
@@ -330,33 +734,108 @@ func (p *GoldmarkParser) extractParagraphText(n ast.Node, source []byte) string
 	return strings.TrimSpace(buf.String())
 }
 
-// extractEmphasisText extracts text from emphasis nodes with markers
+// extractEmphasisText extracts text from emphasis nodes with markers,
+// keeping the marker character of the source and any emphasis nested in
+// them
 func (p *GoldmarkParser) extractEmphasisText(n ast.Node, source []byte, buf *bytes.Buffer) {
 	emph := n.(*ast.Emphasis)
-	marker := "*"
-	if emph.Level == StrongEmphasisLevel {
-		marker = "**"
-	}
+	marker := strings.Repeat(string(emphasisMarker(emph, source)), emph.Level)
 	buf.WriteString(marker)
-	buf.WriteString(p.extractTextRecursive(n, source))
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		buf.WriteString(p.extractWithInlineFormatting(child, source))
+	}
 	buf.WriteString(marker)
 }
 
+// emphasisMarker returns the character emph is written with: * or _. Its
+// delimiters end right before the first text of emph, after those of the
+// emphasis nested at its start. Emphasis starting with other inline nodes
+// is taken to be written with *.
+func emphasisMarker(emph *ast.Emphasis, source []byte) byte {
+	offset := 0
+	var node ast.Node = emph
+	for {
+		nested, ok := node.(*ast.Emphasis)
+		if !ok {
+			break
+		}
+		offset += nested.Level
+		node = nested.FirstChild()
+	}
+	if t, ok := node.(*ast.Text); ok && t.Segment.Start >= offset && source[t.Segment.Start-offset] == '_' {
+		return '_'
+	}
+	return '*'
+}
+
+// extractMarkText extracts strikethrough and highlight marks with their markers
+func (p *GoldmarkParser) extractMarkText(n ast.Node, source []byte, buf *bytes.Buffer) {
+	var inner bytes.Buffer
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		inner.WriteString(p.extractWithInlineFormatting(child, source))
+	}
+
+	if n.Kind() == KindHighlight {
+		buf.WriteString((&Highlight{Text: inner.String()}).Markdown())
+		return
+	}
+	buf.WriteString((&Strikethrough{Marker: strikethroughMarker(n, source), Text: inner.String()}).Markdown())
+}
+
 // extractCodeSpanText extracts text from inline code with backticks
 func (p *GoldmarkParser) extractCodeSpanText(n ast.Node, source []byte, buf *bytes.Buffer) {
-	buf.WriteString("`")
-	buf.WriteString(p.extractTextRecursive(n, source))
-	buf.WriteString("`")
+	var segments []text.Segment
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if t, ok := child.(*ast.Text); ok {
+			segments = append(segments, t.Segment)
+		}
+	}
+	if len(segments) == 0 {
+		buf.WriteString("`")
+		buf.WriteString(p.extractTextRecursive(n, source))
+		buf.WriteString("`")
+		return
+	}
+
+	// Keep the backtick strings and padding as written: the spaces padding
+	// the content are significant when it starts or ends with a backtick
+	start, stop := segments[0].Start, segments[len(segments)-1].Stop
+	for start > 0 && source[start-1] != '`' {
+		start--
+	}
+	for start > 0 && source[start-1] == '`' {
+		start--
+	}
+	for stop < len(source) && source[stop] != '`' {
+		stop++
+	}
+	for stop < len(source) && source[stop] == '`' {
+		stop++
+	}
+
+	buf.Write(source[start:segments[0].Start])
+	for i, segment := range segments {
+		if i > 0 {
+			// Line breaks inside the span drop the prefixes of containers
+			if gap := source[segments[i-1].Stop:segment.Start]; bytes.IndexByte(gap, '\n') >= 0 {
+				buf.WriteByte('\n')
+			} else {
+				buf.Write(gap)
+			}
+		}
+		buf.Write(segment.Value(source))
+	}
+	buf.Write(source[segments[len(segments)-1].Stop:stop])
 }
 
-// extractLinkText extracts text from link nodes with markdown syntax
+// extractLinkText extracts link and image nodes with markdown syntax,
+// keeping titles and reference style
 func (p *GoldmarkParser) extractLinkText(n ast.Node, source []byte, buf *bytes.Buffer) {
-	link := n.(*ast.Link)
-	buf.WriteString("[")
-	buf.WriteString(p.extractTextRecursive(n, source))
-	buf.WriteString("](")
-	buf.Write(link.Destination)
-	buf.WriteString(")")
+	var text bytes.Buffer
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		text.WriteString(p.extractWithInlineFormatting(child, source))
+	}
+	buf.WriteString(convertLink(n, text.String()).Markdown())
 }
 
 // extractGenericText extracts text from other container nodes
@@ -375,16 +854,24 @@ func (p *GoldmarkParser) extractGenericText(n ast.Node, source []byte) string {
 // extractTextRecursive extracts text content recursively from all children
 func (p *GoldmarkParser) extractTextRecursive(n ast.Node, source []byte) string {
 	var buf bytes.Buffer
+	if p.writeCustomInline(&buf, n, source) {
+		return buf.String()
+	}
 
 	switch n.Kind() {
 	case ast.KindText:
-		textNode := n.(*ast.Text)
-		buf.Write(textNode.Segment.Value(source))
+		writeTextSegment(&buf, n.(*ast.Text), source)
 		return buf.String()
 	case ast.KindString:
 		str := n.(*ast.String)
 		buf.Write(str.Value)
 		return buf.String()
+	case KindMathInline, KindWikiLink, KindTag, ast.KindAutoLink, ast.KindRawHTML:
+		writeInlineLeaf(&buf, n, source)
+		return buf.String()
+	case extast.KindStrikethrough, KindHighlight:
+		p.extractMarkText(n, source, &buf)
+		return buf.String()
 	}
 
 	// For container nodes, extract text from all children recursively
@@ -396,6 +883,43 @@ func (p *GoldmarkParser) extractTextRecursive(n ast.Node, source []byte) string
 	return strings.TrimSpace(buf.String())
 }
 
+// EndsWithLineBreak reports whether content ends with a line break
+func EndsWithLineBreak(content []byte) bool {
+	return len(content) > 0 && (content[len(content)-1] == '\n' || content[len(content)-1] == '\r')
+}
+
+// writeTextSegment writes a text node's content, keeping line breaks as
+// newlines and hard breaks as HardBreak, whichever way they were written
+func writeTextSegment(buf *bytes.Buffer, textNode *ast.Text, source []byte) {
+	buf.Write(textNode.Segment.Value(source))
+	switch {
+	case textNode.HardLineBreak():
+		buf.WriteString(HardBreak)
+	case textNode.SoftLineBreak():
+		buf.WriteString("\n")
+	}
+}
+
+// writeInlineLeaf writes inline nodes that are kept exactly as written:
+// math, wiki links, tags, autolinks and raw inline HTML (including MDX inline JSX)
+func writeInlineLeaf(buf *bytes.Buffer, n ast.Node, source []byte) {
+	switch leaf := n.(type) {
+	case *mathInlineNode:
+		buf.Write(leaf.Segment.Value(source))
+	case *wikiLinkNode:
+		buf.Write(leaf.Segment.Value(source))
+	case *tagNode:
+		buf.Write(leaf.Segment.Value(source))
+	case *ast.AutoLink:
+		buf.WriteString(convertAutolink(leaf, source).Markdown())
+	case *ast.RawHTML:
+		for i := 0; i < leaf.Segments.Len(); i++ {
+			segment := leaf.Segments.At(i)
+			buf.Write(segment.Value(source))
+		}
+	}
+}
+
 // Validate checks if the parser is properly configured
 func (p *GoldmarkParser) Validate() error {
 	if p.markdown == nil {
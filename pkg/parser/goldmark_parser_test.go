@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -79,6 +81,34 @@ func TestGoldmarkParser_ParseParagraph(t *testing.T) {
 	}
 }
 
+func TestGoldmarkParser_ParseEmphasis(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"asterisks", "*em* and **strong**", "*em* and **strong**"},
+		{"underscores", "_em_ and __strong__", "_em_ and __strong__"},
+		{"nested", "a *b _c_ d* and __x *y*__", "a *b _c_ d* and __x *y*__"},
+		{"nested at start", "*__b__ c* and ***d***", "*__b__ c* and ***d***"},
+		{"literal asterisk", "**!_!*!0_", "**!_!*!0_"},
+		{"starting with a link", "_[link](https://example.com)_", "*[link](https://example.com)*"},
+	}
+
+	parser := NewGoldmarkParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if para := doc.Children[0].(*Paragraph); para.Text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, para.Text)
+			}
+		})
+	}
+}
+
 func TestGoldmarkParser_ParseList(t *testing.T) {
 	parser := NewGoldmarkParser()
 	content := []byte(`
@@ -109,6 +139,10 @@ func TestGoldmarkParser_ParseList(t *testing.T) {
 		t.Error("Expected unordered list, got ordered")
 	}
 
+	if list.Loose {
+		t.Error("Expected tight list, got loose")
+	}
+
 	if len(list.Items) != 3 {
 		t.Errorf("Expected 3 list items, got %d", len(list.Items))
 	}
@@ -123,6 +157,75 @@ func TestGoldmarkParser_ParseList(t *testing.T) {
 	}
 }
 
+func TestGoldmarkParser_ParseTaskList(t *testing.T) {
+	source := []byte("- [ ] todo\n- [X] done\n- plain\n")
+
+	doc, err := NewGoldmarkParser().Parse(source)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	expected := []ListItem{{Text: "todo", Task: true}, {Text: "done", Task: true, Checked: true}, {Text: "plain"}}
+	for i, item := range doc.Children[0].(*List).Items {
+		if item.Text != expected[i].Text || item.Task != expected[i].Task || item.Checked != expected[i].Checked {
+			t.Errorf("Expected item %d %q task=%t checked=%t, got %q task=%t checked=%t", i,
+				expected[i].Text, expected[i].Task, expected[i].Checked, item.Text, item.Task, item.Checked)
+		}
+	}
+
+	// CommonMark has no task lists: the checkbox is text
+	doc, err = NewGoldmarkParserWithOptions(Options{Dialect: DialectCommonMark}).Parse(source)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if item := doc.Children[0].(*List).Items[0]; item.Task || item.Text != "[ ] todo" {
+		t.Errorf("Expected text %q without task, got %q task=%t", "[ ] todo", item.Text, item.Task)
+	}
+}
+
+func TestGoldmarkParser_ParseLooseList(t *testing.T) {
+	doc, err := NewGoldmarkParser().Parse([]byte("- one\n\n- two\n  - nested\n  - tight\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	list := doc.Children[0].(*List)
+	if !list.Loose {
+		t.Error("Expected loose list, got tight")
+	}
+	if nested := list.Items[1].Children[0].(*List); nested.Loose {
+		t.Error("Expected tight nested list, got loose")
+	}
+}
+
+func TestGoldmarkParser_ParseListItemBlocks(t *testing.T) {
+	content := "1. Install the tool.\n\n   Then run it:\n\n   ```sh\n   mdfmt --write .\n   ```\n\n   - nested\n"
+	doc, err := NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	list, ok := doc.Children[0].(*List)
+	if !ok || len(list.Items) != 1 {
+		t.Fatalf("Expected a list with one item, got %s", DebugString(doc))
+	}
+	item := list.Items[0]
+	if item.Text != "Install the tool." {
+		t.Errorf("Expected the first paragraph as item text, got %q", item.Text)
+	}
+	expected := []NodeType{NodeParagraph, NodeCodeBlock, NodeList}
+	if len(item.Children) != len(expected) {
+		t.Fatalf("Expected %d children, got %s", len(expected), DebugString(doc))
+	}
+	for i, child := range item.Children {
+		if child.Type() != expected[i] {
+			t.Errorf("Expected child %d to be %s, got %s", i, NodeTypeString(expected[i]), NodeTypeString(child.Type()))
+		}
+	}
+	if code := item.Children[1].(*CodeBlock); code.Content != "mdfmt --write .\n" || code.Language != "sh" {
+		t.Errorf("Expected the code block kept, got %#v", code)
+	}
+}
+
 func TestGoldmarkParser_ParseOrderedList(t *testing.T) {
 	parser := NewGoldmarkParser()
 	content := []byte(`
@@ -156,11 +259,73 @@ func TestGoldmarkParser_ParseOrderedList(t *testing.T) {
 	if len(list.Items) != 3 {
 		t.Errorf("Expected 3 list items, got %d", len(list.Items))
 	}
+
+	if list.Start != 1 {
+		t.Errorf("Expected the list to start at 1, got %d", list.Start)
+	}
+}
+
+func TestGoldmarkParser_ParseOrderedListStart(t *testing.T) {
+	doc, err := NewGoldmarkParser().Parse([]byte("1. a\n\ntext\n\n2. b\n3. c\n\n- d\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	var starts []int
+	for _, child := range doc.Children {
+		if list, ok := child.(*List); ok {
+			starts = append(starts, list.Start)
+		}
+	}
+	if fmt.Sprint(starts) != "[1 2 0]" {
+		t.Errorf("Expected list starts [1 2 0], got %v", starts)
+	}
+}
+
+func TestGoldmarkParser_ParseListMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		list     string
+		expected []string
+	}{
+		{"dash", "- a\n- b\n", "-", []string{"-", "-"}},
+		{"asterisk", "* a\n* b\n", "*", []string{"*", "*"}},
+		{"plus", "+ a\n+ b\n", "+", []string{"+", "+"}},
+		{"ordered", "1. a\n2. b\n", ".", []string{"1.", "2."}},
+		{"parenthesis", "3) a\n7) b\n", ")", []string{"3)", "7)"}},
+		{"empty items", "4.\n5.\n", ".", []string{"4.", "5."}},
+		{"nested first", "1. - a\n2. b\n", ".", []string{"1.", "2."}},
+		{"in blockquote", "> 2) a\n> 3) b\n", ")", []string{"2)", "3)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewGoldmarkParser().Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			var list *List
+			walker := NewWalker(doc)
+			for node, ok := walker.Next(); ok && list == nil; node, ok = walker.Next() {
+				list, _ = node.(*List)
+			}
+			if list == nil {
+				t.Fatal("No list found in parsed document")
+			}
+			markers := make([]string, len(list.Items))
+			for i, item := range list.Items {
+				markers[i] = item.Marker
+			}
+			if list.Marker != tt.list || strings.Join(markers, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Expected list marker %q and items %q, got %q and %q", tt.list, tt.expected, list.Marker, markers)
+			}
+		})
+	}
 }
 
 func TestGoldmarkParser_ParseCodeBlock(t *testing.T) {
 	parser := NewGoldmarkParser()
-	content := []byte("```go\nfunc main() {\n    fmt.Println(\"Hello\")\n}\n```")
+	content := []byte("```go title=\"main.go\"\nfunc main() {\n    fmt.Println(\"Hello\")\n}\n```")
 
 	doc, err := parser.Parse(content)
 	if err != nil {
@@ -183,6 +348,9 @@ func TestGoldmarkParser_ParseCodeBlock(t *testing.T) {
 	if codeBlock.Language != "go" {
 		t.Errorf("Expected language 'go', got %q", codeBlock.Language)
 	}
+	if codeBlock.Info != `go title="main.go"` {
+		t.Errorf("Expected info string 'go title=\"main.go\"', got %q", codeBlock.Info)
+	}
 
 	if !codeBlock.Fenced {
 		t.Error("Expected fenced code block")
@@ -193,6 +361,94 @@ func TestGoldmarkParser_ParseCodeBlock(t *testing.T) {
 	}
 }
 
+func TestGoldmarkParser_ParseCodeFence(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"backticks", "```go\nx\n```\n", "```"},
+		{"tildes", "~~~go\nx\n~~~\n", "~~~"},
+		{"tildes without info", "~~~\nx\n~~~\n", "~~~"},
+		{"long fence", "`````\n```\n`````\n", "`````"},
+		{"long tildes with info", "~~~~ sh\nx\n~~~~\n", "~~~~"},
+		{"indented", "  ~~~\n  x\n  ~~~\n", "~~~"},
+		{"blockquote", "> ~~~\n> x\n> ~~~\n", "~~~"},
+		{"list item", "- a\n\n  ~~~~\n  x\n  ~~~~\n", "~~~~"},
+		{"info with backticks", "~~~ a`b\nx\n~~~\n", "~~~"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewGoldmarkParser().Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			var code *CodeBlock
+			walker := NewWalker(doc)
+			for node, ok := walker.Next(); ok && code == nil; node, ok = walker.Next() {
+				code, _ = node.(*CodeBlock)
+			}
+			if code == nil {
+				t.Fatal("No code block found in parsed document")
+			}
+			if code.Fence != tt.expected {
+				t.Errorf("Expected fence %q, got %q", tt.expected, code.Fence)
+			}
+		})
+	}
+}
+
+func TestGoldmarkParser_ParseTable(t *testing.T) {
+	content := "> | Name | Value |\n> |:--|:-:|\n> | `a\\|b` | **1** |\n> | long name |\n"
+	doc, err := NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	var table *Table
+	walker := NewWalker(doc)
+	for node, ok := walker.Next(); ok && table == nil; node, ok = walker.Next() {
+		table, _ = node.(*Table)
+	}
+	if table == nil {
+		t.Fatal("No table found in parsed document")
+	}
+
+	if strings.Join(table.Alignments, ",") != "left,center" {
+		t.Errorf("Expected alignments left,center, got %q", table.Alignments)
+	}
+	expected := "| Name      | Value |\n| :-------- | :---: |\n| `a\\|b`    | **1** |\n| long name |       |"
+	if got := table.Markdown(); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestGoldmarkParser_ParseHardBreaks(t *testing.T) {
+	doc, err := NewGoldmarkParser().Parse([]byte("one\\\ntwo   \nthree\nfour\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	paragraph, ok := doc.Children[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("Expected a paragraph, got %T", doc.Children[0])
+	}
+	if expected := "one\\\ntwo\\\nthree\nfour"; paragraph.Text != expected {
+		t.Errorf("Expected %q, got %q", expected, paragraph.Text)
+	}
+}
+
+func TestGoldmarkParser_NoFinalNewline(t *testing.T) {
+	for content, expected := range map[string]bool{"": true, "text": true, "text\n": false, "text\r": false} {
+		doc, err := NewGoldmarkParser().Parse([]byte(content))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if doc.NoFinalNewline != expected {
+			t.Errorf("Expected NoFinalNewline %t for %q, got %t", expected, content, doc.NoFinalNewline)
+		}
+	}
+}
+
 func TestGoldmarkParser_ParseComplexDocument(t *testing.T) {
 	parser := NewGoldmarkParser()
 	content := []byte(`# Title
@@ -442,3 +698,94 @@ func BenchmarkGoldmarkParser_ParseHugeDocument(b *testing.B) {
 		}
 	}
 }
+
+func TestGoldmarkParser_ParseContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ParseContext(ctx, NewGoldmarkParser(), []byte("# Title\n\nText\n"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	_, err = ParseContext(ctx, NewBasicParser(), []byte("# Title\n"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from a parser without context support, got %v", err)
+	}
+}
+
+// benchmarkDocument is a document exercising the common block types
+var benchmarkDocument = []byte(strings.Repeat("# Heading\n\n"+
+	"Some paragraph text with a [link](https://example.com) and *emphasis*.\n\n"+
+	"- one\n- two\n  - nested\n\n"+
+	"```go\nfmt.Println(\"hello\")\n```\n\n", 20))
+
+// BenchmarkGoldmarkParser_NewParserPerDocument creates a parser per document, as each file was
+// formatted before parsers were shared across a run
+func BenchmarkGoldmarkParser_NewParserPerDocument(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkDocument)))
+	for i := 0; i < b.N; i++ {
+		if _, err := NewGoldmarkParser().Parse(benchmarkDocument); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGoldmarkParser_ReusedParser parses every document with the same parser
+func BenchmarkGoldmarkParser_ReusedParser(b *testing.B) {
+	p := NewGoldmarkParser()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkDocument)))
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Parse(benchmarkDocument); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGoldmarkParser_CommonMarkDialect(t *testing.T) {
+	source := []byte("Term\n: Definition\n\n~~gone~~ and https://example.com\n")
+
+	gfm, err := NewGoldmarkParser().Parse(source)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if gfm.Children[0].Type() != NodeDefinitionList {
+		t.Errorf("Expected a definition list with gfm, got %s", DebugString(gfm))
+	}
+
+	doc, err := NewGoldmarkParserWithOptions(Options{Dialect: DialectCommonMark}).Parse(source)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 2 || doc.Children[0].Type() != NodeParagraph {
+		t.Fatalf("Expected two paragraphs with commonmark, got %s", DebugString(doc))
+	}
+	if text := doc.Children[1].(*Paragraph).Text; text != "~~gone~~ and https://example.com" {
+		t.Errorf("Expected strikethrough and bare URLs left as text, got %q", text)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"# Title\n\nText with *emphasis*, `code` and [a link][ref].\n\n[ref]: https://example.com \"Title\"\n",
+		"- one\n  - nested\n    continued\n\n1. first\n2) second\n",
+		"> [!NOTE]\n> Alert\n\n<details>\n<summary>More</summary>\n\nHidden\n</details>\n",
+		"Term\n: Definition\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n~~struck~~ text\n",
+		"---\ntitle: x\n---\n\n```go\ncode\n```\n\n    indented\n",
+	} {
+		f.Add([]byte(seed))
+	}
+	p := NewGoldmarkParserWithOptions(Options{Positions: true})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		doc, err := p.Parse(data)
+		var conversionErr *ConversionError
+		if errors.As(err, &conversionErr) {
+			t.Fatalf("Conversion panicked: %v\n%s", err, conversionErr.Stack)
+		}
+		if err == nil && doc == nil {
+			t.Fatal("Expected a document without an error")
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Constants
+const (
+	// sourceHTMLRendererPriority is lower than that of the goldmark HTML
+	// renderer, so renderers of registered extensions take precedence
+	sourceHTMLRendererPriority = 2000
+)
+
+// builtinKinds are the node kinds of the syntax the parser adds to
+// goldmark, including that of the recognizers of disabled features
+var builtinKinds = []ast.NodeKind{KindMathBlock, KindMathInline, KindHighlight, KindWikiLink, KindTag, KindMDXBlock}
+
+// sourceHTMLRenderer renders nodes goldmark has no HTML for as their
+// escaped source, so RenderHTML can compare documents using any syntax the
+// parser knows: blocks as their lines, math, wiki links and tags as
+// written, and highlights and other inline nodes as their children
+type sourceHTMLRenderer struct {
+	kinds []ast.NodeKind
+}
+
+// withSourceHTML renders the built-in kinds and those of converters as
+// their source, unless an extension renders them
+func withSourceHTML(converters map[ast.NodeKind]NodeConverter) goldmark.Option {
+	kinds := append([]ast.NodeKind{}, builtinKinds...)
+	for kind := range converters {
+		kinds = append(kinds, kind)
+	}
+	return goldmark.WithRendererOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&sourceHTMLRenderer{kinds: kinds}, sourceHTMLRendererPriority),
+	))
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (r *sourceHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	for _, kind := range r.kinds {
+		reg.Register(kind, renderSource)
+	}
+}
+
+// renderSource renders n as its escaped source
+func renderSource(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	switch n.(type) {
+	case *mathInlineNode, *wikiLinkNode, *tagNode:
+		if entering {
+			var buf bytes.Buffer
+			writeInlineLeaf(&buf, n, source)
+			_, _ = w.Write(util.EscapeHTML(buf.Bytes()))
+		}
+		return ast.WalkSkipChildren, nil
+	case *highlightNode:
+		if entering {
+			_, _ = w.WriteString("<mark>")
+		} else {
+			_, _ = w.WriteString("</mark>")
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if n.Type() != ast.TypeBlock {
+		return ast.WalkContinue, nil
+	}
+	if entering {
+		_, _ = w.WriteString("<pre>")
+		for i := 0; i < n.Lines().Len(); i++ {
+			line := n.Lines().At(i)
+			_, _ = w.Write(util.EscapeHTML(line.Value(source)))
+		}
+		_, _ = w.WriteString("</pre>\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
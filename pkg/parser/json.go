@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"unicode"
+)
+
+// jsonIndent indents the objects of MarshalAST
+const jsonIndent = "  "
+
+// nodeType is the reflected Node interface, to tell node fields apart
+var nodeType = reflect.TypeOf((*Node)(nil)).Elem()
+
+// MarshalAST returns the JSON form of doc, for tools consuming mdfmt's view
+// of a document and for reporting parser bugs. Each node is an object with
+// its "type", its source "line" when known (see Document.Line), its
+// non-empty exported fields under lowerCamel names, and its "children".
+func MarshalAST(doc *Document) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", jsonIndent)
+	if err := encoder.Encode(astValue(doc, reflect.ValueOf(doc))); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// object is a JSON object that keeps its keys in order
+type object []member
+
+// member is a key of an object with its value
+type member struct {
+	key   string
+	value interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		// Encode terminates each value with a newline, which is valid
+		// whitespace between the tokens of an object
+		if err := encoder.Encode(m.key); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := encoder.Encode(m.value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// astValue returns the JSON form of v, looking up node lines in doc
+func astValue(doc *Document, v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if node, ok := v.Interface().(Node); ok {
+			return astNode(doc, node)
+		}
+		return astValue(doc, v.Elem())
+	case reflect.Struct:
+		return astFields(doc, v, nil)
+	case reflect.Slice:
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = astValue(doc, v.Index(i))
+		}
+		return values
+	default:
+		return v.Interface()
+	}
+}
+
+// astNode returns the JSON form of node
+func astNode(doc *Document, node Node) object {
+	o := object{{key: "type", value: astTypeName(node)}}
+	if line, ok := doc.Line(node); ok {
+		o = append(o, member{key: "line", value: line})
+	}
+	var children []interface{}
+	o = append(o, astFields(doc, reflect.ValueOf(node).Elem(), &children)...)
+	if children != nil {
+		o = append(o, member{key: "children", value: children})
+	}
+	return o
+}
+
+// astFields returns the non-empty exported fields of the struct v. Fields
+// holding nodes are appended to children when it is not nil.
+func astFields(doc *Document, v reflect.Value, children *[]interface{}) object {
+	var o object
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		if field.PkgPath != "" || value.IsZero() {
+			continue
+		}
+		if children != nil && value.Kind() == reflect.Slice && value.Type().Elem().Implements(nodeType) {
+			for j := 0; j < value.Len(); j++ {
+				*children = append(*children, astValue(doc, value.Index(j)))
+			}
+			continue
+		}
+		o = append(o, member{key: lowerCamel(field.Name), value: astValue(doc, value)})
+	}
+	return o
+}
+
+// astTypeName returns the name of the type of node
+func astTypeName(node Node) string {
+	if name := NodeTypeString(node.Type()); name != "Custom" && name != "Unknown" {
+		return name
+	}
+	return reflect.TypeOf(node).Elem().Name()
+}
+
+// lowerCamel returns a Go field name in lowerCamel case, lowering a leading
+// initialism as a whole: FrontMatter becomes frontMatter and URL url.
+func lowerCamel(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
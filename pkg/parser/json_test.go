@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseRecordsLines(t *testing.T) {
+	content := []byte("# Title\n\n```\ncode\n```\n\n~~~go\n~~~\n\n<details>\n\nInner\n\n</details>\n\n[a]: https://example.com\n")
+	doc, err := NewGoldmarkParserWithOptions(Options{Positions: true}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 5 {
+		t.Fatalf("Expected 5 blocks, got %s", DebugString(doc))
+	}
+
+	details := doc.Children[3].(*Details)
+	for _, tt := range []struct {
+		node Node
+		line int
+	}{
+		{doc.Children[0], 1},
+		{doc.Children[1], 3},
+		{doc.Children[2], 7},
+		{details, 10},
+		{details.Children[0], 12},
+		{doc.Children[4], 16},
+	} {
+		if line, ok := doc.Line(tt.node); !ok || line != tt.line {
+			t.Errorf("Expected %s on line %d, got %d, %v", tt.node, tt.line, line, ok)
+		}
+	}
+
+	plain, err := NewGoldmarkParser().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, ok := plain.Line(plain.Children[0]); ok {
+		t.Error("Expected no lines without Options.Positions")
+	}
+}
+
+func TestMarshalAST(t *testing.T) {
+	content := []byte("# Title {#intro}\n\n- one\n- [link](https://example.com) <b>\n")
+	doc, err := NewGoldmarkParserWithOptions(Options{Positions: true}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	data, err := MarshalAST(doc)
+	if err != nil {
+		t.Fatalf("MarshalAST failed: %v", err)
+	}
+
+	type node struct {
+		Type       string
+		Line       int
+		Level      int
+		Text       string
+		Attributes string
+		Ordered    *bool
+		Children   []node
+	}
+	var tree node
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, data)
+	}
+
+	if tree.Type != "Document" || len(tree.Children) != 2 {
+		t.Fatalf("Expected a document with 2 children, got:\n%s", data)
+	}
+	heading := tree.Children[0]
+	if heading.Type != "Heading" || heading.Line != 1 || heading.Level != 1 ||
+		heading.Text != "Title" || heading.Attributes != "{#intro}" {
+		t.Errorf("Unexpected heading %+v", heading)
+	}
+	list := tree.Children[1]
+	if list.Type != "List" || list.Line != 3 || list.Ordered != nil || len(list.Children) != 2 {
+		t.Fatalf("Unexpected list %+v", list)
+	}
+	if item := list.Children[1]; item.Type != "ListItem" || item.Line != 4 || item.Text != "[link](https://example.com) <b>" {
+		t.Errorf("Unexpected list item %+v", item)
+	}
+}
+
+func TestLowerCamel(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Text":        "text",
+		"FrontMatter": "frontMatter",
+		"URL":         "url",
+		"HTMLContent": "htmlContent",
+	} {
+		if got := lowerCamel(name); got != expected {
+			t.Errorf("lowerCamel(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Link reference styles, as written in the source
+const (
+	// LinkStyleInline is [text](url "title")
+	LinkStyleInline = "inline"
+	// LinkStyleFull is [text][label]
+	LinkStyleFull = "full"
+	// LinkStyleCollapsed is [text][]
+	LinkStyleCollapsed = "collapsed"
+	// LinkStyleShortcut is [text]
+	LinkStyleShortcut = "shortcut"
+)
+
+var (
+	linkStyleAttribute = []byte("mdfmt-link-style")
+	linkLabelAttribute = []byte("mdfmt-link-label")
+
+	// linkPattern matches a link or image in inline markdown: inline, full or
+	// collapsed reference, or a bare [text] that may be a shortcut reference.
+	// The text may contain one level of nested brackets, such as an image
+	// inside a badge link.
+	linkPattern = regexp.MustCompile(
+		`(!?)\[((?:[^\[\]]|\[[^\[\]]*\](?:\([^()]*\))?)*)\]` +
+			`(?:\((<[^<>\n]*>|[^\s()<>]*)(?:\s+("(?:[^"\\]|\\.)*"|'[^']*'))?\)|\[([^\[\]]*)\])?`)
+)
+
+// Submatch indexes of linkPattern
+const (
+	linkBangGroup  = 1
+	linkTextGroup  = 2
+	linkDestGroup  = 3
+	linkTitleGroup = 4
+	linkLabelGroup = 5
+)
+
+// referenceTrackingLinkParser wraps goldmark's link parser and records whether
+// each link or image was written inline or as a reference, which goldmark
+// otherwise resolves away
+type referenceTrackingLinkParser struct {
+	gmparser.InlineParser
+}
+
+// Parse implements parser.InlineParser.
+func (s *referenceTrackingLinkParser) Parse(parent ast.Node, block text.Reader, pc gmparser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	node := s.InlineParser.Parse(parent, block, pc)
+	if node == nil || line[0] != ']' {
+		return node
+	}
+
+	_, after := block.Position()
+	consumed := after.Start - segment.Start
+	switch {
+	case consumed <= 1 || len(line) < 2:
+		node.SetAttribute(linkStyleAttribute, LinkStyleShortcut)
+	case line[1] == '[':
+		end := bytes.IndexByte(line[2:], ']')
+		if end <= 0 {
+			node.SetAttribute(linkStyleAttribute, LinkStyleCollapsed)
+			break
+		}
+		node.SetAttribute(linkStyleAttribute, LinkStyleFull)
+		node.SetAttribute(linkLabelAttribute, string(line[2:2+end]))
+	default:
+		node.SetAttribute(linkStyleAttribute, LinkStyleInline)
+	}
+	return node
+}
+
+// CloseBlock forwards to the wrapped parser, which tracks open link labels.
+func (s *referenceTrackingLinkParser) CloseBlock(parent ast.Node, block text.Reader, pc gmparser.Context) {
+	if closer, ok := s.InlineParser.(gmparser.CloseBlocker); ok {
+		closer.CloseBlock(parent, block, pc)
+	}
+}
+
+// inlineParsers returns goldmark's default inline parsers with the link
+// parser wrapped to track reference style
+func inlineParsers() []util.PrioritizedValue {
+	parsers := gmparser.DefaultInlineParsers()
+	for i := range parsers {
+		inline, ok := parsers[i].Value.(gmparser.InlineParser)
+		if ok && bytes.IndexByte(inline.Trigger(), ']') >= 0 {
+			parsers[i].Value = &referenceTrackingLinkParser{InlineParser: inline}
+		}
+	}
+	return parsers
+}
+
+// convertLink converts a goldmark link or image into a Link, keeping the
+// reference style it was written in
+func convertLink(n ast.Node, text string) *Link {
+	link := &Link{Text: text, Style: LinkStyleInline}
+
+	switch node := n.(type) {
+	case *ast.Link:
+		link.Destination, link.Title = string(node.Destination), string(node.Title)
+	case *ast.Image:
+		link.Destination, link.Title = string(node.Destination), string(node.Title)
+		link.Image = true
+	}
+
+	if style, ok := n.AttributeString(string(linkStyleAttribute)); ok {
+		link.Style = style.(string)
+	}
+	if label, ok := n.AttributeString(string(linkLabelAttribute)); ok {
+		link.Label = label.(string)
+	}
+	return link
+}
+
+// positionedNode is a converted block with its offset in the source
+type positionedNode struct {
+	offset int
+	node   Node
+}
+
+// positionedDefinition is a link reference definition with its offset in the source
+type positionedDefinition struct {
+	offset     int
+	definition *LinkDefinition
+}
+
+// sourceOffset returns the offset of the first source line of a goldmark
+// node, or -1 if it has none
+func sourceOffset(n ast.Node) int {
+	if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+		return n.Lines().At(0).Start
+	}
+	if textNode, ok := n.(*ast.Text); ok {
+		return textNode.Segment.Start
+	}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if offset := sourceOffset(child); offset >= 0 {
+			return offset
+		}
+	}
+	return -1
+}
+
+// linkDefinitions returns the link reference definitions goldmark collected,
+// with their offsets in the source
+func linkDefinitions(pc gmparser.Context, source []byte) []positionedDefinition {
+	refs := pc.References()
+	definitions := make([]positionedDefinition, 0, len(refs))
+
+	for _, ref := range refs {
+		offset := definitionOffset(source, ref.Label())
+		definitions = append(definitions, positionedDefinition{
+			offset: offset,
+			definition: &LinkDefinition{
+				Label:       string(ref.Label()),
+				Destination: string(ref.Destination()),
+				Title:       string(ref.Title()),
+			},
+		})
+	}
+
+	sort.SliceStable(definitions, func(i, j int) bool {
+		return definitions[i].offset < definitions[j].offset
+	})
+	return definitions
+}
+
+// definitionOffset returns the offset of the line defining label in
+// source, indented by up to three spaces, or len(source) if none does
+func definitionOffset(source, label []byte) int {
+	marker := append(append([]byte("["), label...), "]:"...)
+	for from := 0; ; {
+		i := bytes.Index(source[from:], marker)
+		if i < 0 {
+			return len(source)
+		}
+		i += from
+		start := bytes.LastIndexByte(source[:i], '\n') + 1
+		if i-start <= 3 && len(bytes.TrimLeft(source[start:i], " ")) == 0 {
+			return start
+		}
+		from = i + 1
+	}
+}
+
+// mergeDefinitions places link definitions among the top-level blocks by
+// source position, grouping consecutive definitions
+func mergeDefinitions(blocks []positionedNode, definitions []positionedDefinition) []Node {
+	result := make([]Node, 0, len(blocks)+1)
+	var group *LinkDefinitions
+
+	d := 0
+	for _, block := range blocks {
+		for ; d < len(definitions) && definitions[d].offset < block.offset; d++ {
+			group = appendDefinition(&result, group, definitions[d].definition)
+		}
+		result = append(result, block.node)
+		group = nil
+	}
+	for ; d < len(definitions); d++ {
+		group = appendDefinition(&result, group, definitions[d].definition)
+	}
+
+	return result
+}
+
+// appendDefinition adds a definition to the current group, starting a new
+// group when needed
+func appendDefinition(result *[]Node, group *LinkDefinitions, definition *LinkDefinition) *LinkDefinitions {
+	if group == nil {
+		group = &LinkDefinitions{}
+		*result = append(*result, group)
+	}
+	group.Definitions = append(group.Definitions, definition)
+	return group
+}
+
+// ReplaceLinks calls fn for each link or image in inline markdown text
+// outside code spans and replaces it with the result. Text that is only a
+// bracketed [word] is reported as a shortcut reference; callers decide
+// whether its label resolves. Link.Text holds the raw inner markdown, so
+// nested images are seen by calling ReplaceLinks on it.
+func ReplaceLinks(text string, fn func(*Link) string) string {
+	return MapOutsideCode(text, func(prose string) string {
+		return linkPattern.ReplaceAllStringFunc(prose, func(s string) string {
+			return fn(matchLink(s))
+		})
+	})
+}
+
+// EachLink calls fn for each link, image and angle-bracket autolink in
+// inline markdown text outside code spans, with its byte offset in text.
+// Links are reported as ReplaceLinks reports them; an autolink is reported
+// as an inline link whose text and destination are its address, with
+// "mailto:" before email addresses.
+func EachLink(text string, fn func(link *Link, offset int)) {
+	last := 0
+	visit := func(prose string, base int) {
+		for _, m := range linkPattern.FindAllStringIndex(prose, -1) {
+			fn(matchLink(prose[m[0]:m[1]]), base+m[0])
+		}
+	}
+	for _, r := range defaultSyntax.matchRanges(text, isVerbatimOrCode) {
+		visit(text[last:r[0]], last)
+		last = r[1]
+
+		construct := text[r[0]:r[1]]
+		if m := autolinkPattern.FindStringIndex(construct); m == nil || m[0] != 0 || m[1] != len(construct) {
+			continue
+		}
+		address := construct[1 : len(construct)-1]
+		destination := address
+		if !strings.Contains(address, ":") {
+			destination = "mailto:" + address
+		}
+		fn(&Link{Text: address, Destination: destination, Style: LinkStyleInline}, r[0])
+	}
+	visit(text[last:], last)
+}
+
+// matchLink converts a linkPattern match into a Link
+func matchLink(s string) *Link {
+	g := linkPattern.FindStringSubmatchIndex(s)
+	group := func(i int) string {
+		if g[2*i] < 0 {
+			return ""
+		}
+		return s[g[2*i]:g[2*i+1]]
+	}
+
+	link := &Link{
+		Text:  group(linkTextGroup),
+		Image: group(linkBangGroup) != "",
+	}
+	switch {
+	case g[2*linkDestGroup] >= 0:
+		link.Style = LinkStyleInline
+		link.Destination = strings.TrimSuffix(strings.TrimPrefix(group(linkDestGroup), "<"), ">")
+		if title := group(linkTitleGroup); len(title) >= 2 {
+			link.Title = strings.ReplaceAll(title[1:len(title)-1], `\"`, `"`)
+		}
+	case g[2*linkLabelGroup] < 0:
+		link.Style = LinkStyleShortcut
+	case group(linkLabelGroup) == "":
+		link.Style = LinkStyleCollapsed
+	default:
+		link.Style = LinkStyleFull
+		link.Label = group(linkLabelGroup)
+	}
+	return link
+}
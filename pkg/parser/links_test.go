@@ -0,0 +1,92 @@
+package parser
+
+import "testing"
+
+func TestGoldmarkParser_ParseLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"inline with title", `[docs](https://example.com "Docs")`, `[docs](https://example.com "Docs")`},
+		{"image", "![logo](img/logo.png)", "![logo](img/logo.png)"},
+		{"image title", "![logo](img/logo.png 'The logo')", `![logo](img/logo.png "The logo")`},
+		{"full reference image", "![alt][logo]\n\n[logo]: img/logo.png", "![alt][logo]"},
+		{"collapsed reference", "[logo][]\n\n[logo]: img/logo.png", "[logo][]"},
+		{"shortcut reference", "[logo]\n\n[logo]: img/logo.png", "[logo]"},
+		{"badge", "[![CI](https://ci/badge.svg)](https://ci/run)", "[![CI](https://ci/badge.svg)](https://ci/run)"},
+		{"bold text", "[**bold**](https://example.com)", "[**bold**](https://example.com)"},
+	}
+
+	parser := NewGoldmarkParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			para, ok := doc.Children[0].(*Paragraph)
+			if !ok {
+				t.Fatalf("Expected Paragraph, got %T", doc.Children[0])
+			}
+			if para.Text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, para.Text)
+			}
+		})
+	}
+}
+
+func TestGoldmarkParser_ParseLinkDefinitions(t *testing.T) {
+	content := "[a]: https://a.example\n[B]: <img/b c.png> \"B title\"\n\nText [a] and ![b][B].\n\n[c]: https://c.example\n"
+
+	doc, err := NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d: %v", len(doc.Children), doc.Children)
+	}
+
+	first, ok := doc.Children[0].(*LinkDefinitions)
+	if !ok || len(first.Definitions) != 2 {
+		t.Fatalf("Expected 2 leading definitions, got %v", doc.Children[0])
+	}
+	if got := first.Definitions[1].Markdown(); got != `[B]: <img/b c.png> "B title"` {
+		t.Errorf("Unexpected definition %q", got)
+	}
+	if _, ok := doc.Children[1].(*Paragraph); !ok {
+		t.Errorf("Expected Paragraph between definitions, got %T", doc.Children[1])
+	}
+	last, ok := doc.Children[2].(*LinkDefinitions)
+	if !ok || len(last.Definitions) != 1 || last.Definitions[0].Label != "c" {
+		t.Errorf("Expected trailing definition c, got %v", doc.Children[2])
+	}
+}
+
+func TestEachLink(t *testing.T) {
+	text := "A [link](a.md), `[code](b.md)`, <https://example.com>,\n![image](c.png) and <me@example.com>"
+	type found struct {
+		destination string
+		image       bool
+		offset      int
+	}
+	var got []found
+	EachLink(text, func(link *Link, offset int) {
+		got = append(got, found{link.Destination, link.Image, offset})
+	})
+
+	expected := []found{
+		{"a.md", false, 2},
+		{"https://example.com", false, 32},
+		{"c.png", true, 55},
+		{"mailto:me@example.com", false, 75},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Link %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+}
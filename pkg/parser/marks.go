@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Constants
+const (
+	// highlightParserPriority matches goldmark's strikethrough parser
+	highlightParserPriority = 500
+	// highlightDelimiterLength is the length of the "==" delimiter
+	highlightDelimiterLength = 2
+)
+
+// KindHighlight is the goldmark node kind for ==highlighted== text
+var KindHighlight = ast.NewNodeKind("Highlight")
+
+// highlightNode is a goldmark inline wrapping highlighted text
+type highlightNode struct {
+	ast.BaseInline
+}
+
+// Kind implements ast.Node.
+func (n *highlightNode) Kind() ast.NodeKind { return KindHighlight }
+
+// Dump implements ast.Node.
+func (n *highlightNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// highlightDelimiterProcessor pairs == delimiters
+type highlightDelimiterProcessor struct{}
+
+// IsDelimiter implements parser.DelimiterProcessor.
+func (p *highlightDelimiterProcessor) IsDelimiter(b byte) bool {
+	return b == '='
+}
+
+// CanOpenCloser implements parser.DelimiterProcessor.
+func (p *highlightDelimiterProcessor) CanOpenCloser(opener, closer *gmparser.Delimiter) bool {
+	return opener.Char == closer.Char
+}
+
+// OnMatch implements parser.DelimiterProcessor.
+func (p *highlightDelimiterProcessor) OnMatch(_ int) ast.Node {
+	return &highlightNode{}
+}
+
+// highlightParser parses ==highlight== marks
+type highlightParser struct{}
+
+// Trigger implements parser.InlineParser.
+func (s *highlightParser) Trigger() []byte {
+	return []byte{'='}
+}
+
+// Parse implements parser.InlineParser.
+func (s *highlightParser) Parse(_ ast.Node, block text.Reader, pc gmparser.Context) ast.Node {
+	before := block.PrecendingCharacter()
+	line, segment := block.PeekLine()
+	node := gmparser.ScanDelimiter(line, before, highlightDelimiterLength, &highlightDelimiterProcessor{})
+	if node == nil || node.OriginalLength != highlightDelimiterLength || before == '=' {
+		return nil
+	}
+
+	node.Segment = segment.WithStop(segment.Start + node.OriginalLength)
+	block.Advance(node.OriginalLength)
+	pc.PushDelimiter(node)
+	return node
+}
+
+// highlightExtension registers the highlight parser with goldmark
+type highlightExtension struct{}
+
+// Extend implements goldmark.Extender.
+func (e *highlightExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(gmparser.WithInlineParsers(
+		util.Prioritized(&highlightParser{}, highlightParserPriority),
+	))
+}
+
+// strikethroughMarker returns the tildes a strikethrough was written with,
+// found right before its first text
+func strikethroughMarker(n ast.Node, source []byte) string {
+	for child := n.FirstChild(); child != nil; child = child.FirstChild() {
+		textNode, ok := child.(*ast.Text)
+		if !ok {
+			continue
+		}
+		start := textNode.Segment.Start
+		count := 0
+		for start-count > 0 && source[start-count-1] == '~' {
+			count++
+		}
+		if count == 1 {
+			return "~"
+		}
+		break
+	}
+	return "~~"
+}
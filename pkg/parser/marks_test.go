@@ -0,0 +1,34 @@
+package parser
+
+import "testing"
+
+func TestGoldmarkParser_ParseMarks(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		content  string
+		expected string
+	}{
+		{"double tilde", Options{}, "a ~~deleted *em*~~ b", "a ~~deleted *em*~~ b"},
+		{"single tilde", Options{}, "a ~deleted~ b", "a ~deleted~ b"},
+		{"highlight", Options{Extensions: []string{ExtensionHighlight}}, "a ==marked== b", "a ==marked== b"},
+		{"comparison", Options{Extensions: []string{ExtensionHighlight}}, "if a == b", "if a == b"},
+		{"highlight disabled", Options{}, "a ==marked== b", "a ==marked== b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewWithOptions(tt.opts).Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			para, ok := doc.Children[0].(*Paragraph)
+			if !ok {
+				t.Fatalf("Expected Paragraph, got %T", doc.Children[0])
+			}
+			if para.Text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, para.Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Constants
+const (
+	// mathBlockParserPriority places display math before fenced code and blockquotes
+	mathBlockParserPriority = 650
+	// mathInlineParserPriority places inline math right after code spans
+	mathInlineParserPriority = 150
+	// displayMathDelimiterLength is the length of the "$$" delimiter
+	displayMathDelimiterLength = 2
+)
+
+var (
+	// KindMathBlock is the goldmark node kind for $$...$$ display math
+	KindMathBlock = ast.NewNodeKind("MathBlock")
+	// KindMathInline is the goldmark node kind for $...$ inline math
+	KindMathInline = ast.NewNodeKind("MathInline")
+
+	displayMathDelimiter = []byte("$$")
+)
+
+// mathBlockNode is a goldmark block holding display math lines verbatim
+type mathBlockNode struct {
+	ast.BaseBlock
+	closed bool
+}
+
+// Kind implements ast.Node.
+func (n *mathBlockNode) Kind() ast.NodeKind { return KindMathBlock }
+
+// IsRaw implements ast.Node; math content is never parsed as markdown.
+func (n *mathBlockNode) IsRaw() bool { return true }
+
+// Dump implements ast.Node.
+func (n *mathBlockNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mathInlineNode is a goldmark inline holding $...$ or $$...$$ math verbatim
+type mathInlineNode struct {
+	ast.BaseInline
+	Segment text.Segment
+}
+
+// Kind implements ast.Node.
+func (n *mathInlineNode) Kind() ast.NodeKind { return KindMathInline }
+
+// Dump implements ast.Node.
+func (n *mathInlineNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Value": string(n.Segment.Value(source)),
+	}, nil)
+}
+
+// mathBlockParser parses display math delimited by $$ lines
+type mathBlockParser struct{}
+
+// Trigger implements parser.BlockParser.
+func (b *mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Open implements parser.BlockParser.
+func (b *mathBlockParser) Open(_ ast.Node, reader text.Reader, pc gmparser.Context) (ast.Node, gmparser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], displayMathDelimiter) {
+		return nil, gmparser.NoChildren
+	}
+
+	node := &mathBlockNode{}
+	node.Lines().Append(text.NewSegment(segment.Start+pos, segment.Stop))
+
+	// $$ x $$ on a single line is a complete block
+	rest := bytes.TrimSpace(line[pos+displayMathDelimiterLength:])
+	node.closed = len(rest) >= displayMathDelimiterLength && bytes.HasSuffix(rest, displayMathDelimiter)
+
+	return node, gmparser.NoChildren
+}
+
+// Continue implements parser.BlockParser.
+func (b *mathBlockParser) Continue(node ast.Node, reader text.Reader, _ gmparser.Context) gmparser.State {
+	math := node.(*mathBlockNode)
+	if math.closed {
+		return gmparser.Close
+	}
+
+	line, segment := reader.PeekLine()
+	node.Lines().Append(segment)
+	if bytes.HasSuffix(bytes.TrimSpace(line), displayMathDelimiter) {
+		math.closed = true
+	}
+	return gmparser.Continue | gmparser.NoChildren
+}
+
+// Close implements parser.BlockParser.
+func (b *mathBlockParser) Close(_ ast.Node, _ text.Reader, _ gmparser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.
+func (b *mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.
+func (b *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// mathInlineParser parses $...$ and $$...$$ inline math
+type mathInlineParser struct{}
+
+// Trigger implements parser.InlineParser.
+func (s *mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse implements parser.InlineParser. Following pandoc, the opening
+// delimiter must not be followed by a space, and the closing delimiter must
+// not be preceded by a space or followed by a digit.
+func (s *mathInlineParser) Parse(_ ast.Node, block text.Reader, _ gmparser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	delim := 1
+	if len(line) > 1 && line[1] == '$' {
+		delim = displayMathDelimiterLength
+	}
+	if delim >= len(line) || util.IsSpace(line[delim]) {
+		return nil
+	}
+
+	end := -1
+	for i := delim + 1; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case bytes.HasPrefix(line[i:], line[:delim]) && !util.IsSpace(line[i-1]):
+			if delim == 1 && i+1 < len(line) && util.IsNumeric(line[i+1]) {
+				continue
+			}
+			end = i + delim
+		}
+		if end >= 0 {
+			break
+		}
+	}
+	if end < 0 {
+		return nil
+	}
+
+	node := &mathInlineNode{Segment: segment.WithStop(segment.Start + end)}
+	block.Advance(end)
+	return node
+}
+
+// mathExtension registers the math block and inline parsers with goldmark
+type mathExtension struct{}
+
+// Extend implements goldmark.Extender.
+func (e *mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		gmparser.WithBlockParsers(util.Prioritized(&mathBlockParser{}, mathBlockParserPriority)),
+		gmparser.WithInlineParsers(util.Prioritized(&mathInlineParser{}, mathInlineParserPriority)),
+	)
+}
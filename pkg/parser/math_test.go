@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoldmarkParser_ParseMath(t *testing.T) {
+	parser := NewWithOptions(Options{Extensions: []string{ExtensionMath}})
+	content := []byte("Inline $a_1  *  b_2$ and $$x^2$$ cost $5 and $10.\n\n$$\n\\begin{aligned}\n  a  &= b\n\\end{aligned}\n$$\n")
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 2 {
+		t.Fatalf("Expected paragraph and math block, got %s", DebugString(doc))
+	}
+
+	para, ok := doc.Children[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("Expected Paragraph, got %T", doc.Children[0])
+	}
+	if expected := "Inline $a_1  *  b_2$ and $$x^2$$ cost $5 and $10."; para.Text != expected {
+		t.Errorf("Expected inline math verbatim %q, got %q", expected, para.Text)
+	}
+
+	math, ok := doc.Children[1].(*Math)
+	if !ok {
+		t.Fatalf("Expected Math, got %T", doc.Children[1])
+	}
+	if expected := "$$\n\\begin{aligned}\n  a  &= b\n\\end{aligned}\n$$\n"; math.Content != expected {
+		t.Errorf("Expected display math verbatim %q, got %q", expected, math.Content)
+	}
+}
+
+func TestGoldmarkParser_MathDisabled(t *testing.T) {
+	doc, err := NewGoldmarkParser().Parse([]byte("$$\nx\n$$\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// Display math is still copied verbatim, with a diagnostic
+	if len(FindNodes(doc, NodeMath)) != 1 || len(doc.Diagnostics) != 1 {
+		t.Errorf("Expected verbatim math with a diagnostic, got %s %v", DebugString(doc), doc.Diagnostics)
+	}
+}
+
+func TestWrapTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"plain words", "one  two\nthree", []string{"one", "two", "three"}},
+		{"math kept whole", "see ($a + b$).", []string{"see", "($a + b$)."}},
+		{"dollar amounts", "$5 and $10", []string{"$5", "and", "$10"}},
+		{"code span", "run `go test ./...` now", []string{"run", "`go test ./...`", "now"}},
+		{"link", "a [b c](d) e", []string{"a", "[b c](d)", "e"}},
+		{"wiki link", "a ![[b c]], d", []string{"a", "![[b c]],", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WrapTokens(tt.text); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("WrapTokens(%q) = %q, expected %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMapProse(t *testing.T) {
+	got := MapProse("a  b $x  y$ c  d", func(s string) string { return "<" + s + ">" })
+	if expected := "<a  b >$x  y$< c  d>"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,323 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Constants
+const (
+	// mdxBlockParserPriority places MDX blocks before goldmark's HTML block parser (900)
+	mdxBlockParserPriority = 850
+
+	// MDXKindJSX marks a JSX element block such as <Tabs>...</Tabs>
+	MDXKindJSX = "jsx"
+	// MDXKindESM marks an ESM import/export block
+	MDXKindESM = "esm"
+	// MDXKindExpression marks a {...} expression block
+	MDXKindExpression = "expression"
+)
+
+// KindMDXBlock is the goldmark node kind for raw MDX regions
+var KindMDXBlock = ast.NewNodeKind("MDXBlock")
+
+var (
+	esmImportPrefix = []byte("import ")
+	esmExportPrefix = []byte("export ")
+)
+
+// mdxFragment tells complete JSX regions from lone opening and closing tags
+type mdxFragment int
+
+const (
+	// mdxWhole is a complete region
+	mdxWhole mdxFragment = iota
+	// mdxOpenTag is an opening tag followed by a blank line and markdown children
+	mdxOpenTag
+	// mdxCloseTag is a closing tag on its own line
+	mdxCloseTag
+)
+
+// mdxBlockNode is a goldmark block holding an MDX region verbatim
+type mdxBlockNode struct {
+	ast.BaseBlock
+	mdxKind  string
+	fragment mdxFragment
+	scanner  jsxScanner
+	closed   bool
+}
+
+// Kind implements ast.Node.
+func (n *mdxBlockNode) Kind() ast.NodeKind { return KindMDXBlock }
+
+// IsRaw implements ast.Node; MDX regions are never parsed as markdown.
+func (n *mdxBlockNode) IsRaw() bool { return true }
+
+// Dump implements ast.Node.
+func (n *mdxBlockNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Kind": n.mdxKind}, nil)
+}
+
+// jsxScanner tracks JSX element nesting and {...} expressions across lines
+type jsxScanner struct {
+	depth     int
+	braces    int
+	quote     byte
+	inTag     bool
+	closing   bool
+	selfClose bool
+	started   bool
+	// atOpenTag is set right after the outermost opening tag is completed
+	atOpenTag bool
+}
+
+// scan consumes line and returns the offset just past the point where the
+// outermost element or expression closes, or -1 if it is still open.
+//
+//nolint:gocyclo // character-level state machine
+func (s *jsxScanner) scan(line []byte) int {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if s.atOpenTag && !util.IsSpace(c) {
+			s.atOpenTag = false
+		}
+		switch {
+		case s.quote != 0:
+			if c == s.quote {
+				s.quote = 0
+			}
+		case s.braces > 0:
+			switch c {
+			case '{':
+				s.braces++
+			case '}':
+				s.braces--
+			case '"', '\'', '`':
+				s.quote = c
+			}
+		case s.inTag:
+			switch c {
+			case '"', '\'':
+				s.quote = c
+			case '{':
+				s.braces++
+			case '/':
+				s.selfClose = i+1 < len(line) && line[i+1] == '>'
+			case '>':
+				s.closeTag()
+			}
+		case c == '<' && i+1 < len(line) && isJSXTagStart(line[i+1]):
+			s.inTag, s.started = true, true
+			s.closing = line[i+1] == '/'
+		case c == '{' && s.depth == 0:
+			s.braces++
+			s.started = true
+		}
+
+		if s.started && !s.inTag && s.depth == 0 && s.braces == 0 && s.quote == 0 {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// closeTag finishes the tag being scanned and updates the nesting depth
+func (s *jsxScanner) closeTag() {
+	switch {
+	case s.closing:
+		s.depth--
+	case !s.selfClose:
+		s.depth++
+		s.atOpenTag = s.depth == 1
+	}
+	s.inTag, s.closing, s.selfClose = false, false, false
+}
+
+// isJSXTagStart reports whether c may follow '<' in a JSX tag
+func isJSXTagStart(c byte) bool {
+	return c == '>' || c == '/' || util.IsAlphaNumeric(c)
+}
+
+// mdxBlockParser parses JSX element blocks, {...} expression blocks and
+// ESM import/export blocks
+type mdxBlockParser struct{}
+
+// Trigger implements parser.BlockParser.
+func (b *mdxBlockParser) Trigger() []byte {
+	return []byte{'<', '{', 'i', 'e'}
+}
+
+// Open implements parser.BlockParser.
+func (b *mdxBlockParser) Open(parent ast.Node, reader text.Reader, pc gmparser.Context) (ast.Node, gmparser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, gmparser.NoChildren
+	}
+	rest := line[pos:]
+
+	node := &mdxBlockNode{}
+	switch {
+	case bytes.HasPrefix(rest, esmImportPrefix) || bytes.HasPrefix(rest, esmExportPrefix):
+		// ESM is only allowed at the top level, starting in the first column
+		if pos != 0 || parent.Kind() != ast.KindDocument {
+			return nil, gmparser.NoChildren
+		}
+		node.mdxKind = MDXKindESM
+	case isJSXCloseTag(rest):
+		node.mdxKind, node.fragment, node.closed = MDXKindJSX, mdxCloseTag, true
+		node.Lines().Append(text.NewSegment(segment.Start+pos, segment.Stop))
+		return node, gmparser.NoChildren
+	case len(rest) > 1 && rest[0] == '<' && (rest[1] == '>' || isUpper(rest[1])):
+		node.mdxKind = MDXKindJSX
+	case len(rest) > 0 && rest[0] == '{':
+		node.mdxKind = MDXKindExpression
+	default:
+		return nil, gmparser.NoChildren
+	}
+
+	if node.mdxKind != MDXKindESM {
+		end := node.scanner.scan(rest)
+		// <Badge /> followed by text on the same line is inline JSX
+		if end >= 0 && !util.IsBlank(rest[end:]) {
+			return nil, gmparser.NoChildren
+		}
+		node.closed = end >= 0
+	}
+
+	node.Lines().Append(text.NewSegment(segment.Start+pos, segment.Stop))
+	return node, gmparser.NoChildren
+}
+
+// Continue implements parser.BlockParser.
+func (b *mdxBlockParser) Continue(node ast.Node, reader text.Reader, _ gmparser.Context) gmparser.State {
+	mdx := node.(*mdxBlockNode)
+	if mdx.closed {
+		return gmparser.Close
+	}
+
+	line, segment := reader.PeekLine()
+	switch {
+	case mdx.mdxKind == MDXKindESM:
+		if util.IsBlank(line) {
+			return gmparser.Close
+		}
+	case mdx.scanner.atOpenTag && util.IsBlank(line):
+		// A blank line after the opening tag starts markdown children
+		mdx.fragment = mdxOpenTag
+		return gmparser.Close
+	default:
+		mdx.closed = mdx.scanner.scan(line) >= 0
+	}
+
+	node.Lines().Append(segment)
+	return gmparser.Continue | gmparser.NoChildren
+}
+
+// Close implements parser.BlockParser.
+func (b *mdxBlockParser) Close(_ ast.Node, _ text.Reader, _ gmparser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.
+func (b *mdxBlockParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.
+func (b *mdxBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// isJSXCloseTag reports whether line is a lone closing tag such as </Tabs> or </>
+func isJSXCloseTag(line []byte) bool {
+	if len(line) < 3 || line[0] != '<' || line[1] != '/' || (line[2] != '>' && !isUpper(line[2])) {
+		return false
+	}
+	end := bytes.IndexByte(line, '>')
+	return end > 0 && util.IsBlank(line[end+1:])
+}
+
+// jsxTagName returns the element name of an opening or closing tag line
+func jsxTagName(content string) string {
+	name := strings.TrimLeft(strings.TrimSpace(content), "</")
+	if end := strings.IndexFunc(name, func(r rune) bool {
+		return r == '>' || r == '/' || unicode.IsSpace(r)
+	}); end >= 0 {
+		name = name[:end]
+	}
+	return name
+}
+
+// groupMDX nests the blocks found between a lone JSX opening tag and its
+// closing tag into MDXElement nodes, so markdown children are still formatted.
+// An MDXElement takes the line of its opening tag in lines, which may be nil.
+func groupMDX(nodes []Node, lines map[Node]int) []Node {
+	result := make([]Node, 0, len(nodes))
+
+	for i := 0; i < len(nodes); i++ {
+		open, ok := nodes[i].(*MDXBlock)
+		if !ok || open.fragment != mdxOpenTag {
+			result = append(result, nodes[i])
+			continue
+		}
+
+		end := findJSXClose(nodes, i+1, jsxTagName(open.Content))
+		if end < 0 {
+			result = append(result, nodes[i])
+			continue
+		}
+
+		element := &MDXElement{
+			Open:     open.Content,
+			Close:    nodes[end].(*MDXBlock).Content,
+			Children: groupMDX(nodes[i+1:end], lines),
+		}
+		moveLine(lines, open, element)
+		result = append(result, element)
+		i = end
+	}
+
+	return result
+}
+
+// findJSXClose returns the index of the closing tag for the element named
+// name that opens before start, or -1 if it is never closed
+func findJSXClose(nodes []Node, start int, name string) int {
+	depth := 0
+	for i := start; i < len(nodes); i++ {
+		mdx, ok := nodes[i].(*MDXBlock)
+		if !ok || mdx.fragment == mdxWhole || jsxTagName(mdx.Content) != name {
+			continue
+		}
+		if mdx.fragment == mdxOpenTag {
+			depth++
+			continue
+		}
+		if depth == 0 {
+			return i
+		}
+		depth--
+	}
+	return -1
+}
+
+// isUpper reports whether c is an ASCII upper case letter
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+// mdxExtension registers the MDX block parser with goldmark
+type mdxExtension struct{}
+
+// Extend implements goldmark.Extender.
+func (e *mdxExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		gmparser.WithBlockParsers(util.Prioritized(&mdxBlockParser{}, mdxBlockParserPriority)),
+	)
+}
@@ -0,0 +1,76 @@
+package parser
+
+import "testing"
+
+func TestGoldmarkParser_ParseMDX(t *testing.T) {
+	parser := NewWithOptions(Options{Dialect: DialectMDX})
+	content := []byte(`import { Tabs } from './tabs'
+export const meta = {
+  title: 'Hi',
+}
+
+Text with <Badge /> inline.
+
+<Chart data={[
+  { x: 1 },
+]} />
+
+<Tabs>
+
+*   markdown inside
+
+</Tabs>
+
+{/* comment */}
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 5 {
+		t.Fatalf("Expected 5 top-level nodes, got %s", DebugString(doc))
+	}
+
+	esm, ok := doc.Children[0].(*MDXBlock)
+	if !ok || esm.Kind != MDXKindESM {
+		t.Fatalf("Expected ESM block, got %s", doc.Children[0])
+	}
+	if expected := "import { Tabs } from './tabs'\nexport const meta = {\n  title: 'Hi',\n}\n"; esm.Content != expected {
+		t.Errorf("Expected ESM verbatim %q, got %q", expected, esm.Content)
+	}
+
+	if para, ok := doc.Children[1].(*Paragraph); !ok || para.Text != "Text with <Badge /> inline." {
+		t.Errorf("Expected paragraph with inline JSX, got %s", doc.Children[1])
+	}
+
+	chart, ok := doc.Children[2].(*MDXBlock)
+	if !ok || chart.Kind != MDXKindJSX {
+		t.Fatalf("Expected JSX block, got %s", doc.Children[2])
+	}
+	if expected := "<Chart data={[\n  { x: 1 },\n]} />\n"; chart.Content != expected {
+		t.Errorf("Expected JSX verbatim %q, got %q", expected, chart.Content)
+	}
+
+	tabs, ok := doc.Children[3].(*MDXElement)
+	if !ok {
+		t.Fatalf("Expected MDXElement, got %s", doc.Children[3])
+	}
+	if len(tabs.Children) != 1 || tabs.Children[0].Type() != NodeList {
+		t.Errorf("Expected markdown list inside element, got %d children", len(tabs.Children))
+	}
+
+	if expr, ok := doc.Children[4].(*MDXBlock); !ok || expr.Kind != MDXKindExpression {
+		t.Errorf("Expected expression block, got %s", doc.Children[4])
+	}
+}
+
+func TestGoldmarkParser_MDXDisabled(t *testing.T) {
+	doc, err := NewGoldmarkParser().Parse([]byte("import the data before exporting it\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, ok := doc.Children[0].(*Paragraph); !ok {
+		t.Errorf("Expected import prose to be a paragraph outside MDX, got %s", doc.Children[0])
+	}
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Constants
+const (
+	// wikiLinkParserPriority runs before goldmark's link parser (200)
+	wikiLinkParserPriority = 199
+	// tagParserPriority runs with the other inline parsers
+	tagParserPriority = 999
+)
+
+var (
+	// KindWikiLink is the goldmark node kind for [[wiki links]] and ![[embeds]]
+	KindWikiLink = ast.NewNodeKind("WikiLink")
+	// KindTag is the goldmark node kind for #tags
+	KindTag = ast.NewNodeKind("Tag")
+
+	wikiLinkOpen  = []byte("[[")
+	wikiLinkClose = []byte("]]")
+)
+
+// wikiLinkNode is a goldmark inline holding a wiki link verbatim
+type wikiLinkNode struct {
+	ast.BaseInline
+	Segment text.Segment
+}
+
+// Kind implements ast.Node.
+func (n *wikiLinkNode) Kind() ast.NodeKind { return KindWikiLink }
+
+// Dump implements ast.Node.
+func (n *wikiLinkNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Value": string(n.Segment.Value(source))}, nil)
+}
+
+// tagNode is a goldmark inline holding a #tag verbatim
+type tagNode struct {
+	ast.BaseInline
+	Segment text.Segment
+}
+
+// Kind implements ast.Node.
+func (n *tagNode) Kind() ast.NodeKind { return KindTag }
+
+// Dump implements ast.Node.
+func (n *tagNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Value": string(n.Segment.Value(source))}, nil)
+}
+
+// wikiLinkParser parses [[target|alias]] links and ![[target]] embeds
+type wikiLinkParser struct{}
+
+// Trigger implements parser.InlineParser.
+func (s *wikiLinkParser) Trigger() []byte {
+	return []byte{'[', '!'}
+}
+
+// Parse implements parser.InlineParser.
+func (s *wikiLinkParser) Parse(_ ast.Node, block text.Reader, _ gmparser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	start := 0
+	if line[0] == '!' {
+		start = 1
+	}
+	if !bytes.HasPrefix(line[start:], wikiLinkOpen) {
+		return nil
+	}
+
+	end := bytes.Index(line[start+len(wikiLinkOpen):], wikiLinkClose)
+	if end <= 0 {
+		return nil
+	}
+	target := line[start+len(wikiLinkOpen) : start+len(wikiLinkOpen)+end]
+	if bytes.ContainsAny(target, "[]") {
+		return nil
+	}
+
+	length := start + len(wikiLinkOpen) + end + len(wikiLinkClose)
+	node := &wikiLinkNode{Segment: segment.WithStop(segment.Start + length)}
+	block.Advance(length)
+	return node
+}
+
+// tagParser parses #tags that start a word
+type tagParser struct{}
+
+// Trigger implements parser.InlineParser.
+func (s *tagParser) Trigger() []byte {
+	return []byte{'#'}
+}
+
+// Parse implements parser.InlineParser.
+func (s *tagParser) Parse(_ ast.Node, block text.Reader, _ gmparser.Context) ast.Node {
+	if before := block.PrecendingCharacter(); before != '\n' && !unicode.IsSpace(before) && before != '(' {
+		return nil
+	}
+
+	line, segment := block.PeekLine()
+	length, digitsOnly := 1, true
+	for length < len(line) {
+		r, size := utf8.DecodeRune(line[length:])
+		if !isTagRune(r) {
+			break
+		}
+		if !unicode.IsDigit(r) {
+			digitsOnly = false
+		}
+		length += size
+	}
+	// "#" alone and issue references such as #123 are not tags
+	if length == 1 || digitsOnly {
+		return nil
+	}
+
+	node := &tagNode{Segment: segment.WithStop(segment.Start + length)}
+	block.Advance(length)
+	return node
+}
+
+// isTagRune reports whether r may appear in a tag name
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '/'
+}
+
+// obsidianExtension registers wiki link and tag parsers with goldmark
+type obsidianExtension struct{}
+
+// Extend implements goldmark.Extender.
+func (e *obsidianExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(gmparser.WithInlineParsers(
+		util.Prioritized(&wikiLinkParser{}, wikiLinkParserPriority),
+		util.Prioritized(&tagParser{}, tagParserPriority),
+	))
+}
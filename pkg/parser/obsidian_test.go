@@ -0,0 +1,34 @@
+package parser
+
+import "testing"
+
+func TestGoldmarkParser_ParseObsidian(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"wiki link", "See [[Internal   Links|alias]] now", "See [[Internal   Links|alias]] now"},
+		{"embed", "![[diagram one.png]]", "![[diagram one.png]]"},
+		{"tags", "Tagged #project/alpha and #todo.", "Tagged #project/alpha and #todo."},
+		{"issue reference", "Fixes #123", "Fixes #123"},
+		{"regular link", "A [link](https://example.com)", "A [link](https://example.com)"},
+	}
+
+	parser := NewWithOptions(Options{Dialect: DialectObsidian})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			para, ok := doc.Children[0].(*Paragraph)
+			if !ok {
+				t.Fatalf("Expected Paragraph, got %T", doc.Children[0])
+			}
+			if para.Text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, para.Text)
+			}
+		})
+	}
+}
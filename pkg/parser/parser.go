@@ -1,17 +1,95 @@
 package parser
 
+import "context"
+
 // Parser interface defines methods for parsing Markdown content and validating the parser
 type Parser interface {
 	Parse(content []byte) (*Document, error)
 	Validate() error
 }
 
+// ContextParser is implemented by parsers that stop early when their
+// context is canceled
+type ContextParser interface {
+	ParseContext(ctx context.Context, content []byte) (*Document, error)
+}
+
+// ParseContext parses content with p, giving up once ctx is done. Parsers
+// that do not implement ContextParser are only checked before and after
+// parsing.
+func ParseContext(ctx context.Context, p Parser, content []byte) (*Document, error) {
+	if cp, ok := p.(ContextParser); ok {
+		return cp.ParseContext(ctx, content)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	doc, err := p.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Parser option values
+const (
+	// ExtensionMath enables $...$ inline and $$...$$ display math
+	ExtensionMath = "math"
+	// ExtensionHighlight enables ==highlight== marks
+	ExtensionHighlight = "highlight"
+
+	// DialectCommonMark parses plain CommonMark, without tables,
+	// strikethrough, task lists, bare URL autolinks or definition lists
+	DialectCommonMark = "commonmark"
+	// DialectGFM parses GitHub Flavored Markdown (the default)
+	DialectGFM = "gfm"
+	// DialectMDX additionally keeps JSX blocks and ESM import/export lines verbatim
+	DialectMDX = "mdx"
+	// DialectObsidian additionally keeps [[wiki links]], ![[embeds]] and #tags verbatim
+	DialectObsidian = "obsidian"
+)
+
+// Options configures optional parser features
+type Options struct {
+	// Dialect selects the markdown flavor; empty means DialectGFM
+	Dialect string
+	// Extensions lists the optional syntax extensions to enable
+	Extensions []string
+	// Positions records the source line of each block and list item, for
+	// Document.Line
+	Positions bool
+	// Strict fails parsing with a *ParseError at the first syntax that is
+	// recognized but not enabled, such as math without the math extension,
+	// instead of copying it verbatim and reporting a diagnostic
+	Strict bool
+}
+
+// hasExtension reports whether the named extension is enabled
+func (o Options) hasExtension(name string) bool {
+	for _, ext := range o.Extensions {
+		if ext == name {
+			return true
+		}
+	}
+	return false
+}
+
 // New creates a new parser instance using the default parser implementation
 func New() Parser {
 	return NewGoldmarkParser()
 }
 
+// NewWithOptions creates a new parser instance with the given options
+func NewWithOptions(opts Options) Parser {
+	return NewGoldmarkParserWithOptions(opts)
+}
+
 // DefaultParser returns the default parser implementation using the Goldmark parser
+//
+// Deprecated: use New, which returns the same parser.
 func DefaultParser() Parser {
 	return NewGoldmarkParser()
 }
@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Parser priorities of the inline text parser
+const (
+	paragraphParserPriority     = 1000
+	strikethroughParserPriority = 500
+)
+
+// InlineStyle is a kind of inline markup whose text StyledInlineText passes
+// to a styling function
+type InlineStyle int
+
+// Inline styles
+const (
+	// StyleEmphasis is *emphasis*
+	StyleEmphasis InlineStyle = iota
+	// StyleStrong is **strong emphasis**
+	StyleStrong
+	// StyleCode is a `code span`
+	StyleCode
+	// StyleLink is the text of a link or autolink
+	StyleLink
+	// StyleImage is the alt text of an image
+	StyleImage
+	// StyleStrikethrough is ~~deleted~~ text
+	StyleStrikethrough
+)
+
+// inlineTextParser parses inline markdown only: every line is paragraph
+// text, so "1. Intro" or "# tag" in a heading or list item is not taken for
+// a block of its own
+var inlineTextParser = gmparser.NewParser(
+	gmparser.WithBlockParsers(util.Prioritized(gmparser.NewParagraphParser(), paragraphParserPriority)),
+	gmparser.WithInlineParsers(append(gmparser.DefaultInlineParsers(),
+		util.Prioritized(extension.NewStrikethroughParser(), strikethroughParserPriority))...),
+)
+
+// InlineText returns inline markdown text without its markup: emphasis,
+// code span and strikethrough delimiters are removed, links and images are
+// replaced by their text and alt text, autolinks by their address, raw
+// HTML is dropped, and escapes and entities are resolved. Reference links
+// resolve through definitions. Line breaks are kept.
+func InlineText(markdown string, definitions []*LinkDefinition) string {
+	return StyledInlineText(markdown, definitions, nil)
+}
+
+// StyledInlineText returns inline markdown text without its markup like
+// InlineText, passing the text of each styled span through style, which may
+// be nil. Spans nest, so style may be called with styled text.
+func StyledInlineText(markdown string, definitions []*LinkDefinition,
+	style func(InlineStyle, string) string) string {
+	source := []byte(markdown)
+	pc := gmparser.NewContext()
+	for _, d := range definitions {
+		pc.AddReference(gmparser.NewReference([]byte(d.Label), []byte(d.Destination), []byte(d.Title)))
+	}
+	doc := inlineTextParser.Parse(text.NewReader(source), gmparser.WithContext(pc))
+
+	w := inlineTextWriter{source: source, style: style}
+	for block := doc.FirstChild(); block != nil; block = block.NextSibling() {
+		if w.buf.Len() > 0 {
+			w.buf.WriteByte('\n')
+		}
+		w.write(block)
+	}
+	return w.buf.String()
+}
+
+// inlineTextWriter collects the text of inline nodes
+type inlineTextWriter struct {
+	buf    bytes.Buffer
+	source []byte
+	style  func(InlineStyle, string) string
+}
+
+// write writes the text of the inline node n and its children
+func (w *inlineTextWriter) write(n ast.Node) {
+	switch node := n.(type) {
+	case *ast.Text:
+		value := node.Segment.Value(w.source)
+		w.buf.Write(util.UnescapePunctuations(util.ResolveNumericReferences(util.ResolveEntityNames(value))))
+		if node.SoftLineBreak() || node.HardLineBreak() {
+			w.buf.WriteByte('\n')
+		}
+	case *ast.String:
+		w.buf.Write(node.Value)
+	case *ast.CodeSpan:
+		// Code is neither escaped nor made of entities
+		var code bytes.Buffer
+		for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+			if t, ok := child.(*ast.Text); ok {
+				code.Write(t.Segment.Value(w.source))
+			}
+		}
+		w.writeStyled(StyleCode, code.String())
+	case *ast.AutoLink:
+		w.writeStyled(StyleLink, string(node.Label(w.source)))
+	case *ast.RawHTML:
+		// Tags are dropped, the text between them is kept
+	case *ast.Emphasis:
+		if node.Level >= StrongEmphasisLevel {
+			w.writeChildren(StyleStrong, n)
+		} else {
+			w.writeChildren(StyleEmphasis, n)
+		}
+	case *ast.Link:
+		w.writeChildren(StyleLink, n)
+	case *ast.Image:
+		w.writeChildren(StyleImage, n)
+	case *extast.Strikethrough:
+		w.writeChildren(StyleStrikethrough, n)
+	default:
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			w.write(child)
+		}
+	}
+}
+
+// writeChildren writes the text of the children of n in style
+func (w *inlineTextWriter) writeChildren(style InlineStyle, n ast.Node) {
+	if w.style == nil {
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			w.write(child)
+		}
+		return
+	}
+	inner := inlineTextWriter{source: w.source, style: w.style}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		inner.write(child)
+	}
+	w.writeStyled(style, inner.buf.String())
+}
+
+// writeStyled writes text in style
+func (w *inlineTextWriter) writeStyled(style InlineStyle, text string) {
+	if w.style != nil {
+		text = w.style(style, text)
+	}
+	w.buf.WriteString(text)
+}
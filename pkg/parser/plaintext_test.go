@@ -0,0 +1,28 @@
+package parser
+
+import "testing"
+
+func TestInlineText(t *testing.T) {
+	definitions := []*LinkDefinition{{Label: "Docs", Destination: "https://example.com"}}
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{"emphasis", "Some *em*, __strong__ and ~~deleted~~ text", "Some em, strong and deleted text"},
+		{"links", "A [link](https://example.com) and ![alt *text*](logo.png)", "A link and alt text"},
+		{"references", "See [the guide][docs] or [Docs]", "See the guide or Docs"},
+		{"autolinks", "Mail <me@example.com> or <https://example.com>", "Mail me@example.com or https://example.com"},
+		{"html", "Press <kbd>Ctrl</kbd>", "Press Ctrl"},
+		{"code", "Run `a\\*b` \\*now\\* &amp; later", "Run a\\*b *now* & later"},
+		{"line breaks", "one\ntwo  \nthree", "one\ntwo\nthree"},
+		{"block syntax", "1. Introduction", "1. Introduction"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InlineText(tt.markdown, definitions); got != tt.expected {
+				t.Errorf("InlineText(%q) = %q, expected %q", tt.markdown, got, tt.expected)
+			}
+		})
+	}
+}
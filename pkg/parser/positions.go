@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// lineIndex holds the offsets at which the lines of a source start
+type lineIndex []int
+
+// newLineIndex indexes the lines of source
+func newLineIndex(source []byte) lineIndex {
+	index := lineIndex{0}
+	for i, b := range source {
+		if b == '\n' {
+			index = append(index, i+1)
+		}
+	}
+	return index
+}
+
+// line returns the 1-based line containing offset
+func (index lineIndex) line(offset int) int {
+	return sort.Search(len(index), func(i int) bool { return index[i] > offset })
+}
+
+// blockLine returns the line where the goldmark block n starts, given the
+// offset of its first content
+func (index lineIndex) blockLine(n ast.Node, offset int) int {
+	if code, ok := n.(*ast.FencedCodeBlock); ok {
+		switch {
+		case code.Info != nil:
+			return index.line(code.Info.Segment.Start)
+		case code.Lines().Len() > 0:
+			// The content starts on the line after the opening fence
+			return index.line(code.Lines().At(0).Start) - 1
+		}
+	}
+	return index.line(offset)
+}
+
+// recordDefinitionLines records the line of each run of link reference
+// definitions among nodes, which is the line of its first definition
+func recordDefinitionLines(lines map[Node]int, nodes []Node, definitions []positionedDefinition, index lineIndex) {
+	offsets := make(map[*LinkDefinition]int, len(definitions))
+	for _, d := range definitions {
+		offsets[d.definition] = d.offset
+	}
+	for _, node := range nodes {
+		if group, ok := node.(*LinkDefinitions); ok && len(group.Definitions) > 0 {
+			lines[node] = index.line(offsets[group.Definitions[0]])
+		}
+	}
+}
+
+// definitionLines returns the line of each link reference definition
+func definitionLines(definitions []positionedDefinition, source []byte) map[*LinkDefinition]int {
+	if len(definitions) == 0 {
+		return nil
+	}
+	index := newLineIndex(source)
+	lines := make(map[*LinkDefinition]int, len(definitions))
+	for _, d := range definitions {
+		lines[d.definition] = index.line(d.offset)
+	}
+	return lines
+}
+
+// moveLine records the line of from, if known, as the line of to
+func moveLine(lines map[Node]int, from, to Node) {
+	if line, ok := lines[from]; ok {
+		lines[to] = line
+	}
+}
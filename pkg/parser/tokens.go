@@ -0,0 +1,380 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// HTMLBlockTag matches what follows the < of the HTML blocks that can
+// interrupt a paragraph: comments, processing instructions, declarations,
+// CDATA and the block-level tags of CommonMark
+const HTMLBlockTag = `(?:!--|\?|![A-Za-z]|!\[CDATA\[|(?i:script|pre|style|textarea)(?:[\s>]|$)|` +
+	`/?(?i:address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|` +
+	`dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[1-6]|head|header|hr|` +
+	`html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|` +
+	`section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(?:[\s>]|/>|$))`
+
+// htmlBlockStartPattern matches a word starting with HTML that would start
+// an HTML block at the start of a line
+var htmlBlockStartPattern = regexp.MustCompile(`^<` + HTMLBlockTag)
+
+// inlinePattern matches an inline construct that wrapping must keep whole
+type inlinePattern struct {
+	re *regexp.Regexp
+	// verbatim constructs are also never rewritten by inline formatting
+	verbatim bool
+	// code marks code spans, whose content is never markup
+	code bool
+	// digitAfter allows the match to be directly followed by a digit
+	digitAfter bool
+	// template marks the configured template tags
+	template bool
+}
+
+// autolinkPattern matches <scheme:...> and <user@host> autolinks
+var autolinkPattern = regexp.MustCompile(
+	`<(?:[A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*|[A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9.-]*[A-Za-z0-9])?)>`,
+)
+
+// inlinePatterns lists the atomic inline constructs, most specific first
+var inlinePatterns = []inlinePattern{
+	{re: regexp.MustCompile(`\$\$[^\s$](?:[^$]*[^\s$])?\$\$`), verbatim: true, digitAfter: true}, // inline display math
+	{re: regexp.MustCompile(`\$[^\s$](?:[^$]*[^\s$\\])?\$`), verbatim: true},                     // inline math
+	{re: autolinkPattern, verbatim: true, digitAfter: true},
+	{re: regexp.MustCompile(`!?\[\[[^\[\]\n]+\]\]`), verbatim: true, digitAfter: true}, // wiki links                                      // autolinks
+	{re: regexp.MustCompile("``(?:[^`]|`[^`])+``"), code: true, digitAfter: true},      // double backtick code spans
+	{re: regexp.MustCompile("`[^`]+`"), code: true, digitAfter: true},                  // code spans
+	{re: regexp.MustCompile(`\[[^\]]*\]\([^)]*\)`), digitAfter: true},                  // inline links
+}
+
+// InlineSyntax is a set of atomic inline constructs: the built-in ones and
+// the configured template tags
+type InlineSyntax struct {
+	patterns []inlinePattern
+	keepTabs bool
+}
+
+// defaultSyntax has the built-in constructs and no template tags
+var defaultSyntax = &InlineSyntax{patterns: inlinePatterns}
+
+// templateSyntaxes caches the syntaxes of delimiter lists by key
+var templateSyntaxes sync.Map
+
+// TemplateSyntax returns the inline syntax that also keeps template tags
+// whole and unchanged, such as {{ .Values.name }} or {% if %}. Each entry of
+// delimiters is an open and a close delimiter separated by whitespace;
+// malformed entries are ignored.
+func TemplateSyntax(delimiters []string) *InlineSyntax {
+	if len(delimiters) == 0 {
+		return defaultSyntax
+	}
+	key := strings.Join(delimiters, "\x00")
+	if syntax, ok := templateSyntaxes.Load(key); ok {
+		return syntax.(*InlineSyntax)
+	}
+
+	// Template tags come first: their content may look like any other construct
+	patterns := make([]inlinePattern, 0, len(delimiters)+len(inlinePatterns))
+	for _, entry := range delimiters {
+		pair := strings.Fields(entry)
+		if len(pair) != 2 {
+			continue
+		}
+		patterns = append(patterns, inlinePattern{
+			re:         regexp.MustCompile(regexp.QuoteMeta(pair[0]) + `(?s:.*?)` + regexp.QuoteMeta(pair[1])),
+			verbatim:   true,
+			digitAfter: true,
+			template:   true,
+		})
+	}
+	syntax := &InlineSyntax{patterns: append(patterns, inlinePatterns...)}
+	actual, _ := templateSyntaxes.LoadOrStore(key, syntax)
+	return actual.(*InlineSyntax)
+}
+
+// KeepingTabs returns the syntax that keeps tabs within words rather than
+// splitting words at them
+func (s *InlineSyntax) KeepingTabs() *InlineSyntax {
+	keeping := *s
+	keeping.keepTabs = true
+	return &keeping
+}
+
+// KeepsTabs reports whether tabs are kept within words
+func (s *InlineSyntax) KeepsTabs() bool { return s.keepTabs }
+
+// Span is a piece of inline text. Verbatim spans must be emitted unchanged.
+type Span struct {
+	Text     string
+	Verbatim bool
+}
+
+// SplitVerbatim splits inline text into prose spans and verbatim spans such as math.
+func SplitVerbatim(text string) []Span {
+	return defaultSyntax.SplitVerbatim(text)
+}
+
+// SplitVerbatim splits inline text into prose spans and verbatim spans such
+// as math and template tags.
+func (s *InlineSyntax) SplitVerbatim(text string) []Span {
+	var spans []Span
+	last := 0
+	for _, r := range s.matchRanges(text, isVerbatim) {
+		if r[0] > last {
+			spans = append(spans, Span{Text: text[last:r[0]]})
+		}
+		spans = append(spans, Span{Text: text[r[0]:r[1]], Verbatim: true})
+		last = r[1]
+	}
+	if last < len(text) {
+		spans = append(spans, Span{Text: text[last:]})
+	}
+	return spans
+}
+
+// MapProse applies fn to the prose parts of text, leaving verbatim spans untouched.
+func MapProse(text string, fn func(string) string) string {
+	return defaultSyntax.MapProse(text, fn)
+}
+
+// MapProse applies fn to the prose parts of text, leaving verbatim spans untouched.
+func (s *InlineSyntax) MapProse(text string, fn func(string) string) string {
+	var sb strings.Builder
+	for _, span := range s.SplitVerbatim(text) {
+		if span.Verbatim {
+			sb.WriteString(span.Text)
+		} else {
+			sb.WriteString(fn(span.Text))
+		}
+	}
+	return sb.String()
+}
+
+// MapPlain applies fn to the parts of text outside atomic inline constructs
+// (math, autolinks, code spans and links).
+func MapPlain(text string, fn func(string) string) string {
+	return defaultSyntax.MapPlain(text, fn)
+}
+
+// MapPlain applies fn to the parts of text outside atomic inline constructs.
+func (s *InlineSyntax) MapPlain(text string, fn func(string) string) string {
+	return mapOutside(text, s.matchRanges(text, isAny), fn)
+}
+
+// MapOutsideCode applies fn to the parts of text outside code spans and
+// verbatim constructs, so that links remain visible to fn.
+func MapOutsideCode(text string, fn func(string) string) string {
+	return defaultSyntax.MapOutsideCode(text, fn)
+}
+
+// MapOutsideCode applies fn to the parts of text outside code spans and
+// verbatim constructs, so that links remain visible to fn.
+func (s *InlineSyntax) MapOutsideCode(text string, fn func(string) string) string {
+	return mapOutside(text, s.matchRanges(text, isVerbatimOrCode), fn)
+}
+
+// mapOutside applies fn to the parts of text between ranges
+func mapOutside(text string, ranges [][]int, fn func(string) string) string {
+	var sb strings.Builder
+	last := 0
+	for _, r := range ranges {
+		sb.WriteString(fn(text[last:r[0]]))
+		sb.WriteString(text[r[0]:r[1]])
+		last = r[1]
+	}
+	sb.WriteString(fn(text[last:]))
+	return sb.String()
+}
+
+// HardBreak is a hard line break in block text: a backslash ending the line
+const HardBreak = "\\\n"
+
+// SplitHardBreaks splits text at its hard breaks, dropping them, so each
+// part can be wrapped on its own and joined again with HardBreak
+func SplitHardBreaks(text string) []string {
+	var parts []string
+	start, end := 0, 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		end += len(line)
+		if strings.HasSuffix(line, "\n") && IsHardBreak(line) {
+			parts = append(parts, text[start:end-len(HardBreak)])
+			start = end
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// IsHardBreak reports whether line, with or without its newline, ends in a
+// hard break: an odd number of backslashes, the others escaping each other
+func IsHardBreak(line string) bool {
+	line = strings.TrimSuffix(line, "\n")
+	trimmed := strings.TrimRight(line, `\`)
+	return (len(line)-len(trimmed))%2 == 1
+}
+
+// IsTagLine reports whether line holds nothing but template tags, such as
+// {% for item in items %}
+func (s *InlineSyntax) IsTagLine(line string) bool {
+	rest := strings.TrimSpace(line)
+	if rest == "" {
+		return false
+	}
+	for rest != "" {
+		n := s.tagAt(rest)
+		if n == 0 {
+			return false
+		}
+		rest = strings.TrimLeft(rest[n:], " \t")
+	}
+	return true
+}
+
+// tagAt returns the length of the template tag text starts with, or 0
+func (s *InlineSyntax) tagAt(text string) int {
+	for _, p := range s.patterns {
+		if loc := p.re.FindStringIndex(text); p.template && loc != nil && loc[0] == 0 {
+			return loc[1]
+		}
+	}
+	return 0
+}
+
+// SplitTagLines splits text before and after its lines holding nothing but
+// template tags, so each part can be wrapped on its own and joined again
+// with newlines. Wrapped into the text around them, block tags such as
+// {% endfor %} would change what the template renders.
+func (s *InlineSyntax) SplitTagLines(text string) []string {
+	var parts, prose []string
+	for _, line := range strings.Split(text, "\n") {
+		if !s.IsTagLine(line) {
+			prose = append(prose, line)
+			continue
+		}
+		if len(prose) > 0 {
+			parts = append(parts, strings.Join(prose, "\n"))
+			prose = nil
+		}
+		parts = append(parts, line)
+	}
+	if len(prose) > 0 {
+		parts = append(parts, strings.Join(prose, "\n"))
+	}
+	return parts
+}
+
+// WrapTokens splits text into whitespace-separated words for reflow, keeping
+// atomic inline constructs (math, code spans, links) whole.
+func WrapTokens(text string) []string {
+	return defaultSyntax.WrapTokens(text)
+}
+
+// WrapTokens splits text into whitespace-separated words for reflow, keeping
+// atomic inline constructs, including template tags, whole.
+func (s *InlineSyntax) WrapTokens(text string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	isSpace := isWrapSpace
+	if s.keepTabs {
+		isSpace = func(r rune) bool { return r != '\t' && isWrapSpace(r) }
+	}
+
+	last := 0
+	for _, r := range s.matchRanges(text, isAny) {
+		appendWords(text[last:r[0]], isSpace, &current, flush)
+		// Atomic constructs glue to adjacent punctuation, e.g. "($x$)."
+		// A soft break inside a code span or link text renders as a space.
+		current.WriteString(strings.ReplaceAll(text[r[0]:r[1]], "\n", " "))
+		last = r[1]
+	}
+	appendWords(text[last:], isSpace, &current, flush)
+	flush()
+
+	// Inline HTML such as <div> would start an HTML block at the start of a
+	// line, so it stays on the line of the word before it
+	glued := tokens[:0]
+	for _, token := range tokens {
+		if len(glued) > 0 && htmlBlockStartPattern.MatchString(token) {
+			glued[len(glued)-1] += " " + token
+			continue
+		}
+		glued = append(glued, token)
+	}
+	return glued
+}
+
+// appendWords splits s on whitespace, extending the current token with any
+// leading text and flushing at each whitespace boundary
+func appendWords(s string, isSpace func(rune) bool, current *strings.Builder, flush func()) {
+	for i, word := range strings.FieldsFunc(s, isSpace) {
+		if i > 0 || isSpace(rune(s[0])) {
+			flush()
+		}
+		current.WriteString(word)
+	}
+	if s != "" && isSpace(rune(s[len(s)-1])) {
+		flush()
+	}
+}
+
+// isWrapSpace reports whether r separates words during reflow
+func isWrapSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// matchRanges returns the non-overlapping ranges of the atomic inline
+// constructs selected by include, in order
+func (s *InlineSyntax) matchRanges(text string, include func(inlinePattern) bool) [][]int {
+	var ranges [][]int
+	for pos := 0; pos < len(text); {
+		best := []int(nil)
+		for _, p := range s.patterns {
+			if !include(p) {
+				continue
+			}
+			loc := nextMatch(text, pos, p)
+			if loc != nil && (best == nil || loc[0] < best[0]) {
+				best = loc
+			}
+		}
+		if best == nil {
+			break
+		}
+		ranges = append(ranges, best)
+		pos = best[1]
+	}
+	return ranges
+}
+
+// isVerbatim selects verbatim constructs
+func isVerbatim(p inlinePattern) bool { return p.verbatim }
+
+// isVerbatimOrCode selects verbatim constructs and code spans
+func isVerbatimOrCode(p inlinePattern) bool { return p.verbatim || p.code }
+
+// isAny selects every atomic construct
+func isAny(inlinePattern) bool { return true }
+
+// nextMatch finds the next acceptable match of p at or after pos
+func nextMatch(text string, pos int, p inlinePattern) []int {
+	for pos < len(text) {
+		loc := p.re.FindStringIndex(text[pos:])
+		if loc == nil {
+			return nil
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		// "$5 and $10" is not math: a closing $ must not precede a digit
+		if p.digitAfter || end >= len(text) || text[end] < '0' || text[end] > '9' {
+			return []int{start, end}
+		}
+		pos = start + 1
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTemplateSyntax_WrapTokens(t *testing.T) {
+	syntax := TemplateSyntax([]string{"{{ }}", "{% %}", "{# #}"})
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"go template", "name: {{ .Values.name }}.", []string{"name:", "{{ .Values.name }}."}},
+		{"liquid tag", "{% if page.draft %}draft{% endif %}", []string{"{% if page.draft %}draft{% endif %}"}},
+		{"comment", "a {# b  c #} d", []string{"a", "{# b  c #}", "d"}},
+		{"link in template", `{{ "[x](y)" | md }}`, []string{`{{ "[x](y)" | md }}`}},
+		{"unclosed", "{{ a b", []string{"{{", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syntax.WrapTokens(tt.text); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("WrapTokens(%q) = %q, expected %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTemplateSyntax_MapProse(t *testing.T) {
+	syntax := TemplateSyntax([]string{"<% %>"})
+	got := syntax.MapProse("a  <%=  x  %>  b", func(s string) string { return "<" + s + ">" })
+	if expected := "<a  ><%=  x  %><  b>"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if TemplateSyntax([]string{"<% %>"}) != syntax {
+		t.Error("Expected the syntax of the same delimiters to be cached")
+	}
+	if TemplateSyntax(nil).WrapTokens("{{ a }}")[0] != "{{" {
+		t.Error("Expected no template tags without delimiters")
+	}
+}
+
+func TestTemplateSyntax_SplitTagLines(t *testing.T) {
+	syntax := TemplateSyntax([]string{"{{ }}", "{% %}"})
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"block tags", "{% for item in items %}\n{{ item }} and more\n{% endfor %}",
+			[]string{"{% for item in items %}", "{{ item }} and more", "{% endfor %}"}},
+		{"several tags", "text\n{% if a %} {{ b }}\nmore\ntext", []string{"text", "{% if a %} {{ b }}", "more\ntext"}},
+		{"inline tag", "a {% if b %}c{% endif %}\nd", []string{"a {% if b %}c{% endif %}\nd"}},
+		{"unclosed", "{% if a\nb %}", []string{"{% if a\nb %}"}},
+		{"empty", "", []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syntax.SplitTagLines(tt.text); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SplitTagLines(%q) = %q, expected %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+
+	if TemplateSyntax(nil).IsTagLine("{{ a }}") {
+		t.Error("Expected no tag lines without delimiters")
+	}
+}
+
+func TestSplitHardBreaks(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"one two", []string{"one two"}},
+		{"one\\\ntwo\nthree\\\nfour", []string{"one", "two\nthree", "four"}},
+		{"escaped\\\\\nline", []string{"escaped\\\\\nline"}},
+		{"odd\\\\\\\nline", []string{"odd\\\\", "line"}},
+		{"ends with\\", []string{"ends with\\"}},
+	}
+	for _, tt := range tests {
+		if got := SplitHardBreaks(tt.text); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("SplitHardBreaks(%q) = %q, expected %q", tt.text, got, tt.expected)
+		}
+	}
+}
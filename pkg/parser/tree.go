@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errNotInTree is returned for nodes that are not below the root of a tree
+var errNotInTree = errors.New("node is not in the tree")
+
+// Tree links the nodes below a root to their parents, so formatters can
+// find the neighbors of a node and restructure the document around it.
+// Nodes hold no links themselves: changes must go through the tree to keep
+// its links current.
+type Tree struct {
+	root    Node
+	parents map[Node]Node
+}
+
+// NewTree links the nodes below root
+func NewTree(root Node) *Tree {
+	t := &Tree{root: root, parents: make(map[Node]Node)}
+	t.link(root)
+	return t
+}
+
+// link records the parents of the descendants of node
+func (t *Tree) link(node Node) {
+	for _, child := range Children(node) {
+		t.parents[child] = node
+		t.link(child)
+	}
+}
+
+// unlink forgets node and its descendants
+func (t *Tree) unlink(node Node) {
+	delete(t.parents, node)
+	for _, child := range Children(node) {
+		t.unlink(child)
+	}
+}
+
+// Root returns the root of the tree
+func (t *Tree) Root() Node { return t.root }
+
+// Parent returns the parent of node, or nil for the root and nodes that are
+// not in the tree
+func (t *Tree) Parent(node Node) Node {
+	return t.parents[node]
+}
+
+// PrevSibling returns the node before node in its parent, or nil
+func (t *Tree) PrevSibling(node Node) Node {
+	return t.sibling(node, -1)
+}
+
+// NextSibling returns the node after node in its parent, or nil
+func (t *Tree) NextSibling(node Node) Node {
+	return t.sibling(node, 1)
+}
+
+// sibling returns the child of the parent of node offset positions from it
+func (t *Tree) sibling(node Node, offset int) Node {
+	parent, children, i := t.position(node)
+	if parent == nil || i+offset < 0 || i+offset >= len(children) {
+		return nil
+	}
+	return children[i+offset]
+}
+
+// position returns the parent of node, its children and the index of node
+// among them; parent is nil for nodes that are not in the tree
+func (t *Tree) position(node Node) (parent Node, children []Node, index int) {
+	parent = t.parents[node]
+	if parent == nil {
+		return nil, nil, -1
+	}
+	children = Children(parent)
+	for i, child := range children {
+		if child == node {
+			return parent, children, i
+		}
+	}
+	return nil, nil, -1
+}
+
+// InsertBefore inserts node before sibling in the parent of sibling
+func (t *Tree) InsertBefore(sibling, node Node) error {
+	return t.insert(sibling, node, 0)
+}
+
+// InsertAfter inserts node after sibling in the parent of sibling
+func (t *Tree) InsertAfter(sibling, node Node) error {
+	return t.insert(sibling, node, 1)
+}
+
+// insert inserts node offset positions after the position of sibling
+func (t *Tree) insert(sibling, node Node, offset int) error {
+	parent, children, i := t.position(sibling)
+	if parent == nil {
+		return fmt.Errorf("cannot insert next to %s: %w", NodeTypeString(sibling.Type()), errNotInTree)
+	}
+	if err := checkChild(parent, node); err != nil {
+		return err
+	}
+	i += offset
+	children = append(children[:i], append([]Node{node}, children[i:]...)...)
+	setChildren(parent, children)
+	t.parents[node] = parent
+	t.link(node)
+	return nil
+}
+
+// ReplaceWith replaces old with node in the parent of old
+func (t *Tree) ReplaceWith(old, node Node) error {
+	parent, children, i := t.position(old)
+	if parent == nil {
+		return fmt.Errorf("cannot replace %s: %w", NodeTypeString(old.Type()), errNotInTree)
+	}
+	if err := checkChild(parent, node); err != nil {
+		return err
+	}
+	children[i] = node
+	setChildren(parent, children)
+	t.unlink(old)
+	t.parents[node] = parent
+	t.link(node)
+	return nil
+}
+
+// Remove removes node from its parent
+func (t *Tree) Remove(node Node) error {
+	parent, children, i := t.position(node)
+	if parent == nil {
+		return fmt.Errorf("cannot remove %s: %w", NodeTypeString(node.Type()), errNotInTree)
+	}
+	setChildren(parent, append(children[:i], children[i+1:]...))
+	t.unlink(node)
+	return nil
+}
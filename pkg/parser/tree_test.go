@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTreeNavigation(t *testing.T) {
+	doc := parseWalkSource(t)
+	tree := NewTree(doc)
+
+	heading := doc.Children[0]
+	list, ok := doc.Children[1].(*List)
+	if !ok {
+		t.Fatalf("Expected a list, got %s", DebugString(doc))
+	}
+	nested, ok := list.Items[0].Children[0].(*List)
+	if !ok {
+		t.Fatalf("Expected a nested list, got %s", DebugString(doc))
+	}
+
+	if tree.Parent(doc) != nil || tree.Parent(heading) != doc {
+		t.Error("Expected the document to be the parent of its blocks")
+	}
+	if tree.Parent(nested.Items[0]) != nested || tree.Parent(nested) != list.Items[0] {
+		t.Error("Expected nested list items linked to their lists")
+	}
+	if tree.PrevSibling(list) != heading || tree.NextSibling(heading) != list {
+		t.Error("Expected the heading and list to be siblings")
+	}
+	if tree.PrevSibling(heading) != nil || tree.NextSibling(doc.Children[len(doc.Children)-1]) != nil {
+		t.Error("Expected no siblings past the ends")
+	}
+	if tree.NextSibling(list.Items[0]) != list.Items[1] {
+		t.Error("Expected list items to be siblings")
+	}
+}
+
+func TestTreeMutation(t *testing.T) {
+	doc := parseWalkSource(t)
+	tree := NewTree(doc)
+	heading := doc.Children[0]
+	list := doc.Children[1].(*List)
+
+	intro := &Paragraph{Text: "Intro."}
+	if err := tree.InsertAfter(heading, intro); err != nil {
+		t.Fatalf("InsertAfter failed: %v", err)
+	}
+	if doc.Children[1] != intro || tree.Parent(intro) != doc || tree.NextSibling(intro) != list {
+		t.Errorf("Expected the paragraph after the heading, got %s", DebugString(doc))
+	}
+
+	item := &ListItem{Text: "zero", Marker: "-"}
+	if err := tree.InsertBefore(list.Items[0], item); err != nil {
+		t.Fatalf("InsertBefore failed: %v", err)
+	}
+	if list.Items[0] != item || len(list.Items) != 3 {
+		t.Errorf("Expected the item first, got %s", DebugString(doc))
+	}
+	if err := tree.InsertBefore(list.Items[0], &Paragraph{}); err == nil {
+		t.Error("Expected an error inserting a paragraph into a list")
+	}
+
+	title := &Heading{Level: 2, Text: "Title", Style: "atx"}
+	if err := tree.ReplaceWith(heading, title); err != nil {
+		t.Fatalf("ReplaceWith failed: %v", err)
+	}
+	if doc.Children[0] != title || tree.Parent(heading) != nil || tree.Parent(title) != doc {
+		t.Errorf("Expected the heading replaced, got %s", DebugString(doc))
+	}
+
+	nested := list.Items[1].Children[0]
+	if err := tree.Remove(list); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if tree.Parent(list) != nil || tree.Parent(nested) != nil || len(doc.Children) != 4 {
+		t.Errorf("Expected the list and its items unlinked, got %s", DebugString(doc))
+	}
+	if err := tree.Remove(list); !errors.Is(err, errNotInTree) {
+		t.Errorf("Expected removing a removed node to fail, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WalkStatus tells Walk how to continue after visiting a node
+type WalkStatus int
+
+// Walk statuses
+const (
+	// WalkContinue continues the walk with the children of the node
+	WalkContinue WalkStatus = iota
+	// WalkSkipChildren continues the walk after the node without visiting
+	// its children; on exit it is the same as WalkContinue
+	WalkSkipChildren
+	// WalkStop ends the walk
+	WalkStop
+)
+
+// errWalkRoot is returned when the root of a walk is replaced or removed
+var errWalkRoot = errors.New("the root of a walk cannot be replaced or removed")
+
+// WalkFunc is called by Walk when it enters a node, before its children,
+// and when it exits the node, after them. An error ends the walk and is
+// returned by Walk.
+type WalkFunc func(c *Cursor, entering bool) (WalkStatus, error)
+
+// Cursor is the position of a node in a walk. It is only valid during the
+// call it is passed to.
+type Cursor struct {
+	node    Node
+	parent  Node
+	depth   int
+	index   int
+	removed bool
+}
+
+// Node returns the current node
+func (c *Cursor) Node() Node { return c.node }
+
+// Parent returns the parent of the current node, or nil for the root
+func (c *Cursor) Parent() Node { return c.parent }
+
+// Depth returns the depth of the current node: 0 for the root, 1 for its
+// children and so on
+func (c *Cursor) Depth() int { return c.depth }
+
+// Index returns the position of the current node among the children of its
+// parent, or -1 for the root
+func (c *Cursor) Index() int { return c.index }
+
+// Replace replaces the current node with node in its parent. When called on
+// entering, the walk continues with the children of node. The items of a
+// list can only be replaced by other list items.
+func (c *Cursor) Replace(node Node) error {
+	if c.parent == nil {
+		return errWalkRoot
+	}
+	if err := checkChild(c.parent, node); err != nil {
+		return err
+	}
+	c.node = node
+	return nil
+}
+
+// Remove removes the current node from its parent. The walk continues with
+// its next sibling, without visiting its children or exiting it.
+func (c *Cursor) Remove() error {
+	if c.parent == nil {
+		return errWalkRoot
+	}
+	c.removed = true
+	return nil
+}
+
+// Walk visits root and all its descendants depth-first in document order,
+// including list items and the blocks nested in them, calling fn when it
+// enters and exits each node. The tree may be changed through the cursor.
+func Walk(root Node, fn WalkFunc) error {
+	_, err := walkNode(&Cursor{node: root, index: -1}, fn)
+	return err
+}
+
+// walkNode visits the node of c and its descendants, reporting whether the
+// walk ends
+func walkNode(c *Cursor, fn WalkFunc) (bool, error) {
+	status, err := fn(c, true)
+	if err != nil || status == WalkStop {
+		return true, err
+	}
+	if c.removed {
+		return false, nil
+	}
+	if status != WalkSkipChildren {
+		if stop, err := walkChildren(c, fn); stop || err != nil {
+			return true, err
+		}
+	}
+	status, err = fn(c, false)
+	return err != nil || status == WalkStop, err
+}
+
+// walkChildren visits the children of the node of c, applying the
+// replacements and removals made while visiting them
+func walkChildren(c *Cursor, fn WalkFunc) (bool, error) {
+	parent := c.node
+	children := Children(parent)
+	for i := 0; i < len(children); {
+		child := &Cursor{node: children[i], parent: parent, depth: c.depth + 1, index: i}
+		stop, err := walkNode(child, fn)
+
+		switch {
+		case child.removed:
+			children = append(children[:i], children[i+1:]...)
+			setChildren(parent, children)
+		case child.node != children[i]:
+			children[i] = child.node
+			setChildren(parent, children)
+			i++
+		default:
+			i++
+		}
+		if stop || err != nil {
+			return true, err
+		}
+	}
+	return false, nil
+}
+
+// Children returns the child nodes of node: the blocks of documents and
+// containers, the items of lists and the blocks nested in list items
+func Children(node Node) []Node {
+	switch n := node.(type) {
+	case *Document:
+		return n.Children
+	case *ListItem:
+		return n.Children
+	case *List:
+		items := make([]Node, len(n.Items))
+		for i, item := range n.Items {
+			items[i] = item
+		}
+		return items
+	case BlockContainer:
+		return n.Blocks()
+	}
+	return nil
+}
+
+// checkChild returns an error if node cannot be a child of parent: lists
+// only hold list items
+func checkChild(parent, node Node) error {
+	if _, ok := parent.(*List); ok {
+		if _, ok := node.(*ListItem); !ok {
+			return fmt.Errorf("a list cannot hold %s", NodeTypeString(node.Type()))
+		}
+	}
+	return nil
+}
+
+// setChildren replaces the child nodes of node, which are those Children
+// returns
+func setChildren(node Node, children []Node) {
+	switch n := node.(type) {
+	case *Document:
+		n.Children = children
+	case *ListItem:
+		n.Children = children
+	case *List:
+		n.Items = n.Items[:0]
+		for _, child := range children {
+			if item, ok := child.(*ListItem); ok {
+				n.Items = append(n.Items, item)
+			}
+		}
+	case *Details:
+		n.Children = children
+	case *Blockquote:
+		n.Children = children
+	case *Admonition:
+		n.Children = children
+	case *DefinitionList:
+		n.Children = children
+	case *DefinitionDescription:
+		n.Children = children
+	case *MDXElement:
+		n.Children = children
+	}
+}
@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const walkSource = `# Title
+
+- one
+  - nested
+- two
+
+> quoted
+
+Closing.
+`
+
+func parseWalkSource(t *testing.T) *Document {
+	t.Helper()
+	doc, err := NewGoldmarkParser().Parse([]byte(walkSource))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return doc
+}
+
+func TestWalkVisitsNestedNodes(t *testing.T) {
+	doc := parseWalkSource(t)
+
+	var events []string
+	err := Walk(doc, func(c *Cursor, entering bool) (WalkStatus, error) {
+		prefix := "exit"
+		if entering {
+			prefix = "enter"
+		}
+		events = append(events, fmt.Sprintf("%s %s@%d", prefix, NodeTypeString(c.Node().Type()), c.Depth()))
+		return WalkContinue, nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	expected := []string{
+		"enter Document@0",
+		"enter Heading@1", "exit Heading@1",
+		"enter List@1",
+		"enter ListItem@2",
+		"enter List@3", "enter ListItem@4", "exit ListItem@4", "exit List@3",
+		"exit ListItem@2",
+		"enter ListItem@2", "exit ListItem@2",
+		"exit List@1",
+		"enter Blockquote@1", "enter Paragraph@2", "exit Paragraph@2", "exit Blockquote@1",
+		"enter Paragraph@1", "exit Paragraph@1",
+		"exit Document@0",
+	}
+	if strings.Join(events, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected walk:\n%s\nexpected:\n%s", strings.Join(events, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestWalkSkipAndStop(t *testing.T) {
+	doc := parseWalkSource(t)
+
+	var visited []NodeType
+	err := Walk(doc, func(c *Cursor, entering bool) (WalkStatus, error) {
+		if !entering {
+			return WalkContinue, nil
+		}
+		visited = append(visited, c.Node().Type())
+		switch c.Node().Type() {
+		case NodeList:
+			return WalkSkipChildren, nil
+		case NodeParagraph:
+			return WalkStop, nil
+		}
+		return WalkContinue, nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	expected := []NodeType{NodeDocument, NodeHeading, NodeList, NodeBlockquote, NodeParagraph}
+	if fmt.Sprint(visited) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, visited)
+	}
+
+	failure := errors.New("failure")
+	err = Walk(doc, func(c *Cursor, entering bool) (WalkStatus, error) {
+		if c.Node().Type() == NodeHeading {
+			return WalkContinue, failure
+		}
+		return WalkContinue, nil
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the error of the callback, got %v", err)
+	}
+}
+
+func TestWalkReplaceAndRemove(t *testing.T) {
+	doc := parseWalkSource(t)
+
+	err := Walk(doc, func(c *Cursor, entering bool) (WalkStatus, error) {
+		if !entering {
+			return WalkContinue, nil
+		}
+		switch n := c.Node().(type) {
+		case *Blockquote:
+			return WalkContinue, c.Replace(&Paragraph{Text: "replaced"})
+		case *ListItem:
+			if n.Text == "two" {
+				return WalkContinue, c.Remove()
+			}
+		case *Heading:
+			return WalkContinue, c.Remove()
+		}
+		return WalkContinue, nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	list, ok := doc.Children[0].(*List)
+	if !ok || len(doc.Children) != 3 {
+		t.Fatalf("Expected the heading removed, got %s", DebugString(doc))
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("Expected one list item left, got %d", len(list.Items))
+	}
+	paragraph, ok := doc.Children[1].(*Paragraph)
+	if !ok || paragraph.Text != "replaced" {
+		t.Errorf("Expected the blockquote replaced, got %s", DebugString(doc))
+	}
+
+	err = Walk(list, func(c *Cursor, entering bool) (WalkStatus, error) {
+		if c.Parent() == list {
+			return WalkStop, c.Replace(&Paragraph{})
+		}
+		return WalkContinue, nil
+	})
+	if err == nil {
+		t.Error("Expected an error replacing a list item with a paragraph")
+	}
+	if err := Walk(doc, func(c *Cursor, _ bool) (WalkStatus, error) { return WalkStop, c.Remove() }); err == nil {
+		t.Error("Expected an error removing the root")
+	}
+}
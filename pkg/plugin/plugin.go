@@ -0,0 +1,95 @@
+// Package plugin runs external lint rules. A plugin is a command that reads
+// a document on stdin, as the JSON tree printed by mdfmt parse --ast or as
+// markdown, and writes the problems it finds on stdout as a JSON array:
+//
+//	[{"line": 1, "message": "page has no Owner section"}]
+//
+// Each problem may name its rule with "rule"; it defaults to the plugin
+// name. The path of the document is in the MDFMT_FILE environment variable.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// InputAST sends the JSON tree of the document
+	InputAST = "ast"
+	// InputText sends the markdown of the document
+	InputText = "text"
+	// PathEnv is the environment variable holding the path of the document
+	PathEnv = "MDFMT_FILE"
+	// RuleEnv is the environment variable holding the name of the plugin
+	RuleEnv = "MDFMT_RULE"
+)
+
+// Diagnostic is a problem reported by a plugin
+type Diagnostic struct {
+	Line    int    `json:"line"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// Run runs plugin p on the document at path, whose source is content and
+// whose parsed tree, with positions, is doc. doc is only needed for plugins
+// reading the AST. The problems reported are returned as diagnostics whose
+// feature is their rule.
+func Run(ctx context.Context, p config.PluginConfig, path string, content []byte,
+	doc *parser.Document) ([]parser.Diagnostic, error) {
+	if len(p.Command) == 0 {
+		return nil, fmt.Errorf("plugin %s has no command", p.Name)
+	}
+
+	input := content
+	if p.Input == "" || p.Input == InputAST {
+		ast, err := parser.MarshalAST(doc)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: failed to encode the tree: %w", p.Name, err)
+		}
+		input = ast
+	}
+
+	cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...) // #nosec G204 - command is configured by the user
+	cmd.Env = append(os.Environ(), PathEnv+"="+path, RuleEnv+"="+p.Name)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("plugin %s failed: %w: %s", p.Name, err, message)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", p.Name, err)
+	}
+	return parseOutput(p.Name, stdout.Bytes())
+}
+
+// parseOutput returns the diagnostics of the output of plugin name
+func parseOutput(name string, output []byte) ([]parser.Diagnostic, error) {
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+	var reported []Diagnostic
+	if err := json.Unmarshal(output, &reported); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid output: %w", name, err)
+	}
+
+	diagnostics := make([]parser.Diagnostic, 0, len(reported))
+	for _, d := range reported {
+		rule := d.Rule
+		if rule == "" {
+			rule = name
+		}
+		diagnostics = append(diagnostics, parser.Diagnostic{Line: d.Line, Feature: rule, Message: d.Message})
+	}
+	return diagnostics, nil
+}
@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// helperEnv makes the test binary act as a plugin, see TestHelperPlugin
+const helperEnv = "MDFMT_TEST_PLUGIN"
+
+// helperPlugin returns a plugin running the test binary in mode
+func helperPlugin(t *testing.T, name, input, mode string) config.PluginConfig {
+	t.Helper()
+	t.Setenv(helperEnv, mode)
+	return config.PluginConfig{
+		Name:    name,
+		Command: []string{os.Args[0], "-test.run=^TestHelperPlugin$"},
+		Input:   input,
+	}
+}
+
+// TestHelperPlugin is not a test: it is the plugin run by the other tests
+func TestHelperPlugin(t *testing.T) {
+	mode := os.Getenv(helperEnv)
+	if mode == "" {
+		return
+	}
+	stdin, _ := io.ReadAll(os.Stdin)
+	switch mode {
+	case "owner":
+		if !strings.Contains(string(stdin), "## Owner") {
+			fmt.Printf(`[{"line": 1, "message": "%s has no Owner section"}]`, os.Getenv(PathEnv))
+		}
+	case "ast":
+		if strings.Contains(string(stdin), `"type": "Heading"`) || strings.Contains(string(stdin), `"type":"Heading"`) {
+			fmt.Printf(`[{"line": 3, "rule": "ast-heading", "message": "%s saw a heading"}]`, os.Getenv(RuleEnv))
+		}
+	case "fail":
+		fmt.Fprint(os.Stderr, "broken rule")
+		os.Exit(3)
+	case "garbage":
+		fmt.Print("not json")
+	}
+	os.Exit(0)
+}
+
+func TestRunText(t *testing.T) {
+	p := helperPlugin(t, "owner-section", InputText, "owner")
+
+	diagnostics, err := Run(context.Background(), p, "docs/a.md", []byte("# A\n\nText.\n"), nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	expected := []parser.Diagnostic{{Line: 1, Feature: "owner-section", Message: "docs/a.md has no Owner section"}}
+	if !reflect.DeepEqual(diagnostics, expected) {
+		t.Errorf("Run() = %v, want %v", diagnostics, expected)
+	}
+
+	diagnostics, err = Run(context.Background(), p, "docs/b.md", []byte("# B\n\n## Owner\n\nTeam.\n"), nil)
+	if err != nil || len(diagnostics) != 0 {
+		t.Errorf("Run() = %v, %v, want no diagnostics", diagnostics, err)
+	}
+}
+
+func TestRunAST(t *testing.T) {
+	content := []byte("Intro.\n\n# Title\n")
+	doc, err := parser.NewGoldmarkParserWithOptions(parser.Options{Positions: true}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	diagnostics, err := Run(context.Background(), helperPlugin(t, "tree", "", "ast"), "a.md", content, doc)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	expected := []parser.Diagnostic{{Line: 3, Feature: "ast-heading", Message: "tree saw a heading"}}
+	if !reflect.DeepEqual(diagnostics, expected) {
+		t.Errorf("Run() = %v, want %v", diagnostics, expected)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		mode     string
+		expected string
+	}{
+		{"fail", "plugin broken failed: exit status 3: broken rule"},
+		{"garbage", "plugin broken: invalid output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			_, err := Run(context.Background(), helperPlugin(t, "broken", InputText, tt.mode), "a.md", []byte("# A\n"), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Run() error = %v, want %q", err, tt.expected)
+			}
+		})
+	}
+
+	if _, err := Run(context.Background(), config.PluginConfig{Name: "empty"}, "a.md", nil, nil); err == nil {
+		t.Error("expected an error for a plugin without a command")
+	}
+}
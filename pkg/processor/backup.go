@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Backup constants
+const (
+	// DefaultBackupSuffix is appended to the name of a backup when no
+	// suffix or directory is given
+	DefaultBackupSuffix = ".orig"
+	// BackupDirPermissions defines the permissions of created backup directories
+	BackupDirPermissions = 0o750
+)
+
+// Backup describes where the original content of a rewritten file is kept
+type Backup struct {
+	// Suffix is appended to the file name of the backup
+	Suffix string
+	// Dir, if set, receives the backups at the path of each file relative
+	// to the working directory instead of next to the file
+	Dir string
+}
+
+// ParseBackup parses the value of --backup: a value ending in a path
+// separator names a backup directory, anything else a suffix. An empty
+// value selects DefaultBackupSuffix.
+func ParseBackup(value string) Backup {
+	switch {
+	case value == "":
+		return Backup{Suffix: DefaultBackupSuffix}
+	case strings.HasSuffix(value, "/") || strings.HasSuffix(value, string(filepath.Separator)):
+		return Backup{Dir: filepath.Clean(value)}
+	default:
+		return Backup{Suffix: value}
+	}
+}
+
+// Path returns the path of the backup of path. In a backup directory,
+// files outside the working directory are kept under their absolute path.
+func (b Backup) Path(path string) string {
+	if b.Dir == "" {
+		return path + b.Suffix
+	}
+
+	rel := relativePath(path)
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			abs = path
+		}
+		rel = strings.TrimPrefix(abs, filepath.VolumeName(abs))
+	}
+	return filepath.Join(b.Dir, rel) + b.Suffix
+}
+
+// write stores original as the backup of path with the given mode and
+// returns the backup path
+func (b Backup) write(path string, original []byte, mode os.FileMode) (string, error) {
+	backupPath := b.Path(path)
+	if b.Dir != "" {
+		if err := os.MkdirAll(filepath.Dir(backupPath), BackupDirPermissions); err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(backupPath, original, mode); err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseBackup(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Backup
+	}{
+		{"", Backup{Suffix: DefaultBackupSuffix}},
+		{".bak", Backup{Suffix: ".bak"}},
+		{"backups/", Backup{Dir: "backups"}},
+	}
+	for _, tt := range tests {
+		if got := ParseBackup(tt.value); got != tt.want {
+			t.Errorf("ParseBackup(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWriteTransaction_Backup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(path, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := NewWriteTransaction()
+	tx.SetBackup(Backup{Suffix: ".orig"})
+	if err := tx.Stage(path, []byte("original"), []byte("formatted")); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".orig"); !os.IsNotExist(err) {
+		t.Error("Expected no backup before commit")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	backup, err := os.ReadFile(path + ".orig")
+	if err != nil {
+		t.Fatalf("Expected a backup: %v", err)
+	}
+	if string(backup) != "original" {
+		t.Errorf("Expected the original content in the backup, got %q", backup)
+	}
+	if info, _ := os.Stat(path + ".orig"); info.Mode().Perm() != 0o644 {
+		t.Errorf("Expected the backup to keep mode 0644, got %v", info.Mode().Perm())
+	}
+}
+
+func TestWriteTransaction_BackupDirRollback(t *testing.T) {
+	dir := t.TempDir()
+	backups := filepath.Join(dir, "backups")
+	good := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(good, []byte("original"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bad := filepath.Join(dir, "b.md")
+	if err := os.WriteFile(bad, []byte("original"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := NewWriteTransaction()
+	tx.SetBackup(Backup{Dir: backups})
+	for _, path := range []string{good, bad} {
+		if err := tx.Stage(path, []byte("original"), []byte("formatted")); err != nil {
+			t.Fatalf("Stage failed: %v", err)
+		}
+	}
+
+	// Replacing b.md fails because a directory now stands in its way
+	if err := os.Remove(bad); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(bad, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Expected commit to fail")
+	}
+
+	if content, _ := os.ReadFile(good); string(content) != "original" {
+		t.Errorf("Expected a.md to be restored, got %q", content)
+	}
+	if _, err := os.Stat(Backup{Dir: backups}.Path(good)); !os.IsNotExist(err) {
+		t.Errorf("Expected the backup of a restored file to be removed, got %v", err)
+	}
+}
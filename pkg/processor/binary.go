@@ -0,0 +1,43 @@
+package processor
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// binarySniffLen is the number of leading bytes inspected to tell text
+// files from binary ones, as in git
+const binarySniffLen = 8000
+
+// isBinaryFile reports whether the file at path does not look like text.
+// Files that cannot be read are left to fail when they are processed.
+func isBinaryFile(path string) bool {
+	f, err := os.Open(path) // #nosec G304 - path is found by file discovery
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false
+	}
+	return looksBinary(buf[:n], n == binarySniffLen)
+}
+
+// looksBinary reports whether data contains a NUL byte or invalid UTF-8.
+// If data was truncated, a character cut off at its end is ignored.
+func looksBinary(data []byte, truncated bool) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	if truncated {
+		for i := 1; i < utf8.UTFMax && !utf8.Valid(data) && len(data) > 0; i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	return !utf8.Valid(data)
+}
@@ -0,0 +1,27 @@
+package processor
+
+import (
+	"testing"
+)
+
+func TestLooksBinary(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		truncated bool
+		expected  bool
+	}{
+		{"text", "# Title\n\nSome text.\n", false, false},
+		{"utf8", "# Café ☕\n", false, false},
+		{"empty", "", false, false},
+		{"nul", "PK\x03\x04\x00\x00", false, true},
+		{"latin1", "caf\xe9\n", false, true},
+		{"cut character", "caf\xc3", true, false},
+		{"cut character at end of file", "caf\xc3", false, true},
+	}
+	for _, tt := range tests {
+		if got := looksBinary([]byte(tt.data), tt.truncated); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/Gosayram/go-mdfmt/internal/version"
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+// Crash bundle file names
+const (
+	// CrashReproName is the minimized markdown input of a crash bundle
+	CrashReproName = "repro.md"
+	// CrashConfigName is the configuration of a crash bundle, named so mdfmt finds it
+	CrashConfigName = ".mdfmt.yaml"
+	// CrashReportName describes the panic, version and how to reproduce it
+	CrashReportName = "crash.txt"
+)
+
+// FormatFunc formats markdown content, panicking on inputs that trigger a crash
+type FormatFunc func(content []byte)
+
+// Crash describes a panic recovered while formatting a file
+type Crash struct {
+	// Path is the file that triggered the panic
+	Path string
+	// Value is the recovered panic value
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine
+	Stack []byte
+}
+
+// Error returns the crash as an error message
+func (c *Crash) Error() string {
+	return fmt.Sprintf("panic while formatting %s: %v", c.Path, c.Value)
+}
+
+// CrashBundle is a reproduction of a crash written to disk
+type CrashBundle struct {
+	// Dir is the directory holding the bundle
+	Dir string
+	// Redacted reports whether the text of the reproduction was redacted.
+	// It is false when the redacted input no longer panics.
+	Redacted bool
+	// Minimized reports whether the reproduction is a single block rather
+	// than the whole file
+	Minimized bool
+}
+
+// WriteCrashBundle writes a reproduction of crash to a new temporary
+// directory. content is minimized to the first top-level block that still
+// panics under format, and its text is redacted when the redacted block
+// still panics. The bundle holds the reproduction, cfg and version
+// information, but not the path or name of the file.
+func WriteCrashBundle(crash *Crash, content []byte, cfg *config.Config, format FormatFunc) (*CrashBundle, error) {
+	bundle := &CrashBundle{}
+	repro := MinimizeCrash(content, format)
+	bundle.Minimized = len(repro) < len(content)
+	if redacted := redact(repro); panics(format, redacted) {
+		repro = redacted
+		bundle.Redacted = true
+	}
+
+	dir, err := os.MkdirTemp("", "mdfmt-crash-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create crash bundle directory: %w", err)
+	}
+	bundle.Dir = dir
+
+	if err := os.WriteFile(filepath.Join(dir, CrashReproName), repro, FilePermissions); err != nil {
+		return nil, fmt.Errorf("failed to write crash reproduction: %w", err)
+	}
+	if err := cfg.SaveToFile(filepath.Join(dir, CrashConfigName)); err != nil {
+		return nil, fmt.Errorf("failed to write crash configuration: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, CrashReportName), []byte(crashReport(crash, bundle)), FilePermissions); err != nil {
+		return nil, fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return bundle, nil
+}
+
+// MinimizeCrash returns the first top-level block of content that panics
+// under format on its own, or content if none does
+func MinimizeCrash(content []byte, format FormatFunc) []byte {
+	for _, block := range splitBlocks(content) {
+		if len(block) < len(content) && panics(format, block) {
+			return block
+		}
+	}
+	return content
+}
+
+// crashReport describes crash for the bundle
+func crashReport(crash *Crash, bundle *CrashBundle) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "mdfmt crashed while formatting a markdown file.\n\n")
+	fmt.Fprintf(&b, "Version: %s\n", version.Get().String())
+	fmt.Fprintf(&b, "Panic: %v\n", crash.Value)
+	fmt.Fprintf(&b, "Minimized: %t\n", bundle.Minimized)
+	fmt.Fprintf(&b, "Redacted: %t\n", bundle.Redacted)
+	if !bundle.Redacted {
+		fmt.Fprintf(&b, "\nThe reproduction contains unredacted text from the original file,\n")
+		fmt.Fprintf(&b, "because the crash does not occur once its text is redacted.\n")
+		fmt.Fprintf(&b, "Review %s before sharing this bundle.\n", CrashReproName)
+	}
+	fmt.Fprintf(&b, "\nTo reproduce, run in this directory:\n\n    mdfmt %s\n", CrashReproName)
+	fmt.Fprintf(&b, "\nStack trace:\n\n%s", crash.Stack)
+	return b.String()
+}
+
+// panics reports whether format panics on content
+func panics(format FormatFunc, content []byte) (panicked bool) {
+	defer func() {
+		if recover() != nil {
+			panicked = true
+		}
+	}()
+	format(content)
+	return false
+}
+
+// splitBlocks splits markdown into top-level blocks separated by blank
+// lines outside fenced code
+func splitBlocks(content []byte) [][]byte {
+	var blocks [][]byte
+	var current strings.Builder
+	fence := ""
+	flush := func() {
+		if current.Len() > 0 {
+			blocks = append(blocks, []byte(current.String()))
+			current.Reset()
+		}
+	}
+
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case trimmed == "":
+			flush()
+			continue
+		}
+		current.WriteString(line)
+	}
+	flush()
+	return blocks
+}
+
+// redact replaces letters with x or X and digits with 0, keeping
+// punctuation and whitespace so the markdown structure is unchanged
+func redact(content []byte) []byte {
+	return []byte(strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsUpper(r):
+			return 'X'
+		case unicode.IsLetter(r):
+			return 'x'
+		case unicode.IsDigit(r):
+			return '0'
+		default:
+			return r
+		}
+	}, string(content)))
+}
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+// panicOn returns a FormatFunc that panics when content contains marker
+func panicOn(marker string) FormatFunc {
+	return func(content []byte) {
+		if bytes.Contains(content, []byte(marker)) {
+			panic("boom")
+		}
+	}
+}
+
+func TestWriteCrashBundle(t *testing.T) {
+	content := []byte("# Secret Title\n\nPrivate text.\n\n| a | b |\n|---|---|\n\nMore text.\n")
+	crash := &Crash{Path: "docs/secret.md", Value: "boom", Stack: []byte("goroutine 1")}
+
+	bundle, err := WriteCrashBundle(crash, content, config.Default(), panicOn("|---|"))
+	if err != nil {
+		t.Fatalf("WriteCrashBundle failed: %v", err)
+	}
+	defer os.RemoveAll(bundle.Dir)
+
+	if !bundle.Minimized || !bundle.Redacted {
+		t.Errorf("Expected a minimized and redacted bundle, got %+v", bundle)
+	}
+
+	repro, err := os.ReadFile(filepath.Join(bundle.Dir, CrashReproName))
+	if err != nil {
+		t.Fatalf("Failed to read reproduction: %v", err)
+	}
+	if expected := "| x | x |\n|---|---|\n"; string(repro) != expected {
+		t.Errorf("Expected reproduction %q, got %q", expected, repro)
+	}
+
+	report, err := os.ReadFile(filepath.Join(bundle.Dir, CrashReportName))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if !strings.Contains(string(report), "Panic: boom") || !strings.Contains(string(report), "goroutine 1") {
+		t.Errorf("Expected report to contain the panic and stack, got:\n%s", report)
+	}
+	if strings.Contains(string(report), "secret") {
+		t.Errorf("Expected report not to contain the file path, got:\n%s", report)
+	}
+
+	cfg := config.Default()
+	if err := cfg.LoadFromFile(filepath.Join(bundle.Dir, CrashConfigName)); err != nil {
+		t.Errorf("Expected bundle configuration to load: %v", err)
+	}
+}
+
+func TestWriteCrashBundleUnredacted(t *testing.T) {
+	content := []byte("Intro.\n\nThe word Secret crashes.\n")
+	crash := &Crash{Path: "a.md", Value: "boom"}
+
+	bundle, err := WriteCrashBundle(crash, content, config.Default(), panicOn("Secret"))
+	if err != nil {
+		t.Fatalf("WriteCrashBundle failed: %v", err)
+	}
+	defer os.RemoveAll(bundle.Dir)
+
+	if bundle.Redacted {
+		t.Error("Expected the bundle not to be redacted when redaction hides the crash")
+	}
+	repro, err := os.ReadFile(filepath.Join(bundle.Dir, CrashReproName))
+	if err != nil {
+		t.Fatalf("Failed to read reproduction: %v", err)
+	}
+	if string(repro) != "The word Secret crashes.\n" {
+		t.Errorf("Expected the crashing block, got %q", repro)
+	}
+}
+
+func TestSplitBlocks(t *testing.T) {
+	content := []byte("Para one.\n\n```\ncode\n\nmore code\n```\n\n- item\n")
+	blocks := splitBlocks(content)
+
+	expected := []string{"Para one.\n", "```\ncode\n\nmore code\n```\n", "- item\n"}
+	if len(blocks) != len(expected) {
+		t.Fatalf("Expected %d blocks, got %d: %q", len(expected), len(blocks), blocks)
+	}
+	for i, block := range blocks {
+		if string(block) != expected[i] {
+			t.Errorf("Block %d: expected %q, got %q", i, expected[i], block)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandGlobs replaces the paths containing "*", "?" or "[" with the paths
+// they match, in order, as Unix shells do before running a command. The
+// Windows shells pass such patterns on unexpanded. Paths that exist as
+// named, and patterns that match nothing, are kept, so a missing path is
+// reported by FindFiles.
+func ExpandGlobs(paths []string) ([]string, error) {
+	expanded := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !strings.ContainsAny(path, "*?[") {
+			expanded = append(expanded, path)
+			continue
+		}
+		if _, err := os.Lstat(path); err == nil {
+			expanded = append(expanded, path)
+			continue
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", path, err)
+		}
+		if len(matches) == 0 {
+			expanded = append(expanded, path)
+			continue
+		}
+		expanded = append(expanded, matches...)
+	}
+	return expanded, nil
+}
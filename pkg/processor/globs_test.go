@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExpandGlobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.md", "c.txt", "[draft].md", "docs/guide.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# Title\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	join := func(names ...string) []string {
+		paths := make([]string, len(names))
+		for i, name := range names {
+			paths[i] = filepath.Join(dir, filepath.FromSlash(name))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		expected []string
+	}{
+		{"plain paths", join("a.md", "docs"), join("a.md", "docs")},
+		{"pattern", join("*.md"), join("[draft].md", "a.md", "b.md")},
+		{"in order", join("c.txt", "docs/*.md", "?.md"), join("c.txt", "docs/guide.md", "a.md", "b.md")},
+		{"existing path", join("[draft].md"), join("[draft].md")},
+		{"no match", join("*.markdown"), join("*.markdown")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := ExpandGlobs(tt.paths)
+			if err != nil {
+				t.Fatalf("ExpandGlobs failed: %v", err)
+			}
+			if !slices.Equal(expanded, tt.expected) {
+				t.Errorf("ExpandGlobs(%q) = %q, want %q", tt.paths, expanded, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ExpandGlobs([]string{filepath.Join(dir, "[a-.md")}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
@@ -2,10 +2,12 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -22,13 +24,27 @@ const (
 type FileProcessor struct {
 	config  *config.Config
 	verbose bool
+	// ignore matches paths relative to root against files.ignore_patterns
+	ignore *config.IgnoreMatcher
+	root   string
+	// skipped counts markdown files excluded from discovery
+	skipped int
+	// excludedDirs holds the absolute paths of directories never walked
+	excludedDirs map[string]bool
 }
 
 // NewFileProcessor creates a new file processor instance
 func NewFileProcessor(cfg *config.Config, verbose bool) *FileProcessor {
+	ignore, err := config.NewIgnoreMatcher(cfg.Files.IgnorePatterns)
+	if err != nil {
+		// Validate rejects invalid patterns, so this only affects unvalidated configs
+		ignore = &config.IgnoreMatcher{}
+	}
 	return &FileProcessor{
 		config:  cfg,
 		verbose: verbose,
+		ignore:  ignore,
+		root:    repositoryRoot(),
 	}
 }
 
@@ -40,6 +56,13 @@ type FileInfo struct {
 	Size         int64
 }
 
+// Discovery is the result of Discover: the files to process and the paths
+// excluded from them, each in the order found
+type Discovery struct {
+	Files   []FileInfo
+	Skipped []SkippedPath
+}
+
 // ProcessingResult contains the result of processing a file
 type ProcessingResult struct {
 	File      FileInfo
@@ -49,35 +72,66 @@ type ProcessingResult struct {
 	BytesRead int64
 }
 
-// FindFiles recursively finds all Markdown files in the given paths
+// FindFiles recursively finds all Markdown files in the given paths.
+// Paths named explicitly are followed even if they are symbolic links;
+// links found while walking directories are followed only with
+// files.follow_symlinks.
 func (fp *FileProcessor) FindFiles(paths []string) ([]FileInfo, error) {
-	var files []FileInfo
-	seen := make(map[string]bool)
+	return fp.FindFilesContext(context.Background(), paths)
+}
+
+// FindFilesContext finds files like FindFiles, stopping with ctx.Err() once
+// ctx is done
+func (fp *FileProcessor) FindFilesContext(ctx context.Context, paths []string) ([]FileInfo, error) {
+	found, err := fp.Discover(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	return found.Files, nil
+}
+
+// Discover finds files like FindFilesContext and also returns the paths it
+// excluded, with the reason why: markdown files matching ignore patterns,
+// over the size limit, binary or behind symbolic links that are not
+// followed, files named explicitly without a markdown extension, and the
+// directories it did not walk. Other files in walked directories are not
+// reported.
+func (fp *FileProcessor) Discover(ctx context.Context, paths []string) (*Discovery, error) {
+	d := &discovery{ctx: ctx, seen: make(map[string]bool), dirs: make(map[string]bool)}
 
 	for _, path := range paths {
-		err := fp.findFilesInPath(path, &files, seen)
+		err := fp.findFilesInPath(path, d)
 		if err != nil {
 			return nil, fmt.Errorf("error processing path %s: %w", path, err)
 		}
 	}
 
-	return files, nil
+	return &Discovery{Files: d.files, Skipped: d.skipped}, nil
+}
+
+// discovery holds the state of a FindFiles call
+type discovery struct {
+	ctx     context.Context
+	files   []FileInfo
+	skipped []SkippedPath
+	// seen holds the keys of files already added or excluded
+	seen map[string]bool
+	// dirs holds the keys of directories already walked, which stops
+	// symbolic link loops
+	dirs map[string]bool
 }
 
 // findFilesInPath recursively finds files in a single path
-func (fp *FileProcessor) findFilesInPath(path string, files *[]FileInfo, seen map[string]bool) error {
+func (fp *FileProcessor) findFilesInPath(path string, d *discovery) error {
+	if err := d.ctx.Err(); err != nil {
+		return err
+	}
 	// Clean and resolve the path
 	cleanPath, err := filepath.Abs(path)
 	if err != nil {
 		return fmt.Errorf("failed to resolve path %s: %w", path, err)
 	}
 
-	// Check if we've already processed this path
-	if seen[cleanPath] {
-		return nil
-	}
-	seen[cleanPath] = true
-
 	// Get file info
 	info, err := os.Stat(cleanPath)
 	if err != nil {
@@ -85,88 +139,254 @@ func (fp *FileProcessor) findFilesInPath(path string, files *[]FileInfo, seen ma
 	}
 
 	if info.IsDir() {
-		return fp.findFilesInDirectory(cleanPath, files, seen)
+		return fp.findFilesInDirectory(cleanPath, d)
 	}
 
-	// Check if it's a Markdown file
-	if fp.isMarkdownFile(cleanPath) && !fp.shouldIgnoreFile(cleanPath) {
-		relPath, _ := filepath.Rel(".", cleanPath)
-		*files = append(*files, FileInfo{
-			Path:         cleanPath,
-			RelativePath: relPath,
-			IsDirectory:  false,
-			Size:         info.Size(),
-		})
+	if fp.isMarkdownFile(cleanPath) {
+		fp.addFile(cleanPath, info, d)
+	} else {
+		d.skipped = append(d.skipped, SkippedPath{Path: cleanPath, Reason: SkipExtension})
 	}
-
 	return nil
 }
 
-// findFilesInDirectory finds files in a directory
-func (fp *FileProcessor) findFilesInDirectory(dir string, files *[]FileInfo, seen map[string]bool) error {
-	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			if fp.verbose {
-				fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", path, err)
-			}
-			return nil // Skip files we can't access
-		}
+// findFilesInDirectory finds files in a directory. dir may be a symbolic
+// link; the paths of the files found are below dir.
+func (fp *FileProcessor) findFilesInDirectory(dir string, d *discovery) error {
+	root := dir
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		root = real // WalkDir does not descend into a symbolic link root
+	}
 
-		// Skip if already seen
-		cleanPath, err := filepath.Abs(path)
-		if err != nil {
-			return nil
+	return filepath.WalkDir(root, func(walked string, entry fs.DirEntry, err error) error {
+		path := dir
+		if rel, relErr := filepath.Rel(root, walked); relErr == nil && rel != "." {
+			path = filepath.Join(dir, rel)
 		}
-		if seen[cleanPath] {
-			return nil
+		if ctxErr := d.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			d.skipped = append(d.skipped, SkippedPath{Path: path, Reason: SkipUnreadable, Detail: err.Error()})
+			return nil // Skip files we can't access
 		}
 
-		// Check if we should ignore this path
-		if fp.shouldIgnoreFile(path) {
-			if d.IsDir() {
+		switch {
+		case entry.Type()&fs.ModeSymlink != 0:
+			return fp.findFilesInSymlink(path, d)
+		case entry.IsDir():
+			key := fp.key(path)
+			if d.dirs[key] || fp.shouldSkipDir(path, d) {
 				return filepath.SkipDir
 			}
-			return nil
-		}
-
-		// If it's a Markdown file, add it
-		if !d.IsDir() && fp.isMarkdownFile(path) {
-			info, err := d.Info()
+			d.dirs[key] = true
+		case fp.isMarkdownFile(path):
+			info, err := entry.Info()
 			if err != nil {
 				return nil
 			}
-
-			relPath, _ := filepath.Rel(".", path)
-			*files = append(*files, FileInfo{
-				Path:         path,
-				RelativePath: relPath,
-				IsDirectory:  false,
-				Size:         info.Size(),
-			})
+			fp.addFile(path, info, d)
 		}
-
 		return nil
 	})
 }
 
+// findFilesInSymlink finds files through a symbolic link found while
+// walking a directory
+func (fp *FileProcessor) findFilesInSymlink(path string, d *discovery) error {
+	if !fp.config.Files.FollowSymlinks {
+		if fp.isMarkdownFile(path) {
+			fp.exclude(SkippedPath{Path: path, Reason: SkipSymlink}, d)
+		}
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		d.skipped = append(d.skipped, SkippedPath{Path: path, Reason: SkipUnreadable, Detail: err.Error()})
+		return nil // Skip broken links
+	}
+	if info.IsDir() {
+		return fp.findFilesInDirectory(path, d)
+	}
+	if fp.isMarkdownFile(path) {
+		fp.addFile(path, info, d)
+	}
+	return nil
+}
+
+// addFile adds the markdown file at path unless it was seen before or is
+// excluded by the files options
+func (fp *FileProcessor) addFile(path string, info fs.FileInfo, d *discovery) {
+	files := fp.config.Files
+	if d.seen[fp.key(path)] {
+		return
+	}
+	if pattern, ok := fp.ignorePattern(path, false); ok {
+		fp.exclude(SkippedPath{Path: path, Reason: SkipIgnored, Detail: pattern}, d)
+		return
+	}
+	switch {
+	case files.MaxFileSize > 0 && info.Size() > files.MaxFileSize:
+		fp.exclude(SkippedPath{Path: path, Reason: SkipTooLarge, Detail: strconv.FormatInt(files.MaxFileSize, 10)}, d)
+	case files.SkipBinary && isBinaryFile(path):
+		fp.exclude(SkippedPath{Path: path, Reason: SkipBinary}, d)
+	default:
+		d.seen[fp.key(path)] = true
+		d.files = append(d.files, FileInfo{
+			Path:         path,
+			RelativePath: relativePath(path),
+			IsDirectory:  false,
+			Size:         info.Size(),
+		})
+	}
+}
+
+// exclude records that the markdown file at skipped.Path is skipped
+func (fp *FileProcessor) exclude(skipped SkippedPath, d *discovery) {
+	key := fp.key(skipped.Path)
+	if d.seen[key] {
+		return
+	}
+	d.seen[key] = true
+	fp.skipped++
+	d.skipped = append(d.skipped, skipped)
+}
+
+// key identifies the file or directory at the absolute path: the path
+// itself, or its target with files.follow_symlinks, so that files reached
+// through several links are processed once
+func (fp *FileProcessor) key(path string) string {
+	if fp.config.Files.FollowSymlinks {
+		if real, err := filepath.EvalSymlinks(path); err == nil {
+			return real
+		}
+	}
+	return path
+}
+
+// Skipped returns the number of markdown files FindFiles excluded because
+// they match an ignore pattern, are symbolic links that are not followed,
+// or are too large or not text. Files in skipped directories are not
+// counted.
+func (fp *FileProcessor) Skipped() int {
+	return fp.skipped
+}
+
+// relativePath returns path relative to the working directory, or path
+// itself when it cannot be made relative
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, abs)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
 // isMarkdownFile checks if a file is a Markdown file based on extension
 func (fp *FileProcessor) isMarkdownFile(path string) bool {
-	ext := strings.ToLower(filepath.Ext(path))
-	for _, validExt := range fp.config.Files.Extensions {
-		if ext == validExt {
-			return true
+	return fp.config.IsMarkdownFile(path)
+}
+
+// shouldIgnoreFile checks if a file should be ignored based on patterns
+func (fp *FileProcessor) shouldIgnoreFile(path string, isDir bool) bool {
+	_, ignored := fp.ignorePattern(path, isDir)
+	return ignored
+}
+
+// ignorePattern returns the ignore pattern matching path, if any
+func (fp *FileProcessor) ignorePattern(path string, isDir bool) (string, bool) {
+	rel, ok := fp.ignorePath(path)
+	if !ok {
+		return "", false
+	}
+	return fp.ignore.MatchingPattern(rel, isDir)
+}
+
+// ExcludeDir excludes dir and everything below it from discovery, such as
+// a backup directory inside the tree
+func (fp *FileProcessor) ExcludeDir(dir string) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if fp.excludedDirs == nil {
+		fp.excludedDirs = make(map[string]bool)
+	}
+	fp.excludedDirs[dir] = true
+}
+
+// shouldSkipDir checks if a directory and everything below it is ignored,
+// recording it in d if so
+func (fp *FileProcessor) shouldSkipDir(path string, d *discovery) bool {
+	skipped := SkippedPath{Path: path, IsDirectory: true}
+	if len(fp.excludedDirs) > 0 {
+		if abs, err := filepath.Abs(path); err == nil && fp.excludedDirs[abs] {
+			skipped.Reason = SkipExcluded
+		}
+	}
+	if rel, ok := fp.ignorePath(path); skipped.Reason == "" && ok {
+		if pattern, skip := fp.ignore.SkipDirPattern(rel); skip {
+			skipped.Reason, skipped.Detail = SkipIgnored, pattern
 		}
 	}
-	return false
+	if skipped.Reason == "" {
+		return false
+	}
+	d.skipped = append(d.skipped, skipped)
+	return true
 }
 
-// shouldIgnoreFile checks if a file should be ignored based on patterns
-func (fp *FileProcessor) shouldIgnoreFile(path string) bool {
-	return fp.config.ShouldIgnore(path)
+// ignorePath returns the absolute path relative to the repository root,
+// which ignore patterns are anchored to, with forward slashes on every
+// platform. Paths outside the repository are never ignored.
+func (fp *FileProcessor) ignorePath(path string) (string, bool) {
+	rel, err := filepath.Rel(fp.root, path)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// repositoryRoot returns the root of the git repository containing the
+// working directory, or the working directory outside a repository
+func repositoryRoot() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	for dir := wd; ; {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return wd // not in a repository
+		}
+		dir = parent
+	}
 }
 
 // ProcessFiles processes multiple files concurrently
 func (fp *FileProcessor) ProcessFiles(files []FileInfo, processor func(FileInfo) ProcessingResult) []ProcessingResult {
+	return fp.ProcessFilesContext(context.Background(), files, processor)
+}
+
+// ProcessFilesContext processes files like ProcessFiles. Once ctx is done,
+// files not yet started are not processed; their results carry ctx.Err().
+func (fp *FileProcessor) ProcessFilesContext(ctx context.Context, files []FileInfo,
+	processor func(FileInfo) ProcessingResult) []ProcessingResult {
 	const maxWorkers = 8
 	workers := minInt(maxWorkers, len(files))
 	if workers == 0 {
@@ -183,6 +403,10 @@ func (fp *FileProcessor) ProcessFiles(files []FileInfo, processor func(FileInfo)
 		go func() {
 			defer wg.Done()
 			for file := range jobs {
+				if err := ctx.Err(); err != nil {
+					results <- ProcessingResult{File: file, Error: err}
+					continue
+				}
 				results <- processor(file)
 			}
 		}()
@@ -2,6 +2,7 @@ package processor
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -73,7 +74,7 @@ func TestShouldIgnoreFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.path, func(t *testing.T) {
-			result := processor.shouldIgnoreFile(tt.path)
+			result := processor.shouldIgnoreFile(filepath.Join(processor.root, tt.path), false)
 			if result != tt.expected {
 				t.Errorf("shouldIgnoreFile(%q) = %v, want %v", tt.path, result, tt.expected)
 			}
@@ -81,6 +82,32 @@ func TestShouldIgnoreFile(t *testing.T) {
 	}
 }
 
+// TestIgnorePath tests that ignore patterns see slash-separated paths
+// relative to the repository root
+func TestIgnorePath(t *testing.T) {
+	processor := NewFileProcessor(config.Default(), false)
+
+	tests := []struct {
+		path     string
+		expected string
+		ok       bool
+	}{
+		{filepath.Join(processor.root, "node_modules", "lib", "README.md"), "node_modules/lib/README.md", true},
+		{filepath.Join(processor.root, "README.md"), "README.md", true},
+		{processor.root, "", false},
+		{filepath.Dir(processor.root), "", false},
+		{filepath.Join(filepath.Dir(processor.root), "other", "README.md"), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rel, ok := processor.ignorePath(tt.path)
+			if rel != tt.expected || ok != tt.ok {
+				t.Errorf("ignorePath(%q) = %q, %v, want %q, %v", tt.path, rel, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
 // TestFindFiles tests the FindFiles function
 func TestFindFiles(t *testing.T) {
 	// Create a temporary directory structure for testing
@@ -353,6 +380,43 @@ func BenchmarkFileProcessor_FindFilesLargeProject(b *testing.B) {
 	}
 }
 
+// BenchmarkFileProcessor_FindFilesIgnorePatterns benchmarks discovery in a
+// repository with typical ignore patterns and an ignored dependency tree
+func BenchmarkFileProcessor_FindFilesIgnorePatterns(b *testing.B) {
+	cfg := config.Default()
+	cfg.Files.IgnorePatterns = []string{
+		"node_modules/", "vendor/**", "*.tmp.md", "CHANGELOG.md", "/docs/generated/**",
+		"**/testdata/*.md", "build*", "!docs/keep.md",
+	}
+	tempDir := b.TempDir()
+	processor := NewFileProcessor(cfg, false)
+	processor.root = tempDir
+
+	write := func(file string) {
+		fullPath := filepath.Join(tempDir, file)
+		os.MkdirAll(filepath.Dir(fullPath), 0755)
+		os.WriteFile(fullPath, []byte("# Test\n"), 0644)
+	}
+	for i := 0; i < 100; i++ {
+		for j := 0; j < 10; j++ {
+			write(fmt.Sprintf("pkg/module%d/docs/file%d.md", i, j))
+		}
+		write(fmt.Sprintf("pkg/module%d/testdata/case.md", i))
+		write(fmt.Sprintf("node_modules/dep%d/README.md", i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		files, err := processor.FindFiles([]string{tempDir})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(files) != 1000 {
+			b.Fatalf("Expected 1000 files, got %d", len(files))
+		}
+	}
+}
+
 // BenchmarkFileProcessor_FileOperations benchmarks the file operations
 func BenchmarkFileProcessor_FileOperations(b *testing.B) {
 	cfg := config.Default()
@@ -414,3 +478,160 @@ Final paragraph with [link](https://example.com) and more text.
 		os.Remove(tmpfile.Name())
 	}
 }
+
+// TestFindFilesSymlinks tests following symbolic links and stopping loops
+func TestFindFilesSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := t.TempDir()
+	for _, path := range []string{filepath.Join(dir, "README.md"), filepath.Join(target, "guide.md")} {
+		if err := os.WriteFile(path, []byte("# Title\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		filepath.Join(dir, "shared"):     target,
+		filepath.Join(dir, "link.md"):    filepath.Join(dir, "README.md"),
+		filepath.Join(target, "loop"):    target,
+		filepath.Join(dir, "missing.md"): filepath.Join(dir, "gone.md"),
+	}
+	for link, to := range links {
+		if err := os.Symlink(to, link); err != nil {
+			t.Skipf("symbolic links are not supported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		follow  bool
+		files   []string
+		skipped int
+	}{
+		{false, []string{"README.md"}, 2},
+		{true, []string{"README.md", filepath.Join("shared", "guide.md")}, 0},
+	}
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.Files.FollowSymlinks = tt.follow
+		processor := NewFileProcessor(cfg, false)
+
+		files, err := processor.FindFiles([]string{dir})
+		if err != nil {
+			t.Fatalf("FindFiles failed: %v", err)
+		}
+		var found []string
+		for _, file := range files {
+			rel, err := filepath.Rel(dir, file.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found = append(found, rel)
+		}
+		if fmt.Sprint(found) != fmt.Sprint(tt.files) {
+			t.Errorf("follow_symlinks=%v: expected %v, got %v", tt.follow, tt.files, found)
+		}
+		if processor.Skipped() != tt.skipped {
+			t.Errorf("follow_symlinks=%v: expected %d skipped, got %d", tt.follow, tt.skipped, processor.Skipped())
+		}
+	}
+
+	// Paths named explicitly are always followed
+	processor := NewFileProcessor(config.Default(), false)
+	files, err := processor.FindFiles([]string{filepath.Join(dir, "shared")})
+	if err != nil {
+		t.Fatalf("FindFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != filepath.Join(dir, "shared", "guide.md") {
+		t.Errorf("Expected the linked directory to be walked, got %v", files)
+	}
+}
+
+// TestFindFilesSkipsLargeAndBinary tests the size limit and binary detection
+func TestFindFilesSkipsLargeAndBinary(t *testing.T) {
+	dir := t.TempDir()
+	contents := map[string][]byte{
+		"text.md":   []byte("# Title\n"),
+		"large.md":  bytes.Repeat([]byte("text\n"), 100),
+		"binary.md": {0x89, 'P', 'N', 'G', 0x00, 0x01},
+		"latin1.md": {'c', 'a', 'f', 0xe9, '\n'},
+	}
+	for name, content := range contents {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Files.MaxFileSize = 100
+	processor := NewFileProcessor(cfg, false)
+	files, err := processor.FindFiles([]string{dir})
+	if err != nil {
+		t.Fatalf("FindFiles failed: %v", err)
+	}
+	if len(files) != 1 || filepath.Base(files[0].Path) != "text.md" {
+		t.Errorf("Expected only text.md, got %v", files)
+	}
+	if processor.Skipped() != 3 {
+		t.Errorf("Expected 3 skipped files, got %d", processor.Skipped())
+	}
+
+	cfg.Files.MaxFileSize = 0
+	cfg.Files.SkipBinary = false
+	files, err = NewFileProcessor(cfg, false).FindFiles([]string{dir})
+	if err != nil {
+		t.Fatalf("FindFiles failed: %v", err)
+	}
+	if len(files) != len(contents) {
+		t.Errorf("Expected all %d files without limits, got %d", len(contents), len(files))
+	}
+}
+
+func TestDiscoverSkipped(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.tmp.md", "node_modules/x.md", "backup/a.md", "large.md", "notes.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := []byte("# Title\n")
+		if name == "large.md" {
+			content = bytes.Repeat(content, 100)
+		}
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Files.IgnorePatterns = []string{"node_modules/**", "*.tmp.md"}
+	cfg.Files.MaxFileSize = 100
+	processor := NewFileProcessor(cfg, false)
+	processor.root = dir
+	processor.ExcludeDir(filepath.Join(dir, "backup"))
+	found, err := processor.Discover(context.Background(), []string{dir, filepath.Join(dir, "notes.txt")})
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(found.Files) != 1 || filepath.Base(found.Files[0].Path) != "a.md" {
+		t.Errorf("Expected only a.md, got %v", found.Files)
+	}
+
+	// Files other than markdown are only reported when named explicitly
+	expected := []SkippedPath{
+		{Path: filepath.Join(dir, "b.tmp.md"), Reason: SkipIgnored, Detail: "*.tmp.md"},
+		{Path: filepath.Join(dir, "backup"), IsDirectory: true, Reason: SkipExcluded},
+		{Path: filepath.Join(dir, "large.md"), Reason: SkipTooLarge, Detail: "100"},
+		{Path: filepath.Join(dir, "node_modules"), IsDirectory: true, Reason: SkipIgnored, Detail: "node_modules/**"},
+		{Path: filepath.Join(dir, "notes.txt"), Reason: SkipExtension},
+	}
+	skipped := found.Skipped
+	if len(skipped) != len(expected) {
+		t.Fatalf("Expected %d skipped paths, got %v", len(expected), skipped)
+	}
+	for i := range expected {
+		if skipped[i] != expected[i] {
+			t.Errorf("Skipped path %d = %+v, want %+v", i, skipped[i], expected[i])
+		}
+	}
+	if processor.Skipped() != 2 {
+		t.Errorf("Expected 2 skipped files, got %d", processor.Skipped())
+	}
+}
@@ -0,0 +1,94 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Summary counts the outcome of a run
+type Summary struct {
+	// Scanned is the number of files processed
+	Scanned int
+	// Changed is the number of files that needed formatting
+	Changed int
+	// Skipped is the number of markdown files excluded from processing or
+	// abandoned while being processed
+	Skipped int
+	// Errored is the number of files that could not be processed
+	Errored int
+	// Elapsed is the wall time of the run
+	Elapsed time.Duration
+}
+
+// String formats the summary as a single line
+func (s Summary) String() string {
+	return fmt.Sprintf("%d files scanned, %d changed, %d skipped, %d errored in %s",
+		s.Scanned, s.Changed, s.Skipped, s.Errored, s.Elapsed.Round(time.Millisecond))
+}
+
+// Progress tracks the files of a run and optionally displays each file as
+// it is processed
+type Progress struct {
+	w     io.Writer
+	total int
+	// live rewrites a single status line instead of writing one per file
+	live bool
+	// done counts the files started and finished
+	done    int
+	summary Summary
+	start   time.Time
+	now     func() time.Time
+}
+
+// NewProgress returns a Progress for total files. Progress is written to w
+// unless it is nil; with live, each file replaces the previous status line,
+// as suits a terminal.
+func NewProgress(w io.Writer, total int, live bool) *Progress {
+	return &Progress{w: w, total: total, live: live, start: time.Now(), now: time.Now}
+}
+
+// Start displays path as the file being processed
+func (p *Progress) Start(path string) {
+	if p.w == nil {
+		return
+	}
+	status := fmt.Sprintf("[%d/%d] %s", p.done+1, p.total, path)
+	if p.live {
+		fmt.Fprintf(p.w, "\r\x1b[K%s", status)
+	} else {
+		fmt.Fprintln(p.w, status)
+	}
+}
+
+// Done records the outcome of the file last started
+func (p *Progress) Done(changed bool, err error) {
+	p.done++
+	p.summary.Scanned++
+	if err != nil {
+		p.summary.Errored++
+	} else if changed {
+		p.summary.Changed++
+	}
+}
+
+// Skip records n files excluded from processing
+func (p *Progress) Skip(n int) {
+	p.summary.Skipped += n
+}
+
+// SkipCurrent records the file last started as skipped, for a file given
+// up on before it was processed
+func (p *Progress) SkipCurrent() {
+	p.done++
+	p.summary.Skipped++
+}
+
+// Finish clears the status line and returns the summary of the run
+func (p *Progress) Finish() Summary {
+	if p.w != nil && p.live {
+		fmt.Fprint(p.w, "\r\x1b[K")
+	}
+	p.summary.Elapsed = p.now().Sub(p.start)
+	return p.summary
+}
@@ -0,0 +1,122 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+func TestProgress(t *testing.T) {
+	var out bytes.Buffer
+	progress := NewProgress(&out, 3, false)
+	start := progress.start
+	progress.now = func() time.Time { return start.Add(1500 * time.Millisecond) }
+	progress.Skip(2)
+
+	progress.Start("a.md")
+	progress.Done(true, nil)
+	progress.Start("b.md")
+	progress.Done(false, nil)
+	progress.Start("c.md")
+	progress.Done(false, errors.New("boom"))
+
+	expected := "[1/3] a.md\n[2/3] b.md\n[3/3] c.md\n"
+	if out.String() != expected {
+		t.Errorf("Expected progress %q, got %q", expected, out.String())
+	}
+
+	summary := progress.Finish()
+	want := Summary{Scanned: 3, Changed: 1, Skipped: 2, Errored: 1, Elapsed: 1500 * time.Millisecond}
+	if summary != want {
+		t.Errorf("Expected summary %+v, got %+v", want, summary)
+	}
+	if got := summary.String(); got != "3 files scanned, 1 changed, 2 skipped, 1 errored in 1.5s" {
+		t.Errorf("Unexpected summary line %q", got)
+	}
+}
+
+func TestProgressSkipCurrent(t *testing.T) {
+	var out bytes.Buffer
+	progress := NewProgress(&out, 2, false)
+	progress.Start("slow.md")
+	progress.SkipCurrent()
+	progress.Start("b.md")
+	progress.Done(false, nil)
+
+	if expected := "[1/2] slow.md\n[2/2] b.md\n"; out.String() != expected {
+		t.Errorf("Expected progress %q, got %q", expected, out.String())
+	}
+	if summary := progress.Finish(); summary.Scanned != 1 || summary.Skipped != 1 {
+		t.Errorf("Expected 1 scanned and 1 skipped file, got %+v", summary)
+	}
+}
+
+func TestProgressLive(t *testing.T) {
+	var out bytes.Buffer
+	progress := NewProgress(&out, 1, true)
+	progress.Start("a.md")
+	progress.Done(false, nil)
+	progress.Finish()
+
+	if expected := "\r\x1b[K[1/1] a.md\r\x1b[K"; out.String() != expected {
+		t.Errorf("Expected live progress %q, got %q", expected, out.String())
+	}
+}
+
+func TestProgressWithoutOutput(t *testing.T) {
+	progress := NewProgress(nil, 1, true)
+	progress.Start("a.md")
+	progress.Done(true, nil)
+	if summary := progress.Finish(); summary.Scanned != 1 || summary.Changed != 1 {
+		t.Errorf("Expected files to be counted without output, got %+v", summary)
+	}
+}
+
+func TestFindFilesCountsSkipped(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"README.md", "drafts/a.md", "drafts/notes.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# Title\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Files.IgnorePatterns = []string{"drafts/*"}
+	fp := NewFileProcessor(cfg, false)
+	fp.root = dir
+
+	files, err := fp.FindFiles([]string{dir, filepath.Join(dir, "drafts", "a.md")})
+	if err != nil {
+		t.Fatalf("FindFiles failed: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("Expected 1 file, got %d", len(files))
+	}
+	if fp.Skipped() != 1 {
+		t.Errorf("Expected 1 skipped markdown file, got %d", fp.Skipped())
+	}
+}
+
+func TestFindFilesContextCanceled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Title\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fp := NewFileProcessor(config.Default(), false)
+	if _, err := fp.FindFilesContext(ctx, []string{dir}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
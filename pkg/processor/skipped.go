@@ -0,0 +1,61 @@
+package processor
+
+import "fmt"
+
+// SkipReason is why file discovery excluded a path
+type SkipReason string
+
+// Skip reasons
+const (
+	// SkipExtension is a file named explicitly whose extension is not in
+	// files.extensions. Other files found while walking directories are
+	// not reported.
+	SkipExtension SkipReason = "extension"
+	// SkipIgnored is a file or directory matching files.ignore_patterns;
+	// Detail is the pattern
+	SkipIgnored SkipReason = "ignored"
+	// SkipTooLarge is a file larger than files.max_file_size; Detail is the
+	// limit in bytes
+	SkipTooLarge SkipReason = "too-large"
+	// SkipBinary is a file that is not text, with files.skip_binary
+	SkipBinary SkipReason = "binary"
+	// SkipSymlink is a symbolic link found while walking a directory,
+	// without files.follow_symlinks
+	SkipSymlink SkipReason = "symlink"
+	// SkipExcluded is a directory excluded with ExcludeDir
+	SkipExcluded SkipReason = "excluded"
+	// SkipUnreadable is a path that cannot be read; Detail is the error
+	SkipUnreadable SkipReason = "unreadable"
+)
+
+// SkippedPath is a file or directory file discovery excluded
+type SkippedPath struct {
+	Path        string
+	IsDirectory bool
+	Reason      SkipReason
+	// Detail qualifies Reason, as described for each reason
+	Detail string
+}
+
+// Message describes why the path was skipped, such as
+// `matches "*.tmp.md" in files.ignore_patterns`
+func (s SkippedPath) Message() string {
+	switch s.Reason {
+	case SkipExtension:
+		return "extension not in files.extensions"
+	case SkipIgnored:
+		return fmt.Sprintf("matches %q in files.ignore_patterns", s.Detail)
+	case SkipTooLarge:
+		return fmt.Sprintf("larger than files.max_file_size (%s bytes)", s.Detail)
+	case SkipBinary:
+		return "not a text file"
+	case SkipSymlink:
+		return "symbolic link (files.follow_symlinks is disabled)"
+	case SkipExcluded:
+		return "excluded from discovery"
+	case SkipUnreadable:
+		return s.Detail
+	default:
+		return string(s.Reason)
+	}
+}
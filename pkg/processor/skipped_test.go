@@ -0,0 +1,25 @@
+package processor
+
+import "testing"
+
+func TestSkippedPathMessage(t *testing.T) {
+	tests := []struct {
+		skipped  SkippedPath
+		expected string
+	}{
+		{SkippedPath{Reason: SkipExtension}, "extension not in files.extensions"},
+		{SkippedPath{Reason: SkipIgnored, Detail: "*.tmp.md"}, `matches "*.tmp.md" in files.ignore_patterns`},
+		{SkippedPath{Reason: SkipTooLarge, Detail: "100"}, "larger than files.max_file_size (100 bytes)"},
+		{SkippedPath{Reason: SkipBinary}, "not a text file"},
+		{SkippedPath{Reason: SkipSymlink}, "symbolic link (files.follow_symlinks is disabled)"},
+		{SkippedPath{Reason: SkipExcluded}, "excluded from discovery"},
+		{SkippedPath{Reason: SkipUnreadable, Detail: "permission denied"}, "permission denied"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.skipped.Reason), func(t *testing.T) {
+			if message := tt.skipped.Message(); message != tt.expected {
+				t.Errorf("Message() = %q, want %q", message, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phases of a run measured by Timings
+const (
+	PhaseDiscover = "discover"
+	PhaseRead     = "read"
+	PhaseParse    = "parse"
+	PhaseFormat   = "format"
+	PhaseRender   = "render"
+	PhaseWrite    = "write"
+)
+
+// Phases lists the phases of a run in the order they happen
+var Phases = []string{PhaseDiscover, PhaseRead, PhaseParse, PhaseFormat, PhaseRender, PhaseWrite}
+
+// FileTiming is the time spent on a single file
+type FileTiming struct {
+	Path string
+	// Phases holds the time spent in each phase
+	Phases map[string]time.Duration
+	// Total is the wall time from starting to finishing the file
+	Total time.Duration
+}
+
+// Timings measures the time spent in each phase of a run, in total and per
+// file. A nil *Timings measures nothing, so callers need not check whether
+// timings were requested.
+type Timings struct {
+	mu     sync.Mutex
+	phases map[string]time.Duration
+	files  []FileTiming
+	// current is the index of the file being processed, or -1
+	current int
+	started time.Time
+	now     func() time.Time
+}
+
+// NewTimings returns empty timings
+func NewTimings() *Timings {
+	return &Timings{phases: make(map[string]time.Duration), current: -1, now: time.Now}
+}
+
+// StartFile starts timing the file at path; phases started until EndFile
+// are attributed to it
+func (t *Timings) StartFile(path string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.files = append(t.files, FileTiming{Path: path, Phases: make(map[string]time.Duration)})
+	t.current = len(t.files) - 1
+	t.started = t.now()
+}
+
+// EndFile finishes timing the file started last
+func (t *Timings) EndFile() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current >= 0 {
+		t.files[t.current].Total = t.now().Sub(t.started)
+		t.current = -1
+	}
+}
+
+// Start starts timing phase and returns the function that stops it
+func (t *Timings) Start(phase string) func() {
+	if t == nil {
+		return func() {}
+	}
+	t.mu.Lock()
+	file, start := t.current, t.now()
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		elapsed := t.now().Sub(start)
+		t.phases[phase] += elapsed
+		if file >= 0 {
+			t.files[file].Phases[phase] += elapsed
+		}
+	}
+}
+
+// Total returns the time spent in phase over the whole run
+func (t *Timings) Total(phase string) time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.phases[phase]
+}
+
+// Slowest returns the n files that took longest, slowest first
+func (t *Timings) Slowest(n int) []FileTiming {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	files := append([]FileTiming(nil), t.files...)
+	t.mu.Unlock()
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Total > files[j].Total
+	})
+	if len(files) > n {
+		files = files[:n]
+	}
+	return files
+}
+
+// WriteSummary writes the time spent in each phase and the n slowest files
+// to w
+func (t *Timings) WriteSummary(w io.Writer, n int) {
+	if t == nil {
+		return
+	}
+	fmt.Fprintln(w, "Timings:")
+	for _, phase := range Phases {
+		fmt.Fprintf(w, "  %-10s %s\n", phase, round(t.Total(phase)))
+	}
+
+	slowest := t.Slowest(n)
+	if len(slowest) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Slowest files:")
+	for _, file := range slowest {
+		var phases []string
+		for _, phase := range Phases {
+			if d, ok := file.Phases[phase]; ok {
+				phases = append(phases, fmt.Sprintf("%s %s", phase, round(d)))
+			}
+		}
+		fmt.Fprintf(w, "  %-10s %s (%s)\n", round(file.Total), file.Path, strings.Join(phases, ", "))
+	}
+}
+
+// round rounds d for display
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
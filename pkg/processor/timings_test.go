@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTimings(t *testing.T) {
+	timings := NewTimings()
+	clock := time.Unix(0, 0)
+	timings.now = func() time.Time { return clock }
+	tick := func(d time.Duration) { clock = clock.Add(d) }
+
+	stop := timings.Start(PhaseDiscover)
+	tick(time.Millisecond)
+	stop()
+
+	for _, file := range []struct {
+		path  string
+		parse time.Duration
+	}{{"a.md", 2 * time.Millisecond}, {"b.md", 5 * time.Millisecond}} {
+		timings.StartFile(file.path)
+		stop := timings.Start(PhaseParse)
+		tick(file.parse)
+		stop()
+		stop = timings.Start(PhaseRender)
+		tick(time.Millisecond)
+		stop()
+		timings.EndFile()
+	}
+
+	if got := timings.Total(PhaseParse); got != 7*time.Millisecond {
+		t.Errorf("Expected 7ms parsing, got %s", got)
+	}
+	slowest := timings.Slowest(1)
+	if len(slowest) != 1 || slowest[0].Path != "b.md" || slowest[0].Total != 6*time.Millisecond {
+		t.Fatalf("Expected b.md to be slowest at 6ms, got %+v", slowest)
+	}
+	if _, ok := slowest[0].Phases[PhaseDiscover]; ok {
+		t.Error("Expected discovery not to be attributed to a file")
+	}
+
+	var out bytes.Buffer
+	timings.WriteSummary(&out, 1)
+	expected := "Timings:\n" +
+		"  discover   1ms\n" +
+		"  read       0s\n" +
+		"  parse      7ms\n" +
+		"  format     0s\n" +
+		"  render     2ms\n" +
+		"  write      0s\n" +
+		"Slowest files:\n" +
+		"  6ms        b.md (parse 5ms, render 1ms)\n"
+	if out.String() != expected {
+		t.Errorf("Expected summary:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestTimingsNil(t *testing.T) {
+	var timings *Timings
+	timings.StartFile("a.md")
+	timings.Start(PhaseParse)()
+	timings.EndFile()
+	if timings.Total(PhaseParse) != 0 || timings.Slowest(1) != nil {
+		t.Error("Expected nil timings to measure nothing")
+	}
+}
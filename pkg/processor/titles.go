@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// TitleIndex resolves links between documents to the H1 title of their
+// target, parsing each target at most once
+type TitleIndex struct {
+	parser     parser.Parser
+	extensions []string
+
+	mu     sync.Mutex
+	titles map[string]string
+}
+
+// NewTitleIndex creates a title index that parses targets with p. Only
+// targets with one of the given extensions are resolved.
+func NewTitleIndex(p parser.Parser, extensions []string) *TitleIndex {
+	return &TitleIndex{
+		parser:     p,
+		extensions: extensions,
+		titles:     make(map[string]string),
+	}
+}
+
+// Title returns the H1 title of the document destination points to,
+// relative to the file containing the link. URLs, site-absolute paths and
+// documents without an H1 are not resolved.
+func (idx *TitleIndex) Title(fromFile, destination string) (string, bool) {
+	target, ok := idx.targetPath(fromFile, destination)
+	if !ok {
+		return "", false
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	title, cached := idx.titles[target]
+	if !cached {
+		title = idx.readTitle(target)
+		idx.titles[target] = title
+	}
+	return title, title != ""
+}
+
+// targetPath returns the file a relative link destination points to
+func (idx *TitleIndex) targetPath(fromFile, destination string) (string, bool) {
+	target, ok := LocalPath(fromFile, destination)
+	if !ok {
+		return "", false
+	}
+
+	ext := strings.ToLower(filepath.Ext(target))
+	supported := false
+	for _, candidate := range idx.extensions {
+		if ext == candidate {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return "", false
+	}
+	return target, true
+}
+
+// LocalPath returns the absolute path of the file a relative link
+// destination points to from fromFile, without its query or fragment. URLs,
+// site-absolute paths and fragment-only destinations are not local.
+func LocalPath(fromFile, destination string) (string, bool) {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || path.IsAbs(u.Path) {
+		return "", false
+	}
+	target, err := filepath.Abs(filepath.Join(filepath.Dir(fromFile), filepath.FromSlash(u.Path)))
+	if err != nil {
+		return "", false
+	}
+	return target, true
+}
+
+// readTitle returns the text of the first H1 of the file at target, or ""
+func (idx *TitleIndex) readTitle(target string) string {
+	content, err := os.ReadFile(target) // #nosec G304 - links are resolved relative to processed files
+	if err != nil {
+		return ""
+	}
+	doc, err := idx.parser.Parse(content)
+	if err != nil {
+		return ""
+	}
+
+	walker := parser.NewWalker(doc)
+	for node, ok := walker.Next(); ok; node, ok = walker.Next() {
+		if heading, ok := node.(*parser.Heading); ok && heading.Level == 1 {
+			return heading.Text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestTitleIndex_Title(t *testing.T) {
+	dir := t.TempDir()
+	docs := filepath.Join(dir, "docs")
+	if err := os.MkdirAll(docs, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"docs/start.md": "Intro\n\n# Getting Started {#start}\n\n# Second\n",
+		"docs/none.md":  "## Only H2\n",
+		"docs/data.txt": "# Not markdown\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idx := NewTitleIndex(parser.NewGoldmarkParser(), []string{".md"})
+	from := filepath.Join(dir, "index.md")
+
+	tests := []struct {
+		destination string
+		expected    string
+	}{
+		{"docs/start.md", "Getting Started"},
+		{"./docs/start.md#section", "Getting Started"},
+		{"docs/none.md", ""},
+		{"docs/data.txt", ""},
+		{"docs/missing.md", ""},
+		{"https://example.com/a.md", ""},
+		{"/docs/start.md", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			title, ok := idx.Title(from, tt.destination)
+			if title != tt.expected || ok != (tt.expected != "") {
+				t.Errorf("Title(%q) = %q, %v; want %q", tt.destination, title, ok, tt.expected)
+			}
+		})
+	}
+
+	// Titles are cached once resolved
+	if err := os.Remove(filepath.Join(docs, "start.md")); err != nil {
+		t.Fatal(err)
+	}
+	if title, _ := idx.Title(filepath.Join(docs, "other.md"), "start.md"); title != "Getting Started" {
+		t.Errorf("Expected cached title, got %q", title)
+	}
+}
@@ -0,0 +1,227 @@
+package processor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Constants
+const (
+	// stagedFilePattern is the temp file name pattern used for staged writes
+	stagedFilePattern = ".mdfmt-*.tmp"
+)
+
+// ErrModifiedOnDisk is returned when a file changed on disk after it was read
+var ErrModifiedOnDisk = errors.New("file changed on disk during formatting")
+
+// stagedWrite describes a single pending file replacement
+type stagedWrite struct {
+	path     string
+	tempPath string
+	original []byte
+	hash     [sha256.Size]byte
+	mode     os.FileMode
+}
+
+// WriteTransaction stages formatted outputs next to their targets and only
+// replaces the originals once every file has been staged successfully. If any
+// step fails, or the run is interrupted, all staged and committed changes are
+// rolled back so the tree is never left half-formatted.
+type WriteTransaction struct {
+	mu        sync.Mutex
+	staged    []stagedWrite
+	committed []stagedWrite
+	skipped   []string
+	closed    bool
+	// backup, if set, keeps the original of each file replaced on commit
+	backup *Backup
+	// backups holds the backups written by the current commit
+	backups []string
+}
+
+// NewWriteTransaction creates a new empty write transaction
+func NewWriteTransaction() *WriteTransaction {
+	return &WriteTransaction{}
+}
+
+// SetBackup makes Commit write a backup of each file just before replacing
+// it. Backups of files that are restored on rollback are removed again.
+func (t *WriteTransaction) SetBackup(b Backup) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.backup = &b
+}
+
+// Stage writes content to a temporary file in the same directory as path.
+// The original content is kept so the replacement can be undone on rollback.
+// If the file no longer matches original, ErrModifiedOnDisk is returned and
+// nothing is staged.
+func (t *WriteTransaction) Stage(path string, original, content []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return fmt.Errorf("write transaction already finished")
+	}
+
+	hash := sha256.Sum256(original)
+	if unchanged, err := matchesOnDisk(path, hash); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	} else if !unchanged {
+		return fmt.Errorf("%s: %w", path, ErrModifiedOnDisk)
+	}
+
+	mode := os.FileMode(FilePermissions)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), stagedFilePattern)
+	if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	tempPath := tmp.Name()
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	if err := os.Chmod(tempPath, mode); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+
+	t.staged = append(t.staged, stagedWrite{
+		path:     path,
+		tempPath: tempPath,
+		original: original,
+		hash:     hash,
+		mode:     mode,
+	})
+	return nil
+}
+
+// Len returns the number of staged files
+func (t *WriteTransaction) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.staged)
+}
+
+// Commit renames all staged files over their targets. Files that changed on
+// disk since they were read are left untouched and reported by Skipped. If a
+// rename fails, the files replaced so far are restored and the remaining
+// staged files removed.
+func (t *WriteTransaction) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return fmt.Errorf("write transaction already finished")
+	}
+	t.closed = true
+
+	for i, sw := range t.staged {
+		unchanged, err := matchesOnDisk(sw.path, sw.hash)
+		if err == nil && !unchanged {
+			os.Remove(sw.tempPath)
+			t.skipped = append(t.skipped, sw.path)
+			continue
+		}
+
+		if err == nil && t.backup != nil {
+			var backupPath string
+			if backupPath, err = t.backup.write(sw.path, sw.original, sw.mode); err == nil {
+				t.backups = append(t.backups, backupPath)
+			} else {
+				err = fmt.Errorf("failed to back up: %w", err)
+			}
+		}
+		if err == nil {
+			err = os.Rename(sw.tempPath, sw.path)
+		}
+		if err != nil {
+			commitErr := fmt.Errorf("failed to replace %s: %w", sw.path, err)
+			t.staged = t.staged[i:]
+			return errors.Join(commitErr, t.rollbackLocked())
+		}
+		t.committed = append(t.committed, sw)
+	}
+
+	t.staged = nil
+	t.committed = nil
+	t.backups = nil
+	return nil
+}
+
+// Skipped returns the files left untouched because they changed on disk
+func (t *WriteTransaction) Skipped() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.skipped...)
+}
+
+// Rollback discards staged files and restores the original content of any
+// files that were already replaced. It is safe to call more than once.
+func (t *WriteTransaction) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	return t.rollbackLocked()
+}
+
+// rollbackLocked performs the rollback; the caller must hold t.mu
+func (t *WriteTransaction) rollbackLocked() error {
+	var errs []error
+
+	for _, sw := range t.staged {
+		if err := os.Remove(sw.tempPath); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to remove staged file %s: %w", sw.tempPath, err))
+		}
+	}
+	t.staged = nil
+
+	// Restore in reverse order of commit
+	for i := len(t.committed) - 1; i >= 0; i-- {
+		sw := t.committed[i]
+		if err := os.WriteFile(sw.path, sw.original, sw.mode); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", sw.path, err))
+		}
+	}
+	t.committed = nil
+
+	for _, path := range t.backups {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to remove backup %s: %w", path, err))
+		}
+	}
+	t.backups = nil
+
+	return errors.Join(errs...)
+}
+
+// matchesOnDisk reports whether the file at path still has the given content
+// hash. A file that no longer exists is reported as modified.
+func matchesOnDisk(path string, hash [sha256.Size]byte) (bool, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	content, err := os.ReadFile(path) // #nosec G304 - path is validated through file discovery
+	if err != nil {
+		return false, err
+	}
+	current := sha256.Sum256(content)
+	return bytes.Equal(current[:], hash[:]), nil
+}
@@ -0,0 +1,188 @@
+package processor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteTransaction_Commit tests that staged files replace originals on commit
+func TestWriteTransaction_Commit(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")}
+	for _, p := range paths {
+		if err := os.WriteFile(p, []byte("original"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", p, err)
+		}
+	}
+
+	tx := NewWriteTransaction()
+	for _, p := range paths {
+		if err := tx.Stage(p, []byte("original"), []byte("formatted")); err != nil {
+			t.Fatalf("Stage failed: %v", err)
+		}
+	}
+
+	// Nothing is replaced before commit
+	for _, p := range paths {
+		content, _ := os.ReadFile(p)
+		if string(content) != "original" {
+			t.Errorf("Expected %s to be untouched before commit, got %q", p, content)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	for _, p := range paths {
+		content, _ := os.ReadFile(p)
+		if string(content) != "formatted" {
+			t.Errorf("Expected %s to be formatted, got %q", p, content)
+		}
+		info, _ := os.Stat(p)
+		if info.Mode().Perm() != 0o644 {
+			t.Errorf("Expected mode 0644 to be preserved, got %v", info.Mode().Perm())
+		}
+	}
+
+	assertNoStagedFiles(t, dir)
+}
+
+// TestWriteTransaction_Rollback tests that rollback discards staged files
+func TestWriteTransaction_Rollback(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(path, []byte("original"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tx := NewWriteTransaction()
+	if err := tx.Stage(path, []byte("original"), []byte("formatted")); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if string(content) != "original" {
+		t.Errorf("Expected original content after rollback, got %q", content)
+	}
+	assertNoStagedFiles(t, dir)
+
+	if err := tx.Commit(); err == nil {
+		t.Error("Expected commit after rollback to fail")
+	}
+}
+
+// TestWriteTransaction_CommitFailureRestores tests that a failed rename restores committed files
+func TestWriteTransaction_CommitFailureRestores(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.md")
+	bad := filepath.Join(dir, "bad.md")
+	for _, p := range []string{good, bad} {
+		if err := os.WriteFile(p, []byte("original"), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", p, err)
+		}
+	}
+
+	tx := NewWriteTransaction()
+	if err := tx.Stage(good, []byte("original"), []byte("formatted")); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+	if err := tx.Stage(bad, []byte("original"), []byte("formatted")); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+
+	// Make the second rename fail by replacing its target with a non-empty directory
+	if err := os.Remove(bad); err != nil {
+		t.Fatalf("Failed to remove %s: %v", bad, err)
+	}
+	if err := os.MkdirAll(filepath.Join(bad, "child"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Expected commit to fail")
+	}
+
+	content, _ := os.ReadFile(good)
+	if string(content) != "original" {
+		t.Errorf("Expected %s to be restored, got %q", good, content)
+	}
+	assertNoStagedFiles(t, dir)
+}
+
+// assertNoStagedFiles fails the test if any staged temp files remain in dir
+func assertNoStagedFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, stagedFilePattern))
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if len(matches) > 0 {
+		t.Errorf("Expected no staged files, found %v", matches)
+	}
+}
+
+// TestWriteTransaction_SkipsModifiedFiles tests that files changed on disk are never clobbered
+func TestWriteTransaction_SkipsModifiedFiles(t *testing.T) {
+	dir := t.TempDir()
+	stable := filepath.Join(dir, "stable.md")
+	edited := filepath.Join(dir, "edited.md")
+	for _, p := range []string{stable, edited} {
+		if err := os.WriteFile(p, []byte("original"), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", p, err)
+		}
+	}
+
+	tx := NewWriteTransaction()
+	for _, p := range []string{stable, edited} {
+		if err := tx.Stage(p, []byte("original"), []byte("formatted")); err != nil {
+			t.Fatalf("Stage failed: %v", err)
+		}
+	}
+
+	// Simulate an editor saving the file while formatting was in progress
+	if err := os.WriteFile(edited, []byte("newer content"), 0o600); err != nil {
+		t.Fatalf("Failed to modify %s: %v", edited, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if content, _ := os.ReadFile(stable); string(content) != "formatted" {
+		t.Errorf("Expected %s to be formatted, got %q", stable, content)
+	}
+	if content, _ := os.ReadFile(edited); string(content) != "newer content" {
+		t.Errorf("Expected %s to keep newer content, got %q", edited, content)
+	}
+
+	skipped := tx.Skipped()
+	if len(skipped) != 1 || skipped[0] != edited {
+		t.Errorf("Expected %s to be skipped, got %v", edited, skipped)
+	}
+	assertNoStagedFiles(t, dir)
+}
+
+// TestWriteTransaction_StageDetectsModification tests that staging refuses stale content
+func TestWriteTransaction_StageDetectsModification(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(path, []byte("newer content"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tx := NewWriteTransaction()
+	err := tx.Stage(path, []byte("original"), []byte("formatted"))
+	if !errors.Is(err, ErrModifiedOnDisk) {
+		t.Fatalf("Expected ErrModifiedOnDisk, got %v", err)
+	}
+	if tx.Len() != 0 {
+		t.Errorf("Expected nothing staged, got %d", tx.Len())
+	}
+	assertNoStagedFiles(t, dir)
+}
@@ -0,0 +1,99 @@
+package renderer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// commonBlockStarts matches the markup that starts a block on any line:
+// headings, blockquotes, code fences and thematic breaks
+const commonBlockStarts = `(#)#{0,5}(?:[ \t]|$)|(>)|` + "(`)``|(~)~~|" +
+	`(\*)[ \t]*\*[ \t]*\*[ \t*]*$|(-)[ \t]*-[ \t]*-[ \t-]*$|(_)[ \t]*_[ \t]*_[ \t_]*$`
+
+// Patterns of the markup that starts a block other than a paragraph, after
+// up to three spaces. The first submatch is the character to escape.
+var (
+	// blockStartPattern matches markup that starts a block on the first
+	// line of a paragraph, including any list item and HTML blocks, and a
+	// colon, which after a definition list or paragraph starts a description
+	blockStartPattern = regexp.MustCompile(`^ {0,3}(?:` + commonBlockStarts +
+		`|([-+*])(?:[ \t]|$)|[0-9]{1,9}([.)])(?:[ \t]|$)|(<)` + parser.HTMLBlockTag + `|(:)(?:[ \t]|$))`)
+	// thematicBreakPattern matches a line that is a thematic break
+	thematicBreakPattern = regexp.MustCompile(`^ {0,3}(?:\*[ \t]*\*[ \t]*\*[ \t*]*|-[ \t]*-[ \t]*-[ \t-]*|_[ \t]*_[ \t]*_[ \t_]*)$`)
+	// definitionLabelPattern matches the label of a link reference
+	// definition starting a paragraph, which may span lines. Its colon is
+	// escaped: a link the label refers to renders the same.
+	definitionLabelPattern = regexp.MustCompile(`^ {0,3}\[(?:[^\[\]\\]|\\.)+\]:`)
+	// interruptPattern matches markup that starts a block on a later line,
+	// interrupting the paragraph: list items must have text and ordered ones
+	// start at 1, lines of = or - underline the paragraph as a heading, a
+	// delimiter row turns it into the header of a table and a colon into
+	// the term of a definition list. HTML that starts a block is never
+	// wrapped to the start of a line, as parser.WrapTokens keeps it with the
+	// word before it.
+	interruptPattern = regexp.MustCompile(`^ {0,3}(?:` + commonBlockStarts +
+		`|([-+*])[ \t]+\S|0{0,8}1([.)])[ \t]+\S|(=)=*[ \t]*$|(-)-*[ \t]*$` +
+		`|([-|:])[-|: \t]*$|(:)(?:[ \t]|$))`)
+)
+
+// escapeBlockStarts escapes the markup at the start of lines of paragraph
+// text that would otherwise be parsed as another block, such as a heading,
+// list item, blockquote, code fence or thematic break, when reflow moves it
+// to the start of a line. Markup already escaped is left as it is.
+func escapeBlockStarts(text string) string {
+	if text == "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = escapeLine(line, i == 0)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeDefinitionLabel escapes the colon after the label of a link
+// reference definition starting paragraph text. The text is no definition,
+// as it was parsed as a paragraph, but breaking its line after the
+// destination would make it one. Unlike escapeBlockStarts, it runs before
+// reflow, which then counts the backslash.
+func escapeDefinitionLabel(text string) string {
+	m := definitionLabelPattern.FindStringIndex(text)
+	if m == nil {
+		return text
+	}
+	return text[:m[1]-1] + `\` + text[m[1]-1:]
+}
+
+// escapeLine escapes the markup at the start of line, where first tells
+// whether it is the first line of the paragraph. The backslash goes before
+// the character the first matching submatch captures.
+func escapeLine(line string, first bool) string {
+	pattern := interruptPattern
+	if first {
+		pattern = blockStartPattern
+	}
+	m := pattern.FindStringSubmatchIndex(line)
+	if m == nil {
+		return line
+	}
+	for i := 2; i < len(m); i += 2 {
+		if m[i] < 0 {
+			continue
+		}
+		if line[m[i]] == '`' && !opensFence(line[m[i]:]) {
+			return line
+		}
+		return line[:m[i]] + `\` + line[m[i]:]
+	}
+	return line
+}
+
+// opensFence reports whether a line starting with a run of backticks opens
+// a code fence: its info string cannot contain a backtick, so otherwise the
+// run starts a code span
+func opensFence(line string) bool {
+	rest := strings.TrimLeft(line, "`")
+	return !strings.Contains(rest, "`")
+}
@@ -0,0 +1,175 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestEscapeBlockStarts(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"plain", "just text\nmore text", "just text\nmore text"},
+		{"heading", "# not a heading", `\# not a heading`},
+		{"deep heading", "see\n###### six", "see\n\\###### six"},
+		{"too deep heading", "see\n####### seven", "see\n####### seven"},
+		{"hashtag", "see\n#tag", "see\n#tag"},
+		{"bullet", "- not a list", `\- not a list`},
+		{"star bullet", "see\n* item", "see\n\\* item"},
+		{"plus bullet", "see\n+ item", "see\n\\+ item"},
+		{"empty bullet", "see\n+", "see\n+"},
+		{"emphasis", "see\n*emphasis*", "see\n*emphasis*"},
+		{"ordered", "2. not a list", `2\. not a list`},
+		{"ordered paren", "10) not a list", `10\) not a list`},
+		{"ordered interrupt", "see\n1. item", "see\n1\\. item"},
+		{"ordered no interrupt", "see\n2. item", "see\n2. item"},
+		{"version", "see\n1.2 release", "see\n1.2 release"},
+		{"blockquote", "see\n> quote", "see\n\\> quote"},
+		{"fence", "see\n```go", "see\n\\```go"},
+		{"code span at start", "```js``` is code", "```js``` is code"},
+		{"code span interrupt", "see\n```` ```md ````", "see\n```` ```md ````"},
+		{"fence with tilde info", "see\n~~~ a ` b", "see\n\\~~~ a ` b"},
+		{"tilde fence", "see\n~~~", "see\n\\~~~"},
+		{"code span", "see\n`code`", "see\n`code`"},
+		{"thematic break", "see\n***", "see\n\\***"},
+		{"spaced break", "see\n_ _ _", "see\n\\_ _ _"},
+		{"snake case", "see\n_private_name", "see\n_private_name"},
+		{"setext equals", "see\n===", "see\n\\==="},
+		{"setext dash", "see\n-", "see\n\\-"},
+		{"first line equals", "=== see", "=== see"},
+		{"table delimiter row", "see\n--- | ---", "see\n\\--- | ---"},
+		{"table delimiter pipes", "see\n| :-- |", "see\n\\| :-- |"},
+		{"table delimiter colon", "see\n:---|", "see\n\\:---|"},
+		{"table cell", "see\n| cell |", "see\n| cell |"},
+		{"definition", "see\n: - <div>", "see\n\\: - <div>"},
+		{"colon", "see\n:colon", "see\n:colon"},
+		{"first line definition", ": not a description", `\: not a description`},
+		{"first line colon", ":", `\:`},
+		{"first line delimiter row", "--- | ---", "--- | ---"},
+		{"html block", "<div> first", "\\<div> first"},
+		{"html block interrupt", "see\n<div>", "see\n<div>"},
+		{"inline html", "see\n<span>", "see\n<span>"},
+		{"autolink", "see\n<https://example.com>", "see\n<https://example.com>"},
+		{"indented", "see\n   # heading", "see\n   \\# heading"},
+		{"escaped heading", `\# heading`, `\# heading`},
+		{"escaped ordered", "see\n1\\. item", "see\n1\\. item"},
+		{"escaped quote", "see\n\\> quote", "see\n\\> quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeBlockStarts(tt.text); got != tt.expected {
+				t.Errorf("escapeBlockStarts(%q) = %q, expected %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEscapeDefinitionLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"label", "[label]:0000 0000", "[label]\\:0000 0000"},
+		{"escaped bracket", `[a\]b]: c d`, `[a\]b]\: c d`},
+		{"label lines", "[\nlabel]:x y", "[\nlabel]\\:x y"},
+		{"later line", "see\n[label]: x", "see\n[label]: x"},
+		{"link", "[link](x): y", "[link](x): y"},
+		{"escaped colon", `[label]\: x`, `[label]\: x`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeDefinitionLabel(tt.text); got != tt.expected {
+				t.Errorf("escapeDefinitionLabel(%q) = %q, expected %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderReflowKeepsParagraphs(t *testing.T) {
+	texts := []string{
+		"Count to # three or more",
+		"Items are - one and + two and * three",
+		"The version is 1. Then 2) follows",
+		"Quoted > text and ``` fences and ~~~ tildes",
+		"Rules like *** and ___ and === and -",
+		"Tags such as <div> and <!-- comments -->",
+		"```js``` is a code span and so is ```` ``` ````",
+		`Already \# escaped and 1\. escaped`,
+		"word word --- | --- and | :-: | or :--",
+		"word [x] * > : - <div> text _",
+		"[000]:0000 0000 and [ref]: more",
+	}
+	cfg := config.Default()
+
+	for _, text := range texts {
+		for width := 1; width <= len(text); width++ {
+			cfg.LineWidth = width
+			doc := &parser.Document{Children: []parser.Node{&parser.Paragraph{Text: text}}}
+			output, err := New().Render(doc, cfg)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+
+			reparsed, err := parser.NewGoldmarkParser().Parse([]byte(output))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(reparsed.Children) != 1 || reparsed.Children[0].Type() != parser.NodeParagraph {
+				t.Errorf("Expected %q at width %d to stay one paragraph, got:\n%s", text, width, output)
+				continue
+			}
+			again, err := New().Render(reparsed, cfg)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if again != output {
+				t.Errorf("Expected stable output at width %d:\n%s\ngot:\n%s", width, output, again)
+			}
+		}
+	}
+}
+
+func TestRenderInlineHTMLNotEscaped(t *testing.T) {
+	cfg := config.Default()
+	cfg.LineWidth = 10
+	doc := &parser.Document{Children: []parser.Node{&parser.Paragraph{Text: "Some text <div> and <!-- note --> here"}}}
+	output, err := New().Render(doc, cfg)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := "Some\ntext <div>\nand <!--\nnote -->\nhere\n"
+	if output != expected {
+		t.Errorf("Expected inline HTML to stay after a word:\n%q\ngot:\n%q", expected, output)
+	}
+}
+
+func TestRenderCodeSpanStartIdempotent(t *testing.T) {
+	inputs := []string{
+		"```js``` is a code span\n",
+		"- ```` ```markdown ```` fences\n- ```js``` spans\n",
+	}
+	cfg := config.Default()
+	for _, input := range inputs {
+		output := input
+		for i := 0; i < 2; i++ {
+			doc, err := parser.NewGoldmarkParser().Parse([]byte(output))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if output, err = New().Render(doc, cfg); err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if output != input {
+				t.Errorf("Expected %q to be kept, got %q after %d runs", input, output, i+1)
+				break
+			}
+		}
+	}
+}
@@ -2,9 +2,14 @@
 package renderer
 
 import (
+	"bytes"
+	"context"
 	"io"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/Gosayram/go-mdfmt/pkg/config"
 	"github.com/Gosayram/go-mdfmt/pkg/parser"
@@ -14,8 +19,40 @@ import (
 const (
 	// SecondHeadingLevel represents heading level 2
 	SecondHeadingLevel = 2
+	// DefinitionMarker introduces a description in a definition list
+	DefinitionMarker = ": "
+	// maxPooledBufferSize is the capacity above which output buffers are
+	// not returned to the pool, so one huge document does not pin memory
+	maxPooledBufferSize = 1 << 20
 )
 
+var (
+	// bufferPool holds output buffers reused across renders
+	bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+	// quotePrefixPattern matches the blockquote markers starting a line
+	quotePrefixPattern = regexp.MustCompile(`^(?:[ \t]*>[ \t]?)+`)
+	// markdownLinkPattern matches inline markdown links
+	markdownLinkPattern = regexp.MustCompile(`\[[^\]]*\]\([^)]*\)`)
+	// brokenLinkPattern matches links broken across lines: [text\nmore text](url)
+	brokenLinkPattern = regexp.MustCompile(`\[([^\]]*)\n([^\]]*)\]\(([^)]*)\)`)
+	// multiBreakLinkPattern matches links whose text has several line breaks
+	multiBreakLinkPattern = regexp.MustCompile(`\[([^\]]*(?:\n[^\]]*)*)\]\(([^)]*)\)`)
+)
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buf to the pool
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBufferSize {
+		bufferPool.Put(buf)
+	}
+}
+
 // Renderer represents a renderer that converts AST back to markdown
 type Renderer interface {
 	// Render renders the AST to markdown
@@ -26,8 +63,22 @@ type Renderer interface {
 
 // MarkdownRenderer renders AST back to markdown format
 type MarkdownRenderer struct {
-	output strings.Builder
+	// output is taken from bufferPool for the duration of a render
+	output *bytes.Buffer
 	config *config.Config
+	// ctx is checked between top-level blocks
+	ctx context.Context
+	// sink receives the output after each top-level block when streaming;
+	// nested renderers have none and keep their whole output
+	sink io.Writer
+	// listIndent indents the items of the list being rendered
+	listIndent string
+	// looseList is set while the items of a loose list are rendered
+	looseList bool
+	// block is the kind of block the paragraphs being rendered are in:
+	// "paragraph" at the top level, "blockquote" or "list". Paragraphs are
+	// wrapped only in the blocks reflow.wrap lists.
+	block string
 }
 
 // New creates a new markdown renderer
@@ -37,43 +88,100 @@ func New() *MarkdownRenderer {
 
 // Render renders the AST to markdown string with whitespace normalization.
 func (r *MarkdownRenderer) Render(doc *parser.Document, cfg *config.Config) (string, error) {
-	r.output.Reset()
-	r.config = cfg
+	return r.RenderContext(context.Background(), doc, cfg)
+}
 
-	if err := r.renderDocument(doc, 0); err != nil {
+// RenderContext renders the AST like Render, returning ctx.Err() if ctx is
+// done before the document has been rendered
+func (r *MarkdownRenderer) RenderContext(ctx context.Context, doc *parser.Document, cfg *config.Config) (string, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := r.RenderToContext(ctx, buf, doc, cfg); err != nil {
 		return "", err
 	}
+	return buf.String(), nil
+}
 
-	result := r.output.String()
+// RenderTo renders the AST to a writer
+func (r *MarkdownRenderer) RenderTo(w io.Writer, doc *parser.Document, cfg *config.Config) error {
+	return r.RenderToContext(context.Background(), w, doc, cfg)
+}
 
-	// Apply document-level whitespace rules
-	result = r.normalizeBlankLines(result, cfg.Whitespace.MaxBlankLines)
+// RenderToContext renders the AST to w like RenderTo, returning ctx.Err() if
+// ctx is done before the document has been rendered. Top-level blocks are
+// streamed to w as they are rendered, so only one block and the current
+// line are held in memory; output may already have been written when an
+// error is returned.
+func (r *MarkdownRenderer) RenderToContext(ctx context.Context, w io.Writer, doc *parser.Document, cfg *config.Config) error {
+	r.output = getBuffer()
+	defer func() {
+		putBuffer(r.output)
+		r.output = nil
+		r.sink = nil
+	}()
+	r.config = cfg
+	r.ctx = ctx
+
+	blocks := NewBlockWriter(w, cfg)
+	blocks.SetFinalNewline(!doc.NoFinalNewline)
+	r.sink = blocks
 
-	// Ensure final newline if configured
-	if cfg.Whitespace.EnsureFinalNewline && !strings.HasSuffix(result, "\n") {
-		result += "\n"
+	if doc.FrontMatter != "" {
+		if err := blocks.WriteFrontMatter(doc.FrontMatter, len(doc.Children) > 0); err != nil {
+			return err
+		}
 	}
 
-	return result, nil
+	if err := r.renderDocument(doc, 0); err != nil {
+		return err
+	}
+	return blocks.Close()
 }
 
-// RenderTo renders the AST to a writer
-func (r *MarkdownRenderer) RenderTo(w io.Writer, doc *parser.Document, cfg *config.Config) error {
-	content, err := r.Render(doc, cfg)
-	if err != nil {
-		return err
+// RenderSection renders the blocks of doc, a section of a larger document,
+// without its front matter or the document-level whitespace rules. The
+// sections of a document written in order with a BlockWriter give the same
+// output as rendering the whole document.
+func (r *MarkdownRenderer) RenderSection(ctx context.Context, doc *parser.Document, cfg *config.Config) (string, error) {
+	r.output = getBuffer()
+	defer func() {
+		putBuffer(r.output)
+		r.output = nil
+	}()
+	r.config = cfg
+	r.ctx = ctx
+
+	if err := r.renderDocument(&parser.Document{Children: doc.Children}, 0); err != nil {
+		return "", err
 	}
+	return r.output.String(), nil
+}
 
-	_, err = w.Write([]byte(content))
+// flush writes the rendered output to the sink of a streaming render
+func (r *MarkdownRenderer) flush() error {
+	if r.sink == nil {
+		return nil
+	}
+	_, err := r.sink.Write(r.output.Bytes())
+	r.output.Reset()
 	return err
 }
 
 // renderDocument renders a document node
 func (r *MarkdownRenderer) renderDocument(doc *parser.Document, depth int) error {
 	for _, child := range doc.Children {
+		if r.ctx != nil {
+			if err := r.ctx.Err(); err != nil {
+				return err
+			}
+		}
 		if err := r.renderNode(child, depth); err != nil {
 			return err
 		}
+		if err := r.flush(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -93,6 +201,29 @@ func (r *MarkdownRenderer) renderNode(node parser.Node, depth int) error {
 		return r.renderCodeBlock(n, depth)
 	case *parser.Text:
 		return r.renderText(n, depth)
+	case *parser.HTMLBlock:
+		return r.renderHTMLBlock(n, depth)
+	case *parser.Details:
+		return r.renderDetails(n, depth)
+	case *parser.Blockquote:
+		return r.renderBlockquote(n, depth)
+	case *parser.Admonition:
+		return r.renderAdmonition(n, depth)
+	case *parser.Math:
+		return r.renderMath(n, depth)
+	case *parser.DefinitionList:
+		return r.renderDefinitionList(n, depth)
+	case *parser.LinkDefinitions:
+		return r.renderLinkDefinitions(n, depth)
+	case *parser.MDXBlock:
+		return r.renderMDXBlock(n, depth)
+	case *parser.MDXElement:
+		return r.renderWrapped(n.Open, n.Children, n.Close, depth)
+	case parser.MarkdownNode:
+		// Nodes of registered extensions render themselves
+		r.output.WriteString(strings.TrimRight(n.Markdown(), "\n"))
+		r.output.WriteString("\n\n")
+		return nil
 	default:
 		// Unknown node type, skip
 		return nil
@@ -101,9 +232,14 @@ func (r *MarkdownRenderer) renderNode(node parser.Node, depth int) error {
 
 // renderHeading renders a heading node
 func (r *MarkdownRenderer) renderHeading(heading *parser.Heading, _ int) error {
+	text := heading.Text
+	if heading.Attributes != "" {
+		text += " " + heading.Attributes
+	}
+
 	if heading.Style == "setext" && heading.Level <= SecondHeadingLevel {
 		// Setext-style heading
-		r.output.WriteString(heading.Text)
+		r.output.WriteString(text)
 		r.output.WriteString("\n")
 
 		marker := "="
@@ -111,7 +247,7 @@ func (r *MarkdownRenderer) renderHeading(heading *parser.Heading, _ int) error {
 			marker = "-"
 		}
 
-		textLength := len(strings.TrimSpace(heading.Text))
+		textLength := len(strings.TrimSpace(text))
 		if textLength == 0 {
 			textLength = 3 // minimum length
 		}
@@ -119,10 +255,14 @@ func (r *MarkdownRenderer) renderHeading(heading *parser.Heading, _ int) error {
 		r.output.WriteString(strings.Repeat(marker, textLength))
 		r.output.WriteString("\n\n")
 	} else {
-		// ATX-style heading
+		// ATX-style heading; a run of # ending the text would be taken
+		// for a closing sequence
+		if run := strings.TrimRight(text, "#"); run != text && (run == "" || strings.HasSuffix(run, " ")) {
+			text = run + `\` + text[len(run):]
+		}
 		r.output.WriteString(strings.Repeat("#", heading.Level))
 		r.output.WriteString(" ")
-		r.output.WriteString(heading.Text)
+		r.output.WriteString(text)
 		r.output.WriteString("\n\n")
 	}
 
@@ -132,28 +272,69 @@ func (r *MarkdownRenderer) renderHeading(heading *parser.Heading, _ int) error {
 // renderParagraph renders a paragraph node
 func (r *MarkdownRenderer) renderParagraph(para *parser.Paragraph, _ int) error {
 	content := para.Text
+	// Paragraphs of nothing but whitespace, such as \v, are dropped
+	if content == "" {
+		return nil
+	}
 
 	// Fix broken markdown links first
-	content = r.fixBrokenLinks(content)
+	content = escapeDefinitionLabel(r.fixBrokenLinks(content))
 
 	// Apply line width wrapping only if no markdown links are present
-	if r.config.LineWidth > 0 && !r.containsMarkdownLinks(content) {
+	if r.config.LineWidth > 0 && !para.NoReflow && r.wraps() && !r.containsMarkdownLinks(content) {
 		content = r.wrapText(content, r.config.LineWidth)
 	}
 
-	r.output.WriteString(content)
-	r.output.WriteString("\n\n")
+	r.output.WriteString(r.hardBreaks(escapeBlockStarts(content)))
+	r.output.WriteString("\n")
+	// A blank line after template tags opening a list, such as
+	// {% for item in items %}, would end up between its items
+	if !para.Interrupted || !r.onlyTagLines(content) {
+		r.output.WriteString("\n")
+	}
 
 	return nil
 }
 
+// onlyTagLines reports whether all lines of text hold only template tags
+func (r *MarkdownRenderer) onlyTagLines(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if !r.inlineSyntax().IsTagLine(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// hardBreaks writes the hard breaks of text in whitespace.hard_break_style.
+// A line holding nothing but its break keeps the backslash, since spaces
+// would make it blank and end the paragraph.
+func (r *MarkdownRenderer) hardBreaks(text string) string {
+	if r.config.Whitespace.HardBreakStyle != "spaces" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines[:len(lines)-1] {
+		if parser.IsHardBreak(line) && strings.TrimSpace(line[:len(line)-1]) != "" {
+			lines[i] = line[:len(line)-1] + "  "
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wraps reports whether reflow.wrap lists the block paragraphs are being
+// rendered in
+func (r *MarkdownRenderer) wraps() bool {
+	block := r.block
+	if block == "" {
+		block = "paragraph"
+	}
+	return slices.Contains(r.config.Reflow.Wrap, block)
+}
+
 // containsMarkdownLinks checks if text contains markdown links
 func (r *MarkdownRenderer) containsMarkdownLinks(text string) bool {
-	linkPattern := `\[[^\]]*\]\([^)]*\)`
-	matched, _ := regexp.MatchString(linkPattern, text)
-	// Debug: uncomment to see what's happening
-	// fmt.Printf("DEBUG: Text: %q, Contains links: %v\n", text, matched)
-	return matched
+	return markdownLinkPattern.MatchString(text)
 }
 
 // fixBrokenLinks repairs markdown links that have been broken across lines
@@ -171,14 +352,13 @@ func (r *MarkdownRenderer) fixBrokenLinks(text string) string {
 		urlIndex           = 2
 	)
 
-	// Pattern to match broken links: [text\nmore text](url)
-	brokenLinkPattern := `\[([^\]]*)\n([^\]]*)\]\(([^)]*)\)`
-	re := regexp.MustCompile(brokenLinkPattern)
-
-	// Replace broken links with fixed ones
-	fixed := re.ReplaceAllStringFunc(text, func(match string) string {
+	// Replace broken links with fixed ones, keeping hard breaks
+	fixed := brokenLinkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if strings.Contains(match, parser.HardBreak) {
+			return match
+		}
 		// Extract parts
-		parts := re.FindStringSubmatch(match)
+		parts := brokenLinkPattern.FindStringSubmatch(match)
 		if len(parts) == brokenLinkPartsCount {
 			linkText := parts[linkTextPart1Index] + " " + parts[linkTextPart2Index] // Join with space
 			url := parts[urlPartIndex]
@@ -188,12 +368,11 @@ func (r *MarkdownRenderer) fixBrokenLinks(text string) string {
 	})
 
 	// Also handle cases where the link text itself has multiple line breaks
-	multiBreakPattern := `\[([^\]]*(?:\n[^\]]*)*)\]\(([^)]*)\)`
-	re2 := regexp.MustCompile(multiBreakPattern)
-
-	// Replace multi-break links with fixed ones
-	fixed = re2.ReplaceAllStringFunc(fixed, func(match string) string {
-		parts := re2.FindStringSubmatch(match)
+	fixed = multiBreakLinkPattern.ReplaceAllStringFunc(fixed, func(match string) string {
+		if strings.Contains(match, parser.HardBreak) {
+			return match
+		}
+		parts := multiBreakLinkPattern.FindStringSubmatch(match)
 		if len(parts) == multiBreakPartsCount {
 			linkText := strings.ReplaceAll(parts[linkTextIndex], "\n", " ")
 			url := parts[urlIndex]
@@ -207,7 +386,14 @@ func (r *MarkdownRenderer) fixBrokenLinks(text string) string {
 
 // renderList renders a list node
 func (r *MarkdownRenderer) renderList(list *parser.List, depth int) error {
-	for _, item := range list.Items {
+	loose := r.looseList
+	r.looseList = list.Loose
+	defer func() { r.looseList = loose }()
+
+	for i, item := range list.Items {
+		if i > 0 && list.Loose && !bytes.HasSuffix(r.output.Bytes(), []byte("\n\n")) {
+			r.output.WriteString("\n")
+		}
 		if err := r.renderListItem(item, depth+1); err != nil {
 			return err
 		}
@@ -219,59 +405,207 @@ func (r *MarkdownRenderer) renderList(list *parser.List, depth int) error {
 
 // renderListItem renders a list item node
 func (r *MarkdownRenderer) renderListItem(item *parser.ListItem, depth int) error {
-	// Use proper indentation for nested lists only (depth > 1)
-	indent := ""
-	if depth > 1 {
-		indent = strings.Repeat("  ", depth-1)
-	}
+	indent := r.listIndent
 
 	// Determine marker
 	marker := item.Marker
 	if marker == "" {
 		marker = r.config.List.BulletStyle
+		if marker == "preserve" {
+			marker = "-"
+		}
 	}
 
-	r.output.WriteString(indent)
-	r.output.WriteString(marker)
-	r.output.WriteString(" ")
-	r.output.WriteString(item.Text)
+	// Continuation lines hang under the item text, aligned past the marker
+	markerWidth := utf8.RuneCountInString(marker) + 1
+	hanging := indent + strings.Repeat(" ", markerWidth)
 
-	// Render nested elements
-	if len(item.Children) > 0 {
-		r.output.WriteString("\n")
-		for _, child := range item.Children {
-			if err := r.renderNode(child, depth); err != nil {
+	// Nested lists are indented by list.indent, but never less than the
+	// marker width, or they would no longer belong to the item
+	width := r.config.List.Indent
+	if width == 0 {
+		width = config.DefaultListIndent
+	}
+	if width < markerWidth {
+		width = markerWidth
+	}
+	nestedIndent := indent + strings.Repeat(" ", width)
+
+	// An item cannot start with a blank line, so blocks of items without
+	// text, or the list they start with, start on the marker line
+	body, children := r.hardBreaks(escapeBlockStarts(r.wrapItemText(item, hanging))), item.Children
+	if item.Task {
+		marker += " " + checkbox(item.Checked)
+	}
+	prefix := r.prefixItemText
+	if body == "" && len(children) > 0 {
+		n := max(leadingBlocks(children), 1)
+		blocks, err := r.renderItemBlocks(children[:n], hanging)
+		if err != nil {
+			return err
+		}
+		// Unless the markers of nested items without text, such as
+		// "- - -", would make a thematic break
+		if line, _, _ := strings.Cut(blocks, "\n"); !thematicBreakPattern.MatchString(marker + " " + line) {
+			body, children, prefix = blocks, children[n:], prefixLines
+		}
+	}
+	r.output.WriteString(prefix(body, indent+marker+" ", hanging))
+	r.output.WriteString("\n")
+
+	for len(children) > 0 {
+		if list, ok := children[0].(*parser.List); ok {
+			r.listIndent = nestedIndent
+			if list.TabIndented && r.config.Whitespace.Tabs == "preserve" {
+				r.listIndent = indent + tabs(width, r.config.Whitespace.TabWidth)
+			}
+			err := r.renderNode(children[0], depth)
+			r.listIndent = indent
+			if err != nil {
 				return err
 			}
+			// A blank line after the nested list of an item holding only
+			// lists would make a tight list loose
+			if !r.looseList && onlyLists(item.Children) && bytes.HasSuffix(r.output.Bytes(), []byte("\n\n")) {
+				r.output.Truncate(r.output.Len() - 1)
+			}
+			children = children[1:]
+			continue
 		}
-	} else {
-		// Add newline after list item text if no nested elements
+
+		n := leadingBlocks(children)
+		blocks, err := r.renderItemBlocks(children[:n], hanging)
+		if err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(r.output.Bytes(), []byte("\n\n")) {
+			r.output.WriteString("\n")
+		}
+		r.output.WriteString(prefixLines(blocks, hanging, hanging))
 		r.output.WriteString("\n")
+		children = children[n:]
+		if len(children) > 0 {
+			r.output.WriteString("\n")
+		}
 	}
 
 	return nil
 }
 
+// prefixItemText prefixes the lines of item text like prefixLines, except
+// lines of template tags after the first: they continue the text at the
+// start of the line, where their indent is not repeated in what the
+// template renders, such as the items of {% for %} ... {% endfor %}
+func (r *MarkdownRenderer) prefixItemText(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = prefixLines(line, first, first)
+		case r.inlineSyntax().IsTagLine(line):
+			lines[i] = strings.TrimLeft(line, " \t")
+		default:
+			lines[i] = prefixLines(line, rest, rest)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapItemText wraps the first paragraph of a list item, like paragraphs in
+// list items, so its lines fit the line width after the indent of the item
+func (r *MarkdownRenderer) wrapItemText(item *parser.ListItem, indent string) string {
+	text := escapeDefinitionLabel(item.Text)
+	if r.config.LineWidth > 0 && !item.NoReflow && slices.Contains(r.config.Reflow.Wrap, "list") &&
+		!r.containsMarkdownLinks(text) {
+		width := max(r.config.LineWidth-utf8.RuneCountInString(indent), 1)
+		if !item.Task || text == "" {
+			return r.wrapText(text, width)
+		}
+		// The checkbox takes room on the first line only; the placeholder
+		// is followed by a space or a line break
+		placeholder := strings.Repeat("x", len(checkbox(false)))
+		text = r.wrapText(placeholder+" "+text, width)[len(placeholder)+1:]
+	}
+	return text
+}
+
+// checkbox returns the checkbox of a task list item
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// renderItemBlocks renders blocks of a list item, wrapping their paragraphs
+// to fit the line width after the indent of the item
+func (r *MarkdownRenderer) renderItemBlocks(nodes []parser.Node, indent string) (string, error) {
+	if outer := r.config; outer.LineWidth > 0 {
+		cfg := *outer
+		cfg.LineWidth = max(outer.LineWidth-utf8.RuneCountInString(indent), 1)
+		r.config = &cfg
+		defer func() { r.config = outer }()
+	}
+	return r.renderBlocksIn("list", nodes, 0)
+}
+
+// onlyLists reports whether all of nodes are lists
+func onlyLists(nodes []parser.Node) bool {
+	for _, node := range nodes {
+		if _, ok := node.(*parser.List); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// tabs returns the tabs indenting by at least columns, each tabWidth wide
+func tabs(columns, tabWidth int) string {
+	if tabWidth < 1 {
+		tabWidth = config.DefaultTabWidth
+	}
+	return strings.Repeat("\t", (columns+tabWidth-1)/tabWidth)
+}
+
+// leadingBlocks returns the number of blocks at the start of nodes up to
+// the first nested list
+func leadingBlocks(nodes []parser.Node) int {
+	for i, node := range nodes {
+		if _, ok := node.(*parser.List); ok {
+			return i
+		}
+	}
+	return len(nodes)
+}
+
 // renderCodeBlock renders a code block node
 func (r *MarkdownRenderer) renderCodeBlock(code *parser.CodeBlock, _ int) error {
 	if code.Fenced {
-		r.output.WriteString(code.Fence)
-		if code.Language != "" {
+		fence := code.Fence
+		if fence == "" {
+			fence = "```"
+		}
+		r.output.WriteString(fence)
+		if code.Info != "" {
+			r.output.WriteString(code.Info)
+		} else {
 			r.output.WriteString(code.Language)
 		}
 		r.output.WriteString("\n")
 		r.output.WriteString(code.Content)
-		if !strings.HasSuffix(code.Content, "\n") {
+		if code.Content != "" && !strings.HasSuffix(code.Content, "\n") {
 			r.output.WriteString("\n")
 		}
-		r.output.WriteString(code.Fence)
+		r.output.WriteString(fence)
 		r.output.WriteString("\n\n")
 	} else {
 		// Indented code block
-		lines := strings.Split(code.Content, "\n")
+		lines := strings.Split(strings.TrimSuffix(code.Content, "\n"), "\n")
 		for _, line := range lines {
-			r.output.WriteString("    ")
-			r.output.WriteString(line)
+			if line != "" {
+				r.output.WriteString("    ")
+				r.output.WriteString(line)
+			}
 			r.output.WriteString("\n")
 		}
 		r.output.WriteString("\n")
@@ -280,102 +614,278 @@ func (r *MarkdownRenderer) renderCodeBlock(code *parser.CodeBlock, _ int) error
 	return nil
 }
 
-// renderText renders a text node
-func (r *MarkdownRenderer) renderText(text *parser.Text, _ int) error {
-	content := text.Content
+// renderHTMLBlock renders a raw HTML block exactly as written
+func (r *MarkdownRenderer) renderHTMLBlock(html *parser.HTMLBlock, _ int) error {
+	r.output.WriteString(strings.TrimRight(html.Content, "\n"))
+	r.output.WriteString("\n\n")
+	return nil
+}
 
-	// Apply whitespace normalization
-	if r.config.Whitespace.TrimTrailingSpaces {
-		lines := strings.Split(content, "\n")
-		for i, line := range lines {
-			lines[i] = strings.TrimRight(line, " \t")
+// renderMath renders a display math block exactly as written
+func (r *MarkdownRenderer) renderMath(math *parser.Math, _ int) error {
+	r.output.WriteString(strings.TrimRight(math.Content, "\n"))
+	r.output.WriteString("\n\n")
+	return nil
+}
+
+// renderDefinitionList renders terms on their own lines and descriptions
+// as ": " followed by their blocks, indented to line up with the marker
+func (r *MarkdownRenderer) renderDefinitionList(list *parser.DefinitionList, depth int) error {
+	var previous parser.Node
+	for _, child := range list.Children {
+		switch n := child.(type) {
+		case *parser.DefinitionTerm:
+			if _, ok := previous.(*parser.DefinitionDescription); ok {
+				r.output.WriteString("\n")
+			}
+			// Terms following a term continue its paragraph
+			_, follows := previous.(*parser.DefinitionTerm)
+			r.output.WriteString(escapeLine(n.Text, !follows))
+			r.output.WriteString("\n")
+		case *parser.DefinitionDescription:
+			body, err := r.renderBlocksIn("list", n.Children, depth)
+			if err != nil {
+				return err
+			}
+			if !n.Tight {
+				r.output.WriteString("\n")
+			}
+			r.output.WriteString(indentDescription(body))
+			r.output.WriteString("\n")
 		}
-		content = strings.Join(lines, "\n")
+		previous = child
 	}
 
-	r.output.WriteString(content)
+	r.output.WriteString("\n")
 	return nil
 }
 
-// wrapText wraps text to the specified line width, preserving markdown links
-func (r *MarkdownRenderer) wrapText(text string, width int) string {
-	if width <= 0 {
-		return text
+// indentDescription prefixes the first line with the ": " marker and indents
+// the remaining non-blank lines to line up with it
+func indentDescription(body string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = DefinitionMarker + line
+		case line != "":
+			lines[i] = strings.Repeat(" ", len(DefinitionMarker)) + line
+		}
 	}
+	return strings.Join(lines, "\n")
+}
 
-	// Split text into tokens, preserving markdown links as single units
-	tokens := r.tokenizeWithLinks(text)
-	if len(tokens) == 0 {
-		return text
+// renderLinkDefinitions renders link reference definitions, one per line
+func (r *MarkdownRenderer) renderLinkDefinitions(definitions *parser.LinkDefinitions, _ int) error {
+	for _, definition := range definitions.Definitions {
+		r.output.WriteString(definition.Markdown())
+		r.output.WriteString("\n")
 	}
+	r.output.WriteString("\n")
+	return nil
+}
 
-	var lines []string
-	var currentLine strings.Builder
+// renderMDXBlock renders a JSX, ESM or expression region exactly as written
+func (r *MarkdownRenderer) renderMDXBlock(mdx *parser.MDXBlock, _ int) error {
+	r.output.WriteString(strings.TrimRight(mdx.Content, "\n"))
+	r.output.WriteString("\n\n")
+	return nil
+}
+
+// renderDetails renders a details section, keeping the blank lines GitHub
+// requires between the tags and the markdown inside them
+func (r *MarkdownRenderer) renderDetails(details *parser.Details, depth int) error {
+	return r.renderWrapped(details.Open, details.Children, details.Close, depth)
+}
 
-	for i, token := range tokens {
-		// Check if adding this token would exceed the line width
-		if currentLine.Len() > 0 && currentLine.Len()+1+len(token) > width {
-			// Always start new line when width exceeded (for both links and regular words)
-			lines = append(lines, currentLine.String())
-			currentLine.Reset()
+// renderWrapped renders blocks between verbatim opening and closing tags,
+// separated from them by blank lines
+func (r *MarkdownRenderer) renderWrapped(open string, children []parser.Node, closing string, depth int) error {
+	r.output.WriteString(strings.TrimRight(open, "\n"))
+	r.output.WriteString("\n\n")
+
+	for _, child := range children {
+		if err := r.renderNode(child, depth); err != nil {
+			return err
 		}
+	}
 
-		if currentLine.Len() > 0 {
-			currentLine.WriteString(" ")
+	r.output.WriteString(strings.TrimRight(closing, "\n"))
+	r.output.WriteString("\n\n")
+	return nil
+}
+
+// renderBlockquote renders a blockquote, prefixing its blocks with "> "
+func (r *MarkdownRenderer) renderBlockquote(quote *parser.Blockquote, depth int) error {
+	// Without the marker, a blank line ends the quote, so each block is
+	// quoted on its own
+	groups := [][]parser.Node{quote.Children}
+	if r.config.Blockquote.BlankMarker == "strip" && len(quote.Children) > 1 {
+		groups = make([][]parser.Node, len(quote.Children))
+		for i, child := range quote.Children {
+			groups[i] = []parser.Node{child}
 		}
-		currentLine.WriteString(token)
+	}
 
-		// If this is the last token, add the current line
-		if i == len(tokens)-1 {
-			lines = append(lines, currentLine.String())
+	for _, nodes := range groups {
+		body, err := r.renderQuoted(nodes, depth)
+		if err != nil {
+			return err
 		}
+		r.output.WriteString(body)
+		r.output.WriteString("\n\n")
 	}
+	return nil
+}
 
-	return strings.Join(lines, "\n")
+// renderAdmonition renders a GitHub alert with its marker on its own line
+func (r *MarkdownRenderer) renderAdmonition(admonition *parser.Admonition, depth int) error {
+	body, err := r.renderQuoted(admonition.Children, depth)
+	if err != nil {
+		return err
+	}
+
+	r.output.WriteString("> [!")
+	r.output.WriteString(admonition.Tag)
+	r.output.WriteString("]\n")
+	if body != "" {
+		r.output.WriteString(body)
+		r.output.WriteString("\n")
+	}
+	r.output.WriteString("\n")
+	return nil
 }
 
-// tokenizeWithLinks splits text into words while keeping markdown links intact
-func (r *MarkdownRenderer) tokenizeWithLinks(text string) []string {
-	// Simple regex-based approach to find markdown links
-	linkPattern := `\[[^\]]*\]\([^)]*\)`
-	re := regexp.MustCompile(linkPattern)
+// renderBlocks renders nested blocks with a separate renderer and returns
+// the result without trailing newlines
+func (r *MarkdownRenderer) renderBlocks(nodes []parser.Node, depth int) (string, error) {
+	nested := &MarkdownRenderer{config: r.config, output: getBuffer(), block: r.block}
+	defer putBuffer(nested.output)
+	for _, node := range nodes {
+		if err := nested.renderNode(node, depth); err != nil {
+			return "", err
+		}
+	}
+
+	body := nested.normalizeBlankLines(nested.output.String(), r.config.Whitespace.MaxBlankLines)
+	return strings.TrimRight(body, "\n"), nil
+}
 
-	var tokens []string
-	lastEnd := 0
+// renderBlocksIn renders nodes like renderBlocks, as the blocks of a
+// container of kind block
+func (r *MarkdownRenderer) renderBlocksIn(block string, nodes []parser.Node, depth int) (string, error) {
+	outer := r.block
+	r.block = block
+	defer func() { r.block = outer }()
+	return r.renderBlocks(nodes, depth)
+}
 
-	// Find all links and process text between them
-	matches := re.FindAllStringIndex(text, -1)
+// renderQuoted renders nested blocks as quoted lines, wrapping paragraphs
+// so the quoted lines fit the line width
+func (r *MarkdownRenderer) renderQuoted(nodes []parser.Node, depth int) (string, error) {
+	marker := "> "
+	if r.config.Blockquote.MarkerSpacing == "none" {
+		marker = ">"
+	}
 
-	for _, match := range matches {
-		start, end := match[0], match[1]
+	cfg := *r.config
+	if cfg.LineWidth > 0 {
+		cfg.LineWidth = max(cfg.LineWidth-len(marker), 1)
+	}
+	quoted := &MarkdownRenderer{config: &cfg, block: "blockquote"}
+	body, err := quoted.renderBlocks(nodes, depth)
+	if err != nil {
+		return "", err
+	}
+	return quoteLines(body, marker), nil
+}
 
-		// Add words before the link
-		if start > lastEnd {
-			beforeLink := text[lastEnd:start]
-			words := strings.Fields(beforeLink)
-			tokens = append(tokens, words...)
+// quoteLines prefixes every line with a blockquote marker. Blank lines get
+// a bare ">" to keep the blocks in one quote, and indented lines a space
+// after it, which the marker absorbs, to keep their indentation.
+func quoteLines(text, marker string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.TrimSpace(line) == "":
+			lines[i] = ">"
+		case line[0] == ' ' || line[0] == '\t':
+			lines[i] = "> " + line
+		default:
+			lines[i] = marker + line
 		}
+	}
+	return strings.Join(lines, "\n")
+}
 
-		// Add the link as a single token (e.g. [link](url))
-		link := text[start:end]
-		tokens = append(tokens, link)
+// renderText renders a text node
+func (r *MarkdownRenderer) renderText(text *parser.Text, _ int) error {
+	r.output.WriteString(text.Content)
+	return nil
+}
 
-		lastEnd = end
+// wrapText wraps text to the specified line width, preserving markdown
+// links and hard breaks
+func (r *MarkdownRenderer) wrapText(text string, width int) string {
+	if width <= 0 {
+		return text
 	}
 
-	// Add remaining words after the last link
-	if lastEnd < len(text) {
-		afterLinks := text[lastEnd:]
-		words := strings.Fields(afterLinks)
-		tokens = append(tokens, words...)
+	parts := parser.SplitHardBreaks(text)
+	for i, part := range parts {
+		parts[i] = r.wrapPart(part, width, i == 0)
 	}
+	return strings.Join(parts, parser.HardBreak)
+}
 
-	// If no links found, just split into words
-	if len(matches) == 0 {
-		tokens = strings.Fields(text)
+// wrapPart wraps text without hard breaks to the specified line width,
+// where first tells whether it starts the paragraph. Lines of template
+// tags stay on their own.
+func (r *MarkdownRenderer) wrapPart(text string, width int, first bool) string {
+	if pieces := r.inlineSyntax().SplitTagLines(text); len(pieces) > 1 {
+		for i, piece := range pieces {
+			pieces[i] = r.wrapPart(piece, width, first && i == 0)
+		}
+		return strings.Join(pieces, "\n")
+	}
+
+	// Split text into tokens, preserving markdown links as single units
+	tokens := r.tokenizeWithLinks(text)
+	if len(tokens) == 0 {
+		return text
 	}
 
-	return tokens
+	// Lines are measured as escaped, so output wraps the same when formatted again
+	var lines []string
+	currentLine := tokens[0]
+	for _, token := range tokens[1:] {
+		candidate := currentLine + " " + token
+		if len(escapeLine(candidate, first && len(lines) == 0)) > width {
+			lines = append(lines, currentLine)
+			currentLine = token
+			continue
+		}
+		currentLine = candidate
+	}
+	lines = append(lines, currentLine)
+
+	return strings.Join(lines, "\n")
+}
+
+// tokenizeWithLinks splits text into words while keeping markdown links,
+// code spans, math and template tags intact
+func (r *MarkdownRenderer) tokenizeWithLinks(text string) []string {
+	return r.inlineSyntax().WrapTokens(text)
+}
+
+// inlineSyntax returns the atomic inline constructs of the configuration,
+// including its template tags
+func (r *MarkdownRenderer) inlineSyntax() *parser.InlineSyntax {
+	syntax := parser.TemplateSyntax(r.config.TemplateDelimiters)
+	if r.config.Whitespace.Tabs == "preserve" {
+		return syntax.KeepingTabs()
+	}
+	return syntax
 }
 
 // normalizeBlankLines limits consecutive blank lines to the configured maximum
@@ -387,11 +897,16 @@ func (r *MarkdownRenderer) normalizeBlankLines(text string, maxBlankLines int) s
 	lines := strings.Split(text, "\n")
 	var result []string
 	consecutiveEmpty := 0
+	var verbatim verbatimLines
 
 	for _, line := range lines {
-		isEmpty := strings.TrimSpace(line) == ""
+		isEmpty := strings.Trim(line, " \t") == ""
 
-		if isEmpty {
+		if verbatim.next(line) {
+			// Blank lines of code and raw HTML are content
+			consecutiveEmpty = 0
+			result = append(result, line)
+		} else if isEmpty {
 			consecutiveEmpty++
 			// Only add empty line if we haven't exceeded the limit
 			if consecutiveEmpty <= maxBlankLines {
@@ -405,3 +920,67 @@ func (r *MarkdownRenderer) normalizeBlankLines(text string, maxBlankLines int) s
 
 	return strings.Join(result, "\n")
 }
+
+// rawHTMLEnds maps the start of raw HTML blocks that may contain blank
+// lines to the text that ends them
+var rawHTMLEnds = map[string]string{
+	"<!--":      "-->",
+	"<pre":      "</pre>",
+	"<script":   "</script>",
+	"<style":    "</style>",
+	"<textarea": "</textarea>",
+}
+
+// verbatimLines follows rendered output line by line to tell which lines
+// are inside fenced code blocks or raw HTML, where blank lines are content
+type verbatimLines struct {
+	// fence is the opening fence of the current fenced code block
+	fence string
+	// htmlEnd ends the current raw HTML block
+	htmlEnd string
+}
+
+// next reports whether line is inside a fenced code block or raw HTML
+// block, not counting the lines that open them
+func (v *verbatimLines) next(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	switch {
+	case v.fence != "":
+		if fence := fenceOf(trimmed); fence != "" && fence[0] == v.fence[0] && len(fence) >= len(v.fence) &&
+			strings.TrimSpace(trimmed[len(fence):]) == "" {
+			v.fence = ""
+			return false
+		}
+		return true
+	case v.htmlEnd != "":
+		if strings.Contains(strings.ToLower(line), v.htmlEnd) {
+			v.htmlEnd = ""
+		}
+		return true
+	}
+
+	if fence := fenceOf(trimmed); fence != "" && (fence[0] == '~' || opensFence(trimmed)) {
+		v.fence = fence
+		return false
+	}
+	lower := strings.ToLower(trimmed)
+	for start, end := range rawHTMLEnds {
+		if strings.HasPrefix(lower, start) && !strings.Contains(lower[len(start):], end) {
+			v.htmlEnd = end
+			break
+		}
+	}
+	return false
+}
+
+// fenceOf returns the code fence line starts with, or ""
+func fenceOf(line string) string {
+	if len(line) < 3 || (line[0] != '`' && line[0] != '~') {
+		return ""
+	}
+	n := len(line) - len(strings.TrimLeft(line, line[:1]))
+	if n < 3 {
+		return ""
+	}
+	return line[:n]
+}
@@ -0,0 +1,411 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// benchmarkDocument is a document exercising the common block types
+var benchmarkDocument = strings.Repeat("# Heading\n\n"+
+	"Some paragraph text with a [link](https://example.com) and *emphasis* that is long enough to be wrapped "+
+	"at the configured line width.\n\n"+
+	"- one\n- two\n  - nested\n\n"+
+	"> A quote with **strong** text\n\n"+
+	"```go\nfmt.Println(\"hello\")\n```\n\n", 20)
+
+func TestRenderReusesRenderer(t *testing.T) {
+	cfg := config.Default()
+	r := New()
+	var first string
+	for i := 0; i < 2; i++ {
+		doc, err := parser.New().Parse([]byte(benchmarkDocument))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		output, err := r.Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if i == 0 {
+			first = output
+		} else if output != first {
+			t.Error("Expected the same output from a reused renderer")
+		}
+	}
+}
+
+func TestRenderListItemContinuation(t *testing.T) {
+	doc := &parser.Document{Children: []parser.Node{
+		&parser.List{Ordered: true, Items: []*parser.ListItem{{
+			Marker:   "10.",
+			Text:     "Install\nthe tool.",
+			NoReflow: true,
+			Children: []parser.Node{
+				&parser.Paragraph{Text: "Then run it:"},
+				&parser.CodeBlock{Content: "mdfmt --write .\n", Fenced: true, Fence: "```"},
+				&parser.List{Items: []*parser.ListItem{{Marker: "-", Text: "nested"}}},
+			},
+		}, {
+			Marker:   "11.",
+			Children: []parser.Node{&parser.Blockquote{Children: []parser.Node{&parser.Paragraph{Text: "quoted"}}}},
+		}}},
+	}}
+
+	output, err := New().Render(doc, config.Default())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := "10. Install\n    the tool.\n\n    Then run it:\n\n    ```\n    mdfmt --write .\n    ```\n\n" +
+		"    - nested\n\n11. > quoted\n"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestRenderNestedEmptyItems(t *testing.T) {
+	nested := func(children ...parser.Node) *parser.List {
+		return &parser.List{Items: []*parser.ListItem{{Marker: "-", Children: children}}}
+	}
+	tests := []struct {
+		name     string
+		list     *parser.List
+		expected string
+	}{
+		{"marker line", nested(nested()), "- -\n"},
+		{"thematic break", nested(nested(nested())), "-\n  - -\n"},
+	}
+	for _, tt := range tests {
+		output, err := New().Render(&parser.Document{Children: []parser.Node{tt.list}}, config.Default())
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if output != tt.expected {
+			t.Errorf("Expected %s:\n%s\ngot:\n%s", tt.name, tt.expected, output)
+		}
+	}
+}
+
+func TestRenderBlockquoteWidth(t *testing.T) {
+	doc := &parser.Document{Children: []parser.Node{
+		&parser.Blockquote{Children: []parser.Node{
+			&parser.Paragraph{Text: "one two three four"},
+			&parser.CodeBlock{Content: "code"},
+			&parser.Blockquote{Children: []parser.Node{&parser.Paragraph{Text: "five six"}}},
+		}},
+	}}
+	cfg := config.Default()
+	cfg.LineWidth = 10
+
+	tests := []struct {
+		spacing  string
+		expected string
+	}{
+		{"space", "> one two\n> three\n> four\n>\n>     code\n>\n> > five\n> > six\n"},
+		{"none", ">one two\n>three\n>four\n>\n>     code\n>\n>>five six\n"},
+	}
+	for _, tt := range tests {
+		cfg.Blockquote.MarkerSpacing = tt.spacing
+		output, err := New().Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if output != tt.expected {
+			t.Errorf("Expected with %s spacing:\n%s\ngot:\n%s", tt.spacing, tt.expected, output)
+		}
+	}
+}
+
+func TestRenderBlockquoteBlankMarker(t *testing.T) {
+	input := "> one\n>\n> ```\n> code\n>\n> more\n> ```\n>\n> - x\n>\n> - y\n\n> [!NOTE]\n> two\n>\n> three\n"
+
+	tests := []struct {
+		marker   string
+		expected string
+	}{
+		{"keep", input},
+		{"strip", "> one\n\n> ```\n> code\n>\n> more\n> ```\n\n> - x\n>\n> - y\n\n> [!NOTE]\n> two\n>\n> three\n"},
+	}
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.Blockquote.BlankMarker = tt.marker
+		for _, source := range []string{input, tt.expected} {
+			doc, err := parser.New().Parse([]byte(source))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			output, err := New().Render(doc, cfg)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if output != tt.expected {
+				t.Errorf("Expected with blank marker %s:\n%s\ngot:\n%s", tt.marker, tt.expected, output)
+			}
+		}
+	}
+}
+
+func TestRenderNoReflow(t *testing.T) {
+	doc := &parser.Document{Children: []parser.Node{
+		&parser.Paragraph{Text: "one two\nthree four"},
+		&parser.Paragraph{Text: "one two\nthree four", NoReflow: true},
+	}}
+	cfg := config.Default()
+	cfg.LineWidth = 40
+
+	output, err := New().Render(doc, cfg)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := "one two three four\n\none two\nthree four\n"; output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestRenderWrapBlocks(t *testing.T) {
+	doc := &parser.Document{Children: []parser.Node{
+		&parser.Paragraph{Text: "one two three"},
+		&parser.Blockquote{Children: []parser.Node{&parser.Paragraph{Text: "four five six"}}},
+		&parser.List{Items: []*parser.ListItem{{
+			Marker:   "-",
+			Text:     "item",
+			Children: []parser.Node{&parser.Paragraph{Text: "seven eight nine"}},
+		}}},
+		&parser.Table{Alignments: []string{""}, Rows: [][]string{{"ten eleven twelve"}}},
+	}}
+	cfg := config.Default()
+	cfg.LineWidth = 10
+
+	tests := []struct {
+		wrap     []string
+		expected string
+	}{
+		{[]string{"paragraph", "blockquote", "list"}, "one two\nthree\n\n> four\n> five six\n\n" +
+			"- item\n\n  seven\n  eight\n  nine\n\n| ten eleven twelve |\n| ----------------- |\n"},
+		{[]string{"list"}, "one two three\n\n> four five six\n\n" +
+			"- item\n\n  seven\n  eight\n  nine\n\n| ten eleven twelve |\n| ----------------- |\n"},
+	}
+	for _, tt := range tests {
+		cfg.Reflow.Wrap = tt.wrap
+		output, err := New().Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if output != tt.expected {
+			t.Errorf("Expected with wrap %v:\n%s\ngot:\n%s", tt.wrap, tt.expected, output)
+		}
+	}
+}
+
+func TestRenderListItemWrap(t *testing.T) {
+	input := "- The first paragraph of this list item is long enough to wrap at the default width.\n" +
+		"  1. A nested item that is also long enough to be wrapped at narrower widths.\n"
+
+	for _, width := range []int{80, 30} {
+		cfg := config.Default()
+		cfg.LineWidth = width
+		doc, err := parser.New().Parse([]byte(input))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		output, err := New().Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+		if len(lines) < 3 {
+			t.Errorf("Expected the items wrapped at width %d, got:\n%s", width, output)
+		}
+		for _, line := range lines {
+			if len(line) > width {
+				t.Errorf("Line longer than %d: %q", width, line)
+			}
+		}
+
+		doc, err = parser.New().Parse([]byte(output))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		again, err := New().Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if again != output {
+			t.Errorf("Rendering at width %d is not idempotent:\n%s\nthen:\n%s", width, output, again)
+		}
+	}
+}
+
+func TestRenderTrailingSpaces(t *testing.T) {
+	doc := &parser.Document{Children: []parser.Node{
+		&parser.Paragraph{Text: "one two three\\\nfour"},
+		&parser.Paragraph{Text: "[link\\\ntext](url)"},
+		&parser.Text{Content: "text  \n\n"},
+		&parser.Blockquote{Children: []parser.Node{
+			&parser.CodeBlock{Content: "quoted  \n", Fenced: true, Fence: "```"},
+		}},
+		&parser.CodeBlock{Content: "code  \n", Fenced: true, Fence: "```"},
+		&parser.CodeBlock{Content: "indented  \n"},
+		&parser.HTMLBlock{Content: "<pre>\nhtml  \n</pre>"},
+	}}
+	code := "> ```\n> quoted  \n> ```\n\n```\ncode  \n```\n\n    indented  \n\n<pre>\nhtml  \n</pre>\n"
+
+	tests := []struct {
+		trim     bool
+		style    string
+		expected string
+	}{
+		{true, "backslash", "one two\nthree\\\nfour\n\n[link\\\ntext](url)\n\ntext\n\n" + code},
+		{true, "spaces", "one two\nthree  \nfour\n\n[link  \ntext](url)\n\ntext\n\n" + code},
+		{false, "backslash", "one two\nthree\\\nfour\n\n[link\\\ntext](url)\n\ntext  \n\n" + code},
+		{false, "spaces", "one two\nthree  \nfour\n\n[link  \ntext](url)\n\ntext  \n\n" + code},
+	}
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.LineWidth = 8
+		cfg.Whitespace.TrimTrailingSpaces = tt.trim
+		cfg.Whitespace.HardBreakStyle = tt.style
+		output, err := New().Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if output != tt.expected {
+			t.Errorf("Expected with trim %t and %s breaks:\n%q\ngot:\n%q", tt.trim, tt.style, tt.expected, output)
+		}
+	}
+}
+
+func TestRenderWithoutLineWidth(t *testing.T) {
+	doc := &parser.Document{Children: []parser.Node{
+		&parser.Paragraph{Text: "one two\nthree four"},
+		&parser.Blockquote{Children: []parser.Node{&parser.Paragraph{Text: "five six\nseven"}}},
+	}}
+	cfg := config.Default()
+	cfg.LineWidth = 0
+
+	output, err := New().Render(doc, cfg)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := "one two\nthree four\n\n> five six\n> seven\n"; output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestRenderLooseList(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"loose", "- one\n\n- two\n\n- three\n"},
+		{"tight", "- one\n- two\n- three\n"},
+		{"loose with nested tight", "1. one\n\n2. two\n   - nested\n   - tight\n\n3. three\n"},
+		{"tight with nested loose", "- one\n  - nested\n\n  - loose\n- two\n"},
+		{"loose with blocks", "- one\n\n  more\n\n- two\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.New().Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			output, err := New().Render(doc, config.Default())
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if output != tt.input {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.input, output)
+			}
+		})
+	}
+}
+
+func TestRenderTaskList(t *testing.T) {
+	input := "- [ ] todo\n- [X] done, with text long enough to wrap after the checkbox\n- plain\n  1. [x] nested\n"
+	expected := "- [ ] todo\n- [x] done, with text long enough to\n  wrap after the checkbox\n- plain\n  1. [x] nested\n"
+	cfg := config.Default()
+	cfg.LineWidth = 40
+
+	for _, source := range []string{input, expected} {
+		doc, err := parser.New().Parse([]byte(source))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		output, err := New().Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if output != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+		}
+	}
+}
+
+func TestRenderTabIndentedList(t *testing.T) {
+	nested := &parser.List{TabIndented: true, Items: []*parser.ListItem{{Marker: "-", Text: "nested"}}}
+	doc := &parser.Document{Children: []parser.Node{
+		&parser.List{Ordered: true, Items: []*parser.ListItem{{Marker: "1.", Text: "one", Children: []parser.Node{nested}}}},
+	}}
+	cfg := config.Default()
+
+	tests := []struct {
+		tabs     string
+		tabWidth int
+		expected string
+	}{
+		{"spaces", 4, "1. one\n   - nested\n"},
+		{"preserve", 4, "1. one\n\t- nested\n"},
+		{"preserve", 2, "1. one\n\t\t- nested\n"},
+	}
+	for _, tt := range tests {
+		cfg.Whitespace.Tabs, cfg.Whitespace.TabWidth = tt.tabs, tt.tabWidth
+		output, err := New().Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if !strings.HasPrefix(output, tt.expected) {
+			t.Errorf("Expected with tabs %s at width %d:\n%q\ngot:\n%q", tt.tabs, tt.tabWidth, tt.expected, output)
+		}
+	}
+}
+
+func BenchmarkMarkdownRenderer_Render(b *testing.B) {
+	cfg := config.Default()
+	doc, err := parser.New().Parse([]byte(benchmarkDocument))
+	if err != nil {
+		b.Fatalf("Parse failed: %v", err)
+	}
+	r := New()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkDocument)))
+	for i := 0; i < b.N; i++ {
+		if _, err := r.Render(doc, cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func FuzzRender(f *testing.F) {
+	f.Add([]byte(benchmarkDocument))
+	f.Add([]byte("Some text that is wrapped at # signs and 1. numbers > quotes\n\n- a\n\t- b\n\n> - c\n"))
+	p := parser.New()
+	cfg := config.Default()
+	cfg.LineWidth = 20
+	f.Fuzz(func(t *testing.T, data []byte) {
+		doc, err := p.Parse(data)
+		if err != nil {
+			return
+		}
+		output, err := New().Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if _, err := p.Parse([]byte(output)); err != nil {
+			t.Fatalf("Rendered output does not parse: %v\n%s", err, output)
+		}
+	})
+}
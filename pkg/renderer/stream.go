@@ -0,0 +1,344 @@
+package renderer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// BlockWriter writes rendered top-level blocks to a writer, applying the
+// document-level whitespace rules as they stream through: trailing
+// whitespace is trimmed and blank lines limited first, then line endings
+// converted.
+type BlockWriter struct {
+	out    *bufio.Writer
+	eol    io.Writer
+	blanks *blankLineWriter
+	cfg    *config.Config
+	// finalNewline ends the output with a line break even when
+	// whitespace.ensure_final_newline is off
+	finalNewline bool
+}
+
+// NewBlockWriter returns a BlockWriter writing to w with the whitespace
+// rules of cfg
+func NewBlockWriter(w io.Writer, cfg *config.Config) *BlockWriter {
+	out := bufio.NewWriter(w)
+	eol := newLineEndingWriter(out, cfg.Whitespace.EndOfLine)
+	blanks := newBlankLineWriter(eol, cfg.Whitespace.MaxBlankLines)
+	blanks.trim = cfg.Whitespace.TrimTrailingSpaces
+	blanks.keepBreaks = cfg.Whitespace.HardBreakStyle == "spaces"
+	return &BlockWriter{
+		out:    out,
+		eol:    eol,
+		blanks: blanks,
+		cfg:    cfg,
+		// Output without a source ends like most sources
+		finalNewline: true,
+	}
+}
+
+// SetFinalNewline sets whether the output ends with a line break when
+// whitespace.ensure_final_newline is off, which is whether its source does.
+// With the option on, it always ends with exactly one.
+func (b *BlockWriter) SetFinalNewline(finalNewline bool) {
+	b.finalNewline = finalNewline
+}
+
+// WriteFrontMatter writes the front matter of the document, separated from
+// the blocks that follow it when hasBlocks is set. Its trailing whitespace
+// is kept, since YAML block scalars hold it as content.
+func (b *BlockWriter) WriteFrontMatter(frontMatter string, hasBlocks bool) error {
+	text := strings.ReplaceAll(frontMatter, "\r\n", "\n")
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if hasBlocks {
+		text += "\n"
+	}
+	trim := b.blanks.trim
+	b.blanks.trim = false
+	defer func() { b.blanks.trim = trim }()
+	_, err := io.WriteString(b.blanks, text)
+	return err
+}
+
+// Write writes rendered blocks
+func (b *BlockWriter) Write(p []byte) (int, error) {
+	return b.blanks.Write(p)
+}
+
+// Close writes the end of the document and flushes it to the writer
+func (b *BlockWriter) Close() error {
+	if err := b.blanks.Close(b.cfg.Whitespace.EnsureFinalNewline || b.finalNewline); err != nil {
+		return err
+	}
+	if eol, ok := b.eol.(*lineEndingWriter); ok {
+		if err := eol.Flush(); err != nil {
+			return err
+		}
+	}
+	return b.out.Flush()
+}
+
+// blankLineWriter limits runs of blank lines to max and trims trailing
+// whitespace as rendered blocks are streamed through it, holding back only
+// the current line, the trailing whitespace of the last and the blank lines
+// before it. It follows normalizeBlankLines: lines are joined with "\n" and
+// the text after the last "\n" counts as a line of its own, but the blank
+// lines ending the output are dropped.
+type blankLineWriter struct {
+	w io.Writer
+	// max is the longest run of blank lines kept; negative keeps all
+	max int
+	// trim removes the trailing whitespace of lines outside code and raw HTML
+	trim bool
+	// keepBreaks keeps the two spaces ending a line with a hard break
+	keepBreaks bool
+	// code follows the fenced code and raw HTML blocks, within blockquotes
+	// too, whose trailing whitespace is kept
+	code verbatimLines
+	// indented is set within an indented code block
+	indented bool
+	// afterBlank is set when the last line was blank
+	afterBlank bool
+	// pendingBreak holds the spaces ending the last line, written only if
+	// the next line continues its paragraph and makes them a hard break
+	pendingBreak []byte
+	// line is the current line, not yet terminated
+	line []byte
+	// blanks counts the consecutive blank lines seen
+	blanks int
+	// heldBlanks holds the blank lines since the last line written, with
+	// the line breaks before them, written once another line follows
+	heldBlanks []byte
+	// verbatim follows the code and raw HTML blocks, whose blank lines are kept
+	verbatim verbatimLines
+	// started is set once a line was seen
+	started bool
+	// endsWithNewline reports whether the output written ends with "\n"
+	endsWithNewline bool
+	err             error
+}
+
+// newBlankLineWriter returns a blankLineWriter writing to w
+func newBlankLineWriter(w io.Writer, max int) *blankLineWriter {
+	return &blankLineWriter{w: w, max: max}
+}
+
+// Write writes each complete line of p and holds back the rest
+func (b *blankLineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for b.err == nil {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			b.line = append(b.line, p...)
+			break
+		}
+		b.line = append(b.line, p[:i]...)
+		b.writeLine()
+		p = p[i+1:]
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return n, nil
+}
+
+// Close writes the last line, drops the blank lines ending the output and,
+// with finalNewline, ends it with exactly one "\n"
+func (b *blankLineWriter) Close(finalNewline bool) error {
+	b.writeLine()
+	b.heldBlanks = b.heldBlanks[:0]
+	if finalNewline && !b.endsWithNewline {
+		b.write([]byte("\n"))
+	}
+	return b.err
+}
+
+// writeLine writes the current line unless it exceeds the blank line limit.
+// Blank lines outside code are held back until another line follows them.
+func (b *blankLineWriter) writeLine() {
+	line := b.line
+	b.line = b.line[:0]
+
+	blank := len(bytes.Trim(line, " \t")) == 0
+	code := b.isCode(line, blank)
+	if b.verbatim.next(string(line)) {
+		b.blanks = 0
+	} else if blank {
+		b.blanks++
+		b.pendingBreak = b.pendingBreak[:0]
+		if b.max < 0 || b.blanks <= b.max {
+			b.holdBlank(line, code)
+		}
+		return
+	} else {
+		b.blanks = 0
+	}
+
+	b.write(b.pendingBreak)
+	b.write(b.heldBlanks)
+	b.heldBlanks = b.heldBlanks[:0]
+	if b.started {
+		b.write([]byte("\n"))
+	}
+	b.started = true
+	b.pendingBreak = b.pendingBreak[:0]
+	if b.trim && !code {
+		content := bytes.TrimRight(line, " \t")
+		if space := line[len(content):]; b.keepBreaks && len(content) > 0 && isSpaceBreak(space) {
+			b.pendingBreak = append(b.pendingBreak, space...)
+		}
+		line = content
+		if isEmptyDescription(content) {
+			// The marker of an empty description needs the space after it
+			line = append(content, ' ')
+		}
+	}
+	b.write(line)
+}
+
+// isEmptyDescription reports whether line, without trailing whitespace,
+// is the marker of a definition description without blocks. Colons
+// starting other lines of text are escaped.
+func isEmptyDescription(line []byte) bool {
+	return bytes.Equal(bytes.TrimLeft(line, " >"), []byte(strings.TrimSpace(DefinitionMarker)))
+}
+
+// holdBlank holds back a blank line and the line break before it
+func (b *blankLineWriter) holdBlank(line []byte, code bool) {
+	if b.started {
+		b.heldBlanks = append(b.heldBlanks, '\n')
+	}
+	b.started = true
+	if !b.trim || code {
+		b.heldBlanks = append(b.heldBlanks, line...)
+	}
+}
+
+// isCode reports whether line is code or raw HTML, whose trailing
+// whitespace is kept. Indented code starts after a blank line, as it
+// cannot interrupt a paragraph.
+func (b *blankLineWriter) isCode(line []byte, blank bool) bool {
+	unquoted := quotePrefixPattern.ReplaceAll(line, nil)
+	afterBlank := b.afterBlank || !b.started
+	b.afterBlank = blank
+	if b.code.fence == "" && b.code.htmlEnd == "" && !blank {
+		indented := bytes.HasPrefix(unquoted, []byte("\t")) || bytes.HasPrefix(unquoted, []byte("    "))
+		b.indented = indented && (b.indented || afterBlank)
+		if b.indented {
+			return true
+		}
+	}
+	return b.code.next(string(unquoted)) || (blank && b.indented)
+}
+
+// isSpaceBreak reports whether the trailing whitespace of a line makes a
+// hard break: two or more spaces
+func isSpaceBreak(space []byte) bool {
+	return len(space) >= 2 && len(bytes.Trim(space, " ")) == 0
+}
+
+// write writes p, remembering the first error
+func (b *blankLineWriter) write(p []byte) {
+	if b.err != nil || len(p) == 0 {
+		return
+	}
+	if _, b.err = b.w.Write(p); b.err == nil {
+		b.endsWithNewline = p[len(p)-1] == '\n'
+	}
+}
+
+// lineEndingWriter replaces the "\n" and "\r\n" line endings written to it
+// with newline, holding back a trailing "\r" until the next byte is known
+type lineEndingWriter struct {
+	w       io.Writer
+	newline []byte
+	// pendingCR is set when the last byte written was a held back "\r"
+	pendingCR bool
+	buf       []byte
+}
+
+// newLineEndingWriter returns a writer converting line endings to those of
+// endOfLine ("crlf" or "cr"). Other values leave the output unchanged and
+// return w itself.
+func newLineEndingWriter(w io.Writer, endOfLine string) io.Writer {
+	switch endOfLine {
+	case "crlf":
+		return &lineEndingWriter{w: w, newline: []byte("\r\n")}
+	case "cr":
+		return &lineEndingWriter{w: w, newline: []byte("\r")}
+	default:
+		return w
+	}
+}
+
+// Write converts the line endings of p
+func (l *lineEndingWriter) Write(p []byte) (int, error) {
+	l.buf = l.buf[:0]
+	for _, c := range p {
+		switch {
+		case c == '\n':
+			l.buf = append(l.buf, l.newline...)
+			l.pendingCR = false
+			continue
+		case l.pendingCR:
+			l.buf = append(l.buf, '\r')
+			l.pendingCR = false
+		}
+		if c == '\r' {
+			l.pendingCR = true
+		} else {
+			l.buf = append(l.buf, c)
+		}
+	}
+	if _, err := l.w.Write(l.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes a held back "\r"
+func (l *lineEndingWriter) Flush() error {
+	if !l.pendingCR {
+		return nil
+	}
+	l.pendingCR = false
+	_, err := l.w.Write([]byte("\r"))
+	return err
+}
+
+// RenderConcurrent renders doc to w like RenderToContext, rendering runs of
+// top-level blocks on up to workers goroutines and writing them in order
+func RenderConcurrent(ctx context.Context, w io.Writer, doc *parser.Document, cfg *config.Config, workers int) error {
+	runs := parser.SplitBlocks(doc.Children, workers)
+	outputs := make([]string, len(runs))
+	err := parser.RunConcurrently(len(runs), workers, func(i int) error {
+		output, err := New().RenderSection(ctx, &parser.Document{Children: runs[i]}, cfg)
+		outputs[i] = output
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	blocks := NewBlockWriter(w, cfg)
+	blocks.SetFinalNewline(!doc.NoFinalNewline)
+	if doc.FrontMatter != "" {
+		if err := blocks.WriteFrontMatter(doc.FrontMatter, len(doc.Children) > 0); err != nil {
+			return err
+		}
+	}
+	for _, output := range outputs {
+		if _, err := io.WriteString(blocks, output); err != nil {
+			return err
+		}
+	}
+	return blocks.Close()
+}
@@ -0,0 +1,177 @@
+package renderer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestBlankLineWriter(t *testing.T) {
+	inputs := []string{
+		"",
+		"\n",
+		"a\n\n\n\nb\n",
+		"a\n \n\t\n\nb",
+		"\n\n\na\n\n\n",
+		"a\r\n\r\n\r\n\r\nb\r\n",
+		"a\n\n\n````go\nx\n\n\n```\n\n\ny\n````\n\n\nb\n",
+		"  ~~~\n\n\n  ~~~\n\n\n<!--\n\n\n-->\n\n\nb",
+	}
+	r := New()
+	for _, input := range inputs {
+		for _, maxBlank := range []int{-1, 0, 1, 2} {
+			// Write a byte at a time so lines span writes
+			var out bytes.Buffer
+			w := newBlankLineWriter(&out, maxBlank)
+			for i := 0; i < len(input); i++ {
+				if _, err := w.Write([]byte{input[i]}); err != nil {
+					t.Fatalf("Write failed: %v", err)
+				}
+			}
+			if err := w.Close(false); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+			// Blank lines ending the output are dropped
+			expected := strings.TrimRight(r.normalizeBlankLines(input, maxBlank), "\n")
+			if out.String() != expected {
+				t.Errorf("normalizing %q to %d blank lines: expected %q, got %q", input, maxBlank, expected, out.String())
+			}
+		}
+	}
+}
+
+func TestNormalizeBlankLinesKeepsVerbatimBlocks(t *testing.T) {
+	input := "a\n\n\n```\ncode\n\n\n\nend\n```\n\n\n<pre>\n\n\n</pre>\n\n\nb\n"
+	expected := "a\n\n```\ncode\n\n\n\nend\n```\n\n<pre>\n\n\n</pre>\n\nb\n"
+	if got := New().normalizeBlankLines(input, 1); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestBlockWriterTrailingSpaces(t *testing.T) {
+	cfg := config.Default()
+	cfg.Whitespace.HardBreakStyle = "spaces"
+	var out bytes.Buffer
+	w := NewBlockWriter(&out, cfg)
+	if err := w.WriteFrontMatter("---\nnote: |\n  kept  \n---", true); err != nil {
+		t.Fatalf("WriteFrontMatter failed: %v", err)
+	}
+	w.Write([]byte("break  \nline \t\nend  \n\n> quoted  \n> line  \n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	expected := "---\nnote: |\n  kept  \n---\n\nbreak  \nline\nend\n\n> quoted  \n> line\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestBlockWriterFinalNewline(t *testing.T) {
+	tests := []struct {
+		ensure, finalNewline bool
+		expected             string
+	}{
+		{true, true, "a\n"},
+		{true, false, "a\n"},
+		{false, true, "a\n"},
+		{false, false, "a"},
+	}
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.Whitespace.EnsureFinalNewline = tt.ensure
+		var out bytes.Buffer
+		w := NewBlockWriter(&out, cfg)
+		w.SetFinalNewline(tt.finalNewline)
+		w.Write([]byte("a\n\n\n"))
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("Expected %q with ensure_final_newline %t and a source ending %t, got %q",
+				tt.expected, tt.ensure, tt.finalNewline, out.String())
+		}
+	}
+}
+
+func TestBlankLineWriterFinalNewline(t *testing.T) {
+	for input, expected := range map[string]string{"": "\n", "a": "a\n", "a\n": "a\n", "a\n\n\n": "a\n"} {
+		var out bytes.Buffer
+		w := newBlankLineWriter(&out, 1)
+		w.Write([]byte(input))
+		if err := w.Close(true); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if out.String() != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, input, out.String())
+		}
+	}
+}
+
+func TestLineEndingWriter(t *testing.T) {
+	tests := []struct {
+		endOfLine, input, expected string
+	}{
+		{"crlf", "a\nb\r\nc\r", "a\r\nb\r\nc\r"},
+		{"cr", "a\nb\r\nc\rd", "a\rb\rc\rd"},
+		{"lf", "a\r\nb\n", "a\r\nb\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		w := newLineEndingWriter(&out, tt.endOfLine)
+		// Split "\r\n" across writes
+		for _, part := range strings.SplitAfter(tt.input, "\r") {
+			if _, err := w.Write([]byte(part)); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+		}
+		if flusher, ok := w.(*lineEndingWriter); ok {
+			if err := flusher.Flush(); err != nil {
+				t.Fatalf("Flush failed: %v", err)
+			}
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.endOfLine, tt.expected, out.String())
+		}
+	}
+}
+
+// countingWriter counts the writes made to it
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestRenderToStreams(t *testing.T) {
+	for _, endOfLine := range []string{"lf", "crlf"} {
+		cfg := config.Default()
+		cfg.Whitespace.EndOfLine = endOfLine
+		doc, err := parser.New().Parse([]byte("---\ntitle: x\n---\n" + strings.Repeat(benchmarkDocument, 10)))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+
+		expected, err := New().Render(doc, cfg)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		var out countingWriter
+		if err := New().RenderTo(&out, doc, cfg); err != nil {
+			t.Fatalf("RenderTo failed: %v", err)
+		}
+		if out.String() != expected {
+			t.Errorf("%s: expected RenderTo to match Render", endOfLine)
+		}
+		if out.writes < 2 {
+			t.Errorf("%s: expected the output to be streamed, got %d write", endOfLine, out.writes)
+		}
+	}
+}
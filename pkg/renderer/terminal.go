@@ -0,0 +1,258 @@
+package renderer
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Layout of the terminal renderer
+const (
+	// terminalBullet replaces the markers of unordered list items
+	terminalBullet = "•"
+	// terminalQuote marks the lines of blockquotes and admonitions
+	terminalQuote = "│"
+	// terminalIndent indents code blocks and definition descriptions
+	terminalIndent = "    "
+)
+
+// ansiStyle is an ANSI terminal style. Each style is switched off by its
+// own sequence rather than a reset, so styles nest.
+type ansiStyle struct {
+	on, off string
+}
+
+// ANSI styles of the terminal renderer
+var (
+	ansiBold      = ansiStyle{"\x1b[1m", "\x1b[22m"}
+	ansiDim       = ansiStyle{"\x1b[2m", "\x1b[22m"}
+	ansiItalic    = ansiStyle{"\x1b[3m", "\x1b[23m"}
+	ansiUnderline = ansiStyle{"\x1b[4m", "\x1b[24m"}
+	ansiStrike    = ansiStyle{"\x1b[9m", "\x1b[29m"}
+	ansiGreen     = ansiStyle{"\x1b[32m", "\x1b[39m"}
+	ansiYellow    = ansiStyle{"\x1b[33m", "\x1b[39m"}
+	ansiBlue      = ansiStyle{"\x1b[34m", "\x1b[39m"}
+	ansiMagenta   = ansiStyle{"\x1b[35m", "\x1b[39m"}
+	ansiCyan      = ansiStyle{"\x1b[36m", "\x1b[39m"}
+)
+
+// inlineStyles maps inline markup to the styles it is shown in
+var inlineStyles = map[parser.InlineStyle][]ansiStyle{
+	parser.StyleEmphasis:      {ansiItalic},
+	parser.StyleStrong:        {ansiBold},
+	parser.StyleCode:          {ansiYellow},
+	parser.StyleLink:          {ansiBlue, ansiUnderline},
+	parser.StyleImage:         {ansiMagenta},
+	parser.StyleStrikethrough: {ansiStrike},
+}
+
+// TerminalRenderer renders a document for reading in a terminal: markup is
+// replaced by ANSI styles such as bold headings and colored code, list
+// markers by bullets, and code blocks and quotes are indented. Without
+// Color, the same layout is rendered without styles.
+type TerminalRenderer struct {
+	// Color enables ANSI styles
+	Color bool
+	// definitions resolve the reference links of the document being rendered
+	definitions []*parser.LinkDefinition
+}
+
+// NewTerminal creates a new terminal renderer, using ANSI styles if color
+// is set
+func NewTerminal(color bool) *TerminalRenderer {
+	return &TerminalRenderer{Color: color}
+}
+
+// Render renders doc for a terminal
+func (r *TerminalRenderer) Render(doc *parser.Document, cfg *config.Config) (string, error) {
+	var sb strings.Builder
+	if err := r.RenderTo(&sb, doc, cfg); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// RenderTo renders doc for a terminal to w
+func (r *TerminalRenderer) RenderTo(w io.Writer, doc *parser.Document, cfg *config.Config) error {
+	return r.RenderToContext(context.Background(), w, doc, cfg)
+}
+
+// RenderToContext renders like RenderTo, returning ctx.Err() if ctx is done
+// before the document has been rendered
+func (r *TerminalRenderer) RenderToContext(ctx context.Context, w io.Writer, doc *parser.Document,
+	cfg *config.Config) error {
+	r.definitions = collectDefinitions(doc.Children, nil)
+	defer func() { r.definitions = nil }()
+
+	blocks := NewBlockWriter(w, cfg)
+	if doc.FrontMatter != "" {
+		frontMatter := r.style(strings.TrimRight(doc.FrontMatter, "\r\n"), ansiDim)
+		if err := blocks.WriteFrontMatter(frontMatter, len(doc.Children) > 0); err != nil {
+			return err
+		}
+	}
+	for _, child := range doc.Children {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if text := r.node(child); text != "" {
+			if _, err := io.WriteString(blocks, text+"\n\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return blocks.Close()
+}
+
+// blocks returns the rendering of nodes, separated by blank lines
+func (r *TerminalRenderer) blocks(nodes []parser.Node) string {
+	texts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if text := r.node(node); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// node returns the rendering of a single node
+func (r *TerminalRenderer) node(node parser.Node) string {
+	switch n := node.(type) {
+	case *parser.Heading:
+		styles := []ansiStyle{ansiBold, ansiCyan}
+		if n.Level == 1 {
+			styles = append(styles, ansiUnderline)
+		}
+		return r.style(r.inline(n.Text), styles...)
+	case *parser.Paragraph:
+		return r.inline(n.Text)
+	case *parser.Text:
+		return r.inline(n.Content)
+	case *parser.List:
+		return r.list(n)
+	case *parser.CodeBlock:
+		return prefixLines(r.style(strings.TrimRight(n.Content, "\n"), ansiGreen), terminalIndent, terminalIndent)
+	case *parser.Math:
+		return prefixLines(r.style(strings.TrimRight(n.Content, "\n"), ansiGreen), terminalIndent, terminalIndent)
+	case *parser.HTMLBlock:
+		return r.style(strings.TrimRight(n.Content, "\n"), ansiDim)
+	case *parser.MDXBlock:
+		return r.style(strings.TrimRight(n.Content, "\n"), ansiDim)
+	case *parser.Details:
+		return r.wrapped(n.Open, n.Children, n.Close)
+	case *parser.MDXElement:
+		return r.wrapped(n.Open, n.Children, n.Close)
+	case *parser.Blockquote:
+		return r.quote(r.blocks(n.Children))
+	case *parser.Admonition:
+		body := r.style(n.Kind, ansiBold)
+		if children := r.blocks(n.Children); children != "" {
+			body += "\n" + children
+		}
+		return r.quote(body)
+	case *parser.DefinitionList:
+		return r.blocks(n.Children)
+	case *parser.DefinitionTerm:
+		return r.style(r.inline(n.Text), ansiBold)
+	case *parser.DefinitionDescription:
+		return prefixLines(r.blocks(n.Children), terminalIndent, terminalIndent)
+	case *parser.LinkDefinitions:
+		lines := make([]string, 0, len(n.Definitions))
+		for _, d := range n.Definitions {
+			lines = append(lines, d.Markdown())
+		}
+		return r.style(strings.Join(lines, "\n"), ansiDim)
+	case parser.MarkdownNode:
+		return strings.TrimRight(n.Markdown(), "\n")
+	default:
+		return ""
+	}
+}
+
+// list returns the rendering of a list, one item per line with nested
+// blocks indented under the item text
+func (r *TerminalRenderer) list(list *parser.List) string {
+	items := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		marker := terminalBullet
+		if list.Ordered {
+			marker = item.Marker
+		}
+		indent := strings.Repeat(" ", len([]rune(marker))+1)
+
+		body := r.inline(item.Text)
+		if children := r.blocks(item.Children); children != "" {
+			if body != "" {
+				body += "\n"
+			}
+			body += children
+		}
+		items = append(items, prefixLines(body, r.style(marker, ansiDim)+" ", indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+// quote marks the lines of text as quoted
+func (r *TerminalRenderer) quote(text string) string {
+	prefix := r.style(terminalQuote, ansiDim) + " "
+	return prefixLines(text, prefix, prefix)
+}
+
+// wrapped returns the rendering of children between dimmed opening and
+// closing text
+func (r *TerminalRenderer) wrapped(open string, children []parser.Node, closing string) string {
+	parts := []string{r.style(strings.TrimRight(open, "\n"), ansiDim)}
+	if body := r.blocks(children); body != "" {
+		parts = append(parts, body)
+	}
+	parts = append(parts, r.style(strings.TrimRight(closing, "\n"), ansiDim))
+	return strings.Join(parts, "\n\n")
+}
+
+// inline returns inline markdown text with its markup replaced by styles
+func (r *TerminalRenderer) inline(text string) string {
+	return strings.TrimSpace(parser.StyledInlineText(text, r.definitions,
+		func(style parser.InlineStyle, text string) string {
+			return r.style(text, inlineStyles[style]...)
+		}))
+}
+
+// style returns text in styles, or text itself without Color. Styles are
+// applied line by line, so prefixes added to the lines stay unstyled.
+func (r *TerminalRenderer) style(text string, styles ...ansiStyle) string {
+	if !r.Color || text == "" || len(styles) == 0 {
+		return text
+	}
+	var on, off strings.Builder
+	for i := range styles {
+		on.WriteString(styles[i].on)
+		off.WriteString(styles[len(styles)-1-i].off)
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = on.String() + line + off.String()
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prefixLines prefixes the first line of text with first and the others
+// with rest, leaving empty lines without trailing spaces
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			prefix = strings.TrimRight(prefix, " ")
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,51 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// terminalTestDocument has a block of each kind the terminal renderer lays out
+const terminalTestDocument = "# Getting *Started*\n\nRead [the guide][guide] and run `mdfmt`.\n\n" +
+	"1. one\n1. two\n   - nested\n\n> Quoted\n>\n> twice\n\n```go\ncode()\n```\n\n" +
+	"[guide]: https://example.com\n"
+
+// renderTerminal renders terminalTestDocument with or without color
+func renderTerminal(t *testing.T, color bool) string {
+	t.Helper()
+	doc, err := parser.New().Parse([]byte(terminalTestDocument))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	output, err := NewTerminal(color).Render(doc, config.Default())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	return output
+}
+
+func TestTerminalRendererLayout(t *testing.T) {
+	expected := "Getting Started\n\nRead the guide and run mdfmt.\n\n" +
+		"1. one\n1. two\n   • nested\n\n│ Quoted\n│\n│ twice\n\n    code()\n\n" +
+		"[guide]: https://example.com\n"
+	if output := renderTerminal(t, false); output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+func TestTerminalRendererColor(t *testing.T) {
+	output := renderTerminal(t, true)
+	for _, styled := range []string{
+		"\x1b[1m\x1b[36m\x1b[4mGetting \x1b[3mStarted\x1b[23m\x1b[24m\x1b[39m\x1b[22m",
+		"\x1b[34m\x1b[4mthe guide\x1b[24m\x1b[39m",
+		"\x1b[33mmdfmt\x1b[39m",
+		"    \x1b[32mcode()\x1b[39m",
+	} {
+		if !strings.Contains(output, styled) {
+			t.Errorf("Expected %q in the output, got %q", styled, output)
+		}
+	}
+}
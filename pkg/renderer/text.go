@@ -0,0 +1,150 @@
+package renderer
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// TextRenderer renders the prose of a document as plain text, for
+// spellcheckers, search indexers and word counts. Markup is stripped, keeping
+// the text of links and the alt text of images. Front matter, code, math,
+// HTML and MDX blocks and link definitions are left out, and every block is
+// followed by a blank line; list items are one per line.
+type TextRenderer struct {
+	// definitions resolve the reference links of the document being rendered
+	definitions []*parser.LinkDefinition
+}
+
+// NewText creates a new plain-text renderer
+func NewText() *TextRenderer {
+	return &TextRenderer{}
+}
+
+// Render renders the prose of doc as plain text
+func (r *TextRenderer) Render(doc *parser.Document, cfg *config.Config) (string, error) {
+	var sb strings.Builder
+	if err := r.RenderTo(&sb, doc, cfg); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// RenderTo renders the prose of doc as plain text to w
+func (r *TextRenderer) RenderTo(w io.Writer, doc *parser.Document, cfg *config.Config) error {
+	return r.RenderToContext(context.Background(), w, doc, cfg)
+}
+
+// RenderToContext renders like RenderTo, returning ctx.Err() if ctx is done
+// before the document has been rendered
+func (r *TextRenderer) RenderToContext(ctx context.Context, w io.Writer, doc *parser.Document, cfg *config.Config) error {
+	r.definitions = collectDefinitions(doc.Children, nil)
+	defer func() { r.definitions = nil }()
+
+	blocks := NewBlockWriter(w, cfg)
+	for _, child := range doc.Children {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if text := r.blocks([]parser.Node{child}); text != "" {
+			if _, err := io.WriteString(blocks, text+"\n\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return blocks.Close()
+}
+
+// blocks returns the text of nodes, separated by blank lines
+func (r *TextRenderer) blocks(nodes []parser.Node) string {
+	texts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if text := r.node(node); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// node returns the text of a single node, or "" for nodes without prose
+func (r *TextRenderer) node(node parser.Node) string {
+	switch n := node.(type) {
+	case *parser.Heading:
+		return r.inline(n.Text)
+	case *parser.Paragraph:
+		return r.inline(n.Text)
+	case *parser.Text:
+		return r.inline(n.Content)
+	case *parser.List:
+		items := make([]string, 0, len(n.Items))
+		for _, item := range n.Items {
+			if text := r.node(item); text != "" {
+				items = append(items, text)
+			}
+		}
+		return strings.Join(items, "\n")
+	case *parser.ListItem:
+		lines := make([]string, 0, 1+len(n.Children))
+		if text := r.inline(n.Text); text != "" {
+			lines = append(lines, text)
+		}
+		for _, child := range n.Children {
+			if text := r.node(child); text != "" {
+				lines = append(lines, text)
+			}
+		}
+		return strings.Join(lines, "\n")
+	case *parser.DefinitionTerm:
+		return r.inline(n.Text)
+	case *parser.DefinitionDescription:
+		return r.blocks(n.Children)
+	case *parser.DefinitionList:
+		return r.blocks(n.Children)
+	case *parser.Blockquote:
+		return r.blocks(n.Children)
+	case *parser.Admonition:
+		return r.blocks(n.Children)
+	case *parser.Details:
+		return r.blocks(n.Children)
+	case *parser.MDXElement:
+		return r.blocks(n.Children)
+	case *parser.Table:
+		rows := make([]string, 0, len(n.Rows))
+		for _, row := range n.Rows {
+			cells := make([]string, 0, len(row))
+			for _, cell := range row {
+				cells = append(cells, r.inline(cell))
+			}
+			rows = append(rows, strings.Join(cells, "\t"))
+		}
+		return strings.Join(rows, "\n")
+	default:
+		// Code, math, HTML, MDX blocks, link definitions and the blocks of
+		// registered extensions have no prose
+		return ""
+	}
+}
+
+// inline returns inline markdown text without its markup
+func (r *TextRenderer) inline(text string) string {
+	return strings.TrimSpace(parser.InlineText(text, r.definitions))
+}
+
+// collectDefinitions appends the link reference definitions among nodes and
+// the blocks they contain to definitions
+func collectDefinitions(nodes []parser.Node, definitions []*parser.LinkDefinition) []*parser.LinkDefinition {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *parser.LinkDefinitions:
+			definitions = append(definitions, n.Definitions...)
+		case *parser.Details:
+			definitions = collectDefinitions(n.Children, definitions)
+		case *parser.MDXElement:
+			definitions = collectDefinitions(n.Children, definitions)
+		}
+	}
+	return definitions
+}
@@ -0,0 +1,28 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestTextRenderer(t *testing.T) {
+	content := "---\ntitle: Test\n---\n\n# Getting *Started*\n\nRead [the guide][guide].\n\n" +
+		"- one\n- two\n  1. nested\n\n```go\ncode()\n```\n\n> Quoted **text**\n\n<div>\nhtml\n</div>\n\n" +
+		"| Key | *Value* |\n|-----|---------|\n| a | 1 |\n\n" +
+		"[guide]: https://example.com\n"
+	doc, err := parser.New().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	output, err := NewText().Render(doc, config.Default())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := "Getting Started\n\nRead the guide.\n\none\ntwo\nnested\n\nQuoted text\n\nKey\tValue\na\t1\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
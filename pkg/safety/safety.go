@@ -0,0 +1,92 @@
+// Package safety checks that formatting keeps the meaning of a document by
+// comparing the HTML it renders to before and after.
+package safety
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Constants
+const (
+	// contextWidth is the number of bytes of HTML shown around a difference
+	contextWidth = 40
+)
+
+var (
+	// preformattedPattern matches pre elements, whose whitespace is content
+	preformattedPattern = regexp.MustCompile(`(?s)<pre[\s>].*?</pre>`)
+	// spaceRunPattern matches runs of whitespace
+	spaceRunPattern = regexp.MustCompile(`\s+`)
+	// tagGapPattern matches whitespace between tags
+	tagGapPattern = regexp.MustCompile(`>\s+<`)
+)
+
+// HTMLRenderer renders markdown to HTML
+type HTMLRenderer interface {
+	RenderHTML(w io.Writer, content []byte) error
+}
+
+// Error reports that formatting changes the HTML a document renders to.
+// Original and Formatted are the normalized HTML around the first
+// difference.
+type Error struct {
+	Original  string
+	Formatted string
+}
+
+// Error describes the first difference
+func (e *Error) Error() string {
+	return fmt.Sprintf("formatting changes the rendered HTML: %q becomes %q", e.Original, e.Formatted)
+}
+
+// Check renders original and formatted to HTML with r and returns an *Error
+// if their normalized HTML differs
+func Check(r HTMLRenderer, original, formatted []byte) error {
+	var before, after bytes.Buffer
+	if err := r.RenderHTML(&before, original); err != nil {
+		return err
+	}
+	if err := r.RenderHTML(&after, formatted); err != nil {
+		return err
+	}
+
+	a, b := Normalize(before.String()), Normalize(after.String())
+	if a == b {
+		return nil
+	}
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	start := max(i-contextWidth, 0)
+	return &Error{
+		Original:  a[start:min(i+contextWidth, len(a))],
+		Formatted: b[start:min(i+contextWidth, len(b))],
+	}
+}
+
+// Normalize returns html with the differences that do not change how it
+// displays removed: whitespace between tags is dropped and other runs of
+// whitespace become a single space, except within pre elements
+func Normalize(html string) string {
+	var sb strings.Builder
+	last := 0
+	for _, r := range preformattedPattern.FindAllStringIndex(html, -1) {
+		sb.WriteString(normalizeSpace(html[last:r[0]]))
+		sb.WriteString(html[r[0]:r[1]])
+		last = r[1]
+	}
+	sb.WriteString(normalizeSpace(html[last:]))
+	return sb.String()
+}
+
+// normalizeSpace collapses the whitespace of html outside pre elements,
+// which start and end blocks, so whitespace next to them is dropped too
+func normalizeSpace(html string) string {
+	html = tagGapPattern.ReplaceAllString(html, "><")
+	return strings.TrimSpace(spaceRunPattern.ReplaceAllString(html, " "))
+}
@@ -0,0 +1,78 @@
+package safety
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestNormalize(t *testing.T) {
+	html := "<p>one\ntwo  three</p>\n<ul>\n<li>item</li>\n</ul>\n<pre><code>a\n  b\n</code></pre>\n"
+	expected := "<p>one two three</p><ul><li>item</li></ul><pre><code>a\n  b\n</code></pre>"
+	if got := Normalize(html); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := parser.NewGoldmarkParser()
+	original := []byte("---\ntitle: Guide\n---\n\nSome text\nwrapped  here.\n\n* one\n* two\n\n```\ncode  block\n```\n")
+
+	tests := []struct {
+		name      string
+		formatted string
+		wantErr   bool
+	}{
+		{"reflowed", "---\ntitle: Other\n---\n\nSome text wrapped here.\n\n- one\n- two\n\n~~~\ncode  block\n~~~\n", false},
+		{"code changed", "Some text wrapped here.\n\n- one\n- two\n\n```\ncode block\n```\n", true},
+		{"loose list", "Some text wrapped here.\n\n- one\n\n- two\n\n```\ncode  block\n```\n", true},
+		{"emphasis", "Some *text* wrapped here.\n\n- one\n- two\n\n```\ncode  block\n```\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(p, original, []byte(tt.formatted))
+			var safetyErr *Error
+			if tt.wantErr != errors.As(err, &safetyErr) {
+				t.Errorf("Expected error %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckCustomSyntax(t *testing.T) {
+	parsers := map[string]*parser.GoldmarkParser{
+		"disabled": parser.NewGoldmarkParser(),
+		"enabled": parser.NewGoldmarkParserWithOptions(parser.Options{
+			Extensions: []string{parser.ExtensionMath, parser.ExtensionHighlight},
+		}),
+		"obsidian": parser.NewGoldmarkParserWithOptions(parser.Options{Dialect: parser.DialectObsidian}),
+		"mdx":      parser.NewGoldmarkParserWithOptions(parser.Options{Dialect: parser.DialectMDX}),
+	}
+	tests := []struct {
+		name      string
+		original  string
+		formatted string
+		changed   string
+	}{
+		{"inline math", "x $a$ y  \n", "x $a$ y\n", "x $b$ y\n"},
+		{"display math", "$$\nx  y\n$$\n\ntext  \n", "$$\nx  y\n$$\n\ntext\n", "$$\nx y\n$$\n\ntext\n"},
+		{"wiki link", "a [[Page]] b  \n", "a [[Page]] b\n", "a [[Other]] b\n"},
+		{"highlight", "a ==hi== b  \n", "a ==hi== b\n", "a ==ho== b\n"},
+		{"tag", "a #tag b  \n", "a #tag b\n", "a #other b\n"},
+		{"mdx", "<Note  />\n\ntext  \n", "<Note  />\n\ntext\n", "<Note />\n\ntext\n"},
+	}
+	for name, p := range parsers {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				if err := Check(p, []byte(tt.original), []byte(tt.formatted)); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				var safetyErr *Error
+				if err := Check(p, []byte(tt.original), []byte(tt.changed)); !errors.As(err, &safetyErr) {
+					t.Errorf("Expected a change in the HTML, got %v", err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Package spellcheck checks the words of prose against wordlists: a
+// built-in list of common English and technical words, and dictionaries of
+// project terms. Other checkers, such as ones backed by hunspell, plug in
+// through the Checker interface.
+package spellcheck
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Constants
+const (
+	// commentMarker starts a comment line in dictionary files
+	commentMarker = "#"
+)
+
+// builtinWords is the built-in wordlist, one word per line
+//
+//go:embed words.txt
+var builtinWords string
+
+// Checker decides whether words are spelled correctly
+type Checker interface {
+	// Correct reports whether word is spelled correctly
+	Correct(word string) bool
+}
+
+// CheckerFunc adapts a function to the Checker interface
+type CheckerFunc func(word string) bool
+
+// Correct implements Checker.
+func (f CheckerFunc) Correct(word string) bool {
+	return f(word)
+}
+
+// Combine returns a checker accepting the words any of checkers accepts
+func Combine(checkers ...Checker) Checker {
+	return CheckerFunc(func(word string) bool {
+		for _, checker := range checkers {
+			if checker.Correct(word) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Wordlist is a Checker accepting the words it lists, ignoring case, along
+// with their plurals, possessives and other regular inflections
+type Wordlist struct {
+	words map[string]bool
+}
+
+// NewWordlist creates a wordlist of words
+func NewWordlist(words ...string) *Wordlist {
+	w := &Wordlist{words: make(map[string]bool, len(words))}
+	w.Add(words...)
+	return w
+}
+
+// Builtin returns a new wordlist of common English and technical words
+func Builtin() *Wordlist {
+	w, _ := ReadWordlist(strings.NewReader(builtinWords))
+	return w
+}
+
+// ReadWordlist reads a wordlist with one word per line. Blank lines and
+// lines starting with # are ignored.
+func ReadWordlist(r io.Reader) (*Wordlist, error) {
+	w := NewWordlist()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, commentMarker) {
+			continue
+		}
+		w.Add(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wordlist: %w", err)
+	}
+	return w, nil
+}
+
+// LoadWordlist reads the dictionary file at path
+func LoadWordlist(path string) (*Wordlist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dictionary: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	w, err := ReadWordlist(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return w, nil
+}
+
+// Add adds words to the wordlist
+func (w *Wordlist) Add(words ...string) {
+	for _, word := range words {
+		w.words[normalize(word)] = true
+	}
+}
+
+// Len returns the number of words of the wordlist
+func (w *Wordlist) Len() int {
+	return len(w.words)
+}
+
+// Correct implements Checker. A word is correct when the wordlist has it,
+// or has the word it is a regular inflection of, such as "run" for
+// "running" or "library" for "libraries".
+func (w *Wordlist) Correct(word string) bool {
+	word = normalize(word)
+	if w.words[word] {
+		return true
+	}
+	for _, stem := range stems(word) {
+		if w.words[stem] {
+			return true
+		}
+	}
+	return false
+}
+
+// Word is a word of prose
+type Word struct {
+	// Text is the word as written
+	Text string
+	// Line is the 0-based line of the word within the prose
+	Line int
+}
+
+// Words returns the words of prose that are checked for spelling. Words
+// are split at whitespace and hyphens, with surrounding punctuation
+// removed. Chunks that look like code rather than words are skipped: those
+// with digits, underscores, slashes, dots, @ or other symbols inside,
+// words written in capitals, such as acronyms, and words with capitals
+// inside, such as "GitHub" or "camelCase".
+func Words(prose string) []Word {
+	var words []Word
+	for i, line := range strings.Split(prose, "\n") {
+		for _, chunk := range strings.Fields(line) {
+			chunk = strings.TrimFunc(chunk, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+			if chunk == "" || strings.ContainsFunc(chunk, isCodeRune) {
+				continue
+			}
+			for _, word := range strings.FieldsFunc(chunk, isHyphen) {
+				word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) })
+				if checkable(word) {
+					words = append(words, Word{Text: word, Line: i})
+				}
+			}
+		}
+	}
+	return words
+}
+
+// isCodeRune reports whether r makes a chunk of prose code-like
+func isCodeRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsMark(r) && r != '\'' && r != '’' && !isHyphen(r)
+}
+
+// isHyphen reports whether r joins the words of a compound
+func isHyphen(r rune) bool {
+	return r == '-' || r == '‐' || r == '–' || r == '—'
+}
+
+// checkable reports whether word is a word rather than an acronym or an
+// identifier: after its first letter it has no capitals
+func checkable(word string) bool {
+	if len(word) < 2 {
+		return false
+	}
+	for i, r := range word {
+		if i > 0 && unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// suffixRule turns words ending with suffix back into their stem by
+// replacing the suffix with replacement
+type suffixRule struct {
+	suffix, replacement string
+}
+
+// suffixRules are the regular English inflections
+var suffixRules = []suffixRule{
+	{"'s", ""}, {"s'", ""}, {"ies", "y"}, {"ied", "y"}, {"ier", "y"}, {"iest", "y"}, {"ily", "y"},
+	{"es", ""}, {"s", ""}, {"ed", ""}, {"ed", "e"}, {"ing", ""}, {"ing", "e"},
+	{"er", ""}, {"er", "e"}, {"ers", ""}, {"ers", "e"}, {"est", ""}, {"est", "e"},
+	{"ly", ""}, {"ly", "le"}, {"ness", ""}, {"ment", ""}, {"ments", ""},
+	{"able", ""}, {"able", "e"}, {"ability", ""}, {"ation", ""}, {"ation", "e"}, {"ations", "e"},
+}
+
+// stems returns the words that word may be an inflection of
+func stems(word string) []string {
+	var candidates []string
+	for _, rule := range suffixRules {
+		base, ok := strings.CutSuffix(word, rule.suffix)
+		if !ok || len(base) < 2 {
+			continue
+		}
+		candidates = append(candidates, base+rule.replacement)
+		// "running" and "stopped" double the last consonant of their stem
+		if rule.replacement == "" && len(base) > 2 && base[len(base)-1] == base[len(base)-2] {
+			candidates = append(candidates, base[:len(base)-1])
+		}
+	}
+	return candidates
+}
+
+// normalize returns word in lower case with typographic apostrophes
+// replaced by straight ones
+func normalize(word string) string {
+	return strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+}
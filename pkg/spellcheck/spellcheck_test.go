@@ -0,0 +1,97 @@
+package spellcheck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWordlistCorrect(t *testing.T) {
+	w := NewWordlist("run", "library", "make", "stop", "user", "Kubernetes")
+	tests := []struct {
+		word    string
+		correct bool
+	}{
+		{"run", true},
+		{"Run", true},
+		{"running", true},
+		{"runs", true},
+		{"libraries", true},
+		{"making", true},
+		{"stopped", true},
+		{"user's", true},
+		{"user’s", true},
+		{"users'", true},
+		{"kubernetes", true},
+		{"runx", false},
+		{"teh", false},
+	}
+	for _, tt := range tests {
+		if got := w.Correct(tt.word); got != tt.correct {
+			t.Errorf("Correct(%q) = %t, want %t", tt.word, got, tt.correct)
+		}
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	w := Builtin()
+	for _, word := range []string{"the", "configuration", "formatting", "headings", "don't", "written", "markdown"} {
+		if !w.Correct(word) {
+			t.Errorf("built-in wordlist rejects %q", word)
+		}
+	}
+	for _, word := range []string{"teh", "recieve", "formattting"} {
+		if w.Correct(word) {
+			t.Errorf("built-in wordlist accepts %q", word)
+		}
+	}
+}
+
+func TestReadWordlist(t *testing.T) {
+	w, err := ReadWordlist(strings.NewReader("# project words\nmdfmt\n\n  goldmark  \n"))
+	if err != nil {
+		t.Fatalf("ReadWordlist failed: %v", err)
+	}
+	if w.Len() != 2 || !w.Correct("mdfmt") || !w.Correct("goldmark") || w.Correct("#") {
+		t.Errorf("unexpected wordlist of %d words", w.Len())
+	}
+}
+
+func TestLoadWordlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("mdfmt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := LoadWordlist(path)
+	if err != nil {
+		t.Fatalf("LoadWordlist failed: %v", err)
+	}
+	if !w.Correct("mdfmt") {
+		t.Error("dictionary word rejected")
+	}
+
+	if _, err := LoadWordlist(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected an error for a missing dictionary")
+	}
+}
+
+func TestCombine(t *testing.T) {
+	checker := Combine(NewWordlist("alpha"), CheckerFunc(func(word string) bool { return word == "beta" }))
+	if !checker.Correct("alpha") || !checker.Correct("beta") || checker.Correct("gamma") {
+		t.Error("Combine should accept the words of any checker")
+	}
+}
+
+func TestWords(t *testing.T) {
+	prose := "Teh quick, brown-fox (jumps) over\nthe API's config.yaml and GitHub, user_name v2 don't — \"quoted\" I"
+	var got []string
+	for _, word := range Words(prose) {
+		got = append(got, word.Text+"@"+string(rune('0'+word.Line)))
+	}
+	expected := []string{"Teh@0", "quick@0", "brown@0", "fox@0", "jumps@0", "over@0", "the@1", "and@1", "don't@1", "quoted@1"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Words() = %v, want %v", got, expected)
+	}
+}
@@ -0,0 +1,167 @@
+// Package split divides a markdown document into one document per section.
+package split
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// Constants
+const (
+	// DefaultLevel splits at level 2 headings
+	DefaultLevel = 2
+	// DefaultIndexName is the file name of the generated index
+	DefaultIndexName = "index.md"
+	// MinNumberWidth is the minimum number of digits of a filename prefix
+	MinNumberWidth = 2
+	// IntroductionName names the section holding content before the first split heading
+	IntroductionName = "introduction"
+	// maxLevel is the deepest heading level
+	maxLevel = 6
+	// markdownExtension is appended to generated file names
+	markdownExtension = ".md"
+)
+
+var (
+	// headingIDPattern extracts the #id of a heading attribute block
+	headingIDPattern = regexp.MustCompile(`#([\w:.-]+)`)
+	// slugSeparatorPattern matches runs of characters that are not allowed in slugs
+	slugSeparatorPattern = regexp.MustCompile(`[^\pL\pN]+`)
+	// inlineMarkupPattern matches emphasis, code and strikethrough markers
+	inlineMarkupPattern = regexp.MustCompile("[*_`~=]+")
+)
+
+// Options configures how a document is split
+type Options struct {
+	// Level splits at headings of this level (1-6)
+	Level int
+	// NumberPrefix prefixes file names with zero-padded section numbers ("01-install.md")
+	NumberPrefix bool
+	// Index generates an index document linking to the sections
+	Index bool
+	// IndexName is the file name of the index; empty means DefaultIndexName
+	IndexName string
+}
+
+// IndexFileName returns the file name of the index
+func (o Options) IndexFileName() string {
+	if o.IndexName == "" {
+		return DefaultIndexName
+	}
+	return o.IndexName
+}
+
+// Section is one part of a split document
+type Section struct {
+	// Title is the heading text of the section
+	Title string
+	// FileName is the output file name of the section
+	FileName string
+	// Doc holds the blocks of the section, starting with its heading
+	Doc *parser.Document
+}
+
+// Split divides doc at headings of opts.Level. Blocks before the first such
+// heading, such as the document title, form an introduction, which becomes the top of the
+// index when one is generated. The returned index is nil unless opts.Index
+// is set.
+func Split(doc *parser.Document, opts Options) ([]Section, *parser.Document, error) {
+	level := opts.Level
+	if level == 0 {
+		level = DefaultLevel
+	}
+	if level < 1 || level > maxLevel {
+		return nil, nil, fmt.Errorf("split level must be between 1 and %d, got %d", maxLevel, level)
+	}
+
+	var intro []parser.Node
+	var sections []Section
+	for _, child := range doc.Children {
+		if heading, ok := child.(*parser.Heading); ok && heading.Level == level {
+			sections = append(sections, Section{
+				Title:    heading.Text,
+				FileName: slug(heading),
+				Doc:      &parser.Document{},
+			})
+		}
+		if len(sections) == 0 {
+			intro = append(intro, child)
+			continue
+		}
+		current := sections[len(sections)-1].Doc
+		current.Children = append(current.Children, child)
+	}
+
+	if len(intro) > 0 && !opts.Index {
+		sections = append([]Section{{
+			Title:    IntroductionName,
+			FileName: IntroductionName,
+			Doc:      &parser.Document{Children: intro},
+		}}, sections...)
+	}
+
+	assignFileNames(sections, opts.NumberPrefix)
+
+	if !opts.Index {
+		return sections, nil, nil
+	}
+	return sections, buildIndex(intro, sections), nil
+}
+
+// assignFileNames makes file names unique and adds number prefixes
+func assignFileNames(sections []Section, numberPrefix bool) {
+	width := len(strconv.Itoa(len(sections)))
+	if width < MinNumberWidth {
+		width = MinNumberWidth
+	}
+
+	used := make(map[string]int)
+	for i := range sections {
+		name := sections[i].FileName
+		if used[name]++; used[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, used[name])
+		}
+		if numberPrefix {
+			name = fmt.Sprintf("%0*d-%s", width, i+1, name)
+		}
+		sections[i].FileName = name + markdownExtension
+	}
+}
+
+// buildIndex returns the introduction followed by a list of links to the sections
+func buildIndex(intro []parser.Node, sections []Section) *parser.Document {
+	list := &parser.List{Marker: "-"}
+	for _, section := range sections {
+		link := &parser.Link{
+			Text:        section.Title,
+			Destination: section.FileName,
+			Style:       parser.LinkStyleInline,
+		}
+		list.Items = append(list.Items, &parser.ListItem{Text: link.Markdown(), Marker: "-"})
+	}
+
+	children := append(append([]parser.Node{}, intro...), list)
+	return &parser.Document{Children: children}
+}
+
+// slug returns the file name stem for a heading: its attribute #id when it
+// has one, otherwise its lower-cased text with punctuation replaced by dashes
+func slug(heading *parser.Heading) string {
+	if match := headingIDPattern.FindStringSubmatch(heading.Attributes); match != nil {
+		return match[1]
+	}
+
+	text := parser.ReplaceLinks(heading.Text, func(link *parser.Link) string {
+		return link.Text
+	})
+	text = inlineMarkupPattern.ReplaceAllString(text, "")
+	text = strings.Trim(slugSeparatorPattern.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if text == "" {
+		return "section"
+	}
+	return text
+}
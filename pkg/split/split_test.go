@@ -0,0 +1,84 @@
+package split
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func parse(t *testing.T, content string) *parser.Document {
+	t.Helper()
+	doc, err := parser.NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return doc
+}
+
+func fileNames(sections []Section) []string {
+	names := make([]string, 0, len(sections))
+	for _, section := range sections {
+		names = append(names, section.FileName)
+	}
+	return names
+}
+
+func TestSplit(t *testing.T) {
+	content := "# Guide\n\nIntro.\n\n## Install **it**\n\nRun.\n\n### Sub\n\nx\n\n## Configure {#config}\n\ny\n\n## Install it\n\nz\n"
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []string
+	}{
+		{"plain", Options{}, []string{"introduction.md", "install-it.md", "config.md", "install-it-2.md"}},
+		{"number prefix", Options{NumberPrefix: true}, []string{"01-introduction.md", "02-install-it.md", "03-config.md", "04-install-it-2.md"}},
+		{"index", Options{Index: true, NumberPrefix: true}, []string{"01-install-it.md", "02-config.md", "03-install-it-2.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections, index, err := Split(parse(t, content), tt.opts)
+			if err != nil {
+				t.Fatalf("Split failed: %v", err)
+			}
+			got := fileNames(sections)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, got)
+					break
+				}
+			}
+			if (index != nil) != tt.opts.Index {
+				t.Errorf("Expected index only when requested, got %v", index)
+			}
+		})
+	}
+}
+
+func TestSplit_Index(t *testing.T) {
+	sections, index, err := Split(parse(t, "# Guide\n\nIntro.\n\n## A\n\na\n\n## B\n\nb\n"), Options{Index: true})
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(sections) != 2 || len(sections[0].Doc.Children) != 2 {
+		t.Fatalf("Expected 2 sections with heading and paragraph, got %v", sections)
+	}
+
+	if len(index.Children) != 3 {
+		t.Fatalf("Expected title, intro and link list in index, got %v", index.Children)
+	}
+	list, ok := index.Children[2].(*parser.List)
+	if !ok || len(list.Items) != 2 || list.Items[1].Text != "[B](b.md)" {
+		t.Errorf("Expected links to sections, got %v", index.Children[2])
+	}
+}
+
+func TestSplit_InvalidLevel(t *testing.T) {
+	if _, _, err := Split(&parser.Document{}, Options{Level: 7}); err == nil {
+		t.Error("Expected error for level 7")
+	}
+}
@@ -0,0 +1,209 @@
+// Package stats collects per-document metrics for reports and dashboards.
+package stats
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+const (
+	// headingLevels is the number of heading levels
+	headingLevels = 6
+	// WordsPerMinute is the reading speed reading times are estimated with
+	WordsPerMinute = 200
+)
+
+// tableDelimiterPattern matches the delimiter row of a GFM table, such as
+// "| --- | :-: |" or "---|---"
+var tableDelimiterPattern = regexp.MustCompile(`^ {0,3}\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)+\|?\s*$`)
+
+// Document contains metrics for a single markdown document
+type Document struct {
+	Headings int `json:"headings"`
+	// HeadingLevels counts the headings of each level, from 1 to 6
+	HeadingLevels  [headingLevels]int `json:"heading_levels"`
+	Words          int                `json:"words"`
+	Links          int                `json:"links"`
+	Images         int                `json:"images"`
+	CodeBlocks     int                `json:"code_blocks"`
+	Tables         int                `json:"tables"`
+	LongestLine    int                `json:"longest_line"`
+	WrapViolations int                `json:"wrap_violations"`
+	// ReadingMinutes estimates the reading time at WordsPerMinute
+	ReadingMinutes int `json:"reading_minutes"`
+}
+
+// Add accumulates other into d. LongestLine keeps the maximum and the
+// reading time is estimated from the total words.
+func (d *Document) Add(other Document) {
+	d.Headings += other.Headings
+	for level := range d.HeadingLevels {
+		d.HeadingLevels[level] += other.HeadingLevels[level]
+	}
+	d.Words += other.Words
+	d.Links += other.Links
+	d.Images += other.Images
+	d.CodeBlocks += other.CodeBlocks
+	d.Tables += other.Tables
+	d.WrapViolations += other.WrapViolations
+	if other.LongestLine > d.LongestLine {
+		d.LongestLine = other.LongestLine
+	}
+	d.ReadingMinutes = readingMinutes(d.Words)
+}
+
+// readingMinutes estimates the minutes it takes to read words, rounded up
+func readingMinutes(words int) int {
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
+
+// Collect computes the metrics of a formatted document. Structure is counted
+// from doc, tables from the original source, and line lengths from the
+// formatted output, where lines longer than lineWidth outside fenced code
+// are wrap violations.
+func Collect(doc *parser.Document, source []byte, formatted string, lineWidth int) Document {
+	var d Document
+
+	defined := make(map[string]bool)
+	for _, child := range doc.Children {
+		if group, ok := child.(*parser.LinkDefinitions); ok {
+			for _, definition := range group.Definitions {
+				defined[parser.NormalizeLabel(definition.Label)] = true
+			}
+		}
+	}
+
+	walker := parser.NewWalker(doc)
+	for node, ok := walker.Next(); ok; node, ok = walker.Next() {
+		switch n := node.(type) {
+		case *parser.Heading:
+			d.Headings++
+			if n.Level >= 1 && n.Level <= headingLevels {
+				d.HeadingLevels[n.Level-1]++
+			}
+			d.countText(n.Text, defined)
+		case *parser.Paragraph:
+			d.countText(n.Text, defined)
+		case *parser.DefinitionTerm:
+			d.countText(n.Text, defined)
+		case *parser.List:
+			d.countList(n, defined)
+		case *parser.CodeBlock:
+			d.CodeBlocks++
+		}
+	}
+
+	d.Tables = countTables(string(source))
+	d.measureLines(formatted, lineWidth)
+	d.ReadingMinutes = readingMinutes(d.Words)
+	return d
+}
+
+// countList counts the text of list items, including nested lists
+func (d *Document) countList(list *parser.List, defined map[string]bool) {
+	for _, item := range list.Items {
+		d.countText(item.Text, defined)
+		for _, child := range item.Children {
+			if nested, ok := child.(*parser.List); ok {
+				d.countList(nested, defined)
+			}
+		}
+	}
+}
+
+// countText counts the links, images and words of inline markdown text.
+// Link destinations are not words; link and image text is.
+func (d *Document) countText(text string, defined map[string]bool) {
+	var plain func(string) string
+	plain = func(text string) string {
+		return parser.ReplaceLinks(text, func(link *parser.Link) string {
+			if link.Style == parser.LinkStyleShortcut && !defined[parser.NormalizeLabel(link.Text)] {
+				// [text] without a definition is plain bracketed text
+				return plain(link.Text)
+			}
+			if link.Image {
+				d.Images++
+			} else {
+				d.Links++
+			}
+			return plain(link.Text)
+		})
+	}
+
+	for _, word := range strings.Fields(plain(text)) {
+		if strings.IndexFunc(word, isWordRune) >= 0 {
+			d.Words++
+		}
+	}
+}
+
+// isWordRune reports whether r makes a token count as a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// countTables counts GFM table delimiter rows outside fenced code
+func countTables(source string) int {
+	tables := 0
+	forEachLine(source, func(line string, inCode bool) {
+		if !inCode && tableDelimiterPattern.MatchString(line) {
+			tables++
+		}
+	})
+	return tables
+}
+
+// measureLines records the longest line and counts lines over lineWidth
+// outside fenced code
+func (d *Document) measureLines(formatted string, lineWidth int) {
+	forEachLine(formatted, func(line string, inCode bool) {
+		length := utf8.RuneCountInString(line)
+		if length > d.LongestLine {
+			d.LongestLine = length
+		}
+		if !inCode && lineWidth > 0 && length > lineWidth {
+			d.WrapViolations++
+		}
+	})
+}
+
+// forEachLine calls fn for each line of text, reporting whether it belongs
+// to a fenced code block (fence lines included)
+func forEachLine(text string, fn func(line string, inCode bool)) {
+	inFence := ""
+	for _, line := range strings.Split(text, "\n") {
+		fence := fenceMarker(line)
+		switch {
+		case fence == "":
+			fn(line, inFence != "")
+		case inFence == "":
+			inFence = fence
+			fn(line, true)
+		default:
+			if strings.HasPrefix(fence, inFence) {
+				inFence = ""
+			}
+			fn(line, true)
+		}
+	}
+}
+
+// fenceMarker returns the backtick or tilde run opening a code fence line,
+// or "" if line is not a fence
+func fenceMarker(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return ""
+	}
+	for _, c := range []string{"`", "~"} {
+		run := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+		if run >= 3 {
+			return trimmed[:run]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestCollect(t *testing.T) {
+	source := "# Title\n\n" +
+		"See [docs](https://example.com), ![logo](logo.png), [ref] and [plain].\n\n" +
+		"| a | b |\n|---|:-:|\n| 1 | 2 |\n\n" +
+		"```\n|---|---|\n```\n\n" +
+		"- item [two][ref]\n  - nested\n\n" +
+		"[ref]: https://ref.example\n"
+
+	doc, err := parser.NewGoldmarkParser().Parse([]byte(source))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	formatted := "# Title\n\n```\n" + "a line that is far too long for the width\n" + "```\n\nshort\nanother line that is too long\n"
+	got := Collect(doc, []byte(source), formatted, 20)
+
+	expected := Document{
+		Headings:       1,
+		HeadingLevels:  [6]int{1},
+		Words:          10,
+		Links:          3,
+		Images:         1,
+		CodeBlocks:     1,
+		Tables:         1,
+		LongestLine:    41,
+		WrapViolations: 1,
+		ReadingMinutes: 1,
+	}
+	if got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestDocument_Add(t *testing.T) {
+	total := Document{Words: 3, LongestLine: 40}
+	total.Add(Document{Words: 2, Links: 1, LongestLine: 30, HeadingLevels: [6]int{1, 2}})
+	total.Add(Document{Words: WordsPerMinute, LongestLine: 90, WrapViolations: 2, HeadingLevels: [6]int{0, 1}})
+
+	expected := Document{
+		Words:          WordsPerMinute + 5,
+		Links:          1,
+		LongestLine:    90,
+		WrapViolations: 2,
+		HeadingLevels:  [6]int{1, 3},
+		ReadingMinutes: 2,
+	}
+	if total != expected {
+		t.Errorf("Expected %+v, got %+v", expected, total)
+	}
+}
@@ -0,0 +1,1055 @@
+// Package e2e runs the mdfmt binary against temporary directories and
+// compares its output, exit code and written files with golden transcripts.
+//
+// Run with -update to rewrite the golden files after an intended change:
+//
+//	go test ./test/e2e -update
+package e2e
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+var (
+	buildOnce sync.Once
+	binPath   string
+	errBuild  error
+)
+
+// binary builds mdfmt once per test run and returns its path
+func binary(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping end-to-end test in short mode")
+	}
+
+	buildOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "mdfmt-e2e")
+		if err != nil {
+			errBuild = err
+			return
+		}
+		binPath = filepath.Join(dir, "mdfmt")
+		if runtime.GOOS == "windows" {
+			binPath += ".exe"
+		}
+		cmd := exec.Command("go", "build", "-o", binPath, "../../cmd/mdfmt")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			errBuild = fmt.Errorf("go build failed: %w\n%s", err, output)
+		}
+	})
+	if errBuild != nil {
+		t.Fatalf("Failed to build mdfmt: %v", errBuild)
+	}
+	return binPath
+}
+
+// TestMain removes the built binary after the tests
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if binPath != "" {
+		_ = os.RemoveAll(filepath.Dir(binPath))
+	}
+	os.Exit(code)
+}
+
+// testCase is a single CLI invocation
+type testCase struct {
+	name string
+	// files are created in the work directory before running
+	files map[string]string
+	// dir is the working directory, relative to the work directory
+	dir   string
+	args  []string
+	stdin string
+	// exitCode is the expected exit code
+	exitCode int
+	// show lists files whose content after the run goes into the transcript
+	show []string
+}
+
+const (
+	unformatted = "#  Title\n\nSome   text.\n\n* one\n* two\n"
+	formatted   = "# Title\n\nSome text.\n\n- one\n- two\n"
+)
+
+// whitespaceOnly holds files that differ from formatted only in whitespace
+var whitespaceOnly = map[string]string{
+	"trailing.md": "# Title  \n\nSome text. \n\n- one\t\n- two\n",
+	"blanks.md":   "# Title\n\n\n\n\nSome text.\n\n- one\n- two\n\n\n",
+	"newline.md":  strings.TrimSuffix(formatted, "\n"),
+	"crlf.md":     strings.ReplaceAll(formatted, "\n", "\r\n"),
+	"ok.md":       formatted,
+}
+
+var testCases = []testCase{
+	{
+		name:  "stdout",
+		files: map[string]string{"README.md": unformatted},
+		args:  []string{"README.md"},
+	},
+	{
+		name:  "write",
+		files: map[string]string{"README.md": unformatted, "docs/guide.md": unformatted},
+		args:  []string{"-w", "."},
+		show:  []string{"README.md", "docs/guide.md"},
+	},
+	{
+		name:     "check_unformatted",
+		files:    map[string]string{"README.md": unformatted},
+		args:     []string{"--check", "README.md"},
+		exitCode: 1,
+		show:     []string{"README.md"},
+	},
+	{
+		name:  "check_formatted",
+		files: map[string]string{"README.md": formatted},
+		args:  []string{"-c", "README.md"},
+	},
+	{
+		name:     "subcommand_check",
+		files:    map[string]string{"README.md": unformatted, "ok.md": formatted},
+		args:     []string{"check", "-l", "."},
+		exitCode: 1,
+	},
+	{
+		name:  "subcommand_diff",
+		files: map[string]string{"README.md": unformatted},
+		args:  []string{"diff", "README.md"},
+	},
+	{
+		name: "check_list_diff",
+		files: map[string]string{"a.md": unformatted, "b.md": "#  B\n\nLine.\n\nSome   text.\n\n" + strings.Repeat("Line.\n\n", 5) + "*  last\n",
+			"ok.md": formatted},
+		args:     []string{"--check", "--list", "--diff", "."},
+		exitCode: 1,
+	},
+	{
+		name:  "list_diff",
+		files: map[string]string{"a.md": unformatted, "ok.md": formatted},
+		args:  []string{"-l", "-d", "."},
+	},
+	{
+		name:     "subcommand_diff_check",
+		files:    map[string]string{"README.md": formatted},
+		args:     []string{"diff", "--check", "README.md"},
+		exitCode: 0,
+	},
+	{
+		name:     "write_with_list",
+		files:    map[string]string{"README.md": unformatted},
+		args:     []string{"-w", "-l", "README.md"},
+		exitCode: 2,
+	},
+	{
+		name:     "print0_with_diff",
+		files:    map[string]string{"README.md": unformatted},
+		args:     []string{"-l", "--print0", "-d", "README.md"},
+		exitCode: 2,
+	},
+	{
+		name:     "lint",
+		files:    map[string]string{"a.md": unformatted, "b.md": formatted + "\nCosts $x$ here.\n", "c.md": formatted},
+		args:     []string{"lint", "."},
+		exitCode: 1,
+	},
+	{
+		name: "init_preset",
+		args: []string{"init", "--preset", "github", "-o", "-"},
+	},
+	{
+		name:  "init",
+		files: map[string]string{"README.md": unformatted},
+		args:  []string{"init", "--preset", "strict"},
+		show:  []string{".mdfmt.yaml"},
+	},
+	{
+		name: "config_print",
+		files: map[string]string{
+			".mdfmt.yaml": "line_width: 100\n",
+		},
+		args: []string{"config", "print", "--format", "json"},
+	},
+	{
+		name: "output_checkstyle",
+		files: map[string]string{
+			"a.md": unformatted,
+			"b.md": formatted + "\nCosts $x$ here.\n",
+			"c.md": "---\nmdfmt:\n  line_width: -1\n---\n" + formatted,
+		},
+		args:     []string{"check", "--output", "checkstyle", "."},
+		exitCode: 2,
+	},
+	{
+		name: "output_junit_report_file",
+		files: map[string]string{
+			"a.md": unformatted,
+			"b.md": formatted,
+		},
+		args: []string{"-l", "--output", "junit", "--report-file", "junit.xml", "."},
+		show: []string{"junit.xml"},
+	},
+	{
+		name:  "list",
+		files: map[string]string{"a.md": unformatted, "b.md": formatted, "notes.txt": unformatted},
+		args:  []string{"-l", "."},
+	},
+	{
+		name:  "diff",
+		files: map[string]string{"README.md": unformatted},
+		args:  []string{"-d", "README.md"},
+		show:  []string{"README.md"},
+	},
+	{
+		name:  "stdin",
+		args:  []string{"-"},
+		stdin: unformatted,
+	},
+	{
+		name:     "stdin_check",
+		args:     []string{"-c", "-"},
+		stdin:    unformatted,
+		exitCode: 1,
+	},
+	{
+		name:     "stdin_write",
+		args:     []string{"-w", "-"},
+		stdin:    unformatted,
+		exitCode: 2,
+	},
+	{
+		name:  "files_from_print0",
+		files: map[string]string{"my notes.md": unformatted, "b.md": formatted, "c.md": unformatted},
+		args:  []string{"--list", "--print0", "--files-from", "-"},
+		stdin: "my notes.md\x00b.md\x00",
+	},
+	{
+		name:     "files_from_lines",
+		files:    map[string]string{"my notes.md": unformatted, "list.txt": "my notes.md\r\n\n"},
+		args:     []string{"--check", "--files-from", "list.txt"},
+		exitCode: 1,
+	},
+	{
+		name: "config_discovery",
+		files: map[string]string{
+			".mdfmt.yaml":   "list:\n  bullet_style: \"*\"\n",
+			"docs/guide.md": unformatted,
+		},
+		dir:  "docs",
+		args: []string{"guide.md"},
+	},
+	{
+		name: "config_nested",
+		files: map[string]string{
+			".mdfmt.yaml":              "list:\n  bullet_style: \"*\"\n",
+			"packages/web/.mdfmt.yaml": "heading:\n  style: setext\n",
+			"packages/web/README.md":   unformatted,
+			"packages/api/README.md":   unformatted,
+		},
+		args: []string{"--include-header", "packages/web/README.md", "packages/api/README.md"},
+	},
+	{
+		name:     "stdout_multiple_files",
+		files:    map[string]string{"a.md": unformatted, "docs/b.md": unformatted},
+		args:     []string{"."},
+		exitCode: 2,
+	},
+	{
+		name:  "include_header_single_file",
+		files: map[string]string{"a.md": unformatted},
+		args:  []string{"--include-header", "a.md"},
+	},
+	{
+		name:     "include_header_with_list",
+		files:    map[string]string{"a.md": unformatted},
+		args:     []string{"--include-header", "-l", "a.md"},
+		exitCode: 2,
+	},
+	{
+		name: "front_matter_config",
+		files: map[string]string{
+			".mdfmt.yaml": "list:\n  bullet_style: \"*\"\n",
+			"api.md":      "---\ntitle: API\nmdfmt:\n  heading:\n    style: setext\n---\n" + unformatted,
+		},
+		args: []string{"api.md"},
+	},
+	{
+		name: "keep_going_write",
+		files: map[string]string{
+			"a.md": "---\nmdfmt:\n  line_width: -1\n---\n" + unformatted,
+			"b.md": unformatted,
+		},
+		args:     []string{"--write", "--keep-going", "."},
+		exitCode: 2,
+		show:     []string{"a.md", "b.md"},
+	},
+	{
+		name: "check_keep_going",
+		files: map[string]string{
+			"a.md": "---\nmdfmt:\n  line_width: -1\n---\n" + unformatted,
+			"b.md": unformatted,
+		},
+		args:     []string{"--check", "."},
+		exitCode: 2,
+	},
+	{
+		name: "backup_suffix",
+		files: map[string]string{
+			"a.md": unformatted,
+			"b.md": "# Title\n\nSome text.\n",
+		},
+		args: []string{"--write", "--backup", "."},
+		show: []string{"a.md", "a.md.orig"},
+	},
+	{
+		name:  "backup_dir",
+		files: map[string]string{"docs/a.md": unformatted},
+		args:  []string{"--write", "--backup=backups/", "."},
+		show:  []string{"docs/a.md", "backups/docs/a.md"},
+	},
+	{
+		name: "interactive",
+		files: map[string]string{
+			"a.md": "#  Title\n\nSome text.\n\n* one\n* two\n",
+			"b.md": unformatted,
+		},
+		args:  []string{"--interactive", "a.md", "b.md"},
+		stdin: "n\n?\ny\n",
+		show:  []string{"a.md", "b.md"},
+	},
+	{
+		name:  "interactive_reject_then_accept",
+		files: map[string]string{"a.md": "# Title\n\n\n\nSome text.\n\nMore text.\n\nEven more text.\n\nThe end.\n\n* one\n* two\n"},
+		args:  []string{"--interactive", "a.md"},
+		stdin: "n\ny\n",
+		show:  []string{"a.md"},
+	},
+	{
+		name:  "interactive_moved_line",
+		files: map[string]string{"a.md": "* a\n* b\n\ntext   \n\n\n\n\nend\n"},
+		args:  []string{"--interactive", "a.md"},
+		stdin: "y\nn\n",
+		show:  []string{"a.md"},
+	},
+	{
+		name:     "timeout_skips",
+		files:    map[string]string{"a.md": unformatted},
+		args:     []string{"--write", "--timeout", "1ns", "."},
+		exitCode: 0,
+		show:     []string{"a.md"},
+	},
+	{
+		name:     "cache_identical_files",
+		files:    map[string]string{"a.md": unformatted, "copy/a.md": unformatted},
+		args:     []string{"--write", "."},
+		exitCode: 0,
+		show:     []string{"a.md", "copy/a.md"},
+	},
+	{
+		name:     "cache_size_negative",
+		files:    map[string]string{"a.md": unformatted},
+		args:     []string{"--cache-size", "-1", "."},
+		exitCode: 2,
+	},
+	{
+		name:  "parse",
+		files: map[string]string{"README.md": unformatted},
+		args:  []string{"parse", "README.md"},
+	},
+	{
+		name:  "parse_ast",
+		args:  []string{"parse", "--ast", "-"},
+		stdin: "---\ntitle: Test\n---\n\n# Title\n\n<details>\n\n- one\n- two\n\n</details>\n\n[docs]: https://example.com \"Docs\"\n",
+	},
+	{
+		name:  "render_text",
+		files: map[string]string{"README.md": "---\ntitle: Test\n---\n\n# The *Title*\n\nSee [the docs][docs] and ![a logo](logo.png).\n\n* one\n* `two`\n\n```sh\nmdfmt -w .\n```\n\n[docs]: https://example.com\n"},
+		args:  []string{"render", "--to", "text", "README.md"},
+	},
+	{
+		name:     "render_unknown_format",
+		files:    map[string]string{"README.md": unformatted},
+		args:     []string{"render", "--to", "html", "README.md"},
+		exitCode: 2,
+	},
+	{
+		name:  "preview",
+		files: map[string]string{"README.md": "# The *Title*\n\nSee [the docs](https://example.com) and run `mdfmt`.\n\n* one\n* two\n\n> Quoted\n\n```sh\nmdfmt -w .\n```\n"},
+		args:  []string{"preview", "README.md"},
+	},
+	{
+		name:  "preview_color",
+		files: map[string]string{"README.md": "# The *Title*\n\nSee [the docs](https://example.com) and run `mdfmt`.\n\n* one\n* two\n\n> Quoted\n\n```sh\nmdfmt -w .\n```\n"},
+		args:  []string{"preview", "--color", "always", "README.md"},
+	},
+	{
+		name:     "preview_invalid_color",
+		files:    map[string]string{"README.md": unformatted},
+		args:     []string{"preview", "--color", "sometimes", "README.md"},
+		exitCode: 2,
+	},
+	{
+		name: "stats",
+		files: map[string]string{
+			"a.md":      "# Title\n\nSee [docs](https://example.com) and ![logo](logo.png).\n\n## Usage\n\n```sh\nmdfmt\n```\n",
+			"docs/b.md": unformatted,
+		},
+		args: []string{"stats"},
+	},
+	{
+		name:  "stats_json",
+		files: map[string]string{"a.md": unformatted},
+		args:  []string{"stats", "--json", "a.md"},
+	},
+	{
+		name: "links",
+		files: map[string]string{
+			"a.md":      "# Title\n\nSee [the guide](docs/b.md) and\n[the site][site].\n\n[site]: https://example.com\n",
+			"docs/b.md": "- ![logo](logo.png)\n- <https://example.com/b>\n",
+		},
+		args: []string{"links"},
+	},
+	{
+		name:  "links_external_json",
+		files: map[string]string{"a.md": "See [the guide](docs/b.md) and <https://example.com>.\n"},
+		args:  []string{"links", "--external", "--json"},
+	},
+	{
+		name:     "links_conflicting_filters",
+		files:    map[string]string{"a.md": unformatted},
+		args:     []string{"links", "--external", "--relative"},
+		exitCode: 2,
+	},
+	{
+		name: "join_shift_headings",
+		files: map[string]string{
+			"a.md": "---\ntitle: Handbook\n---\n# Intro\n\nWelcome.\n",
+			"b.md": "---\ntitle: Install\nauthor: Docs\n---\n# Install\n\n## Linux\n\nRun it.\n",
+		},
+		args: []string{"join", "--shift-headings", "a.md", "b.md"},
+	},
+	{
+		name:  "join_output_file",
+		files: map[string]string{"a.md": "# A\n", "b.md": "# B\n"},
+		args:  []string{"join", "-o", "out.md", "a.md", "b.md"},
+		show:  []string{"out.md"},
+	},
+	{
+		name: "lint_unused_definitions",
+		files: map[string]string{
+			".mdfmt.yaml": "links:\n  sort_definitions: alphabetical\n  warn_unused_definitions: true\n",
+			"a.md":        "See [b] and [a].\n\n[b]: b.md\n[a]: a.md\n[old]: old.md\n",
+		},
+		args:     []string{"lint", "a.md"},
+		exitCode: 1,
+	},
+	{
+		name: "lint_images",
+		files: map[string]string{
+			".mdfmt.yaml":       "images:\n  warn_empty_alt: true\n  check_paths: true\n",
+			"docs/a.md":         "# Images\n\n![Logo](img/logo.png)\n\n- ![](img/missing.png)\n",
+			"docs/img/logo.png": "png",
+		},
+		args:     []string{"lint", "docs"},
+		exitCode: 1,
+	},
+	{
+		name:  "badges_one_per_line",
+		files: map[string]string{".mdfmt.yaml": "line_width: 40\nimages:\n  badge_layout: one_per_line\n"},
+		args:  []string{"-"},
+		stdin: "# Project\n\n[![CI](https://ci.example/badge.svg)](https://ci.example) [![Docs](https://docs.example/badge.svg)](https://docs.example) ![MIT](https://img.example/mit.svg)\n",
+	},
+	{
+		name:  "replacements",
+		files: map[string]string{".mdfmt.yaml": "replacements:\n  github: GitHub\n  k8s: Kubernetes\n"},
+		args:  []string{"-"},
+		stdin: "---\nrepo: github\n---\n# Deploying to k8s\n\nPush to github at https://github.com/org/repo and run `k8s-deploy`.\n",
+	},
+	{
+		name:  "template_tags",
+		files: map[string]string{".mdfmt.yaml": "line_width: 30\nlinks:\n  bare_url_style: angle\n"},
+		args:  []string{"-"},
+		stdin: "# Values\n\nThe service is named {{ .Values.service.name | default \"web\" }} and served at\n" +
+			"{{ .Values.url | default \"https://example.com\" }}.\n\n{% if page.draft %}Draft   text{% endif %} {#  note  #}\n",
+	},
+	{
+		name:  "template_block_tags",
+		args:  []string{"-"},
+		stdin: "{% for item in items %}\n* {{ item }}\n{% endfor %}\n\nIntro\n{% if page.draft %}\nDraft   text\n{% endif %}\n",
+	},
+	{
+		name: "notebook",
+		files: map[string]string{
+			".mdfmt.yaml": "files:\n  extensions: [\".md\", \".ipynb\"]\n",
+			"analysis.ipynb": "{\n \"cells\": [\n  {\n   \"cell_type\": \"markdown\",\n   \"metadata\": {},\n" +
+				"   \"source\": [\n    \"Results\\n\",\n    \"=======\\n\",\n    \"* one\\n\",\n    \"* two\"\n   ]\n  },\n" +
+				"  {\n   \"cell_type\": \"code\",\n   \"execution_count\": 1,\n   \"metadata\": {},\n" +
+				"   \"outputs\": [{\"output_type\": \"stream\", \"name\": \"stdout\", \"text\": [\"* kept\\n\"]}],\n" +
+				"   \"source\": [\"print('* kept')\"]\n  },\n" +
+				"  {\"cell_type\": \"markdown\", \"metadata\": {}, \"source\": \"Done   <b>now</b>\"}\n" +
+				" ],\n \"metadata\": {},\n \"nbformat\": 4,\n \"nbformat_minor\": 5\n}\n",
+		},
+		args: []string{"analysis.ipynb"},
+	},
+	{
+		name: "embedded",
+		files: map[string]string{
+			".mdfmt.yaml": "line_width: 40\n",
+			"api.go": "// Package api serves   the   public API of the service, which is described in openapi.yaml.\npackage api\n\n" +
+				"//go:generate go run gen.go\n\n// Handler handles requests:\n//\n// * list\n// * get\nfunc Handler() {}\n",
+			"openapi.yaml": "info:\n  title: API\n  description: |\n    Overview\n    ========\n\n    * one\n    * two\n",
+			"README.md":    "Example\n=======\n\n````markdown\nTitle\n=====\n\n* item\n````\n",
+		},
+		args: []string{"--embedded", "-w", "."},
+		show: []string{"api.go", "openapi.yaml", "README.md"},
+	},
+	{
+		name: "lint_changelog",
+		files: map[string]string{
+			".mdfmt.yaml": "extends: [changelog]\n",
+			"CHANGELOG.md": "# Changelog\n\n## Unreleased\n\n### Fixed\n\n- Crash on empty\n  files\n\n### Added\n\n- New flag\n\n" +
+				"## v1.0.0 (2024-01-31)\n\n- First release\n\n## Beta\n",
+		},
+		args:     []string{"lint", "CHANGELOG.md"},
+		exitCode: 1,
+	},
+	{
+		name:  "changelog",
+		files: map[string]string{".mdfmt.yaml": "extends: [changelog]\n"},
+		args:  []string{"-"},
+		stdin: "# Changelog\n\n## Unreleased\n\n### Fixed\n\n- Crash on empty\n  files\n\n### Added\n\n- New flag\n\n" +
+			"## v1.0.0 (2024-01-31)\n\n- First release\n",
+	},
+	{
+		name: "lint_duplicate_headings",
+		files: map[string]string{
+			".mdfmt.yaml": "heading:\n  duplicates: siblings\n",
+			"guide.md":    "# Guide\n\n## Linux\n\n### Install\n\n### Install\n\n## macOS\n\n### Install\n",
+		},
+		args:     []string{"lint", "guide.md"},
+		exitCode: 1,
+	},
+	{
+		name:  "fix_duplicate_anchors",
+		files: map[string]string{".mdfmt.yaml": "heading:\n  duplicates: document\n  fix_duplicate_anchors: true\n"},
+		args:  []string{"-"},
+		stdin: "# Guide\n\n## Linux\n\n### Install\n\n## macOS\n\n### Install {.tab}\n",
+	},
+	{
+		name: "lint_spellcheck",
+		files: map[string]string{
+			".mdfmt.yaml": "spellcheck:\n  enabled: true\n  dictionaries: [words.txt]\n  words: [goldmark]\n",
+			"words.txt":   "# project words\nmdfmt\n",
+			"guide.md":    "---\ntitle: Gide\n---\n\n# Instaling mdfmt\n\nRun `mdfmt --wrte` to format the\nfiles with goldmark, see <https://example.com/speling>.\n\n- Teh list itme\n",
+		},
+		args:     []string{"lint", "guide.md"},
+		exitCode: 1,
+	},
+	{
+		name: "lint_suppressed",
+		files: map[string]string{
+			".mdfmt.yaml": "heading:\n  duplicates: document\nimages:\n  warn_empty_alt: true\n",
+			"guide.md":    "# Guide\n\n## Install\n\n<!-- mdfmt-disable-next-line duplicate-heading -->\n\n## Install\n\n<!-- mdfmt-disable MD045 -->\n\n![](logo.png)\n\n<!-- mdfmt-enable -->\n\n![](icon.png)\n",
+		},
+		args:     []string{"lint", "guide.md"},
+		exitCode: 1,
+	},
+	{
+		name: "lint_update_baseline",
+		files: map[string]string{
+			".mdfmt.yaml":   "images:\n  warn_empty_alt: true\n",
+			"docs/guide.md": "# Guide\n\n![](a.png)\n\n![](a.png)\n",
+			"docs/ok.md":    "# Fine\n",
+		},
+		args: []string{"lint", "--update-baseline", "docs"},
+		show: []string{".mdfmt-baseline.json"},
+	},
+	{
+		name: "lint_baseline",
+		files: map[string]string{
+			".mdfmt.yaml":          "images:\n  warn_empty_alt: true\n",
+			".mdfmt-baseline.json": "{\"version\": 1, \"violations\": [{\"path\": \"docs/guide.md\", \"rule\": \"empty-alt\", \"message\": \"image a.png has no alt text\", \"count\": 1}]}\n",
+			"docs/guide.md":        "# Guide\n\nIntro.\n\n![](a.png)\n\n![](b.png)\n",
+		},
+		args:     []string{"lint", "docs"},
+		exitCode: 1,
+	},
+	{
+		name:     "baseline_requires_lint",
+		files:    map[string]string{"a.md": "# A\n"},
+		args:     []string{"check", "--update-baseline", "a.md"},
+		exitCode: 2,
+	},
+	{
+		name: "lint_plugin",
+		files: map[string]string{
+			".mdfmt.yaml": "plugins:\n  - name: owner-section\n    command: [sh, owner.sh]\n    input: text\n",
+			"owner.sh":    "grep -q '^## Owner' || echo '[{\"line\": 1, \"message\": \"'\"$MDFMT_FILE\"' has no Owner section\"}]'\n",
+			"a.md":        "# A\n\n## Owner\n\nDocs team.\n",
+			"b.md":        "# B\n",
+			"c.md":        "<!-- mdfmt-disable owner-section -->\n\n# C\n",
+		},
+		args:     []string{"lint", "a.md", "b.md", "c.md"},
+		exitCode: 1,
+	},
+	{
+		name: "rules",
+		args: []string{"rules"},
+	},
+	{
+		name: "rules_markdown",
+		args: []string{"rules", "--markdown"},
+	},
+	{
+		name:     "rules_conflicting_formats",
+		args:     []string{"rules", "--json", "--markdown"},
+		exitCode: 2,
+	},
+	{
+		name:  "bare_url_link",
+		files: map[string]string{".mdfmt.yaml": "line_width: 30\nlinks:\n  bare_url_style: link\n"},
+		args:  []string{"-"},
+		stdin: "# Links\n\nRead https://example.com/a/very/long/path/to/a/page. Or see <https://example.org/other/long/path> " +
+			"and [the docs](https://example.net/docs), but not `https://example.com/code`.\n",
+	},
+	{
+		name: "list_continuation",
+		args: []string{"-"},
+		stdin: "# Setup\n\n1. Install the tool.\n\n   Then   run it on the docs of the project, which wraps this continuation paragraph " +
+			"at the configured line width:\n\n   ```sh\n   mdfmt --write docs\n   ```\n\n   1. nested\n      continued\n\n" +
+			"   > A note.\n2. Done.\n",
+	},
+	{
+		name:  "verbatim_code",
+		files: map[string]string{".mdfmt.yaml": "line_width: 40\nwhitespace:\n  max_blank_lines: 1\n"},
+		args:  []string{"-"},
+		stdin: "# Code\n\nRun ` mdfmt  --check ` or ``echo `date`  now`` before   pushing the   changes.\n\n" +
+			"```make\nbuild:   \n\tgo build ./...\t\n\n\n\ntest:\n\tgo test ./...\n```\n\n" +
+			"<!--\n  keep   this\n\n\n-->\n\n    indented   \n    code\n",
+	},
+	{
+		name:  "tabs_preserve",
+		files: map[string]string{".mdfmt.yaml": "whitespace:\n  tabs: preserve\n"},
+		args:  []string{"-"},
+		stdin: "# Tabs\n\nKey:\tvalue  and\tmore.\n\n- one\n\t- nested\n- two\n\n```\na\tb\n```\n",
+	},
+	{
+		name:  "escape_line_starts",
+		args:  []string{"--line-width", "20", "-"},
+		stdin: "Press the key marked # for the menu, then choose option 1. and\nfollow - the steps > below or \\# keep escapes.\n",
+	},
+	{
+		name:  "safe",
+		args:  []string{"--safe", "-"},
+		stdin: "Some   text\nwrapped.\n\n* one\n    * nested\n* two\n",
+	},
+	{
+		name: "safe_custom_syntax",
+		files: map[string]string{
+			"math.md":  "x $a$ y  \n\n$$\nx\n$$\n",
+			"marks.md": "a [[wiki]] ==hi== #tag b  \n",
+			"mdx.md":   "<Note />\n\ntext  \n",
+		},
+		args: []string{"--safe", "--write", "."},
+		show: []string{"math.md", "marks.md", "mdx.md"},
+	},
+	{
+		name: "mdx_dialect_files",
+		files: map[string]string{
+			".mdfmt.yaml": "dialect: mdx\n",
+			"a.md":        "Title\n=====\n",
+			"c.mdx":       "import X from './x'\n\n<X />\n\n* item\n",
+		},
+		args: []string{"--write", "a.md", "c.mdx"},
+		show: []string{"a.md", "c.mdx"},
+	},
+	{
+		name:     "safe_changes_html",
+		files:    map[string]string{".mdfmt.yaml": "replacements:\n  k8s: Kubernetes\n"},
+		args:     []string{"--safe", "-"},
+		stdin:    "Deploy to k8s.\n",
+		exitCode: 2,
+	},
+	{
+		name:  "safe_adjacent_lists",
+		args:  []string{"--safe", "-"},
+		stdin: "1) a\n2) b\n\n10. ten\n\n- one\n\n* two\n\n+ three\n",
+	},
+	{
+		name:  "format_version_comment",
+		files: map[string]string{".mdfmt.yaml": "format_version: 1\nformat_version_comment: true\n"},
+		args:  []string{"-"},
+		stdin: "<!-- mdfmt format_version: 1 -->\n\n# Versioned\n\nText.\n",
+	},
+	{
+		name:  "format_version_comment_safe",
+		files: map[string]string{".mdfmt.yaml": "format_version: 1\nformat_version_comment: true\n", "a.md": "Versioned\n=========\n"},
+		args:  []string{"--safe", "--write", "a.md"},
+		show:  []string{"a.md"},
+	},
+	{
+		name:     "format_version_newer",
+		args:     []string{"-"},
+		stdin:    "# Versioned\n\n<!-- mdfmt format_version: 2 -->\n",
+		exitCode: 2,
+	},
+	{
+		name:     "format_version_unsupported",
+		files:    map[string]string{".mdfmt.yaml": "format_version: 2\n"},
+		args:     []string{"-"},
+		stdin:    "# Versioned\n",
+		exitCode: 2,
+	},
+	{
+		name:     "check_rule_counts",
+		files:    map[string]string{"a.md": "Title\n=====\n\nSome text\nthat wraps.\n\n* one\n* two\n", "b.md": "# B\n\n\n\n\nText.\n", "c.md": "# C\n"},
+		args:     []string{"check", "--rule-counts", "."},
+		exitCode: 1,
+	},
+	{
+		name:     "rule_counts_without_check",
+		files:    map[string]string{"a.md": "# A\n"},
+		args:     []string{"--rule-counts", "a.md"},
+		exitCode: 2,
+	},
+	{
+		name:  "bullet_style_preserve",
+		args:  []string{"--bullet-style", "preserve", "-"},
+		stdin: "* one\n* two\n  + nested\n\nText.\n\n- three\n",
+	},
+	{
+		name:  "fence_style_preserve",
+		args:  []string{"--fence-style", "preserve", "-"},
+		stdin: "~~~go\nfmt.Println()\n~~~\n\n````\n```\nnested\n```\n````\n",
+	},
+	{
+		name:  "fence_style_tildes",
+		args:  []string{"--fence-style", "~~~", "-"},
+		stdin: "```go\nfmt.Println()\n```\n\n```\n~~~\nnested\n~~~\n```\n",
+	},
+	{
+		name:  "ordered_list_start",
+		args:  []string{"-"},
+		stdin: "1. Install\n\nThen configure:\n\n2. Configure\n2. Run\n",
+	},
+	{
+		name:  "ordered_list_keep_numbers",
+		args:  []string{"--set", "list.renumber=false", "-"},
+		stdin: "1) one\n1) two\n5) three\n",
+	},
+	{
+		name: "no_reflow",
+		files: map[string]string{
+			".mdfmt.yaml": "reflow:\n  exclude: [\"^Name:\"]\n",
+			"card.md": "Prose broken\nby hand.\n\n<!-- mdfmt:no-reflow -->\nRoses are red,\nviolets are blue.\n\n" +
+				"Name: mdfmt\nLicense: Apache-2.0\n",
+		},
+		args: []string{"card.md"},
+	},
+	{
+		name:  "reflow_preserve",
+		args:  []string{"--set", "reflow.mode=preserve", "-"},
+		stdin: "One sentence\nper line,   as written.\n\n> Quoted\n> lines too.\n",
+	},
+	{
+		name:  "line_width_zero",
+		args:  []string{"--line-width", "0", "-"},
+		stdin: "A long line that is well over the default line width of eighty characters and is never wrapped.\nNext line.\n",
+	},
+	{
+		name:  "table",
+		args:  []string{"--line-width", "20", "-"},
+		stdin: "| Option | Effect |\n|:-|-:|\n| `line_width` | Wraps paragraphs and never tables |\n",
+	},
+	{
+		name: "reflow_wrap",
+		args: []string{"--line-width", "30", "--set", "reflow.wrap=[paragraph, blockquote]", "-"},
+		stdin: "A top-level paragraph well past thirty columns.\n\n" +
+			"- An item with a paragraph:\n\n  Kept as written even though well past thirty columns.\n",
+	},
+	{
+		name:  "hard_breaks",
+		args:  []string{"--set", "whitespace.hard_break_style=spaces", "-"},
+		stdin: "one two\\\nthree  \nfour   \n\n```\ncode  \n```\n\n- item\\\n  more\n",
+	},
+	{
+		name: "final_newline",
+		files: map[string]string{"none.md": strings.TrimSuffix(formatted, "\n"), "extra.md": formatted + "\n\n",
+			"ok.md": formatted},
+		args: []string{"-l", "."},
+	},
+	{
+		name:  "final_newline_off",
+		files: map[string]string{"none.md": "# Title", "extra.md": "# Title\n\n\n"},
+		args:  []string{"--set", "whitespace.ensure_final_newline=false", "-w", "."},
+		show:  []string{"none.md", "extra.md"},
+	},
+	{
+		name:     "check_whitespace_only",
+		files:    whitespaceOnly,
+		args:     []string{"--check", "."},
+		exitCode: 1,
+	},
+	{
+		name:  "list_whitespace_only",
+		files: whitespaceOnly,
+		args:  []string{"-l", "."},
+	},
+	{
+		name:  "write_whitespace_only",
+		files: whitespaceOnly,
+		args:  []string{"-w", "."},
+		show:  []string{"trailing.md", "blanks.md", "newline.md", "crlf.md"},
+	},
+	{
+		name: "dry_run",
+		files: map[string]string{
+			".mdfmt.yaml":         "files:\n  ignore_patterns:\n    - \"node_modules/**\"\n    - \"*.tmp.md\"\n",
+			"a.md":                unformatted,
+			"b.md":                "# Title\n",
+			"notes.tmp.md":        unformatted,
+			"docs/.mdfmt.yaml":    "heading:\n  style: setext\n",
+			"docs/guide.md":       "# Title\n",
+			"node_modules/x/a.md": unformatted,
+		},
+		args: []string{"--write", "--dry-run", "."},
+		show: []string{"a.md", "docs/guide.md"},
+	},
+	{
+		name:     "dry_run_without_write",
+		files:    map[string]string{"a.md": unformatted},
+		args:     []string{"--dry-run", "a.md"},
+		exitCode: 2,
+	},
+	{
+		name:  "code_info_string",
+		args:  []string{"-"},
+		stdin: "```go title=\"main.go\" {linenos=true}\npackage main\n```\n\n~~~ not a fence? yes it is\nx\n~~~\n\n~~~sh `title`\nls\n~~~\n",
+	},
+	{
+		name: "config_flag",
+		files: map[string]string{
+			".mdfmt.yaml": "list:\n  bullet_style: \"*\"\n",
+			"custom.yaml": "list:\n  bullet_style: \"+\"\n",
+			"README.md":   unformatted,
+		},
+		args: []string{"--config", "custom.yaml", "README.md"},
+	},
+	{
+		name: "config_toml",
+		files: map[string]string{
+			".mdfmt.toml":   "[list]\nbullet_style = \"*\"\n",
+			"docs/guide.md": unformatted,
+		},
+		dir:  "docs",
+		args: []string{"guide.md"},
+	},
+	{
+		name: "config_extends",
+		files: map[string]string{
+			".mdfmt.yaml":     "extends: [strict, ./base.mdfmt.yaml]\n",
+			"base.mdfmt.yaml": "list:\n  bullet_style: \"*\"\n",
+			"README.md":       unformatted + "\nSee https://example.com.\n",
+		},
+		args: []string{"README.md"},
+	},
+	{
+		name: "option_flags",
+		files: map[string]string{
+			".mdfmt.yaml": "list:\n  bullet_style: \"+\"\n",
+			"README.md":   unformatted,
+		},
+		args: []string{"--bullet-style", "*", "--set", "heading.style=setext", "README.md"},
+	},
+	{
+		name: "print_config",
+		files: map[string]string{
+			".mdfmt.yaml":   "extends: [github]\nline_width: 100\n",
+			".editorconfig": "root = true\n\n[*.md]\nindent_size = 4\n",
+		},
+		args: []string{"--print-config", "-v", "README.md"},
+	},
+	{
+		name: "config_invalid",
+		files: map[string]string{
+			".mdfmt.yaml": "line_width: -1\n",
+			"README.md":   unformatted,
+		},
+		args:     []string{"README.md"},
+		exitCode: 2,
+	},
+	{
+		name: "ignore_patterns",
+		files: map[string]string{
+			".mdfmt.yaml":               "files:\n  ignore_patterns: [\"vendor/**\", \"*.draft.md\"]\n",
+			"README.md":                 unformatted,
+			"notes.draft.md":            unformatted,
+			"vendor/lib/README.md":      unformatted,
+			"docs/vendor-notes.md":      unformatted,
+			"docs/drafts/plan.draft.md": unformatted,
+		},
+		args: []string{"-l", "."},
+	},
+	{
+		name: "ignore_gitignore_syntax",
+		files: map[string]string{
+			".mdfmt.yaml":              "files:\n  ignore_patterns: [\"**/generated/*.md\", \"/CHANGELOG.md\", \"docs/**\", \"!docs/keep.md\"]\n",
+			"CHANGELOG.md":             unformatted,
+			"pkg/CHANGELOG.md":         unformatted,
+			"pkg/web/generated/api.md": unformatted,
+			"docs/guide.md":            unformatted,
+			"docs/keep.md":             unformatted,
+		},
+		args: []string{"-l", "."},
+	},
+	{
+		name:     "conflicting_modes",
+		files:    map[string]string{"README.md": unformatted},
+		args:     []string{"-w", "-c", "README.md"},
+		exitCode: 2,
+	},
+	{
+		name:     "missing_path",
+		args:     []string{"missing.md"},
+		exitCode: 2,
+	},
+}
+
+func TestCLI(t *testing.T) {
+	bin := binary(t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			work := t.TempDir()
+			for name, content := range tc.files {
+				writeFile(t, filepath.Join(work, name), content)
+			}
+
+			transcript := run(t, bin, work, tc)
+			compareGolden(t, filepath.Join("testdata", tc.name+".golden"), transcript)
+		})
+	}
+}
+
+// TestEmbeddedGofmt checks that formatting the doc comments of a file gofmt
+// accepts leaves a file gofmt accepts
+func TestEmbeddedGofmt(t *testing.T) {
+	bin := binary(t)
+	source := "// Package demo computes   things.\n//\n//\tx := compute()\n//\n// Options:\n//\n//   - fast\n//   - slow\n//\n" +
+		"// Notes:\n//\n//   - a\n//     example()\n//\n// # Usage\n//\n// Call   it.\npackage demo\n"
+	if formatted, err := format.Source([]byte(source)); err != nil || string(formatted) != source {
+		t.Fatalf("Expected gofmt to accept the source, got %v:\n%s", err, formatted)
+	}
+	work := t.TempDir()
+	path := filepath.Join(work, "demo.go")
+	writeFile(t, path, source)
+
+	run(t, bin, work, testCase{args: []string{"--embedded", "-w", "demo.go"}})
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read demo.go: %v", err)
+	}
+	if !strings.Contains(string(content), "// Package demo computes things.\n") {
+		t.Errorf("Expected the doc comment to be formatted, got:\n%s", content)
+	}
+	formatted, err := format.Source(content)
+	if err != nil {
+		t.Fatalf("Failed to gofmt demo.go: %v", err)
+	}
+	if !bytes.Equal(formatted, content) {
+		t.Errorf("Expected gofmt to leave demo.go unchanged:\n%s\ngot:\n%s", content, formatted)
+	}
+}
+
+// run executes bin for tc in work and returns the transcript of the run
+func run(t *testing.T, bin, work string, tc testCase) string {
+	t.Helper()
+
+	cmd := exec.Command(bin, tc.args...)
+	cmd.Dir = filepath.Join(work, tc.dir)
+	cmd.Stdin = strings.NewReader(tc.stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	exitCode := 0
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("Failed to run mdfmt: %v", err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+	if exitCode != tc.exitCode {
+		t.Errorf("Expected exit code %d, got %d\nstderr: %s", tc.exitCode, exitCode, stderr.String())
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ mdfmt %s\n", strings.Join(tc.args, " "))
+	fmt.Fprintf(&b, "exit: %d\n", exitCode)
+	fmt.Fprintf(&b, "-- stdout --\n%s", stdout.String())
+	fmt.Fprintf(&b, "-- stderr --\n%s", stderr.String())
+
+	show := append([]string(nil), tc.show...)
+	sort.Strings(show)
+	for _, name := range show {
+		content, err := os.ReadFile(filepath.Join(work, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		fmt.Fprintf(&b, "-- %s --\n%s", name, content)
+	}
+
+	return normalize(b.String(), work)
+}
+
+// normalize replaces the work directory with $WORK so transcripts do not
+// depend on where the test runs
+func normalize(transcript, work string) string {
+	if resolved, err := filepath.EvalSymlinks(work); err == nil && resolved != work {
+		transcript = strings.ReplaceAll(transcript, resolved, "$WORK")
+	}
+	transcript = strings.ReplaceAll(transcript, work, "$WORK")
+	return filepath.ToSlash(transcript)
+}
+
+// compareGolden compares got with the golden file at path, rewriting it
+// with -update
+func compareGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		writeFile(t, path, got)
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Transcript differs from %s:\n--- want ---\n%s--- got ---\n%s", path, want, got)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}